@@ -0,0 +1,90 @@
+// Package debug - отладочный HTTP сервер сервиса: pprof, expvar и дамп горутин
+// Сервер включается переменной DEBUG_ADDR и слушает только loopback: профили и дампы раскрывают
+// внутреннее состояние процесса, поэтому наружу их не публикуем (в docker - через docker exec или port-forward)
+package debug
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrNonLoopbackAddr возвращается, если DEBUG_ADDR указывает не на loopback интерфейс
+var ErrNonLoopbackAddr = errors.New("debug server must listen on a loopback address")
+
+// Handler возвращает mux отладочных endpoint'ов:
+//   - /debug/pprof/ - профили net/http/pprof (profile, trace, heap, goroutine, ...)
+//   - /debug/vars - expvar (memstats, cmdline и опубликованные сервисом переменные)
+//   - /debug/goroutines - полный текстовый дамп стеков всех горутин
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", goroutineDump)
+	return mux
+}
+
+// goroutineDump пишет стеки всех горутин в формате panic (как debug=2 у /debug/pprof/goroutine)
+func goroutineDump(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "goroutines: %d\n\n", runtime.NumGoroutine())
+	_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// Start запускает отладочный сервер на addr и возвращает его для регистрации в shutdown manager
+// Пустой addr - сервер выключен, возвращается nil. Адрес вне loopback - ErrNonLoopbackAddr
+func Start(addr string, logger *zap.Logger) (*http.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	if err := checkLoopback(addr); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("debug server listen %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		// WriteTimeout не задаём: /debug/pprof/profile и /debug/pprof/trace пишут ответ seconds секунд
+	}
+
+	go func() {
+		logger.Info("Debug server started", zap.String("addr", listener.Addr().String()))
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("debug server error", zap.Error(err))
+		}
+	}()
+
+	return srv, nil
+}
+
+// checkLoopback проверяет, что host из addr - localhost или loopback IP
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid DEBUG_ADDR %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrNonLoopbackAddr, addr)
+}
@@ -0,0 +1,89 @@
+// Package discovery создаёт gRPC клиентов к зависимостям, которые могут работать в нескольких инстансах.
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // регистрирует клиентский health check для healthCheckConfig
+)
+
+// Схемы адресов, которые разрешает этот пакет
+const (
+	schemeStatic = "static"
+	schemeSRV    = "srv"
+)
+
+// NewClient создаёт gRPC соединение с зависимостью по адресу addr с балансировкой round_robin между инстансами
+// Форматы addr:
+//   - "inventory:50051" — как раньше: DNS-имя, все A/AAAA-записи (headless service) становятся инстансами;
+//   - "inventory-1:50051,inventory-2:50051" — статический список инстансов;
+//   - "srv:///_grpc._tcp.inventory.svc.cluster.local" — SRV-записи, перечитываются раз в SRVRefreshInterval;
+//   - любой другой target gRPC со схемой (dns:///, unix:, passthrough:///) — без изменений.
+//
+// opts добавляются после опций пакета (транспорт, интерцепторы задаёт сервис)
+func NewClient(addr string, cfg Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil, fmt.Errorf("discovery: empty address")
+	}
+
+	target := addr
+	dialOpts := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig(cfg))}
+	switch {
+	case strings.HasPrefix(addr, schemeSRV+":///"):
+		dialOpts = append(dialOpts, grpc.WithResolvers(&srvBuilder{cfg: cfg, subsetKey: subsetKey(cfg)}))
+	case strings.Contains(addr, ",") && !strings.Contains(addr, "://"):
+		endpoints, err := parseStatic(addr)
+		if err != nil {
+			return nil, err
+		}
+		target = schemeStatic + ":///" + strings.Join(endpoints, ",")
+		dialOpts = append(dialOpts, grpc.WithResolvers(&staticBuilder{cfg: cfg, subsetKey: subsetKey(cfg)}))
+	}
+
+	return grpc.NewClient(target, append(dialOpts, opts...)...)
+}
+
+// serviceConfig возвращает service config клиента: round_robin и, если включён, health check всего сервера
+func serviceConfig(cfg Config) string {
+	if cfg.HealthCheck {
+		return `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+	}
+	return `{"loadBalancingConfig":[{"round_robin":{}}]}`
+}
+
+// subsetKey возвращает ключ подмножества: SubsetKey или имя хоста
+func subsetKey(cfg Config) string {
+	if cfg.SubsetKey != "" {
+		return cfg.SubsetKey
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// parseStatic разбирает статический список "host:port,host:port" (пустые элементы пропускаются, повторы схлопываются)
+func parseStatic(list string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var endpoints []string
+	for _, endpoint := range strings.Split(list, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		if !strings.Contains(endpoint, ":") {
+			return nil, fmt.Errorf("discovery: endpoint %q must be host:port", endpoint)
+		}
+		if _, ok := seen[endpoint]; ok {
+			continue
+		}
+		seen[endpoint] = struct{}{}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("discovery: empty endpoint list %q", list)
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,39 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       string
+		wantTarget string
+		wantErr    bool
+	}{
+		{name: "dns name", addr: "inventory:50051", wantTarget: "dns:///inventory:50051"},
+		{name: "static list", addr: " inv-1:50051, inv-2:50051,inv-1:50051 ", wantTarget: "static:///inv-1:50051,inv-2:50051"},
+		{name: "srv", addr: "srv:///_grpc._tcp.inventory.svc.cluster.local", wantTarget: "srv:///_grpc._tcp.inventory.svc.cluster.local"},
+		{name: "other scheme is kept", addr: "dns:///inventory:50051", wantTarget: "dns:///inventory:50051"},
+		{name: "empty", addr: " ", wantErr: true},
+		{name: "static list without port", addr: "inv-1:50051,inv-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := NewClient(tt.addr, DefaultConfig(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer conn.Close()
+
+			require.Equal(t, tt.wantTarget, conn.CanonicalTarget())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package discovery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caarlos0/env/v10"
+)
+
+// Config содержит настройки обнаружения и балансировки для gRPC клиентов сервисов
+// Адреса зависимостей (INVENTORY_GRPC_ADDR и т.д.) задаются в конфигурации сервиса, формат - см. NewClient
+type Config struct {
+	// SRVRefreshInterval — как часто перечитывать SRV-записи адресов srv:///
+	SRVRefreshInterval time.Duration `env:"GRPC_DISCOVERY_REFRESH_INTERVAL" envDefault:"30s"`
+	// HealthCheck — клиентский health check (grpc.health.v1): round_robin обходит инстансы в NOT_SERVING.
+	// Серверы без health service считаются здоровыми
+	HealthCheck bool `env:"GRPC_CLIENT_HEALTH_CHECK" envDefault:"true"`
+	// SubsetSize — с каким числом инстансов зависимости соединяется клиент; 0 - со всеми.
+	// Применяется к статическому списку и SRV: при сотнях инстансов ограничивает число соединений.
+	// Подмножество выбирается по списку адресов без учёта health check: инстанс в NOT_SERVING остаётся
+	// в подмножестве и не заменяется другим, поэтому размер задаётся с запасом на недоступные инстансы
+	SubsetSize int `env:"GRPC_CLIENT_SUBSET_SIZE" envDefault:"0"`
+	// SubsetKey — ключ выбора подмножества; пусто - имя хоста. Разные ключи равномерно распределяют
+	// клиентов по инстансам, один ключ всегда выбирает одно подмножество
+	SubsetKey string `env:"GRPC_CLIENT_SUBSET_KEY"`
+}
+
+// DefaultConfig возвращает конфигурацию с дефолтными значениями
+func DefaultConfig() Config {
+	return Config{
+		SRVRefreshInterval: 30 * time.Second,
+		HealthCheck:        true,
+	}
+}
+
+// LoadEnv загружает конфигурацию из переменных окружения
+// Использует пакет caarlos0/env/v10 для парсинга env-тегов
+func LoadEnv(cfg *Config) error {
+	return env.Parse(cfg)
+}
+
+// Validate проверяет корректность конфигурации
+func (c Config) Validate() error {
+	if c.SRVRefreshInterval <= 0 {
+		return fmt.Errorf("GRPC_DISCOVERY_REFRESH_INTERVAL must be positive")
+	}
+	if c.SubsetSize < 0 {
+		return fmt.Errorf("GRPC_CLIENT_SUBSET_SIZE must not be negative")
+	}
+	return nil
+}
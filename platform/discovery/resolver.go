@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// srvLookupTimeout ограничивает один DNS-запрос SRV-записей
+const srvLookupTimeout = 5 * time.Second
+
+// staticBuilder разрешает target static:///host:port,host:port в фиксированный список инстансов
+type staticBuilder struct {
+	cfg       Config
+	subsetKey string
+}
+
+func (b *staticBuilder) Scheme() string { return schemeStatic }
+
+func (b *staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	endpoints, err := parseStatic(target.Endpoint())
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.UpdateState(stateOf(subset(endpoints, b.cfg.SubsetSize, b.subsetKey))); err != nil {
+		return nil, err
+	}
+	return staticResolver{}, nil
+}
+
+// staticResolver - список не меняется, перечитывать нечего
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+func (staticResolver) Close()                                {}
+
+// srvBuilder разрешает target srv:///<имя SRV-записи> в инстансы из SRV-записей
+type srvBuilder struct {
+	cfg       Config
+	subsetKey string
+	lookup    func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) // nil - net.DefaultResolver.LookupSRV
+}
+
+func (b *srvBuilder) Scheme() string { return schemeSRV }
+
+func (b *srvBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := strings.TrimSpace(target.Endpoint())
+	if name == "" {
+		return nil, fmt.Errorf("discovery: empty SRV name in %q", target.String())
+	}
+	lookup := b.lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupSRV
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &srvResolver{
+		name:      name,
+		cc:        cc,
+		interval:  b.cfg.SRVRefreshInterval,
+		subset:    b.cfg.SubsetSize,
+		subsetKey: b.subsetKey,
+		lookup:    lookup,
+		resolve:   make(chan struct{}, 1),
+		cancel:    cancel,
+	}
+	r.wg.Add(1)
+	go r.watch(ctx)
+	return r, nil
+}
+
+// srvResolver перечитывает SRV-записи раз в interval и по запросу gRPC (ResolveNow, например после обрыва соединения)
+type srvResolver struct {
+	name      string
+	cc        resolver.ClientConn
+	interval  time.Duration
+	subset    int
+	subsetKey string
+	lookup    func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+	resolve chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (r *srvResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+func (r *srvResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *srvResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+
+	interval := r.interval
+	if interval <= 0 {
+		interval = DefaultConfig().SRVRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+	}
+}
+
+// refresh читает SRV-записи и передаёт инстансы в gRPC
+// Ошибка DNS передаётся через ReportError: балансировщик продолжает работать с последним известным списком
+func (r *srvResolver) refresh(ctx context.Context) {
+	lookupCtx, cancel := context.WithTimeout(ctx, srvLookupTimeout)
+	defer cancel()
+	_, records, err := r.lookup(lookupCtx, "", "", r.name)
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil && len(records) == 0 {
+		err = fmt.Errorf("no SRV records")
+	}
+	if err != nil {
+		r.cc.ReportError(fmt.Errorf("discovery: lookup SRV %s: %w", r.name, err))
+		return
+	}
+
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+	_ = r.cc.UpdateState(stateOf(subset(endpoints, r.subset, r.subsetKey)))
+}
+
+// subset выбирает size инстансов из endpoints rendezvous-хешированием по key
+// Выбор стабилен: добавление или удаление инстанса меняет подмножество минимально.
+// size <= 0 или не меньше числа инстансов - возвращаются все
+func subset(endpoints []string, size int, key string) []string {
+	sorted := append([]string(nil), endpoints...)
+	if size <= 0 || size >= len(sorted) {
+		sort.Strings(sorted)
+		return sorted
+	}
+
+	score := func(endpoint string) uint64 {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(endpoint))
+		return h.Sum64()
+	}
+	sort.Slice(sorted, func(i, j int) bool { return score(sorted[i]) > score(sorted[j]) })
+	sorted = sorted[:size]
+	sort.Strings(sorted)
+	return sorted
+}
+
+// stateOf превращает адреса инстансов в состояние резолвера: каждый адрес - отдельный endpoint
+func stateOf(endpoints []string) resolver.State {
+	state := resolver.State{Endpoints: make([]resolver.Endpoint, 0, len(endpoints))}
+	for _, endpoint := range endpoints {
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{{Addr: endpoint}}})
+	}
+	return state
+}
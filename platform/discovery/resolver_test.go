@@ -0,0 +1,330 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeClientConn - ClientConn резолвера, который запоминает переданные состояния и ошибки
+// Остальные методы интерфейса резолверы пакета не вызывают. Переполненный буфер не блокирует резолвер (Close)
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	states chan resolver.State
+	errs   chan error
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{states: make(chan resolver.State, 16), errs: make(chan error, 16)}
+}
+
+func (c *fakeClientConn) UpdateState(state resolver.State) error {
+	select {
+	case c.states <- state:
+	default:
+	}
+	return nil
+}
+
+func (c *fakeClientConn) ReportError(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+// nextAddrs ждёт следующее состояние резолвера и возвращает его адреса
+func (c *fakeClientConn) nextAddrs(t *testing.T) []string {
+	t.Helper()
+	select {
+	case state := <-c.states:
+		return addrsOf(state)
+	case <-time.After(time.Second):
+		t.Fatal("resolver did not update state")
+		return nil
+	}
+}
+
+func addrsOf(state resolver.State) []string {
+	var addrs []string
+	for _, endpoint := range state.Endpoints {
+		for _, addr := range endpoint.Addresses {
+			addrs = append(addrs, addr.Addr)
+		}
+	}
+	return addrs
+}
+
+// fakeLookup отдаёт результаты SRV по очереди (последний повторяется) и считает запросы
+type fakeLookup struct {
+	mu      sync.Mutex
+	results []lookupResult
+	names   []string
+}
+
+type lookupResult struct {
+	records []*net.SRV
+	err     error
+}
+
+func (l *fakeLookup) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := l.results[min(len(l.names), len(l.results)-1)]
+	l.names = append(l.names, name)
+	return "", result.records, result.err
+}
+
+func (l *fakeLookup) calls() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.names...)
+}
+
+func srvRecords(endpoints ...string) []*net.SRV {
+	records := make([]*net.SRV, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		host, port, _ := net.SplitHostPort(endpoint)
+		var p uint16
+		_, _ = fmt.Sscan(port, &p)
+		records = append(records, &net.SRV{Target: host + ".", Port: p})
+	}
+	return records
+}
+
+func parseTarget(t *testing.T, target string) resolver.Target {
+	t.Helper()
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	return resolver.Target{URL: *u}
+}
+
+func TestParseStatic(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "list", list: "inv-1:50051,inv-2:50051", want: []string{"inv-1:50051", "inv-2:50051"}},
+		{name: "spaces and empty items", list: " inv-1:50051 , ,inv-2:50051,", want: []string{"inv-1:50051", "inv-2:50051"}},
+		{name: "duplicates", list: "inv-1:50051,inv-2:50051,inv-1:50051", want: []string{"inv-1:50051", "inv-2:50051"}},
+		{name: "ipv6", list: "[::1]:50051,10.0.0.1:50051", want: []string{"[::1]:50051", "10.0.0.1:50051"}},
+		{name: "no port", list: "inv-1:50051,inv-2", wantErr: true},
+		{name: "empty", list: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatic(tt.list)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSubset(t *testing.T) {
+	endpoints := []string{"inv-3:50051", "inv-1:50051", "inv-5:50051", "inv-2:50051", "inv-4:50051"}
+	all := []string{"inv-1:50051", "inv-2:50051", "inv-3:50051", "inv-4:50051", "inv-5:50051"}
+
+	tests := []struct {
+		name string
+		size int
+		want []string
+	}{
+		{name: "size 0 returns all", size: 0, want: all},
+		{name: "size not less than endpoints returns all", size: 5, want: all},
+		{name: "size above endpoints returns all", size: 10, want: all},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, subset(endpoints, tt.size, "client-1"))
+		})
+	}
+
+	t.Run("subset is stable and sorted", func(t *testing.T) {
+		got := subset(endpoints, 2, "client-1")
+
+		require.Len(t, got, 2)
+		require.IsIncreasing(t, got)
+		require.Subset(t, all, got)
+		require.Equal(t, got, subset(all, 2, "client-1"), "order of endpoints does not matter")
+		require.Equal(t, []string{"inv-3:50051", "inv-1:50051", "inv-5:50051", "inv-2:50051", "inv-4:50051"}, endpoints, "input is not modified")
+	})
+
+	t.Run("removing other endpoint keeps subset", func(t *testing.T) {
+		got := subset(all, 2, "client-1")
+
+		var rest []string
+		removed := false
+		for _, endpoint := range all {
+			if !removed && endpoint != got[0] && endpoint != got[1] {
+				removed = true
+				continue
+			}
+			rest = append(rest, endpoint)
+		}
+		require.Equal(t, got, subset(rest, 2, "client-1"))
+	})
+
+	t.Run("adding endpoint changes at most one instance", func(t *testing.T) {
+		before := subset(all, 2, "client-1")
+		after := subset(append(append([]string(nil), all...), "inv-6:50051"), 2, "client-1")
+
+		kept := 0
+		for _, endpoint := range after {
+			if endpoint == before[0] || endpoint == before[1] {
+				kept++
+			}
+		}
+		require.GreaterOrEqual(t, kept, 1)
+	})
+
+	t.Run("different keys spread over all endpoints", func(t *testing.T) {
+		chosen := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			for _, endpoint := range subset(all, 2, fmt.Sprintf("client-%d", i)) {
+				chosen[endpoint]++
+			}
+		}
+		require.Len(t, chosen, len(all))
+	})
+}
+
+func TestStaticBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		size    int
+		want    int
+		wantErr bool
+	}{
+		{name: "all endpoints", target: "static:///inv-1:50051,inv-2:50051,inv-3:50051", want: 3},
+		{name: "subset", target: "static:///inv-1:50051,inv-2:50051,inv-3:50051", size: 2, want: 2},
+		{name: "invalid endpoint", target: "static:///inv-1:50051,inv-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := newFakeClientConn()
+			builder := &staticBuilder{cfg: Config{SubsetSize: tt.size}, subsetKey: "client-1"}
+
+			r, err := builder.Build(parseTarget(t, tt.target), cc, resolver.BuildOptions{})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer r.Close()
+
+			got := cc.nextAddrs(t)
+			require.Len(t, got, tt.want)
+			require.Subset(t, []string{"inv-1:50051", "inv-2:50051", "inv-3:50051"}, got)
+		})
+	}
+}
+
+func TestSRVResolver(t *testing.T) {
+	const name = "_grpc._tcp.inventory.svc.cluster.local"
+
+	// build запускает SRV-резолвер с fakeLookup; Close - в t.Cleanup
+	build := func(t *testing.T, cfg Config, lookup *fakeLookup) (resolver.Resolver, *fakeClientConn) {
+		t.Helper()
+		cc := newFakeClientConn()
+		builder := &srvBuilder{cfg: cfg, subsetKey: "client-1", lookup: lookup.LookupSRV}
+		r, err := builder.Build(parseTarget(t, "srv:///"+name), cc, resolver.BuildOptions{})
+		require.NoError(t, err)
+		t.Cleanup(r.Close)
+		return r, cc
+	}
+
+	t.Run("records become endpoints", func(t *testing.T) {
+		lookup := &fakeLookup{results: []lookupResult{{records: srvRecords("inv-2.svc:50051", "inv-1.svc:50052")}}}
+		_, cc := build(t, Config{SRVRefreshInterval: time.Hour}, lookup)
+
+		require.Equal(t, []string{"inv-1.svc:50052", "inv-2.svc:50051"}, cc.nextAddrs(t))
+		require.Equal(t, []string{name}, lookup.calls())
+	})
+
+	t.Run("subset of records", func(t *testing.T) {
+		lookup := &fakeLookup{results: []lookupResult{{records: srvRecords("inv-1.svc:50051", "inv-2.svc:50051", "inv-3.svc:50051")}}}
+		_, cc := build(t, Config{SRVRefreshInterval: time.Hour, SubsetSize: 2}, lookup)
+
+		require.Equal(t, subset([]string{"inv-1.svc:50051", "inv-2.svc:50051", "inv-3.svc:50051"}, 2, "client-1"), cc.nextAddrs(t))
+	})
+
+	t.Run("lookup errors are reported without state", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			result lookupResult
+		}{
+			{name: "dns error", result: lookupResult{err: errors.New("no such host")}},
+			{name: "no records", result: lookupResult{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, cc := build(t, Config{SRVRefreshInterval: time.Hour}, &fakeLookup{results: []lookupResult{tt.result}})
+
+				select {
+				case err := <-cc.errs:
+					require.ErrorContains(t, err, name)
+				case <-time.After(time.Second):
+					t.Fatal("lookup error is not reported")
+				}
+				require.Empty(t, cc.states)
+			})
+		}
+	})
+
+	t.Run("records are re-read by interval", func(t *testing.T) {
+		lookup := &fakeLookup{results: []lookupResult{
+			{records: srvRecords("inv-1.svc:50051")},
+			{err: errors.New("timeout")},
+			{records: srvRecords("inv-1.svc:50051", "inv-2.svc:50051")},
+		}}
+		_, cc := build(t, Config{SRVRefreshInterval: 10 * time.Millisecond}, lookup)
+
+		require.Equal(t, []string{"inv-1.svc:50051"}, cc.nextAddrs(t))
+		// Ошибка между чтениями не сбрасывает список: балансировщик работает с последним известным
+		select {
+		case <-cc.errs:
+		case <-time.After(time.Second):
+			t.Fatal("lookup error is not reported")
+		}
+		require.Equal(t, []string{"inv-1.svc:50051", "inv-2.svc:50051"}, cc.nextAddrs(t))
+	})
+
+	t.Run("ResolveNow re-reads records", func(t *testing.T) {
+		lookup := &fakeLookup{results: []lookupResult{
+			{records: srvRecords("inv-1.svc:50051")},
+			{records: srvRecords("inv-2.svc:50051")},
+		}}
+		r, cc := build(t, Config{SRVRefreshInterval: time.Hour}, lookup)
+
+		require.Equal(t, []string{"inv-1.svc:50051"}, cc.nextAddrs(t))
+		r.ResolveNow(resolver.ResolveNowOptions{})
+		require.Equal(t, []string{"inv-2.svc:50051"}, cc.nextAddrs(t))
+		require.Len(t, lookup.calls(), 2)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		builder := &srvBuilder{lookup: (&fakeLookup{}).LookupSRV}
+
+		_, err := builder.Build(parseTarget(t, "srv:///"), newFakeClientConn(), resolver.BuildOptions{})
+
+		require.Error(t, err)
+	})
+}
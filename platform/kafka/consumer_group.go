@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ConsumerGroupConfig содержит параметры членства consumer'а в consumer group.
+// Нулевые значения означают дефолты kafka-go (session 30s, heartbeat 3s, rebalance 30s).
+//
+// Static membership (group.instance.id, KIP-345) kafka-go v0.4.50 не поддерживает: Reader вступает в группу
+// JoinGroup v1 без group.instance.id, и каждый перезапуск инстанса - это новый member и ребалансировка.
+// Поэтому вместо static membership настраиваются таймауты:
+//   - RebalanceTimeout - сколько координатор ждёт, пока участники завершат обработку и вступят заново;
+//     должен быть больше самой долгой обработки одного сообщения, иначе сообщение в работе достанется
+//     другому инстансу и будет обработано повторно;
+//   - SessionTimeout - через сколько без heartbeat участник считается упавшим (его партиции переназначаются);
+//   - HeartbeatInterval - частота heartbeat, обычно не больше трети SessionTimeout.
+type ConsumerGroupConfig struct {
+	SessionTimeout    time.Duration `env:"KAFKA_CONSUMER_SESSION_TIMEOUT"`
+	HeartbeatInterval time.Duration `env:"KAFKA_CONSUMER_HEARTBEAT_INTERVAL"`
+	RebalanceTimeout  time.Duration `env:"KAFKA_CONSUMER_REBALANCE_TIMEOUT"`
+}
+
+// Validate проверяет согласованность таймаутов consumer group
+func (c ConsumerGroupConfig) Validate() error {
+	if c.SessionTimeout < 0 || c.HeartbeatInterval < 0 || c.RebalanceTimeout < 0 {
+		return fmt.Errorf("consumer group timeouts must not be negative")
+	}
+	if c.SessionTimeout > 0 && c.HeartbeatInterval > 0 && c.HeartbeatInterval >= c.SessionTimeout {
+		return fmt.Errorf("heartbeat interval (%s) must be less than session timeout (%s)", c.HeartbeatInterval, c.SessionTimeout)
+	}
+	return nil
+}
+
+// Apply переносит заданные (ненулевые) таймауты в конфигурацию kafka.Reader
+func (c ConsumerGroupConfig) Apply(rc *kafka.ReaderConfig) {
+	if c.SessionTimeout > 0 {
+		rc.SessionTimeout = c.SessionTimeout
+	}
+	if c.HeartbeatInterval > 0 {
+		rc.HeartbeatInterval = c.HeartbeatInterval
+	}
+	if c.RebalanceTimeout > 0 {
+		rc.RebalanceTimeout = c.RebalanceTimeout
+	}
+}
@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/shestoi/GoBigTech/platform/observability"
+)
+
+// Ключи заголовков события (кроме trace context и x-request-id, их задаёт observability.MessageHeaders)
+const (
+	// HeaderEventType - тип события (например, order.payment.completed): consumer маршрутизирует по нему без разбора body
+	HeaderEventType = "event_type"
+	// HeaderEventID - уникальный ID события: ключ идемпотентности consumer'а
+	HeaderEventID = "event_id"
+	// HeaderSchemaVersion - версия схемы payload (совпадает с event_version в body)
+	HeaderSchemaVersion = "schema_version"
+	// HeaderRetryCount - сколько попыток обработки уже было (выставляется при отправке в DLQ)
+	HeaderRetryCount = "retry_count"
+)
+
+// Headers - заголовки сообщения Kafka в виде map (ключ уникален, значение - строка)
+type Headers map[string]string
+
+// NewHeaders собирает заголовки события: тип, ID, версию схемы, а также trace context и request_id из ctx
+func NewHeaders(ctx context.Context, eventType, eventID string, schemaVersion int) Headers {
+	h := Headers(observability.MessageHeaders(ctx))
+	if eventType != "" {
+		h[HeaderEventType] = eventType
+	}
+	if eventID != "" {
+		h[HeaderEventID] = eventID
+	}
+	if schemaVersion > 0 {
+		h[HeaderSchemaVersion] = strconv.Itoa(schemaVersion)
+	}
+	return h
+}
+
+// HeadersFromKafka читает заголовки сообщения; при повторяющемся ключе побеждает последний
+func HeadersFromKafka(headers []kafka.Header) Headers {
+	h := make(Headers, len(headers))
+	for _, header := range headers {
+		h[header.Key] = string(header.Value)
+	}
+	return h
+}
+
+// Kafka возвращает заголовки для kafka.Message (отсортированы по ключу); nil, если заголовков нет
+func (h Headers) Kafka() []kafka.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]kafka.Header, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, kafka.Header{Key: key, Value: []byte(h[key])})
+	}
+	return out
+}
+
+// Context восстанавливает trace context и request_id из заголовков
+func (h Headers) Context(ctx context.Context) context.Context {
+	return observability.ContextFromMessageHeaders(ctx, h)
+}
+
+// EventType возвращает тип события; пусто, если producer не выставил заголовок
+func (h Headers) EventType() string {
+	return h[HeaderEventType]
+}
+
+// EventID возвращает ID события; пусто, если producer не выставил заголовок
+func (h Headers) EventID() string {
+	return h[HeaderEventID]
+}
+
+// SchemaVersion возвращает версию схемы payload; 0, если заголовка нет или он некорректен
+func (h Headers) SchemaVersion() int {
+	return h.intValue(HeaderSchemaVersion)
+}
+
+// RetryCount возвращает количество уже сделанных попыток обработки; 0, если заголовка нет
+func (h Headers) RetryCount() int {
+	return h.intValue(HeaderRetryCount)
+}
+
+// WithRetryCount возвращает копию заголовков с retry_count = n
+func (h Headers) WithRetryCount(n int) Headers {
+	out := make(Headers, len(h)+1)
+	for key, value := range h {
+		out[key] = value
+	}
+	out[HeaderRetryCount] = strconv.Itoa(n)
+	return out
+}
+
+func (h Headers) intValue(key string) int {
+	n, err := strconv.Atoi(h[key])
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/caarlos0/env/v10"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -21,6 +23,35 @@ type Config struct {
 	Format string
 	// AddCaller добавлять ли информацию о вызывающем коде, default: local=true, docker=false
 	AddCaller bool
+	// Sampling ограничивает повторяющиеся предупреждения и ошибки, default: выключено
+	Sampling SamplingConfig
+}
+
+// SamplingConfig задаёт бюджет одинаковых сообщений уровня Level и выше
+// Ключ бюджета - уровень и текст сообщения (поля не учитываются): за каждый Tick пишутся первые First
+// сообщений с этим ключом, затем каждое Thereafter-е. Так при недоступности Kafka повторы fetch и retry
+// не заливают лог, но первое появление ошибки и её продолжение остаются видны.
+// Сообщения ниже Level (обычные info-логи запросов) пишутся все
+type SamplingConfig struct {
+	// Enabled включает ограничение
+	Enabled bool `env:"LOG_SAMPLING_ENABLED" envDefault:"false"`
+	// Level минимальный уровень, к которому применяется бюджет (debug/info/warn/error)
+	Level string `env:"LOG_SAMPLING_LEVEL" envDefault:"warn"`
+	// Tick окно, в котором считается бюджет
+	Tick time.Duration `env:"LOG_SAMPLING_TICK" envDefault:"1s"`
+	// First сколько одинаковых сообщений за окно пишется без ограничения
+	First int `env:"LOG_SAMPLING_FIRST" envDefault:"10"`
+	// Thereafter после First пишется каждое Thereafter-е сообщение окна; 0 - остальные отбрасываются
+	Thereafter int `env:"LOG_SAMPLING_THEREAFTER" envDefault:"100"`
+}
+
+// LoadSamplingEnv загружает SamplingConfig из переменных окружения LOG_SAMPLING_*
+func LoadSamplingEnv() (SamplingConfig, error) {
+	var cfg SamplingConfig
+	if err := env.Parse(&cfg); err != nil {
+		return SamplingConfig{}, err
+	}
+	return cfg, nil
 }
 
 // New создаёт новый zap.Logger с указанной конфигурацией
@@ -45,18 +76,9 @@ func New(cfg Config) (*zap.Logger, error) {
 	}
 
 	// Парсим уровень логирования
-	var level zapcore.Level //Создаём переменную типа zapcore.Level.
-	switch strings.ToLower(cfg.Level) {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	default:
-		return nil, fmt.Errorf("invalid log level: %s (must be debug/info/warn/error)", cfg.Level)
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
 	}
 
 	// Настраиваем encoder в зависимости от формата
@@ -91,6 +113,12 @@ func New(cfg Config) (*zap.Logger, error) {
 		zapcore.AddSync(os.Stderr), //куда отправлять
 		level,                      //минимальный уровень логирования
 	)
+	if cfg.Sampling.Enabled {
+		core, err = newSampledCore(core, cfg.Sampling)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Создаём logger с опциями
 	var opts []zap.Option
@@ -108,6 +136,22 @@ func New(cfg Config) (*zap.Logger, error) {
 	return logger, nil
 }
 
+// parseLevel разбирает уровень логирования debug/info/warn/error
+func parseLevel(s string) (zapcore.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level: %s (must be debug/info/warn/error)", s)
+	}
+}
+
 // Sync безопасно вызывает log.Sync(), игнорируя harmless ошибки
 // (например, "sync /dev/stderr: invalid argument" на некоторых системах)
 func Sync(log *zap.Logger) {
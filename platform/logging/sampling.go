@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sampledCore пропускает сообщения уровня minLevel и выше через sampler, остальные пишет напрямую
+type sampledCore struct {
+	zapcore.Core               // без ограничения: сообщения ниже minLevel
+	sampled      zapcore.Core  // zapcore sampler поверх того же core
+	minLevel     zapcore.Level // с какого уровня действует бюджет
+}
+
+// newSampledCore оборачивает core бюджетом одинаковых сообщений из cfg
+// Используется sampler zap: счётчики общие для всех логгеров, производных от core через With,
+// поэтому повторы одного сообщения из разных горутин и компонентов делят один бюджет
+func newSampledCore(core zapcore.Core, cfg SamplingConfig) (zapcore.Core, error) {
+	minLevel, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_SAMPLING_LEVEL: %w", err)
+	}
+	if cfg.Tick <= 0 {
+		return nil, fmt.Errorf("LOG_SAMPLING_TICK must be positive")
+	}
+	if cfg.First <= 0 {
+		return nil, fmt.Errorf("LOG_SAMPLING_FIRST must be positive")
+	}
+	if cfg.Thereafter < 0 {
+		return nil, fmt.Errorf("LOG_SAMPLING_THEREAFTER must not be negative")
+	}
+
+	return &sampledCore{
+		Core:     core,
+		sampled:  zapcore.NewSamplerWithOptions(core, cfg.Tick, cfg.First, cfg.Thereafter),
+		minLevel: minLevel,
+	}, nil
+}
+
+// With добавляет поля в оба core; sampler при этом сохраняет свои счётчики
+func (c *sampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sampledCore{
+		Core:     c.Core.With(fields),
+		sampled:  c.sampled.With(fields),
+		minLevel: c.minLevel,
+	}
+}
+
+// Check выбирает core по уровню сообщения
+func (c *sampledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level >= c.minLevel {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
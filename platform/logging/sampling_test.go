@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// manualClock - часы логгера, которые двигает тест: окно sampler считается по времени сообщения
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func (c *manualClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+// newObservedSampledLogger создаёт логгер с sampledCore поверх observer и часами теста
+func newObservedSampledLogger(t *testing.T, cfg SamplingConfig) (*zap.Logger, *observer.ObservedLogs, *manualClock) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampled, err := newSampledCore(core, cfg)
+	require.NoError(t, err)
+	clock := &manualClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return zap.New(sampled, zap.WithClock(clock)), logs, clock
+}
+
+func TestSampledCore(t *testing.T) {
+	t.Run("only messages from level are sampled", func(t *testing.T) {
+		logger, logs, _ := newObservedSampledLogger(t, SamplingConfig{Level: "warn", Tick: time.Second, First: 2, Thereafter: 0})
+
+		for i := 0; i < 5; i++ {
+			logger.Info("request handled")
+			logger.Warn("kafka fetch failed")
+			logger.Error("kafka commit failed")
+		}
+
+		require.Equal(t, 5, logs.FilterMessage("request handled").Len())
+		require.Equal(t, 2, logs.FilterMessage("kafka fetch failed").Len())
+		require.Equal(t, 2, logs.FilterMessage("kafka commit failed").Len())
+	})
+
+	t.Run("first then every thereafter per window", func(t *testing.T) {
+		logger, logs, clock := newObservedSampledLogger(t, SamplingConfig{Level: "warn", Tick: time.Second, First: 2, Thereafter: 3})
+
+		// 1, 2 - первые First; затем 5 и 8 - каждое третье после First
+		for i := 0; i < 10; i++ {
+			logger.Warn("kafka fetch failed", zap.Int("attempt", i+1))
+		}
+		logger.Warn("kafka commit failed")
+
+		fetched := logs.FilterMessage("kafka fetch failed").AllUntimed()
+		require.Len(t, fetched, 4)
+		var attempts []int64
+		for _, entry := range fetched {
+			attempts = append(attempts, entry.ContextMap()["attempt"].(int64))
+		}
+		require.Equal(t, []int64{1, 2, 5, 8}, attempts)
+		require.Equal(t, 1, logs.FilterMessage("kafka commit failed").Len(), "other message has its own budget")
+
+		clock.now = clock.now.Add(time.Second)
+		for i := 0; i < 3; i++ {
+			logger.Warn("kafka fetch failed", zap.Int("attempt", 11+i))
+		}
+		require.Equal(t, 6, logs.FilterMessage("kafka fetch failed").Len(), "new window starts with First again")
+	})
+
+	t.Run("With shares counters", func(t *testing.T) {
+		logger, logs, _ := newObservedSampledLogger(t, SamplingConfig{Level: "warn", Tick: time.Second, First: 2, Thereafter: 0})
+		consumer := logger.With(zap.String("component", "consumer"))
+		producer := logger.With(zap.String("component", "producer"))
+
+		for i := 0; i < 3; i++ {
+			consumer.Warn("kafka unavailable")
+			producer.Warn("kafka unavailable")
+		}
+
+		written := logs.FilterMessage("kafka unavailable").AllUntimed()
+		require.Len(t, written, 2)
+		require.Equal(t, "consumer", written[0].ContextMap()["component"])
+		require.Equal(t, "producer", written[1].ContextMap()["component"])
+	})
+}
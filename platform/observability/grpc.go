@@ -26,13 +26,16 @@ func parseGRPCFullMethod(fullMethod string) (serviceName, method string) {
 	return fullMethod[:idx], fullMethod[idx+1:]
 }
 
-// GRPCUnaryServerInterceptor возвращает unary server interceptor: извлекает trace из metadata, создаёт span на RPC.
+// GRPCUnaryServerInterceptor возвращает unary server interceptor: извлекает trace и request_id из metadata, создаёт span на RPC.
 func GRPCUnaryServerInterceptor(serviceName string) grpc.UnaryServerInterceptor {
 	tracer := otel.Tracer(serviceName)
 	prop := otel.GetTextMapPropagator()
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		md, _ := metadata.FromIncomingContext(ctx)
 		ctx = prop.Extract(ctx, NewMetadataCarrier(md))
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			ctx = WithRequestID(ctx, values[0])
+		}
 		rpcService, rpcMethod := parseGRPCFullMethod(info.FullMethod)
 		if rpcService == "" {
 			rpcService = info.FullMethod
@@ -62,7 +65,58 @@ func GRPCUnaryServerInterceptor(serviceName string) grpc.UnaryServerInterceptor
 	}
 }
 
-// GRPCUnaryClientInterceptor возвращает unary client interceptor: создаёт span, инжектит trace в outgoing metadata.
+// GRPCStreamServerInterceptor возвращает stream server interceptor: извлекает trace и request_id из metadata,
+// создаёт span на весь поток (от открытия до завершения handler'а).
+func GRPCStreamServerInterceptor(serviceName string) grpc.StreamServerInterceptor {
+	tracer := otel.Tracer(serviceName)
+	prop := otel.GetTextMapPropagator()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = prop.Extract(ctx, NewMetadataCarrier(md))
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			ctx = WithRequestID(ctx, values[0])
+		}
+		rpcService, rpcMethod := parseGRPCFullMethod(info.FullMethod)
+		if rpcService == "" {
+			rpcService = info.FullMethod
+		}
+		if rpcMethod == "" {
+			rpcMethod = info.FullMethod
+		}
+		ctx, span := tracer.Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "grpc"),
+				attribute.String("rpc.service", rpcService),
+				attribute.String("rpc.method", rpcMethod),
+			),
+		)
+		defer span.End()
+
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if st, ok := status.FromError(err); ok {
+				span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+			}
+		}
+		return err
+	}
+}
+
+// tracedServerStream подменяет контекст потока на контекст со span'ом
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// GRPCUnaryClientInterceptor возвращает unary client interceptor: создаёт span, инжектит trace и request_id в outgoing metadata.
 func GRPCUnaryClientInterceptor(serviceName string) grpc.UnaryClientInterceptor {
 	tracer := otel.Tracer(serviceName)
 	prop := otel.GetTextMapPropagator()
@@ -89,6 +143,9 @@ func GRPCUnaryClientInterceptor(serviceName string) grpc.UnaryClientInterceptor
 			md = metadata.MD{}
 		}
 		prop.Inject(ctx, NewMetadataCarrier(md))
+		if requestID, ok := RequestIDFromContext(ctx); ok {
+			md.Set(RequestIDMetadataKey, requestID)
+		}
 		ctx = metadata.NewOutgoingContext(ctx, md)
 
 		err := invoker(ctx, method, req, reply, cc, opts...)
@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MessageHeaders возвращает заголовки для передачи контекста через брокер сообщений:
+// trace context (traceparent, tracestate, baggage) и request_id. Сохраняются вместе с событием (outbox) и копируются в заголовки Kafka.
+func MessageHeaders(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		headers[RequestIDMetadataKey] = requestID
+	}
+	return headers
+}
+
+// ContextFromMessageHeaders восстанавливает trace context и request_id из заголовков сообщения (обратная операция к MessageHeaders).
+func ContextFromMessageHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+	if requestID := headers[RequestIDMetadataKey]; requestID != "" {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	return ctx
+}
+
+// StartProducerSpan создаёт span публикации сообщения в topic; заголовки для сообщения берутся из возвращённого контекста через MessageHeaders.
+func StartProducerSpan(ctx context.Context, serviceName, topic string) (context.Context, trace.Span) {
+	return otel.Tracer(serviceName).Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+}
+
+// StartConsumerSpan извлекает контекст из заголовков сообщения и создаёт span его обработки (дочерний к span публикации).
+func StartConsumerSpan(ctx context.Context, serviceName, topic string, headers map[string]string) (context.Context, trace.Span) {
+	ctx = ContextFromMessageHeaders(ctx, headers)
+	return otel.Tracer(serviceName).Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+}
@@ -0,0 +1,24 @@
+package observability
+
+import (
+	"context"
+)
+
+// RequestIDHeader - HTTP заголовок с идентификатором запроса (принимается от клиента или генерируется)
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMetadataKey - ключ gRPC metadata, в котором request_id передаётся между сервисами
+const RequestIDMetadataKey = "x-request-id"
+
+type ctxKeyRequestID struct{}
+
+// WithRequestID сохраняет request_id в контексте: его добавляют L() в логи и GRPCUnaryClientInterceptor в исходящие вызовы
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID{}, requestID)
+}
+
+// RequestIDFromContext возвращает request_id из контекста, если он был установлен
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(ctxKeyRequestID{}).(string)
+	return requestID, ok && requestID != ""
+}
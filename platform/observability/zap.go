@@ -21,10 +21,13 @@ func TraceFields(ctx context.Context) []zap.Field {
 	}
 }
 
-// L возвращает logger с добавленными trace_id/span_id из ctx, если они есть.
+// L возвращает logger с добавленными trace_id/span_id и request_id из ctx, если они есть.
 // Использовать в хендлерах и сервисах: observability.L(ctx, logger).Info(...)
 func L(ctx context.Context, base *zap.Logger) *zap.Logger {
 	fields := TraceFields(ctx)
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
 	if len(fields) == 0 {
 		return base
 	}
@@ -22,8 +22,9 @@ type Manager struct {
 }
 
 type shutdownFunc struct {
-	name string
-	fn   func(context.Context) error
+	name    string
+	fn      func(context.Context) error
+	timeout time.Duration // 0 - общий таймаут Manager
 }
 
 // New создаёт новый Manager с указанным таймаутом и logger
@@ -43,9 +44,16 @@ func (m *Manager) Add(name string, fn func(context.Context) error) {
 	m.funcs = append(m.funcs, shutdownFunc{name: name, fn: fn})
 }
 
+// AddWithTimeout регистрирует shutdown функцию со своим таймаутом вместо общего
+// Нужна для шагов, которым общего таймаута мало или много, например ожидание обработки сообщений в работе
+func (m *Manager) AddWithTimeout(name string, timeout time.Duration, fn func(context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funcs = append(m.funcs, shutdownFunc{name: name, fn: fn, timeout: timeout})
+}
+
 // Wait блокирует выполнение до получения SIGINT или SIGTERM,
 // затем последовательно выполняет все зарегистрированные shutdown функции
-// Каждая функция выполняется с context.WithTimeout
 func (m *Manager) Wait() {
 	// Создаём канал для сигналов
 	sigChan := make(chan os.Signal, 1)
@@ -55,6 +63,13 @@ func (m *Manager) Wait() {
 	<-sigChan
 	m.logger.Info("Received shutdown signal, starting graceful shutdown")
 
+	m.Shutdown()
+}
+
+// Shutdown выполняет все зарегистрированные shutdown функции в обратном порядке регистрации, не дожидаясь сигнала
+// Нужен, когда сервис завершается сам, например если зависимости не готовы при старте
+// Каждая функция выполняется с context.WithTimeout: своим таймаутом из AddWithTimeout или общим
+func (m *Manager) Shutdown() {
 	// Выполняем все зарегистрированные функции последовательно
 	m.mu.Lock()
 	funcs := make([]shutdownFunc, len(m.funcs))
@@ -65,7 +80,11 @@ func (m *Manager) Wait() {
 		fn := funcs[i]
 		m.logger.Info("Executing shutdown function", zap.String("name", fn.name))
 
-		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		timeout := m.timeout
+		if fn.timeout > 0 {
+			timeout = fn.timeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		start := time.Now()
 
 		err := fn.fn(ctx) //выполняем shutdown функцию
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	platformshutdown "github.com/shestoi/GoBigTech/platform/shutdown"
@@ -31,12 +34,17 @@ type App struct {
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "assembly",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
@@ -55,6 +63,12 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Создаём store для idempotency (in-memory для dev/test, в production будет Postgres/Redis)
 	idempotencyStore := service.NewMemoryProcessedEventsStore()
 	const idempotencyTTL = 24 * time.Hour
@@ -68,6 +82,7 @@ func Build(cfg config.Config) (*App, error) {
 		zap.Int("retry_max_attempts", cfg.RetryMaxAttempts),
 		zap.Duration("retry_backoff_base", cfg.RetryBackoffBase),
 		zap.Duration("idempotency_ttl", idempotencyTTL),
+		zap.Duration("drain_timeout", cfg.DrainTimeout),
 	)
 
 	// Создаём Kafka publisher для событий сборки
@@ -99,6 +114,11 @@ func Build(cfg config.Config) (*App, error) {
 		cfg.KafkaBrokers,
 		cfg.ConsumerGroupID,
 		cfg.PaymentCompletedTopic,
+		platformkafka.ConsumerGroupConfig{
+			SessionTimeout:    cfg.ConsumerSessionTimeout,
+			HeartbeatInterval: cfg.ConsumerHeartbeatInterval,
+			RebalanceTimeout:  cfg.ConsumerRebalanceTimeout,
+		},
 		assemblyService,
 		dlqPublisher,
 		cfg.RetryMaxAttempts,
@@ -110,6 +130,9 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Регистрируем shutdown: otel последним, чтобы успели записаться spans/metrics
 	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
 	shutdownMgr.Add("kafka_consumer", func(ctx context.Context) error {
 		return consumer.Close()
 	})
@@ -119,6 +142,9 @@ func Build(cfg config.Config) (*App, error) {
 	shutdownMgr.Add("kafka_dlq_publisher", func(ctx context.Context) error {
 		return dlqPublisher.Close()
 	})
+	// Регистрируется последним, поэтому выполняется первым: сборка в работе дописывает событие через ещё открытые
+	// publishers и коммитит offset до закрытия consumer. Таймаут свой - SHUTDOWN_TIMEOUT обычно меньше сборки
+	shutdownMgr.AddWithTimeout("kafka_consumer_drain", cfg.DrainTimeout, consumer.Drain)
 
 	return &App{
 		logger:      logger,
@@ -22,6 +22,8 @@ const (
 type Config struct {
 	AppEnv          Env
 	ShutdownTimeout time.Duration
+	DrainTimeout    time.Duration // ASSEMBLY_DRAIN_TIMEOUT: сколько при shutdown ждать сборку в работе до закрытия consumer
+	DebugAddr       string        // DEBUG_ADDR: отладочный сервер (pprof, expvar), только loopback; пусто - выключен
 
 	// OpenTelemetry (OTEL_EXPORTER_OTLP_ENDPOINT=otel-collector:4317 в docker)
 	OTelEnabled       bool
@@ -35,6 +37,11 @@ type Config struct {
 	DLQTopic               string // топик для dead letter queue
 	ConsumerGroupID        string
 
+	// Членство в consumer group (0 - дефолт kafka-go), см. platformkafka.ConsumerGroupConfig
+	ConsumerSessionTimeout    time.Duration // без heartbeat дольше - инстанс считается упавшим
+	ConsumerHeartbeatInterval time.Duration
+	ConsumerRebalanceTimeout  time.Duration // должен покрывать самую долгую сборку, чтобы она не обрабатывалась повторно
+
 	// Retry
 	RetryMaxAttempts int           // максимальное количество попыток
 	RetryBackoffBase time.Duration // базовый интервал для backoff
@@ -60,6 +67,16 @@ func Load() (Config, error) {
 	}
 	cfg.ShutdownTimeout = shutdownTimeout
 
+	// ASSEMBLY_DRAIN_TIMEOUT: отдельно от SHUTDOWN_TIMEOUT, сборка (~10s) дольше обычного таймаута закрытия ресурса
+	drainTimeout, err := time.ParseDuration(getString("ASSEMBLY_DRAIN_TIMEOUT", "30s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ASSEMBLY_DRAIN_TIMEOUT: %w", err)
+	}
+	cfg.DrainTimeout = drainTimeout
+
+	// DEBUG_ADDR (например 127.0.0.1:6060); по умолчанию отладочный сервер выключен
+	cfg.DebugAddr = getString("DEBUG_ADDR", "")
+
 	// Kafka Brokers
 	brokersStr := getString("KAFKA_BROKERS", "")
 	if brokersStr != "" {
@@ -90,6 +107,25 @@ func Load() (Config, error) {
 	cfg.DLQTopic = getString("KAFKA_ORDER_PAYMENT_COMPLETED_DLQ_TOPIC", "order.payment.completed.dlq")
 	cfg.ConsumerGroupID = getString("KAFKA_ASSEMBLY_CONSUMER_GROUP_ID", "assembly-service")
 
+	// Consumer group: сборка идёт до ~10s, rebalance timeout с запасом, чтобы сборка в работе успела закоммититься
+	sessionTimeout, err := time.ParseDuration(getString("KAFKA_CONSUMER_SESSION_TIMEOUT", "30s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid KAFKA_CONSUMER_SESSION_TIMEOUT: %w", err)
+	}
+	cfg.ConsumerSessionTimeout = sessionTimeout
+
+	heartbeatInterval, err := time.ParseDuration(getString("KAFKA_CONSUMER_HEARTBEAT_INTERVAL", "3s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid KAFKA_CONSUMER_HEARTBEAT_INTERVAL: %w", err)
+	}
+	cfg.ConsumerHeartbeatInterval = heartbeatInterval
+
+	rebalanceTimeout, err := time.ParseDuration(getString("KAFKA_CONSUMER_REBALANCE_TIMEOUT", "60s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid KAFKA_CONSUMER_REBALANCE_TIMEOUT: %w", err)
+	}
+	cfg.ConsumerRebalanceTimeout = rebalanceTimeout
+
 	// OpenTelemetry
 	cfg.OTelEnabled = getString("OTEL_ENABLED", "0") == "1" || getString("OTEL_ENABLED", "") == "true"
 	if cfg.AppEnv == EnvLocal {
@@ -132,6 +168,9 @@ func (c Config) Validate() error {
 	if c.ShutdownTimeout <= 0 {
 		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive")
 	}
+	if c.DrainTimeout <= 0 {
+		return fmt.Errorf("ASSEMBLY_DRAIN_TIMEOUT must be positive")
+	}
 	if len(c.KafkaBrokers) == 0 {
 		return fmt.Errorf("KAFKA_BROKERS is required")
 	}
@@ -147,6 +186,18 @@ func (c Config) Validate() error {
 	if c.DLQTopic == "" {
 		return fmt.Errorf("KAFKA_ORDER_PAYMENT_COMPLETED_DLQ_TOPIC is required")
 	}
+	if c.ConsumerSessionTimeout <= 0 {
+		return fmt.Errorf("KAFKA_CONSUMER_SESSION_TIMEOUT must be positive")
+	}
+	if c.ConsumerHeartbeatInterval <= 0 {
+		return fmt.Errorf("KAFKA_CONSUMER_HEARTBEAT_INTERVAL must be positive")
+	}
+	if c.ConsumerHeartbeatInterval >= c.ConsumerSessionTimeout {
+		return fmt.Errorf("KAFKA_CONSUMER_HEARTBEAT_INTERVAL must be less than KAFKA_CONSUMER_SESSION_TIMEOUT")
+	}
+	if c.ConsumerRebalanceTimeout <= 0 {
+		return fmt.Errorf("KAFKA_CONSUMER_REBALANCE_TIMEOUT must be positive")
+	}
 	if c.RetryMaxAttempts <= 0 {
 		return fmt.Errorf("KAFKA_RETRY_MAX_ATTEMPTS must be positive")
 	}
@@ -161,11 +212,16 @@ func (c Config) Log() {
 	log.Printf("Config loaded:")
 	log.Printf("  APP_ENV: %s", c.AppEnv)
 	log.Printf("  SHUTDOWN_TIMEOUT: %s", c.ShutdownTimeout)
+	log.Printf("  ASSEMBLY_DRAIN_TIMEOUT: %s", c.DrainTimeout)
+	log.Printf("  DEBUG_ADDR: %q", c.DebugAddr)
 	log.Printf("  KAFKA_BROKERS: %v", c.KafkaBrokers)
 	log.Printf("  KAFKA_ORDER_PAYMENT_COMPLETED_TOPIC: %s", c.PaymentCompletedTopic)
 	log.Printf("  KAFKA_ORDER_ASSEMBLY_COMPLETED_TOPIC: %s", c.AssemblyCompletedTopic)
 	log.Printf("  KAFKA_ORDER_PAYMENT_COMPLETED_DLQ_TOPIC: %s", c.DLQTopic)
 	log.Printf("  KAFKA_ASSEMBLY_CONSUMER_GROUP_ID: %s", c.ConsumerGroupID)
+	log.Printf("  KAFKA_CONSUMER_SESSION_TIMEOUT: %s", c.ConsumerSessionTimeout)
+	log.Printf("  KAFKA_CONSUMER_HEARTBEAT_INTERVAL: %s", c.ConsumerHeartbeatInterval)
+	log.Printf("  KAFKA_CONSUMER_REBALANCE_TIMEOUT: %s", c.ConsumerRebalanceTimeout)
 	log.Printf("  KAFKA_RETRY_MAX_ATTEMPTS: %d", c.RetryMaxAttempts)
 	log.Printf("  KAFKA_RETRY_BACKOFF_BASE: %s", c.RetryBackoffBase)
 }
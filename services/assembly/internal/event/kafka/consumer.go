@@ -2,12 +2,15 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	"github.com/shestoi/GoBigTech/services/assembly/internal/service"
 )
 
@@ -19,6 +22,12 @@ type OrderPaidConsumer struct {
 	dlqPublisher *DLQPublisher
 	maxAttempts  int
 	backoffBase  time.Duration
+
+	// Остановка для Drain: stopFetch прекращает чтение новых сообщений, abortWork прерывает сообщение в работе
+	mu        sync.Mutex
+	stopFetch context.CancelFunc
+	abortWork context.CancelFunc
+	done      chan struct{} // закрывается, когда Start вернулся
 }
 
 // NewOrderPaidConsumer создаёт новый consumer для событий оплаты заказа
@@ -26,6 +35,7 @@ func NewOrderPaidConsumer(
 	logger *zap.Logger,
 	brokers []string,
 	groupID, topic string,
+	group platformkafka.ConsumerGroupConfig, // таймауты членства в consumer group
 	svc *service.Service,
 	dlqPublisher *DLQPublisher,
 	maxAttempts int,
@@ -39,13 +49,15 @@ func NewOrderPaidConsumer(
 		backoffBase = 1 * time.Second
 	}
 
-	reader := kafka.NewReader(kafka.ReaderConfig{
+	readerConfig := kafka.ReaderConfig{
 		Brokers:  brokers,
 		GroupID:  groupID,
 		Topic:    topic,
 		MinBytes: 1,
 		MaxBytes: 10e6, // 10MB
-	})
+	}
+	group.Apply(&readerConfig)
+	reader := kafka.NewReader(readerConfig)
 
 	return &OrderPaidConsumer{
 		logger:       logger,
@@ -54,24 +66,40 @@ func NewOrderPaidConsumer(
 		dlqPublisher: dlqPublisher,
 		maxAttempts:  maxAttempts,
 		backoffBase:  backoffBase,
+		done:         make(chan struct{}),
 	}
 }
 
 // Start запускает consumer и начинает обработку сообщений
 // Использует at-least-once семантику: FetchMessage + CommitMessages после успешной обработки
+// Новые сообщения читаются до отмены ctx или вызова Drain; сообщение в работе прерывает только отмена ctx
+// или истёкший таймаут Drain
 func (c *OrderPaidConsumer) Start(ctx context.Context) error {
+	fetchCtx, stopFetch := context.WithCancel(ctx)
+	workCtx, abortWork := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.stopFetch, c.abortWork = stopFetch, abortWork
+	c.mu.Unlock()
+	defer func() {
+		stopFetch()
+		abortWork()
+		close(c.done)
+	}()
+
 	c.logger.Info("starting kafka consumer",
 		zap.String("topic", c.reader.Config().Topic),
 		zap.String("group_id", c.reader.Config().GroupID),
+		zap.Duration("session_timeout", c.reader.Config().SessionTimeout),
+		zap.Duration("rebalance_timeout", c.reader.Config().RebalanceTimeout),
 		zap.Int("max_retry_attempts", c.maxAttempts),
 	)
 
 	for { //бесконечный цикл для чтения сообщений из Kafka
 		// FetchMessage вместо ReadMessage для ручного контроля commit
-		m, err := c.reader.FetchMessage(ctx)
+		m, err := c.reader.FetchMessage(fetchCtx)
 		if err != nil {
-			// Если контекст отменён, выходим
-			if ctx.Err() != nil {
+			// Если контекст отменён или идёт Drain, выходим
+			if fetchCtx.Err() != nil {
 				c.logger.Info("consumer context cancelled, stopping")
 				return nil
 			}
@@ -83,11 +111,11 @@ func (c *OrderPaidConsumer) Start(ctx context.Context) error {
 		}
 
 		// Обрабатываем сообщение
-		shouldCommit := c.processMessage(ctx, m) //true, если нужно закоммитить offset (успешная обработка или отправка в DLQ)
+		shouldCommit := c.processMessage(workCtx, m) //true, если нужно закоммитить offset (успешная обработка или отправка в DLQ)
 
 		// Коммитим offset только после успешной обработки или отправки в DLQ
 		if shouldCommit {
-			if err := c.reader.CommitMessages(ctx, m); err != nil {
+			if err := c.reader.CommitMessages(workCtx, m); err != nil {
 				c.logger.Error("failed to commit message offset",
 					zap.Error(err),
 					zap.String("topic", m.Topic),
@@ -111,47 +139,50 @@ func (c *OrderPaidConsumer) Start(ctx context.Context) error {
 // processMessage обрабатывает одно сообщение из Kafka
 // Возвращает true, если нужно закоммитить offset (успешная обработка или отправка в DLQ)
 func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
-	// Парсим JSON сообщение
-	var payload map[string]interface{}
-	if err := json.Unmarshal(m.Value, &payload); err != nil {
-		c.logger.Error("failed to unmarshal kafka message - sending to DLQ",
-			zap.Error(err),
-			zap.String("topic", m.Topic),
+	// Продолжаем trace из заголовков сообщения (order outbox -> assembly)
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	// Маршрутизация по заголовку event_type: чужие события пропускаем, не разбирая body
+	if eventType := headers.EventType(); eventType != "" && eventType != eventTypeOrderPaymentCompleted {
+		c.logger.Debug("skipping message with unexpected event_type",
+			zap.String("event_type", eventType),
+			zap.String("event_id", headers.EventID()),
 			zap.Int("partition", m.Partition),
 			zap.Int64("offset", m.Offset),
 		)
+		return true
+	}
 
-		// Отправляем в DLQ и коммитим (poison pill)
-		if err := c.dlqPublisher.Publish(ctx, m, err, "", "", ""); err != nil {
-			c.logger.Error("failed to send message to DLQ",
-				zap.Error(err),
-				zap.String("topic", m.Topic),
+	// Дедупликация по заголовку event_id до разбора body; при ошибке store решение принимает HandleOrderPaid
+	if eventID := headers.EventID(); eventID != "" {
+		if processed, err := c.service.IsEventProcessed(ctx, eventID); err == nil && processed {
+			c.logger.Info("event already processed, skipping",
+				zap.String("event_id", eventID),
 				zap.Int("partition", m.Partition),
 				zap.Int64("offset", m.Offset),
 			)
-			// Не коммитим, если не удалось отправить в DLQ
-			return false
+			return true
 		}
-
-		return true // Коммитим после отправки в DLQ
 	}
 
-	// Преобразуем payload в OrderPaidEvent
-	event, err := c.parseOrderPaidEvent(payload)
+	// Разбираем и проверяем схему события: все нарушения сразу попадают в DLQ (poison pill)
+	event, payload, err := decodeOrderPaidEvent(m.Value)
 	if err != nil {
-		c.logger.Error("failed to parse order paid event - sending to DLQ",
+		c.logger.Error("invalid order paid event - sending to DLQ",
 			zap.Error(err),
 			zap.String("topic", m.Topic),
 			zap.Int("partition", m.Partition),
 			zap.Int64("offset", m.Offset),
 		)
 
-		// Извлекаем event_type и event_id для DLQ
+		// Извлекаем event_type, event_id и order_id для DLQ, если они есть в сообщении
 		eventType, _ := payload["event_type"].(string)
 		eventID, _ := payload["event_id"].(string)
 		orderID, _ := payload["order_id"].(string)
 
-		// Отправляем в DLQ и коммитим (poison pill)
+		// Отправляем в DLQ и коммитим
 		if err := c.dlqPublisher.Publish(ctx, m, err, eventType, eventID, orderID); err != nil {
 			c.logger.Error("failed to send message to DLQ",
 				zap.Error(err),
@@ -159,6 +190,7 @@ func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message)
 				zap.Int("partition", m.Partition),
 				zap.Int64("offset", m.Offset),
 			)
+			// Не коммитим, если не удалось отправить в DLQ
 			return false
 		}
 
@@ -176,6 +208,17 @@ func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message)
 	// Пытаемся обработать событие с retry
 	success := c.handleWithRetry(ctx, m, event)
 
+	if !success && ctx.Err() != nil {
+		// Обработку прервал shutdown (истёк таймаут Drain): это не отказ события, в DLQ не отправляем.
+		// Offset не коммитится, после рестарта сообщение прочитается снова
+		c.logger.Warn("order paid event processing interrupted by shutdown, offset not committed",
+			zap.String("order_id", event.OrderID),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return false
+	}
+
 	if !success {
 		// После исчерпания retry отправляем в DLQ
 		c.logger.Error("failed to handle order paid event after all retries - sending to DLQ",
@@ -190,7 +233,11 @@ func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message)
 			OrderID: event.OrderID,
 		}
 
-		if err := c.dlqPublisher.Publish(ctx, m, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
+		// retry_count в заголовках DLQ-сообщения - сколько попыток обработки было сделано
+		dlqMsg := m
+		dlqMsg.Headers = headers.WithRetryCount(c.maxAttempts).Kafka()
+
+		if err := c.dlqPublisher.Publish(ctx, dlqMsg, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
 			c.logger.Error("failed to send message to DLQ",
 				zap.Error(err),
 				zap.String("topic", m.Topic),
@@ -276,53 +323,28 @@ func (e *ProcessingError) Error() string {
 	return e.Message
 }
 
-// parseOrderPaidEvent преобразует payload в OrderPaidEvent
-func (c *OrderPaidConsumer) parseOrderPaidEvent(payload map[string]interface{}) (service.OrderPaidEvent, error) {
-	event := service.OrderPaidEvent{}
-
-	// Извлекаем поля из payload
-	if v, ok := payload["event_id"].(string); ok {
-		event.EventID = v
-	}
-	if v, ok := payload["event_type"].(string); ok {
-		event.EventType = v
-	}
-	if v, ok := payload["event_version"].(float64); ok {
-		event.EventVersion = int(v)
-	}
-	if v, ok := payload["occurred_at"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, v); err == nil {
-			event.OccurredAt = t
-		}
-	}
-	if v, ok := payload["order_id"].(string); ok {
-		event.OrderID = v
-	} else {
-		return event, &ParseError{Field: "order_id", Message: "order_id is required"}
-	}
-	if v, ok := payload["user_id"].(string); ok {
-		event.UserID = v
-	} else {
-		return event, &ParseError{Field: "user_id", Message: "user_id is required"}
-	}
-	if v, ok := payload["amount"].(float64); ok {
-		event.Amount = int64(v)
-	}
-	if v, ok := payload["payment_method"].(string); ok {
-		event.PaymentMethod = v
+// Drain останавливает чтение новых сообщений и ждёт, пока сообщение в работе обработается и закоммитится
+// Если ctx истёк раньше, обработка прерывается без коммита offset: после рестарта сообщение прочитается снова,
+// а повтор безопасен благодаря idempotency по event_id. Вызывается при shutdown до Close
+func (c *OrderPaidConsumer) Drain(ctx context.Context) error {
+	c.mu.Lock()
+	stopFetch, abortWork := c.stopFetch, c.abortWork
+	c.mu.Unlock()
+	if stopFetch == nil {
+		return nil // Start не запускался
 	}
 
-	return event, nil
-}
-
-// ParseError представляет ошибку парсинга события
-type ParseError struct {
-	Field   string
-	Message string
-}
-
-func (e *ParseError) Error() string {
-	return e.Message
+	c.logger.Info("draining kafka consumer")
+	stopFetch()
+	select {
+	case <-c.done:
+		c.logger.Info("kafka consumer drained")
+		return nil
+	case <-ctx.Done():
+		abortWork()
+		<-c.done
+		return fmt.Errorf("drain timeout exceeded, in-flight message aborted: %w", ctx.Err())
+	}
 }
 
 // Close закрывает Kafka reader
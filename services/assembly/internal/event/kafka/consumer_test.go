@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+)
+
+func TestOrderPaidConsumer_Drain(t *testing.T) {
+	newConsumer := func() *OrderPaidConsumer {
+		// Брокер недоступен: FetchMessage ждёт сообщений, пока не отменят контекст
+		consumer := NewOrderPaidConsumer(zap.NewNop(), []string{"127.0.0.1:1"}, "assembly-test", "order.payment.completed",
+			platformkafka.ConsumerGroupConfig{}, nil, nil, 1, time.Millisecond)
+		t.Cleanup(func() { _ = consumer.Close() })
+		return consumer
+	}
+
+	t.Run("not started", func(t *testing.T) {
+		require.NoError(t, newConsumer().Drain(context.Background()))
+	})
+
+	t.Run("idle consumer stops fetching", func(t *testing.T) {
+		consumer := newConsumer()
+		started := make(chan error, 1)
+		go func() { started <- consumer.Start(context.Background()) }()
+
+		require.Eventually(t, func() bool {
+			consumer.mu.Lock()
+			defer consumer.mu.Unlock()
+			return consumer.stopFetch != nil
+		}, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, consumer.Drain(ctx))
+		require.NoError(t, <-started)
+	})
+}
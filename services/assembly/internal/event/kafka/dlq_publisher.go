@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -22,6 +23,8 @@ type DLQMessage struct {
 	EventType         string `json:"event_type"`         // если удалось извлечь тип события
 	EventID           string `json:"event_id"`           // если удалось извлечь ID события
 	OrderID           string `json:"order_id"`           // если удалось извлечь ID заказа
+	// ValidationErrors - все нарушения схемы, если сообщение отклонено валидацией (poison pill)
+	ValidationErrors []FieldError `json:"validation_errors,omitempty"`
 }
 
 // DLQPublisher публикует сообщения в Dead Letter Queue
@@ -67,6 +70,10 @@ func (p *DLQPublisher) Publish(ctx context.Context, msg kafka.Message, err error
 		EventID:           eventID,
 		OrderID:           orderID,
 	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		dlqMsg.ValidationErrors = validationErr.Errors
+	}
 
 	// Сериализуем в JSON
 	valueBytes, err := json.Marshal(dlqMsg)
@@ -88,8 +95,9 @@ func (p *DLQPublisher) Publish(ctx context.Context, msg kafka.Message, err error
 
 	// Отправляем в DLQ
 	kafkaMsg := kafka.Message{
-		Key:   key,
-		Value: valueBytes,
+		Key:     key,
+		Value:   valueBytes,
+		Headers: msg.Headers, // сохраняем заголовки исходного сообщения (trace context, event_type, event_id, retry_count)
 	}
 
 	if err := p.writer.WriteMessages(ctx, kafkaMsg); err != nil {
@@ -109,6 +117,7 @@ func (p *DLQPublisher) Publish(ctx context.Context, msg kafka.Message, err error
 		zap.Int("original_partition", msg.Partition),
 		zap.Int64("original_offset", msg.Offset),
 		zap.String("error", errorMsg),
+		zap.Int("validation_errors", len(dlqMsg.ValidationErrors)),
 	)
 
 	return nil
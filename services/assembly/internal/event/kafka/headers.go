@@ -0,0 +1,8 @@
+package kafka
+
+// tracerName - имя сервиса для span публикации и обработки сообщений
+const tracerName = "assembly"
+
+// eventTypeOrderPaymentCompleted - тип события, который читает OrderPaidConsumer
+// Сообщения с другим event_type в заголовке (например, order.payment.declined) пропускаются без разбора body
+const eventTypeOrderPaymentCompleted = "order.payment.completed"
@@ -9,6 +9,8 @@ import (
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	"github.com/shestoi/GoBigTech/services/assembly/internal/service"
 )
 
@@ -41,20 +43,33 @@ func (p *KafkaAssemblyEventPublisher) Close() error {
 
 // PublishOrderAssemblyCompleted публикует событие успешной сборки заказа в Kafka
 func (p *KafkaAssemblyEventPublisher) PublishOrderAssemblyCompleted(ctx context.Context, event service.OrderAssemblyCompletedEvent) error {
-	// Генерируем event_id, если он не задан
+	// Service задаёт детерминированный event_id; случайный - только если его нет
 	eventID := event.EventID
 	if eventID == "" {
 		eventID = uuid.New().String() //генерируем уникальный ID для события
 	}
 
+	items := make([]map[string]interface{}, 0, len(event.Items))
+	for _, item := range event.Items {
+		items = append(items, map[string]interface{}{
+			"product_id": item.ProductID,
+			"quantity":   item.Quantity,
+			"status":     item.Status,
+		})
+	}
+
 	// Формируем JSON payload события
 	payload := map[string]interface{}{
-		"event_id":      eventID,
-		"event_type":    event.EventType,
-		"event_version": event.EventVersion,
-		"occurred_at":   event.OccurredAt.Format(time.RFC3339),
-		"order_id":      event.OrderID,
-		"user_id":       event.UserID,
+		"event_id":       eventID,
+		"event_type":     event.EventType,
+		"event_version":  event.EventVersion,
+		"occurred_at":    event.OccurredAt.Format(time.RFC3339),
+		"order_id":       event.OrderID,
+		"user_id":        event.UserID,
+		"amount":         event.Amount,
+		"currency":       event.Currency,
+		"payment_method": event.PaymentMethod,
+		"items":          items,
 	}
 
 	valueBytes, err := json.Marshal(payload) //преобразуем данные события в JSON
@@ -66,10 +81,18 @@ func (p *KafkaAssemblyEventPublisher) PublishOrderAssemblyCompleted(ctx context.
 		return err
 	}
 
+	// Span публикации - дочерний к обработке order.payment.completed, его контекст уходит в заголовки
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, p.topic)
+	defer span.End()
+
 	// Отправляем сообщение в Kafka
+	// Ключ - event_id: повторы одного события получают одинаковый ключ и попадают в одну партицию,
+	// где consumer видит их подряд и отбрасывает по inbox. На заказ приходится одно событие сборки,
+	// поэтому порядок событий заказа от смены ключа не зависит
 	message := kafka.Message{
-		Key:   []byte(event.OrderID),
-		Value: valueBytes,
+		Key:     []byte(eventID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, event.EventType, eventID, event.EventVersion).Kafka(),
 	}
 
 	err = p.writer.WriteMessages(ctx, message)
@@ -0,0 +1,176 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/assembly/internal/service"
+)
+
+// Виды нарушений схемы события
+const (
+	ProblemMissing      = "missing"       // обязательное поле отсутствует или пустое
+	ProblemInvalidType  = "invalid_type"  // поле другого JSON-типа
+	ProblemInvalidValue = "invalid_value" // тип верный, значение недопустимо
+	ProblemMalformed    = "malformed"     // тело сообщения не JSON-объект
+)
+
+// FieldError - нарушение схемы в одном поле события
+type FieldError struct {
+	Field   string `json:"field"`   // путь к полю: order_id, items[1].quantity; $ - всё сообщение
+	Problem string `json:"problem"` // Problem*
+	Message string `json:"message"`
+}
+
+// ValidationError - все нарушения схемы события, найденные за один проход
+// Попадает в DLQMessage.ValidationErrors, чтобы при разборе DLQ было видно каждую причину отказа
+type ValidationError struct {
+	EventType string
+	Errors    []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return fmt.Sprintf("invalid %s event: %s", e.EventType, strings.Join(parts, "; "))
+}
+
+// eventValidator накапливает нарушения схемы вместо выхода на первом
+type eventValidator struct {
+	errors []FieldError
+}
+
+func (v *eventValidator) add(field, problem, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Field: field, Problem: problem, Message: fmt.Sprintf(format, args...)})
+}
+
+// str читает строковое поле obj[key]; path - полный путь поля для отчёта
+func (v *eventValidator) str(obj map[string]interface{}, key, path string, required bool) (string, bool) {
+	raw, ok := obj[key]
+	if !ok || raw == nil {
+		if required {
+			v.add(path, ProblemMissing, "%s is required", key)
+		}
+		return "", false
+	}
+	s, ok := raw.(string)
+	if !ok {
+		v.add(path, ProblemInvalidType, "%s must be a string", key)
+		return "", false
+	}
+	if required && strings.TrimSpace(s) == "" {
+		v.add(path, ProblemMissing, "%s must not be empty", key)
+		return "", false
+	}
+	return s, true
+}
+
+// integer читает целочисленное поле obj[key] (в JSON все числа - float64)
+func (v *eventValidator) integer(obj map[string]interface{}, key, path string, required bool) (int64, bool) {
+	raw, ok := obj[key]
+	if !ok || raw == nil {
+		if required {
+			v.add(path, ProblemMissing, "%s is required", key)
+		}
+		return 0, false
+	}
+	f, ok := raw.(float64)
+	if !ok {
+		v.add(path, ProblemInvalidType, "%s must be a number", key)
+		return 0, false
+	}
+	if f != math.Trunc(f) || math.Abs(f) > math.MaxInt64 {
+		v.add(path, ProblemInvalidValue, "%s must be an integer, got %v", key, f)
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// decodeOrderPaidEvent разбирает тело события order.payment.completed и проверяет его схему
+// Возвращает *ValidationError со всеми нарушениями сразу; payload (может быть nil) нужен,
+// чтобы извлечь event_id/order_id для DLQ даже из невалидного события
+func decodeOrderPaidEvent(value []byte) (service.OrderPaidEvent, map[string]interface{}, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(value, &payload); err != nil || payload == nil {
+		message := "message body must be a JSON object"
+		if err != nil {
+			message = err.Error()
+		}
+		return service.OrderPaidEvent{}, nil, &ValidationError{
+			EventType: eventTypeOrderPaymentCompleted,
+			Errors:    []FieldError{{Field: "$", Problem: ProblemMalformed, Message: message}},
+		}
+	}
+
+	v := &eventValidator{}
+	event := service.OrderPaidEvent{}
+
+	// Конверт: общий для всех событий Order
+	event.EventID, _ = v.str(payload, "event_id", "event_id", true)
+	if eventType, ok := v.str(payload, "event_type", "event_type", true); ok {
+		if eventType != eventTypeOrderPaymentCompleted {
+			v.add("event_type", ProblemInvalidValue, "event_type must be %q, got %q", eventTypeOrderPaymentCompleted, eventType)
+		}
+		event.EventType = eventType
+	}
+	if version, ok := v.integer(payload, "event_version", "event_version", true); ok {
+		if version < 1 {
+			v.add("event_version", ProblemInvalidValue, "event_version must be positive, got %d", version)
+		}
+		event.EventVersion = int(version)
+	}
+	if occurredAt, ok := v.str(payload, "occurred_at", "occurred_at", true); ok {
+		t, err := time.Parse(time.RFC3339, occurredAt)
+		if err != nil {
+			v.add("occurred_at", ProblemInvalidValue, "occurred_at must be RFC3339, got %q", occurredAt)
+		}
+		event.OccurredAt = t
+	}
+
+	// Данные оплаты
+	event.OrderID, _ = v.str(payload, "order_id", "order_id", true)
+	event.UserID, _ = v.str(payload, "user_id", "user_id", true)
+	if amount, ok := v.integer(payload, "amount", "amount", false); ok {
+		if amount < 0 {
+			v.add("amount", ProblemInvalidValue, "amount must not be negative, got %d", amount)
+		}
+		event.Amount = amount
+	}
+	event.Currency, _ = v.str(payload, "currency", "currency", false)
+	event.PaymentMethod, _ = v.str(payload, "payment_method", "payment_method", false)
+
+	// items необязательны: события, опубликованные до их появления, обрабатываются без состава заказа
+	if raw, ok := payload["items"]; ok && raw != nil {
+		items, ok := raw.([]interface{})
+		if !ok {
+			v.add("items", ProblemInvalidType, "items must be an array")
+		}
+		for i, rawItem := range items {
+			path := fmt.Sprintf("items[%d]", i)
+			item, ok := rawItem.(map[string]interface{})
+			if !ok {
+				v.add(path, ProblemInvalidType, "item must be an object")
+				continue
+			}
+			productID, _ := v.str(item, "product_id", path+".product_id", true)
+			quantity, ok := v.integer(item, "quantity", path+".quantity", true)
+			if ok && (quantity <= 0 || quantity > math.MaxInt32) {
+				v.add(path+".quantity", ProblemInvalidValue, "quantity must be in [1, %d], got %d", math.MaxInt32, quantity)
+			}
+			event.Items = append(event.Items, service.OrderItem{
+				ProductID: productID,
+				Quantity:  int32(quantity),
+			})
+		}
+	}
+
+	if len(v.errors) > 0 {
+		return event, payload, &ValidationError{EventType: eventTypeOrderPaymentCompleted, Errors: v.errors}
+	}
+	return event, payload, nil
+}
@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOrderPaidEvent_Valid(t *testing.T) {
+	value := []byte(`{
+		"event_id": "evt-1",
+		"event_type": "order.payment.completed",
+		"event_version": 1,
+		"occurred_at": "2024-05-01T10:00:00Z",
+		"order_id": "order-1",
+		"user_id": "user-1",
+		"amount": 1500,
+		"currency": "RUB",
+		"payment_method": "card",
+		"items": [{"product_id": "p-1", "quantity": 2}]
+	}`)
+
+	event, payload, err := decodeOrderPaidEvent(value)
+	require.NoError(t, err)
+	assert.NotNil(t, payload)
+	assert.Equal(t, "evt-1", event.EventID)
+	assert.Equal(t, 1, event.EventVersion)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), event.OccurredAt)
+	assert.Equal(t, "order-1", event.OrderID)
+	assert.Equal(t, int64(1500), event.Amount)
+	require.Len(t, event.Items, 1)
+	assert.Equal(t, "p-1", event.Items[0].ProductID)
+	assert.Equal(t, int32(2), event.Items[0].Quantity)
+}
+
+func TestDecodeOrderPaidEvent_ReportsAllErrors(t *testing.T) {
+	value := []byte(`{
+		"event_id": "evt-1",
+		"event_type": "order.created",
+		"event_version": "1",
+		"order_id": "",
+		"amount": 10.5,
+		"items": [{"product_id": "p-1", "quantity": 0}, "p-2", {"quantity": 1}]
+	}`)
+
+	_, payload, err := decodeOrderPaidEvent(value)
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []FieldError{
+		{Field: "event_type", Problem: ProblemInvalidValue, Message: `event_type must be "order.payment.completed", got "order.created"`},
+		{Field: "event_version", Problem: ProblemInvalidType, Message: "event_version must be a number"},
+		{Field: "occurred_at", Problem: ProblemMissing, Message: "occurred_at is required"},
+		{Field: "order_id", Problem: ProblemMissing, Message: "order_id must not be empty"},
+		{Field: "user_id", Problem: ProblemMissing, Message: "user_id is required"},
+		{Field: "amount", Problem: ProblemInvalidValue, Message: "amount must be an integer, got 10.5"},
+		{Field: "items[0].quantity", Problem: ProblemInvalidValue, Message: "quantity must be in [1, 2147483647], got 0"},
+		{Field: "items[1]", Problem: ProblemInvalidType, Message: "item must be an object"},
+		{Field: "items[2].product_id", Problem: ProblemMissing, Message: "product_id is required"},
+	}, validationErr.Errors)
+
+	// event_id доступен для DLQ, хотя событие невалидно
+	assert.Equal(t, "evt-1", payload["event_id"])
+}
+
+func TestDecodeOrderPaidEvent_Malformed(t *testing.T) {
+	for _, value := range []string{`{not json`, `null`, `[1, 2]`} {
+		_, payload, err := decodeOrderPaidEvent([]byte(value))
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), value)
+		require.Len(t, validationErr.Errors, 1)
+		assert.Equal(t, "$", validationErr.Errors[0].Field)
+		assert.Equal(t, ProblemMalformed, validationErr.Errors[0].Problem)
+		assert.Nil(t, payload)
+	}
+}
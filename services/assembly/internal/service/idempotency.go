@@ -6,8 +6,6 @@ import (
 )
 
 // ProcessedEventsStore хранит информацию об обработанных событиях для обеспечения idempotency
-//
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ProcessedEventsStore --dir=. --output=./mocks --outpkg=mocks
 type ProcessedEventsStore interface {
 	// MarkProcessed сохраняет eventID как обработанный. Должен быть idempotent сам по себе.
 	// ttl определяет время жизни записи (после истечения ttl событие может быть обработано повторно).
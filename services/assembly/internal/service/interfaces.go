@@ -13,18 +13,39 @@ type OrderPaidEvent struct {
 	OccurredAt    time.Time
 	OrderID       string
 	UserID        string
-	Amount        int64
+	Amount        int64  // в минимальных единицах валюты
+	Currency      string // код валюты ISO 4217
 	PaymentMethod string
+	Items         []OrderItem
+}
+
+// Статусы позиций в событии завершения сборки (Order обновляет по ним статус каждой позиции)
+const (
+	ItemStatusAssembled = "assembled" // позиция собрана
+	ItemStatusCancelled = "cancelled" // позиция не собрана и отменена
+)
+
+// OrderItem представляет позицию заказа в событиях
+type OrderItem struct {
+	ProductID string
+	Quantity  int32
+	Status    string // статус позиции после сборки (ItemStatus*); во входящем событии оплаты пустой
 }
 
 // OrderAssemblyCompletedEvent представляет событие завершения сборки заказа (исходящее в Kafka)
+// Amount, Currency, PaymentMethod и Items переносятся из события оплаты,
+// чтобы notification мог сформировать подробное сообщение без дополнительного запроса к Order
 type OrderAssemblyCompletedEvent struct {
-	EventID      string
-	EventType    string // "order.assembly.completed"
-	EventVersion int
-	OccurredAt   time.Time
-	OrderID      string
-	UserID       string
+	EventID       string
+	EventType     string // "order.assembly.completed"
+	EventVersion  int
+	OccurredAt    time.Time
+	OrderID       string
+	UserID        string
+	Amount        int64
+	Currency      string
+	PaymentMethod string
+	Items         []OrderItem
 }
 
 // AssemblyEventPublisher определяет интерфейс для публикации событий завершения сборки заказа
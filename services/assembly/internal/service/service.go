@@ -5,12 +5,24 @@ import (
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // ErrEventIDRequired возвращается когда event_id отсутствует в событии
 var ErrEventIDRequired = errors.New("event_id is required")
 
+// assemblyEventNamespace - пространство имён UUID v5 для event_id событий order.assembly.completed
+var assemblyEventNamespace = uuid.MustParse("6f1c3a52-8d4e-4b7a-9f20-3c5e8a1d7b64")
+
+// AssemblyCompletedEventID возвращает event_id события order.assembly.completed для события оплаты
+// ID детерминирован по order_id и event_id оплаты: повторная обработка того же события оплаты
+// (retry публикации, replay из DLQ, истёкший TTL processed store) даёт событие с тем же event_id,
+// и inbox Order отбрасывает его как дубликат
+func AssemblyCompletedEventID(orderID, paidEventID string) string {
+	return uuid.NewSHA1(assemblyEventNamespace, []byte(orderID+"/"+paidEventID)).String()
+}
+
 // AssemblyMetricsRecorder записывает метрики сборки (опционально, может быть nil).
 type AssemblyMetricsRecorder interface {
 	RecordAssemblyDuration(d time.Duration, result string)
@@ -50,6 +62,15 @@ func NewServiceWithSleeper(logger *zap.Logger, publisher AssemblyEventPublisher,
 	}
 }
 
+// IsEventProcessed проверяет, обработано ли уже событие с eventID
+// Consumer вызывает его по заголовку event_id до разбора body, чтобы отбросить дубликат без десериализации
+func (s *Service) IsEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	if eventID == "" {
+		return false, ErrEventIDRequired
+	}
+	return s.store.IsProcessed(ctx, eventID)
+}
+
 // HandleOrderPaid обрабатывает событие успешной оплаты заказа
 // Имитирует сборку заказа (ждёт 10 секунд) и публикует событие завершения сборки
 // Обеспечивает idempotency: если событие с тем же event_id уже обработано, не выполняет side-effect повторно
@@ -100,14 +121,25 @@ func (s *Service) HandleOrderPaid(ctx context.Context, event OrderPaidEvent) err
 
 	s.logger.Info("order assembly completed", zap.String("order_id", event.OrderID))
 
+	// Сборка имитируется целиком: все позиции собраны
+	assembledItems := make([]OrderItem, 0, len(event.Items))
+	for _, item := range event.Items {
+		item.Status = ItemStatusAssembled
+		assembledItems = append(assembledItems, item)
+	}
+
 	// Формируем событие завершения сборки
 	assemblyEvent := OrderAssemblyCompletedEvent{
-		EventID:      "", // будет сгенерирован в publisher
-		EventType:    "order.assembly.completed",
-		EventVersion: 1,
-		OccurredAt:   time.Now().UTC(),
-		OrderID:      event.OrderID,
-		UserID:       event.UserID,
+		EventID:       AssemblyCompletedEventID(event.OrderID, event.EventID),
+		EventType:     "order.assembly.completed",
+		EventVersion:  1,
+		OccurredAt:    time.Now().UTC(),
+		OrderID:       event.OrderID,
+		UserID:        event.UserID,
+		Amount:        event.Amount,
+		Currency:      event.Currency,
+		PaymentMethod: event.PaymentMethod,
+		Items:         assembledItems,
 	}
 
 	// Публикуем событие (side-effect)
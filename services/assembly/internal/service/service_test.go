@@ -188,3 +188,109 @@ func TestService_HandleOrderPaid_PublisherError(t *testing.T) {
 	mockPublisher.AssertExpectations(t)
 	mockStore.AssertExpectations(t)
 }
+
+func TestService_HandleOrderPaid_PropagatesPaymentDetails(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	mockPublisher := new(MockAssemblyEventPublisher)
+	mockStore := new(MockProcessedEventsStore)
+	mockSleeper := &MockSleeper{}
+
+	svc := NewServiceWithSleeper(logger, mockPublisher, mockStore, mockSleeper, 24*time.Hour, nil)
+
+	items := []OrderItem{
+		{ProductID: "product-1", Quantity: 2},
+		{ProductID: "product-2", Quantity: 1},
+	}
+	event := OrderPaidEvent{
+		EventID:       "evt-1",
+		EventType:     "order.payment.completed",
+		OrderID:       "order-123",
+		UserID:        "user-456",
+		Amount:        30000,
+		Currency:      "USD",
+		PaymentMethod: "card",
+		Items:         items,
+	}
+
+	mockStore.On("IsProcessed", ctx, "evt-1").Return(false, nil).Once()
+	mockPublisher.On("PublishOrderAssemblyCompleted", ctx, mock.MatchedBy(func(e OrderAssemblyCompletedEvent) bool {
+		return e.Amount == 30000 &&
+			e.Currency == "USD" &&
+			e.PaymentMethod == "card" &&
+			assert.ObjectsAreEqual([]OrderItem{
+				{ProductID: "product-1", Quantity: 2, Status: ItemStatusAssembled},
+				{ProductID: "product-2", Quantity: 1, Status: ItemStatusAssembled},
+			}, e.Items)
+	})).Return(nil).Once()
+	mockStore.On("MarkProcessed", ctx, "evt-1", 24*time.Hour).Return(nil).Once()
+
+	err := svc.HandleOrderPaid(ctx, event)
+	assert.NoError(t, err)
+
+	mockPublisher.AssertExpectations(t)
+	mockStore.AssertExpectations(t)
+}
+
+func TestService_HandleOrderPaid_DeterministicEventID(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	mockPublisher := new(MockAssemblyEventPublisher)
+	mockStore := new(MockProcessedEventsStore)
+	svc := NewServiceWithSleeper(logger, mockPublisher, mockStore, &MockSleeper{}, 24*time.Hour, nil)
+
+	event := OrderPaidEvent{
+		EventID: "evt-1",
+		OrderID: "order-123",
+		UserID:  "user-456",
+	}
+	expectedID := AssemblyCompletedEventID("order-123", "evt-1")
+
+	// Повторная обработка того же события оплаты (например, replay из DLQ после истечения TTL)
+	// публикует событие сборки с тем же event_id
+	mockStore.On("IsProcessed", ctx, "evt-1").Return(false, nil).Twice()
+	mockPublisher.On("PublishOrderAssemblyCompleted", ctx, mock.MatchedBy(func(e OrderAssemblyCompletedEvent) bool {
+		return e.EventID == expectedID
+	})).Return(nil).Twice()
+	mockStore.On("MarkProcessed", ctx, "evt-1", 24*time.Hour).Return(nil).Twice()
+
+	assert.NoError(t, svc.HandleOrderPaid(ctx, event))
+	assert.NoError(t, svc.HandleOrderPaid(ctx, event))
+
+	mockPublisher.AssertExpectations(t)
+	mockStore.AssertExpectations(t)
+}
+
+func TestAssemblyCompletedEventID(t *testing.T) {
+	id := AssemblyCompletedEventID("order-1", "evt-1")
+
+	assert.Equal(t, id, AssemblyCompletedEventID("order-1", "evt-1"))
+	assert.NotEqual(t, id, AssemblyCompletedEventID("order-1", "evt-2"))
+	assert.NotEqual(t, id, AssemblyCompletedEventID("order-2", "evt-1"))
+	assert.NotEqual(t, id, "evt-1")
+}
+
+func TestService_IsEventProcessed(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	mockStore := new(MockProcessedEventsStore)
+	svc := NewServiceWithSleeper(logger, new(MockAssemblyEventPublisher), mockStore, &MockSleeper{}, 24*time.Hour, nil)
+
+	t.Run("delegates to store", func(t *testing.T) {
+		mockStore.On("IsProcessed", ctx, "evt-1").Return(true, nil).Once()
+
+		processed, err := svc.IsEventProcessed(ctx, "evt-1")
+		assert.NoError(t, err)
+		assert.True(t, processed)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("empty event_id", func(t *testing.T) {
+		processed, err := svc.IsEventProcessed(ctx, "")
+		assert.ErrorIs(t, err, ErrEventIDRequired)
+		assert.False(t, processed)
+	})
+}
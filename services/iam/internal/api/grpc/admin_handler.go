@@ -0,0 +1,66 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/service"
+	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
+)
+
+// AdminHandler содержит gRPC-обработчики административных операций IAM
+// Регистрируется только на служебном gRPC сервере (ADMIN_GRPC_ADDR), который не публикуется наружу
+type AdminHandler struct {
+	iampb.UnimplementedIAMAdminServiceServer
+	iamService *service.Service
+	logger     *zap.Logger
+}
+
+// NewAdminHandler создаёт новый gRPC handler административных операций
+func NewAdminHandler(iamService *service.Service, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		iamService: iamService,
+		logger:     logger,
+	}
+}
+
+// DeleteUser обрабатывает gRPC запрос DeleteUser
+func (h *AdminHandler) DeleteUser(ctx context.Context, req *iampb.DeleteUserRequest) (*iampb.DeleteUserResponse, error) {
+	// Валидация входных данных
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.GetRequestedBy() == "" {
+		return nil, status.Error(codes.InvalidArgument, "requested_by is required")
+	}
+
+	// Вызываем service слой
+	result, err := h.iamService.DeleteUser(ctx, service.DeleteUserInput{
+		UserID:            req.GetUserId(),
+		RequestedBy:       req.GetRequestedBy(),
+		Reason:            req.GetReason(),
+		LegalHoldOverride: req.GetLegalHoldOverride(),
+	})
+
+	if err != nil {
+		// Маппим ошибки в gRPC status
+		if errors.Is(err, service.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, service.ErrUserUnderLegalHold) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		h.logger.Error("failed to delete user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &iampb.DeleteUserResponse{
+		UserId:          result.UserID,
+		RevokedSessions: int32(result.RevokedSessions),
+		AlreadyDeleted:  result.AlreadyDeleted,
+	}, nil
+}
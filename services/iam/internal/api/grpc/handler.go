@@ -49,10 +49,15 @@ func (h *Handler) Register(ctx context.Context, req *iampb.RegisterRequest) (*ia
 		Login:      req.GetLogin(),
 		Password:   req.GetPassword(),
 		TelegramID: telegramID,
+		Locale:     req.GetLocale(),
+		Timezone:   req.GetTimezone(),
 	})
 
 	if err != nil {
 		// Маппим ошибки в gRPC status
+		if errors.Is(err, service.ErrInvalidLocale) || errors.Is(err, service.ErrInvalidTimezone) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		if err.Error() == "user with login "+req.GetLogin()+" already exists" {
 			return nil, status.Error(codes.AlreadyExists, err.Error())
 		}
@@ -163,6 +168,8 @@ func (h *Handler) GetUserContact(ctx context.Context, req *iampb.GetUserContactR
 
 	response := &iampb.GetUserContactResponse{
 		PreferredChannel: result.PreferredChannel,
+		Locale:           result.Locale,
+		Timezone:         result.Timezone,
 	}
 	if result.TelegramID != nil {
 		response.TelegramId = result.TelegramID
@@ -171,6 +178,38 @@ func (h *Handler) GetUserContact(ctx context.Context, req *iampb.GetUserContactR
 	return response, nil
 }
 
+// UpdateProfile обрабатывает gRPC запрос UpdateProfile
+func (h *Handler) UpdateProfile(ctx context.Context, req *iampb.UpdateProfileRequest) (*iampb.UpdateProfileResponse, error) {
+	// Валидация входных данных
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Вызываем service слой; незаданные (nil) поля не меняются
+	result, err := h.iamService.UpdateProfile(ctx, service.UpdateProfileInput{
+		UserID:   req.GetUserId(),
+		Locale:   req.Locale,
+		Timezone: req.Timezone,
+	})
+
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidLocale) || errors.Is(err, service.ErrInvalidTimezone) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.logger.Error("failed to update user profile", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &iampb.UpdateProfileResponse{
+		UserId:   result.UserID,
+		Locale:   result.Locale,
+		Timezone: result.Timezone,
+	}, nil
+}
+
 // ValidateSession обрабатывает gRPC запрос ValidateSession
 func (h *Handler) ValidateSession(ctx context.Context, req *iampb.ValidateSessionRequest) (*iampb.ValidateSessionResponse, error) {
 	// Валидация входных данных
@@ -196,5 +235,6 @@ func (h *Handler) ValidateSession(ctx context.Context, req *iampb.ValidateSessio
 
 	return &iampb.ValidateSessionResponse{
 		UserId: result.UserID,
+		Role:   result.Role,
 	}, nil
 }
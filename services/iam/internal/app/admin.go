@@ -0,0 +1,33 @@
+package app
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
+	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
+)
+
+// newAdminServer создаёт отдельный gRPC сервер для служебных endpoint'ов: health, административный API
+// (IAMAdminService) и (опционально) reflection.
+// Он слушает собственный адрес (ADMIN_GRPC_ADDR), поэтому основной порт API можно публиковать,
+// не открывая наружу служебные/отладочные поверхности. Сюда же добавляются будущие debug endpoint'ы.
+func newAdminServer(enableReflection bool, adminHandler iampb.IAMAdminServiceServer, interceptor grpc.UnaryServerInterceptor) (*grpc.Server, *platformhealth.Health) {
+	adminServer := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+
+	// Health check с начальным статусом SERVING
+	health := platformhealth.New(grpc_health_v1.HealthCheckResponse_SERVING)
+	health.Register(adminServer)
+
+	// Административные операции (DeleteUser) доступны только на служебном порту
+	iampb.RegisterIAMAdminServiceServer(adminServer, adminHandler)
+
+	// Reflection описывает все сервисы, зарегистрированные на этом сервере (health, IAMAdminService и сам reflection);
+	// схему IAM API клиенты (grpcurl) берут из proto файлов
+	if enableReflection {
+		reflection.Register(adminServer)
+	}
+
+	return adminServer, health
+}
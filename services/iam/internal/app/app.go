@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
 	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
@@ -22,36 +24,44 @@ import (
 	grpcapi "github.com/shestoi/GoBigTech/services/iam/internal/api/grpc"
 	httpapi "github.com/shestoi/GoBigTech/services/iam/internal/api/http"
 	"github.com/shestoi/GoBigTech/services/iam/internal/config"
+	eventkafka "github.com/shestoi/GoBigTech/services/iam/internal/event/kafka"
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
 	"github.com/shestoi/GoBigTech/services/iam/internal/repository/postgres"
 	redisrepo "github.com/shestoi/GoBigTech/services/iam/internal/repository/redis"
 	"github.com/shestoi/GoBigTech/services/iam/internal/service"
 	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
 )
 
 // App содержит все зависимости для запуска и корректного shutdown IAM Service
 type App struct {
-	logger      *zap.Logger
-	grpcServer  *grpc.Server
-	httpServer  *http.Server
-	listener    net.Listener
-	health      *platformhealth.Health
-	shutdownMgr *platformshutdown.Manager
-	wg          sync.WaitGroup
+	logger        *zap.Logger
+	grpcServer    *grpc.Server
+	httpServer    *http.Server
+	listener      net.Listener
+	adminServer   *grpc.Server // health, reflection и debug endpoint'ы на отдельном порту
+	adminListener net.Listener
+	health        *platformhealth.Health
+	storeMonitor  *sessionStoreMonitor // nil, если проверка Redis для readiness выключена
+	shutdownMgr   *platformshutdown.Manager
+	wg            sync.WaitGroup
 }
 
 // Build создаёт и настраивает все зависимости IAM Service
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "iam",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
@@ -73,6 +83,12 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Подключаемся к PostgreSQL
 	logger.Info("Connecting to PostgreSQL")
 	pool, err := pgxpool.New(context.Background(), cfg.PostgresDSN)
@@ -123,6 +139,11 @@ func Build(cfg config.Config) (*App, error) {
 		DB:       0,
 	})
 
+	// Spans и метрики команд Redis (iam_redis_command_duration_ms, iam_redis_command_errors_total)
+	if cfg.OTelEnabled {
+		redisrepo.Instrument(redisClient)
+	}
+
 	// Проверяем подключение к Redis
 	ctxRedis, cancelRedis := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelRedis()
@@ -133,13 +154,38 @@ func Build(cfg config.Config) (*App, error) {
 	logger.Info("Redis connection established")
 
 	// Создаём PostgreSQL репозиторий
-	userRepo := postgres.NewRepository(pool)
+	var userRepo repository.UserRepository = postgres.NewRepository(pool)
+
+	// Кеш контактов перед PostgreSQL: GetUserContact на каждое событие notification
+	if cfg.ContactCacheEnabled {
+		userRepo = redisrepo.NewCachedUserRepository(userRepo, redisClient, cfg.ContactCacheTTL, logger)
+		logger.Info("User contact cache enabled", zap.Duration("ttl", cfg.ContactCacheTTL))
+	}
 
 	// Создаём Redis session repository
 	sessionRepo := redisrepo.NewSessionRepository(redisClient, logger)
 
+	// Kafka publisher для событий пользователя (iam.user.deleted)
+	userEventPublisher := eventkafka.NewKafkaUserEventPublisher(logger, cfg.KafkaBrokers, cfg.UserDeletedTopic)
+
 	// Создаём service слой
-	iamService := service.NewService(logger, userRepo, sessionRepo, cfg.SessionTTL)
+	iamService := service.NewService(logger, userRepo, sessionRepo, userEventPublisher, cfg.SessionTTL)
+
+	// Начальный администратор: повторный старт (и параллельный старт нескольких реплик) ничего не меняет
+	if cfg.BootstrapAdminLogin != "" {
+		ctxBootstrap, cancelBootstrap := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := iamService.BootstrapAdmin(ctxBootstrap, service.BootstrapAdminInput{
+			Login:    cfg.BootstrapAdminLogin,
+			Password: cfg.BootstrapAdminPassword,
+		})
+		cancelBootstrap()
+		if err != nil {
+			userEventPublisher.Close()
+			pool.Close()
+			redisClient.Close()
+			return nil, fmt.Errorf("failed to bootstrap admin user: %w", err)
+		}
+	}
 
 	// Создаём gRPC handler
 	grpcHandler := grpcapi.NewHandler(iamService, logger)
@@ -157,22 +203,37 @@ func Build(cfg config.Config) (*App, error) {
 		grpc.UnaryInterceptor(platformobservability.GRPCUnaryServerInterceptor("iam")),
 	)
 
-	// Включаем reflection, если указано в конфиге
-	if cfg.EnableGRPCReflection {
-		reflection.Register(grpcServer)
-		logger.Info("gRPC reflection enabled")
-	}
-
-	// Создаём health check с начальным статусом SERVING
-	health := platformhealth.New(grpc_health_v1.HealthCheckResponse_SERVING)
-	health.Register(grpcServer)
-	logger.Info("Health check initialized with SERVING status")
-
 	// Регистрируем gRPC handler
 	iampb.RegisterIAMServiceServer(grpcServer, grpcHandler)
 
 	logger.Info("IAM gRPC server configured", zap.String("addr", cfg.GRPCAddr))
 
+	// Служебный gRPC сервер (health, reflection) на отдельном адресе: основной порт отдаёт только API
+	adminListener, err := net.Listen("tcp", cfg.AdminGRPCAddr)
+	if err != nil {
+		listener.Close()
+		pool.Close()
+		redisClient.Close()
+		return nil, err
+	}
+	adminHandler := grpcapi.NewAdminHandler(iamService, logger)
+	adminServer, health := newAdminServer(cfg.EnableGRPCReflection, adminHandler, platformobservability.GRPCUnaryServerInterceptor("iam"))
+	logger.Info("IAM admin gRPC server configured",
+		zap.String("addr", cfg.AdminGRPCAddr),
+		zap.Bool("reflection", cfg.EnableGRPCReflection),
+	)
+
+	// Readiness учитывает задержку хранилища сессий
+	var storeMonitor *sessionStoreMonitor
+	if cfg.RedisCheckInterval > 0 {
+		storeMonitor = newSessionStoreMonitor(redisClient, health, logger, cfg.RedisCheckInterval, cfg.RedisMaxLatency, cfg.RedisFailureThreshold)
+		logger.Info("Session store readiness check enabled",
+			zap.Duration("interval", cfg.RedisCheckInterval),
+			zap.Duration("max_latency", cfg.RedisMaxLatency),
+			zap.Int("failure_threshold", cfg.RedisFailureThreshold),
+		)
+	}
+
 	// Внутренний HTTP-сервер для Envoy: POST /internal/validate (проверка сессии по x-session-id)
 	validateHandler := httpapi.NewValidateHandler(iamService, logger)
 	httpMux := http.NewServeMux()
@@ -189,21 +250,36 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Регистрируем shutdown функции в обратном порядке выполнения
 	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
+	// Publisher закрывается после остановки gRPC серверов: DeleteUser в процессе успеет опубликовать событие
+	shutdownMgr.Add("kafka_user_event_publisher", func(ctx context.Context) error {
+		return userEventPublisher.Close()
+	})
 	shutdownMgr.Add("http_server", platformshutdown.ShutdownHTTPServer(httpServer))
+	shutdownMgr.Add("admin_grpc_server", platformshutdown.ShutdownGRPCServer(adminServer))
 	shutdownMgr.Add("grpc_server", platformshutdown.ShutdownGRPCServer(grpcServer))
 	shutdownMgr.Add("health_readiness", platformshutdown.SetHealthNotServing(health))
 	shutdownMgr.Add("redis_client", func(ctx context.Context) error {
 		return redisClient.Close()
 	})
 	shutdownMgr.Add("postgres_pool", platformshutdown.ClosePool(pool))
+	// Монитор останавливается первым: после этого readiness меняет только health_readiness
+	if storeMonitor != nil {
+		shutdownMgr.Add("session_store_monitor", storeMonitor.Stop)
+	}
 
 	return &App{
-		logger:      logger,
-		grpcServer:  grpcServer,
-		httpServer:  httpServer,
-		listener:    listener,
-		health:      health,
-		shutdownMgr: shutdownMgr,
+		logger:        logger,
+		grpcServer:    grpcServer,
+		httpServer:    httpServer,
+		listener:      listener,
+		adminServer:   adminServer,
+		adminListener: adminListener,
+		health:        health,
+		storeMonitor:  storeMonitor,
+		shutdownMgr:   shutdownMgr,
 	}, nil
 }
 
@@ -221,6 +297,14 @@ func (a *App) Run() error {
 		}
 	}()
 
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.adminServer.Serve(a.adminListener); err != nil && err != grpc.ErrServerStopped {
+			a.logger.Error("admin gRPC server error", zap.Error(err))
+		}
+	}()
+
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
@@ -229,6 +313,14 @@ func (a *App) Run() error {
 		}
 	}()
 
+	if a.storeMonitor != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.storeMonitor.Run()
+		}()
+	}
+
 	// Ожидаем сигнал и выполняем shutdown
 	a.shutdownMgr.Wait()
 
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
+)
+
+// sessionStoreMonitor переводит readiness IAM в NOT_SERVING, когда хранилище сессий (Redis) недоступно или медленное
+// Проверка сессии стоит на пути каждого авторизованного запроса в Inventory (и Envoy), поэтому инстанс с медленным
+// Redis лучше вывести из балансировки, чем тормозить все запросы
+// Раз в interval выполняется PING: ошибка или задержка выше maxLatency - неудачная проверка.
+// NOT_SERVING ставится после failureThreshold неудачных проверок подряд, SERVING возвращается после первой удачной
+type sessionStoreMonitor struct {
+	client           *redis.Client
+	health           *platformhealth.Health
+	logger           *zap.Logger
+	interval         time.Duration
+	maxLatency       time.Duration
+	failureThreshold int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newSessionStoreMonitor(client *redis.Client, health *platformhealth.Health, logger *zap.Logger, interval, maxLatency time.Duration, failureThreshold int) *sessionStoreMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sessionStoreMonitor{
+		client:           client,
+		health:           health,
+		logger:           logger,
+		interval:         interval,
+		maxLatency:       maxLatency,
+		failureThreshold: failureThreshold,
+		ctx:              ctx,
+		cancel:           cancel,
+		done:             make(chan struct{}),
+	}
+}
+
+// Run выполняет проверки до вызова Stop
+func (m *sessionStoreMonitor) Run() {
+	defer close(m.done)
+	ctx := m.ctx
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	serving := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		latency, err := m.check(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && latency <= m.maxLatency {
+			failures = 0
+			if !serving {
+				serving = true
+				m.health.SetServing("")
+				m.logger.Info("Session store recovered, readiness set to SERVING", zap.Duration("latency", latency))
+			}
+			continue
+		}
+
+		failures++
+		m.logger.Warn("Session store check failed",
+			zap.Duration("latency", latency),
+			zap.Duration("max_latency", m.maxLatency),
+			zap.Int("consecutive_failures", failures),
+			zap.Error(err),
+		)
+		if serving && failures >= m.failureThreshold {
+			serving = false
+			m.health.SetNotServing("")
+			m.logger.Error("Session store unhealthy, readiness set to NOT_SERVING", zap.Int("consecutive_failures", failures))
+		}
+	}
+}
+
+// check выполняет PING с таймаутом, равным interval, и возвращает его длительность
+func (m *sessionStoreMonitor) check(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	start := time.Now()
+	err := m.client.Ping(ctx).Err()
+	return time.Since(start), err
+}
+
+// Stop останавливает проверки и ждёт выхода из Run: после него readiness меняет только shutdown
+func (m *sessionStoreMonitor) Stop(ctx context.Context) error {
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
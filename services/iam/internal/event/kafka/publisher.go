@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/iam/internal/service"
+)
+
+// tracerName - имя сервиса для span публикации сообщений
+const tracerName = "iam"
+
+// KafkaUserEventPublisher реализует UserEventPublisher используя Kafka
+type KafkaUserEventPublisher struct {
+	logger *zap.Logger
+	writer *kafka.Writer //writer для отправки сообщений в Kafka
+	topic  string
+}
+
+// NewKafkaUserEventPublisher создаёт новый Kafka publisher для событий пользователя
+func NewKafkaUserEventPublisher(logger *zap.Logger, brokers []string, topic string) *KafkaUserEventPublisher {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},    // все события пользователя в одной партиции (ключ - user_id)
+		RequiredAcks: kafka.RequireAll, // DeleteUser отвечает успехом только после подтверждения записи
+	}
+
+	return &KafkaUserEventPublisher{
+		logger: logger,
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Close закрывает Kafka writer
+func (p *KafkaUserEventPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// PublishUserDeleted публикует событие удаления пользователя в Kafka
+func (p *KafkaUserEventPublisher) PublishUserDeleted(ctx context.Context, event service.UserDeletedEvent) error {
+	// Только идентификатор пользователя: персональные данные в событие не попадают
+	payload := map[string]interface{}{
+		"event_id":      event.EventID,
+		"event_type":    event.EventType,
+		"event_version": event.EventVersion,
+		"occurred_at":   event.OccurredAt.Format(time.RFC3339),
+		"user_id":       event.UserID,
+	}
+
+	valueBytes, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("failed to marshal user deleted event",
+			zap.Error(err),
+			zap.String("user_id", event.UserID),
+		)
+		return err
+	}
+
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, p.topic)
+	defer span.End()
+
+	message := kafka.Message{
+		Key:     []byte(event.UserID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, event.EventType, event.EventID, event.EventVersion).Kafka(),
+	}
+
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		span.RecordError(err)
+		p.logger.Error("failed to publish user deleted event",
+			zap.Error(err),
+			zap.String("topic", p.topic),
+			zap.String("user_id", event.UserID),
+		)
+		return err
+	}
+
+	p.logger.Info("user deleted event published",
+		zap.String("topic", p.topic),
+		zap.String("event_id", event.EventID),
+		zap.String("user_id", event.UserID),
+	)
+
+	return nil
+}
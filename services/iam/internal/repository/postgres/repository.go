@@ -39,10 +39,15 @@ func (r *Repository) CreateUser(ctx context.Context, user repository.User) error
 		}
 	}
 
+	role := user.Role
+	if role == "" {
+		role = repository.RoleUser
+	}
+
 	_, err = r.pool.Exec(ctx,
-		`INSERT INTO users (id, login, password_hash, telegram_id, created_at)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		userID, user.Login, user.PasswordHash, user.TelegramID, user.CreatedAt)
+		`INSERT INTO users (id, login, password_hash, telegram_id, locale, timezone, created_at, role)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		userID, user.Login, user.PasswordHash, user.TelegramID, user.Locale, user.Timezone, user.CreatedAt, role)
 
 	if err != nil {
 		// Проверяем, это duplicate key error?
@@ -56,17 +61,17 @@ func (r *Repository) CreateUser(ctx context.Context, user repository.User) error
 	return nil
 }
 
-// GetByLogin получает пользователя по login из PostgreSQL
+// GetByLogin получает пользователя по login из PostgreSQL (удалённые пользователи не возвращаются)
 func (r *Repository) GetByLogin(ctx context.Context, login string) (repository.User, error) {
 	var user repository.User
 	var createdAt time.Time
 	var telegramID *string
 
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, login, password_hash, telegram_id, created_at
+		`SELECT id, login, password_hash, telegram_id, locale, timezone, created_at, legal_hold, role
 		 FROM users
-		 WHERE login = $1`,
-		login).Scan(&user.ID, &user.Login, &user.PasswordHash, &telegramID, &createdAt)
+		 WHERE login = $1 AND deleted_at IS NULL`,
+		login).Scan(&user.ID, &user.Login, &user.PasswordHash, &telegramID, &user.Locale, &user.Timezone, &createdAt, &user.LegalHold, &user.Role)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -81,7 +86,7 @@ func (r *Repository) GetByLogin(ctx context.Context, login string) (repository.U
 	return user, nil
 }
 
-// GetByID получает пользователя по ID из PostgreSQL
+// GetByID получает пользователя по ID из PostgreSQL (удалённые пользователи не возвращаются)
 func (r *Repository) GetByID(ctx context.Context, userID string) (repository.User, error) {
 	var user repository.User
 	var createdAt time.Time
@@ -93,10 +98,10 @@ func (r *Repository) GetByID(ctx context.Context, userID string) (repository.Use
 	}
 
 	err = r.pool.QueryRow(ctx,
-		`SELECT id, login, password_hash, telegram_id, created_at
+		`SELECT id, login, password_hash, telegram_id, locale, timezone, created_at, legal_hold, role
 		 FROM users
-		 WHERE id = $1`,
-		parsedUUID).Scan(&user.ID, &user.Login, &user.PasswordHash, &telegramID, &createdAt)
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		parsedUUID).Scan(&user.ID, &user.Login, &user.PasswordHash, &telegramID, &user.Locale, &user.Timezone, &createdAt, &user.LegalHold, &user.Role)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -110,3 +115,108 @@ func (r *Repository) GetByID(ctx context.Context, userID string) (repository.Use
 
 	return user, nil
 }
+
+// GetContactByID получает контактные данные пользователя по ID из PostgreSQL (удалённые пользователи не возвращаются)
+func (r *Repository) GetContactByID(ctx context.Context, userID string) (repository.UserContact, error) {
+	var contact repository.UserContact
+
+	parsedUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return repository.UserContact{}, err
+	}
+
+	err = r.pool.QueryRow(ctx,
+		`SELECT telegram_id, locale, timezone
+		 FROM users
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		parsedUUID).Scan(&contact.TelegramID, &contact.Locale, &contact.Timezone)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.UserContact{}, repository.ErrNotFound
+		}
+		return repository.UserContact{}, err
+	}
+
+	return contact, nil
+}
+
+// UpdateProfile обновляет locale и timezone пользователя; nil поля ProfileUpdate остаются как есть
+func (r *Repository) UpdateProfile(ctx context.Context, userID string, update repository.ProfileUpdate) (repository.User, error) {
+	parsedUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return repository.User{}, repository.ErrNotFound
+	}
+
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users
+		 SET locale = COALESCE($2, locale), timezone = COALESCE($3, timezone)
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		parsedUUID, update.Locale, update.Timezone)
+	if err != nil {
+		return repository.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.User{}, repository.ErrNotFound
+	}
+
+	return r.GetByID(ctx, userID)
+}
+
+// DeleteUser анонимизирует пользователя и записывает удаление в user_deletion_audit в одной транзакции
+// Строка пользователя остаётся (на user_id ссылаются заказы и уведомления), но login заменяется на deleted-<id>,
+// пароль, telegram_id, locale и timezone стираются: войти под пользователем нельзя, а login освобождается для новой регистрации
+func (r *Repository) DeleteUser(ctx context.Context, userID string, deletion repository.UserDeletion) (bool, error) {
+	parsedUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return false, repository.ErrNotFound
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем строку, чтобы параллельные удаления не записали audit дважды
+	var legalHold bool
+	var deletedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT legal_hold, deleted_at
+		 FROM users
+		 WHERE id = $1
+		 FOR UPDATE`,
+		parsedUUID).Scan(&legalHold, &deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, repository.ErrNotFound
+		}
+		return false, err
+	}
+
+	if deletedAt != nil {
+		return true, nil
+	}
+	if legalHold && !deletion.LegalHoldOverride {
+		return false, repository.ErrLegalHold
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE users
+		 SET login = 'deleted-' || id::text, password_hash = '', telegram_id = NULL, locale = '', timezone = '', deleted_at = now()
+		 WHERE id = $1`,
+		parsedUUID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO user_deletion_audit (user_id, requested_by, reason, legal_hold, legal_hold_override)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		parsedUUID, deletion.RequestedBy, deletion.Reason, legalHold, legalHold && deletion.LegalHoldOverride)
+	if err != nil {
+		return false, err
+	}
+
+	return false, tx.Commit(ctx)
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+)
+
+// Результаты обращения к кешу для метрики iam_contact_cache_requests_total
+const (
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+	cacheResultError = "error" // Redis недоступен: чтение ушло в PostgreSQL
+)
+
+// cachedContact - контакт пользователя в Redis (JSON)
+type cachedContact struct {
+	TelegramID *string `json:"telegram_id,omitempty"`
+	Locale     string  `json:"locale"`
+	Timezone   string  `json:"timezone"`
+}
+
+// CachedUserRepository реализует UserRepository с read-through кешем контактов в Redis перед next
+// Notification вызывает GetUserContact на каждое событие, а контакты меняются редко:
+// GetContactByID сначала читает Redis, промах дочитывается из next и кладётся в Redis на ttl.
+// UpdateProfile и DeleteUser удаляют ключ пользователя после записи в next.
+// Кеш необязателен: ошибки Redis только логируются, чтение уходит в next.
+// Промах, дочитанный до инвалидации, может положить в кеш старое значение - ttl ограничивает такое устаревание
+type CachedUserRepository struct {
+	repository.UserRepository // остальные методы идут в next без кеша
+
+	client   redis.Cmdable
+	ttl      time.Duration
+	logger   *zap.Logger
+	requests metric.Int64Counter
+}
+
+// NewCachedUserRepository создаёт кеширующую обёртку над next
+// client - *redis.Client; в тестах - подмена, реализующая Get, Set и Del
+func NewCachedUserRepository(next repository.UserRepository, client redis.Cmdable, ttl time.Duration, logger *zap.Logger) *CachedUserRepository {
+	requests, _ := otel.Meter("iam").Int64Counter("iam_contact_cache_requests_total",
+		metric.WithDescription("User contact reads by cache result: hit, miss or error (Redis unavailable)"))
+	return &CachedUserRepository{
+		UserRepository: next,
+		client:         client,
+		ttl:            ttl,
+		logger:         logger,
+		requests:       requests,
+	}
+}
+
+func contactKey(userID string) string {
+	return fmt.Sprintf("iam:contact:%s", userID)
+}
+
+// GetContactByID возвращает контакт из кеша; при промахе читает next и кеширует ответ
+// ErrNotFound не кешируется: пользователь мог только что зарегистрироваться
+func (r *CachedUserRepository) GetContactByID(ctx context.Context, userID string) (repository.UserContact, error) {
+	value, err := r.client.Get(ctx, contactKey(userID)).Bytes()
+	switch {
+	case err == nil:
+		var cached cachedContact
+		if unmarshalErr := json.Unmarshal(value, &cached); unmarshalErr == nil {
+			r.record(ctx, cacheResultHit)
+			return repository.UserContact{
+				TelegramID: cached.TelegramID,
+				Locale:     cached.Locale,
+				Timezone:   cached.Timezone,
+			}, nil
+		}
+		r.logger.Warn("invalid cached user contact", zap.String("user_id", userID))
+		r.record(ctx, cacheResultMiss)
+	case errors.Is(err, redis.Nil):
+		r.record(ctx, cacheResultMiss)
+	default:
+		r.logger.Warn("contact cache read failed", zap.Error(err), zap.String("user_id", userID))
+		r.record(ctx, cacheResultError)
+		return r.UserRepository.GetContactByID(ctx, userID)
+	}
+
+	contact, err := r.UserRepository.GetContactByID(ctx, userID)
+	if err != nil {
+		return repository.UserContact{}, err
+	}
+	r.store(ctx, userID, contact)
+	return contact, nil
+}
+
+// UpdateProfile меняет профиль в next и удаляет контакт пользователя из кеша
+func (r *CachedUserRepository) UpdateProfile(ctx context.Context, userID string, update repository.ProfileUpdate) (repository.User, error) {
+	user, err := r.UserRepository.UpdateProfile(ctx, userID, update)
+	if err != nil {
+		return repository.User{}, err
+	}
+	r.invalidate(ctx, userID)
+	return user, nil
+}
+
+// DeleteUser анонимизирует пользователя в next и удаляет его контакт из кеша
+// Ключ удаляется и для уже удалённого пользователя: повторный вызов дочищает кеш после сбоя Redis
+func (r *CachedUserRepository) DeleteUser(ctx context.Context, userID string, deletion repository.UserDeletion) (bool, error) {
+	alreadyDeleted, err := r.UserRepository.DeleteUser(ctx, userID, deletion)
+	if err != nil {
+		return false, err
+	}
+	r.invalidate(ctx, userID)
+	return alreadyDeleted, nil
+}
+
+// store кладёт контакт в кеш; ошибка только логируется
+func (r *CachedUserRepository) store(ctx context.Context, userID string, contact repository.UserContact) {
+	value, err := json.Marshal(cachedContact{
+		TelegramID: contact.TelegramID,
+		Locale:     contact.Locale,
+		Timezone:   contact.Timezone,
+	})
+	if err != nil {
+		return
+	}
+	if err := r.client.Set(ctx, contactKey(userID), value, r.ttl).Err(); err != nil {
+		r.logger.Warn("contact cache write failed", zap.Error(err), zap.String("user_id", userID))
+	}
+}
+
+// invalidate удаляет ключ пользователя; если Redis недоступен, кеш устареет не дольше чем на ttl
+// Удаление не зависит от отмены запроса: профиль в next уже изменён
+func (r *CachedUserRepository) invalidate(ctx context.Context, userID string) {
+	if err := r.client.Del(context.WithoutCancel(ctx), contactKey(userID)).Err(); err != nil {
+		r.logger.Warn("contact cache invalidation failed", zap.Error(err), zap.String("user_id", userID))
+	}
+}
+
+func (r *CachedUserRepository) record(ctx context.Context, result string) {
+	r.requests.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+}
@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository/mocks"
+)
+
+// fakeRedis - Redis в памяти для CachedUserRepository: Get, Set и Del по map
+// down - Redis недоступен: каждая команда возвращает ошибку соединения
+type fakeRedis struct {
+	redis.Cmdable
+	values map[string]string
+	ttls   map[string]time.Duration
+	down   bool
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+var errRedisDown = errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+
+func (f *fakeRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	if f.down {
+		return redis.NewStringResult("", errRedisDown)
+	}
+	value, ok := f.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (f *fakeRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	if f.down {
+		return redis.NewStatusResult("", errRedisDown)
+	}
+	f.values[key] = string(value.([]byte))
+	f.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	if f.down {
+		return redis.NewIntResult(0, errRedisDown)
+	}
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := f.values[key]; ok {
+			delete(f.values, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func TestCachedUserRepository_GetContactByID(t *testing.T) {
+	ctx := context.Background()
+	telegramID := "12345"
+	contact := repository.UserContact{TelegramID: &telegramID, Locale: "ru", Timezone: "Europe/Moscow"}
+
+	t.Run("miss reads postgres and caches, hit does not", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		repo := NewCachedUserRepository(next, cache, 10*time.Minute, zap.NewNop())
+
+		next.On("GetContactByID", ctx, "user-1").Return(contact, nil).Once()
+
+		missed, err := repo.GetContactByID(ctx, "user-1")
+		require.NoError(t, err)
+		hit, err := repo.GetContactByID(ctx, "user-1")
+		require.NoError(t, err)
+
+		require.Equal(t, contact, missed)
+		require.Equal(t, contact, hit)
+		require.Contains(t, cache.values, contactKey("user-1"))
+		require.Equal(t, 10*time.Minute, cache.ttls[contactKey("user-1")])
+	})
+
+	t.Run("not found is not cached", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+
+		next.On("GetContactByID", ctx, "user-new").Return(repository.UserContact{}, repository.ErrNotFound).Twice()
+
+		_, err := repo.GetContactByID(ctx, "user-new")
+		require.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = repo.GetContactByID(ctx, "user-new")
+		require.ErrorIs(t, err, repository.ErrNotFound)
+
+		require.Empty(t, cache.values)
+	})
+
+	t.Run("invalid cached value is read again from postgres", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		cache.values[contactKey("user-1")] = "{not json"
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+
+		next.On("GetContactByID", ctx, "user-1").Return(contact, nil).Once()
+
+		got, err := repo.GetContactByID(ctx, "user-1")
+
+		require.NoError(t, err)
+		require.Equal(t, contact, got)
+		require.NotEqual(t, "{not json", cache.values[contactKey("user-1")])
+	})
+
+	t.Run("redis down falls back to postgres", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		cache.down = true
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+
+		next.On("GetContactByID", ctx, "user-1").Return(contact, nil).Twice()
+
+		for i := 0; i < 2; i++ {
+			got, err := repo.GetContactByID(ctx, "user-1")
+			require.NoError(t, err)
+			require.Equal(t, contact, got)
+		}
+	})
+}
+
+func TestCachedUserRepository_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	oldContact := repository.UserContact{Locale: "ru", Timezone: "Europe/Moscow"}
+	newContact := repository.UserContact{Locale: "en", Timezone: "Europe/London"}
+
+	t.Run("UpdateProfile drops cached contact", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+		locale := "en"
+		update := repository.ProfileUpdate{Locale: &locale}
+
+		next.On("GetContactByID", ctx, "user-1").Return(oldContact, nil).Once()
+		next.On("UpdateProfile", ctx, "user-1", update).Return(repository.User{ID: "user-1", Locale: "en"}, nil).Once()
+		next.On("GetContactByID", ctx, "user-1").Return(newContact, nil).Once()
+
+		_, err := repo.GetContactByID(ctx, "user-1")
+		require.NoError(t, err)
+		_, err = repo.UpdateProfile(ctx, "user-1", update)
+		require.NoError(t, err)
+		require.NotContains(t, cache.values, contactKey("user-1"))
+
+		got, err := repo.GetContactByID(ctx, "user-1")
+		require.NoError(t, err)
+		require.Equal(t, newContact, got)
+	})
+
+	t.Run("failed UpdateProfile keeps cached contact", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		cache.values[contactKey("user-1")] = `{"locale":"ru","timezone":"Europe/Moscow"}`
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+
+		next.On("UpdateProfile", ctx, "user-1", repository.ProfileUpdate{}).Return(repository.User{}, repository.ErrNotFound).Once()
+
+		_, err := repo.UpdateProfile(ctx, "user-1", repository.ProfileUpdate{})
+
+		require.ErrorIs(t, err, repository.ErrNotFound)
+		require.Contains(t, cache.values, contactKey("user-1"))
+	})
+
+	t.Run("DeleteUser drops cached contact, also for already deleted user", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+		deletion := repository.UserDeletion{RequestedBy: "admin", Reason: "gdpr"}
+
+		next.On("DeleteUser", ctx, "user-1", deletion).Return(false, nil).Once()
+		next.On("DeleteUser", ctx, "user-1", deletion).Return(true, nil).Once()
+
+		cache.values[contactKey("user-1")] = `{"locale":"ru","timezone":"Europe/Moscow"}`
+		alreadyDeleted, err := repo.DeleteUser(ctx, "user-1", deletion)
+		require.NoError(t, err)
+		require.False(t, alreadyDeleted)
+		require.NotContains(t, cache.values, contactKey("user-1"))
+
+		cache.values[contactKey("user-1")] = `{"locale":"ru","timezone":"Europe/Moscow"}`
+		alreadyDeleted, err = repo.DeleteUser(ctx, "user-1", deletion)
+		require.NoError(t, err)
+		require.True(t, alreadyDeleted)
+		require.NotContains(t, cache.values, contactKey("user-1"))
+	})
+
+	t.Run("redis down does not fail writes", func(t *testing.T) {
+		next := mocks.NewUserRepository(t)
+		cache := newFakeRedis()
+		cache.down = true
+		repo := NewCachedUserRepository(next, cache, time.Minute, zap.NewNop())
+		deletion := repository.UserDeletion{RequestedBy: "admin"}
+
+		next.On("UpdateProfile", ctx, "user-1", repository.ProfileUpdate{}).Return(repository.User{ID: "user-1"}, nil).Once()
+		next.On("DeleteUser", ctx, "user-1", deletion).Return(false, nil).Once()
+
+		_, err := repo.UpdateProfile(ctx, "user-1", repository.ProfileUpdate{})
+		require.NoError(t, err)
+		_, err = repo.DeleteUser(ctx, "user-1", deletion)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName - имя tracer'а и meter'а для команд Redis
+const instrumentationName = "iam/redis"
+
+// Instrument подключает к клиенту OpenTelemetry: span на каждую команду (pipeline - один span),
+// гистограмму длительности команд и счётчик ошибок
+// redis.Nil (ключа нет, например сессия истекла) ошибкой не считается
+func Instrument(client *redis.Client) {
+	client.AddHook(newInstrumentationHook())
+}
+
+// instrumentationHook реализует redis.Hook
+type instrumentationHook struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+func newInstrumentationHook() *instrumentationHook {
+	meter := otel.Meter(instrumentationName)
+	duration, _ := meter.Float64Histogram("iam_redis_command_duration_ms", metric.WithDescription("Redis command duration in milliseconds"))
+	errs, _ := meter.Int64Counter("iam_redis_command_errors_total", metric.WithDescription("Redis commands failed with an error other than redis.Nil"))
+	return &instrumentationHook{
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errs,
+	}
+}
+
+// DialHook не инструментируется: соединения открывает пул, их длительность видна в первой команде
+func (h *instrumentationHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *instrumentationHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis "+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "redis"),
+				attribute.String("db.operation", cmd.Name()),
+			),
+		)
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(ctx, span, cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (h *instrumentationHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			names = append(names, cmd.Name())
+		}
+		ctx, span := h.tracer.Start(ctx, "redis pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "redis"),
+				attribute.String("db.operation", "pipeline"),
+				attribute.String("db.redis.commands", strings.Join(names, " ")),
+				attribute.Int("db.redis.num_cmd", len(cmds)),
+			),
+		)
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(ctx, span, "pipeline", start, err)
+		return err
+	}
+}
+
+// record пишет длительность команды и, если она завершилась ошибкой, счётчик ошибок и статус span'а
+func (h *instrumentationHook) record(ctx context.Context, span trace.Span, command string, start time.Time, err error) {
+	failed := err != nil && !errors.Is(err, redis.Nil)
+	status := "ok"
+	if failed {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.errors.Add(ctx, 1, metric.WithAttributes(attribute.String("command", command)))
+	}
+	h.duration.Record(ctx, float64(time.Since(start).Microseconds())/1000,
+		metric.WithAttributes(attribute.String("command", command), attribute.String("status", status)))
+}
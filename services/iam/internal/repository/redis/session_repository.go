@@ -149,3 +149,60 @@ func (r *SessionRepository) RefreshSession(ctx context.Context, sessionID string
 
 	return nil
 }
+
+// DeleteUserSessions удаляет все сессии пользователя из Redis
+// Индекса сессий по пользователю нет, поэтому ключи session:* перебираются через SCAN;
+// операция редкая (удаление пользователя), так что полный проход допустим
+func (r *SessionRepository) DeleteUserSessions(ctx context.Context, userID string) (int, error) {
+	var cursor uint64
+	deleted := 0
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, sessionKey("*"), 500).Result()
+		if err != nil {
+			r.logger.Error("failed to scan session hashes in redis",
+				zap.Error(err),
+				zap.String("user_id", userID),
+			)
+			return deleted, fmt.Errorf("failed to scan sessions: %w", err)
+		}
+
+		if len(keys) > 0 {
+			// Читаем user_id всех найденных сессий одним pipeline
+			pipe := r.client.Pipeline()
+			cmds := make([]*redis.StringCmd, len(keys))
+			for i, key := range keys {
+				cmds[i] = pipe.HGet(ctx, key, hashFieldUserID)
+			}
+			if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+				return deleted, fmt.Errorf("failed to read sessions: %w", err)
+			}
+
+			userKeys := make([]string, 0)
+			for i, cmd := range cmds {
+				if cmd.Val() == userID {
+					userKeys = append(userKeys, keys[i])
+				}
+			}
+			if len(userKeys) > 0 {
+				n, err := r.client.Del(ctx, userKeys...).Result()
+				if err != nil {
+					return deleted, fmt.Errorf("failed to delete sessions: %w", err)
+				}
+				deleted += int(n)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	r.logger.Info("user sessions deleted",
+		zap.String("user_id", userID),
+		zap.Int("sessions", deleted),
+	)
+
+	return deleted, nil
+}
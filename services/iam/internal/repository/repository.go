@@ -13,10 +13,38 @@ type User struct {
 	Login        string
 	PasswordHash string
 	TelegramID   *string // nullable
+	Locale       string  // язык пользователя (тег BCP-47), пусто - не задан
+	Timezone     string  // часовой пояс пользователя (имя IANA), пусто - не задан
 	CreatedAt    time.Time
+	LegalHold    bool   // данные пользователя нельзя удалять без явного override
+	Role         string // RoleUser или RoleAdmin; пусто при создании - RoleUser
 }
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=UserRepository --dir=. --output=./mocks --outpkg=mocks
+// Роли пользователя
+const (
+	RoleUser  = "user"  // обычный пользователь (регистрация через Register)
+	RoleAdmin = "admin" // администратор; создаётся только bootstrap'ом при старте сервиса
+)
+
+// UserContact - контактные данные и настройки пользователя для уведомлений (без логина и пароля)
+type UserContact struct {
+	TelegramID *string // nullable
+	Locale     string
+	Timezone   string
+}
+
+// ProfileUpdate описывает изменение настроек профиля; nil - поле не меняется, пустая строка - сбрасывает значение
+type ProfileUpdate struct {
+	Locale   *string
+	Timezone *string
+}
+
+// UserDeletion описывает запрос на удаление пользователя для записи в audit log
+type UserDeletion struct {
+	RequestedBy       string // кто запросил удаление
+	Reason            string // основание удаления
+	LegalHoldOverride bool   // удалить, даже если пользователь под legal hold
+}
 
 // UserRepository определяет интерфейс для работы с хранилищем пользователей
 // Service слой зависит от этого интерфейса, а не от конкретной реализации
@@ -32,10 +60,26 @@ type UserRepository interface {
 	// GetByID получает пользователя по ID
 	// Возвращает ErrNotFound, если пользователь не найден
 	GetByID(ctx context.Context, userID string) (User, error)
+
+	// GetContactByID получает контактные данные пользователя по ID
+	// Возвращает ErrNotFound, если пользователь не найден или удалён
+	GetContactByID(ctx context.Context, userID string) (UserContact, error)
+
+	// UpdateProfile меняет настройки профиля пользователя и возвращает пользователя после изменения
+	// Возвращает ErrNotFound, если пользователь не найден или удалён
+	UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (User, error)
+
+	// DeleteUser анонимизирует пользователя (login, пароль, telegram_id, locale, timezone) и записывает удаление в audit log в одной транзакции
+	// Возвращает ErrNotFound, если пользователь не найден, и ErrLegalHold, если пользователь под legal hold без override.
+	// Для уже удалённого пользователя ничего не меняет и возвращает alreadyDeleted=true
+	DeleteUser(ctx context.Context, userID string, deletion UserDeletion) (alreadyDeleted bool, err error)
 }
 
 // ErrNotFound возвращается, когда пользователь не найден в хранилище
 var ErrNotFound = errors.New("user not found")
 
+// ErrLegalHold возвращается, когда пользователь под legal hold и удаление запрошено без override
+var ErrLegalHold = errors.New("user is under legal hold")
+
 // ErrAlreadyExists возвращается, когда пользователь с таким login уже существует
 var ErrAlreadyExists = errors.New("user already exists")
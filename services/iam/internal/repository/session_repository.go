@@ -22,6 +22,9 @@ type SessionRepository interface {
 
 	// RefreshSession обновляет TTL сессии
 	RefreshSession(ctx context.Context, sessionID string, ttl time.Duration) error
+
+	// DeleteUserSessions удаляет все сессии пользователя и возвращает их количество
+	DeleteUserSessions(ctx context.Context, userID string) (int, error)
 }
 
 // ErrSessionNotFound возвращается, когда сессия не найдена или истекла
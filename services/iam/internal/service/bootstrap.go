@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+)
+
+// ErrBootstrapLoginTaken возвращается, если login из IAM_BOOTSTRAP_ADMIN_LOGIN уже занят обычным пользователем
+// Такой пользователь не повышается до администратора: login мог зарегистрировать кто угодно раньше старта сервиса
+var ErrBootstrapLoginTaken = errors.New("bootstrap admin login is taken by a non-admin user")
+
+// BootstrapAdminInput содержит учётные данные начального администратора
+type BootstrapAdminInput struct {
+	Login    string
+	Password string
+}
+
+// BootstrapAdmin создаёт начального администратора, если его ещё нет; повторный вызов ничего не меняет
+// Пароль существующего администратора не перезаписывается: после первого входа его меняют без участия конфига.
+// Возвращает true, если пользователь создан этим вызовом
+func (s *Service) BootstrapAdmin(ctx context.Context, input BootstrapAdminInput) (bool, error) {
+	if input.Login == "" {
+		return false, fmt.Errorf("login is required")
+	}
+	if len(input.Password) < 6 {
+		return false, fmt.Errorf("password must be at least 6 characters")
+	}
+
+	logger := s.logger.With(zap.String("login", input.Login))
+
+	existing, err := s.repo.GetByLogin(ctx, input.Login)
+	switch {
+	case err == nil:
+		return false, checkBootstrapAdmin(existing, logger)
+	case !errors.Is(err, repository.ErrNotFound):
+		return false, fmt.Errorf("failed to get bootstrap admin: %w", err)
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	err = s.repo.CreateUser(ctx, repository.User{
+		Login:        input.Login,
+		PasswordHash: string(passwordHash),
+		Role:         repository.RoleAdmin,
+		CreatedAt:    time.Now(),
+	})
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		// Другой инстанс IAM создал пользователя между чтением и вставкой
+		existing, err := s.repo.GetByLogin(ctx, input.Login)
+		if err != nil {
+			return false, fmt.Errorf("failed to get bootstrap admin: %w", err)
+		}
+		return false, checkBootstrapAdmin(existing, logger)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+
+	logger.Info("bootstrap admin created")
+	return true, nil
+}
+
+// checkBootstrapAdmin проверяет, что уже существующий пользователь с login начального администратора - администратор
+func checkBootstrapAdmin(user repository.User, logger *zap.Logger) error {
+	if user.Role != repository.RoleAdmin {
+		return ErrBootstrapLoginTaken
+	}
+	logger.Info("bootstrap admin already exists", zap.String("user_id", user.ID))
+	return nil
+}
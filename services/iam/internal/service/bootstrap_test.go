@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository/mocks"
+)
+
+func TestService_BootstrapAdmin(t *testing.T) {
+	ctx := context.Background()
+	input := BootstrapAdminInput{Login: "admin", Password: "secret-password"}
+	admin := repository.User{ID: "admin-1", Login: "admin", Role: repository.RoleAdmin}
+	user := repository.User{ID: "user-1", Login: "admin", Role: repository.RoleUser}
+
+	newService := func(t *testing.T) (*Service, *mocks.UserRepository) {
+		repo := mocks.NewUserRepository(t)
+		return NewService(zap.NewNop(), repo, mocks.NewSessionRepository(t), nil, time.Hour), repo
+	}
+
+	t.Run("created", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetByLogin", ctx, "admin").Return(repository.User{}, repository.ErrNotFound).Once()
+		repo.On("CreateUser", ctx, mock.MatchedBy(func(u repository.User) bool {
+			return u.Login == "admin" && u.Role == repository.RoleAdmin &&
+				bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(input.Password)) == nil
+		})).Return(nil).Once()
+
+		created, err := s.BootstrapAdmin(ctx, input)
+
+		require.NoError(t, err)
+		require.True(t, created)
+	})
+
+	t.Run("already admin", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetByLogin", ctx, "admin").Return(admin, nil).Once()
+
+		created, err := s.BootstrapAdmin(ctx, input)
+
+		require.NoError(t, err)
+		require.False(t, created)
+	})
+
+	t.Run("login taken by normal user", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetByLogin", ctx, "admin").Return(user, nil).Once()
+
+		created, err := s.BootstrapAdmin(ctx, input)
+
+		require.ErrorIs(t, err, ErrBootstrapLoginTaken)
+		require.False(t, created)
+	})
+
+	t.Run("insert races with other replica", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			existing repository.User
+			wantErr  error
+		}{
+			{name: "other replica created admin", existing: admin},
+			{name: "user registered login", existing: user, wantErr: ErrBootstrapLoginTaken},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s, repo := newService(t)
+				repo.On("GetByLogin", ctx, "admin").Return(repository.User{}, repository.ErrNotFound).Once()
+				repo.On("CreateUser", ctx, mock.Anything).Return(repository.ErrAlreadyExists).Once()
+				repo.On("GetByLogin", ctx, "admin").Return(tt.existing, nil).Once()
+
+				created, err := s.BootstrapAdmin(ctx, input)
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+				require.False(t, created)
+			})
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		s, repo := newService(t)
+		dbErr := errors.New("connection refused")
+		repo.On("GetByLogin", ctx, "admin").Return(repository.User{}, dbErr).Once()
+
+		created, err := s.BootstrapAdmin(ctx, input)
+
+		require.ErrorIs(t, err, dbErr)
+		require.False(t, created)
+	})
+
+	t.Run("short password", func(t *testing.T) {
+		s, _ := newService(t)
+
+		_, err := s.BootstrapAdmin(ctx, BootstrapAdminInput{Login: "admin", Password: "123"})
+
+		require.Error(t, err)
+	})
+}
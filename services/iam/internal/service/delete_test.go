@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/iam/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/iam/internal/service"
+	"github.com/shestoi/GoBigTech/services/iam/internal/service/mocks"
+)
+
+// userDeletedEvent проверяет событие удаления: детерминированный event_id, чтобы повтор дедуплицировался consumers
+func userDeletedEvent(userID string) interface{} {
+	return mock.MatchedBy(func(event service.UserDeletedEvent) bool {
+		return event.EventID == "user-deleted-"+userID &&
+			event.EventType == service.EventTypeUserDeleted &&
+			event.EventVersion == 1 &&
+			event.UserID == userID &&
+			!event.OccurredAt.IsZero()
+	})
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(t *testing.T) (*service.Service, *repoMocks.UserRepository, *repoMocks.SessionRepository, *mocks.UserEventPublisher) {
+		repo := repoMocks.NewUserRepository(t)
+		sessions := repoMocks.NewSessionRepository(t)
+		publisher := mocks.NewUserEventPublisher(t)
+		return service.NewService(zap.NewNop(), repo, sessions, publisher, time.Hour), repo, sessions, publisher
+	}
+
+	t.Run("deleted: audit written, sessions revoked, event published", func(t *testing.T) {
+		s, repo, sessions, publisher := newService(t)
+		repo.On("DeleteUser", ctx, "user-1", repository.UserDeletion{RequestedBy: "dpo", Reason: "GDPR-17"}).Return(false, nil).Once()
+		sessions.On("DeleteUserSessions", ctx, "user-1").Return(2, nil).Once()
+		publisher.On("PublishUserDeleted", ctx, userDeletedEvent("user-1")).Return(nil).Once()
+
+		out, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "dpo", Reason: "GDPR-17"})
+
+		require.NoError(t, err)
+		require.Equal(t, &service.DeleteUserOutput{UserID: "user-1", RevokedSessions: 2}, out)
+	})
+
+	t.Run("legal hold without override is rejected", func(t *testing.T) {
+		s, repo, _, _ := newService(t)
+		repo.On("DeleteUser", ctx, "user-1", repository.UserDeletion{RequestedBy: "dpo", Reason: "GDPR-17"}).
+			Return(false, repository.ErrLegalHold).Once()
+
+		out, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "dpo", Reason: "GDPR-17"})
+
+		// Сессии не отзываются и событие не публикуется: моки без ожиданий упадут на вызове
+		require.ErrorIs(t, err, service.ErrUserUnderLegalHold)
+		require.Nil(t, out)
+	})
+
+	t.Run("legal hold with override is deleted", func(t *testing.T) {
+		s, repo, sessions, publisher := newService(t)
+		repo.On("DeleteUser", ctx, "user-1", repository.UserDeletion{RequestedBy: "legal", Reason: "court order", LegalHoldOverride: true}).
+			Return(false, nil).Once()
+		sessions.On("DeleteUserSessions", ctx, "user-1").Return(0, nil).Once()
+		publisher.On("PublishUserDeleted", ctx, userDeletedEvent("user-1")).Return(nil).Once()
+
+		out, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "legal", Reason: "court order", LegalHoldOverride: true})
+
+		require.NoError(t, err)
+		require.False(t, out.AlreadyDeleted)
+	})
+
+	t.Run("repeated delete revokes sessions and republishes the same event", func(t *testing.T) {
+		s, repo, sessions, publisher := newService(t)
+		repo.On("DeleteUser", ctx, "user-1", mock.Anything).Return(true, nil).Once()
+		sessions.On("DeleteUserSessions", ctx, "user-1").Return(0, nil).Once()
+		publisher.On("PublishUserDeleted", ctx, userDeletedEvent("user-1")).Return(nil).Once()
+
+		out, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "dpo"})
+
+		require.NoError(t, err)
+		require.Equal(t, &service.DeleteUserOutput{UserID: "user-1", AlreadyDeleted: true}, out)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		s, repo, _, _ := newService(t)
+		repo.On("DeleteUser", ctx, "user-1", mock.Anything).Return(false, repository.ErrNotFound).Once()
+
+		_, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "dpo"})
+
+		require.ErrorIs(t, err, service.ErrUserNotFound)
+	})
+
+	t.Run("errors after deletion are returned for retry", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			sessionErr error
+			publishErr error
+		}{
+			{name: "sessions not revoked", sessionErr: errors.New("redis: connection refused")},
+			{name: "event not published", publishErr: errors.New("kafka: leader not available")},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s, repo, sessions, publisher := newService(t)
+				repo.On("DeleteUser", ctx, "user-1", mock.Anything).Return(false, nil).Once()
+				sessions.On("DeleteUserSessions", ctx, "user-1").Return(0, tt.sessionErr).Once()
+				if tt.sessionErr == nil {
+					publisher.On("PublishUserDeleted", ctx, userDeletedEvent("user-1")).Return(tt.publishErr).Once()
+				}
+
+				_, err := s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1", RequestedBy: "dpo"})
+
+				require.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("user_id and requested_by are required", func(t *testing.T) {
+		s, _, _, _ := newService(t)
+
+		_, err := s.DeleteUser(ctx, service.DeleteUserInput{RequestedBy: "dpo"})
+		require.Error(t, err)
+
+		_, err = s.DeleteUser(ctx, service.DeleteUserInput{UserID: "user-1"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// EventTypeUserDeleted - тип события удаления пользователя
+const EventTypeUserDeleted = "iam.user.deleted"
+
+// UserDeletedEvent представляет событие удаления пользователя (исходящее в Kafka)
+// Несёт только user_id: сервисы, хранящие копии данных пользователя (order, notification), анонимизируют их сами
+type UserDeletedEvent struct {
+	EventID      string // детерминированный (user-deleted-<user_id>), чтобы повторная публикация дедуплицировалась consumers
+	EventType    string // EventTypeUserDeleted
+	EventVersion int
+	OccurredAt   time.Time
+	UserID       string
+}
+
+// UserEventPublisher определяет интерфейс для публикации событий жизненного цикла пользователя
+type UserEventPublisher interface {
+	// PublishUserDeleted публикует событие удаления пользователя
+	PublishUserDeleted(ctx context.Context, event UserDeletedEvent) error
+}
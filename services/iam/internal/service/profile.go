@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // базы часовых поясов нет в alpine-образе: без неё time.LoadLocation не найдёт зоны IANA
+
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+)
+
+// ErrInvalidLocale возвращается, если locale не является тегом BCP-47 (handler маппит в codes.InvalidArgument)
+var ErrInvalidLocale = errors.New("invalid locale")
+
+// ErrInvalidTimezone возвращается, если timezone не является именем зоны IANA (handler маппит в codes.InvalidArgument)
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// normalizeLocale проверяет тег BCP-47 и приводит его к канонической форме (en-us -> en-US).
+// Пустая строка допустима: locale не задан
+func normalizeLocale(locale string) (string, error) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return "", nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, locale)
+	}
+	return tag.String(), nil
+}
+
+// normalizeTimezone проверяет имя зоны IANA (Europe/Moscow, UTC).
+// Пустая строка допустима: timezone не задан. "Local" отклоняется - это зона сервера, а не пользователя
+func normalizeTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return "", nil
+	}
+	if timezone == "Local" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+	return timezone, nil
+}
+
+// UpdateProfileInput содержит входные данные для изменения профиля; nil - поле не меняется
+type UpdateProfileInput struct {
+	UserID   string
+	Locale   *string
+	Timezone *string
+}
+
+// UpdateProfileOutput содержит профиль пользователя после изменения
+type UpdateProfileOutput struct {
+	UserID   string
+	Locale   string
+	Timezone string
+}
+
+// UpdateProfile меняет locale и timezone пользователя
+func (s *Service) UpdateProfile(ctx context.Context, input UpdateProfileInput) (*UpdateProfileOutput, error) {
+	if input.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	var update repository.ProfileUpdate
+	if input.Locale != nil {
+		locale, err := normalizeLocale(*input.Locale)
+		if err != nil {
+			return nil, err
+		}
+		update.Locale = &locale
+	}
+	if input.Timezone != nil {
+		timezone, err := normalizeTimezone(*input.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		update.Timezone = &timezone
+	}
+
+	user, err := s.repo.UpdateProfile(ctx, input.UserID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.Error("failed to update user profile", zap.Error(err), zap.String("user_id", input.UserID))
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	s.logger.Info("user profile updated",
+		zap.String("user_id", user.ID),
+		zap.String("locale", user.Locale),
+		zap.String("timezone", user.Timezone),
+	)
+
+	return &UpdateProfileOutput{
+		UserID:   user.ID,
+		Locale:   user.Locale,
+		Timezone: user.Timezone,
+	}, nil
+}
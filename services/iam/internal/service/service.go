@@ -15,20 +15,28 @@ import (
 // ErrSessionNotFoundOrExpired возвращается при невалидной/истёкшей сессии (handler маппит в codes.Unauthenticated)
 var ErrSessionNotFoundOrExpired = errors.New("session not found or expired")
 
+// ErrUserNotFound возвращается, когда пользователь не найден или удалён (handler маппит в codes.NotFound)
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUserUnderLegalHold возвращается при удалении пользователя под legal hold без override (handler маппит в codes.FailedPrecondition)
+var ErrUserUnderLegalHold = errors.New("user is under legal hold")
+
 // Service содержит бизнес-логику работы с пользователями
 type Service struct {
 	logger      *zap.Logger
 	repo        repository.UserRepository
 	sessionRepo repository.SessionRepository
+	publisher   UserEventPublisher
 	sessionTTL  time.Duration
 }
 
 // NewService создаёт новый экземпляр Service
-func NewService(logger *zap.Logger, repo repository.UserRepository, sessionRepo repository.SessionRepository, sessionTTL time.Duration) *Service {
+func NewService(logger *zap.Logger, repo repository.UserRepository, sessionRepo repository.SessionRepository, publisher UserEventPublisher, sessionTTL time.Duration) *Service {
 	return &Service{
 		logger:      logger,
 		repo:        repo,
 		sessionRepo: sessionRepo,
+		publisher:   publisher,
 		sessionTTL:  sessionTTL,
 	}
 }
@@ -38,6 +46,8 @@ type RegisterInput struct {
 	Login      string
 	Password   string
 	TelegramID *string
+	Locale     string // тег BCP-47, пусто - не задан
+	Timezone   string // имя IANA, пусто - не задан
 }
 
 // RegisterOutput содержит результат регистрации пользователя
@@ -57,6 +67,14 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (*RegisterO
 	if len(input.Password) < 6 {
 		return nil, fmt.Errorf("password must be at least 6 characters")
 	}
+	locale, err := normalizeLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+	timezone, err := normalizeTimezone(input.Timezone)
+	if err != nil {
+		return nil, err
+	}
 
 	// Хэшируем пароль через bcrypt
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
@@ -71,6 +89,8 @@ func (s *Service) Register(ctx context.Context, input RegisterInput) (*RegisterO
 		Login:        input.Login,
 		PasswordHash: string(passwordHash),
 		TelegramID:   input.TelegramID,
+		Locale:       locale,
+		Timezone:     timezone,
 		CreatedAt:    time.Now(),
 	}
 
@@ -208,6 +228,8 @@ type GetUserContactInput struct {
 type GetUserContactOutput struct {
 	TelegramID       *string
 	PreferredChannel string // на будущее
+	Locale           string // тег BCP-47, пусто - не задан
+	Timezone         string // имя IANA, пусто - не задан
 }
 
 // GetUserContact получает контактную информацию пользователя
@@ -217,19 +239,21 @@ func (s *Service) GetUserContact(ctx context.Context, input GetUserContactInput)
 		return nil, fmt.Errorf("user_id is required")
 	}
 
-	// Получаем пользователя по ID
-	user, err := s.repo.GetByID(ctx, input.UserID)
+	// Получаем контакт пользователя по ID (через кеш, если он включён)
+	contact, err := s.repo.GetContactByID(ctx, input.UserID)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return nil, fmt.Errorf("user not found")
 		}
-		s.logger.Error("failed to get user by id", zap.Error(err))
+		s.logger.Error("failed to get user contact by id", zap.Error(err))
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	return &GetUserContactOutput{
-		TelegramID:       user.TelegramID,
+		TelegramID:       contact.TelegramID,
 		PreferredChannel: "telegram", // на будущее
+		Locale:           contact.Locale,
+		Timezone:         contact.Timezone,
 	}, nil
 }
 
@@ -241,9 +265,11 @@ type ValidateSessionInput struct {
 // ValidateSessionOutput содержит результат валидации сессии
 type ValidateSessionOutput struct {
 	UserID string
+	Role   string // repository.RoleUser или repository.RoleAdmin
 }
 
-// ValidateSession проверяет валидность сессии и возвращает user_id; при успехе продлевает TTL (sliding window)
+// ValidateSession проверяет валидность сессии и возвращает user_id и роль пользователя; при успехе продлевает TTL (sliding window)
+// Роль читается из хранилища пользователей при каждой проверке, а не хранится в сессии: снятие роли действует сразу
 func (s *Service) ValidateSession(ctx context.Context, input ValidateSessionInput) (*ValidateSessionOutput, error) {
 	if input.SessionID == "" {
 		return nil, fmt.Errorf("session_id is required")
@@ -273,7 +299,104 @@ func (s *Service) ValidateSession(ctx context.Context, input ValidateSessionInpu
 		return nil, fmt.Errorf("failed to refresh session: %w", err)
 	}
 
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSessionNotFoundOrExpired
+		}
+		s.logger.Error("failed to get session user",
+			zap.Error(err),
+			zap.String("user_id", userID),
+		)
+		return nil, fmt.Errorf("failed to get session user: %w", err)
+	}
+	role := user.Role
+	if role == "" {
+		role = repository.RoleUser
+	}
+
 	return &ValidateSessionOutput{
 		UserID: userID,
+		Role:   role,
+	}, nil
+}
+
+// DeleteUserInput содержит входные данные для удаления пользователя
+type DeleteUserInput struct {
+	UserID            string
+	RequestedBy       string
+	Reason            string
+	LegalHoldOverride bool
+}
+
+// DeleteUserOutput содержит результат удаления пользователя
+type DeleteUserOutput struct {
+	UserID          string
+	RevokedSessions int
+	AlreadyDeleted  bool
+}
+
+// DeleteUser удаляет пользователя (right to be forgotten)
+// Порядок: анонимизация + audit log (одна транзакция) -> отзыв всех сессий -> событие iam.user.deleted.
+// Если отзыв сессий или публикация не удались, вызов можно повторить: для уже удалённого пользователя
+// анонимизация и audit пропускаются, а сессии и событие обрабатываются заново
+func (s *Service) DeleteUser(ctx context.Context, input DeleteUserInput) (*DeleteUserOutput, error) {
+	if input.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	if input.RequestedBy == "" {
+		return nil, fmt.Errorf("requested_by is required")
+	}
+
+	alreadyDeleted, err := s.repo.DeleteUser(ctx, input.UserID, repository.UserDeletion{
+		RequestedBy:       input.RequestedBy,
+		Reason:            input.Reason,
+		LegalHoldOverride: input.LegalHoldOverride,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrLegalHold) {
+			s.logger.Warn("user deletion rejected: legal hold",
+				zap.String("user_id", input.UserID),
+				zap.String("requested_by", input.RequestedBy),
+			)
+			return nil, ErrUserUnderLegalHold
+		}
+		s.logger.Error("failed to delete user", zap.Error(err), zap.String("user_id", input.UserID))
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	revoked, err := s.sessionRepo.DeleteUserSessions(ctx, input.UserID)
+	if err != nil {
+		s.logger.Error("failed to revoke user sessions", zap.Error(err), zap.String("user_id", input.UserID))
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	event := UserDeletedEvent{
+		EventID:      "user-deleted-" + input.UserID,
+		EventType:    EventTypeUserDeleted,
+		EventVersion: 1,
+		OccurredAt:   time.Now().UTC(),
+		UserID:       input.UserID,
+	}
+	if err := s.publisher.PublishUserDeleted(ctx, event); err != nil {
+		s.logger.Error("failed to publish user deleted event", zap.Error(err), zap.String("user_id", input.UserID))
+		return nil, fmt.Errorf("failed to publish user deleted event: %w", err)
+	}
+
+	s.logger.Info("user deleted",
+		zap.String("user_id", input.UserID),
+		zap.String("requested_by", input.RequestedBy),
+		zap.Bool("legal_hold_override", input.LegalHoldOverride),
+		zap.Bool("already_deleted", alreadyDeleted),
+		zap.Int("revoked_sessions", revoked),
+	)
+
+	return &DeleteUserOutput{
+		UserID:          input.UserID,
+		RevokedSessions: revoked,
+		AlreadyDeleted:  alreadyDeleted,
 	}, nil
 }
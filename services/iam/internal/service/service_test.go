@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository"
+	"github.com/shestoi/GoBigTech/services/iam/internal/repository/mocks"
+)
+
+func TestService_ValidateSession(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		user     repository.User
+		userErr  error
+		wantRole string
+		wantErr  error
+	}{
+		{name: "admin", user: repository.User{ID: "user-1", Role: repository.RoleAdmin}, wantRole: repository.RoleAdmin},
+		{name: "user", user: repository.User{ID: "user-1", Role: repository.RoleUser}, wantRole: repository.RoleUser},
+		{name: "empty role is user", user: repository.User{ID: "user-1"}, wantRole: repository.RoleUser},
+		{name: "deleted user", userErr: repository.ErrNotFound, wantErr: ErrSessionNotFoundOrExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewUserRepository(t)
+			sessions := mocks.NewSessionRepository(t)
+			s := NewService(zap.NewNop(), repo, sessions, nil, time.Hour)
+
+			sessions.On("GetUserIDBySession", ctx, "sid").Return("user-1", nil).Once()
+			sessions.On("RefreshSession", ctx, "sid", time.Hour).Return(nil).Once()
+			repo.On("GetByID", ctx, "user-1").Return(tt.user, tt.userErr).Once()
+
+			got, err := s.ValidateSession(ctx, ValidateSessionInput{SessionID: "sid"})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &ValidateSessionOutput{UserID: "user-1", Role: tt.wantRole}, got)
+		})
+	}
+
+	t.Run("expired session does not read user", func(t *testing.T) {
+		sessions := mocks.NewSessionRepository(t)
+		s := NewService(zap.NewNop(), mocks.NewUserRepository(t), sessions, nil, time.Hour)
+		sessions.On("GetUserIDBySession", ctx, "expired").Return("", repository.ErrSessionNotFound).Once()
+
+		_, err := s.ValidateSession(ctx, ValidateSessionInput{SessionID: "expired"})
+
+		require.ErrorIs(t, err, ErrSessionNotFoundOrExpired)
+	})
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL, -- время удаления (анонимизации) пользователя
+    ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT false; -- данные нельзя удалять без явного override
+
+-- Журнал удалений пользователей: хранит только user_id и сведения о запросе, без персональных данных
+CREATE TABLE IF NOT EXISTS user_deletion_audit (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL, -- id удалённого пользователя
+    requested_by TEXT NOT NULL, -- кто запросил удаление (оператор, support тикет)
+    reason TEXT NOT NULL DEFAULT '', -- основание удаления
+    legal_hold BOOLEAN NOT NULL, -- был ли пользователь под legal hold в момент удаления
+    legal_hold_override BOOLEAN NOT NULL, -- удаление выполнено вопреки legal hold
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_deletion_audit_user_id ON user_deletion_audit(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_deletion_audit_user_id;
+DROP TABLE IF EXISTS user_deletion_audit;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS legal_hold,
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '', -- язык пользователя (BCP-47), пусто - не задан
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT ''; -- часовой пояс пользователя (IANA), пусто - не задан
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS locale;
+-- +goose StatementEnd
@@ -0,0 +1,151 @@
+// Package main содержит inventory-import - загрузку каталога и остатков в MongoDB Inventory Service.
+//
+// Файл (CSV или JSON) проверяется целиком, затем товары создаются или обновляются, а остатки на складах
+// выставляются в значения из файла. С -dry-run импорт только печатает план. Повторный запуск того же
+// файла ничего не меняет, поэтому им удобно воспроизводимо наполнять окружения.
+//
+// Подключение к MongoDB берётся из конфигурации сервиса (APP_ENV, INVENTORY_MONGO_URI, INVENTORY_MONGO_DB).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/config"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/importer"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	mongorepo "github.com/shestoi/GoBigTech/services/inventory/internal/repository/mongo"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+func main() {
+	file := flag.String("file", "", "path to the import file, '-' for stdin")
+	format := flag.String("format", "", "file format: csv or json (default: by file extension)")
+	dryRun := flag.Bool("dry-run", false, "validate the file and print the plan without writing")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, err := platformlogging.New(platformlogging.Config{
+		ServiceName: "inventory-import",
+		Env:         string(cfg.AppEnv),
+		Level:       os.Getenv("LOG_LEVEL"),
+		Format:      os.Getenv("LOG_FORMAT"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer platformlogging.Sync(logger)
+
+	records, err := readFile(*file, *format)
+	if err != nil {
+		logger.Fatal("failed to read import file", zap.Error(err))
+	}
+
+	// Контекст отменяется по SIGINT/SIGTERM: импорт прерывается, повторный запуск доделает остальное
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(connectCtx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		logger.Fatal("failed to connect to MongoDB", zap.Error(err))
+	}
+	defer func() {
+		if err := client.Disconnect(context.Background()); err != nil {
+			logger.Error("failed to disconnect from MongoDB", zap.Error(err))
+		}
+	}()
+	if err := client.Ping(connectCtx, nil); err != nil {
+		logger.Fatal("failed to ping MongoDB", zap.Error(err))
+	}
+
+	// Импорт может быть первым, кто обращается к базе: коллекции и индексы создаются так же, как при старте сервиса
+	if err := mongorepo.EnsureSchema(connectCtx, client.Database(cfg.MongoDBName), mongorepo.SchemaOptions{
+		ReservationRetention: cfg.ReservationRetention,
+	}); err != nil {
+		logger.Fatal("failed to ensure MongoDB schema", zap.Error(err))
+	}
+
+	importService := service.NewImportService(
+		mongorepo.NewProductRepository(client, cfg.MongoDBName),
+		mongorepo.NewRepository(client, cfg.MongoDBName, repository.ReadConsistencyStrong),
+		mongorepo.NewWarehouseRepository(client, cfg.MongoDBName),
+		mongorepo.NewMovementRepository(client, cfg.MongoDBName),
+	)
+
+	result, err := importService.Import(ctx, records, *dryRun)
+	printResult(os.Stdout, result, *dryRun)
+	if err != nil {
+		var validationErr *service.ImportValidationError
+		if errors.As(err, &validationErr) {
+			fmt.Fprintln(os.Stderr, validationErr.Error())
+			os.Exit(1)
+		}
+		logger.Fatal("import failed", zap.Error(err))
+	}
+}
+
+// readFile читает записи импорта из файла или stdin
+func readFile(path, format string) ([]service.ImportRecord, error) {
+	if format == "" {
+		if path == "-" {
+			return nil, errors.New("-format is required when reading from stdin")
+		}
+		var err error
+		if format, err = importer.FormatFromPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return importer.Read(r, format)
+}
+
+// printResult печатает итог импорта (или план для -dry-run)
+func printResult(w io.Writer, result service.ImportResult, dryRun bool) {
+	if dryRun {
+		fmt.Fprintln(w, "Dry run, nothing is written")
+	}
+	for _, productID := range result.Created {
+		fmt.Fprintf(w, "create product %s\n", productID)
+	}
+	for _, productID := range result.Updated {
+		fmt.Fprintf(w, "update product %s\n", productID)
+	}
+	for _, change := range result.StockChanges {
+		fmt.Fprintf(w, "set stock %s@%s: %d -> %d\n", change.ProductID, change.WarehouseID, change.From, change.To)
+	}
+	fmt.Fprintf(w, "Products: %d created, %d updated, %d unchanged; stock changes: %d\n",
+		len(result.Created), len(result.Updated), result.Unchanged, len(result.StockChanges))
+}
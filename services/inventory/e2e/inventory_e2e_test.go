@@ -9,13 +9,16 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	tc "github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/mongodb"
@@ -24,6 +27,8 @@ import (
 
 	// TODO: проверь пути до handler/service/repo
 	invhandler "github.com/shestoi/GoBigTech/services/inventory/internal/api/grpc"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/interceptor"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 	invrepo "github.com/shestoi/GoBigTech/services/inventory/internal/repository/mongo"
 	invservice "github.com/shestoi/GoBigTech/services/inventory/internal/service"
 )
@@ -63,6 +68,9 @@ func TestInventory_E2E_ReserveStock(t *testing.T) {
 	db := client.Database(dbName)
 	col := db.Collection("inventory")
 
+	// Коллекции с валидацией и индексы - как при старте сервиса
+	require.NoError(t, invrepo.EnsureSchema(ctx, db, invrepo.SchemaOptions{ReservationRetention: time.Hour}))
+
 	// очистка на всякий
 	_, _ = col.DeleteMany(ctx, bson.M{})
 
@@ -74,11 +82,17 @@ func TestInventory_E2E_ReserveStock(t *testing.T) {
 	require.NoError(t, err)
 
 	// 3) Поднимаем Inventory gRPC сервер внутри теста (реальные repo+service+handler)
-	repo := invrepo.NewRepository(client, dbName)
-	svc := invservice.NewInventoryService(repo)
-	h := invhandler.NewHandler(svc)
+	repo := invrepo.NewRepository(client, dbName, repository.ReadConsistencyStrong)
+	svc := invservice.NewInventoryService(repo, invrepo.NewReservationRepository(client, dbName), nil, nil, nil, nil, nil, nil)
+	h := invhandler.NewHandler(svc, invservice.NewCatalogService(invrepo.NewProductRepository(client, dbName)),
+		invservice.NewWarehouseService(invrepo.NewWarehouseRepository(client, dbName)),
+		invservice.NewStockJournal(nil, invrepo.NewMovementRepository(client, dbName), nil),
+		invservice.NewStockAnalyticsService(invrepo.NewStockAnalyticsRepository(client, dbName)),
+		invservice.NewStockSyncService(svc, repo, invrepo.NewMovementRepository(client, dbName), nil))
 
-	grpcSrv := grpc.NewServer()
+	// Auth interceptor в dev mode: цепочка как в сервисе, но без IAM
+	auth := interceptor.NewAuthInterceptor(nil, zap.NewNop(), interceptor.AuthBypass{Disabled: true, UserID: "e2e"})
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(auth.Unary()), grpc.StreamInterceptor(auth.Stream()))
 	inventorypb.RegisterInventoryServiceServer(grpcSrv, h)
 
 	lis, err := net.Listen("tcp", "127.0.0.1:0")
@@ -124,4 +138,175 @@ func TestInventory_E2E_ReserveStock(t *testing.T) {
 	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
 	require.NoError(t, err)
 	require.Equal(t, int32(32), doc.Stock)
+
+	// 7) приёмка: 32 + 8 = 40
+	addResp, err := c.AddStock(ctx, &inventorypb.AddStockRequest{
+		ProductId: "product-123",
+		Quantity:  8,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(40), addResp.Available)
+
+	// 8) приёмка нового товара создаёт документ
+	addResp, err = c.AddStock(ctx, &inventorypb.AddStockRequest{
+		ProductId: "product-new",
+		Quantity:  5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), addResp.Available)
+
+	err = col.FindOne(ctx, bson.M{"product_id": "product-new"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(5), doc.Stock)
+
+	// 9) чтение остатка с разной consistency: на standalone Mongo secondaryPreferred читает с primary
+	for _, consistency := range []inventorypb.ReadConsistency{
+		inventorypb.ReadConsistency_READ_CONSISTENCY_UNSPECIFIED,
+		inventorypb.ReadConsistency_READ_CONSISTENCY_STRONG,
+		inventorypb.ReadConsistency_READ_CONSISTENCY_EVENTUAL,
+	} {
+		stockResp, err := c.GetStock(ctx, &inventorypb.GetStockRequest{
+			ProductId:   "product-123",
+			Consistency: consistency,
+		})
+		require.NoError(t, err, consistency.String())
+		require.Equal(t, int32(40), stockResp.Available, consistency.String())
+	}
+
+	// 10) резерв с ID и снятие: остаток 40 -> 35 -> 40, повторное снятие - FailedPrecondition
+	reserveResp, err := c.ReserveStock(ctx, &inventorypb.ReserveStockRequest{
+		ProductId: "product-123",
+		Quantity:  5,
+		OrderId:   "order-1",
+	})
+	require.NoError(t, err)
+	require.True(t, reserveResp.Success)
+	require.NotEmpty(t, reserveResp.ReservationId)
+	require.Nil(t, reserveResp.ExpiresAt)
+
+	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(35), doc.Stock)
+
+	// повтор с тем же order_id возвращает исходный резерв и не списывает товар второй раз
+	retryResp, err := c.ReserveStock(ctx, &inventorypb.ReserveStockRequest{
+		ProductId: "product-123",
+		Quantity:  5,
+		OrderId:   "order-1",
+	})
+	require.NoError(t, err)
+	require.True(t, retryResp.Success)
+	require.Equal(t, reserveResp.ReservationId, retryResp.ReservationId)
+
+	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(35), doc.Stock)
+
+	_, err = c.ReserveStock(ctx, &inventorypb.ReserveStockRequest{
+		ProductId: "product-123",
+		Quantity:  6,
+		OrderId:   "order-1",
+	})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// аналитика считает активный резерв: доступно 35, зарезервировано 5, всё на складе по умолчанию
+	analyticsResp, err := c.GetStockAnalytics(ctx, &inventorypb.GetStockAnalyticsRequest{ProductIds: []string{"product-123", "product-new"}})
+	require.NoError(t, err)
+	require.Len(t, analyticsResp.Items, 2)
+	require.Equal(t, "product-123", analyticsResp.Items[0].ProductId)
+	require.Equal(t, int32(35), analyticsResp.Items[0].Available)
+	require.Equal(t, int32(5), analyticsResp.Items[0].Reserved)
+	require.Len(t, analyticsResp.Items[0].Warehouses, 1)
+	require.Equal(t, int32(5), analyticsResp.Items[0].Warehouses[0].Reserved)
+	require.Equal(t, int32(0), analyticsResp.Items[1].Reserved)
+
+	releaseResp, err := c.ReleaseReservation(ctx, &inventorypb.ReleaseReservationRequest{ReservationId: reserveResp.ReservationId})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), releaseResp.Quantity)
+
+	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(40), doc.Stock)
+
+	_, err = c.ReleaseReservation(ctx, &inventorypb.ReleaseReservationRequest{ReservationId: reserveResp.ReservationId})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// 11) резерв со сроком: после истечения sweeper возвращает товар в остаток
+	reserveResp, err = c.ReserveStock(ctx, &inventorypb.ReserveStockRequest{
+		ProductId:  "product-123",
+		Quantity:   3,
+		TtlSeconds: 60,
+	})
+	require.NoError(t, err)
+	require.True(t, reserveResp.Success)
+	require.NotNil(t, reserveResp.ExpiresAt)
+
+	expired, err := svc.ExpireReservations(ctx, reserveResp.ExpiresAt.AsTime().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 1, expired)
+
+	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(40), doc.Stock)
+
+	// 12) каталог: создание, чтение, обновление, дубликат sku, удаление
+	createResp, err := c.CreateProduct(ctx, &inventorypb.CreateProductRequest{
+		ProductId:  "product-123",
+		Sku:        "SKU-123",
+		Name:       "Чайник",
+		Price:      199900,
+		Attributes: map[string]string{"color": "white"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "RUB", createResp.Product.Currency)
+
+	_, err = c.CreateProduct(ctx, &inventorypb.CreateProductRequest{Sku: "SKU-123", Name: "Другой чайник"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	updateResp, err := c.UpdateProduct(ctx, &inventorypb.UpdateProductRequest{
+		ProductId: "product-123",
+		Sku:       "SKU-123",
+		Name:      "Чайник 2",
+		Price:     249900,
+		Currency:  "RUB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(249900), updateResp.Product.Price)
+	require.Empty(t, updateResp.Product.Attributes)
+
+	getResp, err := c.GetProduct(ctx, &inventorypb.GetProductRequest{ProductId: "product-123"})
+	require.NoError(t, err)
+	require.Equal(t, "Чайник 2", getResp.Product.Name)
+
+	// список каталога: фильтр по названию и наличию, курсорная пагинация
+	_, err = c.CreateProduct(ctx, &inventorypb.CreateProductRequest{ProductId: "product-124", Sku: "SKU-124", Name: "Чайник 3"})
+	require.NoError(t, err)
+
+	listResp, err := c.ListProducts(ctx, &inventorypb.ListProductsRequest{PageSize: 1, Query: "чайник"})
+	require.NoError(t, err)
+	require.Len(t, listResp.Products, 1)
+	require.Equal(t, "product-123", listResp.Products[0].ProductId)
+	require.NotEmpty(t, listResp.NextPageToken)
+
+	listResp, err = c.ListProducts(ctx, &inventorypb.ListProductsRequest{PageSize: 1, Query: "чайник", PageToken: listResp.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, listResp.Products, 1)
+	require.Equal(t, "product-124", listResp.Products[0].ProductId)
+	require.Empty(t, listResp.NextPageToken)
+
+	// у product-124 нет остатка на складе
+	listResp, err = c.ListProducts(ctx, &inventorypb.ListProductsRequest{Availability: inventorypb.StockAvailability_STOCK_AVAILABILITY_IN_STOCK})
+	require.NoError(t, err)
+	require.Len(t, listResp.Products, 1)
+	require.Equal(t, "product-123", listResp.Products[0].ProductId)
+
+	_, err = c.DeleteProduct(ctx, &inventorypb.DeleteProductRequest{ProductId: "product-123"})
+	require.NoError(t, err)
+	_, err = c.GetProduct(ctx, &inventorypb.GetProductRequest{ProductId: "product-123"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// остаток при удалении карточки не трогается
+	err = col.FindOne(ctx, bson.M{"product_id": "product-123"}).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, int32(40), doc.Stock)
 }
@@ -2,7 +2,15 @@ package grpcapi
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
 	inventorypb "github.com/shestoi/GoBigTech/services/inventory/v1"
 )
@@ -12,22 +20,36 @@ import (
 type Handler struct {
 	inventorypb.UnimplementedInventoryServiceServer
 	inventoryService *service.InventoryService
+	catalogService   *service.CatalogService
+	warehouseService *service.WarehouseService
+	stockJournal     *service.StockJournal
+	analyticsService *service.StockAnalyticsService
+	stockSync        *service.StockSyncService
 }
 
 // NewHandler создаёт новый gRPC handler
-func NewHandler(inventoryService *service.InventoryService) *Handler {
+func NewHandler(inventoryService *service.InventoryService, catalogService *service.CatalogService, warehouseService *service.WarehouseService, stockJournal *service.StockJournal, analyticsService *service.StockAnalyticsService, stockSync *service.StockSyncService) *Handler {
 	return &Handler{
 		inventoryService: inventoryService,
+		catalogService:   catalogService,
+		warehouseService: warehouseService,
+		stockJournal:     stockJournal,
+		analyticsService: analyticsService,
+		stockSync:        stockSync,
 	}
 }
 
 // GetStock обрабатывает gRPC запрос GetStock
 // Тонкий слой: преобразует protobuf типы в простые типы и вызывает service
+// Товар, удалённый из каталога, - codes.NotFound
 func (h *Handler) GetStock(ctx context.Context, req *inventorypb.GetStockRequest) (*inventorypb.GetStockResponse, error) {
 	// Вызываем service слой для получения количества товара
 	// gRPC handler только преобразует типы protobuf <-> простые типы
-	available, err := h.inventoryService.GetStock(ctx, req.GetProductId())
+	available, err := h.inventoryService.GetStock(ctx, req.GetProductId(), readConsistencyFromProto(req.GetConsistency()))
 	if err != nil {
+		if errors.Is(err, service.ErrProductDeleted) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 		return nil, err
 	}
 
@@ -37,17 +59,579 @@ func (h *Handler) GetStock(ctx context.Context, req *inventorypb.GetStockRequest
 	}, nil
 }
 
+// GetStockBatch обрабатывает gRPC запрос GetStockBatch (остатки многих товаров за один вызов)
+// Пустой или слишком длинный список, пустой product_id - codes.InvalidArgument
+func (h *Handler) GetStockBatch(ctx context.Context, req *inventorypb.GetStockBatchRequest) (*inventorypb.GetStockBatchResponse, error) {
+	levels, err := h.inventoryService.GetStockBatch(ctx, req.GetProductIds(), readConsistencyFromProto(req.GetConsistency()))
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyProductIDs) || errors.Is(err, service.ErrStockBatchTooLarge) || errors.Is(err, service.ErrProductIDRequired) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &inventorypb.GetStockBatchResponse{Items: make([]*inventorypb.ProductStock, 0, len(levels))}
+	for _, level := range levels {
+		resp.Items = append(resp.Items, &inventorypb.ProductStock{
+			ProductId: level.ProductID,
+			Available: level.Available,
+			Found:     level.Found,
+		})
+	}
+	return resp, nil
+}
+
+// WatchStock отправляет клиенту текущий остаток товара и затем каждое его изменение (server streaming)
+// Поток открыт, пока клиент не отменит вызов; пустой product_id - codes.InvalidArgument
+func (h *Handler) WatchStock(req *inventorypb.WatchStockRequest, stream grpc.ServerStreamingServer[inventorypb.StockUpdate]) error {
+	err := h.inventoryService.WatchStock(stream.Context(), req.GetProductId(), func(update service.StockUpdate) error {
+		return stream.Send(&inventorypb.StockUpdate{
+			ProductId:  update.ProductID,
+			Available:  update.Available,
+			Found:      update.Found,
+			Reason:     update.Reason,
+			OccurredAt: timestamppb.New(update.OccurredAt),
+		})
+	})
+	if errors.Is(err, service.ErrProductIDRequired) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
 // ReserveStock обрабатывает gRPC запрос ReserveStock
 // Тонкий слой: преобразует protobuf типы в простые типы и вызывает service
+// При успехе возвращает reservation_id, по которому резерв снимается через ReleaseReservation
+// Повтор с тем же ключом идемпотентности возвращает исходный резерв, с другим количеством - codes.AlreadyExists
+// Товар, удалённый из каталога, - codes.FailedPrecondition
 func (h *Handler) ReserveStock(ctx context.Context, req *inventorypb.ReserveStockRequest) (*inventorypb.ReserveStockResponse, error) {
 	// Вызываем service слой для резервирования товара
 	// gRPC handler только преобразует типы protobuf <-> простые типы
-	success, err := h.inventoryService.ReserveStock(ctx, req.GetProductId(), req.GetQuantity())
+	reservation, success, err := h.inventoryService.CreateReservation(ctx, service.CreateReservationInput{
+		ProductID:      req.GetProductId(),
+		Quantity:       req.GetQuantity(),
+		OrderID:        req.GetOrderId(),
+		TTL:            time.Duration(req.GetTtlSeconds()) * time.Second,
+		Strategy:       allocationStrategyFromProto(req.GetAllocationStrategy()),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrProductIDRequired) || errors.Is(err, service.ErrInvalidQuantity) ||
+			errors.Is(err, service.ErrInvalidTTL) || errors.Is(err, service.ErrInvalidAllocationStrategy) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, service.ErrIdempotencyKeyReused) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if errors.Is(err, service.ErrProductDeleted) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &inventorypb.ReserveStockResponse{
+		Success:       success,
+		ReservationId: reservation.ID,
+		Allocations:   warehouseStockToProto(reservation.Allocations),
+		Backordered:   reservation.Backordered,
+	}
+	if !reservation.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(reservation.ExpiresAt)
+	}
+	return resp, nil
+}
+
+// ReserveStockBatch обрабатывает gRPC запрос ReserveStockBatch (все позиции заказа или ничего)
+// Позиция с товаром, удалённым из каталога, - codes.FailedPrecondition для всего заказа
+func (h *Handler) ReserveStockBatch(ctx context.Context, req *inventorypb.ReserveStockBatchRequest) (*inventorypb.ReserveStockBatchResponse, error) {
+	items := make([]service.BatchItem, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		items = append(items, service.BatchItem{
+			ProductID: item.GetProductId(),
+			Quantity:  item.GetQuantity(),
+		})
+	}
+
+	out, success, err := h.inventoryService.ReserveStockBatch(ctx, service.ReserveStockBatchInput{
+		OrderID:  req.GetOrderId(),
+		Items:    items,
+		TTL:      time.Duration(req.GetTtlSeconds()) * time.Second,
+		Strategy: allocationStrategyFromProto(req.GetAllocationStrategy()),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyBatch) || errors.Is(err, service.ErrProductIDRequired) ||
+			errors.Is(err, service.ErrInvalidQuantity) || errors.Is(err, service.ErrInvalidTTL) ||
+			errors.Is(err, service.ErrInvalidAllocationStrategy) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, service.ErrProductDeleted) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if errors.Is(err, service.ErrIdempotencyKeyReused) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &inventorypb.ReserveStockBatchResponse{
+		Success:               success,
+		InsufficientProductId: out.InsufficientProductID,
+	}
+	for _, reservation := range out.Reservations {
+		resp.Reservations = append(resp.Reservations, &inventorypb.ReservedItem{
+			ReservationId: reservation.ID,
+			ProductId:     reservation.ProductID,
+			Quantity:      reservation.Quantity,
+			Allocations:   warehouseStockToProto(reservation.Allocations),
+			Backordered:   reservation.Backordered,
+		})
+		if resp.ExpiresAt == nil && !reservation.ExpiresAt.IsZero() {
+			resp.ExpiresAt = timestamppb.New(reservation.ExpiresAt)
+		}
+	}
+	return resp, nil
+}
+
+// ReleaseReservation обрабатывает gRPC запрос ReleaseReservation
+// Неизвестный резерв - codes.NotFound, уже снятый или истёкший - codes.FailedPrecondition
+func (h *Handler) ReleaseReservation(ctx context.Context, req *inventorypb.ReleaseReservationRequest) (*inventorypb.ReleaseReservationResponse, error) {
+	reservation, err := h.inventoryService.ReleaseReservation(ctx, req.GetReservationId())
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReservationIDRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrReservationNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrReservationNotActive):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, err
 	}
 
-	return &inventorypb.ReserveStockResponse{
-		Success: success,
+	return &inventorypb.ReleaseReservationResponse{
+		ReservationId: reservation.ID,
+		ProductId:     reservation.ProductID,
+		Quantity:      reservation.Quantity,
 	}, nil
 }
+
+// AddStock обрабатывает gRPC запрос AddStock (приёмка на склад)
+// Ошибки валидации маппятся в codes.InvalidArgument, неизвестный склад - в codes.NotFound
+func (h *Handler) AddStock(ctx context.Context, req *inventorypb.AddStockRequest) (*inventorypb.AddStockResponse, error) {
+	available, err := h.inventoryService.AddWarehouseStock(ctx, req.GetProductId(), req.GetWarehouseId(), req.GetQuantity())
+	if err != nil {
+		if errors.Is(err, service.ErrProductIDRequired) || errors.Is(err, service.ErrInvalidQuantity) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.AddStockResponse{
+		ProductId: req.GetProductId(),
+		Available: available,
+	}, nil
+}
+
+// AdjustStock обрабатывает gRPC запрос AdjustStock
+// Несовпадение остатка с expected_quantity возвращается в ответе (applied = false), а не ошибкой
+func (h *Handler) AdjustStock(ctx context.Context, req *inventorypb.AdjustStockRequest) (*inventorypb.AdjustStockResponse, error) {
+	result, err := h.stockSync.AdjustStock(ctx, service.StockSyncInput{
+		ProductID:   req.GetProductId(),
+		WarehouseID: req.GetWarehouseId(),
+		Expected:    req.GetExpectedQuantity(),
+		Quantity:    req.GetNewQuantity(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrProductIDRequired) || errors.Is(err, service.ErrInvalidSyncQuantity) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.AdjustStockResponse{
+		ProductId:       req.GetProductId(),
+		Applied:         result.Applied,
+		CurrentQuantity: result.Current,
+		Available:       result.Available,
+	}, nil
+}
+
+// GetWarehouseStock обрабатывает gRPC запрос GetWarehouseStock
+// Нет товара - codes.NotFound, склады не настроены - codes.FailedPrecondition
+func (h *Handler) GetWarehouseStock(ctx context.Context, req *inventorypb.GetWarehouseStockRequest) (*inventorypb.GetWarehouseStockResponse, error) {
+	stocks, err := h.inventoryService.GetWarehouseStock(ctx, req.GetProductId(), readConsistencyFromProto(req.GetConsistency()))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductIDRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, warehouseError(err)
+	}
+
+	resp := &inventorypb.GetWarehouseStockResponse{
+		ProductId:  req.GetProductId(),
+		Warehouses: warehouseStockToProto(stocks),
+	}
+	for _, s := range stocks {
+		resp.Available += s.Quantity
+	}
+	return resp, nil
+}
+
+// ListStockMovements обрабатывает gRPC запрос ListStockMovements
+// Неизвестная причина или пустой период - codes.InvalidArgument
+func (h *Handler) ListStockMovements(ctx context.Context, req *inventorypb.ListStockMovementsRequest) (*inventorypb.ListStockMovementsResponse, error) {
+	filter := repository.MovementFilter{
+		ProductID: req.GetProductId(),
+		Reason:    req.GetReason(),
+	}
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+
+	movements, err := h.stockJournal.ListMovements(ctx, filter, int(req.GetLimit()))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMovementReason) || errors.Is(err, service.ErrInvalidMovementPeriod) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &inventorypb.ListStockMovementsResponse{Movements: make([]*inventorypb.StockMovement, 0, len(movements))}
+	for _, m := range movements {
+		resp.Movements = append(resp.Movements, &inventorypb.StockMovement{
+			MovementId:    m.ID,
+			ProductId:     m.ProductID,
+			WarehouseId:   m.WarehouseID,
+			Delta:         m.Delta,
+			Available:     m.Available,
+			Reason:        m.Reason,
+			ReservationId: m.ReservationID,
+			OrderId:       m.OrderID,
+			AdjustmentId:  m.AdjustmentID,
+			Actor:         m.Actor,
+			CreatedAt:     timestamppb.New(m.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// GetStockAnalytics обрабатывает gRPC запрос GetStockAnalytics
+// Пустой или слишком длинный список product_ids, невалидный warehouse_id или page_token - codes.InvalidArgument
+func (h *Handler) GetStockAnalytics(ctx context.Context, req *inventorypb.GetStockAnalyticsRequest) (*inventorypb.GetStockAnalyticsResponse, error) {
+	page, err := h.analyticsService.GetStockAnalytics(ctx, service.StockAnalyticsInput{
+		ProductIDs:  req.GetProductIds(),
+		WarehouseID: req.GetWarehouseId(),
+		PageSize:    int(req.GetPageSize()),
+		PageToken:   req.GetPageToken(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrProductIDRequired) || errors.Is(err, service.ErrStockBatchTooLarge) ||
+			errors.Is(err, service.ErrInvalidWarehouseID) || errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &inventorypb.GetStockAnalyticsResponse{
+		Items:         make([]*inventorypb.StockSummary, 0, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, item := range page.Items {
+		summary := &inventorypb.StockSummary{
+			ProductId:   item.ProductID,
+			Available:   item.Available,
+			Reserved:    item.Reserved,
+			Backordered: item.Backordered,
+			Warehouses:  make([]*inventorypb.WarehouseStockSummary, 0, len(item.Warehouses)),
+		}
+		for _, w := range item.Warehouses {
+			summary.Warehouses = append(summary.Warehouses, &inventorypb.WarehouseStockSummary{
+				WarehouseId: w.WarehouseID,
+				Available:   w.Available,
+				Reserved:    w.Reserved,
+				Backordered: w.Backordered,
+			})
+		}
+		resp.Items = append(resp.Items, summary)
+	}
+	return resp, nil
+}
+
+// CreateWarehouse обрабатывает gRPC запрос CreateWarehouse
+// Занятый warehouse_id - codes.AlreadyExists
+func (h *Handler) CreateWarehouse(ctx context.Context, req *inventorypb.CreateWarehouseRequest) (*inventorypb.CreateWarehouseResponse, error) {
+	warehouse, err := h.warehouseService.CreateWarehouse(ctx, service.WarehouseInput{
+		WarehouseID: req.GetWarehouseId(),
+		Name:        req.GetName(),
+		Priority:    req.GetPriority(),
+	})
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.CreateWarehouseResponse{Warehouse: warehouseToProto(warehouse)}, nil
+}
+
+// GetWarehouse обрабатывает gRPC запрос GetWarehouse
+func (h *Handler) GetWarehouse(ctx context.Context, req *inventorypb.GetWarehouseRequest) (*inventorypb.GetWarehouseResponse, error) {
+	warehouse, err := h.warehouseService.GetWarehouse(ctx, req.GetWarehouseId())
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.GetWarehouseResponse{Warehouse: warehouseToProto(warehouse)}, nil
+}
+
+// UpdateWarehouse обрабатывает gRPC запрос UpdateWarehouse
+func (h *Handler) UpdateWarehouse(ctx context.Context, req *inventorypb.UpdateWarehouseRequest) (*inventorypb.UpdateWarehouseResponse, error) {
+	warehouse, err := h.warehouseService.UpdateWarehouse(ctx, service.WarehouseInput{
+		WarehouseID: req.GetWarehouseId(),
+		Name:        req.GetName(),
+		Priority:    req.GetPriority(),
+	})
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.UpdateWarehouseResponse{Warehouse: warehouseToProto(warehouse)}, nil
+}
+
+// DeleteWarehouse обрабатывает gRPC запрос DeleteWarehouse
+// Склад с остатком - codes.FailedPrecondition
+func (h *Handler) DeleteWarehouse(ctx context.Context, req *inventorypb.DeleteWarehouseRequest) (*inventorypb.DeleteWarehouseResponse, error) {
+	if err := h.warehouseService.DeleteWarehouse(ctx, req.GetWarehouseId()); err != nil {
+		return nil, warehouseError(err)
+	}
+
+	return &inventorypb.DeleteWarehouseResponse{}, nil
+}
+
+// ListWarehouses обрабатывает gRPC запрос ListWarehouses
+func (h *Handler) ListWarehouses(ctx context.Context, _ *inventorypb.ListWarehousesRequest) (*inventorypb.ListWarehousesResponse, error) {
+	warehouses, err := h.warehouseService.ListWarehouses(ctx)
+	if err != nil {
+		return nil, warehouseError(err)
+	}
+
+	resp := &inventorypb.ListWarehousesResponse{Warehouses: make([]*inventorypb.Warehouse, 0, len(warehouses))}
+	for _, warehouse := range warehouses {
+		resp.Warehouses = append(resp.Warehouses, warehouseToProto(warehouse))
+	}
+	return resp, nil
+}
+
+// CreateProduct обрабатывает gRPC запрос CreateProduct
+// Занятые product_id или sku - codes.AlreadyExists
+func (h *Handler) CreateProduct(ctx context.Context, req *inventorypb.CreateProductRequest) (*inventorypb.CreateProductResponse, error) {
+	product, err := h.catalogService.CreateProduct(ctx, service.ProductInput{
+		ProductID:         req.GetProductId(),
+		SKU:               req.GetSku(),
+		Name:              req.GetName(),
+		Price:             req.GetPrice(),
+		Currency:          req.GetCurrency(),
+		Attributes:        req.GetAttributes(),
+		LowStockThreshold: req.GetLowStockThreshold(),
+		BackorderLimit:    req.GetBackorderLimit(),
+		Category:          req.GetCategory(),
+	})
+	if err != nil {
+		return nil, productError(err)
+	}
+
+	return &inventorypb.CreateProductResponse{Product: productToProto(product)}, nil
+}
+
+// GetProduct обрабатывает gRPC запрос GetProduct
+func (h *Handler) GetProduct(ctx context.Context, req *inventorypb.GetProductRequest) (*inventorypb.GetProductResponse, error) {
+	product, err := h.catalogService.GetProduct(ctx, req.GetProductId())
+	if err != nil {
+		return nil, productError(err)
+	}
+
+	return &inventorypb.GetProductResponse{Product: productToProto(product)}, nil
+}
+
+// UpdateProduct обрабатывает gRPC запрос UpdateProduct
+func (h *Handler) UpdateProduct(ctx context.Context, req *inventorypb.UpdateProductRequest) (*inventorypb.UpdateProductResponse, error) {
+	product, err := h.catalogService.UpdateProduct(ctx, service.ProductInput{
+		ProductID:         req.GetProductId(),
+		SKU:               req.GetSku(),
+		Name:              req.GetName(),
+		Price:             req.GetPrice(),
+		Currency:          req.GetCurrency(),
+		Attributes:        req.GetAttributes(),
+		LowStockThreshold: req.GetLowStockThreshold(),
+		BackorderLimit:    req.GetBackorderLimit(),
+		Category:          req.GetCategory(),
+	})
+	if err != nil {
+		return nil, productError(err)
+	}
+
+	return &inventorypb.UpdateProductResponse{Product: productToProto(product)}, nil
+}
+
+// DeleteProduct обрабатывает gRPC запрос DeleteProduct (мягкое удаление)
+func (h *Handler) DeleteProduct(ctx context.Context, req *inventorypb.DeleteProductRequest) (*inventorypb.DeleteProductResponse, error) {
+	if err := h.catalogService.DeleteProduct(ctx, req.GetProductId()); err != nil {
+		return nil, productError(err)
+	}
+
+	return &inventorypb.DeleteProductResponse{}, nil
+}
+
+// UndeleteProduct обрабатывает gRPC запрос UndeleteProduct
+func (h *Handler) UndeleteProduct(ctx context.Context, req *inventorypb.UndeleteProductRequest) (*inventorypb.UndeleteProductResponse, error) {
+	product, err := h.catalogService.UndeleteProduct(ctx, req.GetProductId())
+	if err != nil {
+		return nil, productError(err)
+	}
+
+	return &inventorypb.UndeleteProductResponse{Product: productToProto(product)}, nil
+}
+
+// ListProducts обрабатывает gRPC запрос ListProducts
+// Невалидный page_token - codes.InvalidArgument
+func (h *Handler) ListProducts(ctx context.Context, req *inventorypb.ListProductsRequest) (*inventorypb.ListProductsResponse, error) {
+	page, err := h.catalogService.ListProducts(ctx, service.ListProductsInput{
+		PageSize:       int(req.GetPageSize()),
+		PageToken:      req.GetPageToken(),
+		Query:          req.GetQuery(),
+		Availability:   stockAvailabilityFromProto(req.GetAvailability()),
+		IncludeDeleted: req.GetIncludeDeleted(),
+	})
+	if err != nil {
+		return nil, productError(err)
+	}
+
+	products := make([]*inventorypb.Product, 0, len(page.Products))
+	for _, product := range page.Products {
+		products = append(products, productToProto(product))
+	}
+	return &inventorypb.ListProductsResponse{Products: products, NextPageToken: page.NextPageToken}, nil
+}
+
+// productError маппит ошибки каталога в gRPC статусы: валидация - InvalidArgument,
+// нет товара - NotFound, занятые product_id/sku - AlreadyExists; остальные возвращаются как есть
+func productError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrProductIDRequired), errors.Is(err, service.ErrSKURequired),
+		errors.Is(err, service.ErrNameRequired), errors.Is(err, service.ErrInvalidPrice),
+		errors.Is(err, service.ErrInvalidCurrency), errors.Is(err, service.ErrInvalidLowStockThreshold),
+		errors.Is(err, service.ErrInvalidBackorderLimit), errors.Is(err, service.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrProductAlreadyExists):
+		return status.Error(codes.AlreadyExists, repository.ErrProductAlreadyExists.Error())
+	}
+	return err
+}
+
+// warehouseError маппит ошибки складов в gRPC статусы: валидация - InvalidArgument, нет склада - NotFound,
+// занятый warehouse_id - AlreadyExists, склад с остатком или склады не настроены - FailedPrecondition
+func warehouseError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrWarehouseIDRequired), errors.Is(err, service.ErrInvalidWarehouseID),
+		errors.Is(err, service.ErrWarehouseNameRequired), errors.Is(err, service.ErrInvalidWarehousePriority):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrWarehouseAlreadyExists):
+		return status.Error(codes.AlreadyExists, repository.ErrWarehouseAlreadyExists.Error())
+	case errors.Is(err, repository.ErrWarehouseNotEmpty), errors.Is(err, service.ErrWarehousesNotConfigured):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+// warehouseToProto преобразует склад в protobuf
+func warehouseToProto(w repository.Warehouse) *inventorypb.Warehouse {
+	return &inventorypb.Warehouse{
+		WarehouseId: w.ID,
+		Name:        w.Name,
+		Priority:    w.Priority,
+		CreatedAt:   timestamppb.New(w.CreatedAt),
+		UpdatedAt:   timestamppb.New(w.UpdatedAt),
+	}
+}
+
+// warehouseStockToProto преобразует количества по складам в protobuf
+func warehouseStockToProto(stocks []repository.WarehouseStock) []*inventorypb.WarehouseStock {
+	if len(stocks) == 0 {
+		return nil
+	}
+	out := make([]*inventorypb.WarehouseStock, 0, len(stocks))
+	for _, s := range stocks {
+		out = append(out, &inventorypb.WarehouseStock{WarehouseId: s.WarehouseID, Quantity: s.Quantity})
+	}
+	return out
+}
+
+// productToProto преобразует карточку товара в protobuf
+func productToProto(p repository.Product) *inventorypb.Product {
+	pb := &inventorypb.Product{
+		ProductId:         p.ID,
+		Sku:               p.SKU,
+		Name:              p.Name,
+		Price:             p.Price,
+		Currency:          p.Currency,
+		Attributes:        p.Attributes,
+		LowStockThreshold: p.LowStockThreshold,
+		BackorderLimit:    p.BackorderLimit,
+		Category:          p.Category,
+		CreatedAt:         timestamppb.New(p.CreatedAt),
+		UpdatedAt:         timestamppb.New(p.UpdatedAt),
+	}
+	if p.DeletedAt != nil {
+		pb.DeletedAt = timestamppb.New(*p.DeletedAt)
+	}
+	return pb
+}
+
+// readConsistencyFromProto преобразует protobuf enum в repository.ReadConsistency
+// UNSPECIFIED и неизвестные значения - ReadConsistencyDefault (решает конфиг сервиса)
+func readConsistencyFromProto(c inventorypb.ReadConsistency) repository.ReadConsistency {
+	switch c {
+	case inventorypb.ReadConsistency_READ_CONSISTENCY_STRONG:
+		return repository.ReadConsistencyStrong
+	case inventorypb.ReadConsistency_READ_CONSISTENCY_EVENTUAL:
+		return repository.ReadConsistencyEventual
+	default:
+		return repository.ReadConsistencyDefault
+	}
+}
+
+// stockAvailabilityFromProto преобразует protobuf enum в repository.StockAvailability
+// UNSPECIFIED и неизвестные значения - StockAvailabilityAny
+func stockAvailabilityFromProto(a inventorypb.StockAvailability) repository.StockAvailability {
+	switch a {
+	case inventorypb.StockAvailability_STOCK_AVAILABILITY_IN_STOCK:
+		return repository.StockAvailabilityInStock
+	case inventorypb.StockAvailability_STOCK_AVAILABILITY_OUT_OF_STOCK:
+		return repository.StockAvailabilityOutOfStock
+	default:
+		return repository.StockAvailabilityAny
+	}
+}
+
+// allocationStrategyFromProto преобразует protobuf enum в service.AllocationStrategy
+// UNSPECIFIED и неизвестные значения - AllocationStrategyDefault (решает конфиг сервиса)
+func allocationStrategyFromProto(s inventorypb.AllocationStrategy) service.AllocationStrategy {
+	switch s {
+	case inventorypb.AllocationStrategy_ALLOCATION_STRATEGY_PRIORITY:
+		return service.AllocationStrategyPriority
+	case inventorypb.AllocationStrategy_ALLOCATION_STRATEGY_MOST_STOCK:
+		return service.AllocationStrategyMostStock
+	case inventorypb.AllocationStrategy_ALLOCATION_STRATEGY_SINGLE_WAREHOUSE:
+		return service.AllocationStrategySingleWarehouse
+	default:
+		return service.AllocationStrategyDefault
+	}
+}
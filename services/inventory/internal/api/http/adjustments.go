@@ -0,0 +1,362 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// maxAdjustmentBodyBytes - ограничение тела импорта корректировок (JSON или CSV)
+const maxAdjustmentBodyBytes = 1 << 20
+
+type adjustmentItemJSON struct {
+	ProductID   string `json:"product_id"`
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	Delta       int32  `json:"delta"`
+}
+
+type createAdjustmentRequest struct {
+	Reason string               `json:"reason"`
+	Items  []adjustmentItemJSON `json:"items"`
+}
+
+type reviewAdjustmentRequest struct {
+	Comment string `json:"comment"`
+}
+
+type adjustmentResponse struct {
+	AdjustmentID string               `json:"adjustment_id"`
+	Reason       string               `json:"reason"`
+	Status       string               `json:"status"`
+	Items        []adjustmentItemJSON `json:"items"`
+	CreatedBy    string               `json:"created_by"`
+	CreatedAt    time.Time            `json:"created_at"`
+	ReviewedBy   string               `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time           `json:"reviewed_at,omitempty"`
+	AppliedAt    *time.Time           `json:"applied_at,omitempty"`
+	Comment      string               `json:"comment,omitempty"`
+}
+
+type adjustmentListResponse struct {
+	Adjustments []adjustmentResponse `json:"adjustments"`
+}
+
+type movementResponse struct {
+	MovementID    string    `json:"movement_id"`
+	ProductID     string    `json:"product_id"`
+	WarehouseID   string    `json:"warehouse_id"`
+	Delta         int32     `json:"delta"`
+	Available     int32     `json:"available"`
+	Reason        string    `json:"reason"`
+	AdjustmentID  string    `json:"adjustment_id,omitempty"`
+	ReservationID string    `json:"reservation_id,omitempty"`
+	OrderID       string    `json:"order_id,omitempty"`
+	Actor         string    `json:"actor,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type movementListResponse struct {
+	Movements []movementResponse `json:"movements"`
+}
+
+// CreateAdjustment обрабатывает POST /admin/adjustments - черновик пакета корректировок
+// Тело - JSON {"reason", "items"} или CSV (Content-Type: text/csv) с заголовком product_id,warehouse_id,delta и ?reason=
+func (h *Handler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAdjustmentBodyBytes)
+
+	var req createAdjustmentRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		items, err := readAdjustmentCSV(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid CSV: %v", err), http.StatusBadRequest)
+			return
+		}
+		req = createAdjustmentRequest{Reason: r.URL.Query().Get("reason"), Items: items}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	input := service.AdjustmentInput{Reason: req.Reason, CreatedBy: userID(r)}
+	for _, item := range req.Items {
+		input.Items = append(input.Items, repository.AdjustmentItem{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Delta: item.Delta})
+	}
+
+	adjustment, err := h.adjustmentService.CreateAdjustment(r.Context(), input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, adjustmentToResponse(adjustment))
+}
+
+// ListAdjustments обрабатывает GET /admin/adjustments?status=&limit= - пакеты корректировок, новые первыми
+func (h *Handler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	adjustments, err := h.adjustmentService.ListAdjustments(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resp := adjustmentListResponse{Adjustments: make([]adjustmentResponse, 0, len(adjustments))}
+	for _, adjustment := range adjustments {
+		resp.Adjustments = append(resp.Adjustments, adjustmentToResponse(adjustment))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetAdjustment обрабатывает GET /admin/adjustments/{adjustment_id}; ?format=csv - экспорт позиций в CSV
+func (h *Handler) GetAdjustment(w http.ResponseWriter, r *http.Request) {
+	adjustment, err := h.adjustmentService.GetAdjustment(r.Context(), r.PathValue("adjustment_id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		rows := make([][]string, 0, len(adjustment.Items))
+		for _, item := range adjustment.Items {
+			rows = append(rows, []string{item.ProductID, item.WarehouseID, strconv.Itoa(int(item.Delta))})
+		}
+		writeCSV(w, "adjustment-"+adjustment.ID+".csv", []string{"product_id", "warehouse_id", "delta"}, rows)
+		return
+	}
+	writeJSON(w, http.StatusOK, adjustmentToResponse(adjustment))
+}
+
+// ApproveAdjustment обрабатывает POST /admin/adjustments/{adjustment_id}/approve - одобрение и применение пакета
+// Пакет, который не удалось применить, возвращается со статусом failed и кодом 409
+func (h *Handler) ApproveAdjustment(w http.ResponseWriter, r *http.Request) {
+	adjustment, err := h.adjustmentService.ApproveAdjustment(r.Context(), r.PathValue("adjustment_id"), userID(r))
+	if errors.Is(err, service.ErrAdjustmentNotApplied) && adjustment.ID != "" {
+		writeJSON(w, http.StatusConflict, adjustmentToResponse(adjustment))
+		return
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.Info("adjustment approved via admin API",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.Int("items", len(adjustment.Items)),
+		zap.String("user_id", userID(r)),
+	)
+	writeJSON(w, http.StatusOK, adjustmentToResponse(adjustment))
+}
+
+// RejectAdjustment обрабатывает POST /admin/adjustments/{adjustment_id}/reject; тело {"comment"} необязательно
+func (h *Handler) RejectAdjustment(w http.ResponseWriter, r *http.Request) {
+	var req reviewAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.RejectAdjustment(r.Context(), r.PathValue("adjustment_id"), userID(r), req.Comment)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adjustmentToResponse(adjustment))
+}
+
+// ListMovements обрабатывает GET /admin/movements?product_id=&adjustment_id=&reason=&from=&to=&limit= - журнал движений;
+// from/to - RFC3339, период [from, to); ?format=csv - экспорт
+func (h *Handler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitFromQuery(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := repository.MovementFilter{
+		ProductID:    query.Get("product_id"),
+		AdjustmentID: query.Get("adjustment_id"),
+		Reason:       query.Get("reason"),
+	}
+	if filter.From, ok = timeFromQuery(w, r, "from"); !ok {
+		return
+	}
+	if filter.To, ok = timeFromQuery(w, r, "to"); !ok {
+		return
+	}
+
+	movements, err := h.adjustmentService.ListMovements(r.Context(), filter, limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		rows := make([][]string, 0, len(movements))
+		for _, m := range movements {
+			rows = append(rows, []string{
+				m.CreatedAt.Format(time.RFC3339), m.ProductID, m.WarehouseID, strconv.Itoa(int(m.Delta)),
+				strconv.Itoa(int(m.Available)), m.Reason, m.AdjustmentID, m.ReservationID, m.OrderID, m.Actor,
+			})
+		}
+		writeCSV(w, "movements.csv",
+			[]string{"created_at", "product_id", "warehouse_id", "delta", "available", "reason", "adjustment_id",
+				"reservation_id", "order_id", "actor"}, rows)
+		return
+	}
+
+	resp := movementListResponse{Movements: make([]movementResponse, 0, len(movements))}
+	for _, m := range movements {
+		resp.Movements = append(resp.Movements, movementResponse{
+			MovementID:    m.ID,
+			ProductID:     m.ProductID,
+			WarehouseID:   m.WarehouseID,
+			Delta:         m.Delta,
+			Available:     m.Available,
+			Reason:        m.Reason,
+			AdjustmentID:  m.AdjustmentID,
+			ReservationID: m.ReservationID,
+			OrderID:       m.OrderID,
+			Actor:         m.Actor,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RequireApprover - HTTP middleware: пропускает только пользователей из approvers (user_id из сессии), иначе 403
+// Пустой approvers - одобрять и отклонять корректировки не может никто
+func RequireApprover(approvers []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(approvers))
+	for _, id := range approvers {
+		allowed[id] = struct{}{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := allowed[userID(r)]; !ok {
+				http.Error(w, "adjustment approver role required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readAdjustmentCSV читает позиции из CSV с заголовком; колонки product_id и delta обязательны, warehouse_id - нет
+func readAdjustmentCSV(body io.Reader) ([]adjustmentItemJSON, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	productCol, ok := columns["product_id"]
+	if !ok {
+		return nil, errors.New("header must contain product_id")
+	}
+	deltaCol, ok := columns["delta"]
+	if !ok {
+		return nil, errors.New("header must contain delta")
+	}
+	warehouseCol, hasWarehouse := columns["warehouse_id"]
+
+	var items []adjustmentItemJSON
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		delta, err := strconv.ParseInt(record[deltaCol], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid delta %q", line, record[deltaCol])
+		}
+		item := adjustmentItemJSON{ProductID: record[productCol], Delta: int32(delta)}
+		if hasWarehouse {
+			item.WarehouseID = record[warehouseCol]
+		}
+		items = append(items, item)
+	}
+}
+
+// limitFromQuery читает ?limit=; при невалидном значении пишет 400 и возвращает false
+func limitFromQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	s := r.URL.Query().Get("limit")
+	if s == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil {
+		http.Error(w, "limit must be an integer", http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
+
+// timeFromQuery читает RFC3339-время из параметра name; пусто - нулевое время (граница не задана)
+func timeFromQuery(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		http.Error(w, name+" must be an RFC3339 timestamp", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func adjustmentToResponse(a repository.Adjustment) adjustmentResponse {
+	resp := adjustmentResponse{
+		AdjustmentID: a.ID,
+		Reason:       a.Reason,
+		Status:       a.Status,
+		Items:        make([]adjustmentItemJSON, 0, len(a.Items)),
+		CreatedBy:    a.CreatedBy,
+		CreatedAt:    a.CreatedAt,
+		ReviewedBy:   a.ReviewedBy,
+		Comment:      a.Comment,
+	}
+	for _, item := range a.Items {
+		resp.Items = append(resp.Items, adjustmentItemJSON{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Delta: item.Delta})
+	}
+	if !a.ReviewedAt.IsZero() {
+		reviewedAt := a.ReviewedAt
+		resp.ReviewedAt = &reviewedAt
+	}
+	if !a.AppliedAt.IsZero() {
+		appliedAt := a.AppliedAt
+		resp.AppliedAt = &appliedAt
+	}
+	return resp
+}
+
+// writeCSV пишет выгрузку в CSV как вложение filename
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writer := csv.NewWriter(w)
+	_ = writer.Write(header)
+	_ = writer.WriteAll(rows)
+}
@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	iamclient "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc"
+	iammocks "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc/mocks"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/interceptor"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+func TestRouter_Adjustments(t *testing.T) {
+	draft := repository.Adjustment{
+		ID:        "adj-1",
+		Reason:    "count",
+		Status:    repository.AdjustmentStatusDraft,
+		CreatedBy: "admin-1",
+		Items:     []repository.AdjustmentItem{{ProductID: "product-1", WarehouseID: repository.DefaultWarehouseID, Delta: 2}},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		contentType  string
+		body         string
+		sessionID    string
+		setup        func(adjustments *mocks.AdjustmentRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "create from json",
+			method:      http.MethodPost,
+			target:      "/admin/adjustments",
+			body:        `{"reason": "count", "items": [{"product_id": "product-1", "delta": 2}]}`,
+			sessionID:   "sid",
+			contentType: "application/json",
+			setup: func(adjustments *mocks.AdjustmentRepository) {
+				adjustments.On("CreateAdjustment", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "create from csv",
+			method:      http.MethodPost,
+			target:      "/admin/adjustments?reason=count",
+			body:        "product_id,warehouse_id,delta\nproduct-1,,2\nproduct-2,default,-1\n",
+			sessionID:   "sid",
+			contentType: "text/csv",
+			setup: func(adjustments *mocks.AdjustmentRepository) {
+				adjustments.On("CreateAdjustment", mock.Anything, mock.MatchedBy(func(a repository.Adjustment) bool {
+					return a.Reason == "count" && len(a.Items) == 2 && a.Items[1].Delta == -1
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "create from csv: invalid delta",
+			method:       http.MethodPost,
+			target:       "/admin/adjustments?reason=count",
+			body:         "product_id,delta\nproduct-1,many\n",
+			sessionID:    "sid",
+			contentType:  "text/csv",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "create: no reason",
+			method:       http.MethodPost,
+			target:       "/admin/adjustments",
+			body:         `{"items": [{"product_id": "product-1", "delta": 2}]}`,
+			sessionID:    "sid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:      "export csv",
+			method:    http.MethodGet,
+			target:    "/admin/adjustments/adj-1?format=csv",
+			sessionID: "sid",
+			setup: func(adjustments *mocks.AdjustmentRepository) {
+				adjustments.On("GetAdjustment", mock.Anything, "adj-1").Return(draft, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "product_id,warehouse_id,delta\nproduct-1,default,2\n",
+		},
+		{
+			name:         "approve: not an approver",
+			method:       http.MethodPost,
+			target:       "/admin/adjustments/adj-1/approve",
+			sessionID:    "sid",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:      "approve: own adjustment",
+			method:    http.MethodPost,
+			target:    "/admin/adjustments/adj-1/approve",
+			sessionID: "approver-sid",
+			setup: func(adjustments *mocks.AdjustmentRepository) {
+				own := draft
+				own.CreatedBy = "approver-1"
+				adjustments.On("GetAdjustment", mock.Anything, "adj-1").Return(own, nil).Once()
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:      "reject: already reviewed",
+			method:    http.MethodPost,
+			target:    "/admin/adjustments/adj-1/reject",
+			sessionID: "approver-sid",
+			setup: func(adjustments *mocks.AdjustmentRepository) {
+				adjustments.On("UpdateAdjustmentStatus", mock.Anything, "adj-1", repository.AdjustmentStatusDraft, mock.Anything).
+					Return(repository.Adjustment{}, repository.ErrAdjustmentStatusConflict).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjustments := mocks.NewAdjustmentRepository(t)
+			iamClient := iammocks.NewIAMClient(t)
+			iamClient.On("ValidateSession", mock.Anything, "sid").Return(iamclient.Session{UserID: "admin-1", Role: iamclient.RoleAdmin}, nil).Maybe()
+			iamClient.On("ValidateSession", mock.Anything, "approver-sid").Return(iamclient.Session{UserID: "approver-1", Role: iamclient.RoleAdmin}, nil).Maybe()
+			iamClient.On("ValidateSession", mock.Anything, "expired").Return(iamclient.Session{}, errors.New("session expired")).Maybe()
+			if tt.setup != nil {
+				tt.setup(adjustments)
+			}
+
+			inventoryService := service.NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+			adjustmentService := service.NewAdjustmentService(inventoryService, adjustments, mocks.NewMovementRepository(t))
+			auth := interceptor.NewAuthInterceptor(iamClient, zap.NewNop(), interceptor.AuthBypass{})
+			router := NewRouter(NewHandler(inventoryService, adjustmentService, nil, zap.NewNop()), auth.AdminHTTP, []string{"approver-1"}, func() bool { return true })
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(interceptor.SessionIDHeader, tt.sessionID)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
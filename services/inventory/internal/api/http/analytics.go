@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// stockSummaryResponse - сводка по товару: available уже за вычетом reserved
+type stockSummaryResponse struct {
+	ProductID   string                          `json:"product_id"`
+	Available   int32                           `json:"available"`
+	Reserved    int32                           `json:"reserved"`
+	Backordered int32                           `json:"backordered"`
+	Warehouses  []warehouseStockSummaryResponse `json:"warehouses"`
+}
+
+type warehouseStockSummaryResponse struct {
+	WarehouseID string `json:"warehouse_id"`
+	Available   int32  `json:"available"`
+	Reserved    int32  `json:"reserved"`
+	Backordered int32  `json:"backordered"`
+}
+
+type stockAnalyticsResponse struct {
+	Items         []stockSummaryResponse `json:"items"`
+	NextPageToken string                 `json:"next_page_token,omitempty"`
+}
+
+// GetStockAnalytics обрабатывает GET /admin/analytics/stock?product_id=a&product_id=b&warehouse_id=&page_size=&page_token=
+// Сводка "доступно / зарезервировано / сверх остатка" по товарам и складам, страницы по product_id
+func (h *Handler) GetStockAnalytics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var pageSize int
+	if s := query.Get("page_size"); s != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "page_size must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.analyticsService.GetStockAnalytics(r.Context(), service.StockAnalyticsInput{
+		ProductIDs:  query["product_id"],
+		WarehouseID: query.Get("warehouse_id"),
+		PageSize:    pageSize,
+		PageToken:   query.Get("page_token"),
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resp := stockAnalyticsResponse{
+		Items:         make([]stockSummaryResponse, 0, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, item := range page.Items {
+		summary := stockSummaryResponse{
+			ProductID:   item.ProductID,
+			Available:   item.Available,
+			Reserved:    item.Reserved,
+			Backordered: item.Backordered,
+			Warehouses:  make([]warehouseStockSummaryResponse, 0, len(item.Warehouses)),
+		}
+		for _, wh := range item.Warehouses {
+			summary.Warehouses = append(summary.Warehouses, warehouseStockSummaryResponse{
+				WarehouseID: wh.WarehouseID,
+				Available:   wh.Available,
+				Reserved:    wh.Reserved,
+				Backordered: wh.Backordered,
+			})
+		}
+		resp.Items = append(resp.Items, summary)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,318 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// Handler содержит HTTP-обработчики админского API Inventory Service (остатки, резервы, приёмка, корректировки)
+// Те же операции, что и в gRPC API, поверх того же service слоя
+type Handler struct {
+	inventoryService  *service.InventoryService
+	adjustmentService *service.AdjustmentService
+	analyticsService  *service.StockAnalyticsService
+	logger            *zap.Logger
+}
+
+// NewHandler создаёт HTTP handler админского API
+func NewHandler(inventoryService *service.InventoryService, adjustmentService *service.AdjustmentService, analyticsService *service.StockAnalyticsService, logger *zap.Logger) *Handler {
+	return &Handler{
+		inventoryService:  inventoryService,
+		adjustmentService: adjustmentService,
+		analyticsService:  analyticsService,
+		logger:            logger,
+	}
+}
+
+// stockResponse - остаток товара; warehouses есть, только если склады настроены
+type stockResponse struct {
+	ProductID  string                   `json:"product_id"`
+	Available  int32                    `json:"available"`
+	Found      *bool                    `json:"found,omitempty"`
+	Warehouses []warehouseStockResponse `json:"warehouses,omitempty"`
+}
+
+type warehouseStockResponse struct {
+	WarehouseID string `json:"warehouse_id"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type stockBatchResponse struct {
+	Items []stockResponse `json:"items"`
+}
+
+type replenishRequest struct {
+	Quantity    int32  `json:"quantity"`
+	WarehouseID string `json:"warehouse_id"` // пусто - склад по умолчанию
+}
+
+type reservationResponse struct {
+	ReservationID string                   `json:"reservation_id"`
+	OrderID       string                   `json:"order_id,omitempty"`
+	ProductID     string                   `json:"product_id"`
+	Quantity      int32                    `json:"quantity"`
+	Status        string                   `json:"status"`
+	CreatedAt     time.Time                `json:"created_at"`
+	ExpiresAt     *time.Time               `json:"expires_at,omitempty"`
+	Allocations   []warehouseStockResponse `json:"allocations,omitempty"`
+	Backordered   int32                    `json:"backordered,omitempty"`
+}
+
+type reservationListResponse struct {
+	Reservations []reservationResponse `json:"reservations"`
+}
+
+type backorderResponse struct {
+	BackorderID   string    `json:"backorder_id"`
+	ProductID     string    `json:"product_id"`
+	OrderID       string    `json:"order_id,omitempty"`
+	ReservationID string    `json:"reservation_id,omitempty"`
+	Quantity      int32     `json:"quantity"`
+	Backordered   int32     `json:"backordered"`
+	Available     int32     `json:"available"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type backorderListResponse struct {
+	Backorders []backorderResponse `json:"backorders"`
+}
+
+// GetStockBatch обрабатывает GET /admin/stock?product_id=a&product_id=b - остатки нескольких товаров
+func (h *Handler) GetStockBatch(w http.ResponseWriter, r *http.Request) {
+	consistency, err := readConsistencyFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	levels, err := h.inventoryService.GetStockBatch(r.Context(), r.URL.Query()["product_id"], consistency)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resp := stockBatchResponse{Items: make([]stockResponse, 0, len(levels))}
+	for _, level := range levels {
+		found := level.Found
+		resp.Items = append(resp.Items, stockResponse{ProductID: level.ProductID, Available: level.Available, Found: &found})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetStock обрабатывает GET /admin/stock/{product_id} - остаток товара и его разбивка по складам
+func (h *Handler) GetStock(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("product_id")
+	consistency, err := readConsistencyFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	available, err := h.inventoryService.GetStock(r.Context(), productID, consistency)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	resp := stockResponse{ProductID: productID, Available: available}
+
+	// Разбивка по складам - дополнительная информация: без складов отдаём только общий остаток
+	stocks, err := h.inventoryService.GetWarehouseStock(r.Context(), productID, consistency)
+	switch {
+	case err == nil:
+		resp.Warehouses = warehouseStockToResponse(stocks)
+	case !errors.Is(err, service.ErrWarehousesNotConfigured):
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ReplenishStock обрабатывает POST /admin/stock/{product_id}/replenish - приёмка товара на склад
+func (h *Handler) ReplenishStock(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("product_id")
+
+	var req replenishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	available, err := h.inventoryService.AddWarehouseStock(r.Context(), productID, req.WarehouseID, req.Quantity)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.Info("stock replenished via admin API",
+		zap.String("product_id", productID),
+		zap.String("warehouse_id", req.WarehouseID),
+		zap.Int32("quantity", req.Quantity),
+		zap.String("user_id", userID(r)),
+	)
+	writeJSON(w, http.StatusOK, stockResponse{ProductID: productID, Available: available})
+}
+
+// ListReservations обрабатывает GET /admin/reservations?product_id=&order_id=&status=&limit= - резервы, новые первыми
+func (h *Handler) ListReservations(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitFromQuery(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := repository.ReservationFilter{
+		ProductID: query.Get("product_id"),
+		OrderID:   query.Get("order_id"),
+		Status:    query.Get("status"),
+	}
+
+	reservations, err := h.inventoryService.ListReservations(r.Context(), filter, limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resp := reservationListResponse{Reservations: make([]reservationResponse, 0, len(reservations))}
+	for _, reservation := range reservations {
+		resp.Reservations = append(resp.Reservations, reservationToResponse(reservation))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetReservation обрабатывает GET /admin/reservations/{reservation_id}
+func (h *Handler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.inventoryService.GetReservation(r.Context(), r.PathValue("reservation_id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reservationToResponse(reservation))
+}
+
+// ReleaseReservation обрабатывает POST /admin/reservations/{reservation_id}/release - снятие резерва с возвратом товара
+func (h *Handler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.inventoryService.ReleaseReservation(r.Context(), r.PathValue("reservation_id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.Info("reservation released via admin API",
+		zap.String("reservation_id", reservation.ID),
+		zap.String("user_id", userID(r)),
+	)
+	writeJSON(w, http.StatusOK, reservationToResponse(reservation))
+}
+
+// ListBackorders обрабатывает GET /admin/backorders?product_id=&limit= - журнал резервирований сверх остатка, новые первыми
+func (h *Handler) ListBackorders(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	backorders, err := h.inventoryService.ListBackorders(r.Context(), r.URL.Query().Get("product_id"), limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resp := backorderListResponse{Backorders: make([]backorderResponse, 0, len(backorders))}
+	for _, b := range backorders {
+		resp.Backorders = append(resp.Backorders, backorderResponse{
+			BackorderID:   b.ID,
+			ProductID:     b.ProductID,
+			OrderID:       b.OrderID,
+			ReservationID: b.ReservationID,
+			Quantity:      b.Quantity,
+			Backordered:   b.Backordered,
+			Available:     b.Available,
+			CreatedAt:     b.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeError маппит ошибки service/repository в HTTP статусы так же, как gRPC handler - в codes
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrProductIDRequired), errors.Is(err, service.ErrInvalidQuantity),
+		errors.Is(err, service.ErrEmptyProductIDs), errors.Is(err, service.ErrStockBatchTooLarge),
+		errors.Is(err, service.ErrReservationIDRequired), errors.Is(err, service.ErrInvalidReservationStatus),
+		errors.Is(err, service.ErrWarehouseIDRequired), errors.Is(err, service.ErrInvalidWarehouseID),
+		errors.Is(err, service.ErrAdjustmentIDRequired), errors.Is(err, service.ErrAdjustmentReasonRequired),
+		errors.Is(err, service.ErrEmptyAdjustment), errors.Is(err, service.ErrAdjustmentTooLarge),
+		errors.Is(err, service.ErrInvalidAdjustmentDelta), errors.Is(err, service.ErrInvalidAdjustmentStatus),
+		errors.Is(err, service.ErrAdjustmentActorRequired), errors.Is(err, service.ErrInvalidMovementReason),
+		errors.Is(err, service.ErrInvalidMovementPeriod), errors.Is(err, service.ErrInvalidPageToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrSelfApproval):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrReservationNotFound), errors.Is(err, service.ErrProductDeleted),
+		errors.Is(err, repository.ErrWarehouseNotFound), errors.Is(err, repository.ErrAdjustmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrReservationNotActive), errors.Is(err, service.ErrWarehousesNotConfigured),
+		errors.Is(err, repository.ErrAdjustmentStatusConflict), errors.Is(err, service.ErrAdjustmentNotApplied):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.Error("admin API request failed",
+			zap.Error(err),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// readConsistencyFromQuery читает ?consistency=strong|eventual; пусто - настройка сервиса
+func readConsistencyFromQuery(r *http.Request) (repository.ReadConsistency, error) {
+	switch c := repository.ReadConsistency(r.URL.Query().Get("consistency")); c {
+	case repository.ReadConsistencyDefault, repository.ReadConsistencyStrong, repository.ReadConsistencyEventual:
+		return c, nil
+	default:
+		return "", fmt.Errorf("consistency must be 'strong' or 'eventual'")
+	}
+}
+
+func reservationToResponse(r repository.Reservation) reservationResponse {
+	resp := reservationResponse{
+		ReservationID: r.ID,
+		OrderID:       r.OrderID,
+		ProductID:     r.ProductID,
+		Quantity:      r.Quantity,
+		Status:        r.Status,
+		CreatedAt:     r.CreatedAt,
+		Allocations:   warehouseStockToResponse(r.Allocations),
+		Backordered:   r.Backordered,
+	}
+	if !r.ExpiresAt.IsZero() {
+		expiresAt := r.ExpiresAt
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+func warehouseStockToResponse(stocks []repository.WarehouseStock) []warehouseStockResponse {
+	if len(stocks) == 0 {
+		return nil
+	}
+	resp := make([]warehouseStockResponse, 0, len(stocks))
+	for _, s := range stocks {
+		resp = append(resp, warehouseStockResponse{WarehouseID: s.WarehouseID, Quantity: s.Quantity})
+	}
+	return resp
+}
+
+// writeJSON пишет тело ответа в JSON
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
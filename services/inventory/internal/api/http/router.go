@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"net/http"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/http"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/interceptor"
+)
+
+// NewRouter создаёт роутер админского HTTP API Inventory Service
+// auth оборачивает все /admin/* маршруты (сессия администратора IAM, см. interceptor.AuthInterceptor.AdminHTTP).
+// approvers - user_id, которым разрешено одобрять и отклонять корректировки остатка; пусто - никому.
+// readiness - проверка готовности для GET /health; /health доступен без сессии.
+func NewRouter(handler *Handler, auth func(http.Handler) http.Handler, approvers []string, readiness func() bool) http.Handler {
+	requireApprover := RequireApprover(approvers)
+
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /admin/stock", handler.GetStockBatch)
+	admin.HandleFunc("GET /admin/stock/{product_id}", handler.GetStock)
+	admin.HandleFunc("POST /admin/stock/{product_id}/replenish", handler.ReplenishStock)
+	admin.HandleFunc("GET /admin/reservations", handler.ListReservations)
+	admin.HandleFunc("GET /admin/reservations/{reservation_id}", handler.GetReservation)
+	admin.HandleFunc("POST /admin/reservations/{reservation_id}/release", handler.ReleaseReservation)
+	admin.HandleFunc("GET /admin/backorders", handler.ListBackorders)
+	admin.HandleFunc("GET /admin/analytics/stock", handler.GetStockAnalytics)
+	admin.HandleFunc("POST /admin/adjustments", handler.CreateAdjustment)
+	admin.HandleFunc("GET /admin/adjustments", handler.ListAdjustments)
+	admin.HandleFunc("GET /admin/adjustments/{adjustment_id}", handler.GetAdjustment)
+	admin.Handle("POST /admin/adjustments/{adjustment_id}/approve", requireApprover(http.HandlerFunc(handler.ApproveAdjustment)))
+	admin.Handle("POST /admin/adjustments/{adjustment_id}/reject", requireApprover(http.HandlerFunc(handler.RejectAdjustment)))
+	admin.HandleFunc("GET /admin/movements", handler.ListMovements)
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", auth(admin))
+	mux.Handle("GET /health", platformhealth.Handler(readiness))
+	return mux
+}
+
+// userID возвращает user_id администратора из context (кладёт auth middleware) для аудита в логах
+func userID(r *http.Request) string {
+	userID, _ := interceptor.UserIDFromContext(r.Context())
+	return userID
+}
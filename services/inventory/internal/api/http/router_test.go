@@ -0,0 +1,259 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	iamclient "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc"
+	iammocks "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc/mocks"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/interceptor"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+func newTestRouter(t *testing.T) (http.Handler, *mocks.InventoryRepository, *mocks.ReservationRepository) {
+	repo := mocks.NewInventoryRepository(t)
+	reservations := mocks.NewReservationRepository(t)
+	iamClient := iammocks.NewIAMClient(t)
+	iamClient.On("ValidateSession", mock.Anything, "sid").Return(iamclient.Session{UserID: "admin-1", Role: iamclient.RoleAdmin}, nil).Maybe()
+	iamClient.On("ValidateSession", mock.Anything, "expired").Return(iamclient.Session{}, errors.New("session expired")).Maybe()
+	iamClient.On("ValidateSession", mock.Anything, "user-sid").Return(iamclient.Session{UserID: "user-1", Role: "user"}, nil).Maybe()
+
+	inventoryService := service.NewInventoryService(repo, reservations, nil, nil, nil, nil, nil, nil)
+	auth := interceptor.NewAuthInterceptor(iamClient, zap.NewNop(), interceptor.AuthBypass{})
+	router := NewRouter(NewHandler(inventoryService, nil, nil, zap.NewNop()), auth.AdminHTTP, nil, func() bool { return true })
+	return router, repo, reservations
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		sessionID    string
+		setup        func(repo *mocks.InventoryRepository, reservations *mocks.ReservationRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "health does not require session",
+			method:       http.MethodGet,
+			target:       "/health",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "admin requires session",
+			method:       http.MethodGet,
+			target:       "/admin/stock/product-1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid session",
+			method:       http.MethodGet,
+			target:       "/admin/stock/product-1",
+			sessionID:    "expired",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "admin requires admin role",
+			method:       http.MethodGet,
+			target:       "/admin/stock/product-1",
+			sessionID:    "user-sid",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:      "get stock",
+			method:    http.MethodGet,
+			target:    "/admin/stock/product-1?consistency=eventual",
+			sessionID: "sid",
+			setup: func(repo *mocks.InventoryRepository, _ *mocks.ReservationRepository) {
+				repo.On("GetStock", mock.Anything, "product-1", repository.ReadConsistencyEventual).Return(int32(7), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"product_id":"product-1","available":7}`,
+		},
+		{
+			name:      "get stock: not found",
+			method:    http.MethodGet,
+			target:    "/admin/stock/product-1",
+			sessionID: "sid",
+			setup: func(repo *mocks.InventoryRepository, _ *mocks.ReservationRepository) {
+				repo.On("GetStock", mock.Anything, "product-1", repository.ReadConsistencyDefault).Return(int32(0), repository.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "get stock: invalid consistency",
+			method:       http.MethodGet,
+			target:       "/admin/stock/product-1?consistency=fast",
+			sessionID:    "sid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:      "get stock batch",
+			method:    http.MethodGet,
+			target:    "/admin/stock?product_id=product-1&product_id=product-2",
+			sessionID: "sid",
+			setup: func(repo *mocks.InventoryRepository, _ *mocks.ReservationRepository) {
+				repo.On("GetStockBatch", mock.Anything, []string{"product-1", "product-2"}, repository.ReadConsistencyDefault).
+					Return(map[string]int32{"product-1": 3}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[{"product_id":"product-1","available":3,"found":true},{"product_id":"product-2","available":0,"found":false}]}`,
+		},
+		{
+			name:         "get stock batch: no products",
+			method:       http.MethodGet,
+			target:       "/admin/stock",
+			sessionID:    "sid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:      "replenish",
+			method:    http.MethodPost,
+			target:    "/admin/stock/product-1/replenish",
+			body:      `{"quantity": 5}`,
+			sessionID: "sid",
+			setup: func(repo *mocks.InventoryRepository, _ *mocks.ReservationRepository) {
+				repo.On("AddStock", mock.Anything, "product-1", int32(5)).Return(int32(12), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"product_id":"product-1","available":12}`,
+		},
+		{
+			name:         "replenish: invalid quantity",
+			method:       http.MethodPost,
+			target:       "/admin/stock/product-1/replenish",
+			body:         `{"quantity": 0}`,
+			sessionID:    "sid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "replenish: warehouses not configured",
+			method:       http.MethodPost,
+			target:       "/admin/stock/product-1/replenish",
+			body:         `{"quantity": 1, "warehouse_id": "msk"}`,
+			sessionID:    "sid",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:      "list reservations",
+			method:    http.MethodGet,
+			target:    "/admin/reservations?order_id=order-1&limit=10",
+			sessionID: "sid",
+			setup: func(_ *mocks.InventoryRepository, reservations *mocks.ReservationRepository) {
+				reservations.On("ListReservations", mock.Anything, repository.ReservationFilter{OrderID: "order-1"}, 10).
+					Return([]repository.Reservation{{ID: "res-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2, Status: repository.ReservationStatusActive}}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"reservations":[{"reservation_id":"res-1","order_id":"order-1","product_id":"product-1","quantity":2,"status":"active","created_at":"0001-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:         "list reservations: invalid limit",
+			method:       http.MethodGet,
+			target:       "/admin/reservations?limit=ten",
+			sessionID:    "sid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:      "get reservation: not found",
+			method:    http.MethodGet,
+			target:    "/admin/reservations/res-1",
+			sessionID: "sid",
+			setup: func(_ *mocks.InventoryRepository, reservations *mocks.ReservationRepository) {
+				reservations.On("GetReservation", mock.Anything, "res-1").Return(repository.Reservation{}, repository.ErrReservationNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:      "release reservation",
+			method:    http.MethodPost,
+			target:    "/admin/reservations/res-1/release",
+			sessionID: "sid",
+			setup: func(repo *mocks.InventoryRepository, reservations *mocks.ReservationRepository) {
+				reservations.On("FinishReservation", mock.Anything, "res-1", repository.ReservationStatusReleased).
+					Return(repository.Reservation{ID: "res-1", ProductID: "product-1", Quantity: 2, Status: repository.ReservationStatusReleased}, nil).Once()
+				repo.On("AddStock", mock.Anything, "product-1", int32(2)).Return(int32(9), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:      "release reservation: already released",
+			method:    http.MethodPost,
+			target:    "/admin/reservations/res-1/release",
+			sessionID: "sid",
+			setup: func(_ *mocks.InventoryRepository, reservations *mocks.ReservationRepository) {
+				reservations.On("FinishReservation", mock.Anything, "res-1", repository.ReservationStatusReleased).
+					Return(repository.Reservation{}, repository.ErrReservationNotActive).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo, reservations := newTestRouter(t)
+			if tt.setup != nil {
+				tt.setup(repo, reservations)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.sessionID != "" {
+				req.Header.Set(interceptor.SessionIDHeader, tt.sessionID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			if tt.expectedBody != "" {
+				require.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_StockAnalytics(t *testing.T) {
+	analytics := mocks.NewStockAnalyticsRepository(t)
+	iamClient := iammocks.NewIAMClient(t)
+	iamClient.On("ValidateSession", mock.Anything, "sid").Return(iamclient.Session{UserID: "admin-1", Role: iamclient.RoleAdmin}, nil)
+	auth := interceptor.NewAuthInterceptor(iamClient, zap.NewNop(), interceptor.AuthBypass{})
+	handler := NewHandler(nil, nil, service.NewStockAnalyticsService(analytics), zap.NewNop())
+	router := NewRouter(handler, auth.AdminHTTP, nil, func() bool { return true })
+
+	analytics.On("GetStockAnalytics", mock.Anything, repository.StockAnalyticsFilter{
+		ProductIDs:  []string{"product-1"},
+		WarehouseID: "msk",
+		Limit:       2,
+	}).Return([]repository.StockSummary{{
+		ProductID:   "product-1",
+		Available:   -2,
+		Reserved:    5,
+		Backordered: 2,
+		Warehouses:  []repository.WarehouseStockSummary{{WarehouseID: "msk", Available: 1, Reserved: 3}},
+	}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/analytics/stock?product_id=product-1&warehouse_id=msk&page_size=1", nil)
+	req.Header.Set(interceptor.SessionIDHeader, "sid")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `{"items":[{"product_id":"product-1","available":-2,"reserved":5,"backordered":2,
+		"warehouses":[{"warehouse_id":"msk","available":1,"reserved":3,"backordered":0}]}]}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/analytics/stock?page_token=!!!", nil)
+	req.Header.Set(interceptor.SessionIDHeader, "sid")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -2,24 +2,36 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
 	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	platformshutdown "github.com/shestoi/GoBigTech/platform/shutdown"
 	grpcapi "github.com/shestoi/GoBigTech/services/inventory/internal/api/grpc"
+	httpapi "github.com/shestoi/GoBigTech/services/inventory/internal/api/http"
 	iamclient "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc"
 	"github.com/shestoi/GoBigTech/services/inventory/internal/config"
+	eventkafka "github.com/shestoi/GoBigTech/services/inventory/internal/event/kafka"
 	"github.com/shestoi/GoBigTech/services/inventory/internal/interceptor"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 	mongorepo "github.com/shestoi/GoBigTech/services/inventory/internal/repository/mongo"
+	redisrepo "github.com/shestoi/GoBigTech/services/inventory/internal/repository/redis"
 	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
 	inventorypb "github.com/shestoi/GoBigTech/services/inventory/v1"
 	"google.golang.org/grpc"
@@ -29,24 +41,34 @@ import (
 
 // App содержит все зависимости для запуска и корректного shutdown Inventory Service
 type App struct {
-	logger      *zap.Logger
-	grpcServer  *grpc.Server
-	listener    net.Listener
-	health      *platformhealth.Health
-	shutdownMgr *platformshutdown.Manager
-	wg          sync.WaitGroup
+	logger       *zap.Logger
+	grpcServer   *grpc.Server
+	listener     net.Listener
+	adminServer  *http.Server // nil - админский HTTP API выключен
+	health       *platformhealth.Health
+	mongoMonitor *mongoMonitor // nil, если проверка MongoDB для readiness выключена
+	shutdownMgr  *platformshutdown.Manager
+	sweeper      *service.ReservationSweeper
+	wg           sync.WaitGroup
+
+	orderCancelledConsumer *eventkafka.OrderCancelledConsumer // nil, если все топики отмены заказов выключены
 }
 
 // Build создаёт и настраивает все зависимости Inventory Service
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "inventory",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
@@ -68,6 +90,12 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Создаём health check с начальным статусом NOT_SERVING
 	health := platformhealth.New(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
 
@@ -76,7 +104,12 @@ func Build(cfg config.Config) (*App, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	mongoOpts := options.Client().ApplyURI(cfg.MongoURI)
+	if cfg.OTelEnabled {
+		// Span и метрики на каждую команду MongoDB всех repository
+		mongoOpts.SetMonitor(mongorepo.NewCommandMonitor())
+	}
+	client, err := mongo.Connect(ctx, mongoOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -92,15 +125,141 @@ func Build(cfg config.Config) (*App, error) {
 	health.SetServing("")
 	logger.Info("Readiness status set to SERVING")
 
+	// Коллекции inventory и reservations: валидация JSON Schema, индексы и TTL индекс завершённых резервов
+	if err := mongorepo.EnsureSchema(ctx, client.Database(cfg.MongoDBName), mongorepo.SchemaOptions{
+		ReservationRetention: cfg.ReservationRetention,
+	}); err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ensure MongoDB schema: %w", err)
+	}
+	logger.Info("MongoDB schema ensured", zap.Duration("reservation_retention", cfg.ReservationRetention))
+
 	// Создаём MongoDB репозиторий
-	inventoryRepo := mongorepo.NewRepository(client, cfg.MongoDBName)
+	// Чтения остатка без явного consistency идут по INVENTORY_STOCK_READ_CONSISTENCY
+	inventoryRepo := mongorepo.NewRepository(client, cfg.MongoDBName, repository.ReadConsistency(cfg.StockReadConsistency))
+
+	// Read-through кеш остатка в Redis для горячих товаров (INVENTORY_STOCK_CACHE_ENABLED)
+	var stockRepo redisrepo.StockRepository = inventoryRepo
+	var redisClient *redis.Client
+	if cfg.StockCacheEnabled {
+		logger.Info("Connecting to Redis for stock cache", zap.String("addr", cfg.RedisAddr), zap.Duration("ttl", cfg.StockCacheTTL))
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       0,
+		})
+		// Кеш необязателен: без Redis сервис стартует, чтения идут в MongoDB
+		if err := redisClient.Ping(ctx).Err(); err != nil {
+			logger.Warn("Redis is unavailable, stock reads will fall back to MongoDB", zap.Error(err))
+		}
+		stockRepo = redisrepo.NewCachedStockRepository(inventoryRepo, redisClient, cfg.StockCacheTTL, logger)
+	}
+
+	// Резервы с ID и сроком хранятся в отдельной коллекции
+	reservationRepo := mongorepo.NewReservationRepository(client, cfg.MongoDBName)
+
+	// Каталог товаров (карточки с sku, ценой и атрибутами) - коллекция products
+	productRepo := mongorepo.NewProductRepository(client, cfg.MongoDBName)
+
+	// Справочник складов; остатки по складам хранятся в документе товара рядом с общим остатком
+	warehouseRepo := mongorepo.NewWarehouseRepository(client, cfg.MongoDBName)
+	allocator := service.NewWarehouseAllocator(stockRepo, warehouseRepo, service.AllocationStrategy(cfg.AllocationStrategy))
+
+	// Метрики резервирования (исходы, длительность, write conflicts, проходы sweeper'а); при отключённом OTEL — noop
+	var reservationMetrics service.ReservationMetricsRecorder
+	var sweepMetrics service.ReservationSweepMetricsRecorder
+	if cfg.OTelEnabled {
+		recorder := newReservationMetricsRecorder()
+		reservationMetrics = recorder
+		sweepMetrics = recorder
+	}
+
+	// События inventory.stock.changed (best-effort); пустой топик отключает публикацию
+	var stockEvents service.StockEventPublisher
+	var stockPublisher *eventkafka.KafkaStockEventPublisher
+	if cfg.StockChangedTopic != "" {
+		logger.Info("Initializing Kafka stock changed publisher",
+			zap.Strings("brokers", cfg.KafkaBrokers),
+			zap.String("topic", cfg.StockChangedTopic),
+		)
+		stockPublisher = eventkafka.NewKafkaStockEventPublisher(logger, cfg.KafkaBrokers, cfg.StockChangedTopic)
+		stockEvents = stockPublisher
+	}
+
+	// События inventory.stock.low: монитор проверяет порог карточки товара после каждого резервирования
+	var stockLowPublisher *eventkafka.KafkaStockLowPublisher
+	if cfg.StockLowTopic != "" {
+		logger.Info("Initializing Kafka stock low publisher",
+			zap.Strings("brokers", cfg.KafkaBrokers),
+			zap.String("topic", cfg.StockLowTopic),
+		)
+		stockLowPublisher = eventkafka.NewKafkaStockLowPublisher(logger, cfg.KafkaBrokers, cfg.StockLowTopic)
+		stockEvents = service.NewLowStockMonitor(stockEvents, productRepo, stockLowPublisher)
+	}
+
+	// Предзаказ: товары с backorder_limit > 0 резервируются сверх остатка, резерв попадает в журнал backorders
+	// и в событие inventory.backordered (пустой топик отключает только событие)
+	var backorderEvents service.BackorderPublisher
+	var backorderedPublisher *eventkafka.KafkaBackorderedPublisher
+	if cfg.BackorderedTopic != "" {
+		logger.Info("Initializing Kafka backordered publisher",
+			zap.Strings("brokers", cfg.KafkaBrokers),
+			zap.String("topic", cfg.BackorderedTopic),
+		)
+		backorderedPublisher = eventkafka.NewKafkaBackorderedPublisher(logger, cfg.KafkaBrokers, cfg.BackorderedTopic)
+		backorderEvents = backorderedPublisher
+	}
+	backorders := service.NewBackorders(productRepo, stockRepo, mongorepo.NewBackorderRepository(client, cfg.MongoDBName), backorderEvents)
+
+	// Журнал движений остатка: каждое резервирование, возврат и приёмка пишется в stock_movements
+	// с инициатором из сессии; корректировки и импорт пишут туда же свои движения
+	movementRepo := mongorepo.NewMovementRepository(client, cfg.MongoDBName)
+	actor := func(ctx context.Context) string {
+		userID, _ := interceptor.UserIDFromContext(ctx)
+		return userID
+	}
+	stockJournal := service.NewStockJournal(stockEvents, movementRepo, actor)
+	stockEvents = stockJournal
+
+	// Сроки резервов по категориям товаров: применяются, когда клиент передал ttl_seconds = 0
+	reservationTTLs := service.NewReservationTTLs(productRepo, cfg.ReservationDefaultTTL, cfg.ReservationCategoryTTLs)
 
 	// Создаём service слой
-	inventoryService := service.NewInventoryService(inventoryRepo)
+	inventoryService := service.NewInventoryService(stockRepo, reservationRepo, reservationMetrics, stockEvents, allocator, backorders, reservationTTLs, productRepo)
+	catalogService := service.NewCatalogService(productRepo)
+	warehouseService := service.NewWarehouseService(warehouseRepo)
+	// Аналитика остатков: доступно / зарезервировано / сверх остатка считается агрегацией MongoDB
+	analyticsService := service.NewStockAnalyticsService(mongorepo.NewStockAnalyticsRepository(client, cfg.MongoDBName))
+	// Синхронизация с внешней складской системой: compare-and-set остатка склада, движение пишется с причиной sync
+	stockSync := service.NewStockSyncService(inventoryService, stockRepo, movementRepo, actor)
+
+	// Sweeper возвращает в остаток товар истёкших резервов
+	sweeper := service.NewReservationSweeper(inventoryService, cfg.ReservationSweepInterval, sweepMetrics)
+
+	// Резервы отменённых, истёкших и отклонённых при оплате заказов снимаются по событиям Order
+	var orderCancelledConsumer *eventkafka.OrderCancelledConsumer
+	if cfg.OrderCancelledConsumerEnabled() {
+		logger.Info("Initializing Kafka order cancelled consumer",
+			zap.Strings("brokers", cfg.KafkaBrokers),
+			zap.String("cancelled_topic", cfg.OrderCancelledTopic),
+			zap.String("expired_topic", cfg.OrderExpiredTopic),
+			zap.String("payment_declined_topic", cfg.OrderPaymentDeclinedTopic),
+			zap.String("group_id", cfg.ConsumerGroupID),
+		)
+		orderCancelledConsumer = eventkafka.NewOrderCancelledConsumer(
+			logger,
+			cfg.KafkaBrokers,
+			cfg.ConsumerGroupID,
+			[]string{cfg.OrderCancelledTopic, cfg.OrderExpiredTopic, cfg.OrderPaymentDeclinedTopic},
+			inventoryService,
+			cfg.RetryMaxAttempts,
+			cfg.RetryBackoffBase,
+		)
+	}
 
 	// Подключаемся к IAM Service для проверки сессий
 	logger.Info("Connecting to IAM service", zap.String("addr", cfg.IAMGRPCAddr))
-	iamClient, iamConn, err := iamclient.NewIAMGRPCClient(cfg.IAMGRPCAddr, logger, platformobservability.GRPCUnaryClientInterceptor("inventory"))
+	iamClient, iamConn, err := iamclient.NewIAMGRPCClient(cfg.IAMGRPCAddr, cfg.Discovery, logger, platformobservability.GRPCUnaryClientInterceptor("inventory"))
 	if err != nil {
 		client.Disconnect(ctx)
 		return nil, err
@@ -109,11 +268,15 @@ func Build(cfg config.Config) (*App, error) {
 	// Создаём адаптер для IAM клиента
 	iamClientAdapter := iamclient.NewIAMClientAdapter(iamClient, logger)
 
-	// Создаём auth interceptor
-	authInterceptor := interceptor.NewAuthInterceptor(iamClientAdapter, logger)
+	// Создаём auth interceptor; dev mode и allowlist методов - только явно через конфиг
+	authInterceptor := interceptor.NewAuthInterceptor(iamClientAdapter, logger, interceptor.AuthBypass{
+		Disabled: cfg.AuthDisabled,
+		UserID:   cfg.AuthDevUserID,
+		Methods:  cfg.AuthSkipMethods,
+	})
 
 	// Создаём gRPC handler
-	grpcHandler := grpcapi.NewHandler(inventoryService)
+	grpcHandler := grpcapi.NewHandler(inventoryService, catalogService, warehouseService, stockJournal, analyticsService, stockSync)
 
 	// Слушаем на указанном адресе
 	listener, err := net.Listen("tcp", cfg.GRPCAddr)
@@ -123,12 +286,16 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
-	// gRPC сервер: tracing (extract + span), затем auth
+	// gRPC сервер: tracing (extract + span), затем auth; для потоков (WatchStock) - так же
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			platformobservability.GRPCUnaryServerInterceptor("inventory"),
 			authInterceptor.Unary(),
 		),
+		grpc.ChainStreamInterceptor(
+			platformobservability.GRPCStreamServerInterceptor("inventory"),
+			authInterceptor.Stream(),
+		),
 	)
 
 	// Включаем reflection, если указано в конфиге
@@ -146,25 +313,103 @@ func Build(cfg config.Config) (*App, error) {
 
 	logger.Info("Inventory gRPC server configured", zap.String("addr", cfg.GRPCAddr))
 
+	// Админский HTTP API для ops-инструментов: те же сессии IAM, что и в gRPC (заголовок x-session-id)
+	var adminServer *http.Server
+	if cfg.AdminHTTPAddr != "" {
+		readiness := func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			return client.Ping(ctx, nil) == nil
+		}
+		// Корректировки остатка: черновик → одобрение пользователем из INVENTORY_ADJUSTMENT_APPROVERS → журнал движений
+		adjustmentService := service.NewAdjustmentService(inventoryService,
+			mongorepo.NewAdjustmentRepository(client, cfg.MongoDBName), movementRepo)
+		if len(cfg.AdjustmentApprovers) == 0 {
+			logger.Warn("INVENTORY_ADJUSTMENT_APPROVERS is empty: stock adjustments cannot be approved")
+		}
+		adminHandler := httpapi.NewHandler(inventoryService, adjustmentService, analyticsService, logger)
+		// Observability: span на каждый запрос админского API, как и для gRPC
+		adminRouter := platformobservability.HTTPMiddleware("inventory", logger)(
+			httpapi.NewRouter(adminHandler, authInterceptor.AdminHTTP, cfg.AdjustmentApprovers, readiness))
+		adminServer = &http.Server{
+			Addr:         cfg.AdminHTTPAddr,
+			Handler:      adminRouter,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		logger.Info("Inventory admin HTTP API configured", zap.String("addr", cfg.AdminHTTPAddr))
+	}
+
+	// Readiness переоценивается по доступности MongoDB, а не только при старте
+	var monitor *mongoMonitor
+	if cfg.MongoCheckInterval > 0 {
+		monitor = newMongoMonitor(client, health, logger, cfg.MongoCheckInterval, cfg.MongoFailureThreshold)
+		logger.Info("MongoDB readiness check enabled",
+			zap.Duration("interval", cfg.MongoCheckInterval),
+			zap.Int("failure_threshold", cfg.MongoFailureThreshold),
+		)
+	}
+
 	// Создаём shutdown manager
 	shutdownMgr := platformshutdown.New(cfg.ShutdownTimeout, logger)
 
 	// Регистрируем shutdown функции в обратном порядке выполнения
 	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
 	shutdownMgr.Add("mongodb", platformshutdown.DisconnectMongo(client))
+	if redisClient != nil {
+		shutdownMgr.Add("redis", func(ctx context.Context) error {
+			return redisClient.Close()
+		})
+	}
+	if stockPublisher != nil {
+		shutdownMgr.Add("stock_publisher", func(ctx context.Context) error {
+			return stockPublisher.Close()
+		})
+	}
+	if stockLowPublisher != nil {
+		shutdownMgr.Add("stock_low_publisher", func(ctx context.Context) error {
+			return stockLowPublisher.Close()
+		})
+	}
+	if backorderedPublisher != nil {
+		shutdownMgr.Add("backordered_publisher", func(ctx context.Context) error {
+			return backorderedPublisher.Close()
+		})
+	}
+	if orderCancelledConsumer != nil {
+		shutdownMgr.Add("order_cancelled_consumer", func(ctx context.Context) error {
+			return orderCancelledConsumer.Close()
+		})
+	}
 	shutdownMgr.Add("iam_conn", func(ctx context.Context) error {
 		iamConn.Close()
 		return nil
 	})
 	shutdownMgr.Add("grpc_server", platformshutdown.ShutdownGRPCServer(grpcServer))
+	if adminServer != nil {
+		shutdownMgr.Add("admin_http_server", platformshutdown.ShutdownHTTPServer(adminServer))
+	}
 	shutdownMgr.Add("health_readiness", platformshutdown.SetHealthNotServing(health))
+	// Монитор останавливается первым: после этого readiness меняет только health_readiness
+	if monitor != nil {
+		shutdownMgr.Add("mongo_monitor", monitor.Stop)
+	}
 
 	return &App{
-		logger:      logger,
-		grpcServer:  grpcServer,
-		listener:    listener,
-		health:      health,
-		shutdownMgr: shutdownMgr,
+		logger:       logger,
+		grpcServer:   grpcServer,
+		listener:     listener,
+		adminServer:  adminServer,
+		health:       health,
+		mongoMonitor: monitor,
+		shutdownMgr:  shutdownMgr,
+		sweeper:      sweeper,
+
+		orderCancelledConsumer: orderCancelledConsumer,
 	}, nil
 }
 
@@ -174,6 +419,9 @@ func (a *App) Run() error {
 
 	a.logger.Info("Starting Inventory service", zap.String("addr", a.listener.Addr().String()))
 
+	sweeperCtx, sweeperCancel := context.WithCancel(context.Background())
+	defer sweeperCancel()
+
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
@@ -182,10 +430,103 @@ func (a *App) Run() error {
 		}
 	}()
 
+	if a.adminServer != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				a.logger.Error("admin HTTP server error", zap.Error(err))
+			}
+		}()
+	}
+
+	if a.mongoMonitor != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.mongoMonitor.Run()
+		}()
+	}
+
+	if a.orderCancelledConsumer != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.orderCancelledConsumer.Start(sweeperCtx); err != nil {
+				a.logger.Error("order cancelled consumer error", zap.Error(err))
+			}
+		}()
+	}
+
+	// Запускаем sweeper истёкших резервов в отдельной горутине
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.sweeper.Start(sweeperCtx); err != nil {
+			a.logger.Error("reservation sweeper error", zap.Error(err))
+		}
+	}()
+
 	// Ожидаем сигнал и выполняем shutdown
 	a.shutdownMgr.Wait()
 
+	// Останавливаем sweeper и consumer
+	sweeperCancel()
+
 	a.wg.Wait()
 	a.logger.Info("Inventory service stopped")
 	return nil
 }
+
+// reservationMetricsRecorder реализует service.ReservationMetricsRecorder и service.ReservationSweepMetricsRecorder через OpenTelemetry Meter.
+type reservationMetricsRecorder struct {
+	reservations   metric.Int64Counter
+	duration       metric.Float64Histogram
+	writeConflicts metric.Int64Counter
+	sweeps         metric.Int64Counter
+	sweepDuration  metric.Float64Histogram
+	expired        metric.Int64Counter
+	expiredUnits   metric.Int64Counter
+}
+
+func newReservationMetricsRecorder() *reservationMetricsRecorder {
+	meter := otel.Meter("inventory")
+	reservations, _ := meter.Int64Counter("inventory_reservations_total", metric.WithDescription("Total stock reservations by result"))
+	duration, _ := meter.Float64Histogram("inventory_reservation_duration_ms", metric.WithDescription("Stock reservation duration in milliseconds, including conflict retries"))
+	writeConflicts, _ := meter.Int64Counter("inventory_reservation_write_conflicts_total", metric.WithDescription("Write conflicts on concurrent reservations of the same product"))
+	sweeps, _ := meter.Int64Counter("inventory_reservation_sweeps_total", metric.WithDescription("Expired reservation sweeps by result"))
+	sweepDuration, _ := meter.Float64Histogram("inventory_reservation_sweep_duration_ms", metric.WithDescription("Expired reservation sweep duration in milliseconds"))
+	expired, _ := meter.Int64Counter("inventory_reservations_expired_total", metric.WithDescription("Expired reservations returned to stock by the sweeper"))
+	expiredUnits, _ := meter.Int64Counter("inventory_reservation_expired_units_total", metric.WithDescription("Units returned to stock from expired reservations"))
+	return &reservationMetricsRecorder{
+		reservations:   reservations,
+		duration:       duration,
+		writeConflicts: writeConflicts,
+		sweeps:         sweeps,
+		sweepDuration:  sweepDuration,
+		expired:        expired,
+		expiredUnits:   expiredUnits,
+	}
+}
+
+func (r *reservationMetricsRecorder) RecordReservation(d time.Duration, result string) {
+	attrs := metric.WithAttributes(attribute.String("result", result))
+	r.reservations.Add(context.Background(), 1, attrs)
+	r.duration.Record(context.Background(), float64(d.Microseconds())/1000, attrs)
+}
+
+func (r *reservationMetricsRecorder) RecordWriteConflict(retried bool) {
+	r.writeConflicts.Add(context.Background(), 1, metric.WithAttributes(attribute.Bool("retried", retried)))
+}
+
+func (r *reservationMetricsRecorder) RecordReservationSweep(result service.ReservationSweepResult, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	attrs := metric.WithAttributes(attribute.String("result", outcome))
+	r.sweeps.Add(context.Background(), 1, attrs)
+	r.sweepDuration.Record(context.Background(), float64(result.Duration.Microseconds())/1000, attrs)
+	r.expired.Add(context.Background(), int64(result.Expired))
+	r.expiredUnits.Add(context.Background(), result.Quantity)
+}
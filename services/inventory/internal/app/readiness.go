@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
+)
+
+// mongoMonitor переводит readiness Inventory в NOT_SERVING, когда MongoDB недоступна, и обратно, когда она вернулась
+// Без него статус SERVING ставится один раз при старте: инстанс без Mongo остаётся в балансировке
+// и отвечает ошибками на каждый запрос
+// Раз в interval выполняется ping: NOT_SERVING ставится после failureThreshold неудачных ping подряд
+// (одиночный таймаут не выводит инстанс из балансировки), SERVING возвращается после первого удачного
+type mongoMonitor struct {
+	client           *mongo.Client
+	health           *platformhealth.Health
+	logger           *zap.Logger
+	interval         time.Duration
+	failureThreshold int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newMongoMonitor(client *mongo.Client, health *platformhealth.Health, logger *zap.Logger, interval time.Duration, failureThreshold int) *mongoMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &mongoMonitor{
+		client:           client,
+		health:           health,
+		logger:           logger,
+		interval:         interval,
+		failureThreshold: failureThreshold,
+		ctx:              ctx,
+		cancel:           cancel,
+		done:             make(chan struct{}),
+	}
+}
+
+// Run выполняет проверки до вызова Stop
+func (m *mongoMonitor) Run() {
+	defer close(m.done)
+	ctx := m.ctx
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	serving := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if !serving {
+				m.health.SetServing("")
+				m.logger.Info("MongoDB recovered, readiness set to SERVING", zap.Int("failed_checks", failures))
+				serving = true
+			}
+			failures = 0
+			continue
+		}
+
+		failures++
+		m.logger.Warn("MongoDB readiness check failed", zap.Int("consecutive_failures", failures), zap.Error(err))
+		if serving && failures >= m.failureThreshold {
+			serving = false
+			m.health.SetNotServing("")
+			m.logger.Error("MongoDB unreachable, readiness set to NOT_SERVING", zap.Int("consecutive_failures", failures))
+		}
+	}
+}
+
+// ping проверяет primary с таймаутом, равным interval: следующая проверка не начнётся, пока не закончилась эта
+func (m *mongoMonitor) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+	return m.client.Ping(ctx, nil)
+}
+
+// Stop останавливает проверки и ждёт выхода из Run: после него readiness меняет только shutdown
+func (m *mongoMonitor) Stop(ctx context.Context) error {
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
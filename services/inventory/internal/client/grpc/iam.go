@@ -7,13 +7,23 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	platformdiscovery "github.com/shestoi/GoBigTech/platform/discovery"
 	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
 )
 
+// RoleAdmin - роль администратора IAM (ValidateSessionResponse.role)
+const RoleAdmin = "admin"
+
+// Session - пользователь сессии и его роль по данным IAM
+type Session struct {
+	UserID string
+	Role   string
+}
+
 // IAMClient определяет интерфейс для работы с IAM Service
 type IAMClient interface {
-	// ValidateSession проверяет валидность сессии и возвращает user_id
-	ValidateSession(ctx context.Context, sessionID string) (userID string, err error)
+	// ValidateSession проверяет валидность сессии и возвращает пользователя сессии и его роль
+	ValidateSession(ctx context.Context, sessionID string) (Session, error)
 }
 
 // IAMClientAdapter адаптирует gRPC клиент к интерфейсу IAMClient
@@ -31,27 +41,28 @@ func NewIAMClientAdapter(client iampb.IAMServiceClient, logger *zap.Logger) IAMC
 }
 
 // ValidateSession реализует IAMClient интерфейс
-func (a *IAMClientAdapter) ValidateSession(ctx context.Context, sessionID string) (string, error) {
+func (a *IAMClientAdapter) ValidateSession(ctx context.Context, sessionID string) (Session, error) {
 	req := &iampb.ValidateSessionRequest{
 		SessionId: sessionID,
 	}
 
 	resp, err := a.client.ValidateSession(ctx, req)
 	if err != nil {
-		return "", err
+		return Session{}, err
 	}
 
-	return resp.GetUserId(), nil
+	return Session{UserID: resp.GetUserId(), Role: resp.GetRole()}, nil
 }
 
 // NewIAMGRPCClient создаёт новый gRPC клиент для IAM Service.
+// addr - host:port, список через запятую или srv:///имя (см. platform/discovery).
 // clientInterceptor опционально — для tracing (observability.GRPCUnaryClientInterceptor).
-func NewIAMGRPCClient(addr string, logger *zap.Logger, clientInterceptor grpc.UnaryClientInterceptor) (iampb.IAMServiceClient, *grpc.ClientConn, error) {
+func NewIAMGRPCClient(addr string, discovery platformdiscovery.Config, logger *zap.Logger, clientInterceptor grpc.UnaryClientInterceptor) (iampb.IAMServiceClient, *grpc.ClientConn, error) {
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	if clientInterceptor != nil {
 		opts = append(opts, grpc.WithChainUnaryInterceptor(clientInterceptor))
 	}
-	conn, err := grpc.NewClient(addr, opts...)
+	conn, err := platformdiscovery.NewClient(addr, discovery, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -5,7 +5,10 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	platformdiscovery "github.com/shestoi/GoBigTech/platform/discovery"
 )
 
 // Env представляет окружение приложения
@@ -24,14 +27,62 @@ type Config struct {
 	GRPCAddr             string
 	MongoURI             string
 	MongoDBName          string
+	StockReadConsistency string // strong | eventual: чтение остатка, если клиент не указал consistency
 	IAMGRPCAddr          string // адрес IAM Service для проверки сессий
+
+	// Исключения из проверки сессии: dev mode (все методы) и allowlist gRPC методов
+	AuthDisabled    bool     // INVENTORY_AUTH_DISABLED: сессия не проверяется, user_id запросов - AuthDevUserID
+	AuthDevUserID   string   // INVENTORY_AUTH_DEV_USER_ID
+	AuthSkipMethods []string // INVENTORY_AUTH_SKIP_METHODS: полные имена gRPC методов, например /inventory.v1.InventoryService/GetStock
+
 	EnableGRPCReflection bool
 	ShutdownTimeout      time.Duration
+	DebugAddr            string   // DEBUG_ADDR: отладочный сервер (pprof, expvar), только loopback; пусто - выключен
+	AdminHTTPAddr        string   // INVENTORY_ADMIN_HTTP_ADDR: админский HTTP API (остатки, резервы, приёмка); пусто - выключен
+	AdjustmentApprovers  []string // INVENTORY_ADJUSTMENT_APPROVERS: user_id, которые одобряют корректировки остатка
+
+	// Readiness по MongoDB: ping раз в MongoCheckInterval (0 - проверка выключена);
+	// MongoFailureThreshold неудачных ping подряд - NOT_SERVING, первый удачный - снова SERVING
+	MongoCheckInterval    time.Duration
+	MongoFailureThreshold int
+
+	// Резервы
+	ReservationSweepInterval time.Duration // как часто истёкшие резервы возвращаются в остаток
+	ReservationRetention     time.Duration // сколько хранить снятые и истёкшие резервы (TTL индекс MongoDB); 0 - всегда
+	AllocationStrategy       string        // priority | most_stock | single_warehouse: распределение по складам, если клиент не указал стратегию
+
+	// Сроки резервов, если клиент передал ttl_seconds = 0: по категории товара, иначе ReservationDefaultTTL (0 - без срока)
+	ReservationDefaultTTL   time.Duration
+	ReservationCategoryTTLs map[string]time.Duration // INVENTORY_RESERVATION_CATEGORY_TTLS: electronics=30m,groceries=10m
+
+	// Кеш остатка в Redis (read-through для GetStock/GetStockBatch)
+	StockCacheEnabled bool
+	StockCacheTTL     time.Duration
+	RedisAddr         string
+	RedisPassword     string
+
+	// Kafka: события изменения остатка (best-effort)
+	KafkaBrokers      []string
+	StockChangedTopic string // inventory.stock.changed; пусто - события не публикуются
+	StockLowTopic     string // inventory.stock.low (остаток ниже порога товара); пусто - алерты не публикуются
+	BackorderedTopic  string // inventory.backordered (резерв сверх остатка); пусто - события не публикуются
+
+	// Kafka: отмена заказов - резервы отменённых, истёкших и отклонённых при оплате заказов снимаются;
+	// все топики пустые - consumer выключен
+	OrderCancelledTopic       string // order.cancelled
+	OrderExpiredTopic         string // order.expired
+	OrderPaymentDeclinedTopic string // order.payment.declined
+	ConsumerGroupID           string
+	RetryMaxAttempts          int           // попыток снять резервы заказа, прежде чем оставить событие без commit
+	RetryBackoffBase          time.Duration // базовый интервал экспоненциального backoff между попытками
 
 	// OpenTelemetry
 	OTelEnabled       bool
 	OTelEndpoint      string
 	OTelSamplingRatio float64
+
+	// Обнаружение инстансов IAM: статический список или SRV, round_robin между инстансами
+	Discovery platformdiscovery.Config
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -64,6 +115,147 @@ func Load() (Config, error) {
 	// INVENTORY_MONGO_DB
 	cfg.MongoDBName = getString("INVENTORY_MONGO_DB", "inventory")
 
+	// INVENTORY_STOCK_READ_CONSISTENCY
+	cfg.StockReadConsistency = getString("INVENTORY_STOCK_READ_CONSISTENCY", "strong")
+
+	// INVENTORY_ALLOCATION_STRATEGY
+	cfg.AllocationStrategy = getString("INVENTORY_ALLOCATION_STRATEGY", "priority")
+
+	// INVENTORY_MONGO_READINESS_CHECK_INTERVAL, INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD
+	mongoCheckInterval, err := time.ParseDuration(getString("INVENTORY_MONGO_READINESS_CHECK_INTERVAL", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_MONGO_READINESS_CHECK_INTERVAL: %w", err)
+	}
+	cfg.MongoCheckInterval = mongoCheckInterval
+	mongoFailureThreshold, err := strconv.Atoi(getString("INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD", "3"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD: %w", err)
+	}
+	cfg.MongoFailureThreshold = mongoFailureThreshold
+
+	// INVENTORY_RESERVATION_SWEEP_INTERVAL
+	sweepIntervalStr := getString("INVENTORY_RESERVATION_SWEEP_INTERVAL", "30s")
+	sweepInterval, err := time.ParseDuration(sweepIntervalStr)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_RESERVATION_SWEEP_INTERVAL: %w", err)
+	}
+	cfg.ReservationSweepInterval = sweepInterval
+
+	// INVENTORY_RESERVATION_RETENTION: завершённые резервы удаляет TTL индекс MongoDB
+	reservationRetention, err := time.ParseDuration(getString("INVENTORY_RESERVATION_RETENTION", "720h"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_RESERVATION_RETENTION: %w", err)
+	}
+	cfg.ReservationRetention = reservationRetention
+
+	// INVENTORY_RESERVATION_DEFAULT_TTL, INVENTORY_RESERVATION_CATEGORY_TTLS
+	reservationDefaultTTL, err := time.ParseDuration(getString("INVENTORY_RESERVATION_DEFAULT_TTL", "0s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_RESERVATION_DEFAULT_TTL: %w", err)
+	}
+	cfg.ReservationDefaultTTL = reservationDefaultTTL
+	categoryTTLs, err := parseCategoryTTLs(getString("INVENTORY_RESERVATION_CATEGORY_TTLS", ""))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_RESERVATION_CATEGORY_TTLS: %w", err)
+	}
+	cfg.ReservationCategoryTTLs = categoryTTLs
+
+	// INVENTORY_STOCK_CACHE_ENABLED, INVENTORY_STOCK_CACHE_TTL, REDIS_ADDR, REDIS_PASSWORD
+	cfg.StockCacheEnabled = getBool("INVENTORY_STOCK_CACHE_ENABLED", false)
+	stockCacheTTL, err := time.ParseDuration(getString("INVENTORY_STOCK_CACHE_TTL", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_STOCK_CACHE_TTL: %w", err)
+	}
+	cfg.StockCacheTTL = stockCacheTTL
+	if cfg.AppEnv == EnvLocal {
+		cfg.RedisAddr = getString("REDIS_ADDR", "127.0.0.1:16379")
+	} else {
+		cfg.RedisAddr = getString("REDIS_ADDR", "redis:6379")
+	}
+	cfg.RedisPassword = getString("REDIS_PASSWORD", "")
+
+	// Kafka Brokers
+	brokersStr := getString("KAFKA_BROKERS", "")
+	if brokersStr != "" {
+		// Парсим список брокеров через запятую
+		brokers := []string{}
+		for _, broker := range strings.Split(brokersStr, ",") {
+			broker = strings.TrimSpace(broker)
+			if broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+		if len(brokers) > 0 {
+			cfg.KafkaBrokers = brokers
+		}
+	}
+	// Если не задано, используем дефолт в зависимости от окружения
+	if len(cfg.KafkaBrokers) == 0 {
+		if cfg.AppEnv == EnvLocal {
+			cfg.KafkaBrokers = []string{"localhost:19092"}
+		} else {
+			cfg.KafkaBrokers = []string{"kafka:9092"}
+		}
+	}
+
+	// KAFKA_INVENTORY_STOCK_CHANGED_TOPIC: явно пустое значение отключает публикацию
+	cfg.StockChangedTopic = "inventory.stock.changed"
+	if topic, ok := os.LookupEnv("KAFKA_INVENTORY_STOCK_CHANGED_TOPIC"); ok {
+		cfg.StockChangedTopic = strings.TrimSpace(topic)
+	}
+	cfg.StockLowTopic = "inventory.stock.low"
+	if topic, ok := os.LookupEnv("KAFKA_INVENTORY_STOCK_LOW_TOPIC"); ok {
+		cfg.StockLowTopic = strings.TrimSpace(topic)
+	}
+	cfg.BackorderedTopic = "inventory.backordered"
+	if topic, ok := os.LookupEnv("KAFKA_INVENTORY_BACKORDERED_TOPIC"); ok {
+		cfg.BackorderedTopic = strings.TrimSpace(topic)
+	}
+
+	// KAFKA_ORDER_CANCELLED_TOPIC, KAFKA_ORDER_EXPIRED_TOPIC, KAFKA_ORDER_PAYMENT_DECLINED_TOPIC: явно пустое значение отключает топик
+	cfg.OrderCancelledTopic = "order.cancelled"
+	if topic, ok := os.LookupEnv("KAFKA_ORDER_CANCELLED_TOPIC"); ok {
+		cfg.OrderCancelledTopic = strings.TrimSpace(topic)
+	}
+	cfg.OrderExpiredTopic = "order.expired"
+	if topic, ok := os.LookupEnv("KAFKA_ORDER_EXPIRED_TOPIC"); ok {
+		cfg.OrderExpiredTopic = strings.TrimSpace(topic)
+	}
+	cfg.OrderPaymentDeclinedTopic = "order.payment.declined"
+	if topic, ok := os.LookupEnv("KAFKA_ORDER_PAYMENT_DECLINED_TOPIC"); ok {
+		cfg.OrderPaymentDeclinedTopic = strings.TrimSpace(topic)
+	}
+	cfg.ConsumerGroupID = getString("KAFKA_INVENTORY_CONSUMER_GROUP_ID", "inventory-service")
+
+	// KAFKA_RETRY_MAX_ATTEMPTS, KAFKA_RETRY_BACKOFF_BASE
+	retryMaxAttempts, err := strconv.Atoi(getString("KAFKA_RETRY_MAX_ATTEMPTS", "3"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid KAFKA_RETRY_MAX_ATTEMPTS: %w", err)
+	}
+	cfg.RetryMaxAttempts = retryMaxAttempts
+	retryBackoffBase, err := time.ParseDuration(getString("KAFKA_RETRY_BACKOFF_BASE", "1s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid KAFKA_RETRY_BACKOFF_BASE: %w", err)
+	}
+	cfg.RetryBackoffBase = retryBackoffBase
+
+	// INVENTORY_ADMIN_HTTP_ADDR: явно пустое значение отключает админский HTTP API
+	if cfg.AppEnv == EnvLocal {
+		cfg.AdminHTTPAddr = "127.0.0.1:8083"
+	} else {
+		cfg.AdminHTTPAddr = "0.0.0.0:8083"
+	}
+	if addr, ok := os.LookupEnv("INVENTORY_ADMIN_HTTP_ADDR"); ok {
+		cfg.AdminHTTPAddr = strings.TrimSpace(addr)
+	}
+
+	// INVENTORY_ADJUSTMENT_APPROVERS: user_id через запятую; пусто - одобрять корректировки некому
+	for _, id := range strings.Split(getString("INVENTORY_ADJUSTMENT_APPROVERS", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			cfg.AdjustmentApprovers = append(cfg.AdjustmentApprovers, id)
+		}
+	}
+
 	// IAM_GRPC_ADDR
 	if cfg.AppEnv == EnvLocal {
 		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "127.0.0.1:50053")
@@ -71,6 +263,21 @@ func Load() (Config, error) {
 		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "iam:50053")
 	}
 
+	// INVENTORY_AUTH_DISABLED, INVENTORY_AUTH_DEV_USER_ID, INVENTORY_AUTH_SKIP_METHODS
+	cfg.AuthDisabled = getBool("INVENTORY_AUTH_DISABLED", false)
+	cfg.AuthDevUserID = strings.TrimSpace(getString("INVENTORY_AUTH_DEV_USER_ID", "dev"))
+	for _, method := range strings.Split(getString("INVENTORY_AUTH_SKIP_METHODS", ""), ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			cfg.AuthSkipMethods = append(cfg.AuthSkipMethods, method)
+		}
+	}
+
+	// GRPC_DISCOVERY_*, GRPC_CLIENT_*: обнаружение и балансировка инстансов зависимостей
+	cfg.Discovery = platformdiscovery.DefaultConfig()
+	if err := platformdiscovery.LoadEnv(&cfg.Discovery); err != nil {
+		return Config{}, fmt.Errorf("invalid gRPC discovery config: %w", err)
+	}
+
 	// ENABLE_GRPC_REFLECTION
 	cfg.EnableGRPCReflection = getBool("ENABLE_GRPC_REFLECTION", false)
 
@@ -82,6 +289,9 @@ func Load() (Config, error) {
 	}
 	cfg.ShutdownTimeout = shutdownTimeout
 
+	// DEBUG_ADDR (например 127.0.0.1:6060); по умолчанию отладочный сервер выключен
+	cfg.DebugAddr = getString("DEBUG_ADDR", "")
+
 	// OpenTelemetry
 	cfg.OTelEnabled = getBool("OTEL_ENABLED", false)
 	if cfg.AppEnv == EnvLocal {
@@ -99,6 +309,11 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
+// OrderCancelledConsumerEnabled сообщает, задан ли хотя бы один топик, после событий которого снимаются резервы заказа
+func (c Config) OrderCancelledConsumerEnabled() bool {
+	return c.OrderCancelledTopic != "" || c.OrderExpiredTopic != "" || c.OrderPaymentDeclinedTopic != ""
+}
+
 // Validate проверяет корректность конфигурации
 func (c Config) Validate() error {
 	if c.GRPCAddr == "" {
@@ -110,9 +325,66 @@ func (c Config) Validate() error {
 	if c.MongoDBName == "" {
 		return fmt.Errorf("INVENTORY_MONGO_DB is required")
 	}
+	if c.StockReadConsistency != "strong" && c.StockReadConsistency != "eventual" {
+		return fmt.Errorf("INVENTORY_STOCK_READ_CONSISTENCY must be 'strong' or 'eventual'")
+	}
+	switch c.AllocationStrategy {
+	case "priority", "most_stock", "single_warehouse":
+	default:
+		return fmt.Errorf("INVENTORY_ALLOCATION_STRATEGY must be 'priority', 'most_stock' or 'single_warehouse'")
+	}
+	if c.MongoCheckInterval < 0 {
+		return fmt.Errorf("INVENTORY_MONGO_READINESS_CHECK_INTERVAL must not be negative")
+	}
+	if c.MongoCheckInterval > 0 && c.MongoFailureThreshold <= 0 {
+		return fmt.Errorf("INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD must be positive")
+	}
+	if c.ReservationSweepInterval <= 0 {
+		return fmt.Errorf("INVENTORY_RESERVATION_SWEEP_INTERVAL must be positive")
+	}
+	if c.ReservationRetention < 0 || (c.ReservationRetention > 0 && c.ReservationRetention < time.Second) {
+		return fmt.Errorf("INVENTORY_RESERVATION_RETENTION must be 0 or at least 1s")
+	}
+	if c.ReservationDefaultTTL < 0 {
+		return fmt.Errorf("INVENTORY_RESERVATION_DEFAULT_TTL must not be negative")
+	}
+	for category, ttl := range c.ReservationCategoryTTLs {
+		if ttl <= 0 {
+			return fmt.Errorf("INVENTORY_RESERVATION_CATEGORY_TTLS: ttl of category %q must be positive", category)
+		}
+	}
+	if c.StockCacheEnabled && c.StockCacheTTL <= 0 {
+		return fmt.Errorf("INVENTORY_STOCK_CACHE_TTL must be positive")
+	}
+	if c.StockCacheEnabled && c.RedisAddr == "" {
+		return fmt.Errorf("REDIS_ADDR is required when INVENTORY_STOCK_CACHE_ENABLED is set")
+	}
+	if c.OrderCancelledConsumerEnabled() {
+		if c.ConsumerGroupID == "" {
+			return fmt.Errorf("KAFKA_INVENTORY_CONSUMER_GROUP_ID is required")
+		}
+		if c.RetryMaxAttempts <= 0 {
+			return fmt.Errorf("KAFKA_RETRY_MAX_ATTEMPTS must be positive")
+		}
+		if c.RetryBackoffBase <= 0 {
+			return fmt.Errorf("KAFKA_RETRY_BACKOFF_BASE must be positive")
+		}
+	}
 	if c.IAMGRPCAddr == "" {
 		return fmt.Errorf("IAM_GRPC_ADDR is required")
 	}
+	if c.AuthDisabled && c.AuthDevUserID == "" {
+		return fmt.Errorf("INVENTORY_AUTH_DEV_USER_ID is required when INVENTORY_AUTH_DISABLED is set")
+	}
+	for _, method := range c.AuthSkipMethods {
+		// Формат grpc.UnaryServerInfo.FullMethod: /package.Service/Method
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return fmt.Errorf("INVENTORY_AUTH_SKIP_METHODS: invalid method %q, expected /package.Service/Method", method)
+		}
+	}
+	if err := c.Discovery.Validate(); err != nil {
+		return err
+	}
 	if c.ShutdownTimeout <= 0 {
 		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive")
 	}
@@ -129,9 +401,40 @@ func (c Config) Log() {
 	log.Printf("  GRPC_ADDR: %s", c.GRPCAddr)
 	log.Printf("  INVENTORY_MONGO_URI: %s", maskMongoURI(c.MongoURI))
 	log.Printf("  INVENTORY_MONGO_DB: %s", c.MongoDBName)
+	log.Printf("  INVENTORY_STOCK_READ_CONSISTENCY: %s", c.StockReadConsistency)
+	log.Printf("  INVENTORY_ALLOCATION_STRATEGY: %s", c.AllocationStrategy)
+	log.Printf("  INVENTORY_MONGO_READINESS_CHECK_INTERVAL: %s", c.MongoCheckInterval)
+	log.Printf("  INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD: %d", c.MongoFailureThreshold)
+	log.Printf("  INVENTORY_RESERVATION_SWEEP_INTERVAL: %s", c.ReservationSweepInterval)
+	log.Printf("  INVENTORY_RESERVATION_RETENTION: %s", c.ReservationRetention)
+	log.Printf("  INVENTORY_RESERVATION_DEFAULT_TTL: %s", c.ReservationDefaultTTL)
+	log.Printf("  INVENTORY_RESERVATION_CATEGORY_TTLS: %v", c.ReservationCategoryTTLs)
+	log.Printf("  INVENTORY_STOCK_CACHE_ENABLED: %v", c.StockCacheEnabled)
+	log.Printf("  INVENTORY_STOCK_CACHE_TTL: %s", c.StockCacheTTL)
+	log.Printf("  REDIS_ADDR: %s", c.RedisAddr)
+	log.Printf("  KAFKA_BROKERS: %v", c.KafkaBrokers)
+	log.Printf("  KAFKA_INVENTORY_STOCK_CHANGED_TOPIC: %q", c.StockChangedTopic)
+	log.Printf("  KAFKA_INVENTORY_STOCK_LOW_TOPIC: %q", c.StockLowTopic)
+	log.Printf("  KAFKA_INVENTORY_BACKORDERED_TOPIC: %q", c.BackorderedTopic)
+	log.Printf("  KAFKA_ORDER_CANCELLED_TOPIC: %q", c.OrderCancelledTopic)
+	log.Printf("  KAFKA_ORDER_EXPIRED_TOPIC: %q", c.OrderExpiredTopic)
+	log.Printf("  KAFKA_ORDER_PAYMENT_DECLINED_TOPIC: %q", c.OrderPaymentDeclinedTopic)
+	log.Printf("  KAFKA_INVENTORY_CONSUMER_GROUP_ID: %s", c.ConsumerGroupID)
+	log.Printf("  KAFKA_RETRY_MAX_ATTEMPTS: %d (backoff base %s)", c.RetryMaxAttempts, c.RetryBackoffBase)
 	log.Printf("  IAM_GRPC_ADDR: %s", c.IAMGRPCAddr)
+	log.Printf("  INVENTORY_AUTH_DISABLED: %v", c.AuthDisabled)
+	if c.AuthDisabled {
+		log.Printf("  INVENTORY_AUTH_DEV_USER_ID: %s", c.AuthDevUserID)
+	}
+	log.Printf("  INVENTORY_AUTH_SKIP_METHODS: %v", c.AuthSkipMethods)
+	log.Printf("  GRPC_DISCOVERY_REFRESH_INTERVAL: %s", c.Discovery.SRVRefreshInterval)
+	log.Printf("  GRPC_CLIENT_HEALTH_CHECK: %v", c.Discovery.HealthCheck)
+	log.Printf("  GRPC_CLIENT_SUBSET_SIZE: %d", c.Discovery.SubsetSize)
 	log.Printf("  ENABLE_GRPC_REFLECTION: %v", c.EnableGRPCReflection)
 	log.Printf("  SHUTDOWN_TIMEOUT: %s", c.ShutdownTimeout)
+	log.Printf("  DEBUG_ADDR: %q", c.DebugAddr)
+	log.Printf("  INVENTORY_ADMIN_HTTP_ADDR: %q", c.AdminHTTPAddr)
+	log.Printf("  INVENTORY_ADJUSTMENT_APPROVERS: %v", c.AdjustmentApprovers)
 	log.Printf("  OTEL_ENABLED: %v", c.OTelEnabled)
 	log.Printf("  OTEL_EXPORTER_OTLP_ENDPOINT: %s", c.OTelEndpoint)
 	log.Printf("  OTEL_SAMPLING_RATIO: %f", c.OTelSamplingRatio)
@@ -151,6 +454,31 @@ func getFloat64(key string, defaultValue float64) float64 {
 }
 
 // getString читает переменную окружения или возвращает дефолт
+// parseCategoryTTLs разбирает список category=duration через запятую; категории приводятся к нижнему регистру
+func parseCategoryTTLs(value string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		category, durationStr, ok := strings.Cut(part, "=")
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !ok || category == "" {
+			return nil, fmt.Errorf("expected category=duration, got %q", part)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(durationStr))
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %w", category, err)
+		}
+		if _, exists := ttls[category]; exists {
+			return nil, fmt.Errorf("duplicate category %q", category)
+		}
+		ttls[category] = ttl
+	}
+	return ttls, nil
+}
+
 func getString(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
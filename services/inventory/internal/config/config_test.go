@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad_LocalDefaults(t *testing.T) {
@@ -57,4 +58,308 @@ func TestLoad_EnableReflection(t *testing.T) {
 	}
 }
 
+func TestLoad_StockReadConsistency(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.StockReadConsistency != "strong" {
+		t.Errorf("Expected StockReadConsistency=strong, got %s", cfg.StockReadConsistency)
+	}
+
+	os.Setenv("INVENTORY_STOCK_READ_CONSISTENCY", "eventual")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.StockReadConsistency != "eventual" {
+		t.Errorf("Expected StockReadConsistency=eventual, got %s", cfg.StockReadConsistency)
+	}
+
+	os.Setenv("INVENTORY_STOCK_READ_CONSISTENCY", "nearest")
+	if _, err := Load(); err == nil {
+		t.Errorf("Expected error for INVENTORY_STOCK_READ_CONSISTENCY=nearest")
+	}
+}
+
+func TestLoad_AllocationStrategy(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AllocationStrategy != "priority" {
+		t.Errorf("Expected AllocationStrategy=priority, got %s", cfg.AllocationStrategy)
+	}
+
+	os.Setenv("INVENTORY_ALLOCATION_STRATEGY", "most_stock")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AllocationStrategy != "most_stock" {
+		t.Errorf("Expected AllocationStrategy=most_stock, got %s", cfg.AllocationStrategy)
+	}
+
+	os.Setenv("INVENTORY_ALLOCATION_STRATEGY", "nearest")
+	if _, err := Load(); err == nil {
+		t.Errorf("Expected error for INVENTORY_ALLOCATION_STRATEGY=nearest")
+	}
+}
+
+func TestLoad_StockChangedTopic(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.StockChangedTopic != "inventory.stock.changed" {
+		t.Errorf("Expected StockChangedTopic=inventory.stock.changed, got %q", cfg.StockChangedTopic)
+	}
+	if len(cfg.KafkaBrokers) != 1 || cfg.KafkaBrokers[0] != "localhost:19092" {
+		t.Errorf("Expected KafkaBrokers=[localhost:19092], got %v", cfg.KafkaBrokers)
+	}
+
+	// Явно пустой топик отключает публикацию
+	os.Setenv("KAFKA_INVENTORY_STOCK_CHANGED_TOPIC", "")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.StockChangedTopic != "" {
+		t.Errorf("Expected empty StockChangedTopic, got %q", cfg.StockChangedTopic)
+	}
+	if cfg.StockLowTopic != "inventory.stock.low" {
+		t.Errorf("Expected StockLowTopic=inventory.stock.low, got %q", cfg.StockLowTopic)
+	}
+	if cfg.BackorderedTopic != "inventory.backordered" {
+		t.Errorf("Expected BackorderedTopic=inventory.backordered, got %q", cfg.BackorderedTopic)
+	}
+}
+
+func TestLoad_AdminHTTPAddr(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AdminHTTPAddr != "127.0.0.1:8083" {
+		t.Errorf("Expected AdminHTTPAddr=127.0.0.1:8083, got %q", cfg.AdminHTTPAddr)
+	}
+
+	// Явно пустой адрес отключает админский HTTP API
+	os.Setenv("INVENTORY_ADMIN_HTTP_ADDR", "")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AdminHTTPAddr != "" {
+		t.Errorf("Expected empty AdminHTTPAddr, got %q", cfg.AdminHTTPAddr)
+	}
+}
+
+func TestLoad_AdjustmentApprovers(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "local")
+	os.Setenv("INVENTORY_ADJUSTMENT_APPROVERS", " admin-1, ,admin-2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(cfg.AdjustmentApprovers) != 2 || cfg.AdjustmentApprovers[0] != "admin-1" || cfg.AdjustmentApprovers[1] != "admin-2" {
+		t.Errorf("Expected AdjustmentApprovers=[admin-1 admin-2], got %v", cfg.AdjustmentApprovers)
+	}
+}
+
+func TestLoad_StockCache(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.StockCacheEnabled {
+		t.Error("Expected stock cache to be disabled by default")
+	}
+	if cfg.StockCacheTTL != 5*time.Second {
+		t.Errorf("Expected StockCacheTTL=5s, got %s", cfg.StockCacheTTL)
+	}
+	if cfg.RedisAddr != "redis:6379" {
+		t.Errorf("Expected RedisAddr=redis:6379, got %q", cfg.RedisAddr)
+	}
+
+	os.Setenv("INVENTORY_STOCK_CACHE_ENABLED", "true")
+	os.Setenv("INVENTORY_STOCK_CACHE_TTL", "0s")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for zero INVENTORY_STOCK_CACHE_TTL with cache enabled")
+	}
+}
+
+func TestLoad_MongoReadiness(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.MongoCheckInterval != 5*time.Second {
+		t.Errorf("Expected MongoCheckInterval=5s, got %s", cfg.MongoCheckInterval)
+	}
+	if cfg.MongoFailureThreshold != 3 {
+		t.Errorf("Expected MongoFailureThreshold=3, got %d", cfg.MongoFailureThreshold)
+	}
+
+	os.Setenv("INVENTORY_MONGO_READINESS_CHECK_INTERVAL", "0s")
+	os.Setenv("INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD", "0")
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected disabled check to ignore threshold, got %v", err)
+	}
+
+	os.Setenv("INVENTORY_MONGO_READINESS_CHECK_INTERVAL", "1s")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for zero INVENTORY_MONGO_READINESS_FAILURE_THRESHOLD")
+	}
+}
+
+func TestLoad_OrderCancelledConsumer(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.OrderCancelledTopic != "order.cancelled" || cfg.OrderExpiredTopic != "order.expired" || cfg.OrderPaymentDeclinedTopic != "order.payment.declined" {
+		t.Errorf("Expected topics order.cancelled/order.expired/order.payment.declined, got %q/%q/%q", cfg.OrderCancelledTopic, cfg.OrderExpiredTopic, cfg.OrderPaymentDeclinedTopic)
+	}
+	if cfg.ConsumerGroupID != "inventory-service" {
+		t.Errorf("Expected ConsumerGroupID=inventory-service, got %s", cfg.ConsumerGroupID)
+	}
+
+	os.Setenv("KAFKA_RETRY_MAX_ATTEMPTS", "0")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for zero KAFKA_RETRY_MAX_ATTEMPTS")
+	}
+
+	os.Setenv("KAFKA_ORDER_CANCELLED_TOPIC", "")
+	os.Setenv("KAFKA_ORDER_EXPIRED_TOPIC", "")
+	if _, err := Load(); err == nil {
+		t.Error("Expected order.payment.declined topic to keep consumer enabled")
+	}
+
+	os.Setenv("KAFKA_ORDER_PAYMENT_DECLINED_TOPIC", "")
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected disabled consumer to ignore retry settings, got %v", err)
+	}
+}
+
+func TestLoad_ReservationTTLs(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.ReservationDefaultTTL != 0 || len(cfg.ReservationCategoryTTLs) != 0 {
+		t.Errorf("Expected no reservation ttl policy by default, got %s/%v", cfg.ReservationDefaultTTL, cfg.ReservationCategoryTTLs)
+	}
+
+	os.Setenv("INVENTORY_RESERVATION_DEFAULT_TTL", "15m")
+	os.Setenv("INVENTORY_RESERVATION_CATEGORY_TTLS", " Electronics=30m, groceries=10m ")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.ReservationDefaultTTL != 15*time.Minute {
+		t.Errorf("Expected ReservationDefaultTTL=15m, got %s", cfg.ReservationDefaultTTL)
+	}
+	if cfg.ReservationCategoryTTLs["electronics"] != 30*time.Minute || cfg.ReservationCategoryTTLs["groceries"] != 10*time.Minute {
+		t.Errorf("Unexpected ReservationCategoryTTLs: %v", cfg.ReservationCategoryTTLs)
+	}
+
+	for _, value := range []string{"electronics", "electronics=soon", "electronics=0s", "a=1m,A=2m"} {
+		os.Setenv("INVENTORY_RESERVATION_CATEGORY_TTLS", value)
+		if _, err := Load(); err == nil {
+			t.Errorf("Expected error for INVENTORY_RESERVATION_CATEGORY_TTLS=%q", value)
+		}
+	}
+}
+
+func TestLoad_ReservationRetention(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.ReservationRetention != 720*time.Hour {
+		t.Errorf("Expected ReservationRetention=720h by default, got %s", cfg.ReservationRetention)
+	}
+
+	os.Setenv("INVENTORY_RESERVATION_RETENTION", "0s")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.ReservationRetention != 0 {
+		t.Errorf("Expected retention disabled, got %s", cfg.ReservationRetention)
+	}
 
+	for _, value := range []string{"-1h", "500ms", "month"} {
+		os.Setenv("INVENTORY_RESERVATION_RETENTION", value)
+		if _, err := Load(); err == nil {
+			t.Errorf("Expected error for INVENTORY_RESERVATION_RETENTION=%q", value)
+		}
+	}
+}
+
+func TestLoad_AuthBypass(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "docker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AuthDisabled || len(cfg.AuthSkipMethods) != 0 {
+		t.Errorf("Expected auth enabled for all methods by default, got disabled=%v methods=%v", cfg.AuthDisabled, cfg.AuthSkipMethods)
+	}
+
+	os.Setenv("INVENTORY_AUTH_DISABLED", "true")
+	os.Setenv("INVENTORY_AUTH_SKIP_METHODS", " /inventory.v1.InventoryService/GetStock, /inventory.v1.InventoryService/GetStockBatch ")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.AuthDisabled || cfg.AuthDevUserID != "dev" {
+		t.Errorf("Expected dev mode with user dev, got disabled=%v user=%q", cfg.AuthDisabled, cfg.AuthDevUserID)
+	}
+	if len(cfg.AuthSkipMethods) != 2 || cfg.AuthSkipMethods[1] != "/inventory.v1.InventoryService/GetStockBatch" {
+		t.Errorf("Unexpected AuthSkipMethods: %v", cfg.AuthSkipMethods)
+	}
+
+	os.Setenv("INVENTORY_AUTH_DEV_USER_ID", "  ")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for empty INVENTORY_AUTH_DEV_USER_ID in dev mode")
+	}
+
+	os.Unsetenv("INVENTORY_AUTH_DEV_USER_ID")
+	os.Setenv("INVENTORY_AUTH_SKIP_METHODS", "GetStock")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for method without service in INVENTORY_AUTH_SKIP_METHODS")
+	}
+}
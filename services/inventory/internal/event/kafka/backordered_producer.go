@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// backorderedEventVersion - версия схемы события inventory.backordered
+const backorderedEventVersion = 1
+
+// KafkaBackorderedPublisher реализует service.BackorderPublisher используя Kafka
+// Writer асинхронный, как у inventory.stock.changed: событие публикуется из резервирования и не должно его замедлять
+type KafkaBackorderedPublisher struct {
+	logger *zap.Logger
+	writer *kafka.Writer
+	topic  string
+}
+
+// NewKafkaBackorderedPublisher создаёт Kafka publisher событий резервирования сверх остатка
+func NewKafkaBackorderedPublisher(logger *zap.Logger, brokers []string, topic string) *KafkaBackorderedPublisher {
+	writer := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.Hash{}, // партиция по ключу (product_id)
+		Async:    true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				logger.Error("failed to deliver backordered events",
+					zap.Error(err),
+					zap.String("topic", topic),
+					zap.Int("messages", len(messages)),
+				)
+			}
+		},
+	}
+
+	return &KafkaBackorderedPublisher{
+		logger: logger,
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Close дописывает буферизованные сообщения и закрывает Kafka writer
+func (p *KafkaBackorderedPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// PublishBackordered ставит событие inventory.backordered в очередь на отправку
+func (p *KafkaBackorderedPublisher) PublishBackordered(ctx context.Context, event service.BackorderedEvent) error {
+	payload := map[string]interface{}{
+		"event_id":        event.EventID,
+		"event_type":      service.EventTypeBackordered,
+		"event_version":   backorderedEventVersion,
+		"occurred_at":     event.OccurredAt.Format(time.RFC3339Nano),
+		"backorder_id":    event.BackorderID,
+		"product_id":      event.ProductID,
+		"order_id":        event.OrderID,
+		"reservation_id":  event.ReservationID,
+		"quantity":        event.Quantity,
+		"backordered":     event.Backordered,
+		"available":       event.Available,
+		"backorder_limit": event.Limit,
+	}
+
+	valueBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, p.topic)
+	defer span.End()
+
+	message := kafka.Message{
+		Key:     []byte(event.ProductID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, service.EventTypeBackordered, event.EventID, backorderedEventVersion).Kafka(),
+	}
+	return p.writer.WriteMessages(ctx, message)
+}
@@ -0,0 +1,217 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+)
+
+// Типы событий, после которых резервы заказа больше не нужны
+const (
+	eventTypeOrderCancelled       = "order.cancelled"
+	eventTypeOrderExpired         = "order.expired"
+	eventTypeOrderPaymentDeclined = "order.payment.declined" // Order сохраняет заказ payment_declined, товар он уже зарезервировал
+)
+
+// OrderReservationReleaser снимает активные резервы заказа; реализуется *service.InventoryService
+type OrderReservationReleaser interface {
+	ReleaseOrderReservations(ctx context.Context, orderID string) (int, error)
+}
+
+// orderCancelledEvent - событие order.cancelled, order.expired или order.payment.declined: из payload нужен только заказ
+type orderCancelledEvent struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	OrderID   string `json:"order_id"`
+	Reason    string `json:"reason"`
+}
+
+// OrderCancelledConsumer снимает резервы отменённых, истёкших и неоплаченных (отказ в оплате) заказов
+// Замыкает компенсацию без синхронного вызова из Order: Order публикует событие, Inventory возвращает товар в остаток
+// At-least-once: offset коммитится после обработки, повтор события ничего не снимает второй раз
+type OrderCancelledConsumer struct {
+	logger      *zap.Logger
+	reader      *kafka.Reader
+	service     OrderReservationReleaser
+	maxAttempts int
+	backoffBase time.Duration
+}
+
+// NewOrderCancelledConsumer создаёт consumer событий отмены заказа
+// topics - топики order.cancelled, order.expired и order.payment.declined (пустые пропускаются), читаются одной consumer group
+func NewOrderCancelledConsumer(
+	logger *zap.Logger,
+	brokers []string,
+	groupID string,
+	topics []string,
+	svc OrderReservationReleaser,
+	maxAttempts int,
+	backoffBase time.Duration,
+) *OrderCancelledConsumer {
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	if backoffBase <= 0 {
+		backoffBase = time.Second
+	}
+
+	groupTopics := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if topic != "" {
+			groupTopics = append(groupTopics, topic)
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		GroupTopics: groupTopics,
+		MinBytes:    1,
+		MaxBytes:    10e6, // 10MB
+	})
+
+	return &OrderCancelledConsumer{
+		logger:      logger,
+		reader:      reader,
+		service:     svc,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+	}
+}
+
+// Start читает события до отмены ctx или закрытия reader
+func (c *OrderCancelledConsumer) Start(ctx context.Context) error {
+	c.logger.Info("starting kafka consumer",
+		zap.Strings("topics", c.reader.Config().GroupTopics),
+		zap.String("group_id", c.reader.Config().GroupID),
+		zap.Int("max_retry_attempts", c.maxAttempts),
+		zap.Duration("retry_backoff_base", c.backoffBase),
+	)
+
+	for {
+		m, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				c.logger.Info("order cancelled consumer stopped")
+				return nil
+			}
+			c.logger.Error("failed to fetch message from kafka", zap.Error(err))
+			continue
+		}
+
+		if !c.processMessage(ctx, m) {
+			continue
+		}
+		if err := c.reader.CommitMessages(ctx, m); err != nil {
+			c.logger.Error("failed to commit message offset",
+				zap.Error(err),
+				zap.String("topic", m.Topic),
+				zap.Int("partition", m.Partition),
+				zap.Int64("offset", m.Offset),
+			)
+		}
+	}
+}
+
+// processMessage обрабатывает одно сообщение
+// Возвращает true, если offset нужно закоммитить: событие обработано, чужое или не разбирается
+func (c *OrderCancelledConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	fields := []zap.Field{
+		zap.String("topic", m.Topic),
+		zap.Int("partition", m.Partition),
+		zap.Int64("offset", m.Offset),
+	}
+
+	// Маршрутизация по заголовку event_type: чужие события пропускаем, не разбирая body
+	if eventType := headers.EventType(); eventType != "" && !isOrderCancelledEventType(eventType) {
+		c.logger.Warn("skipping message with unexpected event_type", append(fields, zap.String("event_type", eventType))...)
+		return true
+	}
+
+	var event orderCancelledEvent
+	if err := json.Unmarshal(m.Value, &event); err != nil {
+		// Poison pill коммитим, чтобы не зациклиться
+		c.logger.Error("failed to unmarshal order cancelled event", append(fields, zap.Error(err))...)
+		return true
+	}
+	if event.EventType != "" && !isOrderCancelledEventType(event.EventType) {
+		c.logger.Warn("skipping message with unexpected event_type", append(fields, zap.String("event_type", event.EventType))...)
+		return true
+	}
+	if event.OrderID == "" {
+		c.logger.Error("order cancelled event without order_id", append(fields, zap.String("event_id", event.EventID))...)
+		return true
+	}
+
+	fields = append(fields,
+		zap.String("event_id", event.EventID),
+		zap.String("event_type", event.EventType),
+		zap.String("order_id", event.OrderID),
+		zap.String("reason", event.Reason),
+	)
+	c.logger.Info("received order cancelled event", fields...)
+
+	released, err := c.handleWithRetry(ctx, event.OrderID)
+	if err != nil {
+		// После исчерпания попыток не коммитим: событие придёт снова после перезапуска или ребалансировки
+		c.logger.Error("failed to release order reservations after all retries", append(fields, zap.Error(err))...)
+		return false
+	}
+
+	c.logger.Info("order reservations released", append(fields, zap.Int("released", released))...)
+	return true
+}
+
+// handleWithRetry снимает резервы заказа с экспоненциальным backoff между попытками
+func (c *OrderCancelledConsumer) handleWithRetry(ctx context.Context, orderID string) (int, error) {
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := c.backoffBase * time.Duration(1<<uint(attempt-2))
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		released, err := c.service.ReleaseOrderReservations(ctx, orderID)
+		if err == nil {
+			return released, nil
+		}
+		lastErr = err
+		c.logger.Warn("failed to release order reservations",
+			zap.Error(err),
+			zap.String("order_id", orderID),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", c.maxAttempts),
+		)
+	}
+	return 0, lastErr
+}
+
+func isOrderCancelledEventType(eventType string) bool {
+	switch eventType {
+	case eventTypeOrderCancelled, eventTypeOrderExpired, eventTypeOrderPaymentDeclined:
+		return true
+	}
+	return false
+}
+
+// Close закрывает Kafka reader
+func (c *OrderCancelledConsumer) Close() error {
+	c.logger.Info("closing order cancelled consumer")
+	return c.reader.Close()
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+)
+
+// fakeReleaser запоминает заказы, резервы которых снимал consumer; err - ошибка каждого вызова
+type fakeReleaser struct {
+	orders []string
+	err    error
+}
+
+func (f *fakeReleaser) ReleaseOrderReservations(ctx context.Context, orderID string) (int, error) {
+	f.orders = append(f.orders, orderID)
+	if f.err != nil {
+		return 0, f.err
+	}
+	return 2, nil
+}
+
+func newTestOrderCancelledConsumer(releaser OrderReservationReleaser) *OrderCancelledConsumer {
+	return &OrderCancelledConsumer{logger: zap.NewNop(), service: releaser, maxAttempts: 2, backoffBase: time.Millisecond}
+}
+
+func orderEventMessage(topic, headerEventType, value string) kafka.Message {
+	var headers []kafka.Header
+	if headerEventType != "" {
+		headers = platformkafka.Headers{platformkafka.HeaderEventType: headerEventType}.Kafka()
+	}
+	return kafka.Message{Topic: topic, Headers: headers, Value: []byte(value)}
+}
+
+func TestOrderCancelledConsumer_ProcessMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      kafka.Message
+		wantReleased []string
+	}{
+		{
+			name: "payment declined releases order reservations",
+			message: orderEventMessage("order.payment.declined", "order.payment.declined",
+				`{"event_id":"e-1","event_type":"order.payment.declined","order_id":"order-1","user_id":"user-1","reason":"insufficient_funds"}`),
+			wantReleased: []string{"order-1"},
+		},
+		{
+			name:         "cancelled releases order reservations",
+			message:      orderEventMessage("order.cancelled", "order.cancelled", `{"event_id":"e-2","event_type":"order.cancelled","order_id":"order-2"}`),
+			wantReleased: []string{"order-2"},
+		},
+		{
+			name:         "expired without header releases order reservations",
+			message:      orderEventMessage("order.expired", "", `{"event_id":"e-3","event_type":"order.expired","order_id":"order-3"}`),
+			wantReleased: []string{"order-3"},
+		},
+		{
+			name:    "foreign event type in header is skipped",
+			message: orderEventMessage("order.payment.declined", "order.payment.completed", `{"order_id":"order-4"}`),
+		},
+		{
+			name:    "foreign event type in payload is skipped",
+			message: orderEventMessage("order.payment.declined", "", `{"event_type":"order.assembled","order_id":"order-5"}`),
+		},
+		{
+			name:    "event without order_id is skipped",
+			message: orderEventMessage("order.payment.declined", "order.payment.declined", `{"event_type":"order.payment.declined"}`),
+		},
+		{
+			name:    "poison pill is skipped",
+			message: orderEventMessage("order.payment.declined", "order.payment.declined", `{not json`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			releaser := &fakeReleaser{}
+			consumer := newTestOrderCancelledConsumer(releaser)
+
+			commit := consumer.processMessage(context.Background(), tt.message)
+
+			require.True(t, commit)
+			require.Equal(t, tt.wantReleased, releaser.orders)
+		})
+	}
+
+	t.Run("failed release is retried and not committed", func(t *testing.T) {
+		releaser := &fakeReleaser{err: errors.New("mongo unavailable")}
+		consumer := newTestOrderCancelledConsumer(releaser)
+
+		commit := consumer.processMessage(context.Background(), orderEventMessage("order.payment.declined", "order.payment.declined",
+			`{"event_type":"order.payment.declined","order_id":"order-1"}`))
+
+		require.False(t, commit)
+		require.Equal(t, []string{"order-1", "order-1"}, releaser.orders)
+	})
+}
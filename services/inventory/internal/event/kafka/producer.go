@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// tracerName - имя сервиса для span публикации сообщений
+const tracerName = "inventory"
+
+// stockChangedEventVersion - версия схемы события inventory.stock.changed
+const stockChangedEventVersion = 1
+
+// KafkaStockEventPublisher реализует service.StockEventPublisher используя Kafka
+// Writer асинхронный: PublishStockChanged не ждёт подтверждения брокера и не замедляет резервирование,
+// ошибки доставки только логируются (best-effort, события не заменяют чтение остатка)
+type KafkaStockEventPublisher struct {
+	logger *zap.Logger
+	writer *kafka.Writer
+	topic  string
+}
+
+// NewKafkaStockEventPublisher создаёт Kafka publisher событий изменения остатка
+func NewKafkaStockEventPublisher(logger *zap.Logger, brokers []string, topic string) *KafkaStockEventPublisher {
+	writer := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.Hash{}, // партиция по ключу (product_id): события товара читаются по порядку
+		Async:    true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				logger.Error("failed to deliver stock changed events",
+					zap.Error(err),
+					zap.String("topic", topic),
+					zap.Int("messages", len(messages)),
+				)
+			}
+		},
+	}
+
+	return &KafkaStockEventPublisher{
+		logger: logger,
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Close дописывает буферизованные сообщения и закрывает Kafka writer
+func (p *KafkaStockEventPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// PublishStockChanged ставит событие inventory.stock.changed в очередь на отправку
+func (p *KafkaStockEventPublisher) PublishStockChanged(ctx context.Context, event service.StockChangedEvent) error {
+	payload := map[string]interface{}{
+		"event_id":      event.EventID,
+		"event_type":    service.EventTypeStockChanged,
+		"event_version": stockChangedEventVersion,
+		"occurred_at":   event.OccurredAt.Format(time.RFC3339Nano),
+		"product_id":    event.ProductID,
+		"delta":         event.Delta,
+		"reason":        event.Reason,
+	}
+	if event.Available != nil {
+		payload["available"] = *event.Available
+	}
+	if event.ReservationID != "" {
+		payload["reservation_id"] = event.ReservationID
+	}
+	if event.OrderID != "" {
+		payload["order_id"] = event.OrderID
+	}
+
+	valueBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, p.topic)
+	defer span.End()
+
+	message := kafka.Message{
+		Key:     []byte(event.ProductID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, service.EventTypeStockChanged, event.EventID, stockChangedEventVersion).Kafka(),
+	}
+	// Для асинхронного writer ошибка здесь - только переполнение/закрытие, доставка логируется в Completion
+	return p.writer.WriteMessages(ctx, message)
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// stockLowEventVersion - версия схемы события inventory.stock.low
+const stockLowEventVersion = 1
+
+// KafkaStockLowPublisher реализует service.StockLowPublisher используя Kafka
+// Writer асинхронный, как у inventory.stock.changed: событие публикуется из резервирования и не должно его замедлять
+type KafkaStockLowPublisher struct {
+	logger *zap.Logger
+	writer *kafka.Writer
+	topic  string
+}
+
+// NewKafkaStockLowPublisher создаёт Kafka publisher событий низкого остатка
+func NewKafkaStockLowPublisher(logger *zap.Logger, brokers []string, topic string) *KafkaStockLowPublisher {
+	writer := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.Hash{}, // партиция по ключу (product_id)
+		Async:    true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				logger.Error("failed to deliver stock low events",
+					zap.Error(err),
+					zap.String("topic", topic),
+					zap.Int("messages", len(messages)),
+				)
+			}
+		},
+	}
+
+	return &KafkaStockLowPublisher{
+		logger: logger,
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Close дописывает буферизованные сообщения и закрывает Kafka writer
+func (p *KafkaStockLowPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// PublishStockLow ставит событие inventory.stock.low в очередь на отправку
+func (p *KafkaStockLowPublisher) PublishStockLow(ctx context.Context, event service.StockLowEvent) error {
+	payload := map[string]interface{}{
+		"event_id":      event.EventID,
+		"event_type":    service.EventTypeStockLow,
+		"event_version": stockLowEventVersion,
+		"occurred_at":   event.OccurredAt.Format(time.RFC3339Nano),
+		"product_id":    event.ProductID,
+		"sku":           event.SKU,
+		"name":          event.Name,
+		"available":     event.Available,
+		"threshold":     event.Threshold,
+	}
+
+	valueBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, p.topic)
+	defer span.End()
+
+	message := kafka.Message{
+		Key:     []byte(event.ProductID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, service.EventTypeStockLow, event.EventID, stockLowEventVersion).Kafka(),
+	}
+	return p.writer.WriteMessages(ctx, message)
+}
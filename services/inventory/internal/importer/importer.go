@@ -0,0 +1,235 @@
+// Package importer читает файлы импорта каталога и остатков (CSV и JSON) для cmd/inventory-import
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+// Форматы файла импорта
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Колонки CSV; product_id, sku и name обязательны, остальные можно не указывать
+const (
+	columnProductID         = "product_id"
+	columnSKU               = "sku"
+	columnName              = "name"
+	columnPrice             = "price"
+	columnCurrency          = "currency"
+	columnLowStockThreshold = "low_stock_threshold"
+	columnBackorderLimit    = "backorder_limit"
+	columnCategory          = "category"
+	columnAttributes        = "attributes" // пары key=value через ';'
+	columnStock             = "stock"      // пусто - остаток не меняется
+	columnWarehouseID       = "warehouse_id"
+)
+
+var knownColumns = []string{
+	columnProductID, columnSKU, columnName, columnPrice, columnCurrency,
+	columnLowStockThreshold, columnBackorderLimit, columnCategory, columnAttributes, columnStock, columnWarehouseID,
+}
+
+// ErrUnknownFormat возвращается для формата, отличного от csv и json
+var ErrUnknownFormat = errors.New("unknown import format, expected csv or json")
+
+// FormatFromPath определяет формат по расширению файла
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, path)
+	}
+}
+
+// Read читает записи импорта в формате format
+func Read(r io.Reader, format string) ([]service.ImportRecord, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(r)
+	case FormatJSON:
+		return ReadJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// ReadCSV читает CSV с заголовком; порядок колонок произвольный, неизвестные колонки - ошибка
+// Line записи - номер строки файла (заголовок - строка 1)
+func ReadCSV(r io.Reader) ([]service.ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isKnownColumn(name) {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("duplicate csv column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{columnProductID, columnSKU, columnName} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv column %q is required", required)
+		}
+	}
+
+	var records []service.ImportRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := service.ImportRecord{
+			Line: line,
+			Product: service.ProductInput{
+				ProductID: field(columnProductID),
+				SKU:       field(columnSKU),
+				Name:      field(columnName),
+				Currency:  field(columnCurrency),
+				Category:  field(columnCategory),
+			},
+			WarehouseID: field(columnWarehouseID),
+		}
+		if value := field(columnPrice); value != "" {
+			if record.Product.Price, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid price %q", line, value)
+			}
+		}
+		if value := field(columnLowStockThreshold); value != "" {
+			threshold, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid low_stock_threshold %q", line, value)
+			}
+			record.Product.LowStockThreshold = int32(threshold)
+		}
+		if value := field(columnBackorderLimit); value != "" {
+			limit, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid backorder_limit %q", line, value)
+			}
+			record.Product.BackorderLimit = int32(limit)
+		}
+		if value := field(columnStock); value != "" {
+			stock, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid stock %q", line, value)
+			}
+			s := int32(stock)
+			record.Stock = &s
+		}
+		if value := field(columnAttributes); value != "" {
+			if record.Product.Attributes, err = parseAttributes(value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// jsonRecord - элемент JSON-массива импорта
+type jsonRecord struct {
+	ProductID         string            `json:"product_id"`
+	SKU               string            `json:"sku"`
+	Name              string            `json:"name"`
+	Price             int64             `json:"price"`
+	Currency          string            `json:"currency"`
+	Attributes        map[string]string `json:"attributes"`
+	LowStockThreshold int32             `json:"low_stock_threshold"`
+	BackorderLimit    int32             `json:"backorder_limit"`
+	Category          string            `json:"category"`
+	Stock             *int32            `json:"stock"` // null или отсутствует - остаток не меняется
+	WarehouseID       string            `json:"warehouse_id"`
+}
+
+// ReadJSON читает JSON-массив записей; неизвестные поля - ошибка
+// Line записи - номер элемента массива, начиная с 1
+func ReadJSON(r io.Reader) ([]service.ImportRecord, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var items []jsonRecord
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	records := make([]service.ImportRecord, len(items))
+	for i, item := range items {
+		records[i] = service.ImportRecord{
+			Line: i + 1,
+			Product: service.ProductInput{
+				ProductID:         item.ProductID,
+				SKU:               item.SKU,
+				Name:              item.Name,
+				Price:             item.Price,
+				Currency:          item.Currency,
+				Attributes:        item.Attributes,
+				LowStockThreshold: item.LowStockThreshold,
+				BackorderLimit:    item.BackorderLimit,
+				Category:          item.Category,
+			},
+			Stock:       item.Stock,
+			WarehouseID: item.WarehouseID,
+		}
+	}
+	return records, nil
+}
+
+// parseAttributes разбирает атрибуты вида "color=red;size=L"
+func parseAttributes(value string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid attribute %q, expected key=value", pair)
+		}
+		attributes[key] = strings.TrimSpace(val)
+	}
+	return attributes, nil
+}
+
+func isKnownColumn(name string) bool {
+	for _, known := range knownColumns {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
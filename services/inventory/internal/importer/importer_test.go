@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/service"
+)
+
+func TestReadCSV(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		input := "product_id,sku,name,price,currency,low_stock_threshold,attributes,stock,warehouse_id\n" +
+			"product-1,SKU-1,Keyboard,1000,usd,5,color=black;layout=ru,10,\n" +
+			"product-1,SKU-1,Keyboard,1000,usd,5,color=black;layout=ru,4,msk\n" +
+			"product-2,SKU-2,\"Mouse, wireless\",500,,,,,\n"
+
+		records, err := ReadCSV(strings.NewReader(input))
+
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, service.ImportRecord{
+			Line: 2,
+			Product: service.ProductInput{
+				ProductID:         "product-1",
+				SKU:               "SKU-1",
+				Name:              "Keyboard",
+				Price:             1000,
+				Currency:          "usd",
+				Attributes:        map[string]string{"color": "black", "layout": "ru"},
+				LowStockThreshold: 5,
+			},
+			Stock: records[0].Stock,
+		}, records[0])
+		require.Equal(t, int32(10), *records[0].Stock)
+		require.Equal(t, "msk", records[1].WarehouseID)
+		require.Equal(t, "Mouse, wireless", records[2].Product.Name)
+		require.Nil(t, records[2].Stock)
+	})
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "unknown column", input: "product_id,sku,name,qty\n"},
+		{name: "missing required column", input: "product_id,name\n"},
+		{name: "invalid price", input: "product_id,sku,name,price\nproduct-1,SKU-1,Keyboard,ten\n"},
+		{name: "invalid stock", input: "product_id,sku,name,stock\nproduct-1,SKU-1,Keyboard,1.5\n"},
+		{name: "invalid attributes", input: "product_id,sku,name,attributes\nproduct-1,SKU-1,Keyboard,color\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.input))
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{"product_id": "product-1", "sku": "SKU-1", "name": "Keyboard", "price": 1000, "attributes": {"color": "black"}, "stock": 0},
+		{"product_id": "product-2", "sku": "SKU-2", "name": "Mouse", "stock": null}
+	]`
+
+	records, err := ReadJSON(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, 1, records[0].Line)
+	require.Equal(t, int32(0), *records[0].Stock)
+	require.Equal(t, map[string]string{"color": "black"}, records[0].Product.Attributes)
+	require.Nil(t, records[1].Stock)
+
+	_, err = ReadJSON(strings.NewReader(`[{"product_id": "product-1", "qty": 1}]`))
+	require.Error(t, err)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	format, err := FormatFromPath("seed/products.CSV")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	_, err = FormatFromPath("seed/products.xlsx")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -2,6 +2,7 @@ package interceptor
 
 import (
 	"context"
+	"net/http"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -29,17 +30,44 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	return userID, ok
 }
 
+// AuthBypass - явные исключения из проверки сессии для локальной разработки, e2e и внутренних вызовов
+// Нулевое значение - проверка сессии для всех методов, кроме health и reflection
+type AuthBypass struct {
+	// Disabled - dev mode: сессия не проверяется ни в gRPC, ни в HTTP, в context кладётся UserID
+	Disabled bool
+	// UserID - user_id запросов в dev mode (журнал движений, корректировки)
+	UserID string
+	// Methods - полные имена gRPC методов без проверки сессии, например /inventory.v1.InventoryService/GetStock
+	Methods []string
+}
+
 // AuthInterceptor проверяет сессию через IAM Service
 type AuthInterceptor struct {
-	iamClient iamclient.IAMClient
-	logger    *zap.Logger
+	iamClient   iamclient.IAMClient
+	logger      *zap.Logger
+	bypass      AuthBypass
+	skipMethods map[string]struct{}
 }
 
 // NewAuthInterceptor создаёт новый auth interceptor
-func NewAuthInterceptor(iamClient iamclient.IAMClient, logger *zap.Logger) *AuthInterceptor {
+// iamClient может быть nil, если bypass.Disabled (IAM не вызывается)
+func NewAuthInterceptor(iamClient iamclient.IAMClient, logger *zap.Logger, bypass AuthBypass) *AuthInterceptor {
+	skipMethods := make(map[string]struct{}, len(bypass.Methods))
+	for _, method := range bypass.Methods {
+		skipMethods[method] = struct{}{}
+	}
+	if bypass.Disabled {
+		logger.Warn("auth is disabled: session validation is skipped for all methods",
+			zap.String("user_id", bypass.UserID),
+		)
+	} else if len(skipMethods) > 0 {
+		logger.Warn("auth is skipped for allowlisted methods", zap.Strings("methods", bypass.Methods))
+	}
 	return &AuthInterceptor{
-		iamClient: iamClient,
-		logger:    logger,
+		iamClient:   iamClient,
+		logger:      logger,
+		bypass:      bypass,
+		skipMethods: skipMethods,
 	}
 }
 
@@ -55,53 +83,163 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 		if a.isPublicMethod(info.FullMethod) { // если метод публичный, пропускаем проверку сессии
 			return handler(ctx, req) // вызываем следующий handler
 		}
+		if a.bypass.Disabled {
+			return handler(a.devContext(ctx), req)
+		}
 
-		// Извлекаем metadata из контекста
-		md, ok := metadata.FromIncomingContext(ctx) // md - metadata, ok - флаг, который показывает, есть ли metadata в контексте
-		if !ok {                                    // если metadata нет в контексте, возвращаем ошибку
-			a.logger.Warn("no metadata in context",
-				zap.String("method", info.FullMethod),
-			)
-			return nil, status.Error(codes.Unauthenticated, "session_id is required")
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
 
-		// Получаем session_id из metadata
-		sessionIDs := md.Get(SessionIDHeader)
-		if len(sessionIDs) == 0 || sessionIDs[0] == "" {
-			a.logger.Warn("session_id not found in metadata",
-				zap.String("method", info.FullMethod),
-			)
-			return nil, status.Error(codes.Unauthenticated, "session_id is required")
+		// Вызываем следующий handler
+		return handler(ctx, req)
+	}
+}
+
+// Stream возвращает stream interceptor с той же проверкой сессии, что и Unary (WatchStock)
+// Сессия проверяется один раз при открытии потока
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if a.bypass.Disabled {
+			return handler(srv, &authenticatedStream{ServerStream: ss, ctx: a.devContext(ss.Context())})
+		}
+
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream подменяет context потока на context с user_id
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate валидирует x-session-id из metadata через IAM и кладёт user_id в context
+func (a *AuthInterceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	// Извлекаем metadata из контекста
+	md, ok := metadata.FromIncomingContext(ctx) // md - metadata, ok - флаг, который показывает, есть ли metadata в контексте
+	if !ok {                                    // если metadata нет в контексте, возвращаем ошибку
+		a.logger.Warn("no metadata in context",
+			zap.String("method", fullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "session_id is required")
+	}
+
+	// Получаем session_id из metadata
+	sessionIDs := md.Get(SessionIDHeader)
+	if len(sessionIDs) == 0 || sessionIDs[0] == "" {
+		a.logger.Warn("session_id not found in metadata",
+			zap.String("method", fullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "session_id is required")
+	}
+
+	sessionID := sessionIDs[0]
+
+	// Валидируем сессию через IAM Service
+	session, err := a.iamClient.ValidateSession(ctx, sessionID)
+	if err != nil {
+		a.logger.Warn("session validation failed",
+			zap.Error(err),
+			zap.String("session_id", sessionID),
+			zap.String("method", fullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired session")
+	}
+
+	a.logger.Debug("session validated",
+		zap.String("user_id", session.UserID),
+		zap.String("method", fullMethod),
+	)
+
+	// Добавляем user_id в контекст для использования в handlers userIDKey - ключ для хранения user_id в context
+	return context.WithValue(ctx, userIDKey, session.UserID), nil
+}
+
+// HTTP возвращает HTTP middleware с той же проверкой сессии, что и Unary: заголовок x-session-id валидируется через IAM
+// Без сессии или с невалидной сессией - 401, иначе user_id кладётся в context (UserIDFromContext)
+func (a *AuthInterceptor) HTTP(next http.Handler) http.Handler {
+	return a.httpAuth(next, false)
+}
+
+// AdminHTTP - HTTP, который пропускает только сессии пользователей с ролью admin в IAM; сессия обычного пользователя - 403
+// В dev mode (AuthBypass.Disabled) роль не проверяется, как и сама сессия
+func (a *AuthInterceptor) AdminHTTP(next http.Handler) http.Handler {
+	return a.httpAuth(next, true)
+}
+
+func (a *AuthInterceptor) httpAuth(next http.Handler, requireAdmin bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.bypass.Disabled {
+			next.ServeHTTP(w, r.WithContext(a.devContext(r.Context())))
+			return
 		}
 
-		sessionID := sessionIDs[0]
+		sessionID := r.Header.Get(SessionIDHeader)
+		if sessionID == "" {
+			a.logger.Warn("session_id not found in headers",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+			)
+			http.Error(w, "session_id is required", http.StatusUnauthorized)
+			return
+		}
 
-		// Валидируем сессию через IAM Service
-		userID, err := a.iamClient.ValidateSession(ctx, sessionID)
+		session, err := a.iamClient.ValidateSession(r.Context(), sessionID)
 		if err != nil {
 			a.logger.Warn("session validation failed",
 				zap.Error(err),
 				zap.String("session_id", sessionID),
-				zap.String("method", info.FullMethod),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
 			)
-			return nil, status.Error(codes.Unauthenticated, "invalid or expired session")
+			http.Error(w, "invalid or expired session", http.StatusUnauthorized)
+			return
+		}
+		if requireAdmin && session.Role != iamclient.RoleAdmin {
+			a.logger.Warn("admin role required",
+				zap.String("user_id", session.UserID),
+				zap.String("role", session.Role),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+			)
+			http.Error(w, "admin access required", http.StatusForbidden)
+			return
 		}
-
-		// Добавляем user_id в контекст для использования в handlers userIDKey - ключ для хранения user_id в context 
-		ctx = context.WithValue(ctx, userIDKey, userID)
 
 		a.logger.Debug("session validated",
-			zap.String("user_id", userID),
-			zap.String("method", info.FullMethod),
+			zap.String("user_id", session.UserID),
+			zap.String("path", r.URL.Path),
 		)
 
-		// Вызываем следующий handler
-		return handler(ctx, req)
-	}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIDKey, session.UserID)))
+	})
+}
+
+// devContext кладёт в context user_id dev mode вместо проверенного через IAM
+func (a *AuthInterceptor) devContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, userIDKey, a.bypass.UserID)
 }
 
 // isPublicMethod проверяет, является ли метод публичным (не требует аутентификации)
 func (a *AuthInterceptor) isPublicMethod(fullMethod string) bool {
+	// Методы из allowlist конфигурации
+	if _, ok := a.skipMethods[fullMethod]; ok {
+		return true
+	}
+
 	// Health check методы
 	if fullMethod == "/grpc.health.v1.Health/Check" ||
 		fullMethod == "/grpc.health.v1.Health/Watch" {
@@ -0,0 +1,142 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	iamclient "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc"
+	iammocks "github.com/shestoi/GoBigTech/services/inventory/internal/client/grpc/mocks"
+)
+
+const (
+	getStockMethod     = "/inventory.v1.InventoryService/GetStock"
+	reserveStockMethod = "/inventory.v1.InventoryService/ReserveStock"
+)
+
+// callUnary вызывает unary interceptor и возвращает user_id из context handler
+func callUnary(ctx context.Context, auth *AuthInterceptor, method string) (string, error) {
+	var userID string
+	_, err := auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		userID, _ = UserIDFromContext(ctx)
+		return nil, nil
+	})
+	return userID, err
+}
+
+func TestAuthInterceptor_Unary(t *testing.T) {
+	withSession := metadata.NewIncomingContext(context.Background(), metadata.Pairs(SessionIDHeader, "sid"))
+
+	t.Run("session is validated by default", func(t *testing.T) {
+		iamClient := iammocks.NewIAMClient(t)
+		iamClient.On("ValidateSession", mock.Anything, "sid").Return(iamclient.Session{UserID: "user-1", Role: "user"}, nil).Once()
+		auth := NewAuthInterceptor(iamClient, zap.NewNop(), AuthBypass{})
+
+		userID, err := callUnary(withSession, auth, reserveStockMethod)
+
+		require.NoError(t, err)
+		require.Equal(t, "user-1", userID)
+	})
+
+	t.Run("no session is rejected", func(t *testing.T) {
+		auth := NewAuthInterceptor(iammocks.NewIAMClient(t), zap.NewNop(), AuthBypass{})
+
+		_, err := callUnary(context.Background(), auth, reserveStockMethod)
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("allowlisted method skips session validation", func(t *testing.T) {
+		iamClient := iammocks.NewIAMClient(t)
+		auth := NewAuthInterceptor(iamClient, zap.NewNop(), AuthBypass{Methods: []string{getStockMethod}})
+
+		_, err := callUnary(context.Background(), auth, getStockMethod)
+		require.NoError(t, err)
+
+		// Остальные методы по-прежнему требуют сессию
+		_, err = callUnary(context.Background(), auth, reserveStockMethod)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		iamClient.AssertNotCalled(t, "ValidateSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dev mode skips IAM and sets dev user", func(t *testing.T) {
+		auth := NewAuthInterceptor(nil, zap.NewNop(), AuthBypass{Disabled: true, UserID: "dev"})
+
+		userID, err := callUnary(context.Background(), auth, reserveStockMethod)
+
+		require.NoError(t, err)
+		require.Equal(t, "dev", userID)
+	})
+}
+
+func TestAuthInterceptor_HTTP(t *testing.T) {
+	var gotUserID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, _ = UserIDFromContext(r.Context())
+	})
+
+	t.Run("dev mode skips IAM and sets dev user", func(t *testing.T) {
+		auth := NewAuthInterceptor(nil, zap.NewNop(), AuthBypass{Disabled: true, UserID: "dev"})
+		rec := httptest.NewRecorder()
+
+		auth.HTTP(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stock/product-1", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "dev", gotUserID)
+	})
+
+	t.Run("invalid session is rejected without dev mode", func(t *testing.T) {
+		iamClient := iammocks.NewIAMClient(t)
+		iamClient.On("ValidateSession", mock.Anything, "expired").Return(iamclient.Session{}, errors.New("session expired")).Once()
+		auth := NewAuthInterceptor(iamClient, zap.NewNop(), AuthBypass{})
+		req := httptest.NewRequest(http.MethodGet, "/admin/stock/product-1", nil)
+		req.Header.Set(SessionIDHeader, "expired")
+		rec := httptest.NewRecorder()
+
+		auth.HTTP(next).ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestAuthInterceptor_AdminHTTP(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name         string
+		session      iamclient.Session
+		bypass       AuthBypass
+		expectedCode int
+	}{
+		{name: "admin", session: iamclient.Session{UserID: "admin-1", Role: iamclient.RoleAdmin}, expectedCode: http.StatusOK},
+		{name: "user", session: iamclient.Session{UserID: "user-1", Role: "user"}, expectedCode: http.StatusForbidden},
+		{name: "dev mode skips role check", bypass: AuthBypass{Disabled: true, UserID: "dev"}, expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iamClient := iammocks.NewIAMClient(t)
+			if !tt.bypass.Disabled {
+				iamClient.On("ValidateSession", mock.Anything, "sid").Return(tt.session, nil).Once()
+			}
+			auth := NewAuthInterceptor(iamClient, zap.NewNop(), tt.bypass)
+			req := httptest.NewRequest(http.MethodGet, "/admin/stock/product-1", nil)
+			req.Header.Set(SessionIDHeader, "sid")
+			rec := httptest.NewRecorder()
+
+			auth.AdminHTTP(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
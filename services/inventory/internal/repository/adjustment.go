@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Статусы пакета корректировок остатка
+const (
+	AdjustmentStatusDraft    = "draft"    // создан, ждёт одобрения; остаток не изменён
+	AdjustmentStatusApproved = "approved" // одобрен и применяется
+	AdjustmentStatusApplied  = "applied"  // все позиции применены, движения записаны
+	AdjustmentStatusRejected = "rejected" // отклонён, остаток не изменён
+	AdjustmentStatusFailed   = "failed"   // применить не удалось, уже применённые позиции откатаны
+)
+
+// AdjustmentItem - корректировка остатка товара на одном складе
+type AdjustmentItem struct {
+	ProductID   string
+	WarehouseID string
+	Delta       int32 // положительное - излишек, отрицательное - недостача
+}
+
+// Adjustment - пакет корректировок остатка (инвентаризация, списание брака)
+// Применяется только после одобрения и целиком: либо все позиции, либо ни одной
+type Adjustment struct {
+	ID         string
+	Reason     string
+	Items      []AdjustmentItem
+	Status     string
+	CreatedBy  string // user_id автора
+	CreatedAt  time.Time
+	ReviewedBy string    // user_id одобрившего или отклонившего
+	ReviewedAt time.Time // нулевое значение - ещё не рассмотрен
+	AppliedAt  time.Time // нулевое значение - не применён
+	Comment    string    // причина отклонения или ошибка применения
+}
+
+// AdjustmentStatusUpdate - новый статус пакета и сопутствующие поля; нулевые поля не меняются
+type AdjustmentStatusUpdate struct {
+	Status     string
+	ReviewedBy string
+	ReviewedAt time.Time
+	AppliedAt  time.Time
+	Comment    string
+}
+
+// AdjustmentRepository определяет интерфейс для хранения пакетов корректировок
+// Остаток меняет InventoryRepository, здесь только учёт самих пакетов
+type AdjustmentRepository interface {
+	// CreateAdjustment сохраняет новый пакет
+	CreateAdjustment(ctx context.Context, adjustment Adjustment) error
+
+	// GetAdjustment возвращает пакет по ID
+	// Возвращает ErrAdjustmentNotFound, если пакета нет
+	GetAdjustment(ctx context.Context, adjustmentID string) (Adjustment, error)
+
+	// ListAdjustments возвращает до limit пакетов в статусе status (пусто - в любом), новые первыми
+	ListAdjustments(ctx context.Context, status string, limit int) ([]Adjustment, error)
+
+	// UpdateAdjustmentStatus атомарно переводит пакет из статуса from и возвращает его после изменения
+	// Только один вызов для пакета в статусе from получает успех, поэтому пакет применяется не больше одного раза
+	// Возвращает ErrAdjustmentNotFound, если пакета нет, и ErrAdjustmentStatusConflict (с текущим пакетом), если он не в статусе from
+	UpdateAdjustmentStatus(ctx context.Context, adjustmentID, from string, update AdjustmentStatusUpdate) (Adjustment, error)
+}
+
+// ErrAdjustmentNotFound возвращается, когда пакет корректировок не найден
+var ErrAdjustmentNotFound = errors.New("adjustment not found")
+
+// ErrAdjustmentStatusConflict возвращается, когда пакет уже рассмотрен (не в ожидаемом статусе)
+var ErrAdjustmentStatusConflict = errors.New("adjustment is not in the expected status")
@@ -0,0 +1,37 @@
+package repository
+
+import "context"
+
+// StockSummary - сводка по остатку товара для аналитики: сколько доступно, сколько под резервами
+// Резерв списывает товар с остатка, поэтому Available уже за вычетом Reserved
+type StockSummary struct {
+	ProductID   string
+	Available   int32 // суммарный остаток; отрицательный, если предзаказ увёл его в минус
+	Reserved    int32 // сумма активных резервов
+	Backordered int32 // сколько из Reserved зарезервировано сверх остатка
+	Warehouses  []WarehouseStockSummary
+}
+
+// WarehouseStockSummary - сводка по товару на одном складе
+// Резервы, созданные до появления складов (без Allocations), относятся к складу по умолчанию
+type WarehouseStockSummary struct {
+	WarehouseID string
+	Available   int32
+	Reserved    int32
+	Backordered int32 // сверх остатка резервируется только склад по умолчанию
+}
+
+// StockAnalyticsFilter - условия выборки сводки; пустое поле не фильтрует
+type StockAnalyticsFilter struct {
+	ProductIDs     []string
+	WarehouseID    string // только товары, у которых есть этот склад, и только его строка в Warehouses
+	AfterProductID string // курсор: товары с product_id больше AfterProductID
+	Limit          int
+}
+
+// StockAnalyticsRepository считает сводку остатков и резервов на стороне хранилища
+type StockAnalyticsRepository interface {
+	// GetStockAnalytics возвращает до filter.Limit товаров, отсортированных по product_id
+	// Товар попадает в выборку, если у него есть остаток в хранилище (в том числе нулевой)
+	GetStockAnalytics(ctx context.Context, filter StockAnalyticsFilter) ([]StockSummary, error)
+}
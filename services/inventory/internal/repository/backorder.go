@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Backorder - запись журнала предзаказов: резервирование, которому не хватило остатка
+// и которое увело остаток склада по умолчанию в минус (товары с Product.BackorderLimit > 0)
+type Backorder struct {
+	ID            string
+	ProductID     string
+	OrderID       string // пусто, если резерв сделан не под заказ
+	ReservationID string // пусто для ReserveStock без резерва
+	Quantity      int32  // сколько единиц зарезервировано всего
+	Backordered   int32  // сколько из них зарезервировано сверх остатка
+	Available     int32  // суммарный остаток после резервирования (отрицательный)
+	CreatedAt     time.Time
+}
+
+// BackorderStockRepository списывает остаток с разрешённым уходом в минус (предзаказ)
+type BackorderStockRepository interface {
+	WarehouseStockRepository
+
+	// ReserveBackorder атомарно списывает allocations с остатков складов
+	// Остаток склада по умолчанию может уйти в минус, но не ниже -limit; остальные склады - не ниже нуля.
+	// Если товара ещё нет в хранилище, он создаётся с отрицательным остатком.
+	// Если условие не выполнено (остаток изменился или лимит исчерпан), ничего не меняется и возвращается false
+	// Возвращает суммарный остаток после списания
+	ReserveBackorder(ctx context.Context, productID string, allocations []WarehouseStock, limit int32) (int32, bool, error)
+}
+
+// BackorderRepository определяет интерфейс для журнала предзаказов
+// Остаток меняет BackorderStockRepository, здесь только учёт резервирований сверх остатка
+type BackorderRepository interface {
+	// CreateBackorder сохраняет запись журнала
+	CreateBackorder(ctx context.Context, backorder Backorder) error
+
+	// ListBackorders возвращает до limit записей журнала товара productID (пусто - всех товаров), новые первыми
+	ListBackorders(ctx context.Context, productID string, limit int) ([]Backorder, error)
+}
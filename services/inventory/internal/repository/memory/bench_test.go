@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// Конкурентное резервирование одного горячего товара (flash sale):
+//
+//	go test -bench=ReserveStock -cpu=1,4,16 ./internal/repository/memory/
+func BenchmarkReserveStock_HotProduct(b *testing.B) {
+	const productID = "hot-product"
+	initial := map[string]int32{productID: math.MaxInt32}
+
+	b.Run("mutex", func(b *testing.B) {
+		benchmarkReserveStock(b, NewMemoryRepository(initial), productID)
+	})
+	for _, shards := range []int{4, 16, 64} {
+		b.Run(fmt.Sprintf("sharded-%d", shards), func(b *testing.B) {
+			benchmarkReserveStock(b, NewShardedRepository(initial, shards), productID)
+		})
+	}
+}
+
+// Конкурентное резервирование разных товаров: здесь шардирование не должно давать выигрыша
+func BenchmarkReserveStock_ManyProducts(b *testing.B) {
+	productIDs := make([]string, 1024)
+	initial := make(map[string]int32, len(productIDs))
+	for i := range productIDs {
+		productIDs[i] = fmt.Sprintf("product-%d", i)
+		initial[productIDs[i]] = math.MaxInt32
+	}
+
+	b.Run("mutex", func(b *testing.B) {
+		benchmarkReserveStockSpread(b, NewMemoryRepository(initial), productIDs)
+	})
+	b.Run("sharded-16", func(b *testing.B) {
+		benchmarkReserveStockSpread(b, NewShardedRepository(initial, 16), productIDs)
+	})
+}
+
+func benchmarkReserveStock(b *testing.B, repo repository.InventoryRepository, productID string) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := repo.ReserveStock(ctx, productID, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchmarkReserveStockSpread(b *testing.B, repo repository.InventoryRepository, productIDs []string) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, _, err := repo.ReserveStock(ctx, productIDs[i%len(productIDs)], 1); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
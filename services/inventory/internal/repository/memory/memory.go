@@ -3,6 +3,8 @@ package memory
 import (
 	"context"
 	"sync"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 )
 
 const (
@@ -38,7 +40,8 @@ func NewMemoryRepository(initialStock map[string]int32) *MemoryRepository {
 // GetStock получает количество товара из памяти
 // Если товар отсутствует, возвращает default=42 для обратной совместимости
 // Защищён мьютексом для безопасного доступа из разных горутин
-func (r *MemoryRepository) GetStock(ctx context.Context, productID string) (int32, error) {
+// consistency игнорируется: у in-memory хранилища нет реплик
+func (r *MemoryRepository) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -52,10 +55,27 @@ func (r *MemoryRepository) GetStock(ctx context.Context, productID string) (int3
 	return available, nil
 }
 
+// GetStockBatch получает остатки нескольких товаров под одной блокировкой
+// Как и GetStock, для отсутствующих товаров возвращает default=42, поэтому в результате есть все productIDs
+func (r *MemoryRepository) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) (map[string]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stock := make(map[string]int32, len(productIDs))
+	for _, productID := range productIDs {
+		available, exists := r.stock[productID]
+		if !exists {
+			available = r.defaultStock
+		}
+		stock[productID] = available
+	}
+	return stock, nil
+}
+
 // ReserveStock резервирует товар на складе
 // Проверяет доступность, уменьшает остаток при успешном резервировании
 // Защищён мьютексом для безопасного доступа из разных горутин
-func (r *MemoryRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error) {
+func (r *MemoryRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -68,7 +88,7 @@ func (r *MemoryRepository) ReserveStock(ctx context.Context, productID string, q
 	// Проверяем, хватает ли товара
 	if currentStock < quantity {
 		// Недостаточно товара - возвращаем false без изменения остатка
-		return false, nil
+		return 0, false, nil
 	}
 
 	// Достаточно товара - резервируем (уменьшаем остаток)
@@ -84,7 +104,24 @@ func (r *MemoryRepository) ReserveStock(ctx context.Context, productID string, q
 		r.stock[productID] = newStock
 	}
 
-	return true, nil
+	return newStock, true, nil
+}
+
+// AddStock увеличивает остаток товара на quantity
+// Если товара нет, пополняется default=42 (как его видят GetStock и ReserveStock)
+// Защищён мьютексом для безопасного доступа из разных горутин
+func (r *MemoryRepository) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	currentStock := r.defaultStock
+	if available, exists := r.stock[productID]; exists {
+		currentStock = available
+	}
+
+	r.stock[productID] = currentStock + quantity
+
+	return r.stock[productID], nil
 }
 
 // stockExists проверяет, существует ли товар в хранилище
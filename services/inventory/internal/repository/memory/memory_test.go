@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+func TestMemoryRepository_ReserveStock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("insufficient stock leaves stock unchanged", func(t *testing.T) {
+		repo := NewMemoryRepository(map[string]int32{"product-1": 3})
+
+		_, ok, err := repo.ReserveStock(ctx, "product-1", 4)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		stock, err := repo.GetStock(ctx, "product-1", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(3), stock)
+	})
+
+	t.Run("concurrent reservations never oversell", func(t *testing.T) {
+		const stock = 500
+		repo := NewMemoryRepository(map[string]int32{"hot": stock})
+
+		const workers = 32
+		var reserved atomic.Int32
+		var wg sync.WaitGroup
+		// require в горутине не останавливает тест: ошибки проверяются после Wait
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 40; j++ {
+					_, ok, err := repo.ReserveStock(ctx, "hot", 1)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if ok {
+						reserved.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		left, err := repo.GetStock(ctx, "hot", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(stock), reserved.Load())
+		require.Equal(t, int32(0), left)
+	})
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// stockShard - часть остатка товара со своим мьютексом
+// Выравнивание до 64 байт, чтобы соседние шарды не делили cache line (false sharing)
+type stockShard struct {
+	mu    sync.Mutex
+	stock int32
+	_     [56]byte
+}
+
+// shardedStock - остаток одного товара, разложенный по шардам
+type shardedStock struct {
+	shards []stockShard
+	next   atomic.Uint32 // round-robin выбор стартового шарда
+}
+
+// ShardedRepository реализует InventoryRepository, раскладывая остаток каждого товара по нескольким шардам
+// Предназначен для очень горячих товаров (flash sale): одновременные резервирования обычно блокируют разные шарды,
+// а не один общий мьютекс, как в MemoryRepository. Поведение (default=42 для неизвестных товаров) совпадает с MemoryRepository.
+type ShardedRepository struct {
+	mu           sync.RWMutex
+	products     map[string]*shardedStock
+	shardCount   int
+	defaultStock int32
+}
+
+// NewShardedRepository создаёт in-memory репозиторий с shardCount шардами на товар
+// shardCount < 1 трактуется как 1 (эквивалент одного мьютекса на товар)
+func NewShardedRepository(initialStock map[string]int32, shardCount int) *ShardedRepository {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	r := &ShardedRepository{
+		products:     make(map[string]*shardedStock, len(initialStock)),
+		shardCount:   shardCount,
+		defaultStock: DefaultStock,
+	}
+	for productID, stock := range initialStock {
+		r.products[productID] = r.newShardedStock(stock)
+	}
+	return r
+}
+
+// GetStock возвращает суммарный остаток по всем шардам (consistency игнорируется)
+func (r *ShardedRepository) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
+	s := r.product(productID)
+
+	s.lockAll()
+	defer s.unlockAll()
+
+	return s.total(), nil
+}
+
+// GetStockBatch возвращает суммарные остатки товаров; каждый товар читается под блокировкой своих шардов
+func (r *ShardedRepository) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) (map[string]int32, error) {
+	stock := make(map[string]int32, len(productIDs))
+	for _, productID := range productIDs {
+		available, err := r.GetStock(ctx, productID, consistency)
+		if err != nil {
+			return nil, err
+		}
+		stock[productID] = available
+	}
+	return stock, nil
+}
+
+// ReserveStock резервирует товар
+// Быстрый путь: списать quantity целиком из одного шарда (блокируется только он).
+// Медленный путь (остаток размазан по шардам): блокируются все шарды по порядку и списание идёт из нескольких.
+// На быстром пути возвращаемый остаток приблизительный: шарды читаются по очереди, а не под общей блокировкой
+func (r *ShardedRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error) {
+	s := r.product(productID)
+	n := len(s.shards)
+	start := int(s.next.Add(1) % uint32(n))
+
+	for i := 0; i < n; i++ {
+		shard := &s.shards[(start+i)%n]
+		shard.mu.Lock()
+		if shard.stock >= quantity {
+			shard.stock -= quantity
+			shard.mu.Unlock()
+			return s.approxTotal(), true, nil
+		}
+		shard.mu.Unlock()
+	}
+
+	s.lockAll()
+	defer s.unlockAll()
+
+	if s.total() < quantity {
+		return 0, false, nil
+	}
+
+	remaining := quantity
+	for i := range s.shards {
+		take := min(s.shards[i].stock, remaining)
+		s.shards[i].stock -= take
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+
+	return s.total(), true, nil
+}
+
+// AddStock добавляет quantity в один шард (round-robin, как стартовый шард в ReserveStock)
+// Возвращает суммарный остаток после пополнения
+func (r *ShardedRepository) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	s := r.product(productID)
+	n := len(s.shards)
+
+	shard := &s.shards[int(s.next.Add(1)%uint32(n))]
+	shard.mu.Lock()
+	shard.stock += quantity
+	shard.mu.Unlock()
+
+	s.lockAll()
+	defer s.unlockAll()
+
+	return s.total(), nil
+}
+
+// product возвращает остаток товара, создавая его с defaultStock при первом обращении
+func (r *ShardedRepository) product(productID string) *shardedStock {
+	r.mu.RLock()
+	s, exists := r.products[productID]
+	r.mu.RUnlock()
+	if exists {
+		return s
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, exists := r.products[productID]; exists {
+		return s
+	}
+	s = r.newShardedStock(r.defaultStock)
+	r.products[productID] = s
+	return s
+}
+
+// newShardedStock раскладывает остаток поровну по шардам (остаток от деления - в первые шарды)
+func (r *ShardedRepository) newShardedStock(stock int32) *shardedStock {
+	s := &shardedStock{shards: make([]stockShard, r.shardCount)}
+	base := stock / int32(r.shardCount)
+	extra := stock % int32(r.shardCount)
+	for i := range s.shards {
+		s.shards[i].stock = base
+		if int32(i) < extra {
+			s.shards[i].stock++
+		}
+	}
+	return s
+}
+
+// lockAll блокирует все шарды в фиксированном порядке (исключает deadlock между медленными путями)
+func (s *shardedStock) lockAll() {
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+	}
+}
+
+func (s *shardedStock) unlockAll() {
+	for i := range s.shards {
+		s.shards[i].mu.Unlock()
+	}
+}
+
+// approxTotal - суммарный остаток без общей блокировки: каждый шард читается под своим мьютексом,
+// поэтому конкурентные резервирования между чтениями шардов могут не попасть в сумму
+func (s *shardedStock) approxTotal() int32 {
+	var total int32
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		total += s.shards[i].stock
+		s.shards[i].mu.Unlock()
+	}
+	return total
+}
+
+// total - суммарный остаток; вызывается только под lockAll
+func (s *shardedStock) total() int32 {
+	var total int32
+	for i := range s.shards {
+		total += s.shards[i].stock
+	}
+	return total
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+func TestShardedRepository_ReserveStock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("splits stock across shards", func(t *testing.T) {
+		repo := NewShardedRepository(map[string]int32{"product-1": 10}, 4)
+
+		stock, err := repo.GetStock(ctx, "product-1", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(10), stock)
+	})
+
+	t.Run("reserves across shards when no single shard has enough", func(t *testing.T) {
+		repo := NewShardedRepository(map[string]int32{"product-1": 8}, 4)
+
+		available, ok, err := repo.ReserveStock(ctx, "product-1", 7)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int32(1), available)
+
+		stock, err := repo.GetStock(ctx, "product-1", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), stock)
+
+		_, ok, err = repo.ReserveStock(ctx, "product-1", 2)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("unknown product uses default stock", func(t *testing.T) {
+		repo := NewShardedRepository(nil, 8)
+
+		stock, err := repo.GetStock(ctx, "unknown", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, DefaultStock, stock)
+	})
+
+	t.Run("added stock is reservable", func(t *testing.T) {
+		repo := NewShardedRepository(map[string]int32{"product-1": 0}, 4)
+
+		available, err := repo.AddStock(ctx, "product-1", 5)
+		require.NoError(t, err)
+		require.Equal(t, int32(5), available)
+
+		_, ok, err := repo.ReserveStock(ctx, "product-1", 5)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("concurrent reservations never oversell", func(t *testing.T) {
+		const stock = 1000
+		repo := NewShardedRepository(map[string]int32{"hot": stock}, 8)
+
+		const workers = 64
+		var reserved atomic.Int32
+		var wg sync.WaitGroup
+		// require в горутине не останавливает тест: ошибки проверяются после Wait
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					_, ok, err := repo.ReserveStock(ctx, "hot", 1)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if ok {
+						reserved.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		left, err := repo.GetStock(ctx, "hot", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(stock), reserved.Load())
+		require.Equal(t, int32(0), left)
+	})
+}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	repository "github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,9 +14,37 @@ type InventoryRepository struct {
 	mock.Mock
 }
 
-// GetStock provides a mock function with given fields: ctx, productID
-func (_m *InventoryRepository) GetStock(ctx context.Context, productID string) (int32, error) {
-	ret := _m.Called(ctx, productID)
+// AddStock provides a mock function with given fields: ctx, productID, quantity
+func (_m *InventoryRepository) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	ret := _m.Called(ctx, productID, quantity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddStock")
+	}
+
+	var r0 int32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int32) (int32, error)); ok {
+		return rf(ctx, productID, quantity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int32) int32); ok {
+		r0 = rf(ctx, productID, quantity)
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int32) error); ok {
+		r1 = rf(ctx, productID, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStock provides a mock function with given fields: ctx, productID, consistency
+func (_m *InventoryRepository) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
+	ret := _m.Called(ctx, productID, consistency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetStock")
@@ -23,17 +52,47 @@ func (_m *InventoryRepository) GetStock(ctx context.Context, productID string) (
 
 	var r0 int32
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (int32, error)); ok {
-		return rf(ctx, productID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.ReadConsistency) (int32, error)); ok {
+		return rf(ctx, productID, consistency)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) int32); ok {
-		r0 = rf(ctx, productID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.ReadConsistency) int32); ok {
+		r0 = rf(ctx, productID, consistency)
 	} else {
 		r0 = ret.Get(0).(int32)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, productID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, repository.ReadConsistency) error); ok {
+		r1 = rf(ctx, productID, consistency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStockBatch provides a mock function with given fields: ctx, productIDs, consistency
+func (_m *InventoryRepository) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) (map[string]int32, error) {
+	ret := _m.Called(ctx, productIDs, consistency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStockBatch")
+	}
+
+	var r0 map[string]int32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, repository.ReadConsistency) (map[string]int32, error)); ok {
+		return rf(ctx, productIDs, consistency)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, repository.ReadConsistency) map[string]int32); ok {
+		r0 = rf(ctx, productIDs, consistency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int32)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, repository.ReadConsistency) error); ok {
+		r1 = rf(ctx, productIDs, consistency)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,31 +101,38 @@ func (_m *InventoryRepository) GetStock(ctx context.Context, productID string) (
 }
 
 // ReserveStock provides a mock function with given fields: ctx, productID, quantity
-func (_m *InventoryRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error) {
+func (_m *InventoryRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error) {
 	ret := _m.Called(ctx, productID, quantity)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReserveStock")
 	}
 
-	var r0 bool
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int32) (bool, error)); ok {
+	var r0 int32
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int32) (int32, bool, error)); ok {
 		return rf(ctx, productID, quantity)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, int32) bool); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, int32) int32); ok {
 		r0 = rf(ctx, productID, quantity)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(int32)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, int32) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, int32) bool); ok {
 		r1 = rf(ctx, productID, quantity)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(bool)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string, int32) error); ok {
+		r2 = rf(ctx, productID, quantity)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // NewInventoryRepository creates a new instance of InventoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
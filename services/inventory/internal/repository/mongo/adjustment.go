@@ -0,0 +1,176 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// AdjustmentDocument представляет документ пакета корректировок в коллекции MongoDB
+type AdjustmentDocument struct {
+	AdjustmentID string                   `bson:"adjustment_id"`
+	Reason       string                   `bson:"reason"`
+	Items        []AdjustmentItemDocument `bson:"items"`
+	Status       string                   `bson:"status"`
+	CreatedBy    string                   `bson:"created_by"`
+	CreatedAt    time.Time                `bson:"created_at"`
+	ReviewedBy   string                   `bson:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time               `bson:"reviewed_at,omitempty"`
+	AppliedAt    *time.Time               `bson:"applied_at,omitempty"`
+	Comment      string                   `bson:"comment,omitempty"`
+}
+
+// AdjustmentItemDocument - позиция пакета корректировок
+type AdjustmentItemDocument struct {
+	ProductID   string `bson:"product_id"`
+	WarehouseID string `bson:"warehouse_id"`
+	Delta       int32  `bson:"delta"`
+}
+
+// AdjustmentRepository реализует repository.AdjustmentRepository используя MongoDB
+type AdjustmentRepository struct {
+	col *mongo.Collection
+}
+
+// NewAdjustmentRepository создаёт репозиторий пакетов корректировок
+// Создаёт уникальный индекс на adjustment_id и индекс (status, created_at) для очереди на одобрение
+func NewAdjustmentRepository(client *mongo.Client, dbName string) *AdjustmentRepository {
+	col := client.Database(dbName).Collection("stock_adjustments")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "adjustment_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Создаём индексы (если уже существуют - игнорируем ошибку)
+	_, _ = col.Indexes().CreateMany(ctx, indexModels)
+
+	return &AdjustmentRepository{col: col}
+}
+
+// CreateAdjustment сохраняет новый пакет
+func (r *AdjustmentRepository) CreateAdjustment(ctx context.Context, adjustment repository.Adjustment) error {
+	doc := AdjustmentDocument{
+		AdjustmentID: adjustment.ID,
+		Reason:       adjustment.Reason,
+		Status:       adjustment.Status,
+		CreatedBy:    adjustment.CreatedBy,
+		CreatedAt:    adjustment.CreatedAt,
+	}
+	for _, item := range adjustment.Items {
+		doc.Items = append(doc.Items, AdjustmentItemDocument{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Delta: item.Delta})
+	}
+
+	_, err := r.col.InsertOne(ctx, doc)
+	return err
+}
+
+// GetAdjustment возвращает пакет по adjustment_id
+func (r *AdjustmentRepository) GetAdjustment(ctx context.Context, adjustmentID string) (repository.Adjustment, error) {
+	var doc AdjustmentDocument
+	err := r.col.FindOne(ctx, bson.M{"adjustment_id": adjustmentID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Adjustment{}, repository.ErrAdjustmentNotFound
+		}
+		return repository.Adjustment{}, err
+	}
+	return doc.toAdjustment(), nil
+}
+
+// ListAdjustments возвращает до limit пакетов, отсортированных по created_at от новых к старым
+func (r *AdjustmentRepository) ListAdjustments(ctx context.Context, status string, limit int) ([]repository.Adjustment, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []AdjustmentDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	adjustments := make([]repository.Adjustment, 0, len(docs))
+	for _, doc := range docs {
+		adjustments = append(adjustments, doc.toAdjustment())
+	}
+	return adjustments, nil
+}
+
+// UpdateAdjustmentStatus меняет статус одним FindOneAndUpdate с условием status = from
+// Если документ не подошёл под условие, отдельным чтением различаем "нет пакета" и "пакет в другом статусе"
+func (r *AdjustmentRepository) UpdateAdjustmentStatus(ctx context.Context, adjustmentID, from string, update repository.AdjustmentStatusUpdate) (repository.Adjustment, error) {
+	set := bson.M{"status": update.Status}
+	if update.ReviewedBy != "" {
+		set["reviewed_by"] = update.ReviewedBy
+	}
+	if !update.ReviewedAt.IsZero() {
+		set["reviewed_at"] = update.ReviewedAt
+	}
+	if !update.AppliedAt.IsZero() {
+		set["applied_at"] = update.AppliedAt
+	}
+	if update.Comment != "" {
+		set["comment"] = update.Comment
+	}
+
+	var doc AdjustmentDocument
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"adjustment_id": adjustmentID, "status": from}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err == nil {
+		return doc.toAdjustment(), nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return repository.Adjustment{}, err
+	}
+
+	current, err := r.GetAdjustment(ctx, adjustmentID)
+	if err != nil {
+		return repository.Adjustment{}, err
+	}
+	return current, repository.ErrAdjustmentStatusConflict
+}
+
+func (d AdjustmentDocument) toAdjustment() repository.Adjustment {
+	adjustment := repository.Adjustment{
+		ID:         d.AdjustmentID,
+		Reason:     d.Reason,
+		Status:     d.Status,
+		CreatedBy:  d.CreatedBy,
+		CreatedAt:  d.CreatedAt,
+		ReviewedBy: d.ReviewedBy,
+		Comment:    d.Comment,
+	}
+	for _, item := range d.Items {
+		adjustment.Items = append(adjustment.Items, repository.AdjustmentItem{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Delta: item.Delta})
+	}
+	if d.ReviewedAt != nil {
+		adjustment.ReviewedAt = *d.ReviewedAt
+	}
+	if d.AppliedAt != nil {
+		adjustment.AppliedAt = *d.AppliedAt
+	}
+	return adjustment
+}
@@ -0,0 +1,162 @@
+package mongo
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// stockAnalyticsDocument - строка результата агрегации GetStockAnalytics
+type stockAnalyticsDocument struct {
+	ProductID   string `bson:"product_id"`
+	Stock       int32  `bson:"stock"`
+	Reserved    int32  `bson:"reserved"`
+	Backordered int32  `bson:"backordered"`
+	Warehouses  []struct {
+		WarehouseID string `bson:"k"`
+		Quantity    int32  `bson:"v"`
+	} `bson:"warehouses"`
+	Reservations []struct {
+		WarehouseID string `bson:"_id"`
+		Reserved    int32  `bson:"reserved"`
+		Backordered int32  `bson:"backordered"`
+	} `bson:"reservations"`
+}
+
+// StockAnalyticsRepository реализует repository.StockAnalyticsRepository используя MongoDB
+type StockAnalyticsRepository struct {
+	inventory *mongo.Collection
+}
+
+// NewStockAnalyticsRepository создаёт репозиторий аналитики остатков
+// Отчёт читается с secondary, если он доступен: отставание реплики для аналитики допустимо, а primary не нагружается
+// Индексы не создаёт: агрегация идёт по уникальному индексу inventory.product_id,
+// а резервы товара ищутся по индексу reservations (product_id, created_at)
+func NewStockAnalyticsRepository(client *mongo.Client, dbName string) *StockAnalyticsRepository {
+	return &StockAnalyticsRepository{
+		inventory: client.Database(dbName).Collection("inventory",
+			options.Collection().SetReadPreference(readpref.SecondaryPreferred())),
+	}
+}
+
+// GetStockAnalytics считает сводку одной агрегацией по коллекции inventory
+// Остатки по складам берутся из документа товара, активные резервы присоединяются $lookup из reservations
+// и группируются по складу списания; итоги товара - сумма по складам. В Go только сводятся два списка складов
+func (r *StockAnalyticsRepository) GetStockAnalytics(ctx context.Context, filter repository.StockAnalyticsFilter) ([]repository.StockSummary, error) {
+	match := bson.M{}
+	productID := bson.M{}
+	if len(filter.ProductIDs) > 0 {
+		productID["$in"] = filter.ProductIDs
+	}
+	if filter.AfterProductID != "" {
+		productID["$gt"] = filter.AfterProductID
+	}
+	if len(productID) > 0 {
+		match["product_id"] = productID
+	}
+	if filter.WarehouseID != "" {
+		match[warehouseField(filter.WarehouseID)] = bson.M{"$exists": true}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "product_id", Value: 1}}}},
+		{{Key: "$limit", Value: int64(filter.Limit)}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "reservations",
+			"let":  bson.M{"product_id": "$product_id"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"status": repository.ReservationStatusActive,
+					"$expr":  bson.M{"$eq": bson.A{"$product_id", "$$product_id"}},
+				}}},
+				// Резерв без распределения списан со склада по умолчанию
+				{{Key: "$project", Value: bson.M{
+					"backordered": bson.M{"$ifNull": bson.A{"$backordered", 0}},
+					"allocations": bson.M{"$ifNull": bson.A{"$allocations", bson.A{
+						bson.M{"warehouse_id": repository.DefaultWarehouseID, "quantity": "$quantity"},
+					}}},
+				}}},
+				{{Key: "$unwind", Value: "$allocations"}},
+				// Сверх остатка списывается только склад по умолчанию: backordered резерва относится к нему
+				{{Key: "$group", Value: bson.M{
+					"_id":      "$allocations.warehouse_id",
+					"reserved": bson.M{"$sum": "$allocations.quantity"},
+					"backordered": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{"$allocations.warehouse_id", repository.DefaultWarehouseID}},
+						"$backordered",
+						0,
+					}}},
+				}}},
+			},
+			"as": "reservations",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":          0,
+			"product_id":   1,
+			"stock":        1,
+			"warehouses":   bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$warehouses", bson.M{}}}},
+			"reservations": 1,
+			"reserved":     bson.M{"$sum": "$reservations.reserved"},
+			"backordered":  bson.M{"$sum": "$reservations.backordered"},
+		}}},
+	}
+
+	cursor, err := r.inventory.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []stockAnalyticsDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]repository.StockSummary, 0, len(docs))
+	for _, doc := range docs {
+		summaries = append(summaries, doc.toStockSummary(filter.WarehouseID))
+	}
+	return summaries, nil
+}
+
+// toStockSummary сводит остатки и резервы по складам; warehouseID оставляет строку только одного склада
+func (d stockAnalyticsDocument) toStockSummary(warehouseID string) repository.StockSummary {
+	byWarehouse := make(map[string]*repository.WarehouseStockSummary)
+	line := func(id string) *repository.WarehouseStockSummary {
+		if byWarehouse[id] == nil {
+			byWarehouse[id] = &repository.WarehouseStockSummary{WarehouseID: id}
+		}
+		return byWarehouse[id]
+	}
+	for _, w := range d.Warehouses {
+		line(w.WarehouseID).Available = w.Quantity
+	}
+	for _, res := range d.Reservations {
+		l := line(res.WarehouseID)
+		l.Reserved = res.Reserved
+		l.Backordered = res.Backordered
+	}
+
+	summary := repository.StockSummary{
+		ProductID:   d.ProductID,
+		Available:   d.Stock,
+		Reserved:    d.Reserved,
+		Backordered: d.Backordered,
+	}
+	for id, l := range byWarehouse {
+		if warehouseID == "" || id == warehouseID {
+			summary.Warehouses = append(summary.Warehouses, *l)
+		}
+	}
+	sort.Slice(summary.Warehouses, func(i, j int) bool {
+		return summary.Warehouses[i].WarehouseID < summary.Warehouses[j].WarehouseID
+	})
+	return summary
+}
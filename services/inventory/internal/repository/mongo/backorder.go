@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// BackorderDocument представляет запись журнала предзаказов в коллекции MongoDB
+type BackorderDocument struct {
+	BackorderID   string    `bson:"backorder_id"`
+	ProductID     string    `bson:"product_id"`
+	OrderID       string    `bson:"order_id,omitempty"`
+	ReservationID string    `bson:"reservation_id,omitempty"`
+	Quantity      int32     `bson:"quantity"`
+	Backordered   int32     `bson:"backordered"`
+	Available     int32     `bson:"available"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// BackorderRepository реализует repository.BackorderRepository используя MongoDB
+type BackorderRepository struct {
+	col *mongo.Collection
+}
+
+// NewBackorderRepository создаёт репозиторий журнала предзаказов
+// Создаёт индексы (product_id, created_at) для истории товара и created_at для общего журнала
+func NewBackorderRepository(client *mongo.Client, dbName string) *BackorderRepository {
+	col := client.Database(dbName).Collection("backorders")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Создаём индексы (если уже существуют - игнорируем ошибку)
+	_, _ = col.Indexes().CreateMany(ctx, indexModels)
+
+	return &BackorderRepository{col: col}
+}
+
+// CreateBackorder сохраняет запись журнала
+func (r *BackorderRepository) CreateBackorder(ctx context.Context, backorder repository.Backorder) error {
+	_, err := r.col.InsertOne(ctx, BackorderDocument{
+		BackorderID:   backorder.ID,
+		ProductID:     backorder.ProductID,
+		OrderID:       backorder.OrderID,
+		ReservationID: backorder.ReservationID,
+		Quantity:      backorder.Quantity,
+		Backordered:   backorder.Backordered,
+		Available:     backorder.Available,
+		CreatedAt:     backorder.CreatedAt,
+	})
+	return err
+}
+
+// ListBackorders возвращает до limit записей журнала, отсортированных по created_at от новых к старым
+func (r *BackorderRepository) ListBackorders(ctx context.Context, productID string, limit int) ([]repository.Backorder, error) {
+	query := bson.M{}
+	if productID != "" {
+		query["product_id"] = productID
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []BackorderDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	backorders := make([]repository.Backorder, 0, len(docs))
+	for _, d := range docs {
+		backorders = append(backorders, repository.Backorder{
+			ID:            d.BackorderID,
+			ProductID:     d.ProductID,
+			OrderID:       d.OrderID,
+			ReservationID: d.ReservationID,
+			Quantity:      d.Quantity,
+			Backordered:   d.Backordered,
+			Available:     d.Available,
+			CreatedAt:     d.CreatedAt,
+		})
+	}
+	return backorders, nil
+}
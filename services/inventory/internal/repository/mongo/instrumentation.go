@@ -0,0 +1,111 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName - имя tracer'а и meter'а для команд MongoDB
+const instrumentationName = "inventory/mongo"
+
+// Служебные команды драйвера (handshake, аутентификация, мониторинг) не трассируются
+var skippedCommands = map[string]struct{}{
+	"hello":        {},
+	"isMaster":     {},
+	"ismaster":     {},
+	"saslStart":    {},
+	"saslContinue": {},
+	"endSessions":  {},
+}
+
+// NewCommandMonitor возвращает монитор команд для options.Client().SetMonitor: span на каждую команду
+// (дочерний к span'у RPC из контекста запроса), гистограмму длительности команд и счётчик ошибок
+// Так трассируются все repository пакета, включая транзакции: каждая команда внутри - отдельный span
+func NewCommandMonitor() *event.CommandMonitor {
+	m := newCommandInstrumentation()
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+// commandInstrumentation связывает события начала и конца команды по connection_id и request_id
+type commandInstrumentation struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	spans    sync.Map // commandKey -> trace.Span
+}
+
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+func newCommandInstrumentation() *commandInstrumentation {
+	meter := otel.Meter(instrumentationName)
+	duration, _ := meter.Float64Histogram("inventory_mongo_command_duration_ms", metric.WithDescription("MongoDB command duration in milliseconds"))
+	errs, _ := meter.Int64Counter("inventory_mongo_command_errors_total", metric.WithDescription("MongoDB commands failed with an error"))
+	return &commandInstrumentation{
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errs,
+	}
+}
+
+func (m *commandInstrumentation) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if _, skip := skippedCommands[evt.CommandName]; skip {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.name", evt.DatabaseName),
+		attribute.String("db.operation", evt.CommandName),
+	}
+	// Имя коллекции - значение поля с именем команды: {"find": "stocks", ...}
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		attrs = append(attrs, attribute.String("db.mongodb.collection", collection))
+	}
+	_, span := m.tracer.Start(ctx, "mongodb "+evt.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	m.spans.Store(commandKey{evt.ConnectionID, evt.RequestID}, span)
+}
+
+func (m *commandInstrumentation) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	m.finish(ctx, evt.CommandFinishedEvent, nil)
+}
+
+func (m *commandInstrumentation) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	m.finish(ctx, evt.CommandFinishedEvent, errors.New(evt.Failure))
+}
+
+// finish закрывает span команды и пишет длительность; при ошибке - счётчик ошибок и статус span'а
+func (m *commandInstrumentation) finish(ctx context.Context, evt event.CommandFinishedEvent, err error) {
+	value, ok := m.spans.LoadAndDelete(commandKey{evt.ConnectionID, evt.RequestID})
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		m.errors.Add(ctx, 1, metric.WithAttributes(attribute.String("command", evt.CommandName)))
+	}
+	m.duration.Record(ctx, float64(evt.Duration.Microseconds())/1000,
+		metric.WithAttributes(attribute.String("command", evt.CommandName), attribute.String("status", status)))
+}
@@ -0,0 +1,139 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// MovementDocument представляет запись журнала движений остатка в коллекции MongoDB
+type MovementDocument struct {
+	MovementID    string    `bson:"movement_id"`
+	ProductID     string    `bson:"product_id"`
+	WarehouseID   string    `bson:"warehouse_id"`
+	Delta         int32     `bson:"delta"`
+	Available     int32     `bson:"available"`
+	Reason        string    `bson:"reason"`
+	AdjustmentID  string    `bson:"adjustment_id,omitempty"`
+	ReservationID string    `bson:"reservation_id,omitempty"`
+	OrderID       string    `bson:"order_id,omitempty"`
+	Actor         string    `bson:"actor,omitempty"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// MovementRepository реализует repository.MovementRepository используя MongoDB
+type MovementRepository struct {
+	col *mongo.Collection
+}
+
+// NewMovementRepository создаёт репозиторий журнала движений
+// Создаёт индексы (product_id, created_at) для истории товара, adjustment_id для движений пакета
+// и created_at для выборок за период по всем товарам (сверка склада)
+func NewMovementRepository(client *mongo.Client, dbName string) *MovementRepository {
+	col := client.Database(dbName).Collection("stock_movements")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "adjustment_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Создаём индексы (если уже существуют - игнорируем ошибку)
+	_, _ = col.Indexes().CreateMany(ctx, indexModels)
+
+	return &MovementRepository{col: col}
+}
+
+// CreateMovements сохраняет записи журнала одним InsertMany
+func (r *MovementRepository) CreateMovements(ctx context.Context, movements []repository.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(movements))
+	for _, m := range movements {
+		docs = append(docs, MovementDocument{
+			MovementID:    m.ID,
+			ProductID:     m.ProductID,
+			WarehouseID:   m.WarehouseID,
+			Delta:         m.Delta,
+			Available:     m.Available,
+			Reason:        m.Reason,
+			AdjustmentID:  m.AdjustmentID,
+			ReservationID: m.ReservationID,
+			OrderID:       m.OrderID,
+			Actor:         m.Actor,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	_, err := r.col.InsertMany(ctx, docs)
+	return err
+}
+
+// ListMovements возвращает до limit движений под filter, отсортированных по created_at от новых к старым
+func (r *MovementRepository) ListMovements(ctx context.Context, filter repository.MovementFilter, limit int) ([]repository.StockMovement, error) {
+	query := bson.M{}
+	if filter.ProductID != "" {
+		query["product_id"] = filter.ProductID
+	}
+	if filter.AdjustmentID != "" {
+		query["adjustment_id"] = filter.AdjustmentID
+	}
+	if filter.Reason != "" {
+		query["reason"] = filter.Reason
+	}
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []MovementDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	movements := make([]repository.StockMovement, 0, len(docs))
+	for _, d := range docs {
+		movements = append(movements, repository.StockMovement{
+			ID:            d.MovementID,
+			ProductID:     d.ProductID,
+			WarehouseID:   d.WarehouseID,
+			Delta:         d.Delta,
+			Available:     d.Available,
+			Reason:        d.Reason,
+			AdjustmentID:  d.AdjustmentID,
+			ReservationID: d.ReservationID,
+			OrderID:       d.OrderID,
+			Actor:         d.Actor,
+			CreatedAt:     d.CreatedAt,
+		})
+	}
+	return movements, nil
+}
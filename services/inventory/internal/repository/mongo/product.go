@@ -0,0 +1,269 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// ProductDocument представляет документ товара в коллекции MongoDB
+type ProductDocument struct {
+	ProductID         string            `bson:"product_id"`
+	SKU               string            `bson:"sku"`
+	Name              string            `bson:"name"`
+	Price             int64             `bson:"price"`
+	Currency          string            `bson:"currency"`
+	Attributes        map[string]string `bson:"attributes,omitempty"`
+	LowStockThreshold int32             `bson:"low_stock_threshold,omitempty"`
+	BackorderLimit    int32             `bson:"backorder_limit,omitempty"`
+	Category          string            `bson:"category,omitempty"`
+	CreatedAt         time.Time         `bson:"created_at"`
+	UpdatedAt         time.Time         `bson:"updated_at"`
+	DeletedAt         *time.Time        `bson:"deleted_at,omitempty"`
+}
+
+// ProductRepository реализует repository.ProductRepository используя MongoDB
+type ProductRepository struct {
+	col *mongo.Collection
+}
+
+// NewProductRepository создаёт репозиторий каталога товаров
+// Создаёт уникальные индексы на product_id и sku
+func NewProductRepository(client *mongo.Client, dbName string) *ProductRepository {
+	col := client.Database(dbName).Collection("products")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "sku", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Создаём индексы (если уже существуют - игнорируем ошибку)
+	_, _ = col.Indexes().CreateMany(ctx, indexModels)
+
+	return &ProductRepository{col: col}
+}
+
+// CreateProduct сохраняет новый товар
+// Дубликат product_id или sku отсекает уникальный индекс (ошибка duplicate key → ErrProductAlreadyExists)
+func (r *ProductRepository) CreateProduct(ctx context.Context, product repository.Product) error {
+	_, err := r.col.InsertOne(ctx, productToDocument(product))
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %w", repository.ErrProductAlreadyExists, err)
+	}
+	return err
+}
+
+// GetProduct возвращает товар по product_id
+func (r *ProductRepository) GetProduct(ctx context.Context, productID string) (repository.Product, error) {
+	var doc ProductDocument
+	err := r.col.FindOne(ctx, bson.M{"product_id": productID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Product{}, repository.ErrNotFound
+		}
+		return repository.Product{}, err
+	}
+	return doc.toProduct(), nil
+}
+
+// UpdateProduct заменяет изменяемые поля товара одним FindOneAndUpdate и возвращает документ после обновления
+// created_at не меняется
+func (r *ProductRepository) UpdateProduct(ctx context.Context, product repository.Product) (repository.Product, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"sku":                 product.SKU,
+			"name":                product.Name,
+			"price":               product.Price,
+			"currency":            product.Currency,
+			"attributes":          product.Attributes,
+			"low_stock_threshold": product.LowStockThreshold,
+			"backorder_limit":     product.BackorderLimit,
+			"category":            product.Category,
+			"updated_at":          product.UpdatedAt,
+		},
+	}
+
+	var doc ProductDocument
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"product_id": product.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Product{}, repository.ErrNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return repository.Product{}, fmt.Errorf("%w: %w", repository.ErrProductAlreadyExists, err)
+		}
+		return repository.Product{}, err
+	}
+	return doc.toProduct(), nil
+}
+
+// DeleteProduct выставляет deleted_at, если товар ещё не удалён; документ остаётся в коллекции
+// Ничего не изменилось - товара нет (ErrNotFound) или он уже удалён (не ошибка, deleted_at не перезаписывается)
+func (r *ProductRepository) DeleteProduct(ctx context.Context, productID string, deletedAt time.Time) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"product_id": productID, "deleted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"deleted_at": deletedAt, "updated_at": deletedAt}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		_, err := r.GetProduct(ctx, productID)
+		return err
+	}
+	return nil
+}
+
+// UndeleteProduct снимает deleted_at одним FindOneAndUpdate и возвращает документ после обновления
+// Товар не был удалён - возвращается текущий документ без изменений
+func (r *ProductRepository) UndeleteProduct(ctx context.Context, productID string, updatedAt time.Time) (repository.Product, error) {
+	var doc ProductDocument
+	err := r.col.FindOneAndUpdate(ctx,
+		bson.M{"product_id": productID, "deleted_at": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"deleted_at": ""}, "$set": bson.M{"updated_at": updatedAt}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return r.GetProduct(ctx, productID)
+	}
+	if err != nil {
+		return repository.Product{}, err
+	}
+	return doc.toProduct(), nil
+}
+
+// DeletedProductIDs находит удалённые товары среди productIDs одним запросом по индексу product_id
+func (r *ProductRepository) DeletedProductIDs(ctx context.Context, productIDs []string) (map[string]bool, error) {
+	cursor, err := r.col.Find(ctx,
+		bson.M{"product_id": bson.M{"$in": productIDs}, "deleted_at": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"product_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ProductID string `bson:"product_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	deleted := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		deleted[doc.ProductID] = true
+	}
+	return deleted, nil
+}
+
+// ListProducts возвращает страницу каталога, отсортированную по product_id
+// Курсор (product_id > AfterID) и сортировка идут по уникальному индексу product_id, префикс SKU - по индексу sku.
+// Фильтр по наличию присоединяет остаток из коллекции inventory ($lookup по её уникальному индексу product_id);
+// товар без документа остатка считается отсутствующим на складе. Удалённые товары отсекаются по deleted_at, если не IncludeDeleted
+func (r *ProductRepository) ListProducts(ctx context.Context, filter repository.ProductFilter) ([]repository.Product, error) {
+	match := bson.M{}
+	if !filter.IncludeDeleted {
+		match["deleted_at"] = bson.M{"$exists": false}
+	}
+	if filter.AfterID != "" {
+		match["product_id"] = bson.M{"$gt": filter.AfterID}
+	}
+	if filter.Query != "" {
+		quoted := regexp.QuoteMeta(filter.Query)
+		match["$or"] = bson.A{
+			bson.M{"name": bson.M{"$regex": quoted, "$options": "i"}},
+			bson.M{"sku": bson.M{"$regex": "^" + quoted}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "product_id", Value: 1}}}},
+	}
+	switch filter.Availability {
+	case repository.StockAvailabilityInStock, repository.StockAvailabilityOutOfStock:
+		inStock := bson.M{"$gt": 0}
+		stockMatch := bson.M{"stock.stock": inStock}
+		if filter.Availability == repository.StockAvailabilityOutOfStock {
+			stockMatch = bson.M{"stock.stock": bson.M{"$not": inStock}}
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{
+				"from":         "inventory",
+				"localField":   "product_id",
+				"foreignField": "product_id",
+				"as":           "stock",
+			}}},
+			bson.D{{Key: "$match", Value: stockMatch}},
+			bson.D{{Key: "$project", Value: bson.M{"stock": 0}}},
+		)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: filter.Limit}})
+
+	cursor, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []ProductDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	products := make([]repository.Product, 0, len(docs))
+	for _, doc := range docs {
+		products = append(products, doc.toProduct())
+	}
+	return products, nil
+}
+
+func productToDocument(p repository.Product) ProductDocument {
+	return ProductDocument{
+		ProductID:         p.ID,
+		SKU:               p.SKU,
+		Name:              p.Name,
+		Price:             p.Price,
+		Currency:          p.Currency,
+		Attributes:        p.Attributes,
+		LowStockThreshold: p.LowStockThreshold,
+		BackorderLimit:    p.BackorderLimit,
+		Category:          p.Category,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+		DeletedAt:         p.DeletedAt,
+	}
+}
+
+func (d ProductDocument) toProduct() repository.Product {
+	return repository.Product{
+		ID:                d.ProductID,
+		SKU:               d.SKU,
+		Name:              d.Name,
+		Price:             d.Price,
+		Currency:          d.Currency,
+		Attributes:        d.Attributes,
+		LowStockThreshold: d.LowStockThreshold,
+		BackorderLimit:    d.BackorderLimit,
+		Category:          d.Category,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+		DeletedAt:         d.DeletedAt,
+	}
+}
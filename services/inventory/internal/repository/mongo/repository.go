@@ -3,20 +3,30 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 )
 
+// writeConflictCode - код ошибки MongoDB WriteConflict (конкурентное изменение одного документа)
+const writeConflictCode = 112
+
 // InventoryDocument представляет документ в коллекции MongoDB
+// Stock - суммарный остаток, Warehouses - остаток по складам; обе величины меняются одним обновлением документа,
+// поэтому Stock всегда равен сумме Warehouses
 type InventoryDocument struct {
-	ProductID string    `bson:"product_id"`
-	Stock     int32     `bson:"stock"`
-	UpdatedAt time.Time `bson:"updated_at"`
+	ProductID  string           `bson:"product_id"`
+	Stock      int32            `bson:"stock"`
+	Warehouses map[string]int32 `bson:"warehouses,omitempty"` // warehouse_id -> остаток
+	UpdatedAt  time.Time        `bson:"updated_at"`
 }
 
 // Repository реализует InventoryRepository используя MongoDB
@@ -24,39 +34,56 @@ type Repository struct {
 	client *mongo.Client
 	db     *mongo.Database
 	col    *mongo.Collection
+
+	// Коллекции с настройками чтения для GetStock: strong - primary + majority, eventual - secondaryPreferred + local
+	strongCol          *mongo.Collection
+	eventualCol        *mongo.Collection
+	defaultConsistency repository.ReadConsistency
 }
 
 // NewRepository создаёт новый MongoDB репозиторий
-// Создаёт уникальный индекс на product_id при инициализации
-func NewRepository(client *mongo.Client, dbName string) *Repository {
+// Коллекцию и уникальный индекс на product_id создаёт EnsureSchema
+// defaultConsistency используется в GetStock, если вызывающий не указал consistency явно
+func NewRepository(client *mongo.Client, dbName string, defaultConsistency repository.ReadConsistency) *Repository {
 	db := client.Database(dbName)
 	col := db.Collection("inventory")
 
-	// Создаём уникальный индекс на product_id
-	// Это гарантирует, что каждый товар будет иметь только один документ
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "product_id", Value: 1}},
-		Options: options.Index().SetUnique(true),
-	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Создаём индекс (если уже существует - игнорируем ошибку)
-	_, _ = col.Indexes().CreateOne(ctx, indexModel)
+	// Остаток, накопленный до появления складов, переносим на склад по умолчанию (повторный запуск ничего не меняет)
+	_, _ = col.UpdateMany(ctx,
+		bson.M{"warehouses": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"warehouses": bson.M{repository.DefaultWarehouseID: "$stock"},
+		}}}},
+	)
+
+	if defaultConsistency == repository.ReadConsistencyDefault {
+		defaultConsistency = repository.ReadConsistencyStrong
+	}
 
 	return &Repository{
 		client: client,
 		db:     db,
 		col:    col,
+		strongCol: db.Collection("inventory", options.Collection().
+			SetReadPreference(readpref.Primary()).
+			SetReadConcern(readconcern.Majority())),
+		eventualCol: db.Collection("inventory", options.Collection().
+			SetReadPreference(readpref.SecondaryPreferred()).
+			SetReadConcern(readconcern.Local())),
+		defaultConsistency: defaultConsistency,
 	}
 }
 
 // GetStock получает количество товара из MongoDB
+// consistency выбирает read preference/read concern; ReadConsistencyDefault - r.defaultConsistency
 // Возвращает ErrNotFound, если товар не найден
 // Service слой обработает ErrNotFound и вернёт default=42
-func (r *Repository) GetStock(ctx context.Context, productID string) (int32, error) {
+func (r *Repository) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
 	var doc InventoryDocument
-	err := r.col.FindOne(ctx, bson.M{"product_id": productID}).Decode(&doc)
+	err := r.readCollection(consistency).FindOne(ctx, bson.M{"product_id": productID}).Decode(&doc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return 0, repository.ErrNotFound
@@ -67,20 +94,56 @@ func (r *Repository) GetStock(ctx context.Context, productID string) (int32, err
 	return doc.Stock, nil
 }
 
-// ReserveStock резервирует товар на складе атомарно
+// GetStockBatch получает остатки товаров одним Find по product_id $in
+// consistency выбирает read preference/read concern, как в GetStock
+func (r *Repository) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) (map[string]int32, error) {
+	opts := options.Find().SetProjection(bson.M{"product_id": 1, "stock": 1})
+	cursor, err := r.readCollection(consistency).Find(ctx, bson.M{"product_id": bson.M{"$in": productIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []InventoryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	stock := make(map[string]int32, len(docs))
+	for _, doc := range docs {
+		stock[doc.ProductID] = doc.Stock
+	}
+	return stock, nil
+}
+
+// ReserveStock резервирует товар со склада по умолчанию атомарно
+// Резервирование с распределением по складам - ReserveWarehouseStock
+func (r *Repository) ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error) {
+	return r.ReserveWarehouseStock(ctx, productID, []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: quantity}})
+}
+
+// ReserveWarehouseStock списывает allocations с остатков складов атомарно
 // Использует FindOneAndUpdate для атомарной проверки и обновления
-// Логика: уменьшить stock на quantity, если stock >= quantity
-// Возвращает true, если резервирование успешно, false если недостаточно товара
-func (r *Repository) ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error) {
-	// Атомарная операция: найти документ с product_id и stock >= quantity,
-	// затем уменьшить stock на quantity и обновить updated_at
-	filter := bson.M{
-		"product_id": productID,
-		"stock":      bson.M{"$gte": quantity}, // stock >= quantity
+// Логика: уменьшить остаток каждого склада и stock, если на каждом складе остаток >= списываемого
+// Проверка и списание - одна операция над документом, предварительного чтения остатка нет:
+// два одновременных резервирования последних единиц склада не могут оба пройти условие
+// Возвращает остаток из документа после обновления и true, если резервирование успешно; false, если недостаточно товара
+func (r *Repository) ReserveWarehouseStock(ctx context.Context, productID string, allocations []repository.WarehouseStock) (int32, bool, error) {
+	// Атомарная операция: найти документ с product_id и достаточным остатком на каждом складе,
+	// затем уменьшить остатки складов и stock и обновить updated_at
+	filter := bson.M{"product_id": productID}
+	inc := bson.M{}
+	var total int32
+	for _, a := range allocations {
+		field := warehouseField(a.WarehouseID)
+		filter[field] = bson.M{"$gte": a.Quantity} // остаток склада >= quantity
+		inc[field] = -a.Quantity
+		total += a.Quantity
 	}
+	inc["stock"] = -total
 
 	update := bson.M{
-		"$inc": bson.M{"stock": -quantity},       // уменьшить stock на quantity
+		"$inc": inc,                              // уменьшить остатки складов и stock
 		"$set": bson.M{"updated_at": time.Now()}, // обновить updated_at
 	}
 
@@ -95,11 +158,188 @@ func (r *Repository) ReserveStock(ctx context.Context, productID string, quantit
 			// Это означает: либо товара нет, либо недостаточно товара
 			// Возвращаем false (недостаточно товара), но не ErrNotFound
 			// Service слой обработает это как "недостаточно товара"
-			return false, nil
+			return 0, false, nil
+		}
+		if isWriteConflict(err) {
+			// Горячий товар: документ одновременно меняет другой запрос, service повторит резервирование
+			return 0, false, fmt.Errorf("%w: %w", repository.ErrWriteConflict, err)
 		}
-		return false, err
+		return 0, false, err
 	}
 
 	// Резервирование успешно
-	return true, nil
+	return updatedDoc.Stock, true, nil
+}
+
+// ReserveBackorder списывает allocations с остатков складов атомарно, разрешая складу по умолчанию уйти в минус до -limit
+// Условие на склад по умолчанию: остаток >= списываемого - limit; отсутствующее поле считается нулём.
+// Upsert создаёт документ товара, которого ещё нет, сразу с отрицательным остатком. Если документ есть,
+// но условие не выполнено, upsert упирается в уникальный индекс product_id - это тоже "не списано"
+func (r *Repository) ReserveBackorder(ctx context.Context, productID string, allocations []repository.WarehouseStock, limit int32) (int32, bool, error) {
+	filter := bson.M{"product_id": productID}
+	inc := bson.M{}
+	var total int32
+	for _, a := range allocations {
+		field := warehouseField(a.WarehouseID)
+		switch floor := a.Quantity - limit; {
+		case a.WarehouseID != repository.DefaultWarehouseID:
+			filter[field] = bson.M{"$gte": a.Quantity}
+		case floor > 0:
+			filter[field] = bson.M{"$gte": floor}
+		default:
+			// $not $lt пропускает и документ без поля склада по умолчанию (остаток 0 >= floor)
+			filter[field] = bson.M{"$not": bson.M{"$lt": floor}}
+		}
+		inc[field] = -a.Quantity
+		total += a.Quantity
+	}
+	inc["stock"] = -total
+
+	update := bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var updatedDoc InventoryDocument
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDoc)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Документ товара есть, но остаток изменился или лимит исчерпан
+			return 0, false, nil
+		}
+		if isWriteConflict(err) {
+			return 0, false, fmt.Errorf("%w: %w", repository.ErrWriteConflict, err)
+		}
+		return 0, false, err
+	}
+
+	return updatedDoc.Stock, true, nil
+}
+
+// AddStock увеличивает остаток склада по умолчанию на quantity атомарно
+// Возвращает остаток после пополнения
+func (r *Repository) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	return r.AddWarehouseStock(ctx, productID, []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: quantity}})
+}
+
+// AddWarehouseStock увеличивает остатки складов и stock атомарно
+// Использует FindOneAndUpdate с upsert: если документа нет, он создаётся с остатками allocations
+// Возвращает остаток после пополнения
+func (r *Repository) AddWarehouseStock(ctx context.Context, productID string, allocations []repository.WarehouseStock) (int32, error) {
+	filter := bson.M{"product_id": productID}
+
+	inc := bson.M{}
+	var total int32
+	for _, a := range allocations {
+		inc[warehouseField(a.WarehouseID)] = a.Quantity
+		total += a.Quantity
+	}
+	inc["stock"] = total
+
+	update := bson.M{
+		"$inc": inc,                              // увеличить остатки (при upsert - начать с allocations)
+		"$set": bson.M{"updated_at": time.Now()}, // обновить updated_at
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After) // вернуть документ после обновления
+
+	var updatedDoc InventoryDocument
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDoc)
+	if err != nil {
+		if isWriteConflict(err) {
+			return 0, fmt.Errorf("%w: %w", repository.ErrWriteConflict, err)
+		}
+		return 0, err
+	}
+
+	return updatedDoc.Stock, nil
+}
+
+// CompareAndSetWarehouseStock выставляет остаток склада в quantity одним FindOneAndUpdate с условием остаток = expected
+// Остаток склада в условии зафиксирован, поэтому $inc на разницу равносилен $set и держит stock равным сумме складов.
+// При expected = 0 условие пропускает и документ без поля склада, а upsert создаёт товар, которого ещё нет;
+// если документ есть, но остаток другой, upsert упирается в уникальный индекс product_id - это "не совпало"
+func (r *Repository) CompareAndSetWarehouseStock(ctx context.Context, productID, warehouseID string, expected, quantity int32) (int32, bool, error) {
+	field := warehouseField(warehouseID)
+	filter := bson.M{"product_id": productID, field: expected}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if expected == 0 {
+		// $in из двух значений не попадает в документ, созданный upsert
+		filter[field] = bson.M{"$in": bson.A{0, nil}}
+		opts.SetUpsert(true)
+	}
+
+	delta := quantity - expected
+	update := bson.M{
+		"$inc": bson.M{field: delta, "stock": delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	var updatedDoc InventoryDocument
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDoc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+			return 0, false, nil
+		}
+		if isWriteConflict(err) {
+			return 0, false, fmt.Errorf("%w: %w", repository.ErrWriteConflict, err)
+		}
+		return 0, false, err
+	}
+
+	return updatedDoc.Stock, true, nil
+}
+
+// GetWarehouseStock возвращает остатки товара по складам из MongoDB
+// consistency выбирает read preference/read concern так же, как в GetStock
+// Возвращает ErrNotFound, если товар не найден
+func (r *Repository) GetWarehouseStock(ctx context.Context, productID string, consistency repository.ReadConsistency) ([]repository.WarehouseStock, error) {
+	var doc InventoryDocument
+	err := r.readCollection(consistency).FindOne(ctx, bson.M{"product_id": productID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	stocks := make([]repository.WarehouseStock, 0, len(doc.Warehouses))
+	for warehouseID, quantity := range doc.Warehouses {
+		stocks = append(stocks, repository.WarehouseStock{WarehouseID: warehouseID, Quantity: quantity})
+	}
+	sort.Slice(stocks, func(i, j int) bool { return stocks[i].WarehouseID < stocks[j].WarehouseID })
+	return stocks, nil
+}
+
+// warehouseField возвращает путь к остатку склада в документе
+// ID склада проверяется service слоем (без точек и $), поэтому путь не может указать на другое поле
+func warehouseField(warehouseID string) string {
+	return "warehouses." + warehouseID
+}
+
+// readCollection возвращает коллекцию с настройками чтения для consistency
+// Неизвестное значение трактуется как strong: лучше лишний запрос на primary, чем устаревший остаток на checkout
+func (r *Repository) readCollection(consistency repository.ReadConsistency) *mongo.Collection {
+	if consistency == repository.ReadConsistencyDefault {
+		consistency = r.defaultConsistency
+	}
+	if consistency == repository.ReadConsistencyEventual {
+		return r.eventualCol
+	}
+	return r.strongCol
+}
+
+// isWriteConflict проверяет, что ошибка MongoDB вызвана конкурентной записью в тот же документ
+func isWriteConflict(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	return serverErr.HasErrorCode(writeConflictCode) || serverErr.HasErrorLabel("TransientTransactionError")
 }
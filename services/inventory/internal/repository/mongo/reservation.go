@@ -0,0 +1,214 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// ReservationDocument представляет документ резерва в коллекции MongoDB
+type ReservationDocument struct {
+	ReservationID string     `bson:"reservation_id"`
+	OrderID       string     `bson:"order_id,omitempty"`
+	ProductID     string     `bson:"product_id"`
+	Quantity      int32      `bson:"quantity"`
+	Status        string     `bson:"status"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	ExpiresAt     *time.Time `bson:"expires_at,omitempty"` // nil - резерв без срока
+	FinishedAt    *time.Time `bson:"finished_at,omitempty"`
+	// Allocations - распределение резерва по складам; нет у резервов, созданных до появления складов
+	Allocations []WarehouseStockDocument `bson:"allocations,omitempty"`
+	Backordered int32                    `bson:"backordered,omitempty"` // сколько единиц зарезервировано сверх остатка
+	// IdempotencyKey - ключ идемпотентности ReserveStock; нет у резервов без ключа
+	IdempotencyKey string `bson:"idempotency_key,omitempty"`
+}
+
+// WarehouseStockDocument - количество товара на складе во вложенных документах
+type WarehouseStockDocument struct {
+	WarehouseID string `bson:"warehouse_id"`
+	Quantity    int32  `bson:"quantity"`
+}
+
+// ReservationRepository реализует repository.ReservationRepository используя MongoDB
+type ReservationRepository struct {
+	col *mongo.Collection
+}
+
+// NewReservationRepository создаёт репозиторий резервов
+// Коллекцию, её индексы и TTL индекс завершённых резервов создаёт EnsureSchema
+func NewReservationRepository(client *mongo.Client, dbName string) *ReservationRepository {
+	return &ReservationRepository{col: client.Database(dbName).Collection("reservations")}
+}
+
+// CreateReservation сохраняет новый резерв
+// Нарушение уникального индекса по ключу идемпотентности - repository.ErrReservationAlreadyExists
+func (r *ReservationRepository) CreateReservation(ctx context.Context, reservation repository.Reservation) error {
+	doc := ReservationDocument{
+		ReservationID:  reservation.ID,
+		OrderID:        reservation.OrderID,
+		ProductID:      reservation.ProductID,
+		Quantity:       reservation.Quantity,
+		Status:         reservation.Status,
+		CreatedAt:      reservation.CreatedAt,
+		Backordered:    reservation.Backordered,
+		IdempotencyKey: reservation.IdempotencyKey,
+	}
+	for _, a := range reservation.Allocations {
+		doc.Allocations = append(doc.Allocations, WarehouseStockDocument{WarehouseID: a.WarehouseID, Quantity: a.Quantity})
+	}
+	if !reservation.ExpiresAt.IsZero() {
+		expiresAt := reservation.ExpiresAt
+		doc.ExpiresAt = &expiresAt
+	}
+
+	_, err := r.col.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return repository.ErrReservationAlreadyExists
+	}
+	return err
+}
+
+// GetReservationByIdempotencyKey возвращает резерв по (idempotency_key, product_id)
+func (r *ReservationRepository) GetReservationByIdempotencyKey(ctx context.Context, key, productID string) (repository.Reservation, error) {
+	var doc ReservationDocument
+	err := r.col.FindOne(ctx, bson.M{"idempotency_key": key, "product_id": productID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Reservation{}, repository.ErrReservationNotFound
+		}
+		return repository.Reservation{}, err
+	}
+	return doc.toReservation(), nil
+}
+
+// FinishReservation переводит активный резерв в status одним FindOneAndUpdate с условием status = active
+// Если документ не подошёл под условие, отдельным чтением различаем "нет резерва" и "уже завершён"
+func (r *ReservationRepository) FinishReservation(ctx context.Context, reservationID string, status string) (repository.Reservation, error) {
+	filter := bson.M{
+		"reservation_id": reservationID,
+		"status":         repository.ReservationStatusActive,
+	}
+	update := bson.M{
+		"$set": bson.M{"status": status, "finished_at": time.Now()},
+	}
+
+	var doc ReservationDocument
+	err := r.col.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err == nil {
+		return doc.toReservation(), nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return repository.Reservation{}, err
+	}
+
+	err = r.col.FindOne(ctx, bson.M{"reservation_id": reservationID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Reservation{}, repository.ErrReservationNotFound
+		}
+		return repository.Reservation{}, err
+	}
+	return doc.toReservation(), repository.ErrReservationNotActive
+}
+
+// ListExpiredReservations возвращает до limit активных резервов с expires_at <= now, самые старые первыми
+// Резервы без срока не имеют поля expires_at и под условие не попадают
+func (r *ReservationRepository) ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]repository.Reservation, error) {
+	filter := bson.M{
+		"status":     repository.ReservationStatusActive,
+		"expires_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []ReservationDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	reservations := make([]repository.Reservation, 0, len(docs))
+	for _, doc := range docs {
+		reservations = append(reservations, doc.toReservation())
+	}
+	return reservations, nil
+}
+
+// GetReservation возвращает резерв по reservation_id
+func (r *ReservationRepository) GetReservation(ctx context.Context, reservationID string) (repository.Reservation, error) {
+	var doc ReservationDocument
+	err := r.col.FindOne(ctx, bson.M{"reservation_id": reservationID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Reservation{}, repository.ErrReservationNotFound
+		}
+		return repository.Reservation{}, err
+	}
+	return doc.toReservation(), nil
+}
+
+// ListReservations возвращает до limit резервов под filter, отсортированных по created_at от новых к старым
+func (r *ReservationRepository) ListReservations(ctx context.Context, filter repository.ReservationFilter, limit int) ([]repository.Reservation, error) {
+	query := bson.M{}
+	if filter.ProductID != "" {
+		query["product_id"] = filter.ProductID
+	}
+	if filter.OrderID != "" {
+		query["order_id"] = filter.OrderID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []ReservationDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	reservations := make([]repository.Reservation, 0, len(docs))
+	for _, doc := range docs {
+		reservations = append(reservations, doc.toReservation())
+	}
+	return reservations, nil
+}
+
+func (d ReservationDocument) toReservation() repository.Reservation {
+	reservation := repository.Reservation{
+		ID:             d.ReservationID,
+		OrderID:        d.OrderID,
+		ProductID:      d.ProductID,
+		Quantity:       d.Quantity,
+		Status:         d.Status,
+		CreatedAt:      d.CreatedAt,
+		Backordered:    d.Backordered,
+		IdempotencyKey: d.IdempotencyKey,
+	}
+	if d.ExpiresAt != nil {
+		reservation.ExpiresAt = *d.ExpiresAt
+	}
+	for _, a := range d.Allocations {
+		reservation.Allocations = append(reservation.Allocations, repository.WarehouseStock{WarehouseID: a.WarehouseID, Quantity: a.Quantity})
+	}
+	return reservation
+}
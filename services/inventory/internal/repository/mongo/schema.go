@@ -0,0 +1,194 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// Коды ошибок MongoDB, которые EnsureSchema считает штатными
+const (
+	namespaceExistsCode      = 48 // коллекция уже создана
+	indexNotFoundCode        = 27 // удаляемого индекса нет
+	indexOptionsConflictCode = 85 // индекс с тем же ключом есть, но с другими опциями (другой expireAfterSeconds)
+)
+
+// reservationTTLIndex - имя TTL индекса завершённых резервов
+const reservationTTLIndex = "finished_at_ttl"
+
+// SchemaOptions задаёт настраиваемую часть схемы
+type SchemaOptions struct {
+	// ReservationRetention - сколько хранить снятые и истёкшие резервы (TTL индекс по finished_at); 0 - хранить всегда
+	ReservationRetention time.Duration
+}
+
+// collectionSchema - коллекция с валидатором JSON Schema и её индексы
+type collectionSchema struct {
+	name      string
+	validator bson.M
+	indexes   []mongo.IndexModel
+}
+
+// EnsureSchema создаёт коллекции inventory и reservations с валидацией JSON Schema, их индексы и TTL индекс резервов
+// Вызывается при старте сервиса и cmd/inventory-import; повторный вызов ничего не меняет, а изменённый
+// валидатор или срок хранения применяется к уже существующим коллекциям.
+// Валидация в режиме moderate: новые документы проверяются всегда, а старые, не прошедшие схему,
+// можно обновлять - иначе один битый документ заблокировал бы резервирование товара
+func EnsureSchema(ctx context.Context, db *mongo.Database, opts SchemaOptions) error {
+	for _, schema := range []collectionSchema{inventorySchema(), reservationSchema()} {
+		if err := ensureCollection(ctx, db, schema); err != nil {
+			return err
+		}
+	}
+	if err := ensureReservationTTL(ctx, db.Collection("reservations"), opts.ReservationRetention); err != nil {
+		return fmt.Errorf("reservations TTL index: %w", err)
+	}
+	return nil
+}
+
+// inventorySchema - остаток товара: stock и остатки складов целые (int32 из сервиса или int64 из ручной правки)
+// Остаток может быть отрицательным: предзаказ уводит склад по умолчанию в минус
+func inventorySchema() collectionSchema {
+	integer := bson.M{"bsonType": bson.A{"int", "long"}}
+	return collectionSchema{
+		name: "inventory",
+		validator: bson.M{"$jsonSchema": bson.M{
+			"bsonType": "object",
+			"required": bson.A{"product_id", "stock"},
+			"properties": bson.M{
+				"product_id": bson.M{"bsonType": "string", "minLength": 1},
+				"stock":      integer,
+				"warehouses": bson.M{"bsonType": "object", "additionalProperties": integer},
+				"updated_at": bson.M{"bsonType": "date"},
+			},
+		}},
+		indexes: []mongo.IndexModel{
+			{
+				// Каждый товар - один документ: upsert при приёмке не создаст дубликат даже при гонке
+				Keys:    bson.D{{Key: "product_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	}
+}
+
+// reservationSchema - резерв: статус из repository.ReservationStatus*, количество положительное
+func reservationSchema() collectionSchema {
+	date := bson.M{"bsonType": "date"}
+	return collectionSchema{
+		name: "reservations",
+		validator: bson.M{"$jsonSchema": bson.M{
+			"bsonType": "object",
+			"required": bson.A{"reservation_id", "product_id", "quantity", "status", "created_at"},
+			"properties": bson.M{
+				"reservation_id": bson.M{"bsonType": "string", "minLength": 1},
+				"product_id":     bson.M{"bsonType": "string", "minLength": 1},
+				"order_id":       bson.M{"bsonType": "string"},
+				"quantity":       bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 1},
+				"status": bson.M{"enum": bson.A{
+					repository.ReservationStatusActive, repository.ReservationStatusReleased, repository.ReservationStatusExpired,
+				}},
+				"created_at":  date,
+				"expires_at":  date,
+				"finished_at": date,
+				"allocations": bson.M{"bsonType": "array", "items": bson.M{
+					"bsonType": "object",
+					"required": bson.A{"warehouse_id", "quantity"},
+				}},
+				"backordered":     bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+				"idempotency_key": bson.M{"bsonType": "string"},
+			},
+		}},
+		// Уникальный индекс на reservation_id, (status, expires_at) для поиска истёкших резервов sweeper'ом,
+		// (product_id, created_at) и (order_id, created_at) для выборок админки и частичный уникальный индекс
+		// (idempotency_key, product_id) по резервам с ключом: повтор ReserveStock не создаст второй резерв даже при гонке
+		indexes: []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "reservation_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "idempotency_key", Value: 1}, {Key: "product_id", Value: 1}},
+				Options: options.Index().
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$exists": true}}),
+			},
+		},
+	}
+}
+
+// ensureCollection создаёт коллекцию с валидатором или, если она уже есть, заменяет валидатор через collMod
+func ensureCollection(ctx context.Context, db *mongo.Database, schema collectionSchema) error {
+	err := db.CreateCollection(ctx, schema.name, options.CreateCollection().
+		SetValidator(schema.validator).
+		SetValidationLevel("moderate").
+		SetValidationAction("error"))
+	if hasErrorCode(err, namespaceExistsCode) {
+		err = db.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: schema.name},
+			{Key: "validator", Value: schema.validator},
+			{Key: "validationLevel", Value: "moderate"},
+			{Key: "validationAction", Value: "error"},
+		}).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("collection %s: %w", schema.name, err)
+	}
+
+	if _, err := db.Collection(schema.name).Indexes().CreateMany(ctx, schema.indexes); err != nil {
+		return fmt.Errorf("collection %s indexes: %w", schema.name, err)
+	}
+	return nil
+}
+
+// ensureReservationTTL держит TTL индекс по finished_at со сроком retention
+// TTL стоит на finished_at, а не на expires_at: активный резерв с истёкшим сроком ещё держит товар, и удалить его
+// до того, как sweeper вернёт товар в остаток, значит потерять остаток. finished_at есть только у снятых и истёкших
+// резервов, поэтому активные индекс не удаляет. Срок меняется через collMod, retention = 0 удаляет индекс
+func ensureReservationTTL(ctx context.Context, col *mongo.Collection, retention time.Duration) error {
+	if retention <= 0 {
+		_, err := col.Indexes().DropOne(ctx, reservationTTLIndex)
+		if err != nil && !hasErrorCode(err, indexNotFoundCode) {
+			return err
+		}
+		return nil
+	}
+
+	seconds := int32(retention / time.Second)
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "finished_at", Value: 1}},
+		Options: options.Index().SetName(reservationTTLIndex).SetExpireAfterSeconds(seconds),
+	})
+	if hasErrorCode(err, indexOptionsConflictCode) {
+		return col.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: col.Name()},
+			{Key: "index", Value: bson.D{
+				{Key: "name", Value: reservationTTLIndex},
+				{Key: "expireAfterSeconds", Value: seconds},
+			}},
+		}).Err()
+	}
+	return err
+}
+
+// hasErrorCode проверяет код ошибки сервера MongoDB
+func hasErrorCode(err error, code int) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(code)
+}
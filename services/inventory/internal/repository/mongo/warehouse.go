@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// WarehouseDocument представляет документ склада в коллекции MongoDB
+type WarehouseDocument struct {
+	WarehouseID string    `bson:"warehouse_id"`
+	Name        string    `bson:"name"`
+	Priority    int32     `bson:"priority"`
+	CreatedAt   time.Time `bson:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+// WarehouseRepository реализует repository.WarehouseRepository используя MongoDB
+type WarehouseRepository struct {
+	col       *mongo.Collection
+	inventory *mongo.Collection // остатки: склад с остатком удалить нельзя
+}
+
+// NewWarehouseRepository создаёт репозиторий складов
+// Создаёт уникальный индекс на warehouse_id
+func NewWarehouseRepository(client *mongo.Client, dbName string) *WarehouseRepository {
+	db := client.Database(dbName)
+	col := db.Collection("warehouses")
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "warehouse_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Создаём индекс (если уже существует - игнорируем ошибку)
+	_, _ = col.Indexes().CreateOne(ctx, indexModel)
+
+	return &WarehouseRepository{
+		col:       col,
+		inventory: db.Collection("inventory"),
+	}
+}
+
+// CreateWarehouse сохраняет новый склад
+// Дубликат warehouse_id отсекает уникальный индекс (ошибка duplicate key → ErrWarehouseAlreadyExists)
+func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, warehouse repository.Warehouse) error {
+	_, err := r.col.InsertOne(ctx, WarehouseDocument{
+		WarehouseID: warehouse.ID,
+		Name:        warehouse.Name,
+		Priority:    warehouse.Priority,
+		CreatedAt:   warehouse.CreatedAt,
+		UpdatedAt:   warehouse.UpdatedAt,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %w", repository.ErrWarehouseAlreadyExists, err)
+	}
+	return err
+}
+
+// GetWarehouse возвращает склад по warehouse_id
+func (r *WarehouseRepository) GetWarehouse(ctx context.Context, warehouseID string) (repository.Warehouse, error) {
+	var doc WarehouseDocument
+	err := r.col.FindOne(ctx, bson.M{"warehouse_id": warehouseID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Warehouse{}, repository.ErrWarehouseNotFound
+		}
+		return repository.Warehouse{}, err
+	}
+	return doc.toWarehouse(), nil
+}
+
+// UpdateWarehouse заменяет название и приоритет склада одним FindOneAndUpdate и возвращает документ после обновления
+func (r *WarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse repository.Warehouse) (repository.Warehouse, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       warehouse.Name,
+			"priority":   warehouse.Priority,
+			"updated_at": warehouse.UpdatedAt,
+		},
+	}
+
+	var doc WarehouseDocument
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"warehouse_id": warehouse.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return repository.Warehouse{}, repository.ErrWarehouseNotFound
+		}
+		return repository.Warehouse{}, err
+	}
+	return doc.toWarehouse(), nil
+}
+
+// DeleteWarehouse удаляет документ склада, если ни у одного товара на нём нет остатка
+// Проверка остатка и удаление - два запроса: приёмку на удаляемый склад в этот момент никто не защищает,
+// но такой остаток не теряется - он остаётся в документе товара и виден в GetWarehouseStock
+func (r *WarehouseRepository) DeleteWarehouse(ctx context.Context, warehouseID string) error {
+	err := r.inventory.FindOne(ctx, bson.M{warehouseField(warehouseID): bson.M{"$gt": 0}}).Err()
+	if err == nil {
+		return repository.ErrWarehouseNotEmpty
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"warehouse_id": warehouseID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repository.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// ListWarehouses возвращает все склады, отсортированные по priority, затем по warehouse_id
+// Складов единицы, поэтому без пагинации
+func (r *WarehouseRepository) ListWarehouses(ctx context.Context) ([]repository.Warehouse, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}, {Key: "warehouse_id", Value: 1}})
+	cursor, err := r.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []WarehouseDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	warehouses := make([]repository.Warehouse, 0, len(docs))
+	for _, doc := range docs {
+		warehouses = append(warehouses, doc.toWarehouse())
+	}
+	return warehouses, nil
+}
+
+func (d WarehouseDocument) toWarehouse() repository.Warehouse {
+	return repository.Warehouse{
+		ID:        d.WarehouseID,
+		Name:      d.Name,
+		Priority:  d.Priority,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Причины движения остатка
+const (
+	MovementReasonAdjustment  = "adjustment"  // применён пакет корректировок
+	MovementReasonImport      = "import"      // остаток выставлен cmd/inventory-import
+	MovementReasonReserved    = "reserved"    // товар списан под резерв
+	MovementReasonReleased    = "released"    // резерв снят или несостоявшееся резервирование откатено
+	MovementReasonExpired     = "expired"     // истёкший резерв вернулся в остаток
+	MovementReasonReplenished = "replenished" // приёмка на склад (AddStock)
+	MovementReasonSync        = "sync"        // остаток выставлен внешней складской системой (AdjustStock)
+)
+
+// StockMovement - запись журнала движений: как и почему изменился остаток товара на складе
+type StockMovement struct {
+	ID            string
+	ProductID     string
+	WarehouseID   string // пусто - склад не известен (резервы и возвраты по общему остатку)
+	Delta         int32
+	Available     int32  // суммарный остаток товара после движения
+	Reason        string // MovementReason*
+	AdjustmentID  string // для reason = adjustment
+	ReservationID string // для released/expired по резерву с ID
+	OrderID       string // для released/expired по резерву заказа
+	Actor         string // user_id, по чьему запросу изменился остаток; пусто - сам сервис (sweeper, откат)
+	CreatedAt     time.Time
+}
+
+// MovementFilter - условия выборки движений; пустое поле не фильтрует
+type MovementFilter struct {
+	ProductID    string
+	AdjustmentID string
+	Reason       string
+	From         time.Time // created_at >= From
+	To           time.Time // created_at < To
+}
+
+// MovementRepository определяет интерфейс для журнала движений остатка (только добавление)
+type MovementRepository interface {
+	// CreateMovements сохраняет записи журнала
+	CreateMovements(ctx context.Context, movements []StockMovement) error
+
+	// ListMovements возвращает до limit движений под filter, новые первыми
+	ListMovements(ctx context.Context, filter MovementFilter, limit int) ([]StockMovement, error)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Product - карточка товара в каталоге
+// Остаток товара хранится отдельно (InventoryRepository) и связан с карточкой по ID = product_id
+type Product struct {
+	ID         string
+	SKU        string // артикул, уникален в каталоге
+	Name       string
+	Price      int64  // в минимальных единицах валюты
+	Currency   string // код валюты ISO 4217
+	Attributes map[string]string
+	// LowStockThreshold - порог низкого остатка: резервирование, после которого остаток опустился ниже порога,
+	// публикует inventory.stock.low; 0 - без алерта
+	LowStockThreshold int32
+	// BackorderLimit - на сколько единиц остаток может уйти в минус при резервировании (предзаказ); 0 - предзаказ выключен
+	BackorderLimit int32
+	// Category - категория товара (electronics, groceries, ...); по ней выбирается срок резерва,
+	// если клиент его не задал (INVENTORY_RESERVATION_CATEGORY_TTLS). Пусто - без категории
+	Category  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt - когда товар удалён из каталога (мягкое удаление); nil - товар в каталоге
+	// Карточка удалённого товара остаётся в хранилище и восстанавливается через UndeleteProduct
+	DeletedAt *time.Time
+}
+
+// ProductRepository определяет интерфейс для хранения каталога товаров
+type ProductRepository interface {
+	// CreateProduct сохраняет новый товар
+	// Возвращает ErrProductAlreadyExists, если товар с таким ID или SKU уже есть
+	CreateProduct(ctx context.Context, product Product) error
+
+	// GetProduct возвращает товар по ID
+	// Возвращает ErrNotFound, если товар не найден
+	GetProduct(ctx context.Context, productID string) (Product, error)
+
+	// UpdateProduct заменяет SKU, название, цену, валюту, атрибуты, порог низкого остатка, лимит предзаказа и категорию товара, обновляет UpdatedAt и возвращает товар после изменения
+	// Возвращает ErrNotFound, если товар не найден, и ErrProductAlreadyExists, если новый SKU занят другим товаром
+	UpdateProduct(ctx context.Context, product Product) (Product, error)
+
+	// DeleteProduct помечает товар удалённым на момент deletedAt; повторное удаление ничего не меняет
+	// Возвращает ErrNotFound, если товар не найден
+	DeleteProduct(ctx context.Context, productID string, deletedAt time.Time) error
+
+	// UndeleteProduct снимает пометку удаления, обновляет UpdatedAt и возвращает товар; товар не удалён - возвращается как есть
+	// Возвращает ErrNotFound, если товар не найден
+	UndeleteProduct(ctx context.Context, productID string, updatedAt time.Time) (Product, error)
+
+	// DeletedProductIDs возвращает, какие из productIDs помечены удалёнными (товары без карточки не попадают)
+	DeletedProductIDs(ctx context.Context, productIDs []string) (map[string]bool, error)
+
+	// ListProducts возвращает товары по фильтру, отсортированные по ID
+	ListProducts(ctx context.Context, filter ProductFilter) ([]Product, error)
+}
+
+// StockAvailability - фильтр товаров по наличию на складе
+type StockAvailability string
+
+const (
+	// StockAvailabilityAny - все товары независимо от остатка
+	StockAvailabilityAny StockAvailability = ""
+	// StockAvailabilityInStock - только товары с остатком больше нуля
+	StockAvailabilityInStock StockAvailability = "in_stock"
+	// StockAvailabilityOutOfStock - товары с нулевым остатком или без записи об остатке
+	StockAvailabilityOutOfStock StockAvailability = "out_of_stock"
+)
+
+// ProductFilter задаёт параметры выборки каталога
+type ProductFilter struct {
+	AfterID      string // курсор: только товары с ID больше AfterID (пусто - с начала)
+	Query        string // подстрока названия без учёта регистра или префикс SKU (пусто - без фильтра)
+	Availability StockAvailability
+	// IncludeDeleted - вместе с удалёнными товарами; по умолчанию удалённые в выборку не попадают
+	IncludeDeleted bool
+	Limit          int // максимальное количество товаров
+}
+
+// ErrProductAlreadyExists возвращается, когда товар с таким ID или SKU уже есть в каталоге
+var ErrProductAlreadyExists = errors.New("product already exists")
@@ -0,0 +1,234 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// Результаты обращения к кешу для метрики inventory_stock_cache_requests_total
+const (
+	cacheResultHit    = "hit"
+	cacheResultMiss   = "miss"
+	cacheResultBypass = "bypass" // strong чтение: кеш не используется
+	cacheResultError  = "error"  // Redis недоступен: чтение ушло в хранилище
+)
+
+// StockRepository - хранилище остатка, перед которым стоит кеш (MongoDB репозиторий)
+type StockRepository interface {
+	repository.InventoryRepository
+	repository.BackorderStockRepository
+	repository.StockCASRepository
+}
+
+// CachedStockRepository реализует InventoryRepository, BackorderStockRepository и StockCASRepository с read-through кешем
+// общего остатка в Redis перед next
+// GetStock и GetStockBatch сначала читают Redis, промахи дочитываются из next и кладутся в Redis на ttl.
+// Любое изменение остатка (резерв, в том числе сверх остатка, возврат, приёмка, синхронизация) удаляет ключ товара после записи в next.
+// Strong чтения идут мимо кеша: checkout не должен видеть устаревший остаток.
+// Кеш необязателен: ошибки Redis только логируются, чтение уходит в next.
+// Промах, дочитанный до инвалидации, может положить в кеш старое значение - ttl ограничивает такое устаревание
+type CachedStockRepository struct {
+	next     StockRepository
+	client   *redis.Client
+	ttl      time.Duration
+	logger   *zap.Logger
+	requests metric.Int64Counter
+}
+
+// NewCachedStockRepository создаёт кеширующую обёртку над next
+func NewCachedStockRepository(next StockRepository, client *redis.Client, ttl time.Duration, logger *zap.Logger) *CachedStockRepository {
+	requests, _ := otel.Meter("inventory").Int64Counter("inventory_stock_cache_requests_total",
+		metric.WithDescription("Stock reads by cache result: hit, miss, bypass (strong read) or error (Redis unavailable)"))
+	return &CachedStockRepository{
+		next:     next,
+		client:   client,
+		ttl:      ttl,
+		logger:   logger,
+		requests: requests,
+	}
+}
+
+func stockKey(productID string) string {
+	return fmt.Sprintf("inventory:stock:%s", productID)
+}
+
+// GetStock возвращает остаток из кеша; при промахе читает next и кеширует ответ
+// ErrNotFound не кешируется
+func (r *CachedStockRepository) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
+	if consistency == repository.ReadConsistencyStrong {
+		r.record(ctx, cacheResultBypass, 1)
+		return r.next.GetStock(ctx, productID, consistency)
+	}
+
+	value, err := r.client.Get(ctx, stockKey(productID)).Result()
+	switch {
+	case err == nil:
+		if available, parseErr := strconv.ParseInt(value, 10, 32); parseErr == nil {
+			r.record(ctx, cacheResultHit, 1)
+			return int32(available), nil
+		}
+		r.logger.Warn("invalid cached stock value", zap.String("product_id", productID), zap.String("value", value))
+		r.record(ctx, cacheResultMiss, 1)
+	case errors.Is(err, redis.Nil):
+		r.record(ctx, cacheResultMiss, 1)
+	default:
+		r.logger.Warn("stock cache read failed", zap.Error(err), zap.String("product_id", productID))
+		r.record(ctx, cacheResultError, 1)
+		return r.next.GetStock(ctx, productID, consistency)
+	}
+
+	available, err := r.next.GetStock(ctx, productID, consistency)
+	if err != nil {
+		return 0, err
+	}
+	r.store(ctx, map[string]int32{productID: available})
+	return available, nil
+}
+
+// GetStockBatch читает остатки одним MGET; промахи дочитываются из next одним запросом и кешируются
+func (r *CachedStockRepository) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) (map[string]int32, error) {
+	if consistency == repository.ReadConsistencyStrong || len(productIDs) == 0 {
+		r.record(ctx, cacheResultBypass, len(productIDs))
+		return r.next.GetStockBatch(ctx, productIDs, consistency)
+	}
+
+	keys := make([]string, len(productIDs))
+	for i, productID := range productIDs {
+		keys[i] = stockKey(productID)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		r.logger.Warn("stock cache batch read failed", zap.Error(err), zap.Int("products", len(productIDs)))
+		r.record(ctx, cacheResultError, len(productIDs))
+		return r.next.GetStockBatch(ctx, productIDs, consistency)
+	}
+
+	stock := make(map[string]int32, len(productIDs))
+	var missed []string
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			missed = append(missed, productIDs[i])
+			continue
+		}
+		available, parseErr := strconv.ParseInt(s, 10, 32)
+		if parseErr != nil {
+			missed = append(missed, productIDs[i])
+			continue
+		}
+		stock[productIDs[i]] = int32(available)
+	}
+	r.record(ctx, cacheResultHit, len(stock))
+	r.record(ctx, cacheResultMiss, len(missed))
+	if len(missed) == 0 {
+		return stock, nil
+	}
+
+	fetched, err := r.next.GetStockBatch(ctx, missed, consistency)
+	if err != nil {
+		return nil, err
+	}
+	for productID, available := range fetched {
+		stock[productID] = available
+	}
+	r.store(ctx, fetched)
+	return stock, nil
+}
+
+// ReserveStock списывает остаток в next и инвалидирует кеш товара
+func (r *CachedStockRepository) ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error) {
+	available, ok, err := r.next.ReserveStock(ctx, productID, quantity)
+	if err == nil && ok {
+		r.invalidate(ctx, productID)
+	}
+	return available, ok, err
+}
+
+// AddStock пополняет остаток в next и инвалидирует кеш товара
+func (r *CachedStockRepository) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	available, err := r.next.AddStock(ctx, productID, quantity)
+	if err == nil {
+		r.invalidate(ctx, productID)
+	}
+	return available, err
+}
+
+// GetWarehouseStock не кешируется: разбивка по складам нужна админке и распределению резерва
+func (r *CachedStockRepository) GetWarehouseStock(ctx context.Context, productID string, consistency repository.ReadConsistency) ([]repository.WarehouseStock, error) {
+	return r.next.GetWarehouseStock(ctx, productID, consistency)
+}
+
+// ReserveWarehouseStock списывает остатки складов в next и инвалидирует кеш товара
+func (r *CachedStockRepository) ReserveWarehouseStock(ctx context.Context, productID string, allocations []repository.WarehouseStock) (int32, bool, error) {
+	available, ok, err := r.next.ReserveWarehouseStock(ctx, productID, allocations)
+	if err == nil && ok {
+		r.invalidate(ctx, productID)
+	}
+	return available, ok, err
+}
+
+// ReserveBackorder списывает остаток сверх наличия в next и инвалидирует кеш товара
+func (r *CachedStockRepository) ReserveBackorder(ctx context.Context, productID string, allocations []repository.WarehouseStock, limit int32) (int32, bool, error) {
+	available, ok, err := r.next.ReserveBackorder(ctx, productID, allocations, limit)
+	if err == nil && ok {
+		r.invalidate(ctx, productID)
+	}
+	return available, ok, err
+}
+
+// AddWarehouseStock пополняет остатки складов в next и инвалидирует кеш товара
+func (r *CachedStockRepository) AddWarehouseStock(ctx context.Context, productID string, allocations []repository.WarehouseStock) (int32, error) {
+	available, err := r.next.AddWarehouseStock(ctx, productID, allocations)
+	if err == nil {
+		r.invalidate(ctx, productID)
+	}
+	return available, err
+}
+
+// CompareAndSetWarehouseStock выставляет остаток склада в next и инвалидирует кеш товара
+func (r *CachedStockRepository) CompareAndSetWarehouseStock(ctx context.Context, productID, warehouseID string, expected, quantity int32) (int32, bool, error) {
+	available, ok, err := r.next.CompareAndSetWarehouseStock(ctx, productID, warehouseID, expected, quantity)
+	if err == nil && ok {
+		r.invalidate(ctx, productID)
+	}
+	return available, ok, err
+}
+
+// store кладёт остатки в кеш одним pipeline; ошибки только логируются
+func (r *CachedStockRepository) store(ctx context.Context, stock map[string]int32) {
+	if len(stock) == 0 {
+		return
+	}
+	pipe := r.client.Pipeline()
+	for productID, available := range stock {
+		pipe.Set(ctx, stockKey(productID), strconv.Itoa(int(available)), r.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Warn("stock cache write failed", zap.Error(err), zap.Int("products", len(stock)))
+	}
+}
+
+// invalidate удаляет ключ товара; если Redis недоступен, кеш устареет не дольше чем на ttl
+// Удаление не зависит от отмены запроса: остаток в next уже изменён
+func (r *CachedStockRepository) invalidate(ctx context.Context, productID string) {
+	if err := r.client.Del(context.WithoutCancel(ctx), stockKey(productID)).Err(); err != nil {
+		r.logger.Warn("stock cache invalidation failed", zap.Error(err), zap.String("product_id", productID))
+	}
+}
+
+func (r *CachedStockRepository) record(ctx context.Context, result string, n int) {
+	if n > 0 {
+		r.requests.Add(ctx, int64(n), metric.WithAttributes(attribute.String("result", result)))
+	}
+}
@@ -5,20 +5,45 @@ import (
 	"errors"
 )
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=InventoryRepository --dir=. --output=./mocks --outpkg=mocks
-
 // InventoryRepository определяет интерфейс для работы с хранилищем инвентаря
 // Service слой зависит от этого интерфейса, а не от конкретной реализации
 type InventoryRepository interface {
 	// GetStock получает количество товара на складе
+	// consistency задаёт, откуда читать (primary или secondary); ReadConsistencyDefault - по настройке хранилища
 	// Возвращает ErrNotFound, если товар не найден
-	GetStock(ctx context.Context, productID string) (int32, error)
+	GetStock(ctx context.Context, productID string, consistency ReadConsistency) (int32, error)
+
+	// GetStockBatch получает остатки нескольких товаров одним запросом
+	// Возвращает только найденные товары: ненайденного product_id в map нет (ErrNotFound не возвращается)
+	GetStockBatch(ctx context.Context, productIDs []string, consistency ReadConsistency) (map[string]int32, error)
 
 	// ReserveStock резервирует товар на складе
 	// Проверяет доступность и уменьшает остаток при успешном резервировании
-	// Возвращает true, если резервирование успешно, false если недостаточно товара
-	ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error)
+	// Возвращает остаток после списания и true, если резервирование успешно; 0 и false, если недостаточно товара
+	// Остаток возвращается той же операцией, что и списание: по нему service определяет пересечение порога низкого остатка
+	ReserveStock(ctx context.Context, productID string, quantity int32) (int32, bool, error)
+
+	// AddStock увеличивает остаток товара на quantity (приёмка на склад)
+	// Если товара ещё нет в хранилище, создаёт его
+	// Возвращает остаток после пополнения
+	AddStock(ctx context.Context, productID string, quantity int32) (int32, error)
 }
 
+// ReadConsistency определяет требования к свежести чтения остатка
+type ReadConsistency string
+
+const (
+	// ReadConsistencyDefault - использовать настройку хранилища по умолчанию
+	ReadConsistencyDefault ReadConsistency = ""
+	// ReadConsistencyStrong - чтение с primary с read concern majority (checkout: остаток не должен быть устаревшим)
+	ReadConsistencyStrong ReadConsistency = "strong"
+	// ReadConsistencyEventual - чтение с secondary, если он доступен (просмотр каталога: допустимо отставание реплики)
+	ReadConsistencyEventual ReadConsistency = "eventual"
+)
+
 // ErrNotFound возвращается, когда товар не найден в хранилище
 var ErrNotFound = errors.New("product not found")
+
+// ErrWriteConflict возвращается, когда резервирование не применилось из-за конкурентного изменения того же товара
+// Операцию можно безопасно повторить: остаток не изменён
+var ErrWriteConflict = errors.New("write conflict")
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Статусы резерва
+const (
+	ReservationStatusActive   = "active"   // товар списан с остатка и удерживается за резервом
+	ReservationStatusReleased = "released" // резерв снят через ReleaseReservation, товар вернулся в остаток
+	ReservationStatusExpired  = "expired"  // срок резерва истёк, sweeper вернул товар в остаток
+)
+
+// Reservation - резерв товара: сколько единиц product_id списано с остатка под заказ
+type Reservation struct {
+	ID        string
+	OrderID   string // может быть пустым, если резерв сделан не под заказ
+	ProductID string
+	Quantity  int32
+	Status    string
+	CreatedAt time.Time
+	ExpiresAt time.Time // нулевое значение - резерв без срока, sweeper его не трогает
+	// Allocations - с каких складов списан товар; при снятии резерва товар возвращается на те же склады
+	// nil - резерв списан с общего остатка (склады не настроены)
+	Allocations []WarehouseStock
+	// Backordered - сколько единиц зарезервировано сверх остатка (предзаказ); они списаны со склада по умолчанию
+	Backordered int32
+	// IdempotencyKey - ключ идемпотентности ReserveStock; вместе с ProductID уникален среди всех резервов
+	// Пустой - резерв не идемпотентен (пакетное резервирование, резерв без заказа)
+	IdempotencyKey string
+}
+
+// ReservationFilter - условия выборки резервов в ListReservations; пустое поле не фильтрует
+type ReservationFilter struct {
+	ProductID string
+	OrderID   string
+	Status    string
+}
+
+// ReservationRepository определяет интерфейс для хранения резервов
+// Остаток товара меняет InventoryRepository, здесь только учёт самих резервов
+type ReservationRepository interface {
+	// CreateReservation сохраняет новый активный резерв
+	// Возвращает ErrReservationAlreadyExists, если резерв с тем же IdempotencyKey и ProductID уже есть
+	CreateReservation(ctx context.Context, reservation Reservation) error
+
+	// GetReservationByIdempotencyKey возвращает резерв товара productID по ключу идемпотентности в любом статусе
+	// Возвращает ErrReservationNotFound, если резерва нет
+	GetReservationByIdempotencyKey(ctx context.Context, key, productID string) (Reservation, error)
+
+	// FinishReservation атомарно переводит активный резерв в status (released или expired) и возвращает его
+	// Только один вызов для резерва получает успех, поэтому товар возвращается в остаток ровно один раз
+	// Возвращает ErrReservationNotFound, если резерва нет, и ErrReservationNotActive, если он уже завершён
+	FinishReservation(ctx context.Context, reservationID string, status string) (Reservation, error)
+
+	// ListExpiredReservations возвращает до limit активных резервов с expires_at <= now
+	ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]Reservation, error)
+
+	// GetReservation возвращает резерв по ID в любом статусе
+	// Возвращает ErrReservationNotFound, если резерва нет
+	GetReservation(ctx context.Context, reservationID string) (Reservation, error)
+
+	// ListReservations возвращает до limit резервов под filter, новые первыми
+	ListReservations(ctx context.Context, filter ReservationFilter, limit int) ([]Reservation, error)
+}
+
+// ErrReservationNotFound возвращается, когда резерв не найден
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ErrReservationAlreadyExists возвращается, когда резерв с тем же ключом идемпотентности и товаром уже сохранён
+var ErrReservationAlreadyExists = errors.New("reservation already exists")
+
+// ErrReservationNotActive возвращается, когда резерв уже снят или истёк
+var ErrReservationNotActive = errors.New("reservation is not active")
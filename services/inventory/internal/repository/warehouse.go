@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultWarehouseID - склад, на котором лежит остаток, принятый без указания склада
+// (AddStock без warehouse_id и остаток, накопленный до появления складов)
+const DefaultWarehouseID = "default"
+
+// Warehouse - склад, на котором хранится остаток товаров
+type Warehouse struct {
+	ID        string
+	Name      string
+	Priority  int32 // порядок списания при стратегии priority: меньше - раньше
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WarehouseStock - остаток товара на одном складе
+// Используется и как распределение резерва: сколько единиц списано с какого склада
+type WarehouseStock struct {
+	WarehouseID string
+	Quantity    int32
+}
+
+// WarehouseRepository определяет интерфейс для хранения справочника складов
+type WarehouseRepository interface {
+	// CreateWarehouse сохраняет новый склад
+	// Возвращает ErrWarehouseAlreadyExists, если склад с таким ID уже есть
+	CreateWarehouse(ctx context.Context, warehouse Warehouse) error
+
+	// GetWarehouse возвращает склад по ID
+	// Возвращает ErrWarehouseNotFound, если склада нет
+	GetWarehouse(ctx context.Context, warehouseID string) (Warehouse, error)
+
+	// UpdateWarehouse заменяет название и приоритет склада, обновляет UpdatedAt и возвращает склад после изменения
+	// Возвращает ErrWarehouseNotFound, если склада нет
+	UpdateWarehouse(ctx context.Context, warehouse Warehouse) (Warehouse, error)
+
+	// DeleteWarehouse удаляет склад
+	// Возвращает ErrWarehouseNotFound, если склада нет, и ErrWarehouseNotEmpty, если на нём есть остаток
+	DeleteWarehouse(ctx context.Context, warehouseID string) error
+
+	// ListWarehouses возвращает все склады, отсортированные по приоритету, затем по ID
+	ListWarehouses(ctx context.Context) ([]Warehouse, error)
+}
+
+// WarehouseStockRepository определяет интерфейс для работы с остатком товара по складам
+// Суммарный остаток (InventoryRepository.GetStock) всегда равен сумме остатков по складам
+type WarehouseStockRepository interface {
+	// GetWarehouseStock возвращает остатки товара по складам, отсортированные по ID склада (склады с нулём тоже)
+	// Возвращает ErrNotFound, если товар не найден
+	GetWarehouseStock(ctx context.Context, productID string, consistency ReadConsistency) ([]WarehouseStock, error)
+
+	// ReserveWarehouseStock атомарно списывает allocations с остатков складов
+	// Списание проходит целиком, только если на каждом складе хватает товара; иначе ничего не меняется и возвращается false
+	// Возвращает суммарный остаток после списания
+	ReserveWarehouseStock(ctx context.Context, productID string, allocations []WarehouseStock) (int32, bool, error)
+
+	// AddWarehouseStock атомарно увеличивает остатки складов на allocations (приёмка или возврат резерва)
+	// Если товара ещё нет в хранилище, создаёт его. Возвращает суммарный остаток после пополнения
+	AddWarehouseStock(ctx context.Context, productID string, allocations []WarehouseStock) (int32, error)
+}
+
+// StockCASRepository выставляет остаток склада по принципу compare-and-set (синхронизация с внешней складской системой)
+type StockCASRepository interface {
+	WarehouseStockRepository
+
+	// CompareAndSetWarehouseStock атомарно выставляет остаток товара на складе warehouseID в quantity,
+	// только если сейчас он равен expected; иначе ничего не меняется и возвращается false.
+	// Нет товара или склада в документе товара - остаток 0: при expected = 0 товар создаётся.
+	// Возвращает суммарный остаток после изменения
+	CompareAndSetWarehouseStock(ctx context.Context, productID, warehouseID string, expected, quantity int32) (int32, bool, error)
+}
+
+// ErrWarehouseNotFound возвращается, когда склад не найден
+var ErrWarehouseNotFound = errors.New("warehouse not found")
+
+// ErrWarehouseAlreadyExists возвращается, когда склад с таким ID уже есть
+var ErrWarehouseAlreadyExists = errors.New("warehouse already exists")
+
+// ErrWarehouseNotEmpty возвращается при удалении склада, на котором ещё есть остаток
+var ErrWarehouseNotEmpty = errors.New("warehouse has stock")
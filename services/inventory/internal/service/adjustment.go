@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// maxAdjustmentItems - сколько позиций может быть в одном пакете корректировок
+const maxAdjustmentItems = 1000
+
+// Ошибки корректировок (handler маппит в 400, ErrSelfApproval - в 403, ErrAdjustmentNotApplied - в 409)
+var (
+	ErrAdjustmentIDRequired     = errors.New("adjustment_id is required")
+	ErrAdjustmentReasonRequired = errors.New("adjustment reason is required")
+	ErrEmptyAdjustment          = errors.New("adjustment items must not be empty")
+	ErrAdjustmentTooLarge       = fmt.Errorf("adjustment must contain at most %d items", maxAdjustmentItems)
+	ErrInvalidAdjustmentDelta   = errors.New("adjustment delta must not be zero")
+	ErrInvalidAdjustmentStatus  = errors.New("adjustment status must be draft, approved, applied, rejected or failed")
+	ErrAdjustmentActorRequired  = errors.New("adjustment actor (user_id) is required")
+	ErrSelfApproval             = errors.New("adjustment must be approved by someone other than its author")
+	ErrAdjustmentNotApplied     = errors.New("adjustment could not be applied")
+)
+
+// errAdjustmentInsufficientStock - причина неприменения: уменьшение больше остатка склада
+var errAdjustmentInsufficientStock = errors.New("insufficient stock")
+
+// AdjustmentInput содержит черновик пакета корректировок
+type AdjustmentInput struct {
+	Reason    string
+	Items     []repository.AdjustmentItem // повторяющиеся (product_id, warehouse_id) суммируются
+	CreatedBy string                      // user_id автора из сессии
+}
+
+// AdjustmentService содержит бизнес-логику корректировок остатка с одобрением
+// Корректировка не меняет остаток сразу: пакет создаётся черновиком, и только одобрение другим пользователем
+// применяет все позиции и пишет их в журнал движений
+type AdjustmentService struct {
+	inventory   *InventoryService
+	adjustments repository.AdjustmentRepository
+	movements   repository.MovementRepository
+}
+
+// NewAdjustmentService создаёт сервис корректировок
+// Остаток меняется через inventory: те же склады, повторы при write conflict и события inventory.stock.changed
+func NewAdjustmentService(inventory *InventoryService, adjustments repository.AdjustmentRepository, movements repository.MovementRepository) *AdjustmentService {
+	return &AdjustmentService{
+		inventory:   inventory,
+		adjustments: adjustments,
+		movements:   movements,
+	}
+}
+
+// CreateAdjustment проверяет позиции и сохраняет пакет в статусе draft; остаток не меняется
+func (s *AdjustmentService) CreateAdjustment(ctx context.Context, input AdjustmentInput) (repository.Adjustment, error) {
+	log.Printf("CreateAdjustment called: items=%d, created_by=%s", len(input.Items), input.CreatedBy)
+
+	if input.CreatedBy == "" {
+		return repository.Adjustment{}, ErrAdjustmentActorRequired
+	}
+	if input.Reason == "" {
+		return repository.Adjustment{}, ErrAdjustmentReasonRequired
+	}
+	items, err := s.mergeAdjustmentItems(input.Items)
+	if err != nil {
+		return repository.Adjustment{}, err
+	}
+
+	adjustment := repository.Adjustment{
+		ID:        uuid.NewString(),
+		Reason:    input.Reason,
+		Items:     items,
+		Status:    repository.AdjustmentStatusDraft,
+		CreatedBy: input.CreatedBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.adjustments.CreateAdjustment(ctx, adjustment); err != nil {
+		log.Printf("CreateAdjustment error: %v", err)
+		return repository.Adjustment{}, err
+	}
+
+	log.Printf("Adjustment draft created: id=%s, items=%d", adjustment.ID, len(items))
+	return adjustment, nil
+}
+
+// mergeAdjustmentItems проверяет позиции и суммирует повторяющиеся (product_id, warehouse_id), сохраняя порядок
+// Пустой warehouse_id - склад по умолчанию; другие склады требуют настроенных складов
+func (s *AdjustmentService) mergeAdjustmentItems(items []repository.AdjustmentItem) ([]repository.AdjustmentItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyAdjustment
+	}
+	if len(items) > maxAdjustmentItems {
+		return nil, ErrAdjustmentTooLarge
+	}
+
+	type key struct{ productID, warehouseID string }
+	merged := make([]repository.AdjustmentItem, 0, len(items))
+	index := make(map[key]int, len(items))
+	for _, item := range items {
+		if item.ProductID == "" {
+			return nil, ErrProductIDRequired
+		}
+		if item.Delta == 0 {
+			return nil, ErrInvalidAdjustmentDelta
+		}
+		if item.WarehouseID == "" {
+			item.WarehouseID = repository.DefaultWarehouseID
+		}
+		if item.WarehouseID != repository.DefaultWarehouseID {
+			if s.inventory.allocator == nil {
+				return nil, ErrWarehousesNotConfigured
+			}
+			if err := validateWarehouseID(item.WarehouseID); err != nil {
+				return nil, err
+			}
+		}
+
+		k := key{item.ProductID, item.WarehouseID}
+		if i, ok := index[k]; ok {
+			merged[i].Delta += item.Delta
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, item)
+	}
+
+	// Взаимно погасившиеся позиции не меняют остаток - в пакет они не попадают
+	result := merged[:0]
+	for _, item := range merged {
+		if item.Delta != 0 {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrEmptyAdjustment
+	}
+	return result, nil
+}
+
+// GetAdjustment возвращает пакет корректировок
+func (s *AdjustmentService) GetAdjustment(ctx context.Context, adjustmentID string) (repository.Adjustment, error) {
+	if adjustmentID == "" {
+		return repository.Adjustment{}, ErrAdjustmentIDRequired
+	}
+	return s.adjustments.GetAdjustment(ctx, adjustmentID)
+}
+
+// ListAdjustments возвращает пакеты в статусе status (пусто - в любом), новые первыми
+// limit <= 0 - 50, больше 200 - обрезается до 200
+func (s *AdjustmentService) ListAdjustments(ctx context.Context, status string, limit int) ([]repository.Adjustment, error) {
+	switch status {
+	case "", repository.AdjustmentStatusDraft, repository.AdjustmentStatusApproved, repository.AdjustmentStatusApplied,
+		repository.AdjustmentStatusRejected, repository.AdjustmentStatusFailed:
+	default:
+		return nil, ErrInvalidAdjustmentStatus
+	}
+	return s.adjustments.ListAdjustments(ctx, status, listLimit(limit))
+}
+
+// ListMovements возвращает журнал движений остатка, новые первыми
+func (s *AdjustmentService) ListMovements(ctx context.Context, filter repository.MovementFilter, limit int) ([]repository.StockMovement, error) {
+	if err := validateMovementFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.movements.ListMovements(ctx, filter, listLimit(limit))
+}
+
+// RejectAdjustment отклоняет черновик; остаток не меняется
+// Возвращает repository.ErrAdjustmentStatusConflict, если пакет уже рассмотрен
+func (s *AdjustmentService) RejectAdjustment(ctx context.Context, adjustmentID, rejectedBy, comment string) (repository.Adjustment, error) {
+	log.Printf("RejectAdjustment called: id=%s, by=%s", adjustmentID, rejectedBy)
+
+	if adjustmentID == "" {
+		return repository.Adjustment{}, ErrAdjustmentIDRequired
+	}
+	if rejectedBy == "" {
+		return repository.Adjustment{}, ErrAdjustmentActorRequired
+	}
+	return s.adjustments.UpdateAdjustmentStatus(ctx, adjustmentID, repository.AdjustmentStatusDraft, repository.AdjustmentStatusUpdate{
+		Status:     repository.AdjustmentStatusRejected,
+		ReviewedBy: rejectedBy,
+		ReviewedAt: time.Now().UTC(),
+		Comment:    comment,
+	})
+}
+
+// ApproveAdjustment одобряет черновик и применяет все его позиции по принципу всё или ничего
+// Пакет сначала переводится draft → approved: второе одобрение того же пакета получит ErrAdjustmentStatusConflict.
+// Позиции применяются по очереди (транзакций между документами товаров нет); если какой-то не хватило остатка
+// или запрос упал, уже применённые откатываются, пакет переходит в failed и возвращается ErrAdjustmentNotApplied.
+// После применения каждая позиция записывается в журнал движений, пакет переходит в applied
+func (s *AdjustmentService) ApproveAdjustment(ctx context.Context, adjustmentID, approvedBy string) (repository.Adjustment, error) {
+	log.Printf("ApproveAdjustment called: id=%s, by=%s", adjustmentID, approvedBy)
+
+	if adjustmentID == "" {
+		return repository.Adjustment{}, ErrAdjustmentIDRequired
+	}
+	if approvedBy == "" {
+		return repository.Adjustment{}, ErrAdjustmentActorRequired
+	}
+	draft, err := s.adjustments.GetAdjustment(ctx, adjustmentID)
+	if err != nil {
+		return repository.Adjustment{}, err
+	}
+	if draft.CreatedBy == approvedBy {
+		return repository.Adjustment{}, ErrSelfApproval
+	}
+
+	adjustment, err := s.adjustments.UpdateAdjustmentStatus(ctx, adjustmentID, repository.AdjustmentStatusDraft, repository.AdjustmentStatusUpdate{
+		Status:     repository.AdjustmentStatusApproved,
+		ReviewedBy: approvedBy,
+		ReviewedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return adjustment, err
+	}
+
+	// Пакет уже одобрен: применение и перевод в итоговый статус не должны прерываться уходом клиента
+	ctx = context.WithoutCancel(ctx)
+	movements, applyErr := s.apply(ctx, adjustment)
+	if applyErr != nil {
+		log.Printf("ApproveAdjustment: adjustment %s not applied: %v", adjustmentID, applyErr)
+		failed, err := s.adjustments.UpdateAdjustmentStatus(ctx, adjustmentID, repository.AdjustmentStatusApproved, repository.AdjustmentStatusUpdate{
+			Status:  repository.AdjustmentStatusFailed,
+			Comment: applyErr.Error(),
+		})
+		if err != nil {
+			log.Printf("ApproveAdjustment: failed to mark adjustment %s as failed: %v", adjustmentID, err)
+		}
+		return failed, fmt.Errorf("%w: %w", ErrAdjustmentNotApplied, applyErr)
+	}
+
+	// Остаток уже изменён: ошибка журнала не откатывает пакет, только логируется
+	if err := s.movements.CreateMovements(ctx, movements); err != nil {
+		log.Printf("ApproveAdjustment: failed to record movements for adjustment %s: %v", adjustmentID, err)
+	}
+
+	applied, err := s.adjustments.UpdateAdjustmentStatus(ctx, adjustmentID, repository.AdjustmentStatusApproved, repository.AdjustmentStatusUpdate{
+		Status:    repository.AdjustmentStatusApplied,
+		AppliedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("ApproveAdjustment: stock adjusted, but adjustment %s status was not updated: %v", adjustmentID, err)
+		return repository.Adjustment{}, err
+	}
+
+	log.Printf("Adjustment applied: id=%s, items=%d, approved_by=%s", adjustmentID, len(applied.Items), approvedBy)
+	return applied, nil
+}
+
+// apply применяет позиции пакета; при первой неудаче откатывает уже применённые
+// Возвращает записи журнала движений по применённым позициям
+func (s *AdjustmentService) apply(ctx context.Context, adjustment repository.Adjustment) ([]repository.StockMovement, error) {
+	now := time.Now().UTC()
+	movements := make([]repository.StockMovement, 0, len(adjustment.Items))
+	for _, item := range adjustment.Items {
+		available, ok, err := s.inventory.adjustStock(ctx, item)
+		if err == nil && !ok {
+			err = fmt.Errorf("%w: product %s, warehouse %s, delta %d", errAdjustmentInsufficientStock, item.ProductID, item.WarehouseID, item.Delta)
+		}
+		if err != nil {
+			s.rollback(ctx, movements)
+			return nil, err
+		}
+		movements = append(movements, repository.StockMovement{
+			ID:           uuid.NewString(),
+			ProductID:    item.ProductID,
+			WarehouseID:  item.WarehouseID,
+			Delta:        item.Delta,
+			Available:    available,
+			Reason:       repository.MovementReasonAdjustment,
+			AdjustmentID: adjustment.ID,
+			Actor:        adjustment.ReviewedBy,
+			CreatedAt:    now,
+		})
+	}
+	return movements, nil
+}
+
+// rollback возвращает остаток применённых позиций (ошибки только логируются)
+// Излишек, который успели зарезервировать, откатить нельзя: это видно в логе, пакет всё равно уходит в failed
+func (s *AdjustmentService) rollback(ctx context.Context, applied []repository.StockMovement) {
+	for _, m := range applied {
+		item := repository.AdjustmentItem{ProductID: m.ProductID, WarehouseID: m.WarehouseID, Delta: -m.Delta}
+		if _, ok, err := s.inventory.adjustStock(ctx, item); err != nil || !ok {
+			log.Printf("Failed to roll back adjustment %s: product=%s, warehouse=%s, delta=%d: ok=%v, err=%v",
+				m.AdjustmentID, m.ProductID, m.WarehouseID, item.Delta, ok, err)
+		}
+	}
+}
+
+// adjustStock меняет остаток товара на складе на item.Delta
+// Уменьшение проходит, только если на складе хватает товара (иначе false без ошибки); write conflict повторяется
+func (s *InventoryService) adjustStock(ctx context.Context, item repository.AdjustmentItem) (int32, bool, error) {
+	for attempt := 0; ; attempt++ {
+		available, ok, err := s.adjustStockOnce(ctx, item)
+		if errors.Is(err, repository.ErrWriteConflict) && attempt < reserveConflictRetries {
+			select {
+			case <-ctx.Done():
+				return 0, false, ctx.Err()
+			case <-time.After(reserveConflictBackoff * time.Duration(attempt+1)):
+			}
+			continue
+		}
+		if err != nil || !ok {
+			return 0, ok, err
+		}
+
+		s.publishStockChanged(ctx, StockChangedEvent{ProductID: item.ProductID, Delta: item.Delta, Reason: StockChangeAdjusted, Available: &available})
+		return available, true, nil
+	}
+}
+
+// adjustStockOnce делает одну попытку: через остатки складов или общий остаток repo (склады не настроены)
+func (s *InventoryService) adjustStockOnce(ctx context.Context, item repository.AdjustmentItem) (int32, bool, error) {
+	if s.allocator != nil {
+		if item.Delta > 0 {
+			available, err := s.allocator.stock.AddWarehouseStock(ctx, item.ProductID, []repository.WarehouseStock{{WarehouseID: item.WarehouseID, Quantity: item.Delta}})
+			return available, err == nil, err
+		}
+		return s.allocator.stock.ReserveWarehouseStock(ctx, item.ProductID, []repository.WarehouseStock{{WarehouseID: item.WarehouseID, Quantity: -item.Delta}})
+	}
+	if item.Delta > 0 {
+		available, err := s.repo.AddStock(ctx, item.ProductID, item.Delta)
+		return available, err == nil, err
+	}
+	return s.repo.ReserveStock(ctx, item.ProductID, -item.Delta)
+}
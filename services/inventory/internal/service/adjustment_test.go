@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestAdjustmentService_CreateAdjustment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: duplicates merged, zero net delta dropped", func(t *testing.T) {
+		mockAdjustments := mocks.NewAdjustmentRepository(t)
+		inventory := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+		service := NewAdjustmentService(inventory, mockAdjustments, mocks.NewMovementRepository(t))
+
+		mockAdjustments.On("CreateAdjustment", ctx, mock.MatchedBy(func(a repository.Adjustment) bool {
+			return a.Status == repository.AdjustmentStatusDraft && a.CreatedBy == "admin-1"
+		})).Return(nil).Once()
+
+		adjustment, err := service.CreateAdjustment(ctx, AdjustmentInput{
+			Reason:    "inventory count",
+			CreatedBy: "admin-1",
+			Items: []repository.AdjustmentItem{
+				{ProductID: "product-1", Delta: 5},
+				{ProductID: "product-2", Delta: 3},
+				{ProductID: "product-1", WarehouseID: repository.DefaultWarehouseID, Delta: -2},
+				{ProductID: "product-2", Delta: -3},
+			},
+		})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, adjustment.ID)
+		require.Equal(t, []repository.AdjustmentItem{
+			{ProductID: "product-1", WarehouseID: repository.DefaultWarehouseID, Delta: 3},
+		}, adjustment.Items)
+	})
+
+	tests := []struct {
+		name          string
+		input         AdjustmentInput
+		expectedError error
+	}{
+		{
+			name:          "no author",
+			input:         AdjustmentInput{Reason: "count", Items: []repository.AdjustmentItem{{ProductID: "product-1", Delta: 1}}},
+			expectedError: ErrAdjustmentActorRequired,
+		},
+		{
+			name:          "no reason",
+			input:         AdjustmentInput{CreatedBy: "admin-1", Items: []repository.AdjustmentItem{{ProductID: "product-1", Delta: 1}}},
+			expectedError: ErrAdjustmentReasonRequired,
+		},
+		{
+			name:          "no items",
+			input:         AdjustmentInput{Reason: "count", CreatedBy: "admin-1"},
+			expectedError: ErrEmptyAdjustment,
+		},
+		{
+			name:          "zero delta",
+			input:         AdjustmentInput{Reason: "count", CreatedBy: "admin-1", Items: []repository.AdjustmentItem{{ProductID: "product-1"}}},
+			expectedError: ErrInvalidAdjustmentDelta,
+		},
+		{
+			name: "all items cancel out",
+			input: AdjustmentInput{Reason: "count", CreatedBy: "admin-1", Items: []repository.AdjustmentItem{
+				{ProductID: "product-1", Delta: 2},
+				{ProductID: "product-1", Delta: -2},
+			}},
+			expectedError: ErrEmptyAdjustment,
+		},
+		{
+			name:          "warehouse without warehouses configured",
+			input:         AdjustmentInput{Reason: "count", CreatedBy: "admin-1", Items: []repository.AdjustmentItem{{ProductID: "product-1", WarehouseID: "msk", Delta: 1}}},
+			expectedError: ErrWarehousesNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+			service := NewAdjustmentService(inventory, mocks.NewAdjustmentRepository(t), mocks.NewMovementRepository(t))
+
+			_, err := service.CreateAdjustment(ctx, tt.input)
+
+			require.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAdjustmentService_ApproveAdjustment(t *testing.T) {
+	ctx := context.Background()
+	draft := repository.Adjustment{
+		ID:        "adj-1",
+		Reason:    "inventory count",
+		Status:    repository.AdjustmentStatusDraft,
+		CreatedBy: "admin-1",
+		Items: []repository.AdjustmentItem{
+			{ProductID: "product-1", WarehouseID: repository.DefaultWarehouseID, Delta: 4},
+			{ProductID: "product-2", WarehouseID: repository.DefaultWarehouseID, Delta: -2},
+		},
+	}
+	approved := draft
+	approved.Status = repository.AdjustmentStatusApproved
+	approved.ReviewedBy = "admin-2"
+
+	toStatus := func(status string) interface{} {
+		return mock.MatchedBy(func(u repository.AdjustmentStatusUpdate) bool { return u.Status == status })
+	}
+
+	t.Run("success: items applied and recorded as movements", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockAdjustments := mocks.NewAdjustmentRepository(t)
+		mockMovements := mocks.NewMovementRepository(t)
+		service := NewAdjustmentService(NewInventoryService(mockRepo, mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil), mockAdjustments, mockMovements)
+
+		applied := approved
+		applied.Status = repository.AdjustmentStatusApplied
+		mockAdjustments.On("GetAdjustment", ctx, "adj-1").Return(draft, nil).Once()
+		mockAdjustments.On("UpdateAdjustmentStatus", ctx, "adj-1", repository.AdjustmentStatusDraft, toStatus(repository.AdjustmentStatusApproved)).
+			Return(approved, nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(4)).Return(int32(14), nil).Once()
+		mockRepo.On("ReserveStock", mock.Anything, "product-2", int32(2)).Return(int32(3), true, nil).Once()
+		mockMovements.On("CreateMovements", mock.Anything, mock.MatchedBy(func(m []repository.StockMovement) bool {
+			return len(m) == 2 && m[0].Available == 14 && m[1].Delta == -2 && m[1].Actor == "admin-2" &&
+				m[1].AdjustmentID == "adj-1" && m[1].Reason == repository.MovementReasonAdjustment
+		})).Return(nil).Once()
+		mockAdjustments.On("UpdateAdjustmentStatus", mock.Anything, "adj-1", repository.AdjustmentStatusApproved, toStatus(repository.AdjustmentStatusApplied)).
+			Return(applied, nil).Once()
+
+		result, err := service.ApproveAdjustment(ctx, "adj-1", "admin-2")
+
+		require.NoError(t, err)
+		require.Equal(t, repository.AdjustmentStatusApplied, result.Status)
+	})
+
+	t.Run("insufficient stock: applied items rolled back, adjustment failed", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockAdjustments := mocks.NewAdjustmentRepository(t)
+		service := NewAdjustmentService(NewInventoryService(mockRepo, mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil), mockAdjustments, mocks.NewMovementRepository(t))
+
+		failed := approved
+		failed.Status = repository.AdjustmentStatusFailed
+		mockAdjustments.On("GetAdjustment", ctx, "adj-1").Return(draft, nil).Once()
+		mockAdjustments.On("UpdateAdjustmentStatus", ctx, "adj-1", repository.AdjustmentStatusDraft, toStatus(repository.AdjustmentStatusApproved)).
+			Return(approved, nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(4)).Return(int32(14), nil).Once()
+		mockRepo.On("ReserveStock", mock.Anything, "product-2", int32(2)).Return(int32(1), false, nil).Once()
+		mockRepo.On("ReserveStock", mock.Anything, "product-1", int32(4)).Return(int32(10), true, nil).Once()
+		mockAdjustments.On("UpdateAdjustmentStatus", mock.Anything, "adj-1", repository.AdjustmentStatusApproved, toStatus(repository.AdjustmentStatusFailed)).
+			Return(failed, nil).Once()
+
+		result, err := service.ApproveAdjustment(ctx, "adj-1", "admin-2")
+
+		require.ErrorIs(t, err, ErrAdjustmentNotApplied)
+		require.Equal(t, repository.AdjustmentStatusFailed, result.Status)
+	})
+
+	t.Run("self approval", func(t *testing.T) {
+		mockAdjustments := mocks.NewAdjustmentRepository(t)
+		service := NewAdjustmentService(NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil), mockAdjustments, mocks.NewMovementRepository(t))
+
+		mockAdjustments.On("GetAdjustment", ctx, "adj-1").Return(draft, nil).Once()
+
+		_, err := service.ApproveAdjustment(ctx, "adj-1", "admin-1")
+
+		require.ErrorIs(t, err, ErrSelfApproval)
+	})
+
+	t.Run("already reviewed: stock is not changed", func(t *testing.T) {
+		mockAdjustments := mocks.NewAdjustmentRepository(t)
+		service := NewAdjustmentService(NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil), mockAdjustments, mocks.NewMovementRepository(t))
+
+		mockAdjustments.On("GetAdjustment", ctx, "adj-1").Return(draft, nil).Once()
+		mockAdjustments.On("UpdateAdjustmentStatus", ctx, "adj-1", repository.AdjustmentStatusDraft, toStatus(repository.AdjustmentStatusApproved)).
+			Return(repository.Adjustment{}, repository.ErrAdjustmentStatusConflict).Once()
+
+		_, err := service.ApproveAdjustment(ctx, "adj-1", "admin-2")
+
+		require.ErrorIs(t, err, repository.ErrAdjustmentStatusConflict)
+	})
+}
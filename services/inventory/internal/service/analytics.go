@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// StockAnalyticsService отдаёт сводку "доступно / зарезервировано / сверх остатка" по товарам и складам
+// Сводку считает хранилище (агрегация MongoDB), сервис только проверяет запрос и режет выборку на страницы
+type StockAnalyticsService struct {
+	analytics repository.StockAnalyticsRepository
+}
+
+// NewStockAnalyticsService создаёт сервис аналитики остатков
+func NewStockAnalyticsService(analytics repository.StockAnalyticsRepository) *StockAnalyticsService {
+	return &StockAnalyticsService{analytics: analytics}
+}
+
+// StockAnalyticsInput содержит параметры выборки сводки
+type StockAnalyticsInput struct {
+	ProductIDs  []string // пусто - все товары; не больше maxStockBatchSize
+	WarehouseID string   // пусто - все склады
+	PageSize    int      // 0 - DefaultProductPageSize, больше MaxProductPageSize - обрезается
+	PageToken   string   // NextPageToken предыдущей страницы; пусто - первая страница
+}
+
+// StockAnalyticsOutput - страница сводки
+type StockAnalyticsOutput struct {
+	Items         []repository.StockSummary
+	NextPageToken string // пусто - страница последняя
+}
+
+// GetStockAnalytics возвращает страницу сводки, отсортированную по ID товара
+// Пагинация курсорная, с тем же токеном, что и у ListProducts
+func (s *StockAnalyticsService) GetStockAnalytics(ctx context.Context, input StockAnalyticsInput) (StockAnalyticsOutput, error) {
+	if len(input.ProductIDs) > maxStockBatchSize {
+		return StockAnalyticsOutput{}, ErrStockBatchTooLarge
+	}
+	for _, productID := range input.ProductIDs {
+		if productID == "" {
+			return StockAnalyticsOutput{}, ErrProductIDRequired
+		}
+	}
+	if input.WarehouseID != "" && !warehouseIDPattern.MatchString(input.WarehouseID) {
+		return StockAnalyticsOutput{}, ErrInvalidWarehouseID
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultProductPageSize
+	}
+	if pageSize > MaxProductPageSize {
+		pageSize = MaxProductPageSize
+	}
+	afterID, err := decodePageToken(input.PageToken)
+	if err != nil {
+		return StockAnalyticsOutput{}, err
+	}
+
+	// Запрашиваем на один товар больше: так без отдельного count известно, есть ли следующая страница
+	items, err := s.analytics.GetStockAnalytics(ctx, repository.StockAnalyticsFilter{
+		ProductIDs:     input.ProductIDs,
+		WarehouseID:    input.WarehouseID,
+		AfterProductID: afterID,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		log.Printf("GetStockAnalytics error: %v", err)
+		return StockAnalyticsOutput{}, err
+	}
+
+	out := StockAnalyticsOutput{Items: items}
+	if len(items) > pageSize {
+		out.Items = items[:pageSize]
+		out.NextPageToken = encodePageToken(out.Items[pageSize-1].ProductID)
+	}
+	return out, nil
+}
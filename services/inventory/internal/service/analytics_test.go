@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestStockAnalyticsService_GetStockAnalytics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages through products by cursor", func(t *testing.T) {
+		mockAnalytics := mocks.NewStockAnalyticsRepository(t)
+		service := NewStockAnalyticsService(mockAnalytics)
+
+		mockAnalytics.On("GetStockAnalytics", ctx, repository.StockAnalyticsFilter{WarehouseID: "msk", Limit: 3}).Return([]repository.StockSummary{
+			{ProductID: "p1", Available: 5, Reserved: 2},
+			{ProductID: "p2", Available: -1, Reserved: 3, Backordered: 1},
+			{ProductID: "p3"},
+		}, nil).Once()
+
+		out, err := service.GetStockAnalytics(ctx, StockAnalyticsInput{WarehouseID: "msk", PageSize: 2})
+
+		require.NoError(t, err)
+		require.Len(t, out.Items, 2)
+		require.Equal(t, "p2", out.Items[1].ProductID)
+		require.NotEmpty(t, out.NextPageToken)
+
+		mockAnalytics.On("GetStockAnalytics", ctx, repository.StockAnalyticsFilter{WarehouseID: "msk", AfterProductID: "p2", Limit: 3}).
+			Return([]repository.StockSummary{{ProductID: "p3"}}, nil).Once()
+
+		out, err = service.GetStockAnalytics(ctx, StockAnalyticsInput{WarehouseID: "msk", PageSize: 2, PageToken: out.NextPageToken})
+
+		require.NoError(t, err)
+		require.Len(t, out.Items, 1)
+		require.Empty(t, out.NextPageToken)
+	})
+
+	t.Run("product ids are passed to repository, page size defaults", func(t *testing.T) {
+		mockAnalytics := mocks.NewStockAnalyticsRepository(t)
+		service := NewStockAnalyticsService(mockAnalytics)
+
+		mockAnalytics.On("GetStockAnalytics", ctx, repository.StockAnalyticsFilter{
+			ProductIDs: []string{"p1", "p2"},
+			Limit:      DefaultProductPageSize + 1,
+		}).Return([]repository.StockSummary{{ProductID: "p1"}}, nil).Once()
+
+		out, err := service.GetStockAnalytics(ctx, StockAnalyticsInput{ProductIDs: []string{"p1", "p2"}})
+
+		require.NoError(t, err)
+		require.Len(t, out.Items, 1)
+	})
+
+	t.Run("validation errors do not reach repository", func(t *testing.T) {
+		service := NewStockAnalyticsService(mocks.NewStockAnalyticsRepository(t))
+
+		cases := map[error]StockAnalyticsInput{
+			ErrProductIDRequired:  {ProductIDs: []string{"p1", ""}},
+			ErrStockBatchTooLarge: {ProductIDs: strings.Split(strings.Repeat("p,", maxStockBatchSize), ",")},
+			ErrInvalidWarehouseID: {WarehouseID: "msk.1"},
+			ErrInvalidPageToken:   {PageToken: "!!!"},
+		}
+		for want, input := range cases {
+			_, err := service.GetStockAnalytics(ctx, input)
+			require.ErrorIs(t, err, want)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// EventTypeBackordered - тип события резервирования сверх остатка (предзаказ)
+const EventTypeBackordered = "inventory.backordered"
+
+// BackorderedEvent - событие inventory.backordered: резервирование увело остаток товара в минус
+type BackorderedEvent struct {
+	EventID       string
+	OccurredAt    time.Time
+	BackorderID   string
+	ProductID     string
+	OrderID       string // пусто, если резерв сделан не под заказ
+	ReservationID string // пусто для ReserveStock без резерва
+	Quantity      int32  // сколько единиц зарезервировано всего
+	Backordered   int32  // сколько из них сверх остатка
+	Available     int32  // суммарный остаток после резервирования
+	Limit         int32  // BackorderLimit карточки товара
+}
+
+// BackorderPublisher публикует события inventory.backordered
+type BackorderPublisher interface {
+	PublishBackordered(ctx context.Context, event BackorderedEvent) error
+}
+
+// Backorders резервирует товар сверх остатка для товаров с включённым предзаказом (Product.BackorderLimit > 0)
+// Срабатывает, только когда обычное резервирование не нашло товара: всё, что есть на складах, списывается целиком,
+// недостающие единицы - со склада по умолчанию, остаток которого уходит в минус не ниже -BackorderLimit.
+// Приёмка на склад по умолчанию гасит минус, снятие резерва возвращает единицы туда же
+type Backorders struct {
+	products repository.ProductRepository
+	stock    repository.BackorderStockRepository
+	journal  repository.BackorderRepository
+	events   BackorderPublisher
+}
+
+// NewBackorders создаёт резервирование сверх остатка
+// events может быть nil (события inventory.backordered не публикуются, журнал пишется)
+func NewBackorders(products repository.ProductRepository, stock repository.BackorderStockRepository, journal repository.BackorderRepository, events BackorderPublisher) *Backorders {
+	return &Backorders{
+		products: products,
+		stock:    stock,
+		journal:  journal,
+		events:   events,
+	}
+}
+
+// reserve делает одну попытку зарезервировать quantity сверх остатка
+// false без ошибки - предзаказ для товара выключен или лимит исчерпан
+// Если остаток изменился между чтением и списанием, возвращает ErrWriteConflict, как WarehouseAllocator
+func (b *Backorders) reserve(ctx context.Context, productID string, quantity int32) (reserveResult, bool, error) {
+	product, err := b.products.GetProduct(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return reserveResult{}, false, nil
+	}
+	if err != nil {
+		return reserveResult{}, false, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product.BackorderLimit <= 0 {
+		return reserveResult{}, false, nil
+	}
+
+	stocks, err := b.stock.GetWarehouseStock(ctx, productID, repository.ReadConsistencyStrong)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return reserveResult{}, false, err
+	}
+	plan, backordered := planBackorder(quantity, stocks, product.BackorderLimit)
+	if plan == nil {
+		return reserveResult{}, false, nil
+	}
+
+	available, ok, err := b.stock.ReserveBackorder(ctx, productID, plan, product.BackorderLimit)
+	if err != nil {
+		return reserveResult{}, false, err
+	}
+	if !ok {
+		return reserveResult{}, false, fmt.Errorf("%w: stock changed during backorder", repository.ErrWriteConflict)
+	}
+	return reserveResult{allocations: plan, available: available, backordered: backordered, backorderLimit: product.BackorderLimit}, true, nil
+}
+
+// planBackorder списывает весь положительный остаток складов, а недостающее - со склада по умолчанию
+// Возвращает распределение и число единиц сверх остатка; nil, если товара хватает без предзаказа
+// (резервирование отклонила стратегия, например single_warehouse) или остаток склада по умолчанию ушёл бы ниже -limit
+func planBackorder(quantity int32, stocks []repository.WarehouseStock, limit int32) ([]repository.WarehouseStock, int32) {
+	var inStock, defaultStock int32
+	for _, s := range stocks {
+		if s.WarehouseID == repository.DefaultWarehouseID {
+			defaultStock = s.Quantity
+		}
+		if s.Quantity > 0 {
+			inStock += s.Quantity
+		}
+	}
+	backordered := quantity - inStock
+	if backordered <= 0 || min(defaultStock, 0)-backordered < -limit {
+		return nil, 0
+	}
+
+	plan := make([]repository.WarehouseStock, 0, len(stocks)+1)
+	for _, s := range stocks {
+		if s.Quantity > 0 && s.WarehouseID != repository.DefaultWarehouseID {
+			plan = append(plan, s)
+		}
+	}
+	plan = append(plan, repository.WarehouseStock{WarehouseID: repository.DefaultWarehouseID, Quantity: max(defaultStock, 0) + backordered})
+	return plan, backordered
+}
+
+// record записывает резервирование сверх остатка в журнал и публикует inventory.backordered
+// Резерв уже создан: ошибки журнала и публикации только логируются
+func (b *Backorders) record(ctx context.Context, reservation repository.Reservation, result reserveResult) {
+	backorder := repository.Backorder{
+		ID:            uuid.NewString(),
+		ProductID:     reservation.ProductID,
+		OrderID:       reservation.OrderID,
+		ReservationID: reservation.ID,
+		Quantity:      reservation.Quantity,
+		Backordered:   result.backordered,
+		Available:     result.available,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := b.journal.CreateBackorder(ctx, backorder); err != nil {
+		log.Printf("Failed to record backorder: product=%s, reservation=%s, backordered=%d: %v",
+			backorder.ProductID, backorder.ReservationID, backorder.Backordered, err)
+	}
+
+	if b.events == nil {
+		return
+	}
+	event := BackorderedEvent{
+		EventID:       uuid.NewString(),
+		OccurredAt:    backorder.CreatedAt,
+		BackorderID:   backorder.ID,
+		ProductID:     backorder.ProductID,
+		OrderID:       backorder.OrderID,
+		ReservationID: backorder.ReservationID,
+		Quantity:      backorder.Quantity,
+		Backordered:   backorder.Backordered,
+		Available:     backorder.Available,
+		Limit:         result.backorderLimit,
+	}
+	if err := b.events.PublishBackordered(ctx, event); err != nil {
+		log.Printf("Failed to publish %s: product=%s, backordered=%d: %v",
+			EventTypeBackordered, event.ProductID, event.Backordered, err)
+	}
+}
+
+// ListBackorders возвращает журнал предзаказов товара productID (пусто - всех товаров), новые первыми
+// limit <= 0 - 50, больше 200 - обрезается до 200; без настроенных предзаказов журнал пуст
+func (s *InventoryService) ListBackorders(ctx context.Context, productID string, limit int) ([]repository.Backorder, error) {
+	if s.backorders == nil {
+		return nil, nil
+	}
+	return s.backorders.journal.ListBackorders(ctx, productID, listLimit(limit))
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+// fakeBackordered запоминает опубликованные события inventory.backordered
+type fakeBackordered struct {
+	events []BackorderedEvent
+}
+
+func (p *fakeBackordered) PublishBackordered(ctx context.Context, event BackorderedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestPlanBackorder(t *testing.T) {
+	tests := []struct {
+		name            string
+		quantity        int32
+		stocks          []repository.WarehouseStock
+		limit           int32
+		wantPlan        []repository.WarehouseStock
+		wantBackordered int32
+	}{
+		{
+			name:            "no stock record: everything from default",
+			quantity:        3,
+			limit:           5,
+			wantPlan:        []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 3}},
+			wantBackordered: 3,
+		},
+		{
+			name:     "stock on other warehouses is taken first",
+			quantity: 10,
+			stocks: []repository.WarehouseStock{
+				{WarehouseID: repository.DefaultWarehouseID, Quantity: 2},
+				{WarehouseID: "msk", Quantity: 3},
+				{WarehouseID: "spb", Quantity: 0},
+			},
+			limit: 5,
+			wantPlan: []repository.WarehouseStock{
+				{WarehouseID: "msk", Quantity: 3},
+				{WarehouseID: repository.DefaultWarehouseID, Quantity: 7},
+			},
+			wantBackordered: 5,
+		},
+		{
+			name:            "default already negative: remaining limit counts",
+			quantity:        2,
+			stocks:          []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: -3}},
+			limit:           5,
+			wantPlan:        []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 2}},
+			wantBackordered: 2,
+		},
+		{
+			name:     "limit exceeded",
+			quantity: 3,
+			stocks:   []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: -3}},
+			limit:    5,
+		},
+		{
+			name:     "enough stock: no backorder",
+			quantity: 3,
+			stocks:   []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 2}, {WarehouseID: "spb", Quantity: 2}},
+			limit:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, backordered := planBackorder(tt.quantity, tt.stocks, tt.limit)
+
+			require.Equal(t, tt.wantPlan, plan)
+			require.Equal(t, tt.wantBackordered, backordered)
+		})
+	}
+}
+
+func TestInventoryService_CreateReservation_Backorder(t *testing.T) {
+	ctx := context.Background()
+	warehouses := []repository.Warehouse{{ID: "msk", Priority: 1}}
+	product := repository.Product{ID: "product-1", SKU: "SKU-1", Name: "Чайник", BackorderLimit: 5}
+
+	t.Run("insufficient stock is reserved over the limit and recorded", func(t *testing.T) {
+		mockReservations := mocks.NewReservationRepository(t)
+		mockStock := mocks.NewBackorderStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		mockJournal := mocks.NewBackorderRepository(t)
+		events := &fakeBackordered{}
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		backorders := NewBackorders(mockProducts, mockStock, mockJournal, events)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mockReservations, nil, nil, allocator, backorders, nil, nil)
+
+		stocks := []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 1}, {WarehouseID: "msk", Quantity: 1}}
+		plan := []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 1}, {WarehouseID: repository.DefaultWarehouseID, Quantity: 3}}
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).Return(stocks, nil).Twice()
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Once()
+		mockWarehouses.On("ListWarehouses", ctx).Return(warehouses, nil).Once()
+		mockProducts.On("GetProduct", ctx, "product-1").Return(product, nil).Once()
+		mockStock.On("ReserveBackorder", ctx, "product-1", plan, int32(5)).Return(int32(-2), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.Backordered == 2
+		})).Return(nil).Once()
+		mockJournal.On("CreateBackorder", ctx, mock.MatchedBy(func(b repository.Backorder) bool {
+			return b.ProductID == "product-1" && b.OrderID == "order-1" && b.Backordered == 2 && b.Available == -2
+		})).Return(nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 4, OrderID: "order-1"})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Equal(t, plan, reservation.Allocations)
+		require.Len(t, events.events, 1)
+		require.Equal(t, reservation.ID, events.events[0].ReservationID)
+		require.Equal(t, int32(2), events.events[0].Backordered)
+		require.Equal(t, int32(5), events.events[0].Limit)
+	})
+
+	t.Run("backorder disabled for product", func(t *testing.T) {
+		mockStock := mocks.NewBackorderStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		backorders := NewBackorders(mockProducts, mockStock, mocks.NewBackorderRepository(t), nil)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, allocator, backorders, nil, nil)
+
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).Return(nil, repository.ErrNotFound).Once()
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{ID: "product-1"}, nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+	})
+
+	t.Run("limit exhausted concurrently: replanned and rejected", func(t *testing.T) {
+		mockStock := mocks.NewBackorderStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		backorders := NewBackorders(mockProducts, mockStock, mocks.NewBackorderRepository(t), nil)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, allocator, backorders, nil, nil)
+
+		mockWarehouses.On("ListWarehouses", ctx).Return(warehouses, nil).Twice()
+		mockProducts.On("GetProduct", ctx, "product-1").Return(product, nil).Twice()
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: -1}}, nil).Twice()
+		mockStock.On("ReserveBackorder", ctx, "product-1", mock.Anything, int32(5)).Return(int32(0), false, nil).Once()
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: -4}}, nil).Twice()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 3})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+	})
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// DefaultCurrency используется, если валюта цены товара не передана
+const DefaultCurrency = "RUB"
+
+// Ошибки валидации карточки товара (handler маппит в codes.InvalidArgument)
+var (
+	ErrSKURequired              = errors.New("sku is required")
+	ErrNameRequired             = errors.New("name is required")
+	ErrInvalidPrice             = errors.New("price must not be negative")
+	ErrInvalidCurrency          = errors.New("currency must be an ISO 4217 code")
+	ErrInvalidLowStockThreshold = errors.New("low_stock_threshold must not be negative")
+	ErrInvalidBackorderLimit    = errors.New("backorder_limit must not be negative")
+)
+
+// ProductInput содержит поля карточки товара для создания и обновления
+type ProductInput struct {
+	ProductID         string // при создании необязательно: пусто - сгенерировать UUID
+	SKU               string
+	Name              string
+	Price             int64  // в минимальных единицах валюты
+	Currency          string // код валюты ISO 4217; пусто - DefaultCurrency
+	Attributes        map[string]string
+	LowStockThreshold int32 // 0 - без алерта о низком остатке
+	BackorderLimit    int32 // 0 - без предзаказа: резервирование сверх остатка отклоняется
+	// Category - категория для срока резерва; приводится к нижнему регистру, пусто - без категории
+	Category string
+}
+
+// CatalogService содержит бизнес-логику каталога товаров
+// Каталог отделён от остатка: карточку можно создать до приёмки на склад, а AddStock не требует карточки
+type CatalogService struct {
+	products repository.ProductRepository
+}
+
+// NewCatalogService создаёт сервис каталога товаров
+func NewCatalogService(products repository.ProductRepository) *CatalogService {
+	return &CatalogService{
+		products: products,
+	}
+}
+
+// CreateProduct создаёт товар в каталоге
+// Возвращает repository.ErrProductAlreadyExists, если product_id или sku заняты
+func (s *CatalogService) CreateProduct(ctx context.Context, input ProductInput) (repository.Product, error) {
+	log.Printf("CreateProduct called: product=%s, sku=%s", input.ProductID, input.SKU)
+
+	product, err := productFromInput(input)
+	if err != nil {
+		return repository.Product{}, err
+	}
+	if product.ID == "" {
+		product.ID = uuid.NewString()
+	}
+	now := time.Now().UTC()
+	product.CreatedAt = now
+	product.UpdatedAt = now
+
+	if err := s.products.CreateProduct(ctx, product); err != nil {
+		log.Printf("CreateProduct error: product=%s, sku=%s: %v", product.ID, product.SKU, err)
+		return repository.Product{}, err
+	}
+
+	log.Printf("Product created: id=%s, sku=%s", product.ID, product.SKU)
+	return product, nil
+}
+
+// GetProduct возвращает товар из каталога
+// Возвращает repository.ErrNotFound, если товара нет
+func (s *CatalogService) GetProduct(ctx context.Context, productID string) (repository.Product, error) {
+	if productID == "" {
+		return repository.Product{}, ErrProductIDRequired
+	}
+	return s.products.GetProduct(ctx, productID)
+}
+
+// UpdateProduct заменяет sku, название, цену, валюту, атрибуты, порог низкого остатка и лимит предзаказа товара
+// Возвращает repository.ErrNotFound, если товара нет, и repository.ErrProductAlreadyExists, если sku занят другим товаром
+func (s *CatalogService) UpdateProduct(ctx context.Context, input ProductInput) (repository.Product, error) {
+	log.Printf("UpdateProduct called: product=%s, sku=%s", input.ProductID, input.SKU)
+
+	if input.ProductID == "" {
+		return repository.Product{}, ErrProductIDRequired
+	}
+	product, err := productFromInput(input)
+	if err != nil {
+		return repository.Product{}, err
+	}
+	product.UpdatedAt = time.Now().UTC()
+
+	updated, err := s.products.UpdateProduct(ctx, product)
+	if err != nil {
+		log.Printf("UpdateProduct error: product=%s: %v", product.ID, err)
+		return repository.Product{}, err
+	}
+	return updated, nil
+}
+
+// DeleteProduct удаляет товар из каталога мягко: карточка помечается удалённой и пропадает из ListProducts,
+// остаток перестаёт отдаваться GetStock, новые резервы товара отклоняются (ErrProductDeleted).
+// Остаток и уже созданные резервы не трогаются; повторное удаление не ошибка
+// Возвращает repository.ErrNotFound, если товара нет
+func (s *CatalogService) DeleteProduct(ctx context.Context, productID string) error {
+	log.Printf("DeleteProduct called: product=%s", productID)
+
+	if productID == "" {
+		return ErrProductIDRequired
+	}
+	return s.products.DeleteProduct(ctx, productID, time.Now().UTC())
+}
+
+// UndeleteProduct возвращает удалённый товар в каталог; товар, который не был удалён, возвращается без изменений
+// Возвращает repository.ErrNotFound, если товара нет
+func (s *CatalogService) UndeleteProduct(ctx context.Context, productID string) (repository.Product, error) {
+	log.Printf("UndeleteProduct called: product=%s", productID)
+
+	if productID == "" {
+		return repository.Product{}, ErrProductIDRequired
+	}
+	product, err := s.products.UndeleteProduct(ctx, productID, time.Now().UTC())
+	if err != nil {
+		log.Printf("UndeleteProduct error: product=%s: %v", productID, err)
+		return repository.Product{}, err
+	}
+	return product, nil
+}
+
+// DefaultProductPageSize - размер страницы ListProducts, если он не задан
+const DefaultProductPageSize = 50
+
+// MaxProductPageSize - максимальный размер страницы ListProducts
+const MaxProductPageSize = 200
+
+// ErrInvalidPageToken возвращается, если page_token не выдан ListProducts
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ListProductsInput содержит параметры выборки каталога
+type ListProductsInput struct {
+	PageSize     int    // 0 - DefaultProductPageSize, больше MaxProductPageSize - обрезается
+	PageToken    string // NextPageToken предыдущей страницы; пусто - первая страница
+	Query        string // подстрока названия или префикс SKU
+	Availability repository.StockAvailability
+	// IncludeDeleted - вместе с удалёнными товарами (для восстановления через UndeleteProduct)
+	IncludeDeleted bool
+}
+
+// ListProductsOutput - страница каталога
+type ListProductsOutput struct {
+	Products      []repository.Product
+	NextPageToken string // пусто - страница последняя
+}
+
+// ListProducts возвращает страницу каталога, отсортированную по ID товара
+// Пагинация курсорная: токен хранит ID последнего товара страницы, поэтому вставки и удаления
+// между запросами не сдвигают страницы (в отличие от offset)
+func (s *CatalogService) ListProducts(ctx context.Context, input ListProductsInput) (ListProductsOutput, error) {
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultProductPageSize
+	}
+	if pageSize > MaxProductPageSize {
+		pageSize = MaxProductPageSize
+	}
+	afterID, err := decodePageToken(input.PageToken)
+	if err != nil {
+		return ListProductsOutput{}, err
+	}
+
+	// Запрашиваем на один товар больше: так без отдельного count известно, есть ли следующая страница
+	products, err := s.products.ListProducts(ctx, repository.ProductFilter{
+		AfterID:        afterID,
+		Query:          strings.TrimSpace(input.Query),
+		Availability:   input.Availability,
+		IncludeDeleted: input.IncludeDeleted,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		log.Printf("ListProducts error: %v", err)
+		return ListProductsOutput{}, err
+	}
+
+	out := ListProductsOutput{Products: products}
+	if len(products) > pageSize {
+		out.Products = products[:pageSize]
+		out.NextPageToken = encodePageToken(out.Products[pageSize-1].ID)
+	}
+	return out, nil
+}
+
+// encodePageToken упаковывает ID последнего товара страницы в непрозрачный для клиента токен
+func encodePageToken(lastID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+// decodePageToken возвращает ID товара, после которого начинается страница
+func decodePageToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	lastID, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(lastID) == 0 {
+		return "", ErrInvalidPageToken
+	}
+	return string(lastID), nil
+}
+
+// productFromInput проверяет поля карточки и нормализует валюту
+func productFromInput(input ProductInput) (repository.Product, error) {
+	sku := strings.TrimSpace(input.SKU)
+	if sku == "" {
+		return repository.Product{}, ErrSKURequired
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return repository.Product{}, ErrNameRequired
+	}
+	if input.Price < 0 {
+		return repository.Product{}, ErrInvalidPrice
+	}
+	currency, err := normalizeCurrency(input.Currency)
+	if err != nil {
+		return repository.Product{}, err
+	}
+	if input.LowStockThreshold < 0 {
+		return repository.Product{}, ErrInvalidLowStockThreshold
+	}
+	if input.BackorderLimit < 0 {
+		return repository.Product{}, ErrInvalidBackorderLimit
+	}
+
+	return repository.Product{
+		ID:                input.ProductID,
+		SKU:               sku,
+		Name:              name,
+		Price:             input.Price,
+		Currency:          currency,
+		Attributes:        input.Attributes,
+		LowStockThreshold: input.LowStockThreshold,
+		BackorderLimit:    input.BackorderLimit,
+		Category:          NormalizeCategory(input.Category),
+	}, nil
+}
+
+// normalizeCurrency приводит код валюты к верхнему регистру и проверяет формат (три латинские буквы)
+// Пустое значение заменяется на DefaultCurrency
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency, nil
+	}
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return currency, nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestCatalogService_CreateProduct(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: generates id and normalizes currency", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("CreateProduct", ctx, mock.MatchedBy(func(p repository.Product) bool {
+			return p.ID != "" && p.SKU == "SKU-1" && p.Name == "Чайник" && p.Price == 199900 &&
+				p.Currency == "USD" && p.Attributes["color"] == "white" && !p.CreatedAt.IsZero() && p.CreatedAt.Equal(p.UpdatedAt)
+		})).Return(nil).Once()
+
+		product, err := service.CreateProduct(ctx, ProductInput{
+			SKU:        " SKU-1 ",
+			Name:       "Чайник",
+			Price:      199900,
+			Currency:   "usd",
+			Attributes: map[string]string{"color": "white"},
+		})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, product.ID)
+		require.Equal(t, "USD", product.Currency)
+	})
+
+	t.Run("keeps given id, empty currency defaults to RUB", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("CreateProduct", ctx, mock.MatchedBy(func(p repository.Product) bool {
+			return p.ID == "product-1" && p.Currency == DefaultCurrency
+		})).Return(nil).Once()
+
+		product, err := service.CreateProduct(ctx, ProductInput{ProductID: "product-1", SKU: "SKU-1", Name: "Чайник"})
+
+		require.NoError(t, err)
+		require.Equal(t, "product-1", product.ID)
+	})
+
+	t.Run("validation errors do not reach repository", func(t *testing.T) {
+		service := NewCatalogService(mocks.NewProductRepository(t))
+
+		cases := map[error]ProductInput{
+			ErrSKURequired:              {Name: "Чайник"},
+			ErrNameRequired:             {SKU: "SKU-1", Name: "  "},
+			ErrInvalidPrice:             {SKU: "SKU-1", Name: "Чайник", Price: -1},
+			ErrInvalidCurrency:          {SKU: "SKU-1", Name: "Чайник", Currency: "RUBL"},
+			ErrInvalidLowStockThreshold: {SKU: "SKU-1", Name: "Чайник", LowStockThreshold: -1},
+		}
+		for wantErr, input := range cases {
+			_, err := service.CreateProduct(ctx, input)
+			require.ErrorIs(t, err, wantErr)
+		}
+	})
+
+	t.Run("duplicate sku: error from repository", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("CreateProduct", ctx, mock.Anything).Return(repository.ErrProductAlreadyExists).Once()
+
+		_, err := service.CreateProduct(ctx, ProductInput{SKU: "SKU-1", Name: "Чайник"})
+
+		require.ErrorIs(t, err, repository.ErrProductAlreadyExists)
+	})
+}
+
+func TestCatalogService_UpdateProduct(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: replaces fields", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		updated := repository.Product{ID: "product-1", SKU: "SKU-2", Name: "Чайник 2", Price: 250000, Currency: "RUB"}
+		mockProducts.On("UpdateProduct", ctx, mock.MatchedBy(func(p repository.Product) bool {
+			return p.ID == "product-1" && p.SKU == "SKU-2" && p.Price == 250000 && !p.UpdatedAt.IsZero()
+		})).Return(updated, nil).Once()
+
+		product, err := service.UpdateProduct(ctx, ProductInput{ProductID: "product-1", SKU: "SKU-2", Name: "Чайник 2", Price: 250000})
+
+		require.NoError(t, err)
+		require.Equal(t, updated, product)
+	})
+
+	t.Run("product_id is required", func(t *testing.T) {
+		service := NewCatalogService(mocks.NewProductRepository(t))
+
+		_, err := service.UpdateProduct(ctx, ProductInput{SKU: "SKU-2", Name: "Чайник 2"})
+
+		require.ErrorIs(t, err, ErrProductIDRequired)
+	})
+
+	t.Run("not found: error from repository", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("UpdateProduct", ctx, mock.Anything).Return(repository.Product{}, repository.ErrNotFound).Once()
+
+		_, err := service.UpdateProduct(ctx, ProductInput{ProductID: "missing", SKU: "SKU-2", Name: "Чайник 2"})
+
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestCatalogService_DeleteProduct(t *testing.T) {
+	ctx := context.Background()
+
+	mockProducts := mocks.NewProductRepository(t)
+	service := NewCatalogService(mockProducts)
+
+	mockProducts.On("DeleteProduct", ctx, "product-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockProducts.On("DeleteProduct", ctx, "missing", mock.AnythingOfType("time.Time")).Return(repository.ErrNotFound).Once()
+
+	require.NoError(t, service.DeleteProduct(ctx, "product-1"))
+	require.ErrorIs(t, service.DeleteProduct(ctx, "missing"), repository.ErrNotFound)
+	require.ErrorIs(t, service.DeleteProduct(ctx, ""), ErrProductIDRequired)
+}
+
+func TestCatalogService_UndeleteProduct(t *testing.T) {
+	ctx := context.Background()
+
+	mockProducts := mocks.NewProductRepository(t)
+	service := NewCatalogService(mockProducts)
+
+	restored := repository.Product{ID: "product-1", SKU: "SKU-1", Name: "Чайник"}
+	mockProducts.On("UndeleteProduct", ctx, "product-1", mock.AnythingOfType("time.Time")).Return(restored, nil).Once()
+	mockProducts.On("UndeleteProduct", ctx, "missing", mock.AnythingOfType("time.Time")).Return(repository.Product{}, repository.ErrNotFound).Once()
+
+	product, err := service.UndeleteProduct(ctx, "product-1")
+	require.NoError(t, err)
+	require.Equal(t, restored, product)
+
+	_, err = service.UndeleteProduct(ctx, "missing")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = service.UndeleteProduct(ctx, "")
+	require.ErrorIs(t, err, ErrProductIDRequired)
+}
+
+func TestCatalogService_ListProducts(t *testing.T) {
+	ctx := context.Background()
+	products := func(ids ...string) []repository.Product {
+		result := make([]repository.Product, 0, len(ids))
+		for _, id := range ids {
+			result = append(result, repository.Product{ID: id})
+		}
+		return result
+	}
+
+	t.Run("full page: returns token of the last product", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("ListProducts", ctx, repository.ProductFilter{
+			Query:        "чайник",
+			Availability: repository.StockAvailabilityInStock,
+			Limit:        3,
+		}).Return(products("p1", "p2", "p3"), nil).Once()
+
+		page, err := service.ListProducts(ctx, ListProductsInput{PageSize: 2, Query: " чайник ", Availability: repository.StockAvailabilityInStock})
+
+		require.NoError(t, err)
+		require.Equal(t, products("p1", "p2"), page.Products)
+		require.NotEmpty(t, page.NextPageToken)
+
+		// Следующая страница начинается после последнего товара предыдущей
+		mockProducts.On("ListProducts", ctx, repository.ProductFilter{AfterID: "p2", Limit: 3}).
+			Return(products("p3"), nil).Once()
+
+		page, err = service.ListProducts(ctx, ListProductsInput{PageSize: 2, PageToken: page.NextPageToken})
+
+		require.NoError(t, err)
+		require.Equal(t, products("p3"), page.Products)
+		require.Empty(t, page.NextPageToken)
+	})
+
+	t.Run("page size defaults and is capped", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("ListProducts", ctx, repository.ProductFilter{Limit: DefaultProductPageSize + 1}).Return(nil, nil).Once()
+		mockProducts.On("ListProducts", ctx, repository.ProductFilter{Limit: MaxProductPageSize + 1}).Return(nil, nil).Once()
+
+		_, err := service.ListProducts(ctx, ListProductsInput{})
+		require.NoError(t, err)
+		_, err = service.ListProducts(ctx, ListProductsInput{PageSize: 1000})
+		require.NoError(t, err)
+	})
+
+	t.Run("include deleted passed to repository", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewCatalogService(mockProducts)
+
+		mockProducts.On("ListProducts", ctx, repository.ProductFilter{IncludeDeleted: true, Limit: 3}).
+			Return(products("p1"), nil).Once()
+
+		page, err := service.ListProducts(ctx, ListProductsInput{PageSize: 2, IncludeDeleted: true})
+
+		require.NoError(t, err)
+		require.Equal(t, products("p1"), page.Products)
+	})
+
+	t.Run("invalid page token does not reach repository", func(t *testing.T) {
+		service := NewCatalogService(mocks.NewProductRepository(t))
+
+		_, err := service.ListProducts(ctx, ListProductsInput{PageToken: "not base64!"})
+
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+	})
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// ImportActor - автор движений остатка, записанных импортом
+const ImportActor = "inventory-import"
+
+// Ошибки валидации записей импорта
+var (
+	ErrImportProductIDRequired = errors.New("product_id is required")
+	ErrInvalidImportStock      = errors.New("stock must not be negative")
+	ErrDuplicateImportStock    = errors.New("stock for this product and warehouse is set twice")
+	ErrConflictingImportRecord = errors.New("product fields differ from an earlier record with the same product_id")
+	ErrDuplicateImportSKU      = errors.New("sku is used by another product in the file")
+	ErrEmptyImport             = errors.New("nothing to import")
+)
+
+// ErrImportStockChanged возвращается, если остаток склада изменился между чтением и списанием разницы
+var ErrImportStockChanged = errors.New("stock changed during import, run it again")
+
+// ImportRecord - строка файла импорта: карточка товара и, опционально, целевой остаток на складе
+// Один товар может встречаться в нескольких записях с разными складами; поля карточки в них должны совпадать
+type ImportRecord struct {
+	Line        int // номер строки CSV или элемента JSON для сообщений об ошибках
+	Product     ProductInput
+	Stock       *int32 // целевой остаток на складе; nil - остаток не меняется
+	WarehouseID string // пусто - repository.DefaultWarehouseID
+}
+
+// ImportRecordError - ошибка валидации записи импорта
+type ImportRecordError struct {
+	Line      int
+	ProductID string
+	Err       error
+}
+
+func (e ImportRecordError) Error() string {
+	return fmt.Sprintf("line %d (product %q): %v", e.Line, e.ProductID, e.Err)
+}
+
+func (e ImportRecordError) Unwrap() error {
+	return e.Err
+}
+
+// ImportValidationError содержит ошибки всех невалидных записей: файл отклоняется целиком, до первой записи в хранилище
+type ImportValidationError struct {
+	Errors []ImportRecordError
+}
+
+func (e *ImportValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, recordErr := range e.Errors {
+		lines[i] = recordErr.Error()
+	}
+	return fmt.Sprintf("%d invalid records:\n%s", len(e.Errors), strings.Join(lines, "\n"))
+}
+
+func (e *ImportValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, recordErr := range e.Errors {
+		errs[i] = recordErr
+	}
+	return errs
+}
+
+// ImportStockChange - изменение остатка товара на складе до целевого значения
+type ImportStockChange struct {
+	ProductID   string
+	WarehouseID string
+	From        int32
+	To          int32
+}
+
+// ImportResult - итог импорта (или план, если импорт запущен с dryRun)
+type ImportResult struct {
+	Created      []string // ID созданных товаров
+	Updated      []string // ID товаров, карточка которых изменилась
+	Unchanged    int      // товары, карточка которых совпала с файлом
+	StockChanges []ImportStockChange
+}
+
+// ImportService загружает каталог и остатки из файла (cmd/inventory-import)
+// Импорт идемпотентен: карточки создаются или заменяются, остаток выставляется в целевое значение через
+// разницу с текущим, поэтому повторный запуск того же файла ничего не меняет.
+// Изменения остатка пишутся в журнал движений с причиной repository.MovementReasonImport.
+// Событий об остатке импорт не публикует, кеш остатка в Redis не сбрасывает (устареет не дольше TTL)
+type ImportService struct {
+	products   repository.ProductRepository
+	stock      repository.WarehouseStockRepository
+	warehouses repository.WarehouseRepository
+	movements  repository.MovementRepository
+}
+
+// NewImportService создаёт сервис импорта
+func NewImportService(products repository.ProductRepository, stock repository.WarehouseStockRepository, warehouses repository.WarehouseRepository, movements repository.MovementRepository) *ImportService {
+	return &ImportService{
+		products:   products,
+		stock:      stock,
+		warehouses: warehouses,
+		movements:  movements,
+	}
+}
+
+// importProduct - карточка товара из файла и склады, на которых выставляется остаток
+type importProduct struct {
+	product repository.Product
+	stock   []importStock
+}
+
+type importStock struct {
+	warehouseID string
+	target      int32
+}
+
+// Import проверяет все записи и, если ошибок нет, загружает их в хранилище
+// При ошибках валидации возвращает *ImportValidationError и ничего не пишет.
+// С dryRun только строит план: результат описывает, что изменил бы импорт.
+// Ошибка хранилища прерывает импорт; уже записанное остаётся, повторный запуск доделает остальное
+func (s *ImportService) Import(ctx context.Context, records []ImportRecord, dryRun bool) (ImportResult, error) {
+	log.Printf("Import called: records=%d, dry_run=%t", len(records), dryRun)
+
+	products, err := s.validate(ctx, records)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	var result ImportResult
+	for _, p := range products {
+		if err := s.importProduct(ctx, p.product, dryRun, &result); err != nil {
+			return result, fmt.Errorf("product %q: %w", p.product.ID, err)
+		}
+		for _, stock := range p.stock {
+			if err := s.importStock(ctx, p.product.ID, stock, dryRun, &result); err != nil {
+				return result, fmt.Errorf("product %q, warehouse %q: %w", p.product.ID, stock.warehouseID, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// validate проверяет записи и группирует их по товарам в порядке первого появления в файле
+func (s *ImportService) validate(ctx context.Context, records []ImportRecord) ([]*importProduct, error) {
+	if len(records) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	var (
+		errs       []ImportRecordError
+		order      []*importProduct
+		byID       = make(map[string]*importProduct)
+		skuOwners  = make(map[string]string)
+		warehouses = make(map[string]error) // результат проверки склада, чтобы не читать справочник на каждую запись
+	)
+	fail := func(record ImportRecord, err error) {
+		errs = append(errs, ImportRecordError{Line: record.Line, ProductID: record.Product.ProductID, Err: err})
+	}
+
+	for _, record := range records {
+		productID := strings.TrimSpace(record.Product.ProductID)
+		record.Product.ProductID = productID
+		if productID == "" {
+			fail(record, ErrImportProductIDRequired)
+			continue
+		}
+		product, err := productFromInput(record.Product)
+		if err != nil {
+			fail(record, err)
+			continue
+		}
+
+		p, seen := byID[productID]
+		if !seen {
+			if owner, taken := skuOwners[product.SKU]; taken {
+				fail(record, fmt.Errorf("%w: %q", ErrDuplicateImportSKU, owner))
+				continue
+			}
+			skuOwners[product.SKU] = productID
+			p = &importProduct{product: product}
+			byID[productID] = p
+			order = append(order, p)
+		} else if !sameProduct(p.product, product) {
+			fail(record, ErrConflictingImportRecord)
+			continue
+		}
+
+		if record.Stock == nil {
+			continue
+		}
+		if *record.Stock < 0 {
+			fail(record, ErrInvalidImportStock)
+			continue
+		}
+		warehouseID := strings.TrimSpace(record.WarehouseID)
+		if warehouseID == "" {
+			warehouseID = repository.DefaultWarehouseID
+		}
+		if err := s.checkWarehouse(ctx, warehouseID, warehouses); err != nil {
+			fail(record, err)
+			continue
+		}
+		if hasImportStock(p.stock, warehouseID) {
+			fail(record, ErrDuplicateImportStock)
+			continue
+		}
+		p.stock = append(p.stock, importStock{warehouseID: warehouseID, target: *record.Stock})
+	}
+
+	if len(errs) > 0 {
+		return nil, &ImportValidationError{Errors: errs}
+	}
+	return order, nil
+}
+
+// checkWarehouse проверяет ID склада и, кроме склада по умолчанию, его наличие в справочнике
+func (s *ImportService) checkWarehouse(ctx context.Context, warehouseID string, checked map[string]error) error {
+	if err, ok := checked[warehouseID]; ok {
+		return err
+	}
+	err := validateWarehouseID(warehouseID)
+	if err == nil && warehouseID != repository.DefaultWarehouseID {
+		_, err = s.warehouses.GetWarehouse(ctx, warehouseID)
+	}
+	if err != nil && !errors.Is(err, ErrInvalidWarehouseID) && !errors.Is(err, repository.ErrWarehouseNotFound) {
+		// Сбой хранилища не кешируем как результат проверки
+		return err
+	}
+	checked[warehouseID] = err
+	return err
+}
+
+// importProduct создаёт карточку или заменяет её, если она отличается от файла
+func (s *ImportService) importProduct(ctx context.Context, product repository.Product, dryRun bool, result *ImportResult) error {
+	current, err := s.products.GetProduct(ctx, product.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if !dryRun {
+			if err := s.products.CreateProduct(ctx, product); err != nil {
+				return err
+			}
+		}
+		result.Created = append(result.Created, product.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if sameProduct(current, product) {
+		result.Unchanged++
+		return nil
+	}
+	if !dryRun {
+		if _, err := s.products.UpdateProduct(ctx, product); err != nil {
+			return err
+		}
+	}
+	result.Updated = append(result.Updated, product.ID)
+	return nil
+}
+
+// importStock выставляет остаток склада в целевое значение и пишет движение в журнал
+func (s *ImportService) importStock(ctx context.Context, productID string, stock importStock, dryRun bool, result *ImportResult) error {
+	var current int32
+	stocks, err := s.stock.GetWarehouseStock(ctx, productID, repository.ReadConsistencyStrong)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	for _, ws := range stocks {
+		if ws.WarehouseID == stock.warehouseID {
+			current = ws.Quantity
+		}
+	}
+
+	delta := stock.target - current
+	if delta == 0 {
+		return nil
+	}
+	change := ImportStockChange{ProductID: productID, WarehouseID: stock.warehouseID, From: current, To: stock.target}
+	if dryRun {
+		result.StockChanges = append(result.StockChanges, change)
+		return nil
+	}
+
+	var available int32
+	if delta > 0 {
+		available, err = s.stock.AddWarehouseStock(ctx, productID, []repository.WarehouseStock{{WarehouseID: stock.warehouseID, Quantity: delta}})
+		if err != nil {
+			return err
+		}
+	} else {
+		var ok bool
+		available, ok, err = s.stock.ReserveWarehouseStock(ctx, productID, []repository.WarehouseStock{{WarehouseID: stock.warehouseID, Quantity: -delta}})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrImportStockChanged
+		}
+	}
+	result.StockChanges = append(result.StockChanges, change)
+
+	// Остаток уже изменён: сбой журнала только логируем, как и при применении корректировок
+	if err := s.movements.CreateMovements(ctx, []repository.StockMovement{{
+		ID:          uuid.NewString(),
+		ProductID:   productID,
+		WarehouseID: stock.warehouseID,
+		Delta:       delta,
+		Available:   available,
+		Reason:      repository.MovementReasonImport,
+		Actor:       ImportActor,
+		CreatedAt:   time.Now().UTC(),
+	}}); err != nil {
+		log.Printf("Import: failed to record stock movement: product=%s, error=%v", productID, err)
+	}
+	return nil
+}
+
+// sameProduct сравнивает поля карточки, которые задаёт файл импорта
+func sameProduct(a, b repository.Product) bool {
+	return a.SKU == b.SKU &&
+		a.Name == b.Name &&
+		a.Price == b.Price &&
+		a.Currency == b.Currency &&
+		a.LowStockThreshold == b.LowStockThreshold &&
+		a.BackorderLimit == b.BackorderLimit &&
+		a.Category == b.Category &&
+		maps.Equal(a.Attributes, b.Attributes)
+}
+
+func hasImportStock(stocks []importStock, warehouseID string) bool {
+	for _, stock := range stocks {
+		if stock.warehouseID == warehouseID {
+			return true
+		}
+	}
+	return false
+}
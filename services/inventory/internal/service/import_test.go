@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func stockPtr(v int32) *int32 {
+	return &v
+}
+
+func TestImportService_Import(t *testing.T) {
+	ctx := context.Background()
+	existing := repository.Product{ID: "product-2", SKU: "SKU-2", Name: "Mouse", Price: 500, Currency: "RUB"}
+	records := []ImportRecord{
+		{Line: 2, Product: ProductInput{ProductID: "product-1", SKU: "SKU-1", Name: "Keyboard", Price: 1000}, Stock: stockPtr(10)},
+		{Line: 3, Product: ProductInput{ProductID: "product-1", SKU: "SKU-1", Name: "Keyboard", Price: 1000}, Stock: stockPtr(4), WarehouseID: "msk"},
+		{Line: 4, Product: ProductInput{ProductID: "product-2", SKU: "SKU-2", Name: "Mouse", Price: 500}, Stock: stockPtr(3)},
+	}
+
+	t.Run("success: create, unchanged card, stock set to target", func(t *testing.T) {
+		products := mocks.NewProductRepository(t)
+		stock := mocks.NewWarehouseStockRepository(t)
+		warehouses := mocks.NewWarehouseRepository(t)
+		movements := mocks.NewMovementRepository(t)
+		service := NewImportService(products, stock, warehouses, movements)
+
+		warehouses.On("GetWarehouse", ctx, "msk").Return(repository.Warehouse{ID: "msk"}, nil).Once()
+		products.On("GetProduct", ctx, "product-1").Return(repository.Product{}, repository.ErrNotFound).Once()
+		products.On("CreateProduct", ctx, mock.MatchedBy(func(p repository.Product) bool {
+			return p.ID == "product-1" && p.Currency == DefaultCurrency
+		})).Return(nil).Once()
+		stock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).Return(nil, repository.ErrNotFound).Once()
+		stock.On("AddWarehouseStock", ctx, "product-1", []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 10}}).
+			Return(int32(10), nil).Once()
+		stock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 10}}, nil).Once()
+		stock.On("AddWarehouseStock", ctx, "product-1", []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 4}}).
+			Return(int32(14), nil).Once()
+		products.On("GetProduct", ctx, "product-2").Return(existing, nil).Once()
+		stock.On("GetWarehouseStock", ctx, "product-2", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 5}}, nil).Once()
+		stock.On("ReserveWarehouseStock", ctx, "product-2", []repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 2}}).
+			Return(int32(3), true, nil).Once()
+		movements.On("CreateMovements", ctx, mock.MatchedBy(func(m []repository.StockMovement) bool {
+			return len(m) == 1 && m[0].Reason == repository.MovementReasonImport && m[0].Actor == ImportActor
+		})).Return(nil).Times(3)
+
+		result, err := service.Import(ctx, records, false)
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"product-1"}, result.Created)
+		require.Empty(t, result.Updated)
+		require.Equal(t, 1, result.Unchanged)
+		require.Equal(t, []ImportStockChange{
+			{ProductID: "product-1", WarehouseID: repository.DefaultWarehouseID, From: 0, To: 10},
+			{ProductID: "product-1", WarehouseID: "msk", From: 0, To: 4},
+			{ProductID: "product-2", WarehouseID: repository.DefaultWarehouseID, From: 5, To: 3},
+		}, result.StockChanges)
+	})
+
+	t.Run("dry run: plan without writes", func(t *testing.T) {
+		products := mocks.NewProductRepository(t)
+		stock := mocks.NewWarehouseStockRepository(t)
+		service := NewImportService(products, stock, mocks.NewWarehouseRepository(t), mocks.NewMovementRepository(t))
+
+		changed := existing
+		changed.Price = 400
+		products.On("GetProduct", ctx, "product-2").Return(changed, nil).Once()
+		stock.On("GetWarehouseStock", ctx, "product-2", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 3}}, nil).Once()
+
+		result, err := service.Import(ctx, records[2:], true)
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"product-2"}, result.Updated)
+		require.Empty(t, result.StockChanges)
+	})
+
+	t.Run("stock changed concurrently", func(t *testing.T) {
+		products := mocks.NewProductRepository(t)
+		stock := mocks.NewWarehouseStockRepository(t)
+		service := NewImportService(products, stock, mocks.NewWarehouseRepository(t), mocks.NewMovementRepository(t))
+
+		products.On("GetProduct", ctx, "product-2").Return(existing, nil).Once()
+		stock.On("GetWarehouseStock", ctx, "product-2", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 5}}, nil).Once()
+		stock.On("ReserveWarehouseStock", ctx, "product-2", mock.Anything).Return(int32(1), false, nil).Once()
+
+		_, err := service.Import(ctx, records[2:], false)
+
+		require.ErrorIs(t, err, ErrImportStockChanged)
+	})
+}
+
+func TestImportService_Import_Validation(t *testing.T) {
+	ctx := context.Background()
+	keyboard := ProductInput{ProductID: "product-1", SKU: "SKU-1", Name: "Keyboard"}
+
+	tests := []struct {
+		name          string
+		records       []ImportRecord
+		expectedError error
+	}{
+		{
+			name:          "empty file",
+			expectedError: ErrEmptyImport,
+		},
+		{
+			name:          "no product_id",
+			records:       []ImportRecord{{Line: 2, Product: ProductInput{SKU: "SKU-1", Name: "Keyboard"}}},
+			expectedError: ErrImportProductIDRequired,
+		},
+		{
+			name:          "invalid card",
+			records:       []ImportRecord{{Line: 2, Product: ProductInput{ProductID: "product-1", SKU: "SKU-1"}}},
+			expectedError: ErrNameRequired,
+		},
+		{
+			name:          "negative stock",
+			records:       []ImportRecord{{Line: 2, Product: keyboard, Stock: stockPtr(-1)}},
+			expectedError: ErrInvalidImportStock,
+		},
+		{
+			name:          "invalid warehouse id",
+			records:       []ImportRecord{{Line: 2, Product: keyboard, Stock: stockPtr(1), WarehouseID: "msk.1"}},
+			expectedError: ErrInvalidWarehouseID,
+		},
+		{
+			name: "stock for the same warehouse twice",
+			records: []ImportRecord{
+				{Line: 2, Product: keyboard, Stock: stockPtr(1)},
+				{Line: 3, Product: keyboard, Stock: stockPtr(2), WarehouseID: repository.DefaultWarehouseID},
+			},
+			expectedError: ErrDuplicateImportStock,
+		},
+		{
+			name: "conflicting card for the same product",
+			records: []ImportRecord{
+				{Line: 2, Product: keyboard},
+				{Line: 3, Product: ProductInput{ProductID: "product-1", SKU: "SKU-1", Name: "Keyboard 2"}},
+			},
+			expectedError: ErrConflictingImportRecord,
+		},
+		{
+			name: "sku used by two products",
+			records: []ImportRecord{
+				{Line: 2, Product: keyboard},
+				{Line: 3, Product: ProductInput{ProductID: "product-2", SKU: "SKU-1", Name: "Mouse"}},
+			},
+			expectedError: ErrDuplicateImportSKU,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewImportService(mocks.NewProductRepository(t), mocks.NewWarehouseStockRepository(t),
+				mocks.NewWarehouseRepository(t), mocks.NewMovementRepository(t))
+
+			_, err := service.Import(ctx, tt.records, false)
+
+			require.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+
+	t.Run("all invalid records are reported", func(t *testing.T) {
+		warehouses := mocks.NewWarehouseRepository(t)
+		service := NewImportService(mocks.NewProductRepository(t), mocks.NewWarehouseStockRepository(t),
+			warehouses, mocks.NewMovementRepository(t))
+		warehouses.On("GetWarehouse", ctx, "spb").Return(repository.Warehouse{}, repository.ErrWarehouseNotFound).Once()
+
+		_, err := service.Import(ctx, []ImportRecord{
+			{Line: 2, Product: ProductInput{SKU: "SKU-1", Name: "Keyboard"}},
+			{Line: 3, Product: keyboard, Stock: stockPtr(1), WarehouseID: "spb"},
+			{Line: 4, Product: ProductInput{ProductID: "product-2", SKU: "SKU-2", Name: "Mouse"}, Stock: stockPtr(1), WarehouseID: "spb"},
+		}, false)
+
+		var validationErr *ImportValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Len(t, validationErr.Errors, 3)
+		require.Equal(t, 4, validationErr.Errors[2].Line)
+		require.ErrorIs(t, validationErr.Errors[2], repository.ErrWarehouseNotFound)
+	})
+}
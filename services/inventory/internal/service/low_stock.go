@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// EventTypeStockLow - тип события низкого остатка
+const EventTypeStockLow = "inventory.stock.low"
+
+// StockLowEvent - остаток товара опустился ниже порога карточки после резервирования
+type StockLowEvent struct {
+	EventID    string
+	OccurredAt time.Time
+	ProductID  string
+	SKU        string
+	Name       string
+	Available  int32 // остаток после резервирования
+	Threshold  int32 // LowStockThreshold карточки товара
+}
+
+// StockLowPublisher публикует события низкого остатка
+type StockLowPublisher interface {
+	PublishStockLow(ctx context.Context, event StockLowEvent) error
+}
+
+// LowStockMonitor следит за событиями изменения остатка и публикует inventory.stock.low,
+// когда резервирование опустило остаток ниже LowStockThreshold товара
+// Реализует StockEventPublisher: оборачивает публикацию inventory.stock.changed (next может быть nil)
+//
+// Событие публикуется только при пересечении порога: до резервирования остаток был не ниже порога, после - ниже.
+// Остаток после списания repository возвращает той же операцией, поэтому из конкурентных резервирований
+// порог пересекает ровно одно. Следующее событие - только после того, как остаток снова поднимется до порога
+type LowStockMonitor struct {
+	next     StockEventPublisher
+	products repository.ProductRepository
+	alerts   StockLowPublisher
+}
+
+// NewLowStockMonitor создаёт монитор низкого остатка
+// Порог читается из карточки товара на каждое резервирование: товар без карточки или с порогом 0 не проверяется
+func NewLowStockMonitor(next StockEventPublisher, products repository.ProductRepository, alerts StockLowPublisher) *LowStockMonitor {
+	return &LowStockMonitor{
+		next:     next,
+		products: products,
+		alerts:   alerts,
+	}
+}
+
+// PublishStockChanged передаёт событие дальше и проверяет порог низкого остатка
+// Возвращает только ошибку next: проблемы проверки порога логируются, как и остальная best-effort публикация
+func (m *LowStockMonitor) PublishStockChanged(ctx context.Context, event StockChangedEvent) error {
+	var err error
+	if m.next != nil {
+		err = m.next.PublishStockChanged(ctx, event)
+	}
+	m.checkThreshold(ctx, event)
+	return err
+}
+
+// checkThreshold публикует inventory.stock.low, если резервирование пересекло порог товара
+func (m *LowStockMonitor) checkThreshold(ctx context.Context, event StockChangedEvent) {
+	if event.Reason != StockChangeReserved || event.Available == nil {
+		return
+	}
+
+	product, err := m.products.GetProduct(ctx, event.ProductID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Low stock check failed: product=%s: %v", event.ProductID, err)
+		return
+	}
+
+	available := *event.Available
+	before := available - event.Delta // Delta резервирования отрицательная
+	if !crossedLowStockThreshold(before, available, product.LowStockThreshold) {
+		return
+	}
+
+	low := StockLowEvent{
+		EventID:    uuid.NewString(),
+		OccurredAt: time.Now().UTC(),
+		ProductID:  product.ID,
+		SKU:        product.SKU,
+		Name:       product.Name,
+		Available:  available,
+		Threshold:  product.LowStockThreshold,
+	}
+	log.Printf("Low stock: product=%s, available=%d, threshold=%d", low.ProductID, low.Available, low.Threshold)
+	if err := m.alerts.PublishStockLow(ctx, low); err != nil {
+		log.Printf("Failed to publish %s: product=%s, available=%d: %v", EventTypeStockLow, low.ProductID, low.Available, err)
+	}
+}
+
+// crossedLowStockThreshold проверяет, что остаток опустился ниже порога: before >= threshold > after
+// threshold <= 0 - порог не задан
+func crossedLowStockThreshold(before, after, threshold int32) bool {
+	return threshold > 0 && after < threshold && before >= threshold
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+// fakeStockLow запоминает опубликованные события низкого остатка
+type fakeStockLow struct {
+	events []StockLowEvent
+}
+
+func (p *fakeStockLow) PublishStockLow(ctx context.Context, event StockLowEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func reservedEvent(productID string, quantity, available int32) StockChangedEvent {
+	return StockChangedEvent{ProductID: productID, Delta: -quantity, Reason: StockChangeReserved, Available: &available}
+}
+
+func TestLowStockMonitor(t *testing.T) {
+	ctx := context.Background()
+	product := repository.Product{ID: "product-1", SKU: "SKU-1", Name: "Чайник", LowStockThreshold: 5}
+
+	t.Run("reservation crossing threshold publishes stock low", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		next := &fakeStockEvents{}
+		alerts := &fakeStockLow{}
+		monitor := NewLowStockMonitor(next, mockProducts, alerts)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(product, nil).Once()
+
+		err := monitor.PublishStockChanged(ctx, reservedEvent("product-1", 3, 4)) // 7 -> 4
+
+		require.NoError(t, err)
+		require.Len(t, next.events, 1)
+		require.Len(t, alerts.events, 1)
+		event := alerts.events[0]
+		require.NotEmpty(t, event.EventID)
+		require.False(t, event.OccurredAt.IsZero())
+		require.Equal(t, "product-1", event.ProductID)
+		require.Equal(t, "SKU-1", event.SKU)
+		require.Equal(t, "Чайник", event.Name)
+		require.Equal(t, int32(4), event.Available)
+		require.Equal(t, int32(5), event.Threshold)
+	})
+
+	t.Run("no event without crossing", func(t *testing.T) {
+		cases := map[string]StockChangedEvent{
+			"stays at threshold":   reservedEvent("product-1", 2, 5), // 7 -> 5
+			"already below before": reservedEvent("product-1", 1, 3), // 4 -> 3
+		}
+		for name, changed := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockProducts := mocks.NewProductRepository(t)
+				alerts := &fakeStockLow{}
+				monitor := NewLowStockMonitor(nil, mockProducts, alerts)
+
+				mockProducts.On("GetProduct", ctx, "product-1").Return(product, nil).Once()
+
+				require.NoError(t, monitor.PublishStockChanged(ctx, changed))
+				require.Empty(t, alerts.events)
+			})
+		}
+	})
+
+	t.Run("product without card or threshold is not checked", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		alerts := &fakeStockLow{}
+		monitor := NewLowStockMonitor(nil, mockProducts, alerts)
+
+		mockProducts.On("GetProduct", ctx, "no-card").Return(repository.Product{}, repository.ErrNotFound).Once()
+		mockProducts.On("GetProduct", ctx, "no-threshold").Return(repository.Product{ID: "no-threshold"}, nil).Once()
+
+		require.NoError(t, monitor.PublishStockChanged(ctx, reservedEvent("no-card", 10, 0)))
+		require.NoError(t, monitor.PublishStockChanged(ctx, reservedEvent("no-threshold", 10, 0)))
+		require.Empty(t, alerts.events)
+	})
+
+	t.Run("only reservations are checked", func(t *testing.T) {
+		alerts := &fakeStockLow{}
+		monitor := NewLowStockMonitor(nil, mocks.NewProductRepository(t), alerts)
+
+		available := int32(1)
+		err := monitor.PublishStockChanged(ctx, StockChangedEvent{ProductID: "product-1", Delta: 1, Reason: StockChangeReleased, Available: &available})
+
+		require.NoError(t, err)
+		require.Empty(t, alerts.events)
+	})
+
+	t.Run("consecutive reservations cross threshold once", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		alerts := &fakeStockLow{}
+		service := NewInventoryService(mockRepo, nil, nil, NewLowStockMonitor(nil, mockProducts, alerts), nil, nil, nil, nil)
+
+		// Остаток 6, порог 5: два резервирования по 1 - порог пересекает только второе (6 -> 5 -> 4)
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(5), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(4), true, nil).Once()
+		mockProducts.On("GetProduct", ctx, "product-1").Return(product, nil).Twice()
+
+		for i := 0; i < 2; i++ {
+			reserved, err := service.ReserveStock(ctx, "product-1", 1)
+			require.NoError(t, err)
+			require.True(t, reserved)
+		}
+
+		require.Len(t, alerts.events, 1)
+		require.Equal(t, int32(4), alerts.events[0].Available)
+	})
+}
+
+func TestCrossedLowStockThreshold(t *testing.T) {
+	require.True(t, crossedLowStockThreshold(5, 4, 5))
+	require.True(t, crossedLowStockThreshold(10, 0, 3))
+	require.False(t, crossedLowStockThreshold(6, 5, 5))
+	require.False(t, crossedLowStockThreshold(4, 3, 5))
+	require.False(t, crossedLowStockThreshold(10, 0, 0))
+}
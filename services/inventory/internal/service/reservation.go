@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// expireReservationsBatch - сколько истёкших резервов sweeper обрабатывает за одну выборку
+const expireReservationsBatch = 100
+
+// maxSweepBatches ограничивает число выборок за один проход sweeper'а, чтобы проход не растягивался
+// на большой накопленный хвост: остаток заберёт следующий тик
+const maxSweepBatches = 10
+
+// ErrReservationIDRequired возвращается, если reservation_id не передан (handler маппит в codes.InvalidArgument)
+var ErrReservationIDRequired = errors.New("reservation_id is required")
+
+// ErrOrderIDRequired возвращается, если не передан order_id заказа, резервы которого снимаются
+var ErrOrderIDRequired = errors.New("order_id is required")
+
+// ErrEmptyBatch возвращается, если в пакетном резервировании нет позиций (handler маппит в codes.InvalidArgument)
+var ErrEmptyBatch = errors.New("items must not be empty")
+
+// ErrInvalidTTL возвращается, если срок резерва отрицательный (handler маппит в codes.InvalidArgument)
+var ErrInvalidTTL = errors.New("ttl must not be negative")
+
+// ErrIdempotencyKeyReused возвращается, если ключ идемпотентности уже использован для резерва с другим количеством
+// или, в пакетном резервировании, с другим набором товаров (handler маппит в codes.AlreadyExists)
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used with a different quantity")
+
+// ErrInvalidReservationStatus возвращается, если в фильтре резервов неизвестный статус
+var ErrInvalidReservationStatus = errors.New("reservation status must be active, released or expired")
+
+// Размер выборки списков (резервы, корректировки, движения): по умолчанию и максимальный
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// listLimit приводит limit из запроса к допустимому: <= 0 - defaultListLimit, больше maxListLimit - maxListLimit
+func listLimit(limit int) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	return min(limit, maxListLimit)
+}
+
+// CreateReservationInput содержит входные данные для резервирования
+type CreateReservationInput struct {
+	ProductID string
+	Quantity  int32
+	OrderID   string             // заказ, под который резервируется товар (необязательно)
+	TTL       time.Duration      // 0 - срок по категории товара (ReservationTTLs), без политики - резерв без срока
+	Strategy  AllocationStrategy // распределение по складам; пусто - из конфига
+	// IdempotencyKey - ключ идемпотентности; пусто - OrderID. Без ключа и заказа резерв не идемпотентен
+	IdempotencyKey string
+}
+
+// CreateReservation списывает товар с остатка (ReserveStock) и сохраняет резерв с ID, сроком и распределением по складам
+// Возвращает reserved=false без ошибки, если товара недостаточно (резерв не создаётся)
+// Идемпотентен по ключу (IdempotencyKey или OrderID) и товару: если резерв уже есть в любом статусе,
+// товар не списывается повторно и возвращается исходный резерв - так повторы Order не резервируют товар дважды
+// Резерв сверх остатка (предзаказ) попадает в журнал предзаказов только после сохранения резерва
+// Если резерв не удалось сохранить, списанный товар возвращается в остаток
+func (s *InventoryService) CreateReservation(ctx context.Context, input CreateReservationInput) (repository.Reservation, bool, error) {
+	if input.ProductID == "" {
+		return repository.Reservation{}, false, ErrProductIDRequired
+	}
+	if input.Quantity <= 0 {
+		return repository.Reservation{}, false, ErrInvalidQuantity
+	}
+	if input.TTL < 0 {
+		return repository.Reservation{}, false, ErrInvalidTTL
+	}
+	if err := validateAllocationStrategy(input.Strategy); err != nil {
+		return repository.Reservation{}, false, err
+	}
+
+	key := input.IdempotencyKey
+	if key == "" {
+		key = input.OrderID
+	}
+	if key != "" {
+		existing, err := s.reservations.GetReservationByIdempotencyKey(ctx, key, input.ProductID)
+		if err == nil {
+			return replayReservation(existing, input.Quantity)
+		}
+		if !errors.Is(err, repository.ErrReservationNotFound) {
+			return repository.Reservation{}, false, fmt.Errorf("failed to get reservation by idempotency key: %w", err)
+		}
+	}
+
+	ttl, err := s.reservationTTL(ctx, input.ProductID, input.TTL)
+	if err != nil {
+		return repository.Reservation{}, false, err
+	}
+
+	result, ok, err := s.reserve(ctx, input.ProductID, input.Quantity, input.Strategy)
+	if err != nil || !ok {
+		return repository.Reservation{}, ok, err
+	}
+
+	reservation := newReservation(input.OrderID, input.ProductID, input.Quantity, result, time.Now().UTC(), ttl)
+	reservation.IdempotencyKey = key
+	if err := s.reservations.CreateReservation(ctx, reservation); err != nil {
+		s.returnStock(ctx, []repository.Reservation{reservation})
+		// Параллельный повтор с тем же ключом успел сохранить резерв раньше: отдаём его
+		if errors.Is(err, repository.ErrReservationAlreadyExists) {
+			existing, getErr := s.reservations.GetReservationByIdempotencyKey(ctx, key, input.ProductID)
+			if getErr == nil {
+				return replayReservation(existing, input.Quantity)
+			}
+			err = getErr
+		}
+		log.Printf("CreateReservation error: product=%s, quantity=%d: %v", input.ProductID, input.Quantity, err)
+		return repository.Reservation{}, false, fmt.Errorf("failed to save reservation: %w", err)
+	}
+
+	log.Printf("Reservation created: id=%s, order=%s, product=%s, quantity=%d, expires_at=%v",
+		reservation.ID, reservation.OrderID, reservation.ProductID, reservation.Quantity, reservation.ExpiresAt)
+	s.recordBackorder(ctx, reservation, result)
+	return reservation, true, nil
+}
+
+// replayReservation возвращает уже существующий резерв на повтор CreateReservation с тем же ключом
+// Другое количество - не повтор, а новый запрос с чужим ключом: ErrIdempotencyKeyReused
+func replayReservation(existing repository.Reservation, quantity int32) (repository.Reservation, bool, error) {
+	if existing.Quantity != quantity {
+		return repository.Reservation{}, false, ErrIdempotencyKeyReused
+	}
+	log.Printf("Reservation replayed: id=%s, key=%s, product=%s, status=%s",
+		existing.ID, existing.IdempotencyKey, existing.ProductID, existing.Status)
+	return existing, true, nil
+}
+
+// BatchItem - позиция пакетного резервирования
+type BatchItem struct {
+	ProductID string
+	Quantity  int32
+}
+
+// ReserveStockBatchInput содержит входные данные для пакетного резервирования
+type ReserveStockBatchInput struct {
+	OrderID  string
+	Items    []BatchItem
+	TTL      time.Duration      // срок всех резервов; 0 - срок по категории каждого товара, без политики - без срока
+	Strategy AllocationStrategy // распределение каждой позиции по складам; пусто - из конфига
+}
+
+// ReserveStockBatchOutput содержит результат пакетного резервирования
+type ReserveStockBatchOutput struct {
+	Reservations          []repository.Reservation // по одному на товар, пусто, если резервирование не удалось
+	InsufficientProductID string                   // первый товар, которого не хватило
+}
+
+// ReserveStockBatch резервирует все позиции заказа по принципу всё или ничего
+// Позиции с одинаковым product_id суммируются. Товары списываются по очереди;
+// если какого-то не хватило или запрос упал, уже списанные позиции возвращаются в остаток
+// Возвращает reserved=false без ошибки, если товара недостаточно (InsufficientProductID - какого именно)
+// Идемпотентен по OrderID: если резервы заказа уже есть по всем позициям с тем же количеством, товар не списывается
+// повторно и возвращаются исходные резервы (повтор Order); другой набор позиций - ErrIdempotencyKeyReused
+func (s *InventoryService) ReserveStockBatch(ctx context.Context, input ReserveStockBatchInput) (ReserveStockBatchOutput, bool, error) {
+	log.Printf("ReserveStockBatch called: order=%s, items=%d", input.OrderID, len(input.Items))
+
+	items, err := mergeBatchItems(input.Items)
+	if err != nil {
+		return ReserveStockBatchOutput{}, false, err
+	}
+	if input.TTL < 0 {
+		return ReserveStockBatchOutput{}, false, ErrInvalidTTL
+	}
+	if err := validateAllocationStrategy(input.Strategy); err != nil {
+		return ReserveStockBatchOutput{}, false, err
+	}
+
+	if existing, ok, err := s.replayBatch(ctx, input.OrderID, items); err != nil || ok {
+		return ReserveStockBatchOutput{Reservations: existing}, ok, err
+	}
+
+	// Сроки определяем до списания, чтобы ошибка чтения каталога не требовала отката
+	ttls := make([]time.Duration, len(items))
+	for i, item := range items {
+		ttl, err := s.reservationTTL(ctx, item.ProductID, input.TTL)
+		if err != nil {
+			return ReserveStockBatchOutput{}, false, err
+		}
+		ttls[i] = ttl
+	}
+
+	// 1. Списываем товары; при первой неудаче откатываем уже списанные
+	now := time.Now().UTC()
+	reserved := make([]repository.Reservation, 0, len(items))
+	results := make([]reserveResult, 0, len(items))
+	for i, item := range items {
+		result, ok, err := s.reserve(ctx, item.ProductID, item.Quantity, input.Strategy)
+		if err != nil || !ok {
+			s.returnStock(ctx, reserved)
+			if err != nil {
+				return ReserveStockBatchOutput{}, false, fmt.Errorf("failed to reserve product %s: %w", item.ProductID, err)
+			}
+			log.Printf("ReserveStockBatch failed: order=%s, insufficient stock for product=%s", input.OrderID, item.ProductID)
+			return ReserveStockBatchOutput{InsufficientProductID: item.ProductID}, false, nil
+		}
+		reservation := newReservation(input.OrderID, item.ProductID, item.Quantity, result, now, ttls[i])
+		reservation.IdempotencyKey = input.OrderID
+		reserved = append(reserved, reservation)
+		results = append(results, result)
+	}
+
+	// 2. Сохраняем резервы; если не удалось, снимаем уже сохранённые и возвращаем товары в остаток
+	reservations := make([]repository.Reservation, 0, len(items))
+	for _, reservation := range reserved {
+		if err := s.reservations.CreateReservation(ctx, reservation); err != nil {
+			log.Printf("ReserveStockBatch error: order=%s, product=%s: %v", input.OrderID, reservation.ProductID, err)
+			for _, saved := range reservations {
+				if _, finishErr := s.reservations.FinishReservation(ctx, saved.ID, repository.ReservationStatusReleased); finishErr != nil {
+					log.Printf("ReserveStockBatch: failed to release reservation %s: %v", saved.ID, finishErr)
+				}
+			}
+			s.returnStock(ctx, reserved)
+			// Параллельный повтор того же заказа успел сохранить резервы раньше: отдаём их
+			if errors.Is(err, repository.ErrReservationAlreadyExists) {
+				if existing, ok, replayErr := s.replayBatch(ctx, input.OrderID, items); replayErr != nil || ok {
+					return ReserveStockBatchOutput{Reservations: existing}, ok, replayErr
+				}
+			}
+			return ReserveStockBatchOutput{}, false, fmt.Errorf("failed to save reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	for i, reservation := range reservations {
+		s.recordBackorder(ctx, reservation, results[i])
+	}
+	log.Printf("ReserveStockBatch successful: order=%s, reservations=%d", input.OrderID, len(reservations))
+	return ReserveStockBatchOutput{Reservations: reservations}, true, nil
+}
+
+// replayBatch ищет резервы заказа orderID по всем позициям items для повтора ReserveStockBatch
+// ok=false без ошибки - резервов заказа нет (или заказ не указан), резервируем как обычно
+// Резервы есть не по всем позициям или с другим количеством - ErrIdempotencyKeyReused
+func (s *InventoryService) replayBatch(ctx context.Context, orderID string, items []BatchItem) ([]repository.Reservation, bool, error) {
+	if orderID == "" {
+		return nil, false, nil
+	}
+
+	existing := make([]repository.Reservation, 0, len(items))
+	for _, item := range items {
+		reservation, err := s.reservations.GetReservationByIdempotencyKey(ctx, orderID, item.ProductID)
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get reservation by idempotency key: %w", err)
+		}
+		if reservation.Quantity != item.Quantity {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		existing = append(existing, reservation)
+	}
+
+	switch len(existing) {
+	case 0:
+		return nil, false, nil
+	case len(items):
+		log.Printf("ReserveStockBatch replayed: order=%s, reservations=%d", orderID, len(existing))
+		return existing, true, nil
+	default:
+		return nil, false, ErrIdempotencyKeyReused
+	}
+}
+
+// mergeBatchItems проверяет позиции и суммирует повторяющиеся product_id, сохраняя порядок первого появления
+func mergeBatchItems(items []BatchItem) ([]BatchItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	merged := make([]BatchItem, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		if item.ProductID == "" {
+			return nil, ErrProductIDRequired
+		}
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
+
+// returnStock возвращает в остаток товары откатываемого резервирования (ошибки только логируются)
+// Откат не зависит от отмены ctx: клиент мог уйти, а списанный товар вернуть всё равно нужно
+func (s *InventoryService) returnStock(ctx context.Context, reserved []repository.Reservation) {
+	ctx = context.WithoutCancel(ctx)
+	for _, reservation := range reserved {
+		available, err := s.addReservedStock(ctx, reservation)
+		if err != nil {
+			log.Printf("Failed to return stock: product=%s, quantity=%d: %v", reservation.ProductID, reservation.Quantity, err)
+			continue
+		}
+		s.publishStockChanged(ctx, StockChangedEvent{ProductID: reservation.ProductID, Delta: reservation.Quantity, Reason: StockChangeReleased, Available: &available})
+	}
+}
+
+// addReservedStock возвращает товар резерва в остаток: на те склады, с которых он списан,
+// или в общий остаток, если резерв без распределения (склады не настроены или резерв создан до их появления)
+func (s *InventoryService) addReservedStock(ctx context.Context, reservation repository.Reservation) (int32, error) {
+	if len(reservation.Allocations) > 0 && s.allocator != nil {
+		return s.allocator.stock.AddWarehouseStock(ctx, reservation.ProductID, reservation.Allocations)
+	}
+	return s.repo.AddStock(ctx, reservation.ProductID, reservation.Quantity)
+}
+
+// newReservation создаёт активный резерв по итогу резервирования; ttl = 0 - без срока
+func newReservation(orderID, productID string, quantity int32, result reserveResult, now time.Time, ttl time.Duration) repository.Reservation {
+	reservation := repository.Reservation{
+		ID:          uuid.NewString(),
+		OrderID:     orderID,
+		ProductID:   productID,
+		Quantity:    quantity,
+		Status:      repository.ReservationStatusActive,
+		CreatedAt:   now,
+		Allocations: result.allocations,
+		Backordered: result.backordered,
+	}
+	if ttl > 0 {
+		reservation.ExpiresAt = now.Add(ttl)
+	}
+	return reservation
+}
+
+// ReleaseReservation снимает активный резерв и возвращает товар в остаток
+// Возвращает repository.ErrReservationNotFound или repository.ErrReservationNotActive (уже снят или истёк)
+func (s *InventoryService) ReleaseReservation(ctx context.Context, reservationID string) (repository.Reservation, error) {
+	log.Printf("ReleaseReservation called: id=%s", reservationID)
+
+	if reservationID == "" {
+		return repository.Reservation{}, ErrReservationIDRequired
+	}
+
+	return s.finishReservation(ctx, reservationID, repository.ReservationStatusReleased)
+}
+
+// ReleaseOrderReservations снимает все активные резервы заказа (компенсация отмены или истечения заказа)
+// Повторный вызов ничего не снимает: резерв, уже снятый или истёкший, пропускается
+// Возвращает количество снятых резервов
+func (s *InventoryService) ReleaseOrderReservations(ctx context.Context, orderID string) (int, error) {
+	log.Printf("ReleaseOrderReservations called: order=%s", orderID)
+
+	if orderID == "" {
+		return 0, ErrOrderIDRequired
+	}
+
+	filter := repository.ReservationFilter{OrderID: orderID, Status: repository.ReservationStatusActive}
+	released := 0
+	for {
+		active, err := s.reservations.ListReservations(ctx, filter, maxListLimit)
+		if err != nil {
+			return released, fmt.Errorf("failed to list order reservations: %w", err)
+		}
+
+		for _, reservation := range active {
+			_, err := s.finishReservation(ctx, reservation.ID, repository.ReservationStatusReleased)
+			if errors.Is(err, repository.ErrReservationNotActive) || errors.Is(err, repository.ErrReservationNotFound) {
+				continue
+			}
+			if err != nil {
+				return released, err
+			}
+			released++
+		}
+
+		// Снятые резервы выпадают из выборки активных, поэтому неполная выборка - последняя
+		if len(active) < maxListLimit {
+			return released, nil
+		}
+	}
+}
+
+// GetReservation возвращает резерв в любом статусе
+// Возвращает repository.ErrReservationNotFound, если резерва нет
+func (s *InventoryService) GetReservation(ctx context.Context, reservationID string) (repository.Reservation, error) {
+	if reservationID == "" {
+		return repository.Reservation{}, ErrReservationIDRequired
+	}
+	return s.reservations.GetReservation(ctx, reservationID)
+}
+
+// ListReservations возвращает резервы под filter, новые первыми
+// limit <= 0 - 50, больше 200 - обрезается до 200
+func (s *InventoryService) ListReservations(ctx context.Context, filter repository.ReservationFilter, limit int) ([]repository.Reservation, error) {
+	switch filter.Status {
+	case "", repository.ReservationStatusActive, repository.ReservationStatusReleased, repository.ReservationStatusExpired:
+	default:
+		return nil, ErrInvalidReservationStatus
+	}
+	return s.reservations.ListReservations(ctx, filter, listLimit(limit))
+}
+
+// ExpireReservations возвращает в остаток товар истёкших к now резервов
+// Резерв, который одновременно сняли через ReleaseReservation, пропускается: товар уже возвращён
+// Возвращает количество истёкших резервов
+func (s *InventoryService) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	result, _, err := s.expireReservations(ctx, now)
+	return result.Expired, err
+}
+
+// expireReservations обрабатывает одну выборку истёкших резервов (до expireReservationsBatch)
+// Возвращает итог выборки и сколько резервов в неё попало, чтобы sweeper понял, остались ли ещё
+func (s *InventoryService) expireReservations(ctx context.Context, now time.Time) (ReservationSweepResult, int, error) {
+	var result ReservationSweepResult
+	expired, err := s.reservations.ListExpiredReservations(ctx, now, expireReservationsBatch)
+	if err != nil {
+		return result, 0, fmt.Errorf("failed to list expired reservations: %w", err)
+	}
+
+	for _, reservation := range expired {
+		finished, err := s.finishReservation(ctx, reservation.ID, repository.ReservationStatusExpired)
+		if errors.Is(err, repository.ErrReservationNotActive) || errors.Is(err, repository.ErrReservationNotFound) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, len(expired), err
+		}
+		result.Expired++
+		result.Quantity += int64(finished.Quantity)
+	}
+
+	return result, len(expired), nil
+}
+
+// finishReservation завершает резерв со статусом status и возвращает товар в остаток
+// Статус меняется первым: если AddStock не удался, товар не вернётся (ошибка в логе), но и не вернётся дважды
+// Товар возвращается на те склады, с которых был списан
+func (s *InventoryService) finishReservation(ctx context.Context, reservationID, status string) (repository.Reservation, error) {
+	reservation, err := s.reservations.FinishReservation(ctx, reservationID, status)
+	if err != nil {
+		return repository.Reservation{}, err
+	}
+
+	available, err := s.addReservedStock(ctx, reservation)
+	if err != nil {
+		log.Printf("Reservation %s %s, but stock was not returned: product=%s, quantity=%d: %v",
+			reservationID, status, reservation.ProductID, reservation.Quantity, err)
+		return repository.Reservation{}, fmt.Errorf("failed to return reserved stock: %w", err)
+	}
+
+	reason := StockChangeReleased
+	if status == repository.ReservationStatusExpired {
+		reason = StockChangeExpired
+	}
+	s.publishStockChanged(ctx, StockChangedEvent{
+		ProductID:     reservation.ProductID,
+		Delta:         reservation.Quantity,
+		Reason:        reason,
+		Available:     &available,
+		ReservationID: reservation.ID,
+		OrderID:       reservation.OrderID,
+	})
+
+	log.Printf("Reservation %s: id=%s, product=%s, quantity=%d returned to stock", status, reservationID, reservation.ProductID, reservation.Quantity)
+	return reservation, nil
+}
+
+// ReservationSweepResult - итог одного прохода sweeper'а
+type ReservationSweepResult struct {
+	Expired  int           // резервы, которые истекли и вернули товар в остаток
+	Quantity int64         // сколько единиц товара вернулось в остаток
+	Skipped  int           // резервы, одновременно снятые через ReleaseReservation
+	Duration time.Duration // длительность прохода
+}
+
+// ReservationSweepMetricsRecorder записывает метрики sweeper'а (опционально, может быть nil).
+type ReservationSweepMetricsRecorder interface {
+	// RecordReservationSweep записывает итог прохода; err != nil - проход прерван ошибкой
+	RecordReservationSweep(result ReservationSweepResult, err error)
+}
+
+// ReservationSweeper периодически возвращает в остаток товар истёкших резервов
+// Срок есть у резервов, созданных с ttl: если заказ так и не оплатили и резерв не сняли,
+// товар вернётся в остаток после expires_at. Резервы без срока sweeper не трогает
+type ReservationSweeper struct {
+	service  *InventoryService
+	interval time.Duration
+	metrics  ReservationSweepMetricsRecorder
+}
+
+// NewReservationSweeper создаёт sweeper истёкших резервов
+// metrics может быть nil (метрики не пишутся)
+func NewReservationSweeper(service *InventoryService, interval time.Duration, metrics ReservationSweepMetricsRecorder) *ReservationSweeper {
+	return &ReservationSweeper{
+		service:  service,
+		interval: interval,
+		metrics:  metrics,
+	}
+}
+
+// Start запускает проверку по таймеру до отмены контекста
+func (w *ReservationSweeper) Start(ctx context.Context) error {
+	log.Printf("Starting reservation sweeper: interval=%s", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Reservation sweeper stopped")
+			return nil
+		case <-ticker.C:
+			if _, err := w.Sweep(ctx, time.Now().UTC()); err != nil && ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// Sweep возвращает в остаток товар резервов, истёкших к now: выборками, пока они заполняются целиком,
+// но не больше maxSweepBatches. Итог прохода пишется в лог и в метрики
+func (w *ReservationSweeper) Sweep(ctx context.Context, now time.Time) (ReservationSweepResult, error) {
+	start := time.Now()
+	var result ReservationSweepResult
+	var err error
+	for i := 0; i < maxSweepBatches; i++ {
+		batch, listed, batchErr := w.service.expireReservations(ctx, now)
+		result.Expired += batch.Expired
+		result.Quantity += batch.Quantity
+		result.Skipped += batch.Skipped
+		if batchErr != nil {
+			err = batchErr
+			break
+		}
+		if listed < expireReservationsBatch {
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if w.metrics != nil {
+		w.metrics.RecordReservationSweep(result, err)
+	}
+	switch {
+	case err != nil && ctx.Err() == nil:
+		log.Printf("Reservation sweeper error after %d expired reservations (%d units): %v", result.Expired, result.Quantity, err)
+	case result.Expired > 0 || result.Skipped > 0:
+		log.Printf("Reservation sweeper: %d expired reservations returned to stock (%d units), %d already released, took %s",
+			result.Expired, result.Quantity, result.Skipped, result.Duration)
+	}
+	return result, err
+}
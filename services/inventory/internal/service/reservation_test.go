@@ -0,0 +1,574 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestInventoryService_CreateReservation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: stock reserved and reservation saved with expiry", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Once()
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(2)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ID != "" && r.OrderID == "order-1" && r.ProductID == "product-1" && r.Quantity == 2 &&
+				r.Status == repository.ReservationStatusActive && r.ExpiresAt.Sub(r.CreatedAt) == time.Minute &&
+				r.IdempotencyKey == "order-1"
+		})).Return(nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{
+			ProductID: "product-1",
+			Quantity:  2,
+			OrderID:   "order-1",
+			TTL:       time.Minute,
+		})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.NotEmpty(t, reservation.ID)
+		require.False(t, reservation.ExpiresAt.IsZero())
+	})
+
+	t.Run("no TTL: reservation without expiry", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ExpiresAt.IsZero()
+		})).Return(nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.True(t, reservation.ExpiresAt.IsZero())
+	})
+
+	t.Run("insufficient stock: no reservation is saved", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(100)).Return(int32(0), false, nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 100})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.Empty(t, reservation.ID)
+	})
+
+	t.Run("save failure returns stock", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(3)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.Anything).Return(errors.New("insert failed")).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(3)).Return(int32(10), nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 3})
+
+		require.ErrorContains(t, err, "insert failed")
+		require.False(t, reserved)
+	})
+
+	t.Run("retry with the same order returns original reservation without reserving stock", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		original := repository.Reservation{ID: "res-1", OrderID: "order-1", IdempotencyKey: "order-1", ProductID: "product-1", Quantity: 2, Status: repository.ReservationStatusActive}
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").Return(original, nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 2, OrderID: "order-1"})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Equal(t, original, reservation)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("explicit idempotency key takes precedence over order", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "key-1", "product-1").
+			Return(repository.Reservation{ID: "res-1", Quantity: 3}, nil).Once()
+
+		_, _, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 2, OrderID: "order-1", IdempotencyKey: "key-1"})
+
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	})
+
+	t.Run("concurrent retry: stock returned and winner reservation returned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		winner := repository.Reservation{ID: "res-winner", IdempotencyKey: "order-1", ProductID: "product-1", Quantity: 2, Status: repository.ReservationStatusActive}
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Once()
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(2)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.Anything).Return(repository.ErrReservationAlreadyExists).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(2)).Return(int32(10), nil).Once()
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").Return(winner, nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 2, OrderID: "order-1"})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Equal(t, "res-winner", reservation.ID)
+	})
+
+	t.Run("validation errors do not reach repository", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		_, _, err := service.CreateReservation(ctx, CreateReservationInput{Quantity: 1})
+		require.ErrorIs(t, err, ErrProductIDRequired)
+
+		_, _, err = service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1"})
+		require.ErrorIs(t, err, ErrInvalidQuantity)
+
+		_, _, err = service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1, TTL: -time.Second})
+		require.ErrorIs(t, err, ErrInvalidTTL)
+	})
+}
+
+func TestInventoryService_ReleaseReservation(t *testing.T) {
+	ctx := context.Background()
+	reservation := repository.Reservation{ID: "res-1", ProductID: "product-1", Quantity: 4, Status: repository.ReservationStatusReleased}
+
+	t.Run("success: stock returned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).Return(reservation, nil).Once()
+		mockRepo.On("AddStock", ctx, "product-1", int32(4)).Return(int32(14), nil).Once()
+
+		released, err := service.ReleaseReservation(ctx, "res-1")
+
+		require.NoError(t, err)
+		require.Equal(t, reservation, released)
+	})
+
+	t.Run("already finished: stock is not returned twice", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).
+			Return(repository.Reservation{}, repository.ErrReservationNotActive).Once()
+
+		_, err := service.ReleaseReservation(ctx, "res-1")
+
+		require.ErrorIs(t, err, repository.ErrReservationNotActive)
+	})
+
+	t.Run("empty reservation_id", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+
+		_, err := service.ReleaseReservation(ctx, "")
+
+		require.ErrorIs(t, err, ErrReservationIDRequired)
+	})
+}
+
+func TestInventoryService_ReleaseOrderReservations(t *testing.T) {
+	ctx := context.Background()
+	filter := repository.ReservationFilter{OrderID: "order-1", Status: repository.ReservationStatusActive}
+
+	t.Run("releases active reservations, skips concurrently finished", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		active := []repository.Reservation{
+			{ID: "res-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2},
+			{ID: "res-2", OrderID: "order-1", ProductID: "product-2", Quantity: 1},
+		}
+		mockReservations.On("ListReservations", ctx, filter, maxListLimit).Return(active, nil).Once()
+		mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).Return(active[0], nil).Once()
+		mockReservations.On("FinishReservation", ctx, "res-2", repository.ReservationStatusReleased).
+			Return(repository.Reservation{}, repository.ErrReservationNotActive).Once()
+		mockRepo.On("AddStock", ctx, "product-1", int32(2)).Return(int32(12), nil).Once()
+
+		released, err := service.ReleaseOrderReservations(ctx, "order-1")
+
+		require.NoError(t, err)
+		require.Equal(t, 1, released)
+	})
+
+	t.Run("no active reservations: nothing released", func(t *testing.T) {
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("ListReservations", ctx, filter, maxListLimit).Return([]repository.Reservation{}, nil).Once()
+
+		released, err := service.ReleaseOrderReservations(ctx, "order-1")
+
+		require.NoError(t, err)
+		require.Zero(t, released)
+	})
+
+	t.Run("stock return failure is returned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		reservation := repository.Reservation{ID: "res-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2}
+		mockReservations.On("ListReservations", ctx, filter, maxListLimit).Return([]repository.Reservation{reservation}, nil).Once()
+		mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).Return(reservation, nil).Once()
+		mockRepo.On("AddStock", ctx, "product-1", int32(2)).Return(int32(0), errors.New("mongo unavailable")).Once()
+
+		_, err := service.ReleaseOrderReservations(ctx, "order-1")
+
+		require.Error(t, err)
+	})
+
+	t.Run("empty order_id", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+
+		_, err := service.ReleaseOrderReservations(ctx, "")
+
+		require.ErrorIs(t, err, ErrOrderIDRequired)
+	})
+}
+
+func TestInventoryService_ListReservations(t *testing.T) {
+	ctx := context.Background()
+	filter := repository.ReservationFilter{ProductID: "product-1", Status: repository.ReservationStatusActive}
+
+	tests := []struct {
+		name      string
+		limit     int
+		repoLimit int
+	}{
+		{name: "default limit", limit: 0, repoLimit: 50},
+		{name: "explicit limit", limit: 10, repoLimit: 10},
+		{name: "limit capped", limit: 1000, repoLimit: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReservations := mocks.NewReservationRepository(t)
+			service := NewInventoryService(mocks.NewInventoryRepository(t), mockReservations, nil, nil, nil, nil, nil, nil)
+
+			mockReservations.On("ListReservations", ctx, filter, tt.repoLimit).
+				Return([]repository.Reservation{{ID: "res-1"}}, nil).Once()
+
+			reservations, err := service.ListReservations(ctx, filter, tt.limit)
+
+			require.NoError(t, err)
+			require.Len(t, reservations, 1)
+		})
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+
+		_, err := service.ListReservations(ctx, repository.ReservationFilter{Status: "pending"}, 0)
+
+		require.ErrorIs(t, err, ErrInvalidReservationStatus)
+	})
+}
+
+func TestInventoryService_ExpireReservations(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	mockRepo := mocks.NewInventoryRepository(t)
+	mockReservations := mocks.NewReservationRepository(t)
+	service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+	expired := []repository.Reservation{
+		{ID: "res-1", ProductID: "product-1", Quantity: 2},
+		{ID: "res-2", ProductID: "product-2", Quantity: 1}, // одновременно снят через ReleaseReservation
+	}
+	mockReservations.On("ListExpiredReservations", ctx, now, expireReservationsBatch).Return(expired, nil).Once()
+	mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusExpired).Return(expired[0], nil).Once()
+	mockReservations.On("FinishReservation", ctx, "res-2", repository.ReservationStatusExpired).
+		Return(repository.Reservation{}, repository.ErrReservationNotActive).Once()
+	mockRepo.On("AddStock", ctx, "product-1", int32(2)).Return(int32(7), nil).Once()
+
+	count, err := service.ExpireReservations(ctx, now)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+// sweepRecorder запоминает итоги проходов sweeper'а
+type sweepRecorder struct {
+	results []ReservationSweepResult
+	errs    []error
+}
+
+func (r *sweepRecorder) RecordReservationSweep(result ReservationSweepResult, err error) {
+	r.results = append(r.results, result)
+	r.errs = append(r.errs, err)
+}
+
+func TestReservationSweeper_Sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("full batch: sweeper takes the next one in the same pass", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		recorder := &sweepRecorder{}
+		sweeper := NewReservationSweeper(NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil), time.Minute, recorder)
+
+		first := make([]repository.Reservation, 0, expireReservationsBatch)
+		for i := 0; i < expireReservationsBatch; i++ {
+			first = append(first, repository.Reservation{ID: fmt.Sprintf("res-%d", i), ProductID: "product-1", Quantity: 1})
+		}
+		second := []repository.Reservation{
+			{ID: "res-last", ProductID: "product-2", Quantity: 3},
+			{ID: "res-released", ProductID: "product-2", Quantity: 1}, // одновременно снят через ReleaseReservation
+		}
+		mockReservations.On("ListExpiredReservations", ctx, now, expireReservationsBatch).Return(first, nil).Once()
+		mockReservations.On("ListExpiredReservations", ctx, now, expireReservationsBatch).Return(second, nil).Once()
+		for _, reservation := range first {
+			mockReservations.On("FinishReservation", ctx, reservation.ID, repository.ReservationStatusExpired).Return(reservation, nil).Once()
+		}
+		mockReservations.On("FinishReservation", ctx, "res-last", repository.ReservationStatusExpired).Return(second[0], nil).Once()
+		mockReservations.On("FinishReservation", ctx, "res-released", repository.ReservationStatusExpired).
+			Return(repository.Reservation{}, repository.ErrReservationNotActive).Once()
+		mockRepo.On("AddStock", ctx, "product-1", int32(1)).Return(int32(5), nil).Times(expireReservationsBatch)
+		mockRepo.On("AddStock", ctx, "product-2", int32(3)).Return(int32(3), nil).Once()
+
+		result, err := sweeper.Sweep(ctx, now)
+
+		require.NoError(t, err)
+		require.Equal(t, expireReservationsBatch+1, result.Expired)
+		require.Equal(t, int64(expireReservationsBatch+3), result.Quantity)
+		require.Equal(t, 1, result.Skipped)
+		require.Len(t, recorder.results, 1)
+		require.Equal(t, result, recorder.results[0])
+		require.NoError(t, recorder.errs[0])
+	})
+
+	t.Run("repository error is recorded with partial result", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		recorder := &sweepRecorder{}
+		sweeper := NewReservationSweeper(NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil), time.Minute, recorder)
+
+		mockReservations.On("ListExpiredReservations", ctx, now, expireReservationsBatch).Return(nil, errors.New("mongo down")).Once()
+
+		result, err := sweeper.Sweep(ctx, now)
+
+		require.Error(t, err)
+		require.Zero(t, result.Expired)
+		require.Len(t, recorder.errs, 1)
+		require.Error(t, recorder.errs[0])
+	})
+}
+
+func TestInventoryService_ReserveStockBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: all items reserved, duplicates merged", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", mock.Anything).
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Twice()
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(3)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-2", int32(2)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.OrderID == "order-1" && r.IdempotencyKey == "order-1"
+		})).Return(nil).Twice()
+
+		out, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			OrderID: "order-1",
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 2},
+				{ProductID: "product-1", Quantity: 2},
+			},
+		})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Len(t, out.Reservations, 2)
+		require.Equal(t, "product-1", out.Reservations[0].ProductID)
+		require.Equal(t, int32(3), out.Reservations[0].Quantity)
+		require.Empty(t, out.InsufficientProductID)
+	})
+
+	t.Run("insufficient third item: earlier items returned to stock", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-2", int32(1)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-3", int32(1)).Return(int32(0), false, nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(1)).Return(int32(5), nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-2", int32(1)).Return(int32(5), nil).Once()
+
+		out, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 1},
+				{ProductID: "product-3", Quantity: 1},
+			},
+		})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.Empty(t, out.Reservations)
+		require.Equal(t, "product-3", out.InsufficientProductID)
+	})
+
+	t.Run("repository error: earlier items returned to stock", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-2", int32(1)).Return(int32(0), false, errors.New("database connection failed")).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(1)).Return(int32(5), nil).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 1},
+			},
+		})
+
+		require.ErrorContains(t, err, "database connection failed")
+		require.False(t, reserved)
+	})
+
+	t.Run("save failure: saved reservations released, all stock returned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-2", int32(1)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ProductID == "product-1"
+		})).Return(nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ProductID == "product-2"
+		})).Return(errors.New("insert failed")).Once()
+		mockReservations.On("FinishReservation", ctx, mock.Anything, repository.ReservationStatusReleased).
+			Return(repository.Reservation{}, nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(1)).Return(int32(5), nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-2", int32(1)).Return(int32(5), nil).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 1},
+			},
+		})
+
+		require.ErrorContains(t, err, "insert failed")
+		require.False(t, reserved)
+	})
+
+	t.Run("retried batch: existing reservations returned, stock not reserved again", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		first := repository.Reservation{ID: "res-1", OrderID: "order-1", ProductID: "product-1", Quantity: 3, IdempotencyKey: "order-1"}
+		second := repository.Reservation{ID: "res-2", OrderID: "order-1", ProductID: "product-2", Quantity: 2, IdempotencyKey: "order-1"}
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").Return(first, nil).Once()
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-2").Return(second, nil).Once()
+
+		out, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			OrderID: "order-1",
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 3},
+				{ProductID: "product-2", Quantity: 2},
+			},
+		})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Equal(t, []repository.Reservation{first, second}, out.Reservations)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything, mock.Anything)
+		mockReservations.AssertNotCalled(t, "CreateReservation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("retried batch with different quantity: ErrIdempotencyKeyReused", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").
+			Return(repository.Reservation{ID: "res-1", ProductID: "product-1", Quantity: 3}, nil).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			OrderID: "order-1",
+			Items:   []BatchItem{{ProductID: "product-1", Quantity: 5}},
+		})
+
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+		require.False(t, reserved)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("retried batch with extra item: ErrIdempotencyKeyReused", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, nil, nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-1").
+			Return(repository.Reservation{ID: "res-1", ProductID: "product-1", Quantity: 1}, nil).Once()
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", "product-2").
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			OrderID: "order-1",
+			Items: []BatchItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 1},
+			},
+		})
+
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+		require.False(t, reserved)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("validation errors do not reach repository", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+
+		_, _, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{})
+		require.ErrorIs(t, err, ErrEmptyBatch)
+
+		_, _, err = service.ReserveStockBatch(ctx, ReserveStockBatchInput{Items: []BatchItem{{ProductID: "product-1", Quantity: 1}, {Quantity: 1}}})
+		require.ErrorIs(t, err, ErrProductIDRequired)
+
+		_, _, err = service.ReserveStockBatch(ctx, ReserveStockBatchInput{Items: []BatchItem{{ProductID: "product-1", Quantity: 0}}})
+		require.ErrorIs(t, err, ErrInvalidQuantity)
+	})
+}
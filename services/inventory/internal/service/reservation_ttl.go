@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// NormalizeCategory приводит категорию товара к виду, в котором она хранится и ищется в политике сроков
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// ReservationTTLs выбирает срок резерва, если клиент его не задал (ttl_seconds = 0)
+// Срок берётся по категории товара, а для товара без категории, без карточки или с категорией вне политики -
+// defaultTTL. Так брошенный checkout не держит быстро оборачиваемый товар (продукты - 10 минут),
+// а товар с долгим оформлением (электроника - 30 минут) не теряет резерв раньше времени
+// Срок фиксируется в ExpiresAt при создании резерва: смена политики не меняет уже созданные резервы
+type ReservationTTLs struct {
+	products   repository.ProductRepository
+	defaultTTL time.Duration            // 0 - без срока
+	byCategory map[string]time.Duration // ключи - NormalizeCategory
+}
+
+// NewReservationTTLs создаёт политику сроков резерва
+// defaultTTL = 0 - резерв без срока; byCategory может быть пустым (срок у всех товаров - defaultTTL)
+func NewReservationTTLs(products repository.ProductRepository, defaultTTL time.Duration, byCategory map[string]time.Duration) *ReservationTTLs {
+	normalized := make(map[string]time.Duration, len(byCategory))
+	for category, ttl := range byCategory {
+		normalized[NormalizeCategory(category)] = ttl
+	}
+	return &ReservationTTLs{
+		products:   products,
+		defaultTTL: defaultTTL,
+		byCategory: normalized,
+	}
+}
+
+// TTL возвращает срок резерва товара productID по политике
+// Без категорий в политике карточка товара не читается
+func (t *ReservationTTLs) TTL(ctx context.Context, productID string) (time.Duration, error) {
+	if len(t.byCategory) == 0 {
+		return t.defaultTTL, nil
+	}
+
+	product, err := t.products.GetProduct(ctx, productID)
+	if err != nil {
+		// Остаток может существовать без карточки каталога: такой товар резервируется со сроком по умолчанию
+		if errors.Is(err, repository.ErrNotFound) {
+			return t.defaultTTL, nil
+		}
+		return 0, fmt.Errorf("failed to get product for reservation ttl: %w", err)
+	}
+	if ttl, ok := t.byCategory[product.Category]; ok {
+		return ttl, nil
+	}
+	return t.defaultTTL, nil
+}
+
+// reservationTTL возвращает срок резерва товара: заданный клиентом или по политике, если клиент передал 0
+func (s *InventoryService) reservationTTL(ctx context.Context, productID string, requested time.Duration) (time.Duration, error) {
+	if requested > 0 || s.ttls == nil {
+		return requested, nil
+	}
+	return s.ttls.TTL(ctx, productID)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestReservationTTLs_TTL(t *testing.T) {
+	ctx := context.Background()
+	byCategory := map[string]time.Duration{"Electronics": 30 * time.Minute, "groceries": 10 * time.Minute}
+
+	cases := []struct {
+		name    string
+		product repository.Product
+		getErr  error
+		ttl     time.Duration
+	}{
+		{"category policy", repository.Product{ID: "product-1", Category: "electronics"}, nil, 30 * time.Minute},
+		{"category outside policy", repository.Product{ID: "product-1", Category: "books"}, nil, 15 * time.Minute},
+		{"product without category", repository.Product{ID: "product-1"}, nil, 15 * time.Minute},
+		{"product without catalog card", repository.Product{}, repository.ErrNotFound, 15 * time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProducts := mocks.NewProductRepository(t)
+			ttls := NewReservationTTLs(mockProducts, 15*time.Minute, byCategory)
+
+			mockProducts.On("GetProduct", ctx, "product-1").Return(tc.product, tc.getErr).Once()
+
+			ttl, err := ttls.TTL(ctx, "product-1")
+
+			require.NoError(t, err)
+			require.Equal(t, tc.ttl, ttl)
+		})
+	}
+
+	t.Run("catalog failure is returned", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		ttls := NewReservationTTLs(mockProducts, 15*time.Minute, byCategory)
+		dbErr := errors.New("mongo unavailable")
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{}, dbErr).Once()
+
+		_, err := ttls.TTL(ctx, "product-1")
+
+		require.ErrorIs(t, err, dbErr)
+	})
+
+	t.Run("no categories: default without catalog lookup", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		ttls := NewReservationTTLs(mockProducts, 15*time.Minute, nil)
+
+		ttl, err := ttls.TTL(ctx, "product-1")
+
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Minute, ttl)
+		mockProducts.AssertNotCalled(t, "GetProduct", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_ReservationTTLPolicy(t *testing.T) {
+	ctx := context.Background()
+	byCategory := map[string]time.Duration{"electronics": 30 * time.Minute, "groceries": 10 * time.Minute}
+
+	t.Run("reservation without ttl expires by product category", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, NewReservationTTLs(mockProducts, 0, byCategory), nil)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{ID: "product-1", Category: "groceries"}, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ExpiresAt.Sub(r.CreatedAt) == 10*time.Minute
+		})).Return(nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+
+	t.Run("explicit ttl overrides category policy", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, NewReservationTTLs(mockProducts, 0, byCategory), nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ExpiresAt.Sub(r.CreatedAt) == time.Minute
+		})).Return(nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1, TTL: time.Minute})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		mockProducts.AssertNotCalled(t, "GetProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("catalog failure: nothing is reserved", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, NewReservationTTLs(mockProducts, 0, byCategory), nil)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{}, errors.New("mongo unavailable")).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1})
+
+		require.Error(t, err)
+		require.False(t, reserved)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("batch: each item expires by its own category", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, nil, nil, NewReservationTTLs(mockProducts, 0, byCategory), nil)
+
+		mockReservations.On("GetReservationByIdempotencyKey", ctx, "order-1", mock.Anything).
+			Return(repository.Reservation{}, repository.ErrReservationNotFound).Twice()
+		mockProducts.On("GetProduct", ctx, "tv").Return(repository.Product{ID: "tv", Category: "electronics"}, nil).Once()
+		mockProducts.On("GetProduct", ctx, "milk").Return(repository.Product{ID: "milk", Category: "groceries"}, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "tv", int32(1)).Return(int32(5), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "milk", int32(2)).Return(int32(5), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ProductID == "tv" && r.ExpiresAt.Sub(r.CreatedAt) == 30*time.Minute
+		})).Return(nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.ProductID == "milk" && r.ExpiresAt.Sub(r.CreatedAt) == 10*time.Minute
+		})).Return(nil).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			OrderID: "order-1",
+			Items:   []BatchItem{{ProductID: "tv", Quantity: 1}, {ProductID: "milk", Quantity: 2}},
+		})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+}
@@ -2,33 +2,126 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 )
 
+const (
+	// reserveConflictRetries - сколько раз повторять резервирование после write conflict (горячий товар)
+	reserveConflictRetries = 3
+	// reserveConflictBackoff - пауза перед повтором, растёт линейно с номером попытки
+	reserveConflictBackoff = 5 * time.Millisecond
+)
+
+// Результаты резервирования для метрик
+const (
+	ReservationResultReserved     = "reserved"
+	ReservationResultInsufficient = "insufficient"
+	ReservationResultConflict     = "conflict" // write conflict не разрешился за reserveConflictRetries повторов
+	ReservationResultError        = "error"
+)
+
+// ErrProductIDRequired возвращается, если product_id не передан (handler маппит в codes.InvalidArgument)
+var ErrProductIDRequired = errors.New("product_id is required")
+
+// ErrInvalidQuantity возвращается, если количество для пополнения не положительное (handler маппит в codes.InvalidArgument)
+var ErrInvalidQuantity = errors.New("quantity must be positive")
+
+// ErrProductDeleted возвращается, если товар удалён из каталога: GetStock - codes.NotFound, резервирование - codes.FailedPrecondition
+var ErrProductDeleted = errors.New("product is deleted")
+
+// maxStockBatchSize - сколько товаров можно запросить одним GetStockBatch
+const maxStockBatchSize = 200
+
+// Ошибки GetStockBatch (handler маппит в codes.InvalidArgument)
+var (
+	ErrEmptyProductIDs    = errors.New("product_ids must not be empty")
+	ErrStockBatchTooLarge = fmt.Errorf("product_ids must contain at most %d items", maxStockBatchSize)
+)
+
+// StockLevel - остаток одного товара в ответе GetStockBatch
+type StockLevel struct {
+	ProductID string
+	Available int32
+	Found     bool // false - товара нет в хранилище, Available = 0
+}
+
+// ReservationMetricsRecorder записывает метрики резервирования (опционально, может быть nil).
+type ReservationMetricsRecorder interface {
+	// RecordReservation записывает итог резервирования и его длительность вместе с повторами
+	RecordReservation(d time.Duration, result string)
+	// RecordWriteConflict записывает write conflict; retried=false, если повторы исчерпаны
+	RecordWriteConflict(retried bool)
+}
+
 // InventoryService содержит бизнес-логику работы с инвентарём
 // Использует только простые типы Go, не зависит от protobuf
 // Зависит от интерфейса InventoryRepository, а не от конкретной реализации
 type InventoryService struct {
-	repo repository.InventoryRepository
+	repo         repository.InventoryRepository
+	reservations repository.ReservationRepository
+	metrics      ReservationMetricsRecorder
+	events       StockEventPublisher
+	allocator    *WarehouseAllocator
+	backorders   *Backorders
+	ttls         *ReservationTTLs
+	products     repository.ProductRepository // удалённые из каталога товары; nil - каталог не проверяется
+	watchers     *StockWatchHub               // подписчики WatchStock этого инстанса
+}
+
+// reserveResult - итог успешного резервирования
+type reserveResult struct {
+	allocations    []repository.WarehouseStock // nil, если склады не настроены
+	available      int32                       // суммарный остаток после списания
+	backordered    int32                       // сколько единиц зарезервировано сверх остатка (предзаказ)
+	backorderLimit int32                       // BackorderLimit товара, если backordered > 0
 }
 
 // NewInventoryService создаёт новый экземпляр InventoryService
 // Принимает repository как зависимость - это позволяет легко подменять его в тестах
-func NewInventoryService(repo repository.InventoryRepository) *InventoryService {
+// reservations нужен только для резервов с ID (CreateReservation, ReleaseReservation, ExpireReservations)
+// metrics может быть nil (метрики не пишутся)
+// events может быть nil (события inventory.stock.changed не публикуются)
+// allocator может быть nil (склады не настроены: резервирование и приёмка идут через общий остаток repo)
+// backorders может быть nil (предзаказ выключен: резервирование сверх остатка отклоняется у всех товаров)
+// ttls может быть nil (срок резерва задаёт только клиент: ttl_seconds = 0 - без срока)
+// products может быть nil (удаление из каталога не проверяется: остаток отдаётся и резервируется у всех товаров)
+func NewInventoryService(repo repository.InventoryRepository, reservations repository.ReservationRepository, metrics ReservationMetricsRecorder, events StockEventPublisher, allocator *WarehouseAllocator, backorders *Backorders, ttls *ReservationTTLs, products repository.ProductRepository) *InventoryService {
 	return &InventoryService{
-		repo: repo,
+		repo:         repo,
+		reservations: reservations,
+		metrics:      metrics,
+		events:       events,
+		allocator:    allocator,
+		backorders:   backorders,
+		ttls:         ttls,
+		products:     products,
+		watchers:     NewStockWatchHub(),
 	}
 }
 
 // GetStock возвращает количество товара на складе
 // Делегирует запрос в repository и обрабатывает бизнес-логику
-func (s *InventoryService) GetStock(ctx context.Context, productID string) (int32, error) {
-	log.Printf("GetStock called for product: %s", productID)
+// consistency: strong для checkout (primary), eventual для просмотра (secondary), default - настройка репозитория
+// Товар, удалённый из каталога, - ErrProductDeleted
+func (s *InventoryService) GetStock(ctx context.Context, productID string, consistency repository.ReadConsistency) (int32, error) {
+	log.Printf("GetStock called for product: %s, consistency=%q", productID, consistency)
+
+	if err := s.checkNotDeleted(ctx, productID); err != nil {
+		return 0, err
+	}
 
 	// Получаем остаток из repository
-	available, err := s.repo.GetStock(ctx, productID)
+	available, err := s.repo.GetStock(ctx, productID, consistency)
 	if err != nil {
 		// Если товар не найден, repository вернёт ErrNotFound
 		// Возвращаем ошибку, а не дефолтное значение
@@ -41,25 +134,245 @@ func (s *InventoryService) GetStock(ctx context.Context, productID string) (int3
 	return available, nil
 }
 
-// ReserveStock резервирует товар на складе
-// Делегирует запрос в repository, который проверяет доступность и уменьшает остаток
-// Возвращает true, если резервирование успешно
-func (s *InventoryService) ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error) {
-	log.Printf("ReserveStock called: product=%s, quantity=%d", productID, quantity)
+// GetStockBatch возвращает остатки нескольких товаров одним запросом к repository
+// Повторяющиеся product_id схлопываются; порядок результата - порядок первого упоминания в productIDs
+// Ненайденный товар не ошибка: он возвращается с Found = false, как и товар, удалённый из каталога
+func (s *InventoryService) GetStockBatch(ctx context.Context, productIDs []string, consistency repository.ReadConsistency) ([]StockLevel, error) {
+	log.Printf("GetStockBatch called: products=%d, consistency=%q", len(productIDs), consistency)
 
-	// Делегируем резервирование в repository
-	// Repository проверит доступность и уменьшит остаток при успехе
-	success, err := s.repo.ReserveStock(ctx, productID, quantity)
+	if len(productIDs) == 0 {
+		return nil, ErrEmptyProductIDs
+	}
+	unique := make([]string, 0, len(productIDs))
+	seen := make(map[string]struct{}, len(productIDs))
+	for _, productID := range productIDs {
+		if productID == "" {
+			return nil, ErrProductIDRequired
+		}
+		if _, ok := seen[productID]; ok {
+			continue
+		}
+		seen[productID] = struct{}{}
+		unique = append(unique, productID)
+	}
+	if len(unique) > maxStockBatchSize {
+		return nil, ErrStockBatchTooLarge
+	}
+
+	stock, err := s.repo.GetStockBatch(ctx, unique, consistency)
 	if err != nil {
-		log.Printf("ReserveStock error: %v", err)
-		return false, err
+		log.Printf("GetStockBatch error: %v", err)
+		return nil, err
 	}
+	var deleted map[string]bool
+	if s.products != nil {
+		if deleted, err = s.products.DeletedProductIDs(ctx, unique); err != nil {
+			log.Printf("GetStockBatch error: failed to check deleted products: %v", err)
+			return nil, fmt.Errorf("failed to check deleted products: %w", err)
+		}
+	}
+
+	levels := make([]StockLevel, 0, len(unique))
+	for _, productID := range unique {
+		if deleted[productID] {
+			levels = append(levels, StockLevel{ProductID: productID})
+			continue
+		}
+		available, found := stock[productID]
+		levels = append(levels, StockLevel{ProductID: productID, Available: available, Found: found})
+	}
+	return levels, nil
+}
 
+// GetWarehouseStock возвращает остатки товара по складам
+// Возвращает ErrWarehousesNotConfigured, если склады не настроены, и repository.ErrNotFound, если товара нет
+func (s *InventoryService) GetWarehouseStock(ctx context.Context, productID string, consistency repository.ReadConsistency) ([]repository.WarehouseStock, error) {
+	if productID == "" {
+		return nil, ErrProductIDRequired
+	}
+	if s.allocator == nil {
+		return nil, ErrWarehousesNotConfigured
+	}
+	return s.allocator.stock.GetWarehouseStock(ctx, productID, consistency)
+}
+
+// ReserveStock резервирует товар на складе по стратегии распределения из конфига
+// Возвращает true, если резервирование успешно (в том числе сверх остатка, если у товара включён предзаказ)
+func (s *InventoryService) ReserveStock(ctx context.Context, productID string, quantity int32) (bool, error) {
+	result, success, err := s.reserve(ctx, productID, quantity, AllocationStrategyDefault)
 	if success {
-		log.Printf("ReserveStock successful: product=%s, quantity=%d", productID, quantity)
+		s.recordBackorder(ctx, repository.Reservation{ProductID: productID, Quantity: quantity}, result)
+	}
+	return success, err
+}
+
+// reserve резервирует товар на складе
+// Делегирует запрос в repository (или распределитель по складам), который проверяет доступность и уменьшает остаток
+// При write conflict (одновременное резервирование горячего товара) повторяет до reserveConflictRetries раз
+// Если товара не хватило, а у товара включён предзаказ, резервирует сверх остатка (Backorders)
+// Товар, удалённый из каталога, не резервируется: ErrProductDeleted
+// Возвращает итог резервирования и true, если резервирование успешно
+func (s *InventoryService) reserve(ctx context.Context, productID string, quantity int32, strategy AllocationStrategy) (reserveResult, bool, error) {
+	log.Printf("ReserveStock called: product=%s, quantity=%d, strategy=%q", productID, quantity, strategy)
+	if err := s.checkNotDeleted(ctx, productID); err != nil {
+		log.Printf("ReserveStock rejected: product=%s: %v", productID, err)
+		return reserveResult{}, false, err
+	}
+	start := time.Now()
+	// Span на всё резервирование с повторами и его итог; контекст запроса не меняется,
+	// поэтому команды MongoDB остаются дочерними span'ами RPC
+	_, span := otel.Tracer("inventory").Start(ctx, "InventoryService.Reserve",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("inventory.product_id", productID),
+			attribute.Int("inventory.quantity", int(quantity)),
+		),
+	)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		// Делегируем резервирование в repository
+		// Repository проверит доступность и уменьшит остаток при успехе
+		result, success, err := s.reserveOnce(ctx, productID, quantity, strategy)
+		if errors.Is(err, repository.ErrWriteConflict) {
+			retry := attempt < reserveConflictRetries
+			s.recordWriteConflict(retry)
+			if retry {
+				log.Printf("ReserveStock write conflict: product=%s, attempt=%d, retrying", productID, attempt+1)
+				select {
+				case <-ctx.Done():
+					s.recordReservation(span, start, ReservationResultError, ctx.Err())
+					return reserveResult{}, false, ctx.Err()
+				case <-time.After(reserveConflictBackoff * time.Duration(attempt+1)):
+				}
+				continue
+			}
+			log.Printf("ReserveStock error: product=%s, write conflict after %d retries: %v", productID, reserveConflictRetries, err)
+			s.recordReservation(span, start, ReservationResultConflict, err)
+			return reserveResult{}, false, err
+		}
+		if err != nil {
+			log.Printf("ReserveStock error: %v", err)
+			s.recordReservation(span, start, ReservationResultError, err)
+			return reserveResult{}, false, err
+		}
+
+		if success {
+			log.Printf("ReserveStock successful: product=%s, quantity=%d, allocations=%v, backordered=%d",
+				productID, quantity, result.allocations, result.backordered)
+			s.recordReservation(span, start, ReservationResultReserved, nil)
+			s.publishStockChanged(ctx, StockChangedEvent{ProductID: productID, Delta: -quantity, Reason: StockChangeReserved, Available: &result.available})
+		} else {
+			log.Printf("ReserveStock failed: insufficient stock for product=%s, quantity=%d", productID, quantity)
+			s.recordReservation(span, start, ReservationResultInsufficient, nil)
+		}
+
+		return result, success, nil
+	}
+}
+
+// checkNotDeleted возвращает ErrProductDeleted, если товар удалён из каталога
+// Товар без карточки каталога не считается удалённым: остаток может существовать до создания карточки
+func (s *InventoryService) checkNotDeleted(ctx context.Context, productID string) error {
+	if s.products == nil || productID == "" {
+		return nil
+	}
+	product, err := s.products.GetProduct(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if product.DeletedAt != nil {
+		return fmt.Errorf("%w: %s", ErrProductDeleted, productID)
+	}
+	return nil
+}
+
+// reserveOnce делает одну попытку резервирования: через распределитель по складам или общий остаток repo,
+// а если товара не хватило - сверх остатка (если предзаказ настроен)
+func (s *InventoryService) reserveOnce(ctx context.Context, productID string, quantity int32, strategy AllocationStrategy) (reserveResult, bool, error) {
+	var result reserveResult
+	var success bool
+	var err error
+	if s.allocator != nil {
+		result.available, result.allocations, success, err = s.allocator.reserve(ctx, productID, quantity, strategy)
 	} else {
-		log.Printf("ReserveStock failed: insufficient stock for product=%s, quantity=%d", productID, quantity)
+		result.available, success, err = s.repo.ReserveStock(ctx, productID, quantity)
+	}
+	if err != nil || success || s.backorders == nil {
+		return result, success, err
+	}
+	return s.backorders.reserve(ctx, productID, quantity)
+}
+
+// recordBackorder записывает резервирование сверх остатка в журнал предзаказов (если оно было)
+func (s *InventoryService) recordBackorder(ctx context.Context, reservation repository.Reservation, result reserveResult) {
+	if result.backordered == 0 || s.backorders == nil {
+		return
+	}
+	s.backorders.record(ctx, reservation, result)
+}
+
+// AddStock пополняет остаток товара на складе по умолчанию и возвращает суммарный остаток после пополнения
+func (s *InventoryService) AddStock(ctx context.Context, productID string, quantity int32) (int32, error) {
+	return s.AddWarehouseStock(ctx, productID, "", quantity)
+}
+
+// AddWarehouseStock пополняет остаток товара на складе warehouseID (приёмка) и возвращает суммарный остаток после пополнения
+// Пустой warehouseID - склад по умолчанию; другой склад должен быть в справочнике
+// quantity должен быть положительным: уменьшение остатка идёт только через ReserveStock
+func (s *InventoryService) AddWarehouseStock(ctx context.Context, productID, warehouseID string, quantity int32) (int32, error) {
+	log.Printf("AddStock called: product=%s, warehouse=%q, quantity=%d", productID, warehouseID, quantity)
+
+	if productID == "" {
+		return 0, ErrProductIDRequired
+	}
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
 	}
 
-	return success, nil
+	var available int32
+	var err error
+	switch {
+	case warehouseID == "" || warehouseID == repository.DefaultWarehouseID:
+		available, err = s.repo.AddStock(ctx, productID, quantity)
+	case s.allocator == nil:
+		return 0, ErrWarehousesNotConfigured
+	default:
+		if err := validateWarehouseID(warehouseID); err != nil {
+			return 0, err
+		}
+		if _, err := s.allocator.warehouses.GetWarehouse(ctx, warehouseID); err != nil {
+			return 0, err
+		}
+		available, err = s.allocator.stock.AddWarehouseStock(ctx, productID, []repository.WarehouseStock{{WarehouseID: warehouseID, Quantity: quantity}})
+	}
+	if err != nil {
+		log.Printf("AddStock error: product=%s: %v", productID, err)
+		return 0, err
+	}
+
+	log.Printf("AddStock successful: product=%s, quantity=%d, available=%d", productID, quantity, available)
+	s.publishStockChanged(ctx, StockChangedEvent{ProductID: productID, Delta: quantity, Reason: StockChangeReplenished, Available: &available})
+	return available, nil
+}
+
+// recordReservation пишет итог резервирования в span и метрики; err - ошибка для result error/conflict
+func (s *InventoryService) recordReservation(span trace.Span, start time.Time, result string, err error) {
+	span.SetAttributes(attribute.String("inventory.reservation.result", result))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if s.metrics != nil {
+		s.metrics.RecordReservation(time.Since(start), result)
+	}
+}
+
+func (s *InventoryService) recordWriteConflict(retried bool) {
+	if s.metrics != nil {
+		s.metrics.RecordWriteConflict(retried)
+	}
 }
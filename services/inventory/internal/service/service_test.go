@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
 	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
@@ -62,12 +65,12 @@ func TestInventoryService_GetStock(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
 			mockRepo := mocks.NewInventoryRepository(t)
-			service := NewInventoryService(mockRepo)
+			service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
 
-			mockRepo.On("GetStock", ctx, tt.productID).Return(tt.repoReturn, tt.repoError).Once()
+			mockRepo.On("GetStock", ctx, tt.productID, repository.ReadConsistencyDefault).Return(tt.repoReturn, tt.repoError).Once()
 
 			// Act
-			result, err := service.GetStock(ctx, tt.productID)
+			result, err := service.GetStock(ctx, tt.productID, repository.ReadConsistencyDefault)
 
 			// Assert
 			if tt.expectedError {
@@ -86,6 +89,75 @@ func TestInventoryService_GetStock(t *testing.T) {
 	}
 }
 
+func TestInventoryService_GetStock_PassesConsistency(t *testing.T) {
+	ctx := context.Background()
+
+	for _, consistency := range []repository.ReadConsistency{
+		repository.ReadConsistencyStrong,
+		repository.ReadConsistencyEventual,
+	} {
+		t.Run(string(consistency), func(t *testing.T) {
+			mockRepo := mocks.NewInventoryRepository(t)
+			service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+			mockRepo.On("GetStock", ctx, "product-1", consistency).Return(int32(7), nil).Once()
+
+			result, err := service.GetStock(ctx, "product-1", consistency)
+			require.NoError(t, err)
+			require.Equal(t, int32(7), result)
+		})
+	}
+}
+
+func TestInventoryService_GetStockBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("duplicates collapsed, order kept, missing product not found", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetStockBatch", ctx, []string{"product-2", "product-1", "product-3"}, repository.ReadConsistencyEventual).
+			Return(map[string]int32{"product-1": 5, "product-2": 0}, nil).Once()
+
+		levels, err := service.GetStockBatch(ctx, []string{"product-2", "product-1", "product-2", "product-3"}, repository.ReadConsistencyEventual)
+
+		require.NoError(t, err)
+		require.Equal(t, []StockLevel{
+			{ProductID: "product-2", Available: 0, Found: true},
+			{ProductID: "product-1", Available: 5, Found: true},
+			{ProductID: "product-3", Available: 0, Found: false},
+		}, levels)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := service.GetStockBatch(ctx, nil, repository.ReadConsistencyDefault)
+		require.ErrorIs(t, err, ErrEmptyProductIDs)
+
+		_, err = service.GetStockBatch(ctx, []string{"product-1", ""}, repository.ReadConsistencyDefault)
+		require.ErrorIs(t, err, ErrProductIDRequired)
+
+		tooMany := make([]string, maxStockBatchSize+1)
+		for i := range tooMany {
+			tooMany[i] = fmt.Sprintf("product-%d", i)
+		}
+		_, err = service.GetStockBatch(ctx, tooMany, repository.ReadConsistencyDefault)
+		require.ErrorIs(t, err, ErrStockBatchTooLarge)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetStockBatch", ctx, []string{"product-1"}, repository.ReadConsistencyDefault).
+			Return(nil, errors.New("database error")).Once()
+
+		_, err := service.GetStockBatch(ctx, []string{"product-1"}, repository.ReadConsistencyDefault)
+		require.Error(t, err)
+	})
+}
+
 func TestInventoryService_ReserveStock(t *testing.T) {
 	ctx := context.Background()
 
@@ -133,9 +205,9 @@ func TestInventoryService_ReserveStock(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
 			mockRepo := mocks.NewInventoryRepository(t)
-			service := NewInventoryService(mockRepo)
+			service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
 
-			mockRepo.On("ReserveStock", ctx, tt.productID, tt.quantity).Return(tt.repoReturn, tt.repoError).Once()
+			mockRepo.On("ReserveStock", ctx, tt.productID, tt.quantity).Return(int32(0), tt.repoReturn, tt.repoError).Once()
 
 			// Act
 			result, err := service.ReserveStock(ctx, tt.productID, tt.quantity)
@@ -156,3 +228,173 @@ func TestInventoryService_ReserveStock(t *testing.T) {
 		})
 	}
 }
+
+// fakeReservationMetrics запоминает записанные метрики резервирования
+type fakeReservationMetrics struct {
+	mu        sync.Mutex
+	results   []string
+	conflicts []bool
+}
+
+func (m *fakeReservationMetrics) RecordReservation(_ time.Duration, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+}
+
+func (m *fakeReservationMetrics) RecordWriteConflict(retried bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conflicts = append(m.conflicts, retried)
+}
+
+func TestInventoryService_ReserveStock_WriteConflict(t *testing.T) {
+	ctx := context.Background()
+	conflictErr := fmt.Errorf("%w: WriteConflict", repository.ErrWriteConflict)
+
+	t.Run("retries after conflict and succeeds", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		metrics := &fakeReservationMetrics{}
+		service := NewInventoryService(mockRepo, nil, metrics, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "hot-product", int32(1)).Return(int32(0), false, conflictErr).Twice()
+		mockRepo.On("ReserveStock", ctx, "hot-product", int32(1)).Return(int32(10), true, nil).Once()
+
+		result, err := service.ReserveStock(ctx, "hot-product", 1)
+
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, []bool{true, true}, metrics.conflicts)
+		require.Equal(t, []string{ReservationResultReserved}, metrics.results)
+	})
+
+	t.Run("gives up after retries", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		metrics := &fakeReservationMetrics{}
+		service := NewInventoryService(mockRepo, nil, metrics, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "hot-product", int32(1)).Return(int32(0), false, conflictErr).Times(reserveConflictRetries + 1)
+
+		result, err := service.ReserveStock(ctx, "hot-product", 1)
+
+		require.ErrorIs(t, err, repository.ErrWriteConflict)
+		require.False(t, result)
+		require.Len(t, metrics.conflicts, reserveConflictRetries+1)
+		require.False(t, metrics.conflicts[reserveConflictRetries])
+		require.Equal(t, []string{ReservationResultConflict}, metrics.results)
+	})
+
+	t.Run("insufficient stock is recorded", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		metrics := &fakeReservationMetrics{}
+		service := NewInventoryService(mockRepo, nil, metrics, nil, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "hot-product", int32(5)).Return(int32(0), false, nil).Once()
+
+		result, err := service.ReserveStock(ctx, "hot-product", 5)
+
+		require.NoError(t, err)
+		require.False(t, result)
+		require.Empty(t, metrics.conflicts)
+		require.Equal(t, []string{ReservationResultInsufficient}, metrics.results)
+	})
+}
+
+func TestInventoryService_DeletedProduct(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("GetStock: deleted product not found", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, mockProducts)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{ID: "product-1", DeletedAt: &deletedAt}, nil).Once()
+
+		_, err := service.GetStock(ctx, "product-1", repository.ReadConsistencyDefault)
+		require.ErrorIs(t, err, ErrProductDeleted)
+	})
+
+	t.Run("GetStock: product without catalog card served", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, mockProducts)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{}, repository.ErrNotFound).Once()
+		mockRepo.On("GetStock", ctx, "product-1", repository.ReadConsistencyDefault).Return(int32(3), nil).Once()
+
+		available, err := service.GetStock(ctx, "product-1", repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, int32(3), available)
+	})
+
+	t.Run("GetStockBatch: deleted product reported not found", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, mockProducts)
+
+		mockRepo.On("GetStockBatch", ctx, []string{"product-1", "product-2"}, repository.ReadConsistencyDefault).
+			Return(map[string]int32{"product-1": 5, "product-2": 7}, nil).Once()
+		mockProducts.On("DeletedProductIDs", ctx, []string{"product-1", "product-2"}).
+			Return(map[string]bool{"product-2": true}, nil).Once()
+
+		levels, err := service.GetStockBatch(ctx, []string{"product-1", "product-2"}, repository.ReadConsistencyDefault)
+		require.NoError(t, err)
+		require.Equal(t, []StockLevel{
+			{ProductID: "product-1", Available: 5, Found: true},
+			{ProductID: "product-2", Available: 0, Found: false},
+		}, levels)
+	})
+
+	t.Run("ReserveStock: deleted product rejected", func(t *testing.T) {
+		mockProducts := mocks.NewProductRepository(t)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, mockProducts)
+
+		mockProducts.On("GetProduct", ctx, "product-1").Return(repository.Product{ID: "product-1", DeletedAt: &deletedAt}, nil).Once()
+
+		ok, err := service.ReserveStock(ctx, "product-1", 1)
+		require.ErrorIs(t, err, ErrProductDeleted)
+		require.False(t, ok)
+	})
+}
+
+func TestInventoryService_AddStock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success: returns stock after intake", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("AddStock", ctx, "product-1", int32(10)).Return(int32(15), nil).Once()
+
+		available, err := service.AddStock(ctx, "product-1", 10)
+
+		require.NoError(t, err)
+		require.Equal(t, int32(15), available)
+	})
+
+	t.Run("validation errors do not reach repository", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := service.AddStock(ctx, "", 10)
+		require.ErrorIs(t, err, ErrProductIDRequired)
+
+		_, err = service.AddStock(ctx, "product-1", 0)
+		require.ErrorIs(t, err, ErrInvalidQuantity)
+
+		_, err = service.AddStock(ctx, "product-1", -5)
+		require.ErrorIs(t, err, ErrInvalidQuantity)
+	})
+
+	t.Run("repository error is returned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("AddStock", ctx, "product-1", int32(3)).Return(int32(0), errors.New("database connection failed")).Once()
+
+		available, err := service.AddStock(ctx, "product-1", 3)
+
+		require.ErrorContains(t, err, "database connection failed")
+		require.Zero(t, available)
+	})
+}
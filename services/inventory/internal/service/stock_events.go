@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventTypeStockChanged - тип события изменения остатка
+const EventTypeStockChanged = "inventory.stock.changed"
+
+// Причины изменения остатка в событии inventory.stock.changed
+const (
+	StockChangeReserved    = "reserved"    // товар списан под резерв
+	StockChangeReleased    = "released"    // резерв снят или несостоявшееся резервирование откатено
+	StockChangeExpired     = "expired"     // истёкший резерв вернулся в остаток
+	StockChangeReplenished = "replenished" // приёмка на склад (AddStock)
+	StockChangeAdjusted    = "adjusted"    // применена одобренная корректировка остатка
+)
+
+// StockChangedEvent - событие изменения остатка товара
+type StockChangedEvent struct {
+	EventID       string
+	OccurredAt    time.Time
+	ProductID     string
+	Delta         int32  // изменение остатка: отрицательное при резервировании
+	Reason        string // StockChange*
+	Available     *int32 // остаток после изменения; nil, если он неизвестен
+	ReservationID string // только для released/expired по резерву с ID
+	OrderID       string // только для released/expired по резерву заказа
+}
+
+// StockEventPublisher публикует события изменения остатка
+type StockEventPublisher interface {
+	PublishStockChanged(ctx context.Context, event StockChangedEvent) error
+}
+
+// publishStockChanged публикует событие изменения остатка (best-effort)
+// Ошибка публикации только логируется: остаток уже изменён, и откатывать операцию из-за Kafka нельзя.
+// Поэтому события годятся для инвалидации кешей и аналитики, но не как источник истины об остатке.
+// Изменение также уходит подписчикам WatchStock этого инстанса
+func (s *InventoryService) publishStockChanged(ctx context.Context, event StockChangedEvent) {
+	event.EventID = uuid.NewString()
+	event.OccurredAt = time.Now().UTC()
+	// Подписчики WatchStock получают изменение и без Kafka
+	s.watchers.publish(event)
+	if s.events == nil {
+		return
+	}
+
+	if err := s.events.PublishStockChanged(ctx, event); err != nil {
+		log.Printf("Failed to publish %s: product=%s, reason=%s, delta=%d: %v",
+			EventTypeStockChanged, event.ProductID, event.Reason, event.Delta, err)
+	}
+}
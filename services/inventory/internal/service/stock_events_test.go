@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+// fakeStockEvents запоминает опубликованные события
+// Мок не генерируется: он импортировал бы service (StockChangedEvent) - цикл импортов с тестами service
+type fakeStockEvents struct {
+	events []StockChangedEvent
+	err    error
+}
+
+func (p *fakeStockEvents) PublishStockChanged(ctx context.Context, event StockChangedEvent) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestInventoryService_StockChangedEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reserve publishes negative delta and available after reservation", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		events := &fakeStockEvents{}
+		service := NewInventoryService(mockRepo, nil, nil, events, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(3)).Return(int32(7), true, nil).Once()
+
+		reserved, err := service.ReserveStock(ctx, "product-1", 3)
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Len(t, events.events, 1)
+		event := events.events[0]
+		require.NotEmpty(t, event.EventID)
+		require.False(t, event.OccurredAt.IsZero())
+		require.Equal(t, "product-1", event.ProductID)
+		require.Equal(t, int32(-3), event.Delta)
+		require.Equal(t, StockChangeReserved, event.Reason)
+		require.Equal(t, int32(7), *event.Available)
+	})
+
+	t.Run("insufficient stock publishes nothing", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		events := &fakeStockEvents{}
+		service := NewInventoryService(mockRepo, nil, nil, events, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(100)).Return(int32(0), false, nil).Once()
+
+		reserved, err := service.ReserveStock(ctx, "product-1", 100)
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.Empty(t, events.events)
+	})
+
+	t.Run("replenish publishes available after change", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		events := &fakeStockEvents{}
+		service := NewInventoryService(mockRepo, nil, nil, events, nil, nil, nil, nil)
+
+		mockRepo.On("AddStock", ctx, "product-1", int32(5)).Return(int32(12), nil).Once()
+
+		_, err := service.AddStock(ctx, "product-1", 5)
+
+		require.NoError(t, err)
+		require.Len(t, events.events, 1)
+		require.Equal(t, StockChangeReplenished, events.events[0].Reason)
+		require.Equal(t, int32(5), events.events[0].Delta)
+		require.Equal(t, int32(12), *events.events[0].Available)
+	})
+
+	t.Run("release and expiry carry reservation", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		events := &fakeStockEvents{}
+		service := NewInventoryService(mockRepo, mockReservations, nil, events, nil, nil, nil, nil)
+
+		now := time.Now()
+		released := repository.Reservation{ID: "res-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2}
+		expired := repository.Reservation{ID: "res-2", ProductID: "product-2", Quantity: 1}
+		mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).Return(released, nil).Once()
+		mockRepo.On("AddStock", ctx, "product-1", int32(2)).Return(int32(9), nil).Once()
+		mockReservations.On("ListExpiredReservations", ctx, now, expireReservationsBatch).Return([]repository.Reservation{expired}, nil).Once()
+		mockReservations.On("FinishReservation", ctx, "res-2", repository.ReservationStatusExpired).Return(expired, nil).Once()
+		mockRepo.On("AddStock", ctx, "product-2", int32(1)).Return(int32(4), nil).Once()
+
+		_, err := service.ReleaseReservation(ctx, "res-1")
+		require.NoError(t, err)
+		_, err = service.ExpireReservations(ctx, now)
+		require.NoError(t, err)
+
+		require.Len(t, events.events, 2)
+		require.Equal(t, StockChangeReleased, events.events[0].Reason)
+		require.Equal(t, "res-1", events.events[0].ReservationID)
+		require.Equal(t, "order-1", events.events[0].OrderID)
+		require.Equal(t, StockChangeExpired, events.events[1].Reason)
+		require.Equal(t, "res-2", events.events[1].ReservationID)
+		require.Equal(t, int32(4), *events.events[1].Available)
+	})
+
+	t.Run("batch rollback publishes released for returned items", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		events := &fakeStockEvents{}
+		service := NewInventoryService(mockRepo, mocks.NewReservationRepository(t), nil, events, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+		mockRepo.On("ReserveStock", ctx, "product-2", int32(1)).Return(int32(0), false, nil).Once()
+		mockRepo.On("AddStock", mock.Anything, "product-1", int32(1)).Return(int32(6), nil).Once()
+
+		_, reserved, err := service.ReserveStockBatch(ctx, ReserveStockBatchInput{
+			Items: []BatchItem{{ProductID: "product-1", Quantity: 1}, {ProductID: "product-2", Quantity: 1}},
+		})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.Len(t, events.events, 2)
+		require.Equal(t, StockChangeReserved, events.events[0].Reason)
+		require.Equal(t, StockChangeReleased, events.events[1].Reason)
+		require.Equal(t, int32(1), events.events[1].Delta)
+	})
+
+	t.Run("publish failure does not fail the operation", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		events := &fakeStockEvents{err: errors.New("kafka unavailable")}
+		service := NewInventoryService(mockRepo, nil, nil, events, nil, nil, nil, nil)
+
+		mockRepo.On("ReserveStock", ctx, "product-1", int32(1)).Return(int32(10), true, nil).Once()
+
+		reserved, err := service.ReserveStock(ctx, "product-1", 1)
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+var (
+	ErrInvalidMovementReason = errors.New("movement reason must be reserved, released, expired, replenished, adjustment, import or sync")
+	ErrInvalidMovementPeriod = errors.New("movement period start must be before its end")
+)
+
+// movementReasons - причины движения из StockChangedEvent.Reason
+// Корректировки (adjusted) в журнал пишут AdjustmentService (с adjustment_id) и StockSyncService вместе со складом
+var movementReasons = map[string]string{
+	StockChangeReserved:    repository.MovementReasonReserved,
+	StockChangeReleased:    repository.MovementReasonReleased,
+	StockChangeExpired:     repository.MovementReasonExpired,
+	StockChangeReplenished: repository.MovementReasonReplenished,
+}
+
+// ActorFunc возвращает user_id инициатора запроса из контекста; пустая строка - запрос самого сервиса
+type ActorFunc func(ctx context.Context) string
+
+// StockJournal пишет каждое изменение остатка в журнал движений (stock_movements) для сверки склада
+// Реализует StockEventPublisher: оборачивает публикацию inventory.stock.changed (next может быть nil)
+//
+// Журнал append-only: записи только добавляются. Запись best-effort, как и остальная публикация:
+// остаток уже изменён, поэтому ошибка записи логируется и не откатывает операцию
+type StockJournal struct {
+	next      StockEventPublisher
+	movements repository.MovementRepository
+	actor     ActorFunc
+}
+
+// NewStockJournal создаёт журнал движений; actor может быть nil - тогда инициатор не записывается
+func NewStockJournal(next StockEventPublisher, movements repository.MovementRepository, actor ActorFunc) *StockJournal {
+	return &StockJournal{
+		next:      next,
+		movements: movements,
+		actor:     actor,
+	}
+}
+
+// PublishStockChanged записывает движение и передаёт событие дальше
+// Возвращает только ошибку next
+func (j *StockJournal) PublishStockChanged(ctx context.Context, event StockChangedEvent) error {
+	j.record(ctx, event)
+	if j.next == nil {
+		return nil
+	}
+	return j.next.PublishStockChanged(ctx, event)
+}
+
+// record сохраняет движение по событию; корректировки и неизвестные причины пропускаются
+func (j *StockJournal) record(ctx context.Context, event StockChangedEvent) {
+	reason, ok := movementReasons[event.Reason]
+	if !ok {
+		return
+	}
+
+	movement := repository.StockMovement{
+		ID:            uuid.NewString(),
+		ProductID:     event.ProductID,
+		Delta:         event.Delta,
+		Reason:        reason,
+		ReservationID: event.ReservationID,
+		OrderID:       event.OrderID,
+		CreatedAt:     event.OccurredAt,
+	}
+	if event.Available != nil {
+		movement.Available = *event.Available
+	}
+	if j.actor != nil {
+		movement.Actor = j.actor(ctx)
+	}
+
+	// Запись не зависит от отмены запроса: остаток уже изменён
+	if err := j.movements.CreateMovements(context.WithoutCancel(ctx), []repository.StockMovement{movement}); err != nil {
+		log.Printf("Failed to record stock movement: product=%s, reason=%s, delta=%d: %v",
+			event.ProductID, reason, event.Delta, err)
+	}
+}
+
+// ListMovements возвращает движения остатка за период [filter.From, filter.To), новые первыми
+// limit <= 0 - 50, больше 200 - обрезается до 200
+func (j *StockJournal) ListMovements(ctx context.Context, filter repository.MovementFilter, limit int) ([]repository.StockMovement, error) {
+	if err := validateMovementFilter(filter); err != nil {
+		return nil, err
+	}
+	return j.movements.ListMovements(ctx, filter, listLimit(limit))
+}
+
+// validateMovementFilter проверяет причину и границы периода выборки
+func validateMovementFilter(filter repository.MovementFilter) error {
+	switch filter.Reason {
+	case "", repository.MovementReasonReserved, repository.MovementReasonReleased, repository.MovementReasonExpired,
+		repository.MovementReasonReplenished, repository.MovementReasonAdjustment, repository.MovementReasonImport,
+		repository.MovementReasonSync:
+	default:
+		return ErrInvalidMovementReason
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return ErrInvalidMovementPeriod
+	}
+	return nil
+}
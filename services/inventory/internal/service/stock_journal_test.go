@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestStockJournal(t *testing.T) {
+	ctx := context.Background()
+	occurredAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	actor := func(context.Context) string { return "user-1" }
+
+	t.Run("records movement and passes event on", func(t *testing.T) {
+		mockMovements := mocks.NewMovementRepository(t)
+		next := &fakeStockEvents{}
+		journal := NewStockJournal(next, mockMovements, actor)
+
+		available := int32(7)
+		event := StockChangedEvent{
+			ProductID:     "product-1",
+			Delta:         3,
+			Reason:        StockChangeReleased,
+			Available:     &available,
+			ReservationID: "reservation-1",
+			OrderID:       "order-1",
+			OccurredAt:    occurredAt,
+		}
+		mockMovements.On("CreateMovements", mock.Anything, mock.MatchedBy(func(movements []repository.StockMovement) bool {
+			if len(movements) != 1 {
+				return false
+			}
+			m := movements[0]
+			return m.ID != "" && m.ProductID == "product-1" && m.Delta == 3 &&
+				m.Available == 7 && m.Reason == repository.MovementReasonReleased &&
+				m.ReservationID == "reservation-1" && m.OrderID == "order-1" &&
+				m.Actor == "user-1" && m.CreatedAt.Equal(occurredAt)
+		})).Return(nil).Once()
+
+		require.NoError(t, journal.PublishStockChanged(ctx, event))
+		require.Len(t, next.events, 1)
+	})
+
+	t.Run("adjustments are recorded by adjustment service", func(t *testing.T) {
+		mockMovements := mocks.NewMovementRepository(t)
+		journal := NewStockJournal(nil, mockMovements, actor)
+
+		err := journal.PublishStockChanged(ctx, StockChangedEvent{ProductID: "product-1", Delta: -2, Reason: StockChangeAdjusted})
+
+		require.NoError(t, err)
+		mockMovements.AssertNotCalled(t, "CreateMovements", mock.Anything, mock.Anything)
+	})
+
+	t.Run("write error does not fail publication", func(t *testing.T) {
+		mockMovements := mocks.NewMovementRepository(t)
+		next := &fakeStockEvents{}
+		journal := NewStockJournal(next, mockMovements, nil)
+
+		mockMovements.On("CreateMovements", mock.Anything, mock.Anything).Return(errors.New("mongo down")).Once()
+
+		require.NoError(t, journal.PublishStockChanged(ctx, reservedEvent("product-1", 1, 4)))
+		require.Len(t, next.events, 1)
+	})
+
+	t.Run("list validates filter", func(t *testing.T) {
+		journal := NewStockJournal(nil, mocks.NewMovementRepository(t), nil)
+
+		_, err := journal.ListMovements(ctx, repository.MovementFilter{Reason: "stolen"}, 0)
+		require.ErrorIs(t, err, ErrInvalidMovementReason)
+
+		_, err = journal.ListMovements(ctx, repository.MovementFilter{From: occurredAt, To: occurredAt}, 0)
+		require.ErrorIs(t, err, ErrInvalidMovementPeriod)
+	})
+
+	t.Run("list passes period and clamps limit", func(t *testing.T) {
+		mockMovements := mocks.NewMovementRepository(t)
+		journal := NewStockJournal(nil, mockMovements, nil)
+		filter := repository.MovementFilter{
+			Reason: repository.MovementReasonReserved,
+			From:   occurredAt,
+			To:     occurredAt.Add(24 * time.Hour),
+		}
+		movements := []repository.StockMovement{{ID: "movement-1", ProductID: "product-1"}}
+
+		mockMovements.On("ListMovements", ctx, filter, maxListLimit).Return(movements, nil).Once()
+
+		got, err := journal.ListMovements(ctx, filter, 1000)
+		require.NoError(t, err)
+		require.Equal(t, movements, got)
+	})
+}
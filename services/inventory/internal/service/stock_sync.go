@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// ErrInvalidSyncQuantity - новый остаток склада меньше нуля (handler маппит в 400/InvalidArgument)
+var ErrInvalidSyncQuantity = errors.New("new quantity must not be negative")
+
+// StockSyncInput содержит остаток склада, который внешняя система считала текущим, и новое значение
+type StockSyncInput struct {
+	ProductID   string
+	WarehouseID string // пусто - склад по умолчанию
+	Expected    int32  // может быть отрицательным: склад по умолчанию уходит в минус при предзаказе
+	Quantity    int32
+}
+
+// StockSyncResult - итог синхронизации
+type StockSyncResult struct {
+	Applied   bool
+	Current   int32 // остаток склада: Quantity, если применено, иначе фактический
+	Available int32 // суммарный остаток товара
+}
+
+// StockSyncService выставляет остаток склада по данным внешней складской системы (compare-and-set)
+// Остаток меняется, только если он всё ещё равен тому, что система видела при пересчёте: резерв, прошедший
+// между пересчётом и записью, не затирается. При несовпадении возвращается фактический остаток, и система
+// решает сама - пересчитать или повторить с новым expected
+type StockSyncService struct {
+	inventory *InventoryService
+	stock     repository.StockCASRepository
+	movements repository.MovementRepository
+	actor     ActorFunc
+}
+
+// NewStockSyncService создаёт сервис синхронизации остатка; actor может быть nil - тогда инициатор не записывается
+// События inventory.stock.changed публикуются через inventory (причина adjusted), движение пишется с причиной sync
+func NewStockSyncService(inventory *InventoryService, stock repository.StockCASRepository, movements repository.MovementRepository, actor ActorFunc) *StockSyncService {
+	return &StockSyncService{
+		inventory: inventory,
+		stock:     stock,
+		movements: movements,
+		actor:     actor,
+	}
+}
+
+// AdjustStock выставляет остаток товара на складе в input.Quantity, если сейчас он равен input.Expected
+// Пустой warehouse_id - склад по умолчанию; другой склад должен быть в справочнике.
+// Несовпадение - не ошибка: Applied = false и фактический остаток в Current
+func (s *StockSyncService) AdjustStock(ctx context.Context, input StockSyncInput) (StockSyncResult, error) {
+	log.Printf("AdjustStock called: product=%s, warehouse=%q, expected=%d, quantity=%d",
+		input.ProductID, input.WarehouseID, input.Expected, input.Quantity)
+
+	if input.ProductID == "" {
+		return StockSyncResult{}, ErrProductIDRequired
+	}
+	if input.Quantity < 0 {
+		return StockSyncResult{}, ErrInvalidSyncQuantity
+	}
+	warehouseID, err := s.resolveWarehouse(ctx, input.WarehouseID)
+	if err != nil {
+		return StockSyncResult{}, err
+	}
+
+	available, ok, err := s.compareAndSet(ctx, input.ProductID, warehouseID, input.Expected, input.Quantity)
+	if err != nil {
+		log.Printf("AdjustStock error: product=%s: %v", input.ProductID, err)
+		return StockSyncResult{}, err
+	}
+	if !ok {
+		return s.mismatch(ctx, input.ProductID, warehouseID)
+	}
+
+	delta := input.Quantity - input.Expected
+	log.Printf("AdjustStock applied: product=%s, warehouse=%s, delta=%d, available=%d", input.ProductID, warehouseID, delta, available)
+	if delta != 0 {
+		s.inventory.publishStockChanged(ctx, StockChangedEvent{ProductID: input.ProductID, Delta: delta, Reason: StockChangeAdjusted, Available: &available})
+		s.record(ctx, input.ProductID, warehouseID, delta, available)
+	}
+	return StockSyncResult{Applied: true, Current: input.Quantity, Available: available}, nil
+}
+
+// resolveWarehouse возвращает ID склада для записи; склад кроме default требует настроенных складов
+func (s *StockSyncService) resolveWarehouse(ctx context.Context, warehouseID string) (string, error) {
+	if warehouseID == "" || warehouseID == repository.DefaultWarehouseID {
+		return repository.DefaultWarehouseID, nil
+	}
+	if s.inventory.allocator == nil {
+		return "", ErrWarehousesNotConfigured
+	}
+	if err := validateWarehouseID(warehouseID); err != nil {
+		return "", err
+	}
+	if _, err := s.inventory.allocator.warehouses.GetWarehouse(ctx, warehouseID); err != nil {
+		return "", err
+	}
+	return warehouseID, nil
+}
+
+// compareAndSet делает compare-and-set, повторяя его при write conflict
+// Повтор безопасен: условие на остаток проверяется заново
+func (s *StockSyncService) compareAndSet(ctx context.Context, productID, warehouseID string, expected, quantity int32) (int32, bool, error) {
+	for attempt := 0; ; attempt++ {
+		available, ok, err := s.stock.CompareAndSetWarehouseStock(ctx, productID, warehouseID, expected, quantity)
+		if !errors.Is(err, repository.ErrWriteConflict) || attempt >= reserveConflictRetries {
+			return available, ok, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		case <-time.After(reserveConflictBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// mismatch читает фактический остаток склада после неудачного compare-and-set
+// Чтение отдельное, поэтому к ответу остаток мог снова измениться; нет товара - остаток 0
+func (s *StockSyncService) mismatch(ctx context.Context, productID, warehouseID string) (StockSyncResult, error) {
+	stocks, err := s.stock.GetWarehouseStock(ctx, productID, repository.ReadConsistencyStrong)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return StockSyncResult{}, err
+	}
+
+	var result StockSyncResult
+	for _, ws := range stocks {
+		if ws.WarehouseID == warehouseID {
+			result.Current = ws.Quantity
+		}
+		result.Available += ws.Quantity
+	}
+	log.Printf("AdjustStock not applied: product=%s, warehouse=%s, current=%d", productID, warehouseID, result.Current)
+	return result, nil
+}
+
+// record пишет движение в журнал; остаток уже изменён, поэтому ошибка только логируется
+func (s *StockSyncService) record(ctx context.Context, productID, warehouseID string, delta, available int32) {
+	movement := repository.StockMovement{
+		ID:          uuid.NewString(),
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Delta:       delta,
+		Available:   available,
+		Reason:      repository.MovementReasonSync,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if s.actor != nil {
+		movement.Actor = s.actor(ctx)
+	}
+	if err := s.movements.CreateMovements(context.WithoutCancel(ctx), []repository.StockMovement{movement}); err != nil {
+		log.Printf("AdjustStock: failed to record stock movement: product=%s, error=%v", productID, err)
+	}
+}
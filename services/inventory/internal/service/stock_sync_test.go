@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestStockSyncService_AdjustStock(t *testing.T) {
+	ctx := context.Background()
+	actor := func(ctx context.Context) string { return "sync-bot" }
+
+	t.Run("applied: event published and movement recorded", func(t *testing.T) {
+		mockStock := mocks.NewStockCASRepository(t)
+		mockMovements := mocks.NewMovementRepository(t)
+		events := &fakeStockEvents{}
+		inventory := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, events, nil, nil, nil, nil)
+		service := NewStockSyncService(inventory, mockStock, mockMovements, actor)
+
+		mockStock.On("CompareAndSetWarehouseStock", ctx, "product-1", repository.DefaultWarehouseID, int32(10), int32(7)).
+			Return(int32(7), true, nil).Once()
+		mockMovements.On("CreateMovements", mock.Anything, mock.MatchedBy(func(m []repository.StockMovement) bool {
+			return len(m) == 1 && m[0].Delta == -3 && m[0].Available == 7 && m[0].Actor == "sync-bot" &&
+				m[0].WarehouseID == repository.DefaultWarehouseID && m[0].Reason == repository.MovementReasonSync
+		})).Return(nil).Once()
+
+		result, err := service.AdjustStock(ctx, StockSyncInput{ProductID: "product-1", Expected: 10, Quantity: 7})
+
+		require.NoError(t, err)
+		require.Equal(t, StockSyncResult{Applied: true, Current: 7, Available: 7}, result)
+		require.Len(t, events.events, 1)
+		require.Equal(t, StockChangeAdjusted, events.events[0].Reason)
+		require.Equal(t, int32(-3), events.events[0].Delta)
+	})
+
+	t.Run("applied without change: nothing published", func(t *testing.T) {
+		mockStock := mocks.NewStockCASRepository(t)
+		events := &fakeStockEvents{}
+		inventory := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, events, nil, nil, nil, nil)
+		service := NewStockSyncService(inventory, mockStock, mocks.NewMovementRepository(t), actor)
+
+		mockStock.On("CompareAndSetWarehouseStock", ctx, "product-1", repository.DefaultWarehouseID, int32(5), int32(5)).
+			Return(int32(5), true, nil).Once()
+
+		result, err := service.AdjustStock(ctx, StockSyncInput{ProductID: "product-1", Expected: 5, Quantity: 5})
+
+		require.NoError(t, err)
+		require.True(t, result.Applied)
+		require.Empty(t, events.events)
+	})
+
+	t.Run("mismatch: current stock returned, nothing changed", func(t *testing.T) {
+		mockStock := mocks.NewStockCASRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		events := &fakeStockEvents{}
+		inventory := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, events,
+			NewWarehouseAllocator(mocks.NewWarehouseStockRepository(t), mockWarehouses, ""), nil, nil, nil)
+		service := NewStockSyncService(inventory, mockStock, mocks.NewMovementRepository(t), actor)
+
+		mockWarehouses.On("GetWarehouse", ctx, "spb").Return(repository.Warehouse{ID: "spb"}, nil).Once()
+		mockStock.On("CompareAndSetWarehouseStock", ctx, "product-1", "spb", int32(10), int32(12)).
+			Return(int32(0), false, nil).Once()
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: repository.DefaultWarehouseID, Quantity: 3}, {WarehouseID: "spb", Quantity: 8}}, nil).Once()
+
+		result, err := service.AdjustStock(ctx, StockSyncInput{ProductID: "product-1", WarehouseID: "spb", Expected: 10, Quantity: 12})
+
+		require.NoError(t, err)
+		require.Equal(t, StockSyncResult{Applied: false, Current: 8, Available: 11}, result)
+		require.Empty(t, events.events)
+	})
+
+	t.Run("write conflict retried", func(t *testing.T) {
+		mockStock := mocks.NewStockCASRepository(t)
+		mockMovements := mocks.NewMovementRepository(t)
+		inventory := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, nil)
+		service := NewStockSyncService(inventory, mockStock, mockMovements, nil)
+
+		mockStock.On("CompareAndSetWarehouseStock", ctx, "product-1", repository.DefaultWarehouseID, int32(0), int32(4)).
+			Return(int32(0), false, repository.ErrWriteConflict).Once()
+		mockStock.On("CompareAndSetWarehouseStock", ctx, "product-1", repository.DefaultWarehouseID, int32(0), int32(4)).
+			Return(int32(4), true, nil).Once()
+		mockMovements.On("CreateMovements", mock.Anything, mock.Anything).Return(nil).Once()
+
+		result, err := service.AdjustStock(ctx, StockSyncInput{ProductID: "product-1", Expected: 0, Quantity: 4})
+
+		require.NoError(t, err)
+		require.True(t, result.Applied)
+	})
+
+	tests := []struct {
+		name          string
+		input         StockSyncInput
+		expectedError error
+	}{
+		{
+			name:          "no product",
+			input:         StockSyncInput{Quantity: 1},
+			expectedError: ErrProductIDRequired,
+		},
+		{
+			name:          "negative quantity",
+			input:         StockSyncInput{ProductID: "product-1", Expected: 1, Quantity: -1},
+			expectedError: ErrInvalidSyncQuantity,
+		},
+		{
+			name:          "warehouse without warehouses configured",
+			input:         StockSyncInput{ProductID: "product-1", WarehouseID: "spb", Quantity: 1},
+			expectedError: ErrWarehousesNotConfigured,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, nil)
+			service := NewStockSyncService(inventory, mocks.NewStockCASRepository(t), mocks.NewMovementRepository(t), nil)
+
+			_, err := service.AdjustStock(ctx, tt.input)
+
+			require.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
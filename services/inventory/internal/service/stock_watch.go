@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// stockWatchResync - как часто WatchStock перечитывает остаток из хранилища
+// Изменения, сделанные этим инстансом, приходят сразу через StockWatchHub; пересинхронизация доносит
+// изменения других инстансов и inventory-import, которые хаб не видит
+const stockWatchResync = 15 * time.Second
+
+// StockUpdate - остаток товара в потоке WatchStock
+type StockUpdate struct {
+	ProductID  string
+	Available  int32
+	Found      bool   // false - товара нет в хранилище, Available = 0
+	Reason     string // StockChange*; пусто для начального снимка и пересинхронизации
+	OccurredAt time.Time
+}
+
+// StockWatchHub рассылает изменения остатка подписчикам WatchStock внутри инстанса
+// Подписчику важен только последний остаток: если он не успевает читать, промежуточные изменения теряются
+type StockWatchHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan StockChangedEvent]struct{} // по product_id
+}
+
+// NewStockWatchHub создаёт пустой хаб подписок
+func NewStockWatchHub() *StockWatchHub {
+	return &StockWatchHub{subs: make(map[string]map[chan StockChangedEvent]struct{})}
+}
+
+// subscribe подписывается на изменения остатка товара; вызвавший обязан вызвать возвращённую функцию отписки
+func (h *StockWatchHub) subscribe(productID string) (<-chan StockChangedEvent, func()) {
+	ch := make(chan StockChangedEvent, 1)
+
+	h.mu.Lock()
+	if h.subs[productID] == nil {
+		h.subs[productID] = make(map[chan StockChangedEvent]struct{})
+	}
+	h.subs[productID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[productID], ch)
+		if len(h.subs[productID]) == 0 {
+			delete(h.subs, productID)
+		}
+	}
+}
+
+// publish отправляет изменение подписчикам товара, не блокируясь на медленных:
+// непрочитанное предыдущее изменение заменяется новым
+func (h *StockWatchHub) publish(event StockChangedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[event.ProductID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// WatchStock отправляет в send текущий остаток товара, а затем каждое его изменение, пока ctx не отменён
+// Одинаковый остаток подряд не отправляется. Ошибка send (клиент отключился) завершает наблюдение с этой ошибкой
+func (s *InventoryService) WatchStock(ctx context.Context, productID string, send func(StockUpdate) error) error {
+	if productID == "" {
+		return ErrProductIDRequired
+	}
+	log.Printf("WatchStock started for product: %s", productID)
+	defer log.Printf("WatchStock stopped for product: %s", productID)
+
+	// Подписка до чтения снимка: изменение между чтением и подпиской не потеряется
+	changes, unsubscribe := s.watchers.subscribe(productID)
+	defer unsubscribe()
+
+	var last *StockUpdate
+	emit := func(update StockUpdate) error {
+		if last != nil && last.Available == update.Available && last.Found == update.Found {
+			return nil
+		}
+		if err := send(update); err != nil {
+			return err
+		}
+		last = &update
+		return nil
+	}
+
+	update, err := s.readStockUpdate(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if err := emit(update); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(stockWatchResync)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-changes:
+			if event.Available == nil {
+				// Остаток после изменения неизвестен - перечитываем
+				if update, err = s.readStockUpdate(ctx, productID); err != nil {
+					return err
+				}
+			} else {
+				update = StockUpdate{ProductID: productID, Available: *event.Available, Found: true}
+			}
+			update.Reason = event.Reason
+			update.OccurredAt = event.OccurredAt
+			if err := emit(update); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if update, err = s.readStockUpdate(ctx, productID); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("WatchStock resync failed: product=%s: %v", productID, err)
+				continue
+			}
+			if err := emit(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readStockUpdate читает текущий остаток товара; отсутствие товара - не ошибка
+func (s *InventoryService) readStockUpdate(ctx context.Context, productID string) (StockUpdate, error) {
+	available, err := s.repo.GetStock(ctx, productID, repository.ReadConsistencyDefault)
+	if errors.Is(err, repository.ErrNotFound) {
+		return StockUpdate{ProductID: productID, OccurredAt: time.Now().UTC()}, nil
+	}
+	if err != nil {
+		return StockUpdate{}, err
+	}
+	return StockUpdate{ProductID: productID, Available: available, Found: true, OccurredAt: time.Now().UTC()}, nil
+}
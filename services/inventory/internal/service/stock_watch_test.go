@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+// watchStockAsync запускает WatchStock и возвращает канал отправленных обновлений и канал результата
+func watchStockAsync(ctx context.Context, s *InventoryService, productID string) (<-chan StockUpdate, <-chan error) {
+	updates := make(chan StockUpdate, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchStock(ctx, productID, func(update StockUpdate) error {
+			updates <- update
+			return nil
+		})
+	}()
+	return updates, done
+}
+
+func receiveUpdate(t *testing.T, updates <-chan StockUpdate) StockUpdate {
+	t.Helper()
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("stock update not received")
+		return StockUpdate{}
+	}
+}
+
+func TestInventoryService_WatchStock(t *testing.T) {
+	t.Run("snapshot then changes of this instance, repeated stock skipped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetStock", ctx, "product-1", repository.ReadConsistencyDefault).Return(int32(10), nil).Once()
+		updates, done := watchStockAsync(ctx, service, "product-1")
+
+		snapshot := receiveUpdate(t, updates)
+		require.Equal(t, StockUpdate{ProductID: "product-1", Available: 10, Found: true, OccurredAt: snapshot.OccurredAt}, snapshot)
+
+		unchanged, reserved := int32(10), int32(7)
+		service.publishStockChanged(ctx, StockChangedEvent{ProductID: "product-1", Delta: 0, Reason: StockChangeAdjusted, Available: &unchanged})
+		service.publishStockChanged(ctx, StockChangedEvent{ProductID: "product-2", Delta: -1, Reason: StockChangeReserved, Available: &reserved})
+		service.publishStockChanged(ctx, StockChangedEvent{ProductID: "product-1", Delta: -3, Reason: StockChangeReserved, Available: &reserved})
+
+		update := receiveUpdate(t, updates)
+		require.Equal(t, int32(7), update.Available)
+		require.Equal(t, StockChangeReserved, update.Reason)
+		require.Empty(t, updates)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("unknown available is re-read", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		mockRepo := mocks.NewInventoryRepository(t)
+		service := NewInventoryService(mockRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetStock", ctx, "product-1", repository.ReadConsistencyDefault).Return(int32(0), repository.ErrNotFound).Once()
+		updates, done := watchStockAsync(ctx, service, "product-1")
+		require.False(t, receiveUpdate(t, updates).Found)
+
+		mockRepo.On("GetStock", ctx, "product-1", repository.ReadConsistencyDefault).Return(int32(5), nil).Once()
+		service.publishStockChanged(ctx, StockChangedEvent{ProductID: "product-1", Delta: 5, Reason: StockChangeReleased})
+
+		update := receiveUpdate(t, updates)
+		require.True(t, update.Found)
+		require.Equal(t, int32(5), update.Available)
+		require.Equal(t, StockChangeReleased, update.Reason)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("empty product_id", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, nil)
+
+		err := service.WatchStock(context.Background(), "", func(StockUpdate) error { return nil })
+
+		require.ErrorIs(t, err, ErrProductIDRequired)
+	})
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+)
+
+// AllocationStrategy задаёт, с каких складов списывается резервирование
+type AllocationStrategy string
+
+const (
+	// AllocationStrategyDefault - стратегия из конфига сервиса (INVENTORY_ALLOCATION_STRATEGY)
+	AllocationStrategyDefault AllocationStrategy = ""
+	// AllocationStrategyPriority - склады по приоритету; чего не хватило на первом, добирается со следующих
+	AllocationStrategyPriority AllocationStrategy = "priority"
+	// AllocationStrategyMostStock - сначала склады с наибольшим остатком: резерв дробится на меньшее число складов
+	AllocationStrategyMostStock AllocationStrategy = "most_stock"
+	// AllocationStrategySingleWarehouse - резерв целиком с одного склада (первого по приоритету, где хватает товара)
+	AllocationStrategySingleWarehouse AllocationStrategy = "single_warehouse"
+)
+
+// Ошибки складов (handler маппит в codes.InvalidArgument, ErrWarehousesNotConfigured - в codes.FailedPrecondition)
+var (
+	ErrWarehouseIDRequired       = errors.New("warehouse_id is required")
+	ErrInvalidWarehouseID        = errors.New("warehouse_id must be 1-64 characters: letters, digits, '-' or '_'")
+	ErrWarehouseNameRequired     = errors.New("warehouse name is required")
+	ErrInvalidWarehousePriority  = errors.New("warehouse priority must not be negative")
+	ErrInvalidAllocationStrategy = errors.New("unknown allocation strategy")
+	ErrWarehousesNotConfigured   = errors.New("warehouses are not configured")
+)
+
+// warehouseIDPattern - допустимый ID склада; он входит в путь поля документа остатка, поэтому без точек и $
+var warehouseIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// WarehouseAllocator распределяет резервирование по складам
+// План строится по прочитанным остаткам, а списание - одно условное обновление: если остаток какого-то склада
+// успел измениться, оно не проходит и возвращается ErrWriteConflict, по которому InventoryService перестраивает план
+type WarehouseAllocator struct {
+	stock           repository.WarehouseStockRepository
+	warehouses      repository.WarehouseRepository
+	defaultStrategy AllocationStrategy
+}
+
+// NewWarehouseAllocator создаёт распределитель по складам
+// defaultStrategy используется для резервирований без явной стратегии; пусто - AllocationStrategyPriority
+func NewWarehouseAllocator(stock repository.WarehouseStockRepository, warehouses repository.WarehouseRepository, defaultStrategy AllocationStrategy) *WarehouseAllocator {
+	if defaultStrategy == AllocationStrategyDefault {
+		defaultStrategy = AllocationStrategyPriority
+	}
+	return &WarehouseAllocator{
+		stock:           stock,
+		warehouses:      warehouses,
+		defaultStrategy: defaultStrategy,
+	}
+}
+
+// reserve делает одну попытку распределить и списать quantity товара
+// Возвращает суммарный остаток после списания и распределение; false без ошибки - товара недостаточно
+func (a *WarehouseAllocator) reserve(ctx context.Context, productID string, quantity int32, strategy AllocationStrategy) (int32, []repository.WarehouseStock, bool, error) {
+	if strategy == AllocationStrategyDefault {
+		strategy = a.defaultStrategy
+	}
+
+	stocks, err := a.stock.GetWarehouseStock(ctx, productID, repository.ReadConsistencyStrong)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, nil, false, nil
+	}
+	if err != nil {
+		return 0, nil, false, err
+	}
+	warehouses, err := a.warehouses.ListWarehouses(ctx)
+	if err != nil {
+		return 0, nil, false, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+
+	plan := planAllocation(strategy, quantity, stocks, warehouses)
+	if plan == nil {
+		return 0, nil, false, nil
+	}
+
+	available, ok, err := a.stock.ReserveWarehouseStock(ctx, productID, plan)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if !ok {
+		// Остаток склада изменился между чтением и списанием: ничего не списано, план строится заново
+		return 0, nil, false, fmt.Errorf("%w: warehouse stock changed during allocation", repository.ErrWriteConflict)
+	}
+	return available, plan, true, nil
+}
+
+// planAllocation распределяет quantity по остаткам складов согласно strategy
+// Склады без записи в справочнике идут после зарегистрированных (например, default, если его не завели)
+// Возвращает nil, если товара недостаточно
+func planAllocation(strategy AllocationStrategy, quantity int32, stocks []repository.WarehouseStock, warehouses []repository.Warehouse) []repository.WarehouseStock {
+	priority := make(map[string]int32, len(warehouses))
+	for _, w := range warehouses {
+		priority[w.ID] = w.Priority
+	}
+	priorityOf := func(warehouseID string) int64 {
+		if p, ok := priority[warehouseID]; ok {
+			return int64(p)
+		}
+		return math.MaxInt32 + 1
+	}
+
+	candidates := make([]repository.WarehouseStock, 0, len(stocks))
+	for _, s := range stocks {
+		if s.Quantity > 0 {
+			candidates = append(candidates, s)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if strategy == AllocationStrategyMostStock && ci.Quantity != cj.Quantity {
+			return ci.Quantity > cj.Quantity
+		}
+		if pi, pj := priorityOf(ci.WarehouseID), priorityOf(cj.WarehouseID); pi != pj {
+			return pi < pj
+		}
+		return ci.WarehouseID < cj.WarehouseID
+	})
+
+	if strategy == AllocationStrategySingleWarehouse {
+		for _, c := range candidates {
+			if c.Quantity >= quantity {
+				return []repository.WarehouseStock{{WarehouseID: c.WarehouseID, Quantity: quantity}}
+			}
+		}
+		return nil
+	}
+
+	var plan []repository.WarehouseStock
+	remaining := quantity
+	for _, c := range candidates {
+		take := min(c.Quantity, remaining)
+		plan = append(plan, repository.WarehouseStock{WarehouseID: c.WarehouseID, Quantity: take})
+		remaining -= take
+		if remaining == 0 {
+			return plan
+		}
+	}
+	return nil
+}
+
+// validateAllocationStrategy проверяет стратегию из запроса
+func validateAllocationStrategy(strategy AllocationStrategy) error {
+	switch strategy {
+	case AllocationStrategyDefault, AllocationStrategyPriority, AllocationStrategyMostStock, AllocationStrategySingleWarehouse:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidAllocationStrategy, strategy)
+}
+
+// validateWarehouseID проверяет ID склада
+func validateWarehouseID(warehouseID string) error {
+	if warehouseID == "" {
+		return ErrWarehouseIDRequired
+	}
+	if !warehouseIDPattern.MatchString(warehouseID) {
+		return ErrInvalidWarehouseID
+	}
+	return nil
+}
+
+// WarehouseInput содержит поля склада для создания и обновления
+type WarehouseInput struct {
+	WarehouseID string
+	Name        string
+	Priority    int32 // меньше - раньше при стратегии priority
+}
+
+// WarehouseService содержит бизнес-логику справочника складов
+type WarehouseService struct {
+	warehouses repository.WarehouseRepository
+}
+
+// NewWarehouseService создаёт сервис справочника складов
+func NewWarehouseService(warehouses repository.WarehouseRepository) *WarehouseService {
+	return &WarehouseService{
+		warehouses: warehouses,
+	}
+}
+
+// CreateWarehouse регистрирует склад
+// Возвращает repository.ErrWarehouseAlreadyExists, если warehouse_id занят
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, input WarehouseInput) (repository.Warehouse, error) {
+	log.Printf("CreateWarehouse called: warehouse=%s", input.WarehouseID)
+
+	warehouse, err := warehouseFromInput(input)
+	if err != nil {
+		return repository.Warehouse{}, err
+	}
+	now := time.Now().UTC()
+	warehouse.CreatedAt = now
+	warehouse.UpdatedAt = now
+
+	if err := s.warehouses.CreateWarehouse(ctx, warehouse); err != nil {
+		log.Printf("CreateWarehouse error: warehouse=%s: %v", warehouse.ID, err)
+		return repository.Warehouse{}, err
+	}
+
+	log.Printf("Warehouse created: id=%s, priority=%d", warehouse.ID, warehouse.Priority)
+	return warehouse, nil
+}
+
+// GetWarehouse возвращает склад
+// Возвращает repository.ErrWarehouseNotFound, если склада нет
+func (s *WarehouseService) GetWarehouse(ctx context.Context, warehouseID string) (repository.Warehouse, error) {
+	if err := validateWarehouseID(warehouseID); err != nil {
+		return repository.Warehouse{}, err
+	}
+	return s.warehouses.GetWarehouse(ctx, warehouseID)
+}
+
+// UpdateWarehouse заменяет название и приоритет склада
+// Возвращает repository.ErrWarehouseNotFound, если склада нет
+func (s *WarehouseService) UpdateWarehouse(ctx context.Context, input WarehouseInput) (repository.Warehouse, error) {
+	log.Printf("UpdateWarehouse called: warehouse=%s", input.WarehouseID)
+
+	warehouse, err := warehouseFromInput(input)
+	if err != nil {
+		return repository.Warehouse{}, err
+	}
+	warehouse.UpdatedAt = time.Now().UTC()
+
+	updated, err := s.warehouses.UpdateWarehouse(ctx, warehouse)
+	if err != nil {
+		log.Printf("UpdateWarehouse error: warehouse=%s: %v", warehouse.ID, err)
+		return repository.Warehouse{}, err
+	}
+	return updated, nil
+}
+
+// DeleteWarehouse удаляет склад из справочника
+// Возвращает repository.ErrWarehouseNotFound, если склада нет, и repository.ErrWarehouseNotEmpty, если на нём есть остаток
+func (s *WarehouseService) DeleteWarehouse(ctx context.Context, warehouseID string) error {
+	log.Printf("DeleteWarehouse called: warehouse=%s", warehouseID)
+
+	if err := validateWarehouseID(warehouseID); err != nil {
+		return err
+	}
+	if err := s.warehouses.DeleteWarehouse(ctx, warehouseID); err != nil {
+		log.Printf("DeleteWarehouse error: warehouse=%s: %v", warehouseID, err)
+		return err
+	}
+	return nil
+}
+
+// ListWarehouses возвращает все склады в порядке приоритета
+func (s *WarehouseService) ListWarehouses(ctx context.Context) ([]repository.Warehouse, error) {
+	return s.warehouses.ListWarehouses(ctx)
+}
+
+// warehouseFromInput проверяет поля склада и собирает repository.Warehouse
+func warehouseFromInput(input WarehouseInput) (repository.Warehouse, error) {
+	if err := validateWarehouseID(input.WarehouseID); err != nil {
+		return repository.Warehouse{}, err
+	}
+	if input.Name == "" {
+		return repository.Warehouse{}, ErrWarehouseNameRequired
+	}
+	if input.Priority < 0 {
+		return repository.Warehouse{}, ErrInvalidWarehousePriority
+	}
+	return repository.Warehouse{
+		ID:       input.WarehouseID,
+		Name:     input.Name,
+		Priority: input.Priority,
+	}, nil
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository"
+	"github.com/shestoi/GoBigTech/services/inventory/internal/repository/mocks"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	warehouses := []repository.Warehouse{
+		{ID: "msk", Priority: 1},
+		{ID: "spb", Priority: 2},
+		{ID: "ekb", Priority: 3},
+	}
+	stocks := []repository.WarehouseStock{
+		{WarehouseID: "default", Quantity: 100},
+		{WarehouseID: "ekb", Quantity: 8},
+		{WarehouseID: "msk", Quantity: 3},
+		{WarehouseID: "spb", Quantity: 5},
+	}
+
+	tests := []struct {
+		name     string
+		strategy AllocationStrategy
+		quantity int32
+		stocks   []repository.WarehouseStock
+		want     []repository.WarehouseStock
+	}{
+		{
+			name:     "priority: fits on first warehouse",
+			strategy: AllocationStrategyPriority,
+			quantity: 2,
+			stocks:   stocks,
+			want:     []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 2}},
+		},
+		{
+			name:     "priority: split across warehouses in priority order",
+			strategy: AllocationStrategyPriority,
+			quantity: 10,
+			stocks:   stocks,
+			want: []repository.WarehouseStock{
+				{WarehouseID: "msk", Quantity: 3},
+				{WarehouseID: "spb", Quantity: 5},
+				{WarehouseID: "ekb", Quantity: 2},
+			},
+		},
+		{
+			name:     "priority: unregistered warehouse goes last",
+			strategy: AllocationStrategyPriority,
+			quantity: 20,
+			stocks:   stocks,
+			want: []repository.WarehouseStock{
+				{WarehouseID: "msk", Quantity: 3},
+				{WarehouseID: "spb", Quantity: 5},
+				{WarehouseID: "ekb", Quantity: 8},
+				{WarehouseID: "default", Quantity: 4},
+			},
+		},
+		{
+			name:     "most_stock: largest warehouse first",
+			strategy: AllocationStrategyMostStock,
+			quantity: 101,
+			stocks:   stocks,
+			want: []repository.WarehouseStock{
+				{WarehouseID: "default", Quantity: 100},
+				{WarehouseID: "ekb", Quantity: 1},
+			},
+		},
+		{
+			name:     "single_warehouse: first by priority with enough stock",
+			strategy: AllocationStrategySingleWarehouse,
+			quantity: 4,
+			stocks:   stocks,
+			want:     []repository.WarehouseStock{{WarehouseID: "spb", Quantity: 4}},
+		},
+		{
+			name:     "single_warehouse: no warehouse has enough",
+			strategy: AllocationStrategySingleWarehouse,
+			quantity: 9,
+			stocks:   []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 5}, {WarehouseID: "spb", Quantity: 5}},
+			want:     nil,
+		},
+		{
+			name:     "insufficient total stock",
+			strategy: AllocationStrategyPriority,
+			quantity: 200,
+			stocks:   stocks,
+			want:     nil,
+		},
+		{
+			name:     "empty warehouses are skipped",
+			strategy: AllocationStrategyPriority,
+			quantity: 1,
+			stocks:   []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 0}, {WarehouseID: "spb", Quantity: 1}},
+			want:     []repository.WarehouseStock{{WarehouseID: "spb", Quantity: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, planAllocation(tt.strategy, tt.quantity, tt.stocks, warehouses))
+		})
+	}
+}
+
+func TestInventoryService_CreateReservation_Warehouses(t *testing.T) {
+	ctx := context.Background()
+	warehouses := []repository.Warehouse{{ID: "msk", Priority: 1}, {ID: "spb", Priority: 2}}
+
+	t.Run("split across warehouses and saved with allocations", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockStock := mocks.NewWarehouseStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, allocator, nil, nil, nil)
+
+		want := []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 2}, {WarehouseID: "spb", Quantity: 3}}
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: "msk", Quantity: 2}, {WarehouseID: "spb", Quantity: 10}}, nil).Once()
+		mockWarehouses.On("ListWarehouses", ctx).Return(warehouses, nil).Once()
+		mockStock.On("ReserveWarehouseStock", ctx, "product-1", want).Return(int32(7), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.MatchedBy(func(r repository.Reservation) bool {
+			return r.Quantity == 5 && len(r.Allocations) == 2
+		})).Return(nil).Once()
+
+		reservation, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 5})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Equal(t, want, reservation.Allocations)
+	})
+
+	t.Run("stock changed between read and write: allocation is replanned", func(t *testing.T) {
+		mockRepo := mocks.NewInventoryRepository(t)
+		mockReservations := mocks.NewReservationRepository(t)
+		mockStock := mocks.NewWarehouseStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		service := NewInventoryService(mockRepo, mockReservations, nil, nil, allocator, nil, nil, nil)
+
+		mockWarehouses.On("ListWarehouses", ctx).Return(warehouses, nil).Twice()
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: "msk", Quantity: 5}, {WarehouseID: "spb", Quantity: 5}}, nil).Once()
+		mockStock.On("ReserveWarehouseStock", ctx, "product-1", []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 3}}).
+			Return(int32(0), false, nil).Once()
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: "msk", Quantity: 1}, {WarehouseID: "spb", Quantity: 5}}, nil).Once()
+		mockStock.On("ReserveWarehouseStock", ctx, "product-1", []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 1}, {WarehouseID: "spb", Quantity: 2}}).
+			Return(int32(3), true, nil).Once()
+		mockReservations.On("CreateReservation", ctx, mock.Anything).Return(nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 3})
+
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+
+	t.Run("single_warehouse: insufficient on every warehouse", func(t *testing.T) {
+		mockStock := mocks.NewWarehouseStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		allocator := NewWarehouseAllocator(mockStock, mockWarehouses, AllocationStrategyPriority)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, allocator, nil, nil, nil)
+
+		mockStock.On("GetWarehouseStock", ctx, "product-1", repository.ReadConsistencyStrong).
+			Return([]repository.WarehouseStock{{WarehouseID: "msk", Quantity: 2}, {WarehouseID: "spb", Quantity: 2}}, nil).Once()
+		mockWarehouses.On("ListWarehouses", ctx).Return(warehouses, nil).Once()
+
+		_, reserved, err := service.CreateReservation(ctx, CreateReservationInput{
+			ProductID: "product-1",
+			Quantity:  3,
+			Strategy:  AllocationStrategySingleWarehouse,
+		})
+
+		require.NoError(t, err)
+		require.False(t, reserved)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), mocks.NewReservationRepository(t), nil, nil, nil, nil, nil, nil)
+
+		_, _, err := service.CreateReservation(ctx, CreateReservationInput{ProductID: "product-1", Quantity: 1, Strategy: "nearest"})
+
+		require.ErrorIs(t, err, ErrInvalidAllocationStrategy)
+	})
+}
+
+func TestInventoryService_ReleaseReservation_Warehouses(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewInventoryRepository(t)
+	mockReservations := mocks.NewReservationRepository(t)
+	mockStock := mocks.NewWarehouseStockRepository(t)
+	allocator := NewWarehouseAllocator(mockStock, mocks.NewWarehouseRepository(t), AllocationStrategyPriority)
+	service := NewInventoryService(mockRepo, mockReservations, nil, nil, allocator, nil, nil, nil)
+
+	allocations := []repository.WarehouseStock{{WarehouseID: "msk", Quantity: 1}, {WarehouseID: "spb", Quantity: 2}}
+	mockReservations.On("FinishReservation", ctx, "res-1", repository.ReservationStatusReleased).
+		Return(repository.Reservation{ID: "res-1", ProductID: "product-1", Quantity: 3, Allocations: allocations}, nil).Once()
+	mockStock.On("AddWarehouseStock", ctx, "product-1", allocations).Return(int32(8), nil).Once()
+
+	reservation, err := service.ReleaseReservation(ctx, "res-1")
+
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reservation.Quantity)
+}
+
+func TestInventoryService_AddWarehouseStock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("registered warehouse", func(t *testing.T) {
+		mockStock := mocks.NewWarehouseStockRepository(t)
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, NewWarehouseAllocator(mockStock, mockWarehouses, ""), nil, nil, nil)
+
+		mockWarehouses.On("GetWarehouse", ctx, "spb").Return(repository.Warehouse{ID: "spb"}, nil).Once()
+		mockStock.On("AddWarehouseStock", ctx, "product-1", []repository.WarehouseStock{{WarehouseID: "spb", Quantity: 4}}).
+			Return(int32(14), nil).Once()
+
+		available, err := service.AddWarehouseStock(ctx, "product-1", "spb", 4)
+
+		require.NoError(t, err)
+		require.Equal(t, int32(14), available)
+	})
+
+	t.Run("unknown warehouse", func(t *testing.T) {
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil,
+			NewWarehouseAllocator(mocks.NewWarehouseStockRepository(t), mockWarehouses, ""), nil, nil, nil)
+
+		mockWarehouses.On("GetWarehouse", ctx, "nowhere").Return(repository.Warehouse{}, repository.ErrWarehouseNotFound).Once()
+
+		_, err := service.AddWarehouseStock(ctx, "product-1", "nowhere", 4)
+
+		require.ErrorIs(t, err, repository.ErrWarehouseNotFound)
+	})
+
+	t.Run("warehouses not configured", func(t *testing.T) {
+		service := NewInventoryService(mocks.NewInventoryRepository(t), nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := service.AddWarehouseStock(ctx, "product-1", "spb", 4)
+
+		require.ErrorIs(t, err, ErrWarehousesNotConfigured)
+	})
+}
+
+func TestWarehouseService_CreateWarehouse(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mockWarehouses := mocks.NewWarehouseRepository(t)
+		service := NewWarehouseService(mockWarehouses)
+
+		mockWarehouses.On("CreateWarehouse", ctx, mock.MatchedBy(func(w repository.Warehouse) bool {
+			return w.ID == "msk" && w.Name == "Москва" && w.Priority == 1 && !w.CreatedAt.IsZero()
+		})).Return(nil).Once()
+
+		warehouse, err := service.CreateWarehouse(ctx, WarehouseInput{WarehouseID: "msk", Name: "Москва", Priority: 1})
+
+		require.NoError(t, err)
+		require.Equal(t, "msk", warehouse.ID)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := NewWarehouseService(mocks.NewWarehouseRepository(t))
+
+		_, err := service.CreateWarehouse(ctx, WarehouseInput{Name: "Москва"})
+		require.ErrorIs(t, err, ErrWarehouseIDRequired)
+
+		_, err = service.CreateWarehouse(ctx, WarehouseInput{WarehouseID: "msk.1", Name: "Москва"})
+		require.ErrorIs(t, err, ErrInvalidWarehouseID)
+
+		_, err = service.CreateWarehouse(ctx, WarehouseInput{WarehouseID: "msk"})
+		require.ErrorIs(t, err, ErrWarehouseNameRequired)
+
+		_, err = service.CreateWarehouse(ctx, WarehouseInput{WarehouseID: "msk", Name: "Москва", Priority: -1})
+		require.ErrorIs(t, err, ErrInvalidWarehousePriority)
+	})
+}
@@ -0,0 +1,515 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	_ "github.com/jackc/pgx/v5/stdlib" //для goose миграций
+
+	tc "github.com/testcontainers/testcontainers-go"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	grpcclient "github.com/shestoi/GoBigTech/services/notification/internal/client/grpc"
+	"github.com/shestoi/GoBigTech/services/notification/internal/delivery"
+	eventkafka "github.com/shestoi/GoBigTech/services/notification/internal/event/kafka"
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	notifrepo "github.com/shestoi/GoBigTech/services/notification/internal/repository/postgres"
+	"github.com/shestoi/GoBigTech/services/notification/internal/service"
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+	"github.com/shestoi/GoBigTech/services/notification/internal/templates"
+)
+
+const (
+	botToken         = "e2e-token"
+	retryMaxAttempts = 3
+	retryBackoffBase = 100 * time.Millisecond
+	waitTimeout      = 30 * time.Second
+)
+
+// Полный путь уведомления: Kafka → consumer → inbox (dedupe) → шаблон → Telegram (fake) → inbox/outbox sent
+// Telegram Bot API подменён локальным HTTP сервером: он записывает запросы и по сценарию отвечает 429/5xx
+func TestNotification_E2E(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	pool := startPostgres(t, ctx)
+	brokers := startKafka(t, ctx)
+
+	fake := newFakeTelegram()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	iam := fakeIAM{
+		"user-sent":   "1001",
+		"user-retry":  "1002",
+		"user-broken": "1003",
+		"user-outbox": "1004",
+	}
+	env := pipelineEnv{pool: pool, brokers: brokers, telegramURL: srv.URL, iam: iam}
+
+	t.Run("event sent once, duplicate skipped", func(t *testing.T) {
+		p := env.start(t, ctx, "sent", false)
+
+		p.produce(t, ctx, paymentEvent("evt-sent-1", "order-sent-1", "user-sent"))
+		require.Eventually(t, func() bool { return len(fake.delivered("1001")) == 1 }, waitTimeout, 100*time.Millisecond)
+
+		text := fake.delivered("1001")[0]
+		require.Contains(t, text, "Заказ оплачен")
+		require.Contains(t, text, "order-sent-1")
+		require.Contains(t, text, "150000")
+		require.Equal(t, "sent", inboxStatus(t, ctx, pool, "evt-sent-1"))
+
+		// Повтор того же события (redelivery) и следующее событие: второе доходит, повтор - нет
+		p.produce(t, ctx, paymentEvent("evt-sent-1", "order-sent-1", "user-sent"))
+		p.produce(t, ctx, paymentEvent("evt-sent-2", "order-sent-2", "user-sent"))
+		require.Eventually(t, func() bool { return len(fake.delivered("1001")) == 2 }, waitTimeout, 100*time.Millisecond)
+
+		require.Contains(t, fake.delivered("1001")[1], "order-sent-2")
+		require.Equal(t, 2, fake.attempts("1001"))
+	})
+
+	t.Run("telegram 500 and 429 retried by consumer", func(t *testing.T) {
+		fake.script("1002", http.StatusInternalServerError, http.StatusTooManyRequests)
+		p := env.start(t, ctx, "retry", false)
+
+		p.produce(t, ctx, paymentEvent("evt-retry-1", "order-retry-1", "user-retry"))
+		require.Eventually(t, func() bool { return len(fake.delivered("1002")) == 1 }, waitTimeout, 100*time.Millisecond)
+
+		require.Equal(t, 3, fake.attempts("1002"))
+		require.Equal(t, "sent", inboxStatus(t, ctx, pool, "evt-retry-1"))
+	})
+
+	t.Run("poison message and exhausted retries go to DLQ", func(t *testing.T) {
+		fake.failAlways("1003", http.StatusBadGateway)
+		p := env.start(t, ctx, "dlq", false)
+
+		p.produceRaw(t, ctx, kafka.Message{Key: []byte("poison"), Value: []byte("not json")})
+		p.produce(t, ctx, paymentEvent("evt-dlq-1", "order-dlq-1", "user-broken"))
+
+		dlq := p.readDLQ(t, ctx, 2)
+
+		var poison eventkafka.DLQMessage
+		require.NoError(t, json.Unmarshal(dlq[0].Value, &poison))
+		require.Equal(t, p.topic, poison.OriginalTopic)
+		require.Equal(t, "not json", poison.OriginalValue)
+		require.NotEmpty(t, poison.ErrorMessage)
+
+		var exhausted eventkafka.DLQMessage
+		require.NoError(t, json.Unmarshal(dlq[1].Value, &exhausted))
+		require.Equal(t, "evt-dlq-1", exhausted.EventID)
+		require.Equal(t, "order-dlq-1", exhausted.OrderID)
+		require.Equal(t, "exhausted all retry attempts", exhausted.ErrorMessage)
+		require.Equal(t, "order-dlq-1", string(dlq[1].Key))
+		require.Equal(t, retryMaxAttempts, platformkafka.HeadersFromKafka(dlq[1].Headers).RetryCount())
+
+		// Событие не отправлено: inbox остаётся pending с ошибкой последней попытки
+		require.Equal(t, retryMaxAttempts, fake.attempts("1003"))
+		require.Empty(t, fake.delivered("1003"))
+		var status, lastError string
+		require.NoError(t, pool.QueryRow(ctx,
+			`SELECT status, COALESCE(last_error, '') FROM notification_inbox_events WHERE event_id = $1`, "evt-dlq-1",
+		).Scan(&status, &lastError))
+		require.Equal(t, "pending", status)
+		require.Contains(t, lastError, "status 502")
+	})
+
+	t.Run("outbox: worker retries after 429 and marks sent", func(t *testing.T) {
+		fake.script("1004", http.StatusTooManyRequests)
+		p := env.start(t, ctx, "outbox", true)
+
+		p.produce(t, ctx, paymentEvent("evt-outbox-1", "order-outbox-1", "user-outbox"))
+		require.Eventually(t, func() bool {
+			return outboxStatus(t, ctx, pool, "evt-outbox-1") == repository.OutboxStatusSent
+		}, waitTimeout, 100*time.Millisecond)
+
+		require.Len(t, fake.delivered("1004"), 1)
+		require.Contains(t, fake.delivered("1004")[0], "order-outbox-1")
+		require.Equal(t, 2, fake.attempts("1004"))
+		// Inbox помечается sent при постановке в outbox, до отправки
+		require.Equal(t, "sent", inboxStatus(t, ctx, pool, "evt-outbox-1"))
+
+		var attempts int
+		var lastError string
+		require.NoError(t, pool.QueryRow(ctx,
+			`SELECT attempts, COALESCE(last_error, '') FROM notification_outbox WHERE event_id = $1`, "evt-outbox-1",
+		).Scan(&attempts, &lastError))
+		require.Equal(t, 1, attempts)
+		require.Contains(t, lastError, "status 429")
+	})
+}
+
+// startPostgres поднимает PostgreSQL и накатывает миграции сервиса
+func startPostgres(t *testing.T, ctx context.Context) *pgxpool.Pool {
+	t.Helper()
+
+	postgresContainer, err := postgres.RunContainer(ctx,
+		tc.WithImage("postgres:15-alpine"),
+		postgres.WithDatabase("notifications"),
+		postgres.WithUsername("notification_user"),
+		postgres.WithPassword("notification_password"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, postgresContainer.Terminate(context.Background())) })
+
+	dsn, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Ждём готовности БД через ping с retry
+	var pingErr error
+	for i := 0; i < 10; i++ {
+		pingErr = db.PingContext(ctx)
+		if pingErr == nil {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+	require.NoError(t, pingErr, "Failed to ping database after retries")
+
+	require.NoError(t, goose.UpContext(ctx, db, filepath.Join(serviceDir(t), "migrations")), "Failed to run migrations")
+
+	pool, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// startKafka поднимает Kafka (KRaft, один брокер) и возвращает адреса брокеров
+func startKafka(t *testing.T, ctx context.Context) []string {
+	t.Helper()
+
+	kafkaContainer, err := tckafka.RunContainer(ctx,
+		tc.WithImage("confluentinc/confluent-local:7.5.0"),
+		tckafka.WithClusterID("notification-e2e"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, kafkaContainer.Terminate(context.Background())) })
+
+	brokers, err := kafkaContainer.Brokers(ctx)
+	require.NoError(t, err)
+	return brokers
+}
+
+// serviceDir возвращает services/notification относительно текущего файла (services/notification/e2e)
+func serviceDir(t *testing.T) string {
+	t.Helper()
+	_, filename, _, ok := runtime.Caller(0)
+	require.True(t, ok, "Failed to get current file path")
+	return filepath.Dir(filepath.Dir(filename))
+}
+
+// pipelineEnv - общие для сценариев зависимости
+type pipelineEnv struct {
+	pool        *pgxpool.Pool
+	brokers     []string
+	telegramURL string
+	iam         fakeIAM
+}
+
+// pipeline - consumer событий оплаты со своими топиками и consumer group
+type pipeline struct {
+	brokers  []string
+	topic    string
+	dlqTopic string
+}
+
+// start собирает и запускает consumer событий оплаты как в app.Build; withOutbox - отправка через delivery worker
+// Топики и consumer group у каждого сценария свои, поэтому сценарии не видят событий друг друга
+func (e pipelineEnv) start(t *testing.T, ctx context.Context, name string, withOutbox bool) *pipeline {
+	t.Helper()
+
+	p := &pipeline{
+		brokers:  e.brokers,
+		topic:    "order.payment.completed." + name,
+		dlqTopic: "notification.dlq." + name,
+	}
+	createTopics(t, e.brokers, p.topic, p.dlqTopic)
+
+	logger := zap.NewNop()
+	repo := notifrepo.NewRepository(e.pool)
+	sender := telegram.NewTelegramSender(logger, e.telegramURL, botToken)
+	renderer, err := templates.NewRenderer(logger, filepath.Join(serviceDir(t), "templates"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	var outbox repository.OutboxRepository
+	if withOutbox {
+		outbox = repo
+		worker := delivery.NewWorker(logger, repo, sender, 10, 100*time.Millisecond, 5, 200*time.Millisecond, 0)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = worker.Start(ctx)
+		}()
+	}
+
+	svc := service.NewNotificationService(logger, repo, sender, renderer, e.iam, 0, outbox, nil)
+	dlqPublisher := eventkafka.NewDLQPublisher(logger, e.brokers, p.dlqTopic)
+	consumer := eventkafka.NewOrderPaidConsumer(logger, e.brokers, "notification-e2e-"+name, p.topic, svc, dlqPublisher,
+		retryMaxAttempts, retryBackoffBase)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = consumer.Start(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+		_ = consumer.Close()
+		_ = dlqPublisher.Close()
+	})
+	return p
+}
+
+// produce публикует событие оплаты с заголовками, как их пишет outbox Order
+func (p *pipeline) produce(t *testing.T, ctx context.Context, event map[string]interface{}) {
+	t.Helper()
+
+	value, err := json.Marshal(event)
+	require.NoError(t, err)
+	eventID, _ := event["event_id"].(string)
+	orderID, _ := event["order_id"].(string)
+	headers := platformkafka.NewHeaders(ctx, "order.payment.completed", eventID, 1)
+	p.produceRaw(t, ctx, kafka.Message{Key: []byte(orderID), Value: value, Headers: headers.Kafka()})
+}
+
+// produceRaw публикует сообщение в топик сценария как есть
+func (p *pipeline) produceRaw(t *testing.T, ctx context.Context, msg kafka.Message) {
+	t.Helper()
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(p.brokers...),
+		Topic:        p.topic,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer writer.Close()
+	require.NoError(t, writer.WriteMessages(ctx, msg))
+}
+
+// readDLQ ждёт n сообщений в DLQ топике сценария
+func (p *pipeline) readDLQ(t *testing.T, ctx context.Context, n int) []kafka.Message {
+	t.Helper()
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   p.brokers,
+		Topic:     p.dlqTopic,
+		Partition: 0,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	messages := make([]kafka.Message, 0, n)
+	for len(messages) < n {
+		m, err := reader.ReadMessage(ctx)
+		require.NoError(t, err, "DLQ: got %d of %d messages", len(messages), n)
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// createTopics создаёт топики с одной партицией: порядок сообщений в сценарии сохраняется
+func createTopics(t *testing.T, brokers []string, topics ...string) {
+	t.Helper()
+
+	conn, err := kafka.Dial("tcp", brokers[0])
+	require.NoError(t, err)
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	require.NoError(t, err)
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	require.NoError(t, err)
+	defer controllerConn.Close()
+
+	configs := make([]kafka.TopicConfig, 0, len(topics))
+	for _, topic := range topics {
+		configs = append(configs, kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+	}
+	require.NoError(t, controllerConn.CreateTopics(configs...))
+}
+
+// paymentEvent - событие order.payment.completed в формате Order
+func paymentEvent(eventID, orderID, userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"event_id":       eventID,
+		"event_type":     "order.payment.completed",
+		"event_version":  1,
+		"occurred_at":    time.Now().UTC().Format(time.RFC3339),
+		"order_id":       orderID,
+		"user_id":        userID,
+		"amount":         150000,
+		"currency":       "RUB",
+		"payment_method": "card",
+	}
+}
+
+// inboxStatus возвращает статус события в notification_inbox_events
+func inboxStatus(t *testing.T, ctx context.Context, pool *pgxpool.Pool, eventID string) string {
+	t.Helper()
+	var status string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT status FROM notification_inbox_events WHERE event_id = $1`, eventID).Scan(&status))
+	return status
+}
+
+// outboxStatus возвращает статус сообщения в notification_outbox; сообщения ещё нет - пустая строка
+func outboxStatus(t *testing.T, ctx context.Context, pool *pgxpool.Pool, eventID string) string {
+	t.Helper()
+	var status string
+	err := pool.QueryRow(ctx, `SELECT status FROM notification_outbox WHERE event_id = $1`, eventID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ""
+	}
+	require.NoError(t, err)
+	return status
+}
+
+// fakeIAM отдаёт telegram_id пользователя по user_id
+type fakeIAM map[string]string
+
+// GetUserContact реализует grpcclient.IAMClient
+func (f fakeIAM) GetUserContact(ctx context.Context, userID string) (*grpcclient.UserContact, error) {
+	chatID, ok := f[userID]
+	if !ok {
+		return nil, fmt.Errorf("unknown user %s", userID)
+	}
+	return &grpcclient.UserContact{TelegramID: &chatID, PreferredChannel: "telegram"}, nil
+}
+
+// telegramRequest - запрос sendMessage, который получил fake
+type telegramRequest struct {
+	ChatID string
+	Text   string
+	Status int
+}
+
+// fakeTelegram - локальная подмена Telegram Bot API: записывает запросы sendMessage
+// и по сценарию чата отвечает ошибками (429 с retry_after, 5xx) до успешного ответа
+type fakeTelegram struct {
+	mu        sync.Mutex
+	requests  []telegramRequest
+	scripted  map[string][]int // chat_id -> коды ответов следующих запросов
+	failing   map[string]int   // chat_id -> код ответа на все запросы
+	messageID int64
+}
+
+func newFakeTelegram() *fakeTelegram {
+	return &fakeTelegram{
+		scripted: make(map[string][]int),
+		failing:  make(map[string]int),
+	}
+}
+
+// script задаёт коды ответов следующих запросов в чат; после них запросы успешны
+func (f *fakeTelegram) script(chatID string, statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripted[chatID] = append(f.scripted[chatID], statuses...)
+}
+
+// failAlways отвечает status на все запросы в чат
+func (f *fakeTelegram) failAlways(chatID string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing[chatID] = status
+}
+
+// delivered возвращает тексты успешно отправленных в чат сообщений по порядку
+func (f *fakeTelegram) delivered(chatID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var texts []string
+	for _, r := range f.requests {
+		if r.ChatID == chatID && r.Status == http.StatusOK {
+			texts = append(texts, r.Text)
+		}
+	}
+	return texts
+}
+
+// attempts возвращает число запросов sendMessage в чат, включая неуспешные
+func (f *fakeTelegram) attempts(chatID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if r.ChatID == chatID {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/bot"+botToken+"/sendMessage" {
+		http.NotFound(w, r)
+		return
+	}
+	var payload struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: invalid JSON"}`))
+		return
+	}
+
+	f.mu.Lock()
+	status := http.StatusOK
+	if code, ok := f.failing[payload.ChatID]; ok {
+		status = code
+	} else if queue := f.scripted[payload.ChatID]; len(queue) > 0 {
+		status, f.scripted[payload.ChatID] = queue[0], queue[1:]
+	}
+	f.requests = append(f.requests, telegramRequest{ChatID: payload.ChatID, Text: payload.Text, Status: status})
+	f.messageID++
+	messageID := f.messageID
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch status {
+	case http.StatusOK:
+		_, _ = fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"chat":{"id":%s}}}`, messageID, payload.ChatID)
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`))
+	default:
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"ok":false,"error_code":%d,"description":"%s"}`, status, http.StatusText(status))
+	}
+}
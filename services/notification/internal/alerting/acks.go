@@ -0,0 +1,178 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/client/alertmanager"
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+)
+
+// maxTrackedAlerts - сколько последних алертов помнит AckService; на более старые команды не действуют
+const maxTrackedAlerts = 1000
+
+// trackedAlert - отправленный в чат алерт, на который можно ответить /ack или /silence
+type trackedAlert struct {
+	messageID int64             // первое сообщение алерта: его текст редактируется
+	text      string            // исходный текст первого сообщения
+	labels    map[string]string // общие метки группы: по ним создаётся silence
+}
+
+// AckService отправляет алерты в чат дежурных и обрабатывает ответы на них:
+// /ack заглушает группу алертов на ackDuration, /silence <длительность> - на указанное время.
+// Silence создаётся в Alertmanager по общим меткам группы, исходное сообщение дополняется строкой о том, кто и до когда заглушил.
+// Соответствие message_id → алерт хранится в памяти: после рестарта сервиса команды работают только для новых алертов.
+type AckService struct {
+	logger      *zap.Logger
+	bot         telegram.Bot
+	silences    alertmanager.Client
+	chatID      string
+	ackDuration time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	alerts map[int64]*trackedAlert // по message_id каждой части алерта
+	order  [][]int64               // message_id частей в порядке отправки, для вытеснения старых
+}
+
+// NewAckService создаёт сервис алертов с командами; chatID - чат дежурных (ALERT_TELEGRAM_CHAT_ID)
+func NewAckService(logger *zap.Logger, bot telegram.Bot, silences alertmanager.Client, chatID string, ackDuration time.Duration) *AckService {
+	return &AckService{
+		logger:      logger,
+		bot:         bot,
+		silences:    silences,
+		chatID:      chatID,
+		ackDuration: ackDuration,
+		now:         time.Now,
+		alerts:      make(map[int64]*trackedAlert),
+	}
+}
+
+// SendAlert отправляет алерт в чат дежурных и запоминает его сообщения для команд
+func (s *AckService) SendAlert(ctx context.Context, text string, labels map[string]string) error {
+	ids, err := s.bot.SendMessages(ctx, s.chatID, text)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	alert := &trackedAlert{
+		messageID: ids[0],
+		text:      telegram.SplitMessage(text, telegram.MaxMessageLength, telegram.MaxMessageParts)[0],
+		labels:    labels,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.alerts[id] = alert
+	}
+	s.order = append(s.order, ids)
+	if len(s.order) > maxTrackedAlerts {
+		for _, id := range s.order[0] {
+			delete(s.alerts, id)
+		}
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+// HandleMessage обрабатывает сообщение из чата: команды /ack и /silence в ответ на алерт
+// Сообщения из других чатов и не-команды игнорируются; об ошибках пользователь узнаёт ответом в чат
+func (s *AckService) HandleMessage(ctx context.Context, msg *telegram.Message) {
+	if msg == nil || strconv.FormatInt(msg.Chat.ID, 10) != s.chatID {
+		return
+	}
+	cmd, arg, ok := parseCommand(msg.Text)
+	if !ok {
+		return
+	}
+
+	logger := s.logger.With(
+		zap.String("command", cmd),
+		zap.String("user", msg.From.DisplayName()),
+		zap.Int64("message_id", msg.MessageID),
+	)
+
+	if msg.ReplyToMessage == nil {
+		s.reply(ctx, logger, msg.MessageID, "Отправьте "+cmd+" ответом на сообщение с алертом")
+		return
+	}
+
+	s.mu.Lock()
+	alert := s.alerts[msg.ReplyToMessage.MessageID]
+	s.mu.Unlock()
+	if alert == nil {
+		s.reply(ctx, logger, msg.MessageID, "Алерт не найден: сообщение слишком старое или отправлено до перезапуска сервиса")
+		return
+	}
+	if len(alert.labels) == 0 {
+		s.reply(ctx, logger, msg.MessageID, "У алерта нет общих меток, silence создайте в Alertmanager вручную")
+		return
+	}
+
+	duration := s.ackDuration
+	if cmd == commandSilence {
+		var err error
+		duration, err = parseSilenceDuration(arg)
+		if err != nil {
+			s.reply(ctx, logger, msg.MessageID, fmt.Sprintf("%v. Пример: /silence 2h", err))
+			return
+		}
+	}
+
+	startsAt := s.now().UTC()
+	endsAt := startsAt.Add(duration)
+	silenceID, err := s.silences.CreateSilence(ctx, alertmanager.Silence{
+		Matchers:  alertmanager.EqualMatchers(alert.labels),
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: msg.From.DisplayName(),
+		Comment:   cmd + " via Telegram",
+	})
+	if err != nil {
+		logger.Error("failed to create silence", zap.Error(err))
+		s.reply(ctx, logger, msg.MessageID, "Не удалось создать silence в Alertmanager: "+err.Error())
+		return
+	}
+	logger.Info("alert silenced",
+		zap.String("silence_id", silenceID),
+		zap.Time("ends_at", endsAt),
+	)
+
+	status := ackStatusLine(cmd, msg.From.DisplayName(), endsAt, silenceID)
+	if err := s.bot.EditMessageText(ctx, s.chatID, alert.messageID, withStatusLine(status, alert.text)); err != nil {
+		// Silence уже создан: сообщаем о нём ответом, раз не удалось отметить в самом алерте
+		logger.Warn("failed to edit alert message", zap.Error(err), zap.Int64("alert_message_id", alert.messageID))
+		s.reply(ctx, logger, msg.MessageID, status)
+	}
+}
+
+// reply отвечает на команду; ошибка только логируется
+func (s *AckService) reply(ctx context.Context, logger *zap.Logger, replyTo int64, text string) {
+	if err := s.bot.Reply(ctx, s.chatID, replyTo, text); err != nil {
+		logger.Warn("failed to reply to command", zap.Error(err))
+	}
+}
+
+// ackStatusLine описывает состояние алерта после команды
+func ackStatusLine(cmd, user string, endsAt time.Time, silenceID string) string {
+	action := "ACK"
+	if cmd == commandSilence {
+		action = "Silenced"
+	}
+	return fmt.Sprintf("🔕 %s: %s, silence до %s UTC (id %s)", action, user, endsAt.Format("2006-01-02 15:04"), silenceID)
+}
+
+// withStatusLine ставит строку состояния перед текстом алерта, укладываясь в лимит одного сообщения
+func withStatusLine(status, text string) string {
+	return telegram.TruncateMessage(status+"\n\n"+strings.TrimSpace(text), telegram.MaxMessageLength)
+}
@@ -0,0 +1,149 @@
+package alerting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/client/alertmanager"
+	amocks "github.com/shestoi/GoBigTech/services/notification/internal/client/alertmanager/mocks"
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+	tmocks "github.com/shestoi/GoBigTech/services/notification/internal/telegram/mocks"
+)
+
+const testChatID = "-1001"
+
+func newTestAckService(t *testing.T) (*AckService, *tmocks.Bot, *amocks.Client) {
+	bot := tmocks.NewBot(t)
+	silences := amocks.NewClient(t)
+	service := NewAckService(zap.NewNop(), bot, silences, testChatID, time.Hour)
+	service.now = func() time.Time { return time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC) }
+	return service, bot, silences
+}
+
+// sendTestAlert отправляет алерт, который приходит в чат сообщениями 100 и 101
+func sendTestAlert(t *testing.T, service *AckService, bot *tmocks.Bot) {
+	bot.On("SendMessages", mock.Anything, testChatID, "🔥 HighOrderRate").Return([]int64{100, 101}, nil).Once()
+	if err := service.SendAlert(context.Background(), "🔥 HighOrderRate", map[string]string{"alertname": "HighOrderRate", "severity": "warning"}); err != nil {
+		t.Fatalf("send alert: %v", err)
+	}
+}
+
+func command(text string, replyTo int64) *telegram.Message {
+	msg := &telegram.Message{
+		MessageID: 200,
+		From:      &telegram.User{Username: "oncall"},
+		Chat:      telegram.Chat{ID: -1001},
+		Text:      text,
+	}
+	if replyTo != 0 {
+		msg.ReplyToMessage = &telegram.Message{MessageID: replyTo}
+	}
+	return msg
+}
+
+func TestAckService_HandleMessage(t *testing.T) {
+	t.Run("ack creates silence by common labels and edits first alert message", func(t *testing.T) {
+		service, bot, silences := newTestAckService(t)
+		sendTestAlert(t, service, bot)
+
+		silences.On("CreateSilence", mock.Anything, mock.MatchedBy(func(s alertmanager.Silence) bool {
+			return len(s.Matchers) == 2 &&
+				s.Matchers[0] == alertmanager.Matcher{Name: "alertname", Value: "HighOrderRate", IsEqual: true} &&
+				s.Matchers[1] == alertmanager.Matcher{Name: "severity", Value: "warning", IsEqual: true} &&
+				s.EndsAt.Sub(s.StartsAt) == time.Hour &&
+				s.CreatedBy == "@oncall"
+		})).Return("silence-1", nil).Once()
+		bot.On("EditMessageText", mock.Anything, testChatID, int64(100), mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "🔕 ACK: @oncall, silence до 2026-01-02 11:00 UTC (id silence-1)") &&
+				strings.HasSuffix(text, "🔥 HighOrderRate")
+		})).Return(nil).Once()
+
+		// Ответ на вторую часть алерта тоже относится к нему
+		service.HandleMessage(context.Background(), command("/ack", 101))
+	})
+
+	t.Run("silence with bot name uses given duration", func(t *testing.T) {
+		service, bot, silences := newTestAckService(t)
+		sendTestAlert(t, service, bot)
+
+		silences.On("CreateSilence", mock.Anything, mock.MatchedBy(func(s alertmanager.Silence) bool {
+			return s.EndsAt.Sub(s.StartsAt) == 2*time.Hour
+		})).Return("silence-2", nil).Once()
+		bot.On("EditMessageText", mock.Anything, testChatID, int64(100), mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "🔕 Silenced: @oncall")
+		})).Return(nil).Once()
+
+		service.HandleMessage(context.Background(), command("/silence@gobigtech_bot 2h", 100))
+	})
+
+	t.Run("invalid duration is reported without silence", func(t *testing.T) {
+		service, bot, _ := newTestAckService(t)
+		sendTestAlert(t, service, bot)
+
+		bot.On("Reply", mock.Anything, testChatID, int64(200), mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "/silence 2h")
+		})).Return(nil).Once()
+
+		service.HandleMessage(context.Background(), command("/silence forever", 100))
+	})
+
+	t.Run("reply to unknown message is reported", func(t *testing.T) {
+		service, bot, _ := newTestAckService(t)
+
+		bot.On("Reply", mock.Anything, testChatID, int64(200), mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "Алерт не найден")
+		})).Return(nil).Once()
+
+		service.HandleMessage(context.Background(), command("/ack", 42))
+	})
+
+	t.Run("edit failure is reported by reply", func(t *testing.T) {
+		service, bot, silences := newTestAckService(t)
+		sendTestAlert(t, service, bot)
+
+		silences.On("CreateSilence", mock.Anything, mock.Anything).Return("silence-3", nil).Once()
+		bot.On("EditMessageText", mock.Anything, testChatID, int64(100), mock.Anything).Return(context.DeadlineExceeded).Once()
+		bot.On("Reply", mock.Anything, testChatID, int64(200), mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "silence-3")
+		})).Return(nil).Once()
+
+		service.HandleMessage(context.Background(), command("/ack", 100))
+	})
+
+	t.Run("other chats and plain messages are ignored", func(t *testing.T) {
+		service, bot, _ := newTestAckService(t)
+		sendTestAlert(t, service, bot)
+
+		other := command("/ack", 100)
+		other.Chat.ID = 555
+		service.HandleMessage(context.Background(), other)
+		service.HandleMessage(context.Background(), command("смотрю", 100))
+		service.HandleMessage(context.Background(), nil)
+	})
+}
+
+func TestParseSilenceDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"30m":  30 * time.Minute,
+		"2h":   2 * time.Hour,
+		"1d":   24 * time.Hour,
+		"168h": MaxSilenceDuration,
+	}
+	for input, want := range valid {
+		got, err := parseSilenceDuration(input)
+		if err != nil || got != want {
+			t.Errorf("parseSilenceDuration(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "forever", "-1h", "0d", "8d"} {
+		if _, err := parseSilenceDuration(input); err == nil {
+			t.Errorf("parseSilenceDuration(%q): expected error", input)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Команды дежурных в ответ на алерт
+const (
+	commandAck     = "/ack"     // заглушить группу на ALERT_ACK_SILENCE_DURATION
+	commandSilence = "/silence" // заглушить группу на указанное время: /silence 2h, /silence 1d
+)
+
+// MaxSilenceDuration - на сколько максимум можно заглушить алерт из Telegram
+const MaxSilenceDuration = 7 * 24 * time.Hour
+
+// parseCommand выделяет команду и аргумент из текста сообщения
+// В группах Telegram дописывает имя бота (/ack@gobigtech_bot), оно отбрасывается
+func parseCommand(text string) (cmd, arg string, ok bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", "", false
+	}
+
+	cmd, _, _ = strings.Cut(strings.ToLower(fields[0]), "@")
+	if cmd != commandAck && cmd != commandSilence {
+		return "", "", false
+	}
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+	return cmd, arg, true
+}
+
+// parseSilenceDuration разбирает длительность silence: формат time.ParseDuration (30m, 2h) или дни (1d)
+func parseSilenceDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("не указана длительность")
+	}
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("неверная длительность %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("неверная длительность %q", s)
+		}
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("длительность должна быть положительной: %q", s)
+	}
+	if d > MaxSilenceDuration {
+		return 0, fmt.Errorf("длительность больше %s: %q", MaxSilenceDuration, s)
+	}
+	return d, nil
+}
@@ -0,0 +1,64 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+)
+
+const (
+	// pollTimeout - long polling getUpdates: Telegram держит запрос, пока нет новых сообщений
+	pollTimeout = 30 * time.Second
+	// pollRetryDelay - пауза после ошибки getUpdates
+	pollRetryDelay = 5 * time.Second
+)
+
+// CommandPoller получает сообщения бота через getUpdates и передаёт их в AckService
+// Бот не должен иметь webhook (setWebhook), иначе Telegram не отдаёт обновления через getUpdates
+type CommandPoller struct {
+	logger *zap.Logger
+	bot    telegram.Bot
+	acks   *AckService
+}
+
+// NewCommandPoller создаёт poller команд
+func NewCommandPoller(logger *zap.Logger, bot telegram.Bot, acks *AckService) *CommandPoller {
+	return &CommandPoller{
+		logger: logger,
+		bot:    bot,
+		acks:   acks,
+	}
+}
+
+// Start читает обновления до отмены контекста
+// Offset подтверждает обработанные обновления: после рестарта Telegram не отдаст их повторно
+func (p *CommandPoller) Start(ctx context.Context) error {
+	p.logger.Info("Starting Telegram alert command poller")
+
+	var offset int64
+	for {
+		updates, err := p.bot.GetUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				p.logger.Info("Telegram alert command poller stopped")
+				return nil
+			}
+			p.logger.Warn("telegram getUpdates failed", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				p.logger.Info("Telegram alert command poller stopped")
+				return nil
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			p.acks.HandleMessage(ctx, update.Message)
+		}
+	}
+}
@@ -37,19 +37,30 @@ type alertItem struct {
 	Fingerprint  string            `json:"fingerprint"`
 }
 
+// ackHint дописывается к firing-алертам, на которые можно ответить командой
+const ackHint = "Ответьте /ack или /silence 2h, чтобы заглушить алерт"
+
+// AlertTracker отправляет алерт в чат дежурных и запоминает его для команд /ack и /silence (см. alerting.AckService)
+type AlertTracker interface {
+	SendAlert(ctx context.Context, text string, labels map[string]string) error
+}
+
 // AlertmanagerHandler обрабатывает POST /alerts/alertmanager от Alertmanager и шлёт уведомления в Telegram.
 type AlertmanagerHandler struct {
 	logger         *zap.Logger
 	telegramSender telegram.Sender
 	alertChatID    string
+	tracker        AlertTracker
 }
 
 // NewAlertmanagerHandler создаёт обработчик webhook алертов.
-func NewAlertmanagerHandler(logger *zap.Logger, telegramSender telegram.Sender, alertChatID string) *AlertmanagerHandler {
+// tracker может быть nil: тогда команды /ack и /silence не поддерживаются и все алерты уходят через telegramSender.
+func NewAlertmanagerHandler(logger *zap.Logger, telegramSender telegram.Sender, alertChatID string, tracker AlertTracker) *AlertmanagerHandler {
 	return &AlertmanagerHandler{
 		logger:         logger,
 		telegramSender: telegramSender,
 		alertChatID:    alertChatID,
+		tracker:        tracker,
 	}
 }
 
@@ -77,7 +88,15 @@ func (h *AlertmanagerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	if err := h.telegramSender.Send(ctx, h.alertChatID, text); err != nil {
+	// Firing-алерты отправляем через tracker, чтобы на них можно было ответить /ack или /silence;
+	// silence создаётся по общим меткам группы (commonLabels)
+	var err error
+	if h.tracker != nil && payload.Status == "firing" {
+		err = h.tracker.SendAlert(ctx, text+"\n\n"+ackHint, payload.CommonLabels)
+	} else {
+		err = h.telegramSender.Send(ctx, h.alertChatID, text)
+	}
+	if err != nil {
 		h.logger.Error("alertmanager webhook: telegram send failed", zap.Error(err), zap.String("chat_id", h.alertChatID))
 		http.Error(w, "failed to send alert", http.StatusInternalServerError)
 		return
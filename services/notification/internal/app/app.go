@@ -5,18 +5,27 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	platformshutdown "github.com/shestoi/GoBigTech/platform/shutdown"
+	"github.com/shestoi/GoBigTech/services/notification/internal/alerting"
 	httpapi "github.com/shestoi/GoBigTech/services/notification/internal/api/http"
+	"github.com/shestoi/GoBigTech/services/notification/internal/client/alertmanager"
 	grpcclient "github.com/shestoi/GoBigTech/services/notification/internal/client/grpc"
+	orderclient "github.com/shestoi/GoBigTech/services/notification/internal/client/order"
 	"github.com/shestoi/GoBigTech/services/notification/internal/config"
+	"github.com/shestoi/GoBigTech/services/notification/internal/delivery"
 	eventkafka "github.com/shestoi/GoBigTech/services/notification/internal/event/kafka"
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
 	"github.com/shestoi/GoBigTech/services/notification/internal/repository/postgres"
 	"github.com/shestoi/GoBigTech/services/notification/internal/service"
 	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
@@ -27,27 +36,58 @@ import (
 type App struct {
 	logger           *zap.Logger
 	alertServer      *http.Server
+	commandPoller    *alerting.CommandPoller // nil, если команды /ack и /silence выключены
 	paymentConsumer  *eventkafka.OrderPaidConsumer
 	assemblyConsumer *eventkafka.OrderAssemblyCompletedConsumer
+	stockLowConsumer *eventkafka.StockLowConsumer // nil, если KAFKA_INVENTORY_STOCK_LOW_TOPIC пустой
+	deliveryWorker   *delivery.Worker             // nil, если NOTIFICATION_OUTBOX_ENABLED выключен
 	shutdownMgr      *platformshutdown.Manager
 	wg               sync.WaitGroup
+
+	// Зависимости, готовность которых проверяется до запуска consumers
+	readinessChecks  []readinessCheck
+	readinessTimeout time.Duration
 }
 
 // Build создаёт и настраивает все зависимости Notification Service
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "notification",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// OpenTelemetry: traces + metrics (noop если OTEL_ENABLED=false); consumers продолжают trace из заголовков Kafka
+	otelCfg := platformobservability.Config{
+		Enabled:               cfg.OTelEnabled,
+		OTLPEndpoint:          cfg.OTelEndpoint,
+		SamplingRatio:         cfg.OTelSamplingRatio,
+		ServiceName:           "notification",
+		DeploymentEnvironment: string(cfg.AppEnv),
+	}
+	otelShutdown, err := platformobservability.Init(context.Background(), otelCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	logger = logger.With(zap.String("op", op))
 	logger.Info("Building Notification service",
 		zap.Strings("kafka_brokers", cfg.KafkaBrokers),
@@ -90,8 +130,10 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Создаём Telegram sender
 	var telegramSender telegram.Sender
+	var telegramBot *telegram.TelegramSender // nil, если Telegram выключен: команды алертов недоступны
 	if cfg.TelegramEnabled {
-		telegramSender = telegram.NewTelegramSender(logger, cfg.TelegramBotToken)
+		telegramBot = telegram.NewTelegramSender(logger, cfg.TelegramAPIURL, cfg.TelegramBotToken)
+		telegramSender = telegramBot
 		logger.Info("Telegram sender enabled",
 			zap.String("chat_id", cfg.TelegramChatID),
 		)
@@ -100,6 +142,13 @@ func Build(cfg config.Config) (*App, error) {
 		logger.Warn("Telegram disabled, using no-op sender")
 	}
 
+	// Sender уведомлений пользователям: в dry-run режиме только логируем (алерты идут через telegramSender)
+	notificationSender := telegramSender
+	if cfg.DryRun {
+		notificationSender = telegram.NewDryRunSender(logger)
+		logger.Warn("Dry-run mode enabled: user notifications are logged, not sent")
+	}
+
 	// Создаём template renderer
 	renderer, err := templates.NewRenderer(logger, cfg.TemplatesDir)
 	if err != nil {
@@ -109,7 +158,7 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Подключаемся к IAM Service для получения контактной информации пользователей
 	logger.Info("Connecting to IAM service", zap.String("addr", cfg.IAMGRPCAddr))
-	iamClient, iamConn, err := grpcclient.NewIAMGRPCClient(cfg.IAMGRPCAddr, logger)
+	iamClient, iamConn, err := grpcclient.NewIAMGRPCClient(cfg.IAMGRPCAddr, cfg.Discovery, logger)
 	if err != nil {
 		pool.Close()
 		return nil, fmt.Errorf("failed to connect to IAM service: %w", err)
@@ -118,13 +167,44 @@ func Build(cfg config.Config) (*App, error) {
 	// Создаём адаптер для IAM клиента
 	iamClientAdapter := grpcclient.NewIAMClientAdapter(iamClient, logger)
 
+	// Order Service: недостающие в событии сборки сумма и позиции заказа; при ошибке уведомление уходит без них
+	var orderClient orderclient.Client
+	if cfg.OrderHTTPURL != "" {
+		orderClient = orderclient.NewCachedClient(
+			orderclient.NewHTTPClient(cfg.OrderHTTPURL, cfg.OrderSessionID, cfg.OrderLookupTimeout),
+			cfg.OrderCacheTTL,
+			cfg.OrderCacheSize,
+		)
+	}
+
+	// Outbox: consumers только ставят сообщения в очередь, отправляет delivery worker
+	var outboxRepo repository.OutboxRepository
+	var deliveryWorker *delivery.Worker
+	if cfg.OutboxEnabled {
+		outboxRepo = notificationRepo
+		deliveryWorker = delivery.NewWorker(
+			logger,
+			notificationRepo,
+			notificationSender,
+			cfg.OutboxBatchSize,
+			cfg.OutboxPollInterval,
+			cfg.OutboxMaxAttempts,
+			cfg.OutboxBackoffBase,
+			cfg.OutboxRateLimit,
+		)
+		logger.Info("Notification outbox enabled: Telegram sends are decoupled from Kafka consumption")
+	}
+
 	// Создаём service слой
 	notificationService := service.NewNotificationService(
 		logger,
 		notificationRepo,
-		telegramSender,
+		notificationSender,
 		renderer,
 		iamClientAdapter,
+		cfg.CoalesceWindow,
+		outboxRepo,
+		orderClient,
 	)
 
 	// Создаём DLQ publisher
@@ -157,18 +237,57 @@ func Build(cfg config.Config) (*App, error) {
 		cfg.NotificationKafkaRetryBackoffBase,
 	)
 
+	// Чат дежурных: алерты Alertmanager и алерты о низком остатке товаров
+	alertChatID := cfg.AlertTelegramChatID
+	if cfg.TelegramDisable {
+		alertChatID = ""
+	}
+
+	// Consumer inventory.stock.low: алерты о низком остатке идут в чат дежурных, минуя dry-run уведомлений пользователям
+	var stockLowConsumer *eventkafka.StockLowConsumer
+	if cfg.StockLowTopic != "" {
+		stockLowConsumer = eventkafka.NewStockLowConsumer(
+			logger,
+			cfg.KafkaBrokers,
+			cfg.NotificationStockLowGroupID,
+			cfg.StockLowTopic,
+			service.NewStockAlertService(logger, notificationRepo, telegramSender, alertChatID),
+			dlqPublisher,
+			cfg.NotificationKafkaRetryMaxAttempts,
+			cfg.NotificationKafkaRetryBackoffBase,
+		)
+	}
+
 	// HTTP сервер для приёма webhook от Alertmanager (алерты в Telegram)
 	var alertServer *http.Server
+	var commandPoller *alerting.CommandPoller
 	alertListenAddr := cfg.AlertsHTTPAddr
 	if alertListenAddr == "" && cfg.HTTPAlertPort != "" {
 		alertListenAddr = ":" + cfg.HTTPAlertPort
 	}
 	if alertListenAddr != "" {
-		alertChatID := cfg.AlertTelegramChatID
-		if cfg.TelegramDisable {
-			alertChatID = ""
+		// Команды /ack и /silence: нужен настоящий бот и чат дежурных, ответы читаются из этого чата
+		var alertTracker httpapi.AlertTracker
+		if cfg.AlertCommandsEnabled {
+			if telegramBot != nil && alertChatID != "" {
+				ackService := alerting.NewAckService(
+					logger,
+					telegramBot,
+					alertmanager.NewHTTPClient(cfg.AlertmanagerURL),
+					alertChatID,
+					cfg.AlertAckSilenceDuration,
+				)
+				alertTracker = ackService
+				commandPoller = alerting.NewCommandPoller(logger, telegramBot, ackService)
+				logger.Info("Alert commands enabled",
+					zap.String("alertmanager_url", cfg.AlertmanagerURL),
+					zap.Duration("ack_silence_duration", cfg.AlertAckSilenceDuration),
+				)
+			} else {
+				logger.Warn("ALERT_COMMANDS_ENABLED ignored: requires TELEGRAM_ENABLED and ALERT_TELEGRAM_CHAT_ID")
+			}
 		}
-		alertHandler := httpapi.NewAlertmanagerHandler(logger, telegramSender, alertChatID)
+		alertHandler := httpapi.NewAlertmanagerHandler(logger, telegramSender, alertChatID, alertTracker)
 		alertRouter := httpapi.NewAlertRouter(alertHandler)
 		alertServer = &http.Server{
 			Addr:         alertListenAddr,
@@ -182,7 +301,11 @@ func Build(cfg config.Config) (*App, error) {
 	// Создаём shutdown manager
 	shutdownMgr := platformshutdown.New(cfg.ShutdownTimeout, logger)
 
-	// Регистрируем shutdown функции в обратном порядке выполнения
+	// Регистрируем shutdown функции в обратном порядке выполнения (otel последним, чтобы успели записаться spans)
+	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
 	if alertServer != nil {
 		shutdownMgr.Add("alert_http_server", platformshutdown.ShutdownHTTPServer(alertServer))
 	}
@@ -192,6 +315,11 @@ func Build(cfg config.Config) (*App, error) {
 	shutdownMgr.Add("kafka_payment_consumer", func(ctx context.Context) error {
 		return paymentConsumer.Close()
 	})
+	if stockLowConsumer != nil {
+		shutdownMgr.Add("kafka_stock_low_consumer", func(ctx context.Context) error {
+			return stockLowConsumer.Close()
+		})
+	}
 	shutdownMgr.Add("dlq_publisher", func(ctx context.Context) error {
 		return dlqPublisher.Close()
 	})
@@ -201,12 +329,33 @@ func Build(cfg config.Config) (*App, error) {
 	})
 	shutdownMgr.Add("postgres_pool", platformshutdown.ClosePool(pool))
 
+	// Проверки готовности зависимостей перед запуском consumers
+	readinessChecks := []readinessCheck{
+		{name: "postgres", check: pool.Ping},
+		{name: "templates", check: func(ctx context.Context) error {
+			if _, err := renderer.RenderPaymentCompleted("", service.OrderPaidEvent{}); err != nil {
+				return err
+			}
+			if _, err := renderer.RenderAssemblyCompleted("", service.OrderAssemblyCompletedEvent{}); err != nil {
+				return err
+			}
+			_, err := renderer.RenderOrderCompleted("", service.OrderCompletedNotification{})
+			return err
+		}},
+		{name: "iam", check: grpcConnReady(iamConn)},
+	}
+
 	return &App{
 		logger:           logger,
 		alertServer:      alertServer,
+		commandPoller:    commandPoller,
 		paymentConsumer:  paymentConsumer,
 		assemblyConsumer: assemblyConsumer,
+		stockLowConsumer: stockLowConsumer,
+		deliveryWorker:   deliveryWorker,
 		shutdownMgr:      shutdownMgr,
+		readinessChecks:  readinessChecks,
+		readinessTimeout: cfg.StartupReadinessTimeout,
 	}, nil
 }
 
@@ -232,6 +381,33 @@ func (a *App) Run() error {
 		a.logger.Info("Alert webhook server listening", zap.String("addr", a.alertServer.Addr))
 	}
 
+	// Запускаем чтение команд /ack и /silence вместе с webhook, не дожидаясь Kafka-зависимостей
+	if a.commandPoller != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.commandPoller.Start(ctx); err != nil {
+				a.logger.Error("alert command poller error", zap.Error(err))
+			}
+		}()
+	}
+
+	// Не запускаем consumers, пока зависимости не готовы: иначе ранние сообщения уйдут в DLQ
+	a.logger.Info("Waiting for dependencies before starting Kafka consumers",
+		zap.Duration("timeout", a.readinessTimeout),
+	)
+	readyCtx, stopReady := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	err := waitForReadiness(readyCtx, a.logger, a.readinessTimeout, a.readinessChecks)
+	stopReady()
+	if err != nil {
+		a.logger.Error("Dependencies not ready, consumers not started", zap.Error(err))
+		cancel()
+		// Закрываем всё, что открыто в New: серверы, Kafka readers и writers, соединения с IAM и PostgreSQL, otel
+		a.shutdownMgr.Shutdown()
+		a.wg.Wait()
+		return fmt.Errorf("startup readiness: %w", err)
+	}
+
 	// Запускаем payment consumer в отдельной горутине
 	a.wg.Add(1)
 	go func() {
@@ -250,6 +426,28 @@ func (a *App) Run() error {
 		}
 	}()
 
+	// Запускаем consumer алертов о низком остатке, если он включён
+	if a.stockLowConsumer != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.stockLowConsumer.Start(ctx); err != nil {
+				a.logger.Error("kafka stock low consumer error", zap.Error(err))
+			}
+		}()
+	}
+
+	// Запускаем отправку уведомлений из outbox, если она включена
+	if a.deliveryWorker != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.deliveryWorker.Start(ctx); err != nil {
+				a.logger.Error("notification delivery worker error", zap.Error(err))
+			}
+		}()
+	}
+
 	a.logger.Info("Kafka consumers started")
 
 	// Ожидаем сигнал и выполняем shutdown
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// readinessPollInterval - интервал между повторными проверками зависимостей
+const readinessPollInterval = 1 * time.Second
+
+// readinessCheck описывает одну зависимость, без которой нельзя запускать Kafka consumers
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// waitForReadiness блокируется, пока все проверки не пройдут успешно, истечёт timeout или будет отменён ctx.
+// Проверки, прошедшие один раз, повторно не выполняются.
+// Без этого барьера ранние сообщения при rolling deploy сжигают retry и уходят в DLQ.
+func waitForReadiness(ctx context.Context, logger *zap.Logger, timeout time.Duration, checks []readinessCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	pending := checks
+	for {
+		var stillPending []readinessCheck
+		for _, c := range pending {
+			checkCtx, checkCancel := context.WithTimeout(ctx, readinessPollInterval*2)
+			err := c.check(checkCtx)
+			checkCancel()
+			if err != nil {
+				logger.Info("Dependency not ready yet", zap.String("dependency", c.name), zap.Error(err))
+				stillPending = append(stillPending, c)
+				continue
+			}
+			logger.Info("Dependency ready", zap.String("dependency", c.name))
+		}
+
+		if len(stillPending) == 0 {
+			logger.Info("All dependencies ready", zap.Duration("waited", time.Since(start)))
+			return nil
+		}
+		pending = stillPending
+
+		select {
+		case <-ctx.Done():
+			names := make([]string, 0, len(pending))
+			for _, c := range pending {
+				names = append(names, c.name)
+			}
+			return fmt.Errorf("dependencies not ready after %s: %v: %w", time.Since(start).Round(time.Millisecond), names, ctx.Err())
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
+
+// grpcConnReady возвращает проверку, что gRPC соединение установлено (grpc.NewClient подключается лениво)
+func grpcConnReady(conn *grpc.ClientConn) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		conn.Connect()
+		for {
+			state := conn.GetState()
+			if state == connectivity.Ready {
+				return nil
+			}
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("connection state: %s", state)
+			}
+		}
+	}
+}
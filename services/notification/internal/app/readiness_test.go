@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWaitForReadiness(t *testing.T) {
+	logger := zap.NewNop()
+
+	t.Run("all checks pass immediately", func(t *testing.T) {
+		calls := 0
+		checks := []readinessCheck{
+			{name: "postgres", check: func(ctx context.Context) error { calls++; return nil }},
+			{name: "iam", check: func(ctx context.Context) error { calls++; return nil }},
+		}
+
+		err := waitForReadiness(context.Background(), logger, time.Second, checks)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("check becomes ready on retry, passed checks are not repeated", func(t *testing.T) {
+		postgresCalls, iamCalls := 0, 0
+		checks := []readinessCheck{
+			{name: "postgres", check: func(ctx context.Context) error { postgresCalls++; return nil }},
+			{name: "iam", check: func(ctx context.Context) error {
+				iamCalls++
+				if iamCalls < 2 {
+					return errors.New("connecting")
+				}
+				return nil
+			}},
+		}
+
+		err := waitForReadiness(context.Background(), logger, 5*time.Second, checks)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if postgresCalls != 1 {
+			t.Errorf("expected postgres check to run once, got %d", postgresCalls)
+		}
+		if iamCalls != 2 {
+			t.Errorf("expected iam check to run twice, got %d", iamCalls)
+		}
+	})
+
+	t.Run("timeout returns error with pending dependencies", func(t *testing.T) {
+		checks := []readinessCheck{
+			{name: "iam", check: func(ctx context.Context) error { return errors.New("unavailable") }},
+		}
+
+		err := waitForReadiness(context.Background(), logger, 50*time.Millisecond, checks)
+
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "iam") {
+			t.Errorf("expected error to mention pending dependency, got %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
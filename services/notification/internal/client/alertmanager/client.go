@@ -0,0 +1,101 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Matcher - условие silence на метку алерта (только точное равенство)
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// Silence - заглушение алертов, метки которых подходят под все Matchers, на время [StartsAt, EndsAt)
+type Silence struct {
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// Client определяет интерфейс для работы с Alertmanager API v2
+type Client interface {
+	// CreateSilence создаёт silence и возвращает его ID
+	CreateSilence(ctx context.Context, silence Silence) (string, error)
+}
+
+// EqualMatchers строит matchers на точное равенство всех меток (в порядке имён, чтобы запросы были воспроизводимы)
+func EqualMatchers(labels map[string]string) []Matcher {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matchers := make([]Matcher, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, Matcher{Name: name, Value: labels[name], IsEqual: true})
+	}
+	return matchers
+}
+
+// HTTPClient реализует Client через HTTP API Alertmanager
+type HTTPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPClient создаёт клиент Alertmanager; baseURL - адрес без /api/v2 (например http://alertmanager:9093)
+func NewHTTPClient(baseURL string) *HTTPClient {
+	return &HTTPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// CreateSilence создаёт silence через POST /api/v2/silences
+func (c *HTTPClient) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal silence: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/silences", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Alertmanager отвечает 200 {"silenceID": "..."}, при ошибке валидации - 400 с текстом ошибки
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("alertmanager API status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.SilenceID, nil
+}
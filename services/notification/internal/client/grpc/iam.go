@@ -7,13 +7,22 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	platformdiscovery "github.com/shestoi/GoBigTech/platform/discovery"
 	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
 )
 
+// UserContact - контакт и настройки пользователя для отправки уведомления
+type UserContact struct {
+	TelegramID       *string // nil - у пользователя нет Telegram
+	PreferredChannel string
+	Locale           string // тег BCP-47, пусто - не задан
+	Timezone         string // имя IANA, пусто - не задан
+}
+
 // IAMClient определяет интерфейс для работы с IAM Service
 type IAMClient interface {
 	// GetUserContact получает контактную информацию пользователя
-	GetUserContact(ctx context.Context, userID string) (telegramID *string, preferredChannel string, err error)
+	GetUserContact(ctx context.Context, userID string) (*UserContact, error)
 }
 
 // IAMClientAdapter адаптирует gRPC клиент к интерфейсу IAMClient
@@ -31,27 +40,32 @@ func NewIAMClientAdapter(client iampb.IAMServiceClient, logger *zap.Logger) IAMC
 }
 
 // GetUserContact реализует IAMClient интерфейс
-func (a *IAMClientAdapter) GetUserContact(ctx context.Context, userID string) (*string, string, error) {
+func (a *IAMClientAdapter) GetUserContact(ctx context.Context, userID string) (*UserContact, error) {
 	req := &iampb.GetUserContactRequest{
 		UserId: userID,
 	}
 
 	resp, err := a.client.GetUserContact(ctx, req)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	var telegramID *string
+	contact := &UserContact{
+		PreferredChannel: resp.GetPreferredChannel(),
+		Locale:           resp.GetLocale(),
+		Timezone:         resp.GetTimezone(),
+	}
 	if resp.TelegramId != nil && *resp.TelegramId != "" {
-		telegramID = resp.TelegramId
+		contact.TelegramID = resp.TelegramId
 	}
 
-	return telegramID, resp.GetPreferredChannel(), nil
+	return contact, nil
 }
 
-// NewIAMGRPCClient создаёт новый gRPC клиент для IAM Service
-func NewIAMGRPCClient(addr string, logger *zap.Logger) (iampb.IAMServiceClient, *grpc.ClientConn, error) {
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewIAMGRPCClient создаёт новый gRPC клиент для IAM Service.
+// addr - host:port, список через запятую или srv:///имя (см. platform/discovery).
+func NewIAMGRPCClient(addr string, discovery platformdiscovery.Config, logger *zap.Logger) (iampb.IAMServiceClient, *grpc.ClientConn, error) {
+	conn, err := platformdiscovery.NewClient(addr, discovery, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,80 @@
+package order
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedClient кэширует успешные ответы Client на ttl
+// Уведомления об оплате и сборке одного заказа приходят с разницей в секунды, поэтому второе обычно
+// не ходит в Order. Ошибки не кэшируются: следующее событие спросит Order заново
+type CachedClient struct {
+	next       Client
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	order     *Order
+	expiresAt time.Time
+}
+
+// NewCachedClient оборачивает next кэшем на ttl; в кэше не больше maxEntries заказов
+func NewCachedClient(next Client, ttl time.Duration, maxEntries int) *CachedClient {
+	return &CachedClient{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// GetOrder возвращает заказ из кэша, а если его там нет или он устарел - из next
+func (c *CachedClient) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[orderID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.order, nil
+	}
+
+	order, err := c.next.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[orderID]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[orderID] = cacheEntry{order: order, expiresAt: now.Add(c.ttl)}
+	return order, nil
+}
+
+// evict освобождает место под новый заказ: удаляет устаревшие записи,
+// а если устаревших нет - ту, что истекает раньше всех. Вызывается под mu
+func (c *CachedClient) evict(now time.Time) {
+	var oldestID string
+	var oldest time.Time
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+			continue
+		}
+		if oldestID == "" || entry.expiresAt.Before(oldest) {
+			oldestID, oldest = id, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestID)
+	}
+}
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingClient считает запросы и отдаёт заказ с ID из запроса
+type countingClient struct {
+	calls map[string]int
+	err   error
+}
+
+func (c *countingClient) GetOrder(_ context.Context, orderID string) (*Order, error) {
+	c.calls[orderID]++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Order{ID: orderID}, nil
+}
+
+func TestCachedClient(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("cached until ttl expires", func(t *testing.T) {
+		next := &countingClient{calls: map[string]int{}}
+		cache := NewCachedClient(next, time.Minute, 10)
+		cache.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.GetOrder(ctx, "order-1"); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if next.calls["order-1"] != 1 {
+			t.Fatalf("expected 1 call within ttl, got %d", next.calls["order-1"])
+		}
+
+		cache.now = func() time.Time { return now.Add(time.Minute) }
+		if _, err := cache.GetOrder(ctx, "order-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if next.calls["order-1"] != 2 {
+			t.Fatalf("expected refresh after ttl, got %d calls", next.calls["order-1"])
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		next := &countingClient{calls: map[string]int{}, err: errors.New("order unavailable")}
+		cache := NewCachedClient(next, time.Minute, 10)
+
+		for i := 0; i < 2; i++ {
+			if _, err := cache.GetOrder(ctx, "order-1"); err == nil {
+				t.Fatal("expected error")
+			}
+		}
+		if next.calls["order-1"] != 2 {
+			t.Fatalf("expected every failed lookup to reach order service, got %d calls", next.calls["order-1"])
+		}
+	})
+
+	t.Run("full cache evicts entry expiring first", func(t *testing.T) {
+		next := &countingClient{calls: map[string]int{}}
+		cache := NewCachedClient(next, time.Minute, 2)
+		for i, id := range []string{"order-1", "order-2", "order-3"} {
+			at := now.Add(time.Duration(i) * time.Second)
+			cache.now = func() time.Time { return at }
+			if _, err := cache.GetOrder(ctx, id); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		if len(cache.entries) != 2 {
+			t.Fatalf("expected 2 cached orders, got %d", len(cache.entries))
+		}
+		if _, ok := cache.entries["order-1"]; ok {
+			t.Fatal("expected order-1 to be evicted")
+		}
+	})
+}
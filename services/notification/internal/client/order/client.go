@@ -0,0 +1,112 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrOrderNotFound возвращается, если Order Service не знает заказ (404)
+var ErrOrderNotFound = errors.New("order not found")
+
+// Item - позиция заказа
+type Item struct {
+	ProductID string
+	Quantity  int32
+	Status    string // reserved, assembled, shipped, cancelled
+}
+
+// Order - данные заказа для шаблонов уведомлений
+type Order struct {
+	ID          string
+	Status      string
+	TotalAmount int64  // в минимальных единицах валюты; 0 - сумма неизвестна
+	Currency    string // код валюты ISO 4217; пусто - неизвестна
+	Items       []Item
+}
+
+// Client определяет интерфейс для чтения заказов из Order Service
+type Client interface {
+	// GetOrder возвращает заказ по ID; ErrOrderNotFound, если заказа нет
+	GetOrder(ctx context.Context, orderID string) (*Order, error)
+}
+
+// HTTPClient реализует Client через HTTP API Order Service (GET /orders/{id})
+type HTTPClient struct {
+	baseURL   string
+	sessionID string
+	client    *http.Client
+}
+
+// NewHTTPClient создаёт клиент Order Service; baseURL - адрес HTTP API (например http://order:8080)
+// sessionID передаётся в x-session-id: Order требует заголовок у всех ручек заказов
+func NewHTTPClient(baseURL, sessionID string, timeout time.Duration) *HTTPClient {
+	return &HTTPClient{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		sessionID: sessionID,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// orderResponse - ответ GET /orders/{id}: только поля, нужные уведомлениям
+type orderResponse struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	TotalAmount *int64 `json:"total_amount"`
+	Currency    string `json:"currency"`
+	Items       []struct {
+		ProductID string `json:"product_id"`
+		Quantity  int32  `json:"quantity"`
+		Status    string `json:"status"`
+	} `json:"items"`
+}
+
+// GetOrder читает заказ через GET /orders/{id}
+func (c *HTTPClient) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/orders/"+url.PathEscape(orderID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-session-id", c.sessionID)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrOrderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("order API status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var body orderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	order := &Order{
+		ID:       body.ID,
+		Status:   body.Status,
+		Currency: body.Currency,
+		Items:    make([]Item, 0, len(body.Items)),
+	}
+	if body.TotalAmount != nil {
+		order.TotalAmount = *body.TotalAmount
+	}
+	for _, item := range body.Items {
+		order.Items = append(order.Items, Item{ProductID: item.ProductID, Quantity: item.Quantity, Status: item.Status})
+	}
+	return order, nil
+}
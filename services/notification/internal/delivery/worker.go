@@ -0,0 +1,176 @@
+// Package delivery отправляет в Telegram уведомления из очереди notification_outbox.
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+)
+
+// maxBackoff ограничивает экспоненциальную задержку между попытками
+const maxBackoff = time.Hour
+
+// claimLease - на сколько забранное сообщение скрывается от других инстансов
+// Должен превышать время отправки пачки с учётом ограничения скорости и таймаута Telegram
+const claimLease = 5 * time.Minute
+
+// Worker периодически отправляет pending сообщения из notification_outbox
+// Ошибка отправки - повтор с экспоненциальной задержкой, после maxAttempts попыток - failed.
+// Сообщения отправляются не чаще rate в секунду на инстанс (лимит Telegram - около 30 сообщений в секунду на бота)
+type Worker struct {
+	logger      *zap.Logger
+	repo        repository.OutboxRepository
+	sender      telegram.Sender
+	batchSize   int
+	interval    time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+	sendEvery   time.Duration // минимальный интервал между отправками; 0 - без ограничения
+}
+
+// NewWorker создаёт новый delivery worker
+func NewWorker(
+	logger *zap.Logger,
+	repo repository.OutboxRepository,
+	sender telegram.Sender,
+	batchSize int,
+	interval time.Duration,
+	maxAttempts int,
+	backoffBase time.Duration, // задержка перед второй попыткой, дальше удваивается
+	rate float64, // сообщений в секунду; <= 0 - без ограничения
+) *Worker {
+	var sendEvery time.Duration
+	if rate > 0 {
+		sendEvery = time.Duration(float64(time.Second) / rate)
+	}
+	return &Worker{
+		logger:      logger,
+		repo:        repo,
+		sender:      sender,
+		batchSize:   batchSize,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+		sendEvery:   sendEvery,
+	}
+}
+
+// Start запускает worker и работает до отмены контекста
+func (w *Worker) Start(ctx context.Context) error {
+	w.logger.Info("starting notification delivery worker",
+		zap.Int("batch_size", w.batchSize),
+		zap.Duration("interval", w.interval),
+		zap.Int("max_attempts", w.maxAttempts),
+		zap.Duration("backoff_base", w.backoffBase),
+		zap.Duration("send_every", w.sendEvery),
+	)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// Полная пачка - очередь не разобрана, следующую забираем без ожидания тика
+		n, err := w.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Error("failed to process notification outbox", zap.Error(err))
+		}
+		if err == nil && n == w.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("notification delivery worker context cancelled, stopping")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch отправляет сообщения, время попытки которых наступило; возвращает число забранных сообщений
+func (w *Worker) ProcessBatch(ctx context.Context) (int, error) {
+	messages, err := w.repo.ClaimDueOutboxMessages(ctx, time.Now().UTC(), claimLease, w.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	var last time.Time
+	for _, message := range messages {
+		if err := w.wait(ctx, last); err != nil {
+			return len(messages), err
+		}
+		last = time.Now()
+		w.processMessage(ctx, message)
+	}
+	return len(messages), nil
+}
+
+// wait выдерживает sendEvery с момента предыдущей отправки last
+func (w *Worker) wait(ctx context.Context, last time.Time) error {
+	if w.sendEvery <= 0 || last.IsZero() {
+		return ctx.Err()
+	}
+	delay := time.Until(last.Add(w.sendEvery))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// processMessage выполняет одну попытку отправки и сохраняет её результат
+func (w *Worker) processMessage(ctx context.Context, message repository.OutboxMessage) {
+	logger := w.logger.With(
+		zap.String("event_id", message.EventID),
+		zap.String("event_type", message.EventType),
+		zap.String("order_id", message.OrderID),
+		zap.String("telegram_id", message.ChatID),
+		zap.Int("attempt", message.Attempts+1),
+	)
+
+	sendErr := w.sender.Send(ctx, message.ChatID, message.Text)
+	if sendErr == nil {
+		if err := w.repo.MarkOutboxMessageSent(ctx, message.EventID); err != nil {
+			// Сообщение вернётся в очередь по истечении lease и будет отправлено повторно
+			logger.Error("failed to mark outbox message sent", zap.Error(err))
+			return
+		}
+		logger.Info("notification delivered")
+		return
+	}
+	if ctx.Err() != nil {
+		return // остановка сервиса - попытка не считается, сообщение вернётся по истечении lease
+	}
+
+	final := message.Attempts+1 >= w.maxAttempts
+	nextAttemptAt := time.Now().UTC().Add(w.backoff(message.Attempts))
+	if err := w.repo.MarkOutboxAttemptFailed(ctx, message.EventID, sendErr.Error(), nextAttemptAt, final); err != nil {
+		logger.Error("failed to record outbox attempt", zap.Error(err))
+		return
+	}
+	if final {
+		logger.Error("notification delivery failed, attempts exhausted", zap.Error(sendErr))
+		return
+	}
+	logger.Warn("notification delivery failed, will retry", zap.Error(sendErr), zap.Time("next_attempt_at", nextAttemptAt))
+}
+
+// backoff возвращает задержку после неудачной попытки: backoffBase * 2^attempts, но не больше maxBackoff
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.backoffBase
+	for i := 0; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
@@ -0,0 +1,91 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/notification/internal/repository/mocks"
+	telegramMocks "github.com/shestoi/GoBigTech/services/notification/internal/telegram/mocks"
+)
+
+func TestWorker_ProcessBatch(t *testing.T) {
+	ctx := context.Background()
+	newMessage := func(eventID string, attempts int) repository.OutboxMessage {
+		return repository.OutboxMessage{
+			EventID:   eventID,
+			EventType: "order.payment.completed",
+			OrderID:   "order-1",
+			ChatID:    "12345",
+			Text:      "✅ Заказ оплачен",
+			Attempts:  attempts,
+		}
+	}
+
+	t.Run("message is sent and marked", func(t *testing.T) {
+		repo := repoMocks.NewOutboxRepository(t)
+		sender := telegramMocks.NewSender(t)
+		repo.On("ClaimDueOutboxMessages", ctx, mock.AnythingOfType("time.Time"), claimLease, 10).
+			Return([]repository.OutboxMessage{newMessage("evt-1", 0)}, nil).Once()
+		sender.On("Send", ctx, "12345", "✅ Заказ оплачен").Return(nil).Once()
+		repo.On("MarkOutboxMessageSent", ctx, "evt-1").Return(nil).Once()
+
+		w := NewWorker(zap.NewNop(), repo, sender, 10, time.Second, 3, time.Minute, 0)
+		n, err := w.ProcessBatch(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+
+	t.Run("failed send is retried with backoff", func(t *testing.T) {
+		repo := repoMocks.NewOutboxRepository(t)
+		sender := telegramMocks.NewSender(t)
+		repo.On("ClaimDueOutboxMessages", ctx, mock.AnythingOfType("time.Time"), claimLease, 10).
+			Return([]repository.OutboxMessage{newMessage("evt-1", 1)}, nil).Once()
+		sender.On("Send", ctx, "12345", mock.Anything).Return(errors.New("telegram API status 502")).Once()
+		start := time.Now()
+		repo.On("MarkOutboxAttemptFailed", ctx, "evt-1", "telegram API status 502",
+			mock.MatchedBy(func(next time.Time) bool {
+				// вторая попытка провалилась: следующая через backoffBase * 2
+				return !next.Before(start.Add(2*time.Minute)) && next.Before(time.Now().Add(2*time.Minute+time.Second))
+			}), false).Return(nil).Once()
+
+		w := NewWorker(zap.NewNop(), repo, sender, 10, time.Second, 3, time.Minute, 0)
+		_, err := w.ProcessBatch(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("last attempt marks message failed", func(t *testing.T) {
+		repo := repoMocks.NewOutboxRepository(t)
+		sender := telegramMocks.NewSender(t)
+		repo.On("ClaimDueOutboxMessages", ctx, mock.AnythingOfType("time.Time"), claimLease, 10).
+			Return([]repository.OutboxMessage{newMessage("evt-1", 2)}, nil).Once()
+		sender.On("Send", ctx, "12345", mock.Anything).Return(errors.New("chat not found")).Once()
+		repo.On("MarkOutboxAttemptFailed", ctx, "evt-1", "chat not found", mock.AnythingOfType("time.Time"), true).Return(nil).Once()
+
+		w := NewWorker(zap.NewNop(), repo, sender, 10, time.Second, 3, time.Minute, 0)
+		_, err := w.ProcessBatch(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("sends are spaced by rate limit", func(t *testing.T) {
+		repo := repoMocks.NewOutboxRepository(t)
+		sender := telegramMocks.NewSender(t)
+		repo.On("ClaimDueOutboxMessages", ctx, mock.AnythingOfType("time.Time"), claimLease, 10).
+			Return([]repository.OutboxMessage{newMessage("evt-1", 0), newMessage("evt-2", 0), newMessage("evt-3", 0)}, nil).Once()
+		sender.On("Send", ctx, "12345", mock.Anything).Return(nil).Times(3)
+		repo.On("MarkOutboxMessageSent", ctx, mock.Anything).Return(nil).Times(3)
+
+		w := NewWorker(zap.NewNop(), repo, sender, 10, time.Second, 3, time.Minute, 50) // 20ms между отправками
+		start := time.Now()
+		n, err := w.ProcessBatch(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+}
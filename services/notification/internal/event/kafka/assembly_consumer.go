@@ -9,6 +9,8 @@ import (
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	"github.com/shestoi/GoBigTech/services/notification/internal/service"
 )
 
@@ -104,6 +106,22 @@ func (c *OrderAssemblyCompletedConsumer) Start(ctx context.Context) error {
 // processMessage обрабатывает одно сообщение из Kafka
 // Возвращает true, если нужно закоммитить offset (успешная обработка)
 func (c *OrderAssemblyCompletedConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
+	// Продолжаем trace из заголовков сообщения (assembly -> notification)
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	// Маршрутизация по заголовку event_type: чужие события пропускаем, не разбирая body
+	if eventType := headers.EventType(); eventType != "" && eventType != eventTypeOrderAssemblyCompleted {
+		c.logger.Debug("skipping message with unexpected event_type",
+			zap.String("event_type", eventType),
+			zap.String("event_id", headers.EventID()),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return true
+	}
+
 	// Парсим JSON сообщение
 	var payload map[string]interface{}
 	if err := json.Unmarshal(m.Value, &payload); err != nil {
@@ -164,7 +182,10 @@ func (c *OrderAssemblyCompletedConsumer) processMessage(ctx context.Context, m k
 			zap.Int64("offset", m.Offset),
 		)
 		dlqErr := fmt.Errorf("exhausted all retry attempts")
-		if err := c.dlqPublisher.Publish(context.Background(), m, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
+		// retry_count в заголовках DLQ-сообщения - сколько попыток обработки было сделано
+		dlqMsg := m
+		dlqMsg.Headers = headers.WithRetryCount(c.maxAttempts).Kafka()
+		if err := c.dlqPublisher.Publish(context.Background(), dlqMsg, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
 			c.logger.Error("failed to publish to DLQ, not committing",
 				zap.Error(err),
 			)
@@ -263,6 +284,31 @@ func (c *OrderAssemblyCompletedConsumer) parseOrderAssemblyCompletedEvent(payloa
 	if v, ok := payload["user_id"].(string); ok {
 		event.UserID = v
 	}
+	if v, ok := payload["amount"].(float64); ok {
+		event.Amount = int64(v)
+	}
+	if v, ok := payload["currency"].(string); ok {
+		event.Currency = v
+	}
+	if v, ok := payload["payment_method"].(string); ok {
+		event.PaymentMethod = v
+	}
+	if v, ok := payload["items"].([]interface{}); ok {
+		for _, raw := range v {
+			item, ok := raw.(map[string]interface{})
+			if !ok {
+				return event, &ParseError{Field: "items", Message: "items must be an array of objects"}
+			}
+			productID, _ := item["product_id"].(string)
+			quantity, _ := item["quantity"].(float64)
+			status, _ := item["status"].(string)
+			event.Items = append(event.Items, service.OrderItem{
+				ProductID: productID,
+				Quantity:  int32(quantity),
+				Status:    status,
+			})
+		}
+	}
 
 	return event, nil
 }
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// partitionKey - партиция топика, для которой Kafka хранит один offset consumer group
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// trackedMessage - прочитанное сообщение, обработка которого ещё может идти
+type trackedMessage struct {
+	message kafka.Message
+	done    bool
+	commit  bool
+}
+
+// commitTracker коммитит offset'ы сообщений, которые обрабатываются параллельно и завершаются не по порядку
+//
+// Коммит offset'а подтверждает и все предыдущие сообщения партиции, поэтому сообщение коммитится,
+// только когда обработаны все сообщения партиции, прочитанные до него. Сообщение с commit=false
+// само не коммитится, как и при последовательной обработке: его подтверждает коммит следующего
+type commitTracker struct {
+	mu      sync.Mutex
+	pending map[partitionKey][]*trackedMessage // в порядке чтения
+	commit  func(kafka.Message)                // вызывается под mu: offset'ы партиции коммитятся по возрастанию
+}
+
+func newCommitTracker(commit func(kafka.Message)) *commitTracker {
+	return &commitTracker{pending: make(map[partitionKey][]*trackedMessage), commit: commit}
+}
+
+// track регистрирует прочитанное сообщение; вызывать в порядке FetchMessage
+func (t *commitTracker) track(m kafka.Message) *trackedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tm := &trackedMessage{message: m}
+	key := partitionKey{topic: m.Topic, partition: m.Partition}
+	t.pending[key] = append(t.pending[key], tm)
+	return tm
+}
+
+// finish отмечает сообщение обработанным и коммитит последнее сообщение с commit=true
+// из обработанного без пропусков начала очереди партиции
+func (t *commitTracker) finish(tm *trackedMessage, commit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tm.done = true
+	tm.commit = commit
+
+	key := partitionKey{topic: tm.message.Topic, partition: tm.message.Partition}
+	queue := t.pending[key]
+	var last *trackedMessage
+	n := 0
+	for n < len(queue) && queue[n].done {
+		if queue[n].commit {
+			last = queue[n]
+		}
+		n++
+	}
+	if n == len(queue) {
+		delete(t.pending, key)
+	} else {
+		t.pending[key] = queue[n:]
+	}
+
+	if last != nil {
+		t.commit(last.message)
+	}
+}
@@ -78,8 +78,9 @@ func (p *DLQPublisher) Publish(ctx context.Context, originalMessage kafka.Messag
 
 	//msg - сообщение для DLQ в формате Kafka
 	msg := kafka.Message{
-		Key:   key,
-		Value: payload,
+		Key:     key,
+		Value:   payload,
+		Headers: originalMessage.Headers, // сохраняем заголовки исходного сообщения (trace context, event_type, event_id, retry_count)
 	}
 
 	//writeErr - ошибка при записи сообщения в DLQ
@@ -0,0 +1,12 @@
+package kafka
+
+// tracerName - имя сервиса для span обработки сообщений
+const tracerName = "notification"
+
+// Типы событий, которые читают consumers Notification
+// Сообщения с другим event_type в заголовке пропускаются без разбора body
+const (
+	eventTypeOrderPaymentCompleted  = "order.payment.completed"
+	eventTypeOrderAssemblyCompleted = "order.assembly.completed"
+	eventTypeInventoryStockLow      = "inventory.stock.low"
+)
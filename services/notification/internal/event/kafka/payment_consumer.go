@@ -4,22 +4,49 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	"github.com/shestoi/GoBigTech/services/notification/internal/service"
 )
 
+// maxInFlightOrderPaid - сколько событий оплаты обрабатываются одновременно; дальше чтение ждёт
+// Слот занимает и уведомление, ожидающее сборку заказа, поэтому лимит - это и число одновременно ждущих заказов
+const maxInFlightOrderPaid = 64
+
+// orderPaidHandler обрабатывает событие оплаты; реализуется *service.NotificationService
+type orderPaidHandler interface {
+	HandleOrderPaid(ctx context.Context, event service.OrderPaidEvent, topic string, partition int, offset int64) error
+}
+
+// messageReader - методы *kafka.Reader, которые использует consumer
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Config() kafka.ReaderConfig
+	Close() error
+}
+
 // OrderPaidConsumer обрабатывает события оплаты заказа из Kafka
+// События разных заказов обрабатываются параллельно (до maxInFlight): уведомление, которое ждёт сборку
+// своего заказа (NOTIFICATION_COALESCE_WINDOW), не задерживает остальные. События одного заказа (ключ
+// сообщения - order_id) обрабатываются по очереди, offset'ы коммитятся по порядку через commitTracker
 type OrderPaidConsumer struct {
 	logger       *zap.Logger
-	reader       *kafka.Reader
-	service      *service.NotificationService
+	reader       messageReader
+	service      orderPaidHandler
 	dlqPublisher *DLQPublisher
 	maxAttempts  int
 	backoffBase  time.Duration
+	maxInFlight  int
+
+	mu     sync.Mutex
+	orders map[string]chan struct{} // по ключу сообщения: закрывается, когда последнее прочитанное событие заказа обработано
 }
 
 // NewOrderPaidConsumer создаёт новый consumer для событий оплаты заказа
@@ -47,23 +74,41 @@ func NewOrderPaidConsumer(
 		dlqPublisher: dlqPublisher,
 		maxAttempts:  maxAttempts,
 		backoffBase:  backoffBase,
+		maxInFlight:  maxInFlightOrderPaid,
+		orders:       make(map[string]chan struct{}),
 	}
 }
 
 // Start запускает consumer и начинает обработку сообщений
 // Использует at-least-once семантику: FetchMessage + CommitMessages после успешной обработки
+// При отмене ctx дожидается сообщений, которые уже обрабатываются
 func (c *OrderPaidConsumer) Start(ctx context.Context) error {
 	c.logger.Info("starting kafka consumer",
 		zap.String("topic", c.reader.Config().Topic),
 		zap.String("group_id", c.reader.Config().GroupID),
 		zap.Int("max_retry_attempts", c.maxAttempts),
 		zap.Duration("retry_backoff_base", c.backoffBase),
+		zap.Int("max_in_flight", c.maxInFlight),
 	)
 
+	commits := newCommitTracker(func(m kafka.Message) { c.commit(ctx, m) })
+	slots := make(chan struct{}, c.maxInFlight)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
 	for {
+		// Свободный слот - до чтения: при лимите сообщение не должно висеть прочитанным
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			c.logger.Info("consumer context cancelled, stopping")
+			return nil
+		}
+
 		// FetchMessage вместо ReadMessage для ручного контроля commit
 		m, err := c.reader.FetchMessage(ctx)
 		if err != nil {
+			<-slots
 			// Если контекст отменён, выходим
 			if ctx.Err() != nil {
 				c.logger.Info("consumer context cancelled, stopping")
@@ -76,34 +121,91 @@ func (c *OrderPaidConsumer) Start(ctx context.Context) error {
 			continue
 		}
 
-		// Обрабатываем сообщение
-		shouldCommit := c.processMessage(ctx, m)
-
-		// Коммитим offset только после успешной обработки
-		if shouldCommit {
-			if err := c.reader.CommitMessages(ctx, m); err != nil {
-				c.logger.Error("failed to commit message offset",
-					zap.Error(err),
-					zap.String("topic", m.Topic),
-					zap.Int("partition", m.Partition),
-					zap.Int64("offset", m.Offset),
-				)
-				// Продолжаем обработку следующего сообщения
-				continue
+		tracked := commits.track(m)
+		previous, done := c.enqueueOrder(string(m.Key))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if previous != nil {
+				<-previous
 			}
 
-			c.logger.Debug("message offset committed",
-				zap.String("topic", m.Topic),
-				zap.Int("partition", m.Partition),
-				zap.Int64("offset", m.Offset),
-			)
-		}
+			// Коммитим offset только после успешной обработки
+			shouldCommit := c.processMessage(ctx, m)
+			c.finishOrder(string(m.Key), done)
+			commits.finish(tracked, shouldCommit)
+		}()
+	}
+}
+
+// enqueueOrder ставит сообщение в очередь заказа с ключом key
+// previous - канал предыдущего сообщения заказа, которое ещё обрабатывается (nil - нет такого), done закрывает finishOrder
+func (c *OrderPaidConsumer) enqueueOrder(key string) (previous <-chan struct{}, done chan struct{}) {
+	done = make(chan struct{})
+	if key == "" {
+		return nil, done
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.orders[key]; ok {
+		previous = last
+	}
+	c.orders[key] = done
+	return previous, done
+}
+
+// finishOrder отпускает следующее сообщение заказа
+func (c *OrderPaidConsumer) finishOrder(key string, done chan struct{}) {
+	close(done)
+	if key == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.orders[key] == done {
+		delete(c.orders, key)
 	}
 }
 
+// commit коммитит offset сообщения; ошибка только логируется - offset подтвердит коммит следующего сообщения
+func (c *OrderPaidConsumer) commit(ctx context.Context, m kafka.Message) {
+	if err := c.reader.CommitMessages(ctx, m); err != nil {
+		c.logger.Error("failed to commit message offset",
+			zap.Error(err),
+			zap.String("topic", m.Topic),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return
+	}
+
+	c.logger.Debug("message offset committed",
+		zap.String("topic", m.Topic),
+		zap.Int("partition", m.Partition),
+		zap.Int64("offset", m.Offset),
+	)
+}
+
 // processMessage обрабатывает одно сообщение из Kafka
 // Возвращает true, если нужно закоммитить offset (успешная обработка)
 func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
+	// Продолжаем trace из заголовков сообщения (order outbox -> notification)
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	// Маршрутизация по заголовку event_type: чужие события пропускаем, не разбирая body
+	if eventType := headers.EventType(); eventType != "" && eventType != eventTypeOrderPaymentCompleted {
+		c.logger.Debug("skipping message with unexpected event_type",
+			zap.String("event_type", eventType),
+			zap.String("event_id", headers.EventID()),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return true
+	}
+
 	// Парсим JSON сообщение
 	var payload map[string]interface{}
 	if err := json.Unmarshal(m.Value, &payload); err != nil {
@@ -164,7 +266,10 @@ func (c *OrderPaidConsumer) processMessage(ctx context.Context, m kafka.Message)
 			zap.Int64("offset", m.Offset),
 		)
 		dlqErr := fmt.Errorf("exhausted all retry attempts")
-		if err := c.dlqPublisher.Publish(context.Background(), m, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
+		// retry_count в заголовках DLQ-сообщения - сколько попыток обработки было сделано
+		dlqMsg := m
+		dlqMsg.Headers = headers.WithRetryCount(c.maxAttempts).Kafka()
+		if err := c.dlqPublisher.Publish(context.Background(), dlqMsg, dlqErr, event.EventType, event.EventID, event.OrderID); err != nil {
 			c.logger.Error("failed to publish to DLQ, not committing",
 				zap.Error(err),
 			)
@@ -269,6 +374,11 @@ func (c *OrderPaidConsumer) parseOrderPaidEvent(payload map[string]interface{})
 	if v, ok := payload["payment_method"].(string); ok {
 		event.PaymentMethod = v
 	}
+	// События, опубликованные до появления мультивалютности, не содержат currency
+	event.Currency = "RUB"
+	if v, ok := payload["currency"].(string); ok && v != "" {
+		event.Currency = v
+	}
 
 	return event, nil
 }
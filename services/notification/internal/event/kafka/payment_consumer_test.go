@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/service"
+)
+
+// fakeReader отдаёт сообщения из канала и запоминает закоммиченные offset'ы
+type fakeReader struct {
+	messages chan kafka.Message
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func newFakeReader() *fakeReader {
+	return &fakeReader{messages: make(chan kafka.Message, 16)}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.messages:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: "order.payment.completed", GroupID: "notification-test"}
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+func (r *fakeReader) committedOffsets() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// heldHandler держит событие оплаты, пока тест не отпустит его - как уведомление, ждущее сборку заказа
+type heldHandler struct {
+	started chan string              // event_id события, обработка которого началась
+	release map[string]chan struct{} // по event_id
+}
+
+func newHeldHandler(eventIDs ...string) *heldHandler {
+	h := &heldHandler{started: make(chan string, len(eventIDs)), release: make(map[string]chan struct{})}
+	for _, eventID := range eventIDs {
+		h.release[eventID] = make(chan struct{})
+	}
+	return h
+}
+
+func (h *heldHandler) HandleOrderPaid(ctx context.Context, event service.OrderPaidEvent, topic string, partition int, offset int64) error {
+	h.started <- event.EventID
+	<-h.release[event.EventID]
+	return nil
+}
+
+func orderPaidMessage(offset int64, eventID, orderID string) kafka.Message {
+	value := fmt.Sprintf(`{"event_id":%q,"event_type":"order.payment.completed","order_id":%q,"user_id":"user-1","amount":30000}`, eventID, orderID)
+	return kafka.Message{Topic: "order.payment.completed", Partition: 0, Offset: offset, Key: []byte(orderID), Value: []byte(value)}
+}
+
+// startOrderPaidConsumer запускает consumer с fakeReader; остановка и ожидание Start - в t.Cleanup
+func startOrderPaidConsumer(t *testing.T, handler orderPaidHandler) *fakeReader {
+	reader := newFakeReader()
+	consumer := &OrderPaidConsumer{
+		logger:      zap.NewNop(),
+		reader:      reader,
+		service:     handler,
+		maxAttempts: 1,
+		backoffBase: time.Millisecond,
+		maxInFlight: 8,
+		orders:      make(map[string]chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- consumer.Start(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-stopped)
+	})
+	return reader
+}
+
+func requireStarted(t *testing.T, handler *heldHandler, eventID string) {
+	t.Helper()
+	select {
+	case started := <-handler.started:
+		require.Equal(t, eventID, started)
+	case <-time.After(time.Second):
+		t.Fatalf("event %s is not being handled", eventID)
+	}
+}
+
+func TestOrderPaidConsumer_Concurrency(t *testing.T) {
+	t.Run("two orders are held at the same time", func(t *testing.T) {
+		handler := newHeldHandler("paid-1", "paid-2")
+		reader := startOrderPaidConsumer(t, handler)
+
+		reader.messages <- orderPaidMessage(1, "paid-1", "order-1")
+		reader.messages <- orderPaidMessage(2, "paid-2", "order-2")
+
+		// Оплата order-1 ещё ждёт, а order-2 уже обрабатывается
+		var started []string
+		for i := 0; i < 2; i++ {
+			select {
+			case eventID := <-handler.started:
+				started = append(started, eventID)
+			case <-time.After(time.Second):
+				t.Fatalf("only %v are being handled", started)
+			}
+		}
+		require.ElementsMatch(t, []string{"paid-1", "paid-2"}, started)
+		require.Empty(t, reader.committedOffsets())
+
+		// order-2 отправлен раньше: его offset подтвердил бы и ждущий order-1, поэтому коммита нет
+		close(handler.release["paid-2"])
+		require.Never(t, func() bool { return len(reader.committedOffsets()) > 0 }, 50*time.Millisecond, time.Millisecond)
+
+		close(handler.release["paid-1"])
+		require.Eventually(t, func() bool {
+			committed := reader.committedOffsets()
+			return len(committed) > 0 && committed[len(committed)-1] == 2
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("events of one order are handled in turn", func(t *testing.T) {
+		handler := newHeldHandler("paid-1", "paid-1-retry")
+		reader := startOrderPaidConsumer(t, handler)
+
+		reader.messages <- orderPaidMessage(1, "paid-1", "order-1")
+		reader.messages <- orderPaidMessage(2, "paid-1-retry", "order-1")
+
+		requireStarted(t, handler, "paid-1")
+		require.Never(t, func() bool { return len(handler.started) > 0 }, 50*time.Millisecond, time.Millisecond)
+
+		close(handler.release["paid-1"])
+		requireStarted(t, handler, "paid-1-retry")
+		close(handler.release["paid-1-retry"])
+		require.Eventually(t, func() bool {
+			committed := reader.committedOffsets()
+			return len(committed) > 0 && committed[len(committed)-1] == 2
+		}, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,232 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/notification/internal/service"
+)
+
+// StockLowConsumer обрабатывает события низкого остатка товара (inventory.stock.low) из Kafka
+type StockLowConsumer struct {
+	logger       *zap.Logger
+	reader       *kafka.Reader
+	service      *service.StockAlertService
+	dlqPublisher *DLQPublisher
+	maxAttempts  int
+	backoffBase  time.Duration
+}
+
+// NewStockLowConsumer создаёт новый consumer для событий низкого остатка
+func NewStockLowConsumer(
+	logger *zap.Logger,
+	brokers []string,
+	groupID, topic string,
+	svc *service.StockAlertService,
+	dlqPublisher *DLQPublisher,
+	maxAttempts int,
+	backoffBase time.Duration,
+) *StockLowConsumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  brokers,
+		GroupID:  groupID,
+		Topic:    topic,
+		MinBytes: 1,
+		MaxBytes: 10e6, // 10MB
+	})
+
+	return &StockLowConsumer{
+		logger:       logger,
+		reader:       reader,
+		service:      svc,
+		dlqPublisher: dlqPublisher,
+		maxAttempts:  maxAttempts,
+		backoffBase:  backoffBase,
+	}
+}
+
+// Start запускает consumer и начинает обработку сообщений
+// Использует at-least-once семантику: FetchMessage + CommitMessages после успешной обработки
+func (c *StockLowConsumer) Start(ctx context.Context) error {
+	c.logger.Info("starting kafka consumer",
+		zap.String("topic", c.reader.Config().Topic),
+		zap.String("group_id", c.reader.Config().GroupID),
+		zap.Int("max_retry_attempts", c.maxAttempts),
+		zap.Duration("retry_backoff_base", c.backoffBase),
+	)
+
+	for {
+		m, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Info("consumer context cancelled, stopping")
+				return nil
+			}
+			c.logger.Error("failed to fetch message from kafka",
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if !c.processMessage(ctx, m) {
+			continue
+		}
+		if err := c.reader.CommitMessages(ctx, m); err != nil {
+			c.logger.Error("failed to commit message offset",
+				zap.Error(err),
+				zap.String("topic", m.Topic),
+				zap.Int("partition", m.Partition),
+				zap.Int64("offset", m.Offset),
+			)
+		}
+	}
+}
+
+// processMessage обрабатывает одно сообщение из Kafka
+// Возвращает true, если нужно закоммитить offset (успешная обработка или сообщение ушло в DLQ)
+func (c *StockLowConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
+	// Продолжаем trace из заголовков сообщения (inventory reserve -> notification)
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	if eventType := headers.EventType(); eventType != "" && eventType != eventTypeInventoryStockLow {
+		c.logger.Debug("skipping message with unexpected event_type",
+			zap.String("event_type", eventType),
+			zap.String("event_id", headers.EventID()),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return true
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(m.Value, &payload); err != nil {
+		c.logger.Error("failed to unmarshal kafka message",
+			zap.Error(err),
+			zap.String("topic", m.Topic),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return c.publishDLQ(m, err, "", "")
+	}
+
+	event, err := parseStockLowEvent(payload)
+	if err != nil {
+		c.logger.Error("failed to parse stock low event",
+			zap.Error(err),
+			zap.String("topic", m.Topic),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		eventType, _ := payload["event_type"].(string)
+		eventID, _ := payload["event_id"].(string)
+		return c.publishDLQ(m, err, eventType, eventID)
+	}
+
+	if !c.handleWithRetry(ctx, m, event) {
+		c.logger.Error("failed to handle stock low event after all retries, sending to DLQ",
+			zap.String("product_id", event.ProductID),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		dlqMsg := m
+		dlqMsg.Headers = headers.WithRetryCount(c.maxAttempts).Kafka()
+		return c.publishDLQ(dlqMsg, fmt.Errorf("exhausted all retry attempts"), event.EventType, event.EventID)
+	}
+
+	return true
+}
+
+// publishDLQ отправляет сообщение в DLQ; false - DLQ недоступна, offset не коммитится
+// order_id в DLQ пустой: событие относится к товару
+func (c *StockLowConsumer) publishDLQ(m kafka.Message, cause error, eventType, eventID string) bool {
+	if err := c.dlqPublisher.Publish(context.Background(), m, cause, eventType, eventID, ""); err != nil {
+		c.logger.Error("failed to publish to DLQ, not committing",
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
+
+// handleWithRetry обрабатывает событие с экспоненциальным backoff между попытками
+// Возвращает true при успешной обработке, false при исчерпании попыток
+func (c *StockLowConsumer) handleWithRetry(ctx context.Context, m kafka.Message, event service.StockLowEvent) bool {
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := c.backoffBase * time.Duration(1<<uint(attempt-2))
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(backoff):
+			}
+		}
+
+		err := c.service.HandleStockLow(ctx, event, m.Topic, m.Partition, m.Offset)
+		if err == nil {
+			return true
+		}
+		c.logger.Warn("failed to handle stock low event",
+			zap.Error(err),
+			zap.String("product_id", event.ProductID),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", c.maxAttempts),
+		)
+	}
+	return false
+}
+
+// parseStockLowEvent преобразует payload в StockLowEvent
+func parseStockLowEvent(payload map[string]interface{}) (service.StockLowEvent, error) {
+	event := service.StockLowEvent{}
+
+	if v, ok := payload["event_id"].(string); ok && v != "" {
+		event.EventID = v
+	} else {
+		return event, &ParseError{Field: "event_id", Message: "event_id is required"}
+	}
+	if v, ok := payload["event_type"].(string); ok {
+		event.EventType = v
+	}
+	if v, ok := payload["event_version"].(float64); ok {
+		event.EventVersion = int(v)
+	}
+	if v, ok := payload["occurred_at"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			event.OccurredAt = t
+		}
+	}
+	if v, ok := payload["product_id"].(string); ok && v != "" {
+		event.ProductID = v
+	} else {
+		return event, &ParseError{Field: "product_id", Message: "product_id is required"}
+	}
+	if v, ok := payload["sku"].(string); ok {
+		event.SKU = v
+	}
+	if v, ok := payload["name"].(string); ok {
+		event.Name = v
+	}
+	if v, ok := payload["available"].(float64); ok {
+		event.Available = int32(v)
+	}
+	if v, ok := payload["threshold"].(float64); ok {
+		event.Threshold = int32(v)
+	}
+
+	return event, nil
+}
+
+// Close закрывает Kafka reader
+func (c *StockLowConsumer) Close() error {
+	c.logger.Info("closing kafka consumer")
+	return c.reader.Close()
+}
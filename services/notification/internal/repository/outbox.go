@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Статусы сообщения в outbox
+const (
+	OutboxStatusPending = "pending" // ждёт отправки (в том числе повторной)
+	OutboxStatusSent    = "sent"    // отправлено в Telegram
+	OutboxStatusFailed  = "failed"  // попытки исчерпаны
+)
+
+// OutboxMessage - готовое к отправке уведомление пользователю в Telegram
+// Consumer рендерит текст и сохраняет сообщение, delivery worker отправляет его независимо от чтения Kafka
+type OutboxMessage struct {
+	EventID   string // событие, вызвавшее уведомление; для объединённого уведомления - событие сборки
+	EventType string
+	OrderID   string
+	ChatID    string
+	Text      string
+	Attempts  int
+}
+
+// OutboxRepository определяет интерфейс для очереди отправки уведомлений
+type OutboxRepository interface {
+	// EnqueueOutboxMessage сохраняет сообщение и в той же транзакции переводит события inboxEventIDs в статус sent
+	// Повторная постановка того же EventID ничего не меняет
+	EnqueueOutboxMessage(ctx context.Context, message OutboxMessage, inboxEventIDs []string) error
+
+	// ClaimDueOutboxMessages забирает до limit pending сообщений, время попытки которых наступило к now,
+	// и откладывает их следующую попытку на lease: другой инстанс не отправит их, пока попытка не завершится,
+	// а после падения инстанса сообщения вернутся в очередь по истечении lease
+	ClaimDueOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+
+	// MarkOutboxMessageSent отмечает сообщение как отправленное
+	MarkOutboxMessageSent(ctx context.Context, eventID string) error
+
+	// MarkOutboxAttemptFailed увеличивает attempts и сохраняет ошибку
+	// Сообщение остаётся pending до nextAttemptAt или, если final, переходит в failed
+	MarkOutboxAttemptFailed(ctx context.Context, eventID, errMsg string, nextAttemptAt time.Time, final bool) error
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+)
+
+// EnqueueOutboxMessage сохраняет сообщение в notification_outbox и помечает события inbox как sent в одной транзакции
+func (r *Repository) EnqueueOutboxMessage(ctx context.Context, message repository.OutboxMessage, inboxEventIDs []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO notification_outbox (event_id, event_type, order_id, chat_id, text)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (event_id) DO NOTHING`,
+		message.EventID, message.EventType, message.OrderID, message.ChatID, message.Text)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE notification_inbox_events SET status = 'sent', updated_at = now(), last_error = NULL WHERE event_id = ANY($1)`,
+		inboxEventIDs)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimDueOutboxMessages забирает pending сообщения, время попытки которых наступило, и откладывает их на lease
+// FOR UPDATE SKIP LOCKED не даёт двум инстансам забрать одно сообщение одновременно
+func (r *Repository) ClaimDueOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.OutboxMessage, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE notification_outbox o
+		 SET next_attempt_at = $2
+		 FROM (
+		     SELECT event_id FROM notification_outbox
+		     WHERE status = 'pending' AND next_attempt_at <= $1
+		     ORDER BY next_attempt_at ASC
+		     LIMIT $3
+		     FOR UPDATE SKIP LOCKED
+		 ) due
+		 WHERE o.event_id = due.event_id
+		 RETURNING o.event_id, o.event_type, o.order_id, o.chat_id, o.text, o.attempts, o.created_at`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type claimed struct {
+		message   repository.OutboxMessage
+		createdAt time.Time
+	}
+	var batch []claimed
+	for rows.Next() {
+		var c claimed
+		err := rows.Scan(&c.message.EventID, &c.message.EventType, &c.message.OrderID, &c.message.ChatID,
+			&c.message.Text, &c.message.Attempts, &c.createdAt)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса: отправляем в порядке постановки в очередь
+	sort.SliceStable(batch, func(i, j int) bool { return batch[i].createdAt.Before(batch[j].createdAt) })
+	messages := make([]repository.OutboxMessage, 0, len(batch))
+	for _, c := range batch {
+		messages = append(messages, c.message)
+	}
+	return messages, nil
+}
+
+// MarkOutboxMessageSent отмечает сообщение как отправленное
+func (r *Repository) MarkOutboxMessageSent(ctx context.Context, eventID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE notification_outbox
+		 SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = NOW()
+		 WHERE event_id = $1`,
+		eventID)
+	return err
+}
+
+// MarkOutboxAttemptFailed сохраняет неудачную попытку: следующая в nextAttemptAt или статус failed, если final
+func (r *Repository) MarkOutboxAttemptFailed(ctx context.Context, eventID, errMsg string, nextAttemptAt time.Time, final bool) error {
+	status := repository.OutboxStatusPending
+	if final {
+		status = repository.OutboxStatusFailed
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE notification_outbox
+		 SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+		 WHERE event_id = $1`,
+		eventID, status, errMsg, nextAttemptAt)
+	return err
+}
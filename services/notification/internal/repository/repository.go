@@ -5,8 +5,6 @@ import (
 	"time"
 )
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=NotificationRepository --dir=. --output=./mocks --outpkg=mocks
-
 // InboxUpsertResult результат UpsertInboxPending: уже обработано (sent) или можно продолжать (pending)
 type InboxUpsertResult struct {
 	AlreadyProcessed bool // true — запись есть со статусом sent, не обрабатывать
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+)
+
+// orderCoalescer связывает уведомление об оплате, ожидающее сборку, с событием сборки того же заказа
+//
+// Ожидание идёт внутри обработчика оплаты: offset события оплаты коммитится только после отправки
+// (объединённой или отдельной), поэтому at-least-once сохраняется. Consumer оплат обрабатывает разные заказы
+// параллельно, так что ждать сборки одновременно могут уведомления многих заказов. Группировка работает в пределах
+// одного инстанса: если оплата и сборка заказа читаются разными инстансами, уведомления уходят раздельно
+type orderCoalescer struct {
+	mu      sync.Mutex
+	waiting map[string]*heldPayment // по order_id
+}
+
+// heldPayment - уведомление об оплате, ожидающее событие сборки
+type heldPayment struct {
+	event OrderPaidEvent
+	done  chan struct{} // закрывается, когда обработчик сборки закончил объединённую отправку
+	err   error         // результат объединённой отправки; читать после <-done
+}
+
+func newOrderCoalescer() *orderCoalescer {
+	return &orderCoalescer{waiting: make(map[string]*heldPayment)}
+}
+
+// hold регистрирует ожидающее уведомление об оплате
+// Возвращает nil, если для заказа уже ждёт другое событие оплаты: тогда уведомление отправляется без ожидания
+func (c *orderCoalescer) hold(event OrderPaidEvent) *heldPayment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.waiting[event.OrderID]; ok {
+		return nil
+	}
+	h := &heldPayment{event: event, done: make(chan struct{})}
+	c.waiting[event.OrderID] = h
+	return h
+}
+
+// release снимает ожидание по истечении окна
+// Возвращает false, если событие сборки уже забрало оплату: тогда нужно дождаться h.done
+func (c *orderCoalescer) release(h *heldPayment) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.waiting[h.event.OrderID] != h {
+		return false
+	}
+	delete(c.waiting, h.event.OrderID)
+	return true
+}
+
+// claim забирает ожидающее уведомление об оплате заказа для объединённой отправки; nil - никто не ждёт
+// Забравший обязан вызвать finish
+func (c *orderCoalescer) claim(orderID string) *heldPayment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.waiting[orderID]
+	if !ok {
+		return nil
+	}
+	delete(c.waiting, orderID)
+	return h
+}
+
+// finish сообщает обработчику оплаты результат объединённой отправки
+// err != nil - обработчик оплаты отправляет своё уведомление отдельно
+func (h *heldPayment) finish(err error) {
+	h.err = err
+	close(h.done)
+}
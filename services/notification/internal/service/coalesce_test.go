@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	grpcclient "github.com/shestoi/GoBigTech/services/notification/internal/client/grpc"
+	gmocks "github.com/shestoi/GoBigTech/services/notification/internal/client/grpc/mocks"
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	rmocks "github.com/shestoi/GoBigTech/services/notification/internal/repository/mocks"
+	tmocks "github.com/shestoi/GoBigTech/services/notification/internal/telegram/mocks"
+	"github.com/shestoi/GoBigTech/services/notification/internal/templates"
+)
+
+const testTelegramID = "12345"
+
+type coalesceFixture struct {
+	service *NotificationService
+	repo    *rmocks.NotificationRepository
+	sender  *tmocks.Sender
+}
+
+func newCoalesceFixture(t *testing.T, window time.Duration) coalesceFixture {
+	renderer, err := templates.NewRenderer(zap.NewNop(), "../../templates")
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+	repo := rmocks.NewNotificationRepository(t)
+	sender := tmocks.NewSender(t)
+	iam := gmocks.NewIAMClient(t)
+
+	telegramID := testTelegramID
+	iam.On("GetUserContact", mock.Anything, "user-1").Return(&grpcclient.UserContact{TelegramID: &telegramID, PreferredChannel: "telegram"}, nil)
+	repo.On("UpsertInboxPending", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "order-1", mock.Anything, mock.Anything, mock.Anything).
+		Return(&repository.InboxUpsertResult{CanProcess: true}, nil)
+
+	return coalesceFixture{
+		service: NewNotificationService(zap.NewNop(), repo, sender, renderer, iam, window, nil, nil),
+		repo:    repo,
+		sender:  sender,
+	}
+}
+
+func paidEvent() OrderPaidEvent {
+	return OrderPaidEvent{EventID: "paid-1", EventType: "order.payment.completed", OrderID: "order-1", UserID: "user-1", Amount: 30000, Currency: "RUB", PaymentMethod: "card"}
+}
+
+func assembledEvent() OrderAssemblyCompletedEvent {
+	return OrderAssemblyCompletedEvent{EventID: "assembled-1", EventType: "order.assembly.completed", OrderID: "order-1", UserID: "user-1"}
+}
+
+// handlePaidAsync запускает обработку оплаты и ждёт, пока уведомление встанет в ожидание сборки
+func handlePaidAsync(t *testing.T, s *NotificationService, event OrderPaidEvent) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- s.HandleOrderPaid(context.Background(), event, "order.payment.completed", 0, 1)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.coalescer.mu.Lock()
+		_, waiting := s.coalescer.waiting[event.OrderID]
+		s.coalescer.mu.Unlock()
+		if waiting {
+			return done
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("payment notification is not waiting for assembly")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNotificationService_Coalesce(t *testing.T) {
+	t.Run("assembly within window sends one message and marks both events sent", func(t *testing.T) {
+		f := newCoalesceFixture(t, time.Minute)
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "Заказ оплачен и собран") && strings.Contains(text, "Сумма: 30000")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "paid-1").Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "assembled-1").Return(nil).Once()
+
+		paid := handlePaidAsync(t, f.service, paidEvent())
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("assembly: expected no error, got %v", err)
+		}
+		if err := <-paid; err != nil {
+			t.Fatalf("payment: expected no error, got %v", err)
+		}
+	})
+
+	t.Run("payments of two orders wait for assembly at the same time", func(t *testing.T) {
+		f := newCoalesceFixture(t, time.Minute)
+		f.repo.On("UpsertInboxPending", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "order-2", mock.Anything, mock.Anything, mock.Anything).
+			Return(&repository.InboxUpsertResult{CanProcess: true}, nil)
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "Заказ оплачен и собран")
+		})).Return(nil).Twice()
+		for _, eventID := range []string{"paid-1", "assembled-1", "paid-2", "assembled-2"} {
+			f.repo.On("MarkInboxSent", mock.Anything, eventID).Return(nil).Once()
+		}
+
+		secondPaid := paidEvent()
+		secondPaid.EventID, secondPaid.OrderID = "paid-2", "order-2"
+		secondAssembled := assembledEvent()
+		secondAssembled.EventID, secondAssembled.OrderID = "assembled-2", "order-2"
+
+		paid1 := handlePaidAsync(t, f.service, paidEvent())
+		paid2 := handlePaidAsync(t, f.service, secondPaid)
+		f.service.coalescer.mu.Lock()
+		waiting := len(f.service.coalescer.waiting)
+		f.service.coalescer.mu.Unlock()
+		if waiting != 2 {
+			t.Fatalf("expected 2 waiting payments, got %d", waiting)
+		}
+
+		// Сборки приходят в обратном порядке: каждая забирает оплату своего заказа
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), secondAssembled, "order.assembly.completed", 0, 2); err != nil {
+			t.Fatalf("assembly order-2: expected no error, got %v", err)
+		}
+		if err := <-paid2; err != nil {
+			t.Fatalf("payment order-2: expected no error, got %v", err)
+		}
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("assembly order-1: expected no error, got %v", err)
+		}
+		if err := <-paid1; err != nil {
+			t.Fatalf("payment order-1: expected no error, got %v", err)
+		}
+	})
+
+	t.Run("window elapsed sends payment separately", func(t *testing.T) {
+		f := newCoalesceFixture(t, 10*time.Millisecond)
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "✅ Заказ оплачен\n")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "paid-1").Return(nil).Once()
+
+		if err := f.service.HandleOrderPaid(context.Background(), paidEvent(), "order.payment.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(f.service.coalescer.waiting) != 0 {
+			t.Errorf("expected no waiting payments after window, got %d", len(f.service.coalescer.waiting))
+		}
+	})
+
+	t.Run("failed coalesced send falls back to separate payment notification", func(t *testing.T) {
+		f := newCoalesceFixture(t, time.Minute)
+		sendErr := errors.New("telegram unavailable")
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "Заказ оплачен и собран")
+		})).Return(sendErr).Once()
+		f.repo.On("MarkInboxFailed", mock.Anything, "assembled-1", sendErr.Error()).Return(nil).Once()
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "✅ Заказ оплачен\n")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "paid-1").Return(nil).Once()
+
+		paid := handlePaidAsync(t, f.service, paidEvent())
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); !errors.Is(err, sendErr) {
+			t.Fatalf("assembly: expected %v, got %v", sendErr, err)
+		}
+		if err := <-paid; err != nil {
+			t.Fatalf("payment: expected no error, got %v", err)
+		}
+	})
+
+	t.Run("assembly without waiting payment is sent alone", func(t *testing.T) {
+		f := newCoalesceFixture(t, time.Minute)
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "📦 Заказ собран\n")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "assembled-1").Return(nil).Once()
+
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
@@ -12,16 +12,53 @@ type OrderPaidEvent struct {
 	OccurredAt    time.Time
 	OrderID       string
 	UserID        string
-	Amount        int64
+	Amount        int64  // в минимальных единицах валюты
+	Currency      string // код валюты ISO 4217
 	PaymentMethod string
 }
 
 // OrderAssemblyCompletedEvent представляет событие завершения сборки заказа (входящее из Kafka)
+// Amount, Currency, PaymentMethod и Items приходят из события оплаты через assembly;
+// в событиях старого формата они отсутствуют и остаются нулевыми
 type OrderAssemblyCompletedEvent struct {
+	EventID       string
+	EventType     string
+	EventVersion  int
+	OccurredAt    time.Time
+	OrderID       string
+	UserID        string
+	Amount        int64  // в минимальных единицах валюты
+	Currency      string // код валюты ISO 4217
+	PaymentMethod string
+	Items         []OrderItem
+}
+
+// OrderCompletedNotification - данные объединённого уведомления: оплата и сборка заказа одним сообщением
+// Собирается, когда событие сборки пришло в пределах NOTIFICATION_COALESCE_WINDOW после события оплаты
+type OrderCompletedNotification struct {
+	OrderID   string
+	UserID    string
+	Paid      OrderPaidEvent
+	Assembled OrderAssemblyCompletedEvent
+}
+
+// OrderItem представляет позицию заказа в событиях
+type OrderItem struct {
+	ProductID string
+	Quantity  int32
+	Status    string // статус позиции после сборки (assembled, cancelled); только в событии сборки, может отсутствовать
+}
+
+// StockLowEvent представляет событие низкого остатка товара из Inventory (входящее из Kafka)
+// Inventory публикует его, когда резервирование опустило остаток ниже порога карточки товара
+type StockLowEvent struct {
 	EventID      string
 	EventType    string
 	EventVersion int
 	OccurredAt   time.Time
-	OrderID      string
-	UserID       string
+	ProductID    string
+	SKU          string
+	Name         string
+	Available    int32 // остаток после резервирования
+	Threshold    int32 // порог низкого остатка карточки товара
 }
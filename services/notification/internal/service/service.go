@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
+	_ "time/tzdata" // базы часовых поясов нет в alpine-образе: без неё time.LoadLocation не найдёт зоны IANA
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	grpcclient "github.com/shestoi/GoBigTech/services/notification/internal/client/grpc"
+	orderclient "github.com/shestoi/GoBigTech/services/notification/internal/client/order"
 	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
 	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
 	"github.com/shestoi/GoBigTech/services/notification/internal/templates"
@@ -21,22 +24,40 @@ type NotificationService struct {
 	sender    telegram.Sender
 	renderer  *templates.Renderer
 	iamClient grpcclient.IAMClient
+
+	coalesceWindow time.Duration // 0 - уведомления об оплате и сборке всегда отправляются раздельно
+	coalescer      *orderCoalescer
+
+	outbox repository.OutboxRepository // nil - уведомления отправляются сразу при обработке события
+
+	orders orderclient.Client // nil - шаблоны получают только данные из события
 }
 
 // NewNotificationService создаёт новый экземпляр NotificationService
+// coalesceWindow > 0 - уведомление об оплате ждёт событие сборки того же заказа до coalesceWindow,
+// и если оно пришло, пользователь получает одно сообщение вместо двух
+// outbox != nil - готовые сообщения ставятся в очередь notification_outbox, отправляет их delivery.Worker
+// orders != nil - недостающие в событии сборки сумма и позиции запрашиваются у Order Service
 func NewNotificationService(
 	logger *zap.Logger,
 	repo repository.NotificationRepository,
 	sender telegram.Sender,
 	renderer *templates.Renderer,
 	iamClient grpcclient.IAMClient,
+	coalesceWindow time.Duration,
+	outbox repository.OutboxRepository,
+	orders orderclient.Client,
 ) *NotificationService {
 	return &NotificationService{
-		logger:    logger,
-		repo:      repo,
-		sender:    sender,
-		renderer:  renderer,
-		iamClient: iamClient,
+		logger:         logger,
+		repo:           repo,
+		sender:         sender,
+		renderer:       renderer,
+		iamClient:      iamClient,
+		coalesceWindow: coalesceWindow,
+		coalescer:      newOrderCoalescer(),
+		outbox:         outbox,
+		orders:         orders,
 	}
 }
 
@@ -70,7 +91,12 @@ func (s *NotificationService) HandleOrderPaid(ctx context.Context, event OrderPa
 		return nil
 	}
 
-	telegramID, preferredChannel, err := s.iamClient.GetUserContact(ctx, event.UserID)
+	// Группировка с событием сборки: объединённое сообщение отправляет обработчик сборки
+	if s.waitForAssembly(ctx, event) {
+		return nil
+	}
+
+	contact, err := s.iamClient.GetUserContact(ctx, event.UserID)
 	if err != nil {
 		grpcStatus, ok := status.FromError(err)
 		if ok && grpcStatus.Code() == codes.NotFound {
@@ -92,6 +118,7 @@ func (s *NotificationService) HandleOrderPaid(ctx context.Context, event OrderPa
 		return fmt.Errorf("failed to get user contact: %w", err)
 	}
 
+	telegramID, preferredChannel := contact.TelegramID, contact.PreferredChannel
 	if telegramID == nil || *telegramID == "" {
 		s.logger.Info("user has no telegram_id, marking as sent (no notification)",
 			zap.String("event_id", event.EventID),
@@ -112,7 +139,9 @@ func (s *NotificationService) HandleOrderPaid(ctx context.Context, event OrderPa
 		)
 	}
 
-	text, err := s.renderer.RenderPaymentCompleted(event)
+	// Время события - в часовом поясе пользователя, шаблон - на его языке
+	event.OccurredAt = event.OccurredAt.In(s.userLocation(contact, event.EventID))
+	text, err := s.renderer.RenderPaymentCompleted(contact.Locale, event)
 	if err != nil {
 		s.logger.Error("failed to render payment template",
 			zap.Error(err),
@@ -123,7 +152,8 @@ func (s *NotificationService) HandleOrderPaid(ctx context.Context, event OrderPa
 		return err
 	}
 
-	if err := s.sender.Send(ctx, *telegramID, text); err != nil {
+	message := repository.OutboxMessage{EventID: event.EventID, EventType: event.EventType, OrderID: event.OrderID, ChatID: *telegramID, Text: text}
+	if err := s.deliver(ctx, message, event.EventID); err != nil {
 		s.logger.Error("failed to send telegram notification, will retry",
 			zap.Error(err),
 			zap.String("event_id", event.EventID),
@@ -135,7 +165,6 @@ func (s *NotificationService) HandleOrderPaid(ctx context.Context, event OrderPa
 		return err
 	}
 
-	_ = s.repo.MarkInboxSent(ctx, event.EventID)
 	s.logger.Info("notification sent for order paid",
 		zap.String("event_id", event.EventID),
 		zap.String("order_id", event.OrderID),
@@ -174,7 +203,7 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 		return nil
 	}
 
-	telegramID, preferredChannel, err := s.iamClient.GetUserContact(ctx, event.UserID)
+	contact, err := s.iamClient.GetUserContact(ctx, event.UserID)
 	if err != nil {
 		grpcStatus, ok := status.FromError(err)
 		if ok && grpcStatus.Code() == codes.NotFound {
@@ -196,6 +225,7 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 		return fmt.Errorf("failed to get user contact: %w", err)
 	}
 
+	telegramID, preferredChannel := contact.TelegramID, contact.PreferredChannel
 	if telegramID == nil || *telegramID == "" {
 		s.logger.Info("user has no telegram_id, marking as sent (no notification)",
 			zap.String("event_id", event.EventID),
@@ -216,7 +246,18 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 		)
 	}
 
-	text, err := s.renderer.RenderAssemblyCompleted(event)
+	event = s.enrichAssembly(ctx, event)
+
+	// Время события - в часовом поясе пользователя, шаблон - на его языке
+	loc := s.userLocation(contact, event.EventID)
+	event.OccurredAt = event.OccurredAt.In(loc)
+
+	// Уведомление об оплате этого заказа ещё ждёт: отправляем одно сообщение за оба события
+	if held := s.coalescer.claim(event.OrderID); held != nil {
+		return s.sendOrderCompleted(ctx, held, event, contact.Locale, loc, *telegramID)
+	}
+
+	text, err := s.renderer.RenderAssemblyCompleted(contact.Locale, event)
 	if err != nil {
 		s.logger.Error("failed to render assembly template",
 			zap.Error(err),
@@ -227,7 +268,8 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 		return err
 	}
 
-	if err := s.sender.Send(ctx, *telegramID, text); err != nil {
+	message := repository.OutboxMessage{EventID: event.EventID, EventType: event.EventType, OrderID: event.OrderID, ChatID: *telegramID, Text: text}
+	if err := s.deliver(ctx, message, event.EventID); err != nil {
 		s.logger.Error("failed to send telegram notification, will retry",
 			zap.Error(err),
 			zap.String("event_id", event.EventID),
@@ -239,7 +281,6 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 		return err
 	}
 
-	_ = s.repo.MarkInboxSent(ctx, event.EventID)
 	s.logger.Info("notification sent for order assembly completed",
 		zap.String("event_id", event.EventID),
 		zap.String("order_id", event.OrderID),
@@ -248,3 +289,173 @@ func (s *NotificationService) HandleOrderAssemblyCompleted(ctx context.Context,
 	)
 	return nil
 }
+
+// enrichAssembly дополняет событие сборки суммой и позициями заказа из Order Service, если их нет в событии
+// (события старого формата). Ошибка запроса не задерживает уведомление: оно уходит с тем, что есть в событии
+func (s *NotificationService) enrichAssembly(ctx context.Context, event OrderAssemblyCompletedEvent) OrderAssemblyCompletedEvent {
+	if s.orders == nil || (event.Amount != 0 && len(event.Items) > 0) {
+		return event
+	}
+
+	order, err := s.orders.GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.logger.Warn("failed to get order details, sending notification without them",
+			zap.Error(err),
+			zap.String("event_id", event.EventID),
+			zap.String("order_id", event.OrderID),
+		)
+		return event
+	}
+
+	if event.Amount == 0 && order.TotalAmount != 0 {
+		event.Amount = order.TotalAmount
+		event.Currency = order.Currency
+	}
+	if len(event.Items) == 0 {
+		for _, item := range order.Items {
+			event.Items = append(event.Items, OrderItem{ProductID: item.ProductID, Quantity: item.Quantity, Status: item.Status})
+		}
+	}
+	return event
+}
+
+// waitForAssembly держит уведомление об оплате до coalesceWindow, ожидая событие сборки того же заказа
+// true - обработчик сборки отправил объединённое сообщение и пометил событие оплаты sent.
+// false - окно истекло, группировка выключена или объединённая отправка не удалась: уведомление отправляется отдельно
+func (s *NotificationService) waitForAssembly(ctx context.Context, event OrderPaidEvent) bool {
+	if s.coalesceWindow <= 0 {
+		return false
+	}
+	held := s.coalescer.hold(event)
+	if held == nil {
+		return false
+	}
+
+	timer := time.NewTimer(s.coalesceWindow)
+	defer timer.Stop()
+	select {
+	case <-held.done:
+	case <-timer.C:
+		if s.coalescer.release(held) {
+			return false
+		}
+		<-held.done // сборка забрала оплату в момент истечения окна
+	case <-ctx.Done():
+		// Остановка consumer: отправляем без ожидания, как если бы окно истекло
+		if s.coalescer.release(held) {
+			return false
+		}
+		<-held.done
+	}
+
+	if held.err != nil {
+		s.logger.Warn("coalesced notification failed, sending payment notification separately",
+			zap.Error(held.err),
+			zap.String("event_id", event.EventID),
+			zap.String("order_id", event.OrderID),
+		)
+		return false
+	}
+	s.logger.Info("payment notification coalesced with assembly completed",
+		zap.String("event_id", event.EventID),
+		zap.String("order_id", event.OrderID),
+	)
+	return true
+}
+
+// sendOrderCompleted отправляет одно сообщение за оплату и сборку заказа и помечает sent оба события
+// Результат передаётся ожидающему обработчику оплаты: при ошибке он отправит своё уведомление отдельно,
+// а событие сборки уходит в retry и отправится уже без группировки
+func (s *NotificationService) sendOrderCompleted(
+	ctx context.Context,
+	held *heldPayment,
+	assembled OrderAssemblyCompletedEvent,
+	locale string,
+	loc *time.Location,
+	telegramID string,
+) (err error) {
+	defer func() { held.finish(err) }()
+
+	paid := held.event
+	paid.OccurredAt = paid.OccurredAt.In(loc)
+	text, err := s.renderer.RenderOrderCompleted(locale, OrderCompletedNotification{
+		OrderID:   assembled.OrderID,
+		UserID:    assembled.UserID,
+		Paid:      paid,
+		Assembled: assembled,
+	})
+	if err != nil {
+		s.logger.Error("failed to render order completed template",
+			zap.Error(err),
+			zap.String("event_id", assembled.EventID),
+			zap.String("order_id", assembled.OrderID),
+		)
+		_ = s.repo.MarkInboxFailed(ctx, assembled.EventID, err.Error())
+		return err
+	}
+
+	message := repository.OutboxMessage{EventID: assembled.EventID, EventType: assembled.EventType, OrderID: assembled.OrderID, ChatID: telegramID, Text: text}
+	if err := s.deliver(ctx, message, paid.EventID, assembled.EventID); err != nil {
+		s.logger.Error("failed to send coalesced telegram notification, will retry",
+			zap.Error(err),
+			zap.String("event_id", assembled.EventID),
+			zap.String("order_id", assembled.OrderID),
+			zap.String("user_id", assembled.UserID),
+			zap.String("telegram_id", telegramID),
+		)
+		_ = s.repo.MarkInboxFailed(ctx, assembled.EventID, err.Error())
+		return err
+	}
+
+	s.logger.Info("coalesced notification sent for order paid and assembly completed",
+		zap.String("payment_event_id", paid.EventID),
+		zap.String("event_id", assembled.EventID),
+		zap.String("order_id", assembled.OrderID),
+		zap.String("user_id", assembled.UserID),
+		zap.String("telegram_id", telegramID),
+	)
+	return nil
+}
+
+// deliver отправляет уведомление пользователю и помечает события inboxEventIDs как sent
+// С outbox сообщение только ставится в очередь в одной транзакции с inbox, а отправляет его delivery.Worker
+// со своими retry и ограничением скорости: медленный Telegram не задерживает чтение Kafka.
+// Без outbox сообщение отправляется сразу, ошибка отправки уходит в retry consumer
+func (s *NotificationService) deliver(ctx context.Context, message repository.OutboxMessage, inboxEventIDs ...string) error {
+	if s.outbox != nil {
+		if err := s.outbox.EnqueueOutboxMessage(ctx, message, inboxEventIDs); err != nil {
+			return fmt.Errorf("failed to enqueue notification: %w", err)
+		}
+		s.logger.Debug("notification enqueued to outbox",
+			zap.String("event_id", message.EventID),
+			zap.String("order_id", message.OrderID),
+		)
+		return nil
+	}
+
+	if err := s.sender.Send(ctx, message.ChatID, message.Text); err != nil {
+		return err
+	}
+	for _, eventID := range inboxEventIDs {
+		_ = s.repo.MarkInboxSent(ctx, eventID)
+	}
+	return nil
+}
+
+// userLocation возвращает часовой пояс пользователя для времени в уведомлении.
+// Пустой или неизвестный timezone (IAM проверяет его при сохранении, но базы зон могут разойтись) - UTC
+func (s *NotificationService) userLocation(contact *grpcclient.UserContact, eventID string) *time.Location {
+	if contact.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(contact.Timezone)
+	if err != nil {
+		s.logger.Warn("unknown user timezone, using UTC",
+			zap.Error(err),
+			zap.String("event_id", eventID),
+			zap.String("timezone", contact.Timezone),
+		)
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	orderclient "github.com/shestoi/GoBigTech/services/notification/internal/client/order"
+	omocks "github.com/shestoi/GoBigTech/services/notification/internal/client/order/mocks"
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	rmocks "github.com/shestoi/GoBigTech/services/notification/internal/repository/mocks"
+)
+
+func TestNotificationService_Outbox(t *testing.T) {
+	t.Run("rendered message is enqueued instead of sent", func(t *testing.T) {
+		f := newCoalesceFixture(t, 0)
+		outbox := rmocks.NewOutboxRepository(t)
+		f.service.outbox = outbox
+		outbox.On("EnqueueOutboxMessage", mock.Anything, mock.MatchedBy(func(m repository.OutboxMessage) bool {
+			return m.EventID == "paid-1" && m.OrderID == "order-1" && m.ChatID == testTelegramID &&
+				strings.HasPrefix(m.Text, "✅ Заказ оплачен\n")
+		}), []string{"paid-1"}).Return(nil).Once()
+
+		if err := f.service.HandleOrderPaid(context.Background(), paidEvent(), "order.payment.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("coalesced message marks both events in one enqueue", func(t *testing.T) {
+		f := newCoalesceFixture(t, time.Minute)
+		outbox := rmocks.NewOutboxRepository(t)
+		f.service.outbox = outbox
+		outbox.On("EnqueueOutboxMessage", mock.Anything, mock.MatchedBy(func(m repository.OutboxMessage) bool {
+			return m.EventID == "assembled-1" && strings.Contains(m.Text, "Заказ оплачен и собран")
+		}), []string{"paid-1", "assembled-1"}).Return(nil).Once()
+
+		paid := handlePaidAsync(t, f.service, paidEvent())
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("assembly: expected no error, got %v", err)
+		}
+		if err := <-paid; err != nil {
+			t.Fatalf("payment: expected no error, got %v", err)
+		}
+	})
+
+	t.Run("enqueue failure is retried by consumer", func(t *testing.T) {
+		f := newCoalesceFixture(t, 0)
+		outbox := rmocks.NewOutboxRepository(t)
+		f.service.outbox = outbox
+		dbErr := errors.New("connection refused")
+		outbox.On("EnqueueOutboxMessage", mock.Anything, mock.Anything, []string{"assembled-1"}).Return(dbErr).Once()
+		f.repo.On("MarkInboxFailed", mock.Anything, "assembled-1", mock.Anything).Return(nil).Once()
+
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); !errors.Is(err, dbErr) {
+			t.Fatalf("expected %v, got %v", dbErr, err)
+		}
+	})
+}
+
+func TestNotificationService_EnrichAssembly(t *testing.T) {
+	t.Run("missing amount and items are taken from order service", func(t *testing.T) {
+		f := newCoalesceFixture(t, 0)
+		orders := omocks.NewClient(t)
+		f.service.orders = orders
+		orders.On("GetOrder", mock.Anything, "order-1").Return(&orderclient.Order{
+			ID:          "order-1",
+			TotalAmount: 30000,
+			Currency:    "RUB",
+			Items:       []orderclient.Item{{ProductID: "product-1", Quantity: 2, Status: "assembled"}},
+		}, nil).Once()
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.Contains(text, "product-1 × 2") && strings.Contains(text, "Сумма: 30000 (в минимальных единицах RUB)")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "assembled-1").Return(nil).Once()
+
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("lookup failure sends minimal message", func(t *testing.T) {
+		f := newCoalesceFixture(t, 0)
+		orders := omocks.NewClient(t)
+		f.service.orders = orders
+		orders.On("GetOrder", mock.Anything, "order-1").Return(nil, errors.New("connection refused")).Once()
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.MatchedBy(func(text string) bool {
+			return strings.HasPrefix(text, "📦 Заказ собран\n") && !strings.Contains(text, "Сумма")
+		})).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "assembled-1").Return(nil).Once()
+
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), assembledEvent(), "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("complete event does not query order service", func(t *testing.T) {
+		f := newCoalesceFixture(t, 0)
+		orders := omocks.NewClient(t)
+		f.service.orders = orders
+		event := assembledEvent()
+		event.Amount, event.Currency = 30000, "RUB"
+		event.Items = []OrderItem{{ProductID: "product-1", Quantity: 1}}
+		f.sender.On("Send", mock.Anything, testTelegramID, mock.Anything).Return(nil).Once()
+		f.repo.On("MarkInboxSent", mock.Anything, "assembled-1").Return(nil).Once()
+
+		if err := f.service.HandleOrderAssemblyCompleted(context.Background(), event, "order.assembly.completed", 0, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		orders.AssertNotCalled(t, "GetOrder", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/repository"
+	"github.com/shestoi/GoBigTech/services/notification/internal/telegram"
+)
+
+// StockAlertService отправляет алерты о низком остатке товаров в чат дежурных (тот же, что у алертов Alertmanager)
+// Пользователю ничего не отправляется: это операционный сигнал для закупки, а не уведомление о заказе
+type StockAlertService struct {
+	logger      *zap.Logger
+	repo        repository.NotificationRepository
+	sender      telegram.Sender
+	alertChatID string
+}
+
+// NewStockAlertService создаёт сервис алертов о низком остатке
+// alertChatID пустой - алерты только логируются (как webhook Alertmanager без ALERT_TELEGRAM_CHAT_ID)
+func NewStockAlertService(logger *zap.Logger, repo repository.NotificationRepository, sender telegram.Sender, alertChatID string) *StockAlertService {
+	return &StockAlertService{
+		logger:      logger,
+		repo:        repo,
+		sender:      sender,
+		alertChatID: alertChatID,
+	}
+}
+
+// HandleStockLow обрабатывает событие inventory.stock.low
+// Идемпотентность через тот же inbox, что и у уведомлений о заказах: повтор после отправки не дублирует алерт.
+// order_id в inbox пустой - событие относится к товару, а не к заказу
+func (s *StockAlertService) HandleStockLow(ctx context.Context, event StockLowEvent, topic string, partition int, offset int64) error {
+	s.logger.Info("handling stock low event",
+		zap.String("event_id", event.EventID),
+		zap.String("product_id", event.ProductID),
+		zap.Int32("available", event.Available),
+		zap.Int32("threshold", event.Threshold),
+	)
+
+	res, err := s.repo.UpsertInboxPending(ctx, event.EventID, event.EventType, event.OccurredAt, "", topic, partition, offset)
+	if err != nil {
+		s.logger.Error("failed to upsert inbox event",
+			zap.Error(err),
+			zap.String("event_id", event.EventID),
+			zap.String("product_id", event.ProductID),
+		)
+		return err
+	}
+	if res.AlreadyProcessed {
+		s.logger.Info("event already processed (sent)",
+			zap.String("event_id", event.EventID),
+			zap.String("product_id", event.ProductID),
+		)
+		return nil
+	}
+	if !res.CanProcess {
+		return nil
+	}
+
+	if s.alertChatID == "" {
+		s.logger.Warn("ALERT_TELEGRAM_CHAT_ID not set, stock low alert not sent",
+			zap.String("event_id", event.EventID),
+			zap.String("product_id", event.ProductID),
+		)
+		_ = s.repo.MarkInboxSent(ctx, event.EventID)
+		return nil
+	}
+
+	if err := s.sender.Send(ctx, s.alertChatID, formatStockLowAlert(event)); err != nil {
+		s.logger.Error("failed to send stock low alert, will retry",
+			zap.Error(err),
+			zap.String("event_id", event.EventID),
+			zap.String("product_id", event.ProductID),
+		)
+		_ = s.repo.MarkInboxFailed(ctx, event.EventID, err.Error())
+		return err
+	}
+
+	_ = s.repo.MarkInboxSent(ctx, event.EventID)
+	s.logger.Info("stock low alert sent",
+		zap.String("event_id", event.EventID),
+		zap.String("product_id", event.ProductID),
+	)
+	return nil
+}
+
+// formatStockLowAlert форматирует алерт о низком остатке для чата дежурных
+func formatStockLowAlert(event StockLowEvent) string {
+	var b strings.Builder
+	b.WriteString("📉 Низкий остаток товара\n")
+	if event.Name != "" {
+		b.WriteString(fmt.Sprintf("Товар: %s\n", event.Name))
+	}
+	if event.SKU != "" {
+		b.WriteString(fmt.Sprintf("SKU: %s\n", event.SKU))
+	}
+	b.WriteString(fmt.Sprintf("product_id: %s\n", event.ProductID))
+	b.WriteString(fmt.Sprintf("Остаток: %d (порог %d)", event.Available, event.Threshold))
+	return b.String()
+}
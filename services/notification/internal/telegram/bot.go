@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"context"
+	"time"
+)
+
+// MaxPollTimeout - максимальный таймаут long polling getUpdates, который принимает Telegram
+const MaxPollTimeout = 50 * time.Second
+
+// Bot - операции Bot API для интерактивных сообщений: алерты с командами /ack и /silence
+type Bot interface {
+	// SendMessages отправляет текст (при необходимости несколькими сообщениями, см. SplitMessage) и возвращает message_id частей
+	SendMessages(ctx context.Context, chatID, text string) ([]int64, error)
+	// Reply отправляет ответ на сообщение replyTo
+	Reply(ctx context.Context, chatID string, replyTo int64, text string) error
+	// EditMessageText заменяет текст ранее отправленного сообщения
+	EditMessageText(ctx context.Context, chatID string, messageID int64, text string) error
+	// GetUpdates ждёт новые обновления начиная с offset (long polling не дольше timeout)
+	GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error)
+}
+
+// Update - входящее обновление Bot API (обрабатываем только сообщения)
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message - сообщение в чате
+type Message struct {
+	MessageID      int64    `json:"message_id"`
+	From           *User    `json:"from,omitempty"`
+	Chat           Chat     `json:"chat"`
+	Text           string   `json:"text,omitempty"`
+	ReplyToMessage *Message `json:"reply_to_message,omitempty"`
+}
+
+// User - отправитель сообщения
+type User struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+}
+
+// DisplayName возвращает @username, а если его нет - имя пользователя
+func (u *User) DisplayName() string {
+	if u == nil {
+		return "unknown"
+	}
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	if u.FirstName != "" {
+		return u.FirstName
+	}
+	return "unknown"
+}
+
+// Chat - чат, в котором отправлено сообщение
+type Chat struct {
+	ID int64 `json:"id"`
+}
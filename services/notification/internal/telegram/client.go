@@ -24,29 +24,136 @@ type TelegramSender struct {
 	botToken string
 	apiURL   string
 	client   *http.Client
+	// pollClient - для getUpdates: long polling держит запрос дольше обычного таймаута client
+	pollClient *http.Client
 }
 
+// DefaultAPIURL - адрес Telegram Bot API
+const DefaultAPIURL = "https://api.telegram.org"
+
 // NewTelegramSender создаёт новый Telegram sender
-func NewTelegramSender(logger *zap.Logger, botToken string) *TelegramSender {
+// apiURL - адрес Bot API без /bot<token> (DefaultAPIURL; в e2e тестах - локальный fake сервер), пусто - DefaultAPIURL
+func NewTelegramSender(logger *zap.Logger, apiURL, botToken string) *TelegramSender {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
 	return &TelegramSender{
 		logger:   logger,
 		botToken: botToken,
-		apiURL:   "https://api.telegram.org/bot" + botToken,
+		apiURL:   strings.TrimRight(apiURL, "/") + "/bot" + botToken,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		pollClient: &http.Client{
+			Timeout: MaxPollTimeout + 10*time.Second,
+		},
 	}
 }
 
 // Send отправляет сообщение в Telegram
+// Текст длиннее MaxMessageLength отправляется несколькими сообщениями с маркерами "[i/n]" (см. SplitMessage).
+// Если отправка части не удалась, возвращается ошибка: при повторе уже отправленные части придут ещё раз.
 func (s *TelegramSender) Send(ctx context.Context, chatID, text string) error {
-	url := fmt.Sprintf("%s/sendMessage", s.apiURL)
+	_, err := s.SendMessages(ctx, chatID, text)
+	return err
+}
+
+// SendMessages отправляет текст так же, как Send, и возвращает message_id отправленных частей по порядку
+func (s *TelegramSender) SendMessages(ctx context.Context, chatID, text string) ([]int64, error) {
+	parts := SplitMessage(text, MaxMessageLength, MaxMessageParts)
+	if len(parts) > 1 {
+		s.logger.Info("telegram message too long, splitting",
+			zap.String("chat_id", chatID),
+			zap.Int("text_length", textLength(text)),
+			zap.Int("parts", len(parts)),
+		)
+	}
+
+	ids := make([]int64, 0, len(parts))
+	for i, part := range parts {
+		id, err := s.sendMessage(ctx, chatID, part, 0)
+		if err != nil {
+			if len(parts) > 1 {
+				return ids, fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+			}
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Reply отправляет ответ на сообщение replyTo (текст не делится, длинный ответ обрежет Telegram)
+func (s *TelegramSender) Reply(ctx context.Context, chatID string, replyTo int64, text string) error {
+	_, err := s.sendMessage(ctx, chatID, text, replyTo)
+	return err
+}
+
+// EditMessageText заменяет текст сообщения через editMessageText Bot API
+func (s *TelegramSender) EditMessageText(ctx context.Context, chatID string, messageID int64, text string) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       text,
+	}
+	if err := s.call(ctx, s.client, "editMessageText", payload, nil); err != nil {
+		return err
+	}
 
+	s.logger.Debug("telegram message edited",
+		zap.String("chat_id", chatID),
+		zap.Int64("message_id", messageID),
+	)
+	return nil
+}
+
+// GetUpdates получает обновления через getUpdates Bot API (long polling)
+// timeout ограничивается MaxPollTimeout; обновления с update_id < offset Telegram считает прочитанными
+func (s *TelegramSender) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	if timeout > MaxPollTimeout {
+		timeout = MaxPollTimeout
+	}
+	payload := map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}
+
+	var updates []Update
+	if err := s.call(ctx, s.pollClient, "getUpdates", payload, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// sendMessage отправляет одно сообщение через sendMessage Bot API и возвращает его message_id
+// replyTo = 0 - обычное сообщение, иначе ответ на сообщение replyTo
+func (s *TelegramSender) sendMessage(ctx context.Context, chatID, text string, replyTo int64) (int64, error) {
 	//Готовим payload (тело запроса)
 	payload := map[string]interface{}{
 		"chat_id": chatID,
 		"text":    text,
 	}
+	if replyTo != 0 {
+		payload["reply_to_message_id"] = replyTo
+	}
+
+	var message Message
+	if err := s.call(ctx, s.client, "sendMessage", payload, &message); err != nil {
+		return 0, err
+	}
+
+	s.logger.Debug("telegram message sent successfully",
+		zap.String("chat_id", chatID),
+		zap.Int64("message_id", message.MessageID),
+	)
+
+	return message.MessageID, nil
+}
+
+// call вызывает метод Bot API и декодирует поле result ответа в result (nil - результат не нужен)
+func (s *TelegramSender) call(ctx context.Context, client *http.Client, method string, payload, result interface{}) error {
+	url := fmt.Sprintf("%s/%s", s.apiURL, method)
 
 	//Превращаем payload в JSON
 	jsonData, err := json.Marshal(payload)
@@ -64,7 +171,7 @@ func (s *TelegramSender) Send(ctx context.Context, chatID, text string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	//Отправляем запрос и получаем ответ
-	resp, err := s.client.Do(req) //resp для получения ответа от Telegram
+	resp, err := client.Do(req) //resp для получения ответа от Telegram
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -76,22 +183,24 @@ func (s *TelegramSender) Send(ctx context.Context, chatID, text string) error {
 		return fmt.Errorf("telegram API status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	// Декодируем ответ от Telegram в формате JSON
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	//Телеграм обычно отвечает так: {"ok": true, "result": {"message_id": 1234567890}} или {"ok": false, "description": "Bad Request: chat not found"}
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	//Телеграм обычно отвечает так: {"ok": true, "result": {"message_id": 1234567890}} или {"ok": false, "description": "Bad Request: chat not found"}
-	if ok, _ := result["ok"].(bool); !ok { //ok для проверки успешности отправки сообщения
-		description, _ := result["description"].(string)
-		return fmt.Errorf("telegram API error: %s", description)
+	if !apiResp.OK {
+		return fmt.Errorf("telegram API error: %s", apiResp.Description)
 	}
 
-	s.logger.Debug("telegram message sent successfully",
-		zap.String("chat_id", chatID),
-	)
-
+	if result != nil && len(apiResp.Result) > 0 {
+		if err := json.Unmarshal(apiResp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
 	return nil
 }
 
@@ -116,6 +225,30 @@ func (s *NoOpSender) Send(ctx context.Context, chatID, text string) error {
 	return nil
 }
 
+// DryRunSender - Sender для режима NOTIFICATION_DRY_RUN: вместо отправки пишет сообщение в лог (Info).
+// Используется для проверки нового окружения на живом трафике без сообщений реальным пользователям.
+type DryRunSender struct {
+	logger *zap.Logger
+}
+
+// NewDryRunSender создаёт dry-run sender
+func NewDryRunSender(logger *zap.Logger) *DryRunSender {
+	return &DryRunSender{
+		logger: logger,
+	}
+}
+
+// Send не отправляет сообщение, а логирует то, что было бы отправлено
+func (s *DryRunSender) Send(ctx context.Context, chatID, text string) error {
+	s.logger.Info("dry-run: message not sent",
+		zap.String("chat_id", chatID),
+		zap.Int("text_length", len(text)),
+		zap.Int("parts", len(SplitMessage(text, MaxMessageLength, MaxMessageParts))),
+		zap.String("text_preview", truncate(text, 200)),
+	)
+	return nil
+}
+
 // truncate обрезает строку до указанной длины
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
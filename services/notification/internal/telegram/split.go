@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// MaxMessageLength - лимит Telegram на длину текста одного сообщения (в символах UTF-16)
+const MaxMessageLength = 4096
+
+// MaxMessageParts - на сколько сообщений максимум делится длинный текст; остаток отбрасывается
+const MaxMessageParts = 10
+
+const (
+	// truncatedLineMarker заканчивает строку, которая сама не помещается в сообщение
+	truncatedLineMarker = "… [обрезано]"
+	// truncatedMessageMarker заканчивает последнюю часть, если текст не уместился в MaxMessageParts сообщений
+	truncatedMessageMarker = "… [сообщение обрезано]"
+	// partMarkerReserve - место под маркер продолжения "\n[NN/NN]" в конце каждой части
+	partMarkerReserve = 8
+)
+
+// SplitMessage делит текст на части не длиннее limit символов для отправки несколькими сообщениями.
+// Текст, который помещается целиком, возвращается как есть. Иначе:
+//   - части режутся по границам строк, строки не разрываются между сообщениями;
+//   - строка (одно поле - описание алерта, список товаров), которая одна длиннее части, обрезается с маркером "… [обрезано]";
+//   - в конец каждой части добавляется маркер продолжения "[i/n]";
+//   - если частей больше maxParts, лишние отбрасываются, последняя заканчивается "… [сообщение обрезано]".
+func SplitMessage(text string, limit, maxParts int) []string {
+	if textLength(text) <= limit {
+		return []string{text}
+	}
+
+	budget := limit - partMarkerReserve
+	var (
+		parts   []string
+		current strings.Builder
+		curLen  int
+	)
+	flush := func() {
+		if part := strings.Trim(current.String(), "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+		curLen = 0
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = truncateText(line, budget, truncatedLineMarker)
+		lineLen := textLength(line)
+		if curLen > 0 && curLen+1+lineLen > budget {
+			flush()
+		}
+		if curLen > 0 {
+			current.WriteByte('\n')
+			curLen++
+		}
+		current.WriteString(line)
+		curLen += lineLen
+	}
+	flush()
+
+	if len(parts) > maxParts {
+		parts = parts[:maxParts]
+		last := parts[maxParts-1]
+		markerLen := textLength(truncatedMessageMarker)
+		if textLength(last)+1+markerLen <= budget {
+			parts[maxParts-1] = last + "\n" + truncatedMessageMarker
+		} else {
+			parts[maxParts-1] = truncateText(last, textLength(last)-1, truncatedMessageMarker)
+		}
+	}
+
+	for i := range parts {
+		parts[i] += fmt.Sprintf("\n[%d/%d]", i+1, len(parts))
+	}
+	return parts
+}
+
+// TruncateMessage обрезает текст до limit символов с маркером "… [сообщение обрезано]" (для editMessageText, где делить нельзя)
+func TruncateMessage(text string, limit int) string {
+	return truncateText(text, limit, truncatedMessageMarker)
+}
+
+// truncateText обрезает s до maxLen символов (вместе с marker), если s длиннее
+func truncateText(s string, maxLen int, marker string) string {
+	if textLength(s) <= maxLen {
+		return s
+	}
+
+	keep := maxLen - textLength(marker)
+	n := 0
+	for i, r := range s {
+		runeLen := utf16.RuneLen(r)
+		if runeLen < 0 {
+			runeLen = 1 // невалидный UTF-8 Telegram заменит на один символ
+		}
+		if n+runeLen > keep {
+			return s[:i] + marker
+		}
+		n += runeLen
+	}
+	return s
+}
+
+// textLength возвращает длину строки так, как её считает Telegram (в символах UTF-16)
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		if utf16.RuneLen(r) == 2 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSplitMessage(t *testing.T) {
+	t.Run("short text is sent as is", func(t *testing.T) {
+		parts := SplitMessage("hello\nworld", MaxMessageLength, MaxMessageParts)
+		if len(parts) != 1 || parts[0] != "hello\nworld" {
+			t.Fatalf("unexpected parts: %q", parts)
+		}
+	})
+
+	t.Run("long text is split by lines with continuation markers", func(t *testing.T) {
+		line := strings.Repeat("a", 99)
+		lines := make([]string, 100) // 100 строк по 100 символов с переводом строки - 10000 символов
+		for i := range lines {
+			lines[i] = line
+		}
+
+		parts := SplitMessage(strings.Join(lines, "\n"), MaxMessageLength, MaxMessageParts)
+		if len(parts) != 3 {
+			t.Fatalf("expected 3 parts, got %d", len(parts))
+		}
+		total := 0
+		for i, part := range parts {
+			if n := textLength(part); n > MaxMessageLength {
+				t.Fatalf("part %d is too long: %d", i, n)
+			}
+			marker := "\n[" + string(rune('1'+i)) + "/3]"
+			if !strings.HasSuffix(part, marker) {
+				t.Fatalf("part %d has no marker %q", i, marker)
+			}
+			for _, l := range strings.Split(strings.TrimSuffix(part, marker), "\n") {
+				if l != line {
+					t.Fatalf("line was broken: %q", l)
+				}
+				total++
+			}
+		}
+		if total != len(lines) {
+			t.Fatalf("expected %d lines, got %d", len(lines), total)
+		}
+	})
+
+	t.Run("oversized line is truncated", func(t *testing.T) {
+		parts := SplitMessage("header\n"+strings.Repeat("я", 5000)+"\nfooter", MaxMessageLength, MaxMessageParts)
+		// Обрезанная строка занимает целую часть, соседние строки уходят в свои части
+		if len(parts) != 3 {
+			t.Fatalf("expected 3 parts, got %d", len(parts))
+		}
+		if parts[0] != "header\n[1/3]" || parts[2] != "footer\n[3/3]" {
+			t.Fatalf("unexpected parts: %q, %q", parts[0], parts[2])
+		}
+		if !strings.HasSuffix(parts[1], truncatedLineMarker+"\n[2/3]") || textLength(parts[1]) > MaxMessageLength {
+			t.Fatalf("oversized line is not truncated: length %d", textLength(parts[1]))
+		}
+	})
+
+	t.Run("too many parts are dropped", func(t *testing.T) {
+		line := strings.Repeat("b", 1000)
+		parts := SplitMessage(strings.Repeat(line+"\n", 20), 2000, 3)
+		if len(parts) != 3 {
+			t.Fatalf("expected 3 parts, got %d", len(parts))
+		}
+		if !strings.Contains(parts[2], truncatedMessageMarker) {
+			t.Fatalf("last part has no truncation marker: %q", parts[2][len(parts[2])-40:])
+		}
+		for i, part := range parts {
+			if n := textLength(part); n > 2000 {
+				t.Fatalf("part %d is too long: %d", i, n)
+			}
+		}
+	})
+
+	t.Run("length is counted in UTF-16", func(t *testing.T) {
+		if n := textLength("a😀я"); n != 4 {
+			t.Fatalf("expected 4, got %d", n)
+		}
+		if got := truncateText("😀😀😀", 5, "…"); got != "😀😀…" {
+			t.Fatalf("unexpected truncation: %q", got)
+		}
+	})
+}
+
+func TestTelegramSender_SendSplitsLongMessages(t *testing.T) {
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		texts = append(texts, payload.Text)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	sender := NewTelegramSender(zap.NewNop(), srv.URL, "token")
+	sender.client = srv.Client()
+
+	text := strings.Repeat(strings.Repeat("c", 99)+"\n", 50)
+	if err := sender.Send(context.Background(), "chat-1", text); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(texts) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(texts))
+	}
+	if !strings.HasSuffix(texts[1], "\n[2/2]") {
+		t.Fatalf("second message has no marker: %q", texts[1][len(texts[1])-10:])
+	}
+}
@@ -3,52 +3,118 @@ package templates
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"text/template"
 
 	"go.uber.org/zap"
 )
 
 // Renderer рендерит шаблоны для уведомлений
+//
+// Кроме шаблона по умолчанию (<name>.tmpl, на русском) могут быть локализованные варианты <name>.<locale>.tmpl,
+// например payment_completed.en.tmpl или payment_completed.en-US.tmpl. Шаблон выбирается по locale пользователя
+// из IAM: сначала точное совпадение тега, затем по языку (en-US -> en), иначе шаблон по умолчанию.
 type Renderer struct {
 	logger           *zap.Logger
-	paymentTemplate  *template.Template
-	assemblyTemplate *template.Template
+	paymentTemplate  localizedTemplate
+	assemblyTemplate localizedTemplate
+	orderTemplate    localizedTemplate // оплата и сборка одним сообщением (NOTIFICATION_COALESCE_WINDOW)
+}
+
+// localizedTemplate - шаблон по умолчанию и его локализованные варианты по тегу locale
+type localizedTemplate struct {
+	fallback *template.Template
+	byLocale map[string]*template.Template
 }
 
 // NewRenderer создаёт новый renderer и загружает шаблоны
 func NewRenderer(logger *zap.Logger, templatesDir string) (*Renderer, error) {
-	paymentTemplate, err := template.ParseFiles(templatesDir + "/payment_completed.tmpl") //paymentTemplate для загрузки шаблона для события оплаты заказа
+	paymentTemplate, err := parseLocalized(templatesDir, "payment_completed") //paymentTemplate для загрузки шаблона для события оплаты заказа
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse payment template: %w", err)
 	}
 
-	assemblyTemplate, err := template.ParseFiles(templatesDir + "/assembly_completed.tmpl")
+	assemblyTemplate, err := parseLocalized(templatesDir, "assembly_completed")
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse assembly template: %w", err)
 	}
 
+	orderTemplate, err := parseLocalized(templatesDir, "order_completed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order completed template: %w", err)
+	}
+
 	return &Renderer{
 		logger:           logger,
 		paymentTemplate:  paymentTemplate,
 		assemblyTemplate: assemblyTemplate,
+		orderTemplate:    orderTemplate,
 	}, nil
 }
 
-// RenderPaymentCompleted рендерит шаблон для события оплаты заказа
-func (r *Renderer) RenderPaymentCompleted(data interface{}) (string, error) {
+// parseLocalized загружает <name>.tmpl и все <name>.<locale>.tmpl из templatesDir
+func parseLocalized(templatesDir, name string) (localizedTemplate, error) {
+	fallback, err := template.ParseFiles(filepath.Join(templatesDir, name+".tmpl"))
+	if err != nil {
+		return localizedTemplate{}, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(templatesDir, name+".*.tmpl"))
+	if err != nil {
+		return localizedTemplate{}, err
+	}
+	byLocale := make(map[string]*template.Template, len(paths))
+	for _, path := range paths {
+		locale := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), name+"."), ".tmpl")
+		tmpl, err := template.ParseFiles(path)
+		if err != nil {
+			return localizedTemplate{}, err
+		}
+		byLocale[strings.ToLower(locale)] = tmpl
+	}
+
+	return localizedTemplate{fallback: fallback, byLocale: byLocale}, nil
+}
+
+// forLocale выбирает шаблон для тега BCP-47: точное совпадение, затем язык без региона, иначе шаблон по умолчанию
+func (t localizedTemplate) forLocale(locale string) *template.Template {
+	locale = strings.ToLower(locale)
+	if tmpl, ok := t.byLocale[locale]; ok {
+		return tmpl
+	}
+	if lang, _, found := strings.Cut(locale, "-"); found {
+		if tmpl, ok := t.byLocale[lang]; ok {
+			return tmpl
+		}
+	}
+	return t.fallback
+}
+
+// RenderPaymentCompleted рендерит шаблон для события оплаты заказа на языке locale (пусто - шаблон по умолчанию)
+func (r *Renderer) RenderPaymentCompleted(locale string, data interface{}) (string, error) {
 	var buf bytes.Buffer
 	//Возьми шаблон, подставь в него данные и выведи результат куда скажу
-	if err := r.paymentTemplate.Execute(&buf, data); err != nil {
+	if err := r.paymentTemplate.forLocale(locale).Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("failed to render payment template: %w", err)
 	}
 	return buf.String(), nil
 }
 
-// RenderAssemblyCompleted рендерит шаблон для события завершения сборки заказа
-func (r *Renderer) RenderAssemblyCompleted(data interface{}) (string, error) {
+// RenderAssemblyCompleted рендерит шаблон для события завершения сборки заказа на языке locale (пусто - шаблон по умолчанию)
+func (r *Renderer) RenderAssemblyCompleted(locale string, data interface{}) (string, error) {
 	var buf bytes.Buffer
-	if err := r.assemblyTemplate.Execute(&buf, data); err != nil {
+	if err := r.assemblyTemplate.forLocale(locale).Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("failed to render assembly template: %w", err)
 	}
 	return buf.String(), nil
 }
+
+// RenderOrderCompleted рендерит объединённое уведомление об оплате и сборке заказа на языке locale (пусто - шаблон по умолчанию)
+func (r *Renderer) RenderOrderCompleted(locale string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := r.orderTemplate.forLocale(locale).Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render order completed template: %w", err)
+	}
+	return buf.String(), nil
+}
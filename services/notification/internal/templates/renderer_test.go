@@ -0,0 +1,164 @@
+package templates_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/notification/internal/service"
+	"github.com/shestoi/GoBigTech/services/notification/internal/templates"
+)
+
+func TestRenderer_RenderAssemblyCompleted(t *testing.T) {
+	renderer, err := templates.NewRenderer(zap.NewNop(), "../../templates")
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("enriched event renders items, amount and payment method", func(t *testing.T) {
+		text, err := renderer.RenderAssemblyCompleted("", service.OrderAssemblyCompletedEvent{
+			OrderID:       "order-1",
+			UserID:        "user-1",
+			OccurredAt:    occurredAt,
+			Amount:        30000,
+			Currency:      "RUB",
+			PaymentMethod: "card",
+			Items: []service.OrderItem{
+				{ProductID: "product-1", Quantity: 2, Status: "assembled"},
+				{ProductID: "product-2", Quantity: 1, Status: "cancelled"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, want := range []string{
+			"Заказ: order-1",
+			"product-1 × 2\n",
+			"product-2 × 1 — отменён",
+			"Сумма: 30000 (в минимальных единицах RUB)",
+			"Метод оплаты: card",
+			"2026-01-02 03:04:05 UTC",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected rendered text to contain %q, got:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("legacy event without enrichment omits optional sections", func(t *testing.T) {
+		text, err := renderer.RenderAssemblyCompleted("", service.OrderAssemblyCompletedEvent{
+			OrderID:    "order-2",
+			UserID:     "user-2",
+			OccurredAt: occurredAt,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, unwanted := range []string{"Состав заказа", "Сумма", "Метод оплаты"} {
+			if strings.Contains(text, unwanted) {
+				t.Errorf("expected rendered text not to contain %q, got:\n%s", unwanted, text)
+			}
+		}
+	})
+}
+
+func TestRenderer_RenderOrderCompleted(t *testing.T) {
+	renderer, err := templates.NewRenderer(zap.NewNop(), "../../templates")
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+
+	text, err := renderer.RenderOrderCompleted("", service.OrderCompletedNotification{
+		OrderID: "order-1",
+		UserID:  "user-1",
+		Paid: service.OrderPaidEvent{
+			Amount:        30000,
+			Currency:      "RUB",
+			PaymentMethod: "card",
+			OccurredAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Assembled: service.OrderAssemblyCompletedEvent{
+			Items:      []service.OrderItem{{ProductID: "product-1", Quantity: 2, Status: "assembled"}},
+			OccurredAt: time.Date(2026, 1, 2, 3, 4, 9, 0, time.UTC),
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		"Заказ оплачен и собран",
+		"Заказ: order-1",
+		"Сумма: 30000 (в минимальных единицах RUB)",
+		"Метод оплаты: card",
+		"product-1 × 2\n",
+		"Оплачен: 2026-01-02 03:04:05 UTC",
+		"Собран: 2026-01-02 03:04:09 UTC",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected rendered text to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestRenderer_Localization(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"payment_completed.tmpl":     "Заказ оплачен: {{.OrderID}}",
+		"payment_completed.en.tmpl":  "Order paid: {{.OrderID}}",
+		"assembly_completed.tmpl":    "Заказ собран: {{.OrderID}}, {{.OccurredAt.Format \"15:04 MST\"}}",
+		"assembly_completed.de.tmpl": "Bestellung montiert: {{.OrderID}}",
+		"order_completed.tmpl":       "Заказ оплачен и собран: {{.OrderID}}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+
+	renderer, err := templates.NewRenderer(zap.NewNop(), dir)
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+
+	for _, tt := range []struct {
+		locale string
+		want   string
+	}{
+		{locale: "", want: "Заказ оплачен: order-1"},
+		{locale: "en", want: "Order paid: order-1"},
+		{locale: "en-GB", want: "Order paid: order-1"}, // по языку без региона
+		{locale: "fr", want: "Заказ оплачен: order-1"}, // нет перевода - шаблон по умолчанию
+	} {
+		text, err := renderer.RenderPaymentCompleted(tt.locale, service.OrderPaidEvent{OrderID: "order-1"})
+		if err != nil {
+			t.Fatalf("locale %q: expected no error, got %v", tt.locale, err)
+		}
+		if text != tt.want {
+			t.Errorf("locale %q: expected %q, got %q", tt.locale, tt.want, text)
+		}
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	text, err := renderer.RenderAssemblyCompleted("ru", service.OrderAssemblyCompletedEvent{
+		OrderID:    "order-2",
+		OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).In(moscow),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := "Заказ собран: order-2, 06:04 MSK"; text != want {
+		t.Errorf("expected %q, got %q", want, text)
+	}
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Очередь отправки уведомлений в Telegram (NOTIFICATION_OUTBOX_ENABLED): consumer сохраняет готовый текст
+-- в одной транзакции с переводом inbox в sent, отправляет delivery worker
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    event_id TEXT PRIMARY KEY, -- событие, вызвавшее уведомление (для объединённого - событие сборки)
+    event_type TEXT NOT NULL,
+    order_id TEXT NOT NULL,
+    chat_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, sent, failed
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox(next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_outbox_due;
+DROP TABLE IF EXISTS notification_outbox;
+-- +goose StatementEnd
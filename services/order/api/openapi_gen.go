@@ -11,12 +11,67 @@ import (
 	"github.com/oapi-codegen/runtime"
 )
 
-// Order defines model for Order.
+// Defines values for OrderItemStatus.
+const (
+	OrderItemStatusAssembled OrderItemStatus = "assembled"
+	OrderItemStatusCancelled OrderItemStatus = "cancelled"
+	OrderItemStatusReserved  OrderItemStatus = "reserved"
+	OrderItemStatusShipped   OrderItemStatus = "shipped"
+)
+
+// Defines values for PaymentDeclineReason.
+const (
+	PaymentDeclineReasonInsufficientFunds PaymentDeclineReason = "insufficient_funds"
+	PaymentDeclineReasonLimitExceeded     PaymentDeclineReason = "limit_exceeded"
+	PaymentDeclineReasonProviderError     PaymentDeclineReason = "provider_error"
+	PaymentDeclineReasonRiskDeclined      PaymentDeclineReason = "risk_declined"
+	PaymentDeclineReasonUnknown           PaymentDeclineReason = "unknown"
+)
+
+// ArchiveResult defines model for ArchiveResult.
+type ArchiveResult struct {
+	Archived int64 `json:"archived"`
+}
+
+// ConsumerList defines model for ConsumerList.
+type ConsumerList struct {
+	Consumers []ConsumerState `json:"consumers"`
+}
+
+// ConsumerState defines model for ConsumerState.
+type ConsumerState struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+}
+
+// DependencyUnavailable defines model for DependencyUnavailable.
+type DependencyUnavailable struct {
+	// Dependency Downstream service that is unavailable (inventory or payment).
+	Dependency string `json:"dependency"`
+	Message    string `json:"message"`
+
+	// RetryAfterSeconds Same as the Retry-After header.
+	RetryAfterSeconds int `json:"retry_after_seconds"`
+}
+
+// Order Order representation, response schema v1.
+// Optional fields are omitted when unknown instead of being returned as null.
 type Order struct {
-	Id     *string      `json:"id,omitempty"`
-	Items  *[]OrderItem `json:"items,omitempty"`
-	Status *string      `json:"status,omitempty"`
-	UserId *string      `json:"user_id,omitempty"`
+	// ArchivedAt Unix timestamp of archiving. Present only for archived orders.
+	ArchivedAt *int64 `json:"archived_at,omitempty"`
+
+	// Currency ISO 4217 currency code. Omitted when unknown.
+	Currency *string     `json:"currency,omitempty"`
+	Id       string      `json:"id"`
+	Items    []OrderLine `json:"items"`
+
+	// ItemsError Why items could not be read. Present only with tolerate_item_errors=true, items is empty then.
+	ItemsError *string `json:"items_error,omitempty"`
+	Status     string  `json:"status"`
+
+	// TotalAmount Order total in minor currency units (e.g. kopecks, cents). Omitted when unknown.
+	TotalAmount *int64 `json:"total_amount,omitempty"`
+	UserId      string `json:"user_id"`
 }
 
 // OrderItem defines model for OrderItem.
@@ -25,29 +80,282 @@ type OrderItem struct {
 	Quantity  int    `json:"quantity"`
 }
 
+// OrderItemStatus Fulfillment status of an order item:
+// reserved - stock is reserved, waiting for assembly;
+// assembled - the item is assembled;
+// shipped - the item is shipped;
+// cancelled - the item is cancelled (e.g. not found during assembly).
+type OrderItemStatus string
+
+// OrderLine Order item with its own fulfillment status (items are assembled/shipped independently).
+type OrderLine struct {
+	// Currency ISO 4217 currency code of unit_price. Omitted when unknown.
+	Currency  *string `json:"currency,omitempty"`
+	ProductId string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+
+	// Status Fulfillment status of an order item:
+	// reserved - stock is reserved, waiting for assembly;
+	// assembled - the item is assembled;
+	// shipped - the item is shipped;
+	// cancelled - the item is cancelled (e.g. not found during assembly).
+	Status OrderItemStatus `json:"status"`
+
+	// UnitPrice Unit price at purchase time in minor currency units. Later catalog price changes do not affect it. Omitted when unknown.
+	UnitPrice *int64 `json:"unit_price,omitempty"`
+}
+
+// OrderList defines model for OrderList.
+type OrderList struct {
+	Orders []Order `json:"orders"`
+}
+
 // OrderRequest defines model for OrderRequest.
 type OrderRequest struct {
-	Items  []OrderItem `json:"items"`
-	UserId string      `json:"user_id"`
+	// Currency ISO 4217 currency code (RUB, USD, EUR). Defaults to RUB.
+	Currency *string     `json:"currency,omitempty"`
+	Items    []OrderItem `json:"items"`
+	UserId   string      `json:"user_id"`
+}
+
+// OrderStats defines model for OrderStats.
+type OrderStats struct {
+	UserId  string             `json:"user_id"`
+	Windows []OrderStatsWindow `json:"windows"`
+}
+
+// OrderStatsWindow defines model for OrderStatsWindow.
+type OrderStatsWindow struct {
+	// ByStatus Sorted by status, then currency. Statuses without orders are omitted.
+	ByStatus []OrderStatusStats `json:"by_status"`
+
+	// OrdersCount Orders created in the window, all statuses.
+	OrdersCount int64 `json:"orders_count"`
+
+	// Since Unix timestamp of the window start. Omitted for all.
+	Since *int64 `json:"since,omitempty"`
+
+	// Window Window as requested (30d, 72h, all).
+	Window string `json:"window"`
 }
 
+// OrderStatusStats defines model for OrderStatusStats.
+type OrderStatusStats struct {
+	Count int64 `json:"count"`
+
+	// Currency ISO 4217 currency code of total_amount.
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+
+	// TotalAmount Sum of order totals in minor currency units.
+	TotalAmount int64 `json:"total_amount"`
+}
+
+// PaymentDeclineReason Why the payment was declined:
+// insufficient_funds - not enough money, use another card or top up the balance;
+// limit_exceeded - the amount exceeds the payment limit;
+// risk_declined - declined by risk checks, contact support;
+// provider_error - payment provider is unavailable, retry later;
+// unknown - the payment service did not report a reason.
+type PaymentDeclineReason string
+
+// PaymentDeclined defines model for PaymentDeclined.
+type PaymentDeclined struct {
+	// Message What the user can do about the decline.
+	Message string `json:"message"`
+
+	// OrderId ID of the saved order with status payment_declined. Omitted if the order could not be saved.
+	OrderId *string `json:"order_id,omitempty"`
+
+	// Reason Why the payment was declined:
+	// insufficient_funds - not enough money, use another card or top up the balance;
+	// limit_exceeded - the amount exceeds the payment limit;
+	// risk_declined - declined by risk checks, contact support;
+	// provider_error - payment provider is unavailable, retry later;
+	// unknown - the payment service did not report a reason.
+	Reason PaymentDeclineReason `json:"reason"`
+}
+
+// StatusChangeRequest defines model for StatusChangeRequest.
+type StatusChangeRequest struct {
+	// Force Skip the transition check (any known status except the current one).
+	Force *bool `json:"force,omitempty"`
+
+	// Reason Why the status is changed (incident, ticket). Stored in the audit record and the event.
+	Reason string `json:"reason"`
+
+	// Status Target status (paid, payment_declined, assembled).
+	Status string `json:"status"`
+}
+
+// StatusChangeResult defines model for StatusChangeResult.
+type StatusChangeResult struct {
+	// EventId event_id of order.status.changed.
+	EventId string `json:"event_id"`
+
+	// Forced The transition is not allowed without force.
+	Forced     bool   `json:"forced"`
+	FromStatus string `json:"from_status"`
+	OrderId    string `json:"order_id"`
+	Status     string `json:"status"`
+}
+
+// Webhook defines model for Webhook.
+type Webhook struct {
+	// CreatedAt Unix timestamp of registration.
+	CreatedAt int64  `json:"created_at"`
+	Id        string `json:"id"`
+
+	// Secret HMAC-SHA256 signing secret. Returned only when the webhook is registered.
+	Secret *string `json:"secret,omitempty"`
+	Url    string  `json:"url"`
+	UserId string  `json:"user_id"`
+}
+
+// WebhookList defines model for WebhookList.
+type WebhookList struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookRequest defines model for WebhookRequest.
+type WebhookRequest struct {
+	// Url Absolute http(s) URL that receives POST callbacks.
+	Url string `json:"url"`
+}
+
+// ConsumerName defines model for ConsumerName.
+type ConsumerName = string
+
+// IncludeArchived defines model for IncludeArchived.
+type IncludeArchived = bool
+
+// PostAdminOrdersArchiveParams defines parameters for PostAdminOrdersArchive.
+type PostAdminOrdersArchiveParams struct {
+	// OlderThan Go duration, e.g. 720h. Completed orders in a terminal status (assembled or payment_declined) created earlier than now - older_than are archived.
+	OlderThan string `form:"older_than" json:"older_than"`
+}
+
+// GetOrdersParams defines parameters for GetOrders.
+type GetOrdersParams struct {
+	// UserId Orders of this user.
+	UserId *string `form:"user_id,omitempty" json:"user_id,omitempty"`
+
+	// ProductId Only orders containing this product (e.g. for a product recall). Without user_id searches orders of all users and requires a valid X-Admin-Token header.
+	ProductId *string `form:"product_id,omitempty" json:"product_id,omitempty"`
+
+	// Limit Maximum number of orders (default 50, capped at 100).
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// IncludeArchived Include archived orders. Requires a valid X-Admin-Token header.
+	IncludeArchived *IncludeArchived `form:"include_archived,omitempty" json:"include_archived,omitempty"`
+}
+
+// GetOrdersIdParams defines parameters for GetOrdersId.
+type GetOrdersIdParams struct {
+	// IncludeArchived Include archived orders. Requires a valid X-Admin-Token header.
+	IncludeArchived *IncludeArchived `form:"include_archived,omitempty" json:"include_archived,omitempty"`
+
+	// TolerateItemErrors Return the order without items (with items_error) if its items cannot be read, instead of failing the request.
+	// For support tools; requires a valid X-Admin-Token header.
+	TolerateItemErrors *bool `form:"tolerate_item_errors,omitempty" json:"tolerate_item_errors,omitempty"`
+}
+
+// GetUsersIdOrderStatsParams defines parameters for GetUsersIdOrderStats.
+type GetUsersIdOrderStatsParams struct {
+	// Windows Comma-separated windows counted back from now: Go durations (72h) or days (30d); all - all time.
+	// At most 10. Defaults to ORDER_STATS_WINDOWS.
+	Windows *[]string `form:"windows,omitempty" json:"windows,omitempty"`
+}
+
+// PostAdminOrdersIdStatusJSONRequestBody defines body for PostAdminOrdersIdStatus for application/json ContentType.
+type PostAdminOrdersIdStatusJSONRequestBody = StatusChangeRequest
+
 // PostOrdersJSONRequestBody defines body for PostOrders for application/json ContentType.
 type PostOrdersJSONRequestBody = OrderRequest
 
+// PostWebhooksJSONRequestBody defines body for PostWebhooks for application/json ContentType.
+type PostWebhooksJSONRequestBody = WebhookRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// List background workers (Kafka consumer, outbox dispatcher) and their pause state
+	// (GET /admin/consumers)
+	GetAdminConsumers(w http.ResponseWriter, r *http.Request)
+	// Pause a background worker without restarting the process
+	// (POST /admin/consumers/{name}/pause)
+	PostAdminConsumersNamePause(w http.ResponseWriter, r *http.Request, name ConsumerName)
+	// Resume a paused background worker
+	// (POST /admin/consumers/{name}/resume)
+	PostAdminConsumersNameResume(w http.ResponseWriter, r *http.Request, name ConsumerName)
+	// Archive (soft delete) completed orders older than the given age
+	// (POST /admin/orders/archive)
+	PostAdminOrdersArchive(w http.ResponseWriter, r *http.Request, params PostAdminOrdersArchiveParams)
+	// Change order status by an operator (audited, emits order.status.changed)
+	// (POST /admin/orders/{id}/status)
+	PostAdminOrdersIdStatus(w http.ResponseWriter, r *http.Request, id string)
+	// List orders of a user and/or orders containing a product (newest first)
+	// (GET /orders)
+	GetOrders(w http.ResponseWriter, r *http.Request, params GetOrdersParams)
 	// Create a new order
 	// (POST /orders)
 	PostOrders(w http.ResponseWriter, r *http.Request)
 	// Get order by ID
 	// (GET /orders/{id})
-	GetOrdersId(w http.ResponseWriter, r *http.Request, id string)
+	GetOrdersId(w http.ResponseWriter, r *http.Request, id string, params GetOrdersIdParams)
+	// Order counts and totals of a user by status over time windows
+	// (GET /users/{id}/order-stats)
+	GetUsersIdOrderStats(w http.ResponseWriter, r *http.Request, id string, params GetUsersIdOrderStatsParams)
+	// List order status webhooks of the session user
+	// (GET /webhooks)
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+	// Register a webhook URL for order status changes (paid, payment_declined, assembled)
+	// (POST /webhooks)
+	PostWebhooks(w http.ResponseWriter, r *http.Request)
+	// Delete a webhook of the session user; pending callbacks to it are dropped
+	// (DELETE /webhooks/{id})
+	DeleteWebhooksId(w http.ResponseWriter, r *http.Request, id string)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 
 type Unimplemented struct{}
 
+// List background workers (Kafka consumer, outbox dispatcher) and their pause state
+// (GET /admin/consumers)
+func (_ Unimplemented) GetAdminConsumers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Pause a background worker without restarting the process
+// (POST /admin/consumers/{name}/pause)
+func (_ Unimplemented) PostAdminConsumersNamePause(w http.ResponseWriter, r *http.Request, name ConsumerName) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Resume a paused background worker
+// (POST /admin/consumers/{name}/resume)
+func (_ Unimplemented) PostAdminConsumersNameResume(w http.ResponseWriter, r *http.Request, name ConsumerName) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Archive (soft delete) completed orders older than the given age
+// (POST /admin/orders/archive)
+func (_ Unimplemented) PostAdminOrdersArchive(w http.ResponseWriter, r *http.Request, params PostAdminOrdersArchiveParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Change order status by an operator (audited, emits order.status.changed)
+// (POST /admin/orders/{id}/status)
+func (_ Unimplemented) PostAdminOrdersIdStatus(w http.ResponseWriter, r *http.Request, id string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List orders of a user and/or orders containing a product (newest first)
+// (GET /orders)
+func (_ Unimplemented) GetOrders(w http.ResponseWriter, r *http.Request, params GetOrdersParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Create a new order
 // (POST /orders)
 func (_ Unimplemented) PostOrders(w http.ResponseWriter, r *http.Request) {
@@ -56,7 +364,31 @@ func (_ Unimplemented) PostOrders(w http.ResponseWriter, r *http.Request) {
 
 // Get order by ID
 // (GET /orders/{id})
-func (_ Unimplemented) GetOrdersId(w http.ResponseWriter, r *http.Request, id string) {
+func (_ Unimplemented) GetOrdersId(w http.ResponseWriter, r *http.Request, id string, params GetOrdersIdParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Order counts and totals of a user by status over time windows
+// (GET /users/{id}/order-stats)
+func (_ Unimplemented) GetUsersIdOrderStats(w http.ResponseWriter, r *http.Request, id string, params GetUsersIdOrderStatsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List order status webhooks of the session user
+// (GET /webhooks)
+func (_ Unimplemented) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Register a webhook URL for order status changes (paid, payment_declined, assembled)
+// (POST /webhooks)
+func (_ Unimplemented) PostWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Delete a webhook of the session user; pending callbacks to it are dropped
+// (DELETE /webhooks/{id})
+func (_ Unimplemented) DeleteWebhooksId(w http.ResponseWriter, r *http.Request, id string) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -69,6 +401,186 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// GetAdminConsumers operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminConsumers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAdminConsumers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostAdminConsumersNamePause operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminConsumersNamePause(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name ConsumerName
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", chi.URLParam(r, "name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminConsumersNamePause(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostAdminConsumersNameResume operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminConsumersNameResume(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name ConsumerName
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", chi.URLParam(r, "name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminConsumersNameResume(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostAdminOrdersArchive operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminOrdersArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostAdminOrdersArchiveParams
+
+	// ------------- Required query parameter "older_than" -------------
+
+	if paramValue := r.URL.Query().Get("older_than"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "older_than"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "older_than", r.URL.Query(), &params.OlderThan)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "older_than", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminOrdersArchive(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostAdminOrdersIdStatus operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminOrdersIdStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminOrdersIdStatus(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetOrders operation middleware
+func (siw *ServerInterfaceWrapper) GetOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOrdersParams
+
+	// ------------- Optional query parameter "user_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "product_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "product_id", r.URL.Query(), &params.ProductId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "product_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_archived" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_archived", r.URL.Query(), &params.IncludeArchived)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_archived", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOrders(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // PostOrders operation middleware
 func (siw *ServerInterfaceWrapper) PostOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -99,8 +611,120 @@ func (siw *ServerInterfaceWrapper) GetOrdersId(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOrdersIdParams
+
+	// ------------- Optional query parameter "include_archived" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_archived", r.URL.Query(), &params.IncludeArchived)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_archived", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "tolerate_item_errors" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tolerate_item_errors", r.URL.Query(), &params.TolerateItemErrors)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tolerate_item_errors", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOrdersId(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetUsersIdOrderStats operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersIdOrderStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsersIdOrderStatsParams
+
+	// ------------- Optional query parameter "windows" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "windows", r.URL.Query(), &params.Windows)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "windows", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersIdOrderStats(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetOrdersId(w, r, id)
+		siw.Handler.GetWebhooks(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) PostWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostWebhooks(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteWebhooksId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteWebhooksId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteWebhooksId(w, r, id)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -223,12 +847,42 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/consumers", wrapper.GetAdminConsumers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/consumers/{name}/pause", wrapper.PostAdminConsumersNamePause)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/consumers/{name}/resume", wrapper.PostAdminConsumersNameResume)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/orders/archive", wrapper.PostAdminOrdersArchive)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/orders/{id}/status", wrapper.PostAdminOrdersIdStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/orders", wrapper.GetOrders)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/orders", wrapper.PostOrders)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/orders/{id}", wrapper.GetOrdersId)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/{id}/order-stats", wrapper.GetUsersIdOrderStats)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/webhooks", wrapper.GetWebhooks)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/webhooks", wrapper.PostWebhooks)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/webhooks/{id}", wrapper.DeleteWebhooksId)
+	})
 
 	return r
 }
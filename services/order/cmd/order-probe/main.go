@@ -0,0 +1,108 @@
+// Package main содержит synthetic probe Order Service.
+//
+// Probe периодически проходит happy path как внешний клиент (логин в IAM, создание заказа
+// на тестовый товар, ожидание статуса assembled, проверка истории уведомлений) и экспортирует
+// метрики успеха и длительности через OTLP. Используется как black-box монитор SLO.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
+	grpcclient "github.com/shestoi/GoBigTech/services/order/internal/client/grpc"
+	"github.com/shestoi/GoBigTech/services/order/internal/config"
+	"github.com/shestoi/GoBigTech/services/order/internal/probe"
+)
+
+func main() {
+	cfg, err := config.LoadProbe()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Log()
+
+	logger, err := platformlogging.New(platformlogging.Config{
+		ServiceName: "order-probe",
+		Env:         string(cfg.AppEnv),
+		Level:       os.Getenv("LOG_LEVEL"),
+		Format:      os.Getenv("LOG_FORMAT"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer platformlogging.Sync(logger)
+
+	// Контекст отменяется по SIGINT/SIGTERM: текущий прогон прерывается и probe завершается
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	otelShutdown, err := platformobservability.Init(ctx, platformobservability.Config{
+		Enabled:               cfg.OTelEnabled,
+		OTLPEndpoint:          cfg.OTelEndpoint,
+		SamplingRatio:         1.0,
+		ServiceName:           "order-probe",
+		DeploymentEnvironment: string(cfg.AppEnv),
+	})
+	if err != nil {
+		logger.Fatal("failed to init observability", zap.Error(err))
+	}
+	defer func() {
+		if err := otelShutdown(context.Background()); err != nil {
+			logger.Error("failed to shutdown observability", zap.Error(err))
+		}
+	}()
+
+	iamConn, err := grpc.NewClient(cfg.IAMGRPCAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(platformobservability.GRPCUnaryClientInterceptor("order-probe")),
+	)
+	if err != nil {
+		logger.Fatal("failed to create IAM client", zap.Error(err))
+	}
+	defer iamConn.Close()
+	auth := grpcclient.NewIAMProbeAuthenticator(iampb.NewIAMServiceClient(iamConn), cfg.Login, cfg.Password)
+
+	// История уведомлений проверяется по базе Notification, если задан DSN
+	var notifications probe.NotificationChecker
+	if cfg.NotificationPostgresDSN != "" {
+		pool, err := pgxpool.New(ctx, cfg.NotificationPostgresDSN)
+		if err != nil {
+			logger.Fatal("failed to connect to notification postgres", zap.Error(err))
+		}
+		defer pool.Close()
+		notifications = probe.NewPostgresNotificationChecker(pool)
+	} else {
+		logger.Warn("PROBE_NOTIFICATION_POSTGRES_DSN not set, notification history will not be checked")
+	}
+
+	var metrics probe.MetricsRecorder
+	if cfg.OTelEnabled {
+		metrics = probe.NewOTelMetricsRecorder()
+	}
+
+	p := probe.New(logger, &http.Client{Timeout: 15 * time.Second}, auth, notifications, metrics, probe.Config{
+		OrderURL:        cfg.OrderURL,
+		ProductID:       cfg.ProductID,
+		Currency:        cfg.Currency,
+		AssemblyTimeout: cfg.AssemblyTimeout,
+		PollInterval:    cfg.PollInterval,
+	})
+	if err := p.Start(ctx, cfg.Interval); err != nil {
+		logger.Error("order probe error", zap.Error(err))
+	}
+
+	logger.Info("Order probe stopped")
+}
@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+)
+
+// PausableWorker - фоновый обработчик (Kafka consumer, outbox dispatcher), который можно приостановить без перезапуска процесса
+type PausableWorker interface {
+	Pause() bool
+	Resume() bool
+	Paused() bool
+}
+
+// RegisterWorker делает фоновый обработчик доступным для /admin/consumers под именем name
+func (h *Handler) RegisterWorker(name string, worker PausableWorker) {
+	if h.workers == nil {
+		h.workers = make(map[string]PausableWorker)
+	}
+	h.workers[name] = worker
+}
+
+// GetAdminConsumers обрабатывает GET /admin/consumers - состояние фоновых обработчиков
+// Доступ проверяется middleware (RequireAdmin)
+func (h *Handler) GetAdminConsumers(w http.ResponseWriter, r *http.Request) {
+	const op = "Handler.GetAdminConsumers"
+	logger := platformobservability.L(r.Context(), h.logger.With(zap.String("op", op)))
+
+	names := make([]string, 0, len(h.workers))
+	for name := range h.workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := orderapi.ConsumerList{Consumers: make([]orderapi.ConsumerState, 0, len(names))}
+	for _, name := range names {
+		result.Consumers = append(result.Consumers, orderapi.ConsumerState{Name: name, Paused: h.workers[name].Paused()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// PostAdminConsumersNamePause обрабатывает POST /admin/consumers/{name}/pause - приостановка обработчика
+// Доступ проверяется middleware (RequireAdmin)
+func (h *Handler) PostAdminConsumersNamePause(w http.ResponseWriter, r *http.Request, name orderapi.ConsumerName) {
+	h.setWorkerPaused(w, r, "Handler.PostAdminConsumersNamePause", name, true)
+}
+
+// PostAdminConsumersNameResume обрабатывает POST /admin/consumers/{name}/resume - возобновление обработчика
+// Доступ проверяется middleware (RequireAdmin)
+func (h *Handler) PostAdminConsumersNameResume(w http.ResponseWriter, r *http.Request, name orderapi.ConsumerName) {
+	h.setWorkerPaused(w, r, "Handler.PostAdminConsumersNameResume", name, false)
+}
+
+// setWorkerPaused приостанавливает или возобновляет обработчик; повторный вызов не является ошибкой
+func (h *Handler) setWorkerPaused(w http.ResponseWriter, r *http.Request, op, name string, paused bool) {
+	logger := platformobservability.L(r.Context(), h.logger.With(zap.String("op", op), zap.String("worker", name)))
+
+	worker, ok := h.workers[name]
+	if !ok {
+		logger.Warn("Unknown worker")
+		http.Error(w, "Unknown worker: "+name, http.StatusNotFound)
+		return
+	}
+
+	var changed bool
+	if paused {
+		changed = worker.Pause()
+	} else {
+		changed = worker.Resume()
+	}
+	if changed {
+		logger.Warn("Worker state changed by admin", zap.Bool("paused", paused))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(orderapi.ConsumerState{Name: name, Paused: worker.Paused()}); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/api/http/middleware"
+)
+
+// fakeWorker - PausableWorker для тестов
+type fakeWorker struct {
+	paused bool
+}
+
+func (w *fakeWorker) Pause() bool {
+	changed := !w.paused
+	w.paused = true
+	return changed
+}
+
+func (w *fakeWorker) Resume() bool {
+	changed := w.paused
+	w.paused = false
+	return changed
+}
+
+func (w *fakeWorker) Paused() bool { return w.paused }
+
+func TestAdminConsumers(t *testing.T) {
+	worker := &fakeWorker{}
+	handler := NewHandler(nil, nil, nil, zap.NewNop())
+	handler.RegisterWorker("assembly-consumer", worker)
+	router := NewRouter(handler, func() bool { return true }, nil, nil, testAdminToken, nil)
+
+	do := func(method, target string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, http.NoBody)
+		if admin {
+			req.Header.Set(middleware.AdminTokenHeader, testAdminToken)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/admin/consumers/assembly-consumer/pause", false)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.False(t, worker.paused)
+
+	rec = do(http.MethodPost, "/admin/consumers/unknown/pause", true)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	for i := 0; i < 2; i++ {
+		rec = do(http.MethodPost, "/admin/consumers/assembly-consumer/pause", true)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.JSONEq(t, `{"name":"assembly-consumer","paused":true}`, rec.Body.String())
+	}
+	require.True(t, worker.paused)
+
+	rec = do(http.MethodGet, "/admin/consumers", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"consumers":[{"name":"assembly-consumer","paused":true}]}`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/admin/consumers/assembly-consumer/resume", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"name":"assembly-consumer","paused":false}`, rec.Body.String())
+	require.False(t, worker.paused)
+}
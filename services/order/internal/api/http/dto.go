@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+	"github.com/shestoi/GoBigTech/services/order/internal/api/http/middleware"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+)
+
+// APIVersionHeader - заголовок, в котором сервер сообщает версию схемы ответа
+const APIVersionHeader = "X-API-Version"
+
+// OrderResponseVersionV1 - текущая версия схемы ответов Order API.
+// Несовместимые изменения (переименование/удаление полей) требуют новой версии схемы в openapi.yaml.
+const OrderResponseVersionV1 = "v1"
+
+// Запросы и ответы описаны в services/order/api/openapi.yaml, Go-типы генерируются oapi-codegen (пакет orderapi).
+// Здесь только преобразование результатов service слоя в сгенерированные типы.
+//
+// Схема Order (v1):
+//   - id, user_id, status - всегда присутствуют
+//   - items - всегда присутствует (пустой массив, если позиций нет), у каждой позиции свой status
+//   - items[].unit_price, items[].currency - цена единицы на момент покупки; опускаются для позиций без снимка цены
+//   - total_amount, currency, archived_at - опускаются, если неизвестны (вместо null)
+
+// newOrderResponse собирает orderapi.Order из доменных полей результата service слоя
+func newOrderResponse(id, userID string, status domain.Status, items []domain.Item, total domain.Money) orderapi.Order {
+	httpItems := make([]orderapi.OrderLine, 0, len(items))
+	for _, item := range items {
+		itemStatus := item.Status
+		if itemStatus == "" {
+			itemStatus = domain.ItemStatusReserved
+		}
+		httpItems = append(httpItems, orderapi.OrderLine{
+			ProductId: item.ProductID,
+			Quantity:  int(item.Quantity),
+			Status:    orderapi.OrderItemStatus(itemStatus),
+			UnitPrice: optional(item.UnitPrice.Amount),
+			Currency:  optional(item.UnitPrice.Currency),
+		})
+	}
+
+	return orderapi.Order{
+		Id:          id,
+		UserId:      userID,
+		Status:      string(status),
+		Items:       httpItems,
+		TotalAmount: optional(total.Amount),
+		Currency:    optional(total.Currency),
+	}
+}
+
+// newOrderResponseFromOutput собирает orderapi.Order из результата GetOrder/ListOrders
+func newOrderResponseFromOutput(out service.GetOrderOutput) orderapi.Order {
+	resp := newOrderResponse(out.OrderID, out.UserID, out.Status, out.Items, out.Total)
+	if !out.ArchivedAt.IsZero() {
+		resp.ArchivedAt = optional(out.ArchivedAt.Unix())
+	}
+	resp.ItemsError = optional(out.ItemsError)
+	return resp
+}
+
+// declineMessages - подсказки пользователю по причине отказа в оплате
+var declineMessages = map[orderapi.PaymentDeclineReason]string{
+	orderapi.PaymentDeclineReasonInsufficientFunds: "Insufficient funds: use another card or top up the balance",
+	orderapi.PaymentDeclineReasonLimitExceeded:     "Payment limit exceeded: reduce the order amount or contact your bank",
+	orderapi.PaymentDeclineReasonRiskDeclined:      "Payment declined by risk checks: contact support",
+	orderapi.PaymentDeclineReasonProviderError:     "Payment provider is temporarily unavailable: retry later",
+	orderapi.PaymentDeclineReasonUnknown:           "Payment declined: use another payment method",
+}
+
+// newPaymentDeclinedResponse собирает orderapi.PaymentDeclined; неизвестная причина отдаётся как unknown
+func newPaymentDeclinedResponse(declined *service.PaymentDeclinedError) orderapi.PaymentDeclined {
+	reason := orderapi.PaymentDeclineReason(declined.Reason)
+	if _, ok := declineMessages[reason]; !ok {
+		reason = orderapi.PaymentDeclineReasonUnknown
+	}
+	return orderapi.PaymentDeclined{
+		Reason:  reason,
+		Message: declineMessages[reason],
+		OrderId: optional(declined.OrderID),
+	}
+}
+
+// newDependencyUnavailableResponse собирает orderapi.DependencyUnavailable
+func newDependencyUnavailableResponse(unavailable *service.DependencyUnavailableError) orderapi.DependencyUnavailable {
+	return orderapi.DependencyUnavailable{
+		Dependency:        unavailable.Dependency,
+		Message:           fmt.Sprintf("%s service is temporarily unavailable, retry later", unavailable.Dependency),
+		RetryAfterSeconds: middleware.RetryAfterSeconds(unavailable.RetryAfter),
+	}
+}
+
+// optional возвращает nil для нулевого значения, чтобы поле было опущено в JSON (omitempty)
+func optional[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
+
+// setOrderResponseHeaders выставляет заголовки JSON ответа с версией схемы v1
+func setOrderResponseHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(APIVersionHeader, OrderResponseVersionV1)
+}
@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+)
+
+func TestOrderResponse_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     orderapi.Order
+		expected string
+	}{
+		{
+			name: "all fields present",
+			resp: newOrderResponse("order-1", "user-1", "paid",
+				[]domain.Item{{ProductID: "product-1", Quantity: 2}}, domain.NewMoney(20000, "RUB")),
+			expected: `{"id":"order-1","user_id":"user-1","status":"paid","items":[{"product_id":"product-1","quantity":2,"status":"reserved"}],"total_amount":20000,"currency":"RUB"}`,
+		},
+		{
+			name: "partially assembled order exposes item statuses",
+			resp: newOrderResponse("order-4", "user-4", "assembled",
+				[]domain.Item{
+					{ProductID: "product-1", Quantity: 1, Status: domain.ItemStatusAssembled},
+					{ProductID: "product-2", Quantity: 3, Status: domain.ItemStatusCancelled},
+				}, domain.NewMoney(10000, "RUB")),
+			expected: `{"id":"order-4","user_id":"user-4","status":"assembled","items":[{"product_id":"product-1","quantity":1,"status":"assembled"},{"product_id":"product-2","quantity":3,"status":"cancelled"}],"total_amount":10000,"currency":"RUB"}`,
+		},
+		{
+			name: "items expose unit price snapshot",
+			resp: newOrderResponse("order-5", "user-5", "paid",
+				[]domain.Item{{ProductID: "product-1", Quantity: 2, UnitPrice: domain.NewMoney(10000, "RUB")}}, domain.NewMoney(20000, "RUB")),
+			expected: `{"id":"order-5","user_id":"user-5","status":"paid","items":[{"product_id":"product-1","quantity":2,"status":"reserved","unit_price":10000,"currency":"RUB"}],"total_amount":20000,"currency":"RUB"}`,
+		},
+		{
+			name:     "unknown amount and currency are omitted, items never null",
+			resp:     newOrderResponse("order-2", "user-2", domain.StatusAssembled, nil, domain.Money{}),
+			expected: `{"id":"order-2","user_id":"user-2","status":"assembled","items":[]}`,
+		},
+		{
+			name: "archived order has archived_at",
+			resp: newOrderResponseFromOutput(service.GetOrderOutput{
+				OrderID: "order-3", UserID: "user-3", Status: domain.StatusAssembled, ArchivedAt: time.Unix(1700000000, 0),
+			}),
+			expected: `{"id":"order-3","user_id":"user-3","status":"assembled","items":[],"archived_at":1700000000}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.resp)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(data))
+			require.NotContains(t, string(data), "null")
+		})
+	}
+}
+
+func TestSetOrderResponseHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	setOrderResponseHeaders(rec)
+
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Equal(t, OrderResponseVersionV1, rec.Header().Get(APIVersionHeader))
+}
+
+func TestPaymentDeclinedResponse(t *testing.T) {
+	t.Run("known reason with saved order", func(t *testing.T) {
+		resp := newPaymentDeclinedResponse(&service.PaymentDeclinedError{
+			Reason:  service.DeclineReasonInsufficientFunds,
+			OrderID: "order-1",
+		})
+
+		require.Equal(t, orderapi.PaymentDeclineReasonInsufficientFunds, resp.Reason)
+		require.NotEmpty(t, resp.Message)
+		require.NotNil(t, resp.OrderId)
+		require.Equal(t, "order-1", *resp.OrderId)
+	})
+
+	t.Run("unknown reason and unsaved order", func(t *testing.T) {
+		data, err := json.Marshal(newPaymentDeclinedResponse(&service.PaymentDeclinedError{Reason: "card_expired"}))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"reason":"unknown","message":"Payment declined: use another payment method"}`, string(data))
+	})
+}
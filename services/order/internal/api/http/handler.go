@@ -2,51 +2,49 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+	"github.com/shestoi/GoBigTech/services/order/internal/api/http/middleware"
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
 )
 
+// providerRetryAfter - Retry-After при отказе provider_error: Payment ответил, состояния backoff у Order нет
+const providerRetryAfter = time.Second
+
 // Handler содержит HTTP-обработчики для Order Service
 // Зависит от service слоя, но не знает о деталях реализации (gRPC, БД и т.д.)
 type Handler struct {
-	orderService *service.OrderService
-	logger       *zap.Logger
+	orderService   *service.OrderService
+	webhookService *service.WebhookService
+	statsService   *service.OrderStatsService
+	logger         *zap.Logger
+	workers        map[string]PausableWorker // фоновые обработчики для /admin/consumers (RegisterWorker)
 }
 
+// Handler реализует сгенерированный из openapi.yaml интерфейс сервера
+var _ orderapi.ServerInterface = (*Handler)(nil)
+
 // NewHandler создаёт новый HTTP handler
-func NewHandler(orderService *service.OrderService, logger *zap.Logger) *Handler {
+func NewHandler(orderService *service.OrderService, webhookService *service.WebhookService, statsService *service.OrderStatsService, logger *zap.Logger) *Handler {
 	return &Handler{
-		orderService: orderService,
-		logger:       logger,
+		orderService:   orderService,
+		webhookService: webhookService,
+		statsService:   statsService,
+		logger:         logger,
 	}
 }
 
-// OrderItem представляет товар в HTTP запросе/ответе
-type OrderItem struct {
-	ProductID *string `json:"product_id"`
-	Quantity  *int    `json:"quantity"`
-}
-
-// OrderRequest представляет HTTP запрос на создание заказа
-type OrderRequest struct {
-	UserID *string      `json:"user_id"`
-	Items  *[]OrderItem `json:"items"`
-}
-
-// OrderResponse представляет HTTP ответ с информацией о заказе
-type OrderResponse struct {
-	ID     *string      `json:"id"`
-	UserID *string      `json:"user_id"`
-	Status *string      `json:"status"`
-	Items  *[]OrderItem `json:"items"`
-}
-
 // PostOrders обрабатывает POST /orders - создание нового заказа
 func (h *Handler) PostOrders(w http.ResponseWriter, r *http.Request) {
 	const op = "Handler.PostOrders"
@@ -57,79 +55,110 @@ func (h *Handler) PostOrders(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
 
 	// Декодируем JSON тело запроса
-	var reqBody OrderRequest
+	var reqBody orderapi.PostOrdersJSONRequestBody
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
 		logger.Warn("JSON decode error", zap.Error(err))
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Валидация входных данных
-	if reqBody.UserID == nil || reqBody.Items == nil || len(*reqBody.Items) == 0 {
+	// Валидация входных данных: отсутствующие в JSON обязательные поля декодируются в нулевые значения
+	if reqBody.UserId == "" || len(reqBody.Items) == 0 {
 		logger.Warn("Validation failed: missing required fields")
 		http.Error(w, "Invalid payload: user_id and items are required", http.StatusBadRequest)
 		return
 	}
 
 	// Валидация всех items: product_id не пустой, quantity > 0
-	for i, item := range *reqBody.Items {
-		if item.ProductID == nil || *item.ProductID == "" {
+	for i, item := range reqBody.Items {
+		if item.ProductId == "" {
 			logger.Warn("Validation failed: product_id is required", zap.Int("item_index", i))
 			http.Error(w, fmt.Sprintf("Invalid payload: product_id is required in items[%d]", i), http.StatusBadRequest)
 			return
 		}
-		if item.Quantity == nil || *item.Quantity <= 0 {
+		if item.Quantity <= 0 {
 			logger.Warn("Validation failed: quantity must be > 0", zap.Int("item_index", i))
 			http.Error(w, fmt.Sprintf("Invalid payload: quantity must be > 0 in items[%d]", i), http.StatusBadRequest)
 			return
 		}
 	}
 
-	userID := *reqBody.UserID
+	userID := reqBody.UserId
+
+	currency := ""
+	if reqBody.Currency != nil {
+		currency = *reqBody.Currency
+	}
 
 	// Преобразуем HTTP DTO в service DTO
-	serviceItems := make([]repository.OrderItem, 0, len(*reqBody.Items))
-	for _, item := range *reqBody.Items {
-		serviceItems = append(serviceItems, repository.OrderItem{
-			ProductID: *item.ProductID,
-			Quantity:  int32(*item.Quantity),
+	serviceItems := make([]domain.Line, 0, len(reqBody.Items))
+	for _, item := range reqBody.Items {
+		serviceItems = append(serviceItems, domain.Line{
+			ProductID: item.ProductId,
+			Quantity:  int32(item.Quantity),
 		})
 	}
 
 	// Вызываем service слой для создания заказа
 	// Вся бизнес-логика теперь в service, а не в обработчике
 	result, err := h.orderService.CreateOrder(ctx, service.CreateOrderInput{
-		UserID: userID,
-		Items:  serviceItems,
+		UserID:   userID,
+		Items:    serviceItems,
+		Currency: currency,
 	})
 
 	if err != nil {
-		logger.Error("Order creation error", zap.Error(err))
 		// Определяем HTTP статус на основе типа ошибки
+		if errors.Is(err, domain.ErrUnsupportedCurrency) || errors.Is(err, domain.ErrEmptyOrder) || errors.Is(err, domain.ErrInvalidLine) {
+			logger.Warn("Validation failed", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Invalid payload: %v", err), http.StatusBadRequest)
+			return
+		}
+		// Отказ в оплате: 402 с причиной и подсказкой; недоступность провайдера - 503 (можно повторить)
+		var declined *service.PaymentDeclinedError
+		if errors.As(err, &declined) {
+			code := http.StatusPaymentRequired
+			if declined.Retryable() {
+				code = http.StatusServiceUnavailable
+			}
+			logger.Warn("Payment declined", zap.String("reason", declined.Reason), zap.String("order_id", declined.OrderID))
+			setOrderResponseHeaders(w)
+			if declined.Retryable() {
+				w.Header().Set("Retry-After", strconv.Itoa(middleware.RetryAfterSeconds(providerRetryAfter)))
+			}
+			w.WriteHeader(code)
+			if err := json.NewEncoder(w).Encode(newPaymentDeclinedResponse(declined)); err != nil {
+				logger.Error("Failed to encode response", zap.Error(err))
+			}
+			return
+		}
+		// Inventory или Payment недоступны: 503 с Retry-After по состоянию breaker и backoff
+		var unavailable *service.DependencyUnavailableError
+		if errors.As(err, &unavailable) {
+			resp := newDependencyUnavailableResponse(unavailable)
+			logger.Warn("Dependency unavailable",
+				zap.String("dependency", unavailable.Dependency),
+				zap.Int("retry_after_seconds", resp.RetryAfterSeconds),
+				zap.Error(err),
+			)
+			setOrderResponseHeaders(w)
+			w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfterSeconds))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				logger.Error("Failed to encode response", zap.Error(err))
+			}
+			return
+		}
+		logger.Error("Order creation error", zap.Error(err))
 		http.Error(w, fmt.Sprintf("Failed to create order: %v", err), http.StatusServiceUnavailable)
 		return
 	}
 
 	// Формируем HTTP ответ из результата service
-	// Преобразуем service DTO в HTTP DTO
-	httpItems := make([]OrderItem, 0, len(result.Items))
-	for _, item := range result.Items {
-		productID := item.ProductID
-		quantity := int(item.Quantity)
-		httpItems = append(httpItems, OrderItem{
-			ProductID: &productID,
-			Quantity:  &quantity,
-		})
-	}
-
-	resp := OrderResponse{
-		ID:     &result.OrderID,
-		UserID: &result.UserID,
-		Status: &result.Status,
-		Items:  &httpItems,
-	}
+	// Преобразуем service DTO в сгенерированный HTTP DTO (схема v1)
+	resp := newOrderResponse(result.OrderID, result.UserID, result.Status, result.Items, result.Total)
 
-	w.Header().Set("Content-Type", "application/json")
+	setOrderResponseHeaders(w)
 	w.WriteHeader(http.StatusCreated)
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -142,45 +171,122 @@ func (h *Handler) PostOrders(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetOrdersId обрабатывает GET /orders/{id} - получение заказа по ID
-func (h *Handler) GetOrdersId(w http.ResponseWriter, r *http.Request, id string) {
+func (h *Handler) GetOrdersId(w http.ResponseWriter, r *http.Request, id string, params orderapi.GetOrdersIdParams) {
 	const op = "Handler.GetOrdersId"
 	ctx := r.Context()
 
 	logger := h.logger.With(zap.String("op", op), zap.String("order_id", id))
 	logger.Info("Received request", zap.String("method", r.Method))
 
+	includeArchived, ok := h.checkIncludeArchived(w, r, params.IncludeArchived, logger)
+	if !ok {
+		return
+	}
+
+	// Мягкий режим чтения позиций - для страниц поддержки, только админам
+	tolerateItemErrors := params.TolerateItemErrors != nil && *params.TolerateItemErrors
+	if tolerateItemErrors && !authctx.IsAdmin(ctx) {
+		logger.Warn("tolerate_item_errors requested without admin access")
+		http.Error(w, "tolerate_item_errors requires admin access", http.StatusForbidden)
+		return
+	}
+
 	// Вызываем service слой для получения заказа
 	// Бизнес-логика теперь в service, а не в обработчике
 	result, err := h.orderService.GetOrder(ctx, service.GetOrderInput{
-		OrderID: id,
+		OrderID:            id,
+		IncludeArchived:    includeArchived,
+		TolerateItemErrors: tolerateItemErrors,
 	})
 
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Order not found")
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
 		logger.Error("Get order error", zap.Error(err))
 		http.Error(w, fmt.Sprintf("Failed to get order: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Формируем HTTP ответ из результата service
-	// Преобразуем service DTO (Items []) в HTTP DTO
-	httpItems := make([]OrderItem, 0, len(result.Items))
-	for _, item := range result.Items {
-		productID := item.ProductID
-		quantity := int(item.Quantity)
-		httpItems = append(httpItems, OrderItem{
-			ProductID: &productID,
-			Quantity:  &quantity,
-		})
+	// Преобразуем service DTO (Items []) в сгенерированный HTTP DTO (схема v1)
+	resp := newOrderResponseFromOutput(*result)
+
+	setOrderResponseHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetOrders обрабатывает GET /orders?user_id=...&product_id=...&limit=...&include_archived=... - список заказов
+// пользователя и/или заказов с товаром. Нужен хотя бы один из user_id и product_id (иначе 400);
+// product_id без user_id ищет по всем пользователям и доступен только админу (иначе 403).
+// Формат параметров проверяет сгенерированная обёртка (400 при ошибке)
+func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request, params orderapi.GetOrdersParams) {
+	const op = "Handler.GetOrders"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	limit := 0
+	if params.Limit != nil {
+		if *params.Limit <= 0 {
+			logger.Warn("Validation failed: invalid limit", zap.Int("limit", *params.Limit))
+			http.Error(w, "Invalid limit: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = *params.Limit
+	}
+
+	var userID, productID string
+	if params.UserId != nil {
+		userID = *params.UserId
+	}
+	if params.ProductId != nil {
+		productID = *params.ProductId
+	}
+
+	// Поиск по товару среди всех пользователей (отзыв товара, инциденты) - только для админов
+	if userID == "" && productID != "" && !authctx.IsAdmin(ctx) {
+		logger.Warn("product_id without user_id requested without admin access")
+		http.Error(w, "product_id without user_id requires admin access", http.StatusForbidden)
+		return
+	}
+
+	includeArchived, ok := h.checkIncludeArchived(w, r, params.IncludeArchived, logger)
+	if !ok {
+		return
+	}
+
+	result, err := h.orderService.ListOrders(ctx, service.ListOrdersInput{
+		UserID:          userID,
+		ProductID:       productID,
+		IncludeArchived: includeArchived,
+		Limit:           limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) {
+			logger.Warn("Validation failed: user_id or product_id is required")
+			http.Error(w, "Invalid request: user_id or product_id is required", http.StatusBadRequest)
+			return
+		}
+		logger.Error("List orders error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to list orders: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	resp := OrderResponse{
-		ID:     &result.OrderID,
-		UserID: &result.UserID,
-		Status: &result.Status,
-		Items:  &httpItems,
+	resp := orderapi.OrderList{Orders: make([]orderapi.Order, 0, len(result))}
+	for _, order := range result {
+		resp.Orders = append(resp.Orders, newOrderResponseFromOutput(order))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	setOrderResponseHeaders(w)
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		logger.Error("Failed to encode response", zap.Error(err))
@@ -188,3 +294,125 @@ func (h *Handler) GetOrdersId(w http.ResponseWriter, r *http.Request, id string)
 		return
 	}
 }
+
+// PostAdminOrdersArchive обрабатывает POST /admin/orders/archive?older_than=720h - архивация старых завершённых заказов
+// Доступ проверяется middleware (RequireAdmin)
+func (h *Handler) PostAdminOrdersArchive(w http.ResponseWriter, r *http.Request, params orderapi.PostAdminOrdersArchiveParams) {
+	const op = "Handler.PostAdminOrdersArchive"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	raw := params.OlderThan
+	olderThan, err := time.ParseDuration(raw)
+	if err != nil {
+		logger.Warn("Validation failed: invalid older_than", zap.String("older_than", raw), zap.Error(err))
+		http.Error(w, fmt.Sprintf("Invalid older_than: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.ArchiveOrders(ctx, service.ArchiveOrdersInput{OlderThan: olderThan})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidArchiveAge) {
+			logger.Warn("Validation failed: older_than must be positive", zap.String("older_than", raw))
+			http.Error(w, fmt.Sprintf("Invalid older_than: %v", err), http.StatusBadRequest)
+			return
+		}
+		logger.Error("Archive orders error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to archive orders: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	setOrderResponseHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(orderapi.ArchiveResult{Archived: result.Archived}); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Orders archived", zap.Int64("archived", result.Archived), zap.Duration("older_than", olderThan))
+}
+
+// PostAdminOrdersIdStatus обрабатывает POST /admin/orders/{id}/status - смена статуса заказа оператором
+// Доступ проверяется middleware (RequireAdminSession), оператор в журнале - пользователь сессии администратора.
+// Смена статуса, журнал и событие пишутся в одной транзакции
+func (h *Handler) PostAdminOrdersIdStatus(w http.ResponseWriter, r *http.Request, id string) {
+	const op = "Handler.PostAdminOrdersIdStatus"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op), zap.String("order_id", id)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	operator, ok := authctx.UserIDFromContext(ctx)
+	if !ok {
+		logger.Warn("Admin session user is missing in context")
+		http.Error(w, "admin session required", http.StatusForbidden)
+		return
+	}
+
+	var reqBody orderapi.PostAdminOrdersIdStatusJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		logger.Warn("JSON decode error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.ChangeStatus(ctx, service.ChangeStatusInput{
+		OrderID:  id,
+		Status:   domain.Status(reqBody.Status),
+		Force:    reqBody.Force != nil && *reqBody.Force,
+		Reason:   reqBody.Reason,
+		Operator: operator,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownStatus), errors.Is(err, service.ErrStatusChangeAuditRequired):
+			logger.Warn("Validation failed", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Invalid payload: %v", err), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrNotFound):
+			logger.Warn("Order not found")
+			http.Error(w, "Order not found", http.StatusNotFound)
+		case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, service.ErrStatusUnchanged),
+			errors.Is(err, repository.ErrStatusConflict):
+			logger.Warn("Status change rejected", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Error("Change order status error", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Failed to change order status: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setOrderResponseHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(orderapi.StatusChangeResult{
+		OrderId:    result.OrderID,
+		FromStatus: string(result.From),
+		Status:     string(result.To),
+		Forced:     result.Forced,
+		EventId:    result.EventID,
+	}); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// checkIncludeArchived проверяет query параметр include_archived (уже разобранный сгенерированной обёрткой).
+// Архивные заказы доступны только администраторам: для остальных include_archived=true даёт 403.
+// Возвращает false, если ответ с ошибкой уже записан.
+func (h *Handler) checkIncludeArchived(w http.ResponseWriter, r *http.Request, includeArchived *orderapi.IncludeArchived, logger *zap.Logger) (bool, bool) {
+	if includeArchived == nil || !*includeArchived {
+		return false, true
+	}
+
+	if !authctx.IsAdmin(r.Context()) {
+		logger.Warn("include_archived requested without admin access")
+		http.Error(w, "include_archived requires admin access", http.StatusForbidden)
+		return false, false
+	}
+
+	return true, true
+}
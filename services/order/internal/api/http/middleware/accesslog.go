@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+)
+
+// AccessLog — HTTP middleware: пишет одну структурированную запись на запрос (method, path, status, duration).
+// Должен стоять после RequestID, чтобы запись содержала request_id.
+func AccessLog(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", recorder.status),
+				zap.Duration("duration", time.Since(start)),
+			}
+			reqLogger := platformobservability.L(r.Context(), logger)
+			switch {
+			case recorder.status >= http.StatusInternalServerError:
+				reqLogger.Error("http request", fields...)
+			case recorder.status >= http.StatusBadRequest:
+				reqLogger.Warn("http request", fields...)
+			default:
+				reqLogger.Info("http request", fields...)
+			}
+		})
+	}
+}
+
+// statusRecorder запоминает код ответа для access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader запоминает первый записанный статус
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+)
+
+// AdminTokenHeader - заголовок с токеном администратора
+const AdminTokenHeader = "X-Admin-Token"
+
+// WithAdminFlag — HTTP middleware: если заголовок X-Admin-Token совпадает с token, помечает запрос как административный.
+// Запросы без токена (или с неверным токеном) пропускаются дальше как обычные. Пустой token отключает админ-доступ.
+func WithAdminFlag(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got := r.Header.Get(AdminTokenHeader)
+			// Сравнение за постоянное время, чтобы токен нельзя было подобрать по времени ответа
+			if token != "" && got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				r = r.WithContext(authctx.WithAdmin(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin — HTTP middleware: пропускает запрос, помеченный как административный (см. WithAdminFlag),
+// или запрос с x-session-id сессии пользователя с ролью admin в IAM (см. RequireAdminSession).
+func RequireAdmin(sessions SessionValidator) func(http.Handler) http.Handler {
+	requireSession := RequireAdminSession(sessions)
+	return func(next http.Handler) http.Handler {
+		withSession := requireSession(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authctx.IsAdmin(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			withSession.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdminSession — HTTP middleware для операций, которым нужен конкретный администратор (журнал смены статуса):
+// пропускает только запрос с x-session-id сессии пользователя с ролью admin в IAM, X-Admin-Token не учитывается.
+// Пользователь сессии кладётся в context (authctx.UserIDFromContext).
+// Без сессии или с сессией обычного пользователя - 403; невалидная сессия - 401; IAM недоступен - 503.
+func RequireAdminSession(sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sid := r.Header.Get("x-session-id")
+			if sid == "" || sessions == nil {
+				http.Error(w, "admin session required", http.StatusForbidden)
+				return
+			}
+
+			session, err := sessions.ValidateSession(r.Context(), sid)
+			switch {
+			case errors.Is(err, authctx.ErrInvalidSession), err == nil && session.UserID == "":
+				http.Error(w, "invalid or expired session", http.StatusUnauthorized)
+				return
+			case err != nil:
+				http.Error(w, "IAM is unavailable", http.StatusServiceUnavailable)
+				return
+			case session.Role != authctx.RoleAdmin:
+				http.Error(w, "admin access required", http.StatusForbidden)
+				return
+			}
+
+			ctx := authctx.WithSessionID(r.Context(), sid)
+			ctx = authctx.WithUserID(ctx, session.UserID)
+			ctx = authctx.WithAdmin(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+)
+
+func TestAdmin(t *testing.T) {
+	sessions := fakeSessions{
+		"admin-sid": {UserID: "admin-1", Role: authctx.RoleAdmin},
+		"user-sid":  {UserID: "user-1", Role: "user"},
+	}
+
+	tests := []struct {
+		name         string
+		configured   string
+		headerToken  string
+		sessionID    string
+		expectedCode int
+		expectedUser string
+	}{
+		{name: "valid token", configured: "secret", headerToken: "secret", expectedCode: http.StatusOK},
+		{name: "wrong token", configured: "secret", headerToken: "other", expectedCode: http.StatusForbidden},
+		{name: "missing token", configured: "secret", headerToken: "", expectedCode: http.StatusForbidden},
+		{name: "admin disabled", configured: "", headerToken: "", expectedCode: http.StatusForbidden},
+		{name: "admin session", configured: "secret", sessionID: "admin-sid", expectedCode: http.StatusOK, expectedUser: "admin-1"},
+		{name: "admin session with token disabled", configured: "", sessionID: "admin-sid", expectedCode: http.StatusOK, expectedUser: "admin-1"},
+		{name: "user session", configured: "secret", sessionID: "user-sid", expectedCode: http.StatusForbidden},
+		{name: "invalid session", configured: "secret", sessionID: "expired", expectedCode: http.StatusUnauthorized},
+		{name: "iam unavailable", configured: "secret", sessionID: "sid-iam-down", expectedCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.True(t, authctx.IsAdmin(r.Context()))
+				gotUser, _ = authctx.UserIDFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := WithAdminFlag(tt.configured)(RequireAdmin(sessions)(next))
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/orders/archive", nil)
+			if tt.headerToken != "" {
+				req.Header.Set(AdminTokenHeader, tt.headerToken)
+			}
+			if tt.sessionID != "" {
+				req.Header.Set("x-session-id", tt.sessionID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code)
+			require.Equal(t, tt.expectedUser, gotUser)
+		})
+	}
+}
+
+func TestAdminSession(t *testing.T) {
+	sessions := fakeSessions{
+		"admin-sid": {UserID: "admin-1", Role: authctx.RoleAdmin},
+		"user-sid":  {UserID: "user-1", Role: "user"},
+	}
+
+	tests := []struct {
+		name         string
+		headerToken  string
+		sessionID    string
+		expectedCode int
+		expectedUser string
+	}{
+		{name: "token without session", headerToken: "secret", expectedCode: http.StatusForbidden},
+		{name: "admin session", sessionID: "admin-sid", expectedCode: http.StatusOK, expectedUser: "admin-1"},
+		{name: "token with admin session", headerToken: "secret", sessionID: "admin-sid", expectedCode: http.StatusOK, expectedUser: "admin-1"},
+		{name: "token with user session", headerToken: "secret", sessionID: "user-sid", expectedCode: http.StatusForbidden},
+		{name: "invalid session", sessionID: "expired", expectedCode: http.StatusUnauthorized},
+		{name: "iam unavailable", sessionID: "sid-iam-down", expectedCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = authctx.UserIDFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := WithAdminFlag("secret")(RequireAdminSession(sessions)(next))
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/orders/order-1/status", nil)
+			if tt.headerToken != "" {
+				req.Header.Set(AdminTokenHeader, tt.headerToken)
+			}
+			if tt.sessionID != "" {
+				req.Header.Set("x-session-id", tt.sessionID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code)
+			require.Equal(t, tt.expectedUser, gotUser)
+		})
+	}
+}
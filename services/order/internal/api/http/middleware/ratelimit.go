@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/ratelimit"
+)
+
+// SessionValidator определяет пользователя и его роль по session_id (IAM)
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID string) (authctx.Session, error)
+}
+
+// RateLimit — HTTP middleware: ограничивает частоту запросов по пользователю сессии x-session-id (через sessions),
+// а без сессии или с невалидной сессией — по IP клиента. Поля тела запроса на ключ не влияют: их задаёт клиент.
+// При превышении лимита возвращает 429 Too Many Requests с заголовком Retry-After (в секундах).
+func RateLimit(limiter *ratelimit.Limiter, sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := rateLimitKey(r, sessions)
+
+			allowed, retryAfter := limiter.Allow(key)
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(RetryAfterSeconds(retryAfter)))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey возвращает ключ лимита: "user:<user_id>" для валидной сессии, иначе "ip:<адрес клиента>"
+// Недоступность IAM не блокирует запрос: лимит считается по IP, а сессию дальше проверяет Inventory
+func rateLimitKey(r *http.Request, sessions SessionValidator) string {
+	if sid := r.Header.Get("x-session-id"); sid != "" && sessions != nil {
+		if session, err := sessions.ValidateSession(r.Context(), sid); err == nil && session.UserID != "" {
+			return "user:" + session.UserID
+		}
+	}
+
+	return "ip:" + clientIP(r)
+}
+
+// clientIP возвращает IP клиента из RemoteAddr (без порта)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RetryAfterSeconds округляет время ожидания вверх до целых секунд (минимум 1), как требует Retry-After
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/ratelimit"
+)
+
+// fakeSessions - сессии IAM: session_id -> пользователь сессии, неизвестная сессия - authctx.ErrInvalidSession
+type fakeSessions map[string]authctx.Session
+
+func (s fakeSessions) ValidateSession(ctx context.Context, sessionID string) (authctx.Session, error) {
+	if sessionID == "sid-iam-down" {
+		return authctx.Session{}, errors.New("iam: connection refused")
+	}
+	session, ok := s[sessionID]
+	if !ok {
+		return authctx.Session{}, authctx.ErrInvalidSession
+	}
+	return session, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	var gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	})
+	sessions := fakeSessions{"sid-1": {UserID: "user-1"}, "sid-1b": {UserID: "user-1"}, "sid-2": {UserID: "user-2"}}
+
+	newRequest := func(body, sessionID, remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		if sessionID != "" {
+			req.Header.Set("x-session-id", sessionID)
+		}
+		return req
+	}
+
+	t.Run("limit is per session user and body is preserved for handler", func(t *testing.T) {
+		handler := RateLimit(ratelimit.NewLimiter(1, 1), sessions)(next)
+		body := `{"user_id":"user-1","items":[{"product_id":"p1","quantity":1}]}`
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(body, "sid-1", "10.0.0.1:1234"))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, body, gotBody)
+
+		// Тот же пользователь с другой сессией и другого IP - лимит уже исчерпан
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(body, "sid-1b", "10.0.0.2:1234"))
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		// Другой пользователь с того же IP - свой лимит
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(body, "sid-2", "10.0.0.1:1234"))
+		require.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("user_id from body does not change the key", func(t *testing.T) {
+		handler := RateLimit(ratelimit.NewLimiter(1, 1), sessions)(next)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"user_id":"user-a"}`, "sid-1", "10.0.0.1:1234"))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"user_id":"user-b"}`, "sid-1", "10.0.0.1:1234"))
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("falls back to client IP without valid session", func(t *testing.T) {
+		handler := RateLimit(ratelimit.NewLimiter(1, 1), sessions)(next)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"user_id":"user-a"}`, "sid-forged-1", "10.0.0.1:1234"))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(`{"user_id":"user-b"}`, "", "10.0.0.1:5678"))
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+}
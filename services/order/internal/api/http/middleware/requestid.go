@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+)
+
+// maxRequestIDLength - request_id клиента длиннее этого значения заменяется сгенерированным
+const maxRequestIDLength = 128
+
+// RequestID — HTTP middleware: берёт X-Request-ID из запроса (или генерирует новый), кладёт его в context
+// и возвращает клиенту в заголовке ответа. Из context request_id попадает в логи (observability.L)
+// и в исходящие gRPC вызовы (observability.GRPCUnaryClientInterceptor).
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(platformobservability.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(platformobservability.RequestIDHeader, requestID)
+		ctx := platformobservability.WithRequestID(r.Context(), requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID проверяет request_id клиента: непустой, ограниченной длины, только печатные ASCII символы
+// (значение попадает в логи и gRPC metadata)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+)
+
+func TestRequestID(t *testing.T) {
+	var gotRequestID string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID, _ = platformobservability.RequestIDFromContext(r.Context())
+	}))
+
+	t.Run("propagates client request id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(platformobservability.RequestIDHeader, "req-123")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, "req-123", gotRequestID)
+		require.Equal(t, "req-123", rec.Header().Get(platformobservability.RequestIDHeader))
+	})
+
+	t.Run("generates request id when missing or invalid", func(t *testing.T) {
+		for _, header := range []string{"", strings.Repeat("a", maxRequestIDLength+1), "bad id\n"} {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if header != "" {
+				req.Header.Set(platformobservability.RequestIDHeader, header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.NotEmpty(t, gotRequestID)
+			require.NotEqual(t, header, gotRequestID)
+			require.Equal(t, gotRequestID, rec.Header().Get(platformobservability.RequestIDHeader))
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := RequestID(AccessLog(zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/order-1", nil)
+	req.Header.Set(platformobservability.RequestIDHeader, "req-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	fields := entry.ContextMap()
+	require.Equal(t, zap.WarnLevel, entry.Level)
+	require.Equal(t, "GET", fields["method"])
+	require.Equal(t, "/orders/order-1", fields["path"])
+	require.Equal(t, int64(http.StatusNotFound), fields["status"])
+	require.Equal(t, "req-123", fields["request_id"])
+	require.Contains(t, fields, "duration")
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
@@ -15,6 +16,39 @@ func WithSessionID(next http.Handler) http.Handler {
 			return
 		}
 		ctx := authctx.WithSessionID(r.Context(), sid) // добавляем session_id в контекст
-		next.ServeHTTP(w, r.WithContext(ctx)) // вызываем следующий handler
+		next.ServeHTTP(w, r.WithContext(ctx))          // вызываем следующий handler
 	})
 }
+
+// RequireSessionUser — HTTP middleware: проверяет x-session-id в IAM (sessions) и кладёт пользователя сессии в context.
+// Нужен ручкам, которые действуют от имени пользователя: владелец берётся из сессии, а не из параметров запроса.
+// Без сессии или с невалидной сессией - 401; IAM недоступен - 503.
+func RequireSessionUser(sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sid := r.Header.Get("x-session-id")
+			if sid == "" {
+				http.Error(w, "session_id is required", http.StatusUnauthorized)
+				return
+			}
+			if sessions == nil {
+				http.Error(w, "session validation is not configured", http.StatusServiceUnavailable)
+				return
+			}
+
+			session, err := sessions.ValidateSession(r.Context(), sid)
+			switch {
+			case errors.Is(err, authctx.ErrInvalidSession), err == nil && session.UserID == "":
+				http.Error(w, "invalid or expired session", http.StatusUnauthorized)
+				return
+			case err != nil:
+				http.Error(w, "IAM is unavailable", http.StatusServiceUnavailable)
+				return
+			}
+
+			ctx := authctx.WithSessionID(r.Context(), sid)
+			ctx = authctx.WithUserID(ctx, session.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
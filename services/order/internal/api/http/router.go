@@ -7,15 +7,21 @@ import (
 	platformhealth "github.com/shestoi/GoBigTech/platform/health/http"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
 	"github.com/shestoi/GoBigTech/services/order/internal/api/http/middleware"
+	"github.com/shestoi/GoBigTech/services/order/internal/ratelimit"
 	"go.uber.org/zap"
 )
 
 // NewRouter создаёт и настраивает HTTP роутер для Order Service
+// Маршруты API и разбор параметров генерируются из services/order/api/openapi.yaml (пакет orderapi).
 // readiness - функция для проверки готовности сервиса (например, проверка БД).
 // Если readiness возвращает false, health endpoint вернёт 503 Service Unavailable.
-// logger используется для observability HTTP middleware (trace_id в логах).
-func NewRouter(handler *Handler, readiness func() bool, logger *zap.Logger) chi.Router {
+// logger используется для observability HTTP middleware и access log (trace_id и request_id в логах).
+// sessions проверяет x-session-id в IAM: владелец /webhooks* и ключ лимита - пользователь сессии, роль admin открывает /admin/*.
+// rateLimiter ограничивает создание заказов (POST /orders) по пользователю сессии или IP; nil - без ограничений.
+// adminToken - токен для заголовка X-Admin-Token (include_archived, /admin/*); пустой - доступ по токену выключен.
+func NewRouter(handler *Handler, readiness func() bool, rateLimiter *ratelimit.Limiter, sessions middleware.SessionValidator, adminToken string, logger *zap.Logger) chi.Router {
 	router := chi.NewRouter()
 
 	// Observability: trace context + span на каждый запрос, logger с trace_id в контексте
@@ -23,18 +29,77 @@ func NewRouter(handler *Handler, readiness func() bool, logger *zap.Logger) chi.
 		router.Use(platformobservability.HTTPMiddleware("order", logger))
 	}
 
-	// /orders* требуют x-session-id (middleware возвращает 401 при отсутствии)
-	router.Route("/orders", func(r chi.Router) {
-		r.Use(middleware.WithSessionID)
-		r.Post("/", handler.PostOrders)
-		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
-			id := chi.URLParam(r, "id")
-			handler.GetOrdersId(w, r, id)
-		})
+	// X-Request-ID: принимаем от клиента или генерируем, прокидываем в логи и исходящие gRPC вызовы
+	router.Use(middleware.RequestID)
+	// Access log: method/path/status/duration на каждый запрос (с trace_id и request_id)
+	if logger != nil {
+		router.Use(middleware.AccessLog(logger))
+	}
+
+	// Помечаем запросы с валидным X-Admin-Token как административные (ничего не отклоняет)
+	router.Use(middleware.WithAdminFlag(adminToken))
+
+	// Middleware операций: сгенерированная обёртка применяет их по порядку, последний - внешний
+	var operationMiddlewares []orderapi.MiddlewareFunc
+	// Лимит только на создание заказа: оно вызывает Inventory и Payment
+	if rateLimiter != nil {
+		operationMiddlewares = append(operationMiddlewares,
+			forOperations(middleware.RateLimit(rateLimiter, sessions), "POST /orders"))
+	}
+	operationMiddlewares = append(operationMiddlewares,
+		// /admin/* доступны только с валидным X-Admin-Token или сессией администратора IAM (иначе 403)
+		forOperations(middleware.RequireAdmin(sessions),
+			"POST /admin/orders/archive",
+			"GET /admin/consumers",
+			"POST /admin/consumers/{name}/pause",
+			"POST /admin/consumers/{name}/resume",
+		),
+		// Смену статуса журналируем от имени администратора, поэтому нужна его сессия IAM, токена мало
+		forOperations(middleware.RequireAdminSession(sessions),
+			"POST /admin/orders/{id}/status",
+		),
+		// /orders* и /users/* требуют x-session-id (middleware возвращает 401 при отсутствии)
+		forOperations(middleware.WithSessionID,
+			"GET /orders",
+			"POST /orders",
+			"GET /orders/{id}",
+			"GET /users/{id}/order-stats",
+		),
+		// /webhooks* действуют от имени пользователя: сессия проверяется в IAM, владелец берётся из неё
+		forOperations(middleware.RequireSessionUser(sessions),
+			"GET /webhooks",
+			"POST /webhooks",
+			"DELETE /webhooks/{id}",
+		),
+	)
+
+	orderapi.HandlerWithOptions(handler, orderapi.ChiServerOptions{
+		BaseRouter:  router,
+		Middlewares: operationMiddlewares,
 	})
 
-	// Health без middleware (не требует сессии)
+	// Health без middleware (не требует сессии, не описан в openapi.yaml)
 	router.Get("/health", platformhealth.Handler(readiness))
 
 	return router
 }
+
+// forOperations применяет mw только к перечисленным операциям ("METHOD /path/{param}" как в openapi.yaml).
+// Сгенерированный роутер задаёт одни middleware на все операции, а сессия, лимит и админ-доступ нужны разным маршрутам.
+func forOperations(mw func(http.Handler) http.Handler, operations ...string) orderapi.MiddlewareFunc {
+	set := make(map[string]struct{}, len(operations))
+	for _, op := range operations {
+		set[op] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := set[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]; ok {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
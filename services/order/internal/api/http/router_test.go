@@ -0,0 +1,594 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/api/http/middleware"
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/ratelimit"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+	"github.com/shestoi/GoBigTech/services/order/internal/service/mocks"
+)
+
+const testAdminToken = "admin-secret"
+
+func newTestRouter(t *testing.T) (http.Handler, *repoMocks.OrderRepository) {
+	router, mockRepo, _ := newTestRouterWithWebhooks(t)
+	return router, mockRepo
+}
+
+func newTestRouterWithWebhooks(t *testing.T) (http.Handler, *repoMocks.OrderRepository, *repoMocks.WebhookRepository) {
+	mockRepo := repoMocks.NewOrderRepository(t)
+	webhookRepo := repoMocks.NewWebhookRepository(t)
+	orderService := service.NewOrderService(zap.NewNop(), mocks.NewInventoryClient(t), mocks.NewPaymentClient(t), mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+	handler := NewHandler(orderService, service.NewWebhookService(zap.NewNop(), webhookRepo), nil, zap.NewNop())
+	router := NewRouter(handler, func() bool { return true }, ratelimit.NewLimiter(1, 1), testSessions{}, testAdminToken, nil)
+	return router, mockRepo, webhookRepo
+}
+
+// testSessions - IAM для тестов роутера: sid -> user-1, sid-2 -> user-2, sid-admin -> администратор admin-1,
+// sid-iam-down - IAM недоступен
+type testSessions struct{}
+
+func (testSessions) ValidateSession(ctx context.Context, sessionID string) (authctx.Session, error) {
+	switch sessionID {
+	case "sid":
+		return authctx.Session{UserID: "user-1", Role: "user"}, nil
+	case "sid-2":
+		return authctx.Session{UserID: "user-2", Role: "user"}, nil
+	case "sid-admin":
+		return authctx.Session{UserID: "admin-1", Role: authctx.RoleAdmin}, nil
+	case "sid-iam-down":
+		return authctx.Session{}, errors.New("iam: connection refused")
+	}
+	return authctx.Session{}, authctx.ErrInvalidSession
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		headers      map[string]string
+		setup        func(repo *repoMocks.OrderRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "orders require session",
+			method:       http.MethodGet,
+			target:       "/orders/order-1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "get order by id",
+			method:  http.MethodGet,
+			target:  "/orders/order-1",
+			headers: map[string]string{"x-session-id": "sid"},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{}).
+					Return(repository.Order{ID: "order-1", UserID: "user-1", Status: "paid"}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":"order-1","user_id":"user-1","status":"paid","items":[]}`,
+		},
+		{
+			name:    "archived order is not found",
+			method:  http.MethodGet,
+			target:  "/orders/order-1",
+			headers: map[string]string{"x-session-id": "sid"},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{}).
+					Return(repository.Order{}, repository.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "include_archived requires admin",
+			method:       http.MethodGet,
+			target:       "/orders/order-1?include_archived=true",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "tolerate_item_errors requires admin",
+			method:       http.MethodGet,
+			target:       "/orders/order-1?tolerate_item_errors=true",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:    "order with unreadable items for admin",
+			method:  http.MethodGet,
+			target:  "/orders/order-1?tolerate_item_errors=true",
+			headers: map[string]string{"x-session-id": "sid", middleware.AdminTokenHeader: testAdminToken},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{TolerateItemErrors: true}).
+					Return(repository.Order{ID: "order-1", UserID: "user-1", Status: "paid", ItemsError: "corrupted row"}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":"order-1","user_id":"user-1","status":"paid","items":[],"items_error":"corrupted row"}`,
+		},
+		{
+			name:         "invalid include_archived is rejected by generated wrapper",
+			method:       http.MethodGet,
+			target:       "/orders/order-1?include_archived=maybe",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "list requires user_id or product_id",
+			method:       http.MethodGet,
+			target:       "/orders",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:    "list orders of user with product",
+			method:  http.MethodGet,
+			target:  "/orders?user_id=user-1&product_id=product-1",
+			headers: map[string]string{"x-session-id": "sid"},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("List", mock.Anything, repository.ListFilter{UserID: "user-1", ProductID: "product-1", Limit: service.DefaultListLimit}).
+					Return([]repository.Order{}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"orders":[]}`,
+		},
+		{
+			name:         "product_id without user_id requires admin",
+			method:       http.MethodGet,
+			target:       "/orders?product_id=product-1",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:    "search orders by product for admin",
+			method:  http.MethodGet,
+			target:  "/orders?product_id=product-1",
+			headers: map[string]string{"x-session-id": "sid", middleware.AdminTokenHeader: testAdminToken},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("List", mock.Anything, repository.ListFilter{ProductID: "product-1", Limit: service.DefaultListLimit}).
+					Return([]repository.Order{{ID: "order-1", UserID: "user-2", Status: "paid",
+						Items: []repository.OrderItem{{ProductID: "product-1", Quantity: 2, Status: "reserved"}}}}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"orders":[{"id":"order-1","user_id":"user-2","status":"paid","items":[{"product_id":"product-1","quantity":2,"status":"reserved"}]}]}`,
+		},
+		{
+			name:    "list with include_archived for admin",
+			method:  http.MethodGet,
+			target:  "/orders?user_id=user-1&include_archived=true&limit=10",
+			headers: map[string]string{"x-session-id": "sid", middleware.AdminTokenHeader: testAdminToken},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("List", mock.Anything, repository.ListFilter{UserID: "user-1", IncludeArchived: true, Limit: 10}).
+					Return([]repository.Order{{ID: "order-1", UserID: "user-1", Status: "assembled", ArchivedAt: 1700000000}}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"orders":[{"id":"order-1","user_id":"user-1","status":"assembled","items":[],"archived_at":1700000000}]}`,
+		},
+		{
+			name:         "create order validates body",
+			method:       http.MethodPost,
+			target:       "/orders",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "archive requires admin token",
+			method:       http.MethodPost,
+			target:       "/admin/orders/archive?older_than=720h",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:    "archive with admin token",
+			method:  http.MethodPost,
+			target:  "/admin/orders/archive?older_than=720h",
+			headers: map[string]string{middleware.AdminTokenHeader: testAdminToken},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("ArchiveCompletedBefore", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"archived":2}`,
+		},
+		{
+			name:    "archive with admin session",
+			method:  http.MethodPost,
+			target:  "/admin/orders/archive?older_than=720h",
+			headers: map[string]string{"x-session-id": "sid-admin"},
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("ArchiveCompletedBefore", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"archived":1}`,
+		},
+		{
+			name:         "archive with user session is forbidden",
+			method:       http.MethodPost,
+			target:       "/admin/orders/archive?older_than=720h",
+			headers:      map[string]string{"x-session-id": "sid"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "health does not require session",
+			method:       http.MethodGet,
+			target:       "/health",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo := newTestRouter(t)
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, http.NoBody)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			if tt.expectedBody != "" {
+				require.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_Webhooks(t *testing.T) {
+	createdAt := time.Unix(1700000000, 0).UTC()
+	// IP-литерал: проверка публичного адреса проходит без DNS
+	const hookURL = "https://93.184.215.14/hook"
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		sessionID    string // пусто - "sid" пользователя user-1
+		noSession    bool
+		setup        func(repo *repoMocks.WebhookRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "webhooks require session",
+			method:       http.MethodGet,
+			target:       "/webhooks",
+			noSession:    true,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unknown session is rejected",
+			method:       http.MethodPost,
+			target:       "/webhooks",
+			body:         `{"url":"` + hookURL + `"}`,
+			sessionID:    "sid-forged",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "IAM unavailable",
+			method:       http.MethodGet,
+			target:       "/webhooks",
+			sessionID:    "sid-iam-down",
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "list hides secrets",
+			method: http.MethodGet,
+			target: "/webhooks",
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("ListWebhooks", mock.Anything, "user-1").
+					Return([]repository.Webhook{{ID: "wh-1", UserID: "user-1", URL: hookURL, Secret: "s3cret", CreatedAt: createdAt}}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"webhooks":[{"id":"wh-1","user_id":"user-1","url":"` + hookURL + `","created_at":1700000000}]}`,
+		},
+		{
+			name:   "list ignores user_id from query",
+			method: http.MethodGet,
+			target: "/webhooks?user_id=user-2",
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("ListWebhooks", mock.Anything, "user-1").Return([]repository.Webhook{}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"webhooks":[]}`,
+		},
+		{
+			name:         "register rejects invalid url",
+			method:       http.MethodPost,
+			target:       "/webhooks",
+			body:         `{"url":"not a url"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "register rejects internal address",
+			method:       http.MethodPost,
+			target:       "/webhooks",
+			body:         `{"url":"http://169.254.169.254/latest/meta-data"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "register duplicate url",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"` + hookURL + `"}`,
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("CreateWebhook", mock.Anything, mock.Anything, service.MaxWebhooksPerUser).Return(repository.ErrWebhookExists).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "register over limit",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"` + hookURL + `"}`,
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("CreateWebhook", mock.Anything, mock.Anything, service.MaxWebhooksPerUser).Return(repository.ErrWebhookLimitReached).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "register webhook for session user, not body user_id",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"user_id":"user-2","url":"` + hookURL + `"}`,
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w repository.Webhook) bool {
+					return w.UserID == "user-1" && w.URL == hookURL
+				}), service.MaxWebhooksPerUser).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "delete unknown webhook",
+			method: http.MethodDelete,
+			target: "/webhooks/wh-1",
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("DeleteWebhook", mock.Anything, "user-1", "wh-1").Return(repository.ErrWebhookNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:      "delete webhook of session user",
+			method:    http.MethodDelete,
+			target:    "/webhooks/wh-1?user_id=user-1",
+			sessionID: "sid-2",
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("DeleteWebhook", mock.Anything, "user-2", "wh-1").Return(repository.ErrWebhookNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "delete webhook",
+			method: http.MethodDelete,
+			target: "/webhooks/wh-1",
+			setup: func(repo *repoMocks.WebhookRepository) {
+				repo.On("DeleteWebhook", mock.Anything, "user-1", "wh-1").Return(nil).Once()
+			},
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, repo := newTestRouterWithWebhooks(t)
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if !tt.noSession {
+				sessionID := tt.sessionID
+				if sessionID == "" {
+					sessionID = "sid"
+				}
+				req.Header.Set("x-session-id", sessionID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			if tt.expectedBody != "" {
+				require.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+			if tt.expectedCode == http.StatusCreated {
+				var webhook map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &webhook))
+				require.NotEmpty(t, webhook["secret"])
+				require.Equal(t, "user-1", webhook["user_id"])
+			}
+		})
+	}
+}
+
+func TestRouter_PostOrders_DependencyUnavailable(t *testing.T) {
+	inventory := mocks.NewInventoryClient(t)
+	orderService := service.NewOrderService(zap.NewNop(), inventory, mocks.NewPaymentClient(t), repoMocks.NewOrderRepository(t),
+		"order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+	handler := NewHandler(orderService, service.NewWebhookService(zap.NewNop(), repoMocks.NewWebhookRepository(t)), nil, zap.NewNop())
+	router := NewRouter(handler, func() bool { return true }, ratelimit.NewLimiter(1, 1), nil, testAdminToken, nil)
+
+	inventory.On("ReserveStockBatch", mock.Anything, mock.Anything, mock.Anything).Return(&service.DependencyUnavailableError{
+		Dependency:  service.DependencyInventory,
+		RetryAfter:  2500 * time.Millisecond,
+		BreakerOpen: true,
+	}).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"user_id": "user-1", "items": [{"product_id": "product-1", "quantity": 1}]}`))
+	req.Header.Set("x-session-id", "sid")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	require.Equal(t, "3", rec.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"dependency":"inventory","message":"inventory service is temporarily unavailable, retry later","retry_after_seconds":3}`, rec.Body.String())
+}
+
+func TestRouter_GetUserOrderStats(t *testing.T) {
+	newRouter := func(t *testing.T) (http.Handler, *repoMocks.OrderStatsRepository) {
+		statsRepo := repoMocks.NewOrderStatsRepository(t)
+		orderService := service.NewOrderService(zap.NewNop(), mocks.NewInventoryClient(t), mocks.NewPaymentClient(t), repoMocks.NewOrderRepository(t),
+			"order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+		statsService := service.NewOrderStatsService(zap.NewNop(), statsRepo, []service.StatsWindow{{Name: "all"}})
+		handler := NewHandler(orderService, service.NewWebhookService(zap.NewNop(), repoMocks.NewWebhookRepository(t)), statsService, zap.NewNop())
+		return NewRouter(handler, func() bool { return true }, ratelimit.NewLimiter(1, 1), nil, testAdminToken, nil), statsRepo
+	}
+
+	t.Run("requires session", func(t *testing.T) {
+		router, _ := newRouter(t)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/user-1/order-stats", nil))
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		router, _ := newRouter(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/user-1/order-stats?windows=7d,week", nil)
+		req.Header.Set("x-session-id", "sid")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	})
+
+	t.Run("stats by window and status", func(t *testing.T) {
+		router, statsRepo := newRouter(t)
+		statsRepo.On("GetUserOrderStats", mock.Anything, "user-1", mock.MatchedBy(func(since []time.Time) bool {
+			return len(since) == 2 && !since[0].IsZero() && since[1].IsZero()
+		})).Return([]repository.OrderStatsRow{
+			{Window: 0, Status: "paid", Currency: "RUB", Count: 2, TotalAmount: 3000},
+			{Window: 1, Status: "assembled", Currency: "USD", Count: 1, TotalAmount: 500},
+			{Window: 1, Status: "paid", Currency: "RUB", Count: 3, TotalAmount: 4000},
+		}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/user-1/order-stats?windows=30d,all", nil)
+		req.Header.Set("x-session-id", "sid")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			UserID  string `json:"user_id"`
+			Windows []struct {
+				Window      string            `json:"window"`
+				Since       *int64            `json:"since"`
+				OrdersCount int64             `json:"orders_count"`
+				ByStatus    []json.RawMessage `json:"by_status"`
+			} `json:"windows"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Equal(t, "user-1", resp.UserID)
+		require.Len(t, resp.Windows, 2)
+		require.Equal(t, "30d", resp.Windows[0].Window)
+		require.NotNil(t, resp.Windows[0].Since)
+		require.Equal(t, int64(2), resp.Windows[0].OrdersCount)
+		require.Equal(t, "all", resp.Windows[1].Window)
+		require.Nil(t, resp.Windows[1].Since)
+		require.Equal(t, int64(4), resp.Windows[1].OrdersCount)
+		require.JSONEq(t, `{"status":"assembled","currency":"USD","count":1,"total_amount":500}`, string(resp.Windows[1].ByStatus[0]))
+	})
+}
+
+func TestRouter_AdminOrderStatus(t *testing.T) {
+	adminSession := map[string]string{"x-session-id": "sid-admin"}
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		body         string
+		setup        func(repo *repoMocks.OrderRepository)
+		expectedCode int
+	}{
+		{
+			name:         "requires admin session",
+			body:         `{"status":"assembled","reason":"INC-1"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "admin token is not enough",
+			headers:      map[string]string{middleware.AdminTokenHeader: testAdminToken},
+			body:         `{"status":"assembled","reason":"INC-1"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "user session is rejected",
+			headers:      map[string]string{"x-session-id": "sid"},
+			body:         `{"status":"assembled","reason":"INC-1"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "reason is required",
+			headers:      adminSession,
+			body:         `{"status":"assembled"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:    "transition without force is rejected",
+			headers: adminSession,
+			body:    `{"status":"paid","reason":"INC-1"}`,
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{}).
+					Return(repository.Order{ID: "order-1", Status: "assembled"}, nil).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:    "unknown order",
+			headers: adminSession,
+			body:    `{"status":"assembled","reason":"INC-1"}`,
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{}).
+					Return(repository.Order{}, repository.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:    "operator is the session user, not the body",
+			headers: adminSession,
+			body:    `{"status":"assembled","reason":"INC-1","operator":"someone-else"}`,
+			setup: func(repo *repoMocks.OrderRepository) {
+				repo.On("GetByID", mock.Anything, "order-1", repository.GetOptions{}).
+					Return(repository.Order{ID: "order-1", Status: "paid"}, nil).Once()
+				repo.On("ChangeStatusTx", mock.Anything, mock.MatchedBy(func(audit repository.StatusAudit) bool {
+					return audit.Operator == "admin-1"
+				}), mock.Anything).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo := newTestRouter(t)
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/orders/order-1/status", strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+)
+
+// GetUsersIdOrderStats обрабатывает GET /users/{id}/order-stats?windows=7d,30d,all - количество и суммы заказов
+// пользователя по статусам за каждое окно (страницы поддержки, программа лояльности)
+func (h *Handler) GetUsersIdOrderStats(w http.ResponseWriter, r *http.Request, id string, params orderapi.GetUsersIdOrderStatsParams) {
+	const op = "Handler.GetUsersIdOrderStats"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op), zap.String("user_id", id)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	var windows []string
+	if params.Windows != nil {
+		windows = *params.Windows
+	}
+
+	stats, err := h.statsService.GetUserOrderStats(ctx, service.GetUserOrderStatsInput{UserID: id, Windows: windows})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatsWindow) || errors.Is(err, service.ErrStatsUserIDRequired) {
+			logger.Warn("Validation failed", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		logger.Error("Get order stats error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to get order stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	setOrderResponseHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(newOrderStatsResponse(stats)); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// newOrderStatsResponse преобразует статистику service в сгенерированный HTTP DTO
+func newOrderStatsResponse(stats *service.UserOrderStats) orderapi.OrderStats {
+	resp := orderapi.OrderStats{UserId: stats.UserID, Windows: make([]orderapi.OrderStatsWindow, 0, len(stats.Windows))}
+	for _, window := range stats.Windows {
+		item := orderapi.OrderStatsWindow{
+			Window:      window.Window,
+			OrdersCount: window.OrdersCount,
+			ByStatus:    make([]orderapi.OrderStatusStats, 0, len(window.ByStatus)),
+		}
+		if !window.Since.IsZero() {
+			since := window.Since.Unix()
+			item.Since = &since
+		}
+		for _, s := range window.ByStatus {
+			item.ByStatus = append(item.ByStatus, orderapi.OrderStatusStats{
+				Status:      s.Status,
+				Currency:    s.Currency,
+				Count:       s.Count,
+				TotalAmount: s.TotalAmount,
+			})
+		}
+		resp.Windows = append(resp.Windows, item)
+	}
+	return resp
+}
@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+)
+
+// GetWebhooks обрабатывает GET /webhooks - список webhook'ов пользователя сессии (без секретов)
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	const op = "Handler.GetWebhooks"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	// Пользователя кладёт middleware.RequireSessionUser после проверки сессии в IAM
+	userID, _ := authctx.UserIDFromContext(ctx)
+	webhooks, err := h.webhookService.ListWebhooks(ctx, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookUserIDRequired) {
+			logger.Warn("Session user is missing")
+			http.Error(w, "session user is required", http.StatusUnauthorized)
+			return
+		}
+		logger.Error("List webhooks error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to list webhooks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := orderapi.WebhookList{Webhooks: make([]orderapi.Webhook, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, newWebhookResponse(webhook, false))
+	}
+
+	setOrderResponseHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// PostWebhooks обрабатывает POST /webhooks - регистрация URL для callback'ов об изменении статуса заказа
+// Владелец webhook'а - пользователь сессии. Секрет подписи возвращается только в этом ответе
+func (h *Handler) PostWebhooks(w http.ResponseWriter, r *http.Request) {
+	const op = "Handler.PostWebhooks"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op)))
+	logger.Info("Received request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	var reqBody orderapi.PostWebhooksJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		logger.Warn("JSON decode error", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := authctx.UserIDFromContext(ctx)
+	webhook, err := h.webhookService.RegisterWebhook(ctx, service.RegisterWebhookInput{
+		UserID: userID,
+		URL:    reqBody.Url,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWebhookUserIDRequired):
+			logger.Warn("Session user is missing")
+			http.Error(w, "session user is required", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrInvalidWebhookURL):
+			logger.Warn("Validation failed", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Invalid payload: %v", err), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrWebhookExists), errors.Is(err, service.ErrTooManyWebhooks):
+			logger.Warn("Webhook conflict", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Error("Register webhook error", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Failed to register webhook: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setOrderResponseHeaders(w)
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(newWebhookResponse(*webhook, true)); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+		return
+	}
+
+	logger.Info("Webhook registered", zap.String("webhook_id", webhook.ID))
+}
+
+// DeleteWebhooksId обрабатывает DELETE /webhooks/{id} - удаление webhook'а пользователя сессии
+func (h *Handler) DeleteWebhooksId(w http.ResponseWriter, r *http.Request, id string) {
+	const op = "Handler.DeleteWebhooksId"
+	ctx := r.Context()
+
+	logger := platformobservability.L(ctx, h.logger.With(zap.String("op", op), zap.String("webhook_id", id)))
+	logger.Info("Received request", zap.String("method", r.Method))
+
+	userID, _ := authctx.UserIDFromContext(ctx)
+	if err := h.webhookService.DeleteWebhook(ctx, userID, id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrWebhookUserIDRequired):
+			logger.Warn("Session user is missing")
+			http.Error(w, "session user is required", http.StatusUnauthorized)
+		case errors.Is(err, repository.ErrWebhookNotFound):
+			logger.Warn("Webhook not found")
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+		default:
+			logger.Error("Delete webhook error", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Failed to delete webhook: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newWebhookResponse собирает orderapi.Webhook; секрет включается только при регистрации
+func newWebhookResponse(webhook repository.Webhook, withSecret bool) orderapi.Webhook {
+	resp := orderapi.Webhook{
+		Id:        webhook.ID,
+		UserId:    webhook.UserID,
+		Url:       webhook.URL,
+		CreatedAt: webhook.CreatedAt.Unix(),
+	}
+	if withSecret {
+		resp.Secret = optional(webhook.Secret)
+	}
+	return resp
+}
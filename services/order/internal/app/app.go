@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"sync"
@@ -15,25 +16,39 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
+	platformdiscovery "github.com/shestoi/GoBigTech/platform/discovery"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	platformshutdown "github.com/shestoi/GoBigTech/platform/shutdown"
+	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
 	inventorypb "github.com/shestoi/GoBigTech/services/inventory/v1"
 	httpapi "github.com/shestoi/GoBigTech/services/order/internal/api/http"
 	grpcclient "github.com/shestoi/GoBigTech/services/order/internal/client/grpc"
 	"github.com/shestoi/GoBigTech/services/order/internal/config"
 	eventkafka "github.com/shestoi/GoBigTech/services/order/internal/event/kafka"
+	"github.com/shestoi/GoBigTech/services/order/internal/ratelimit"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository/postgres"
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
+	"github.com/shestoi/GoBigTech/services/order/internal/webhook"
 	paymentpb "github.com/shestoi/GoBigTech/services/payment/v1"
 )
 
+// Имена фоновых обработчиков в /admin/consumers
+const (
+	workerAssemblyConsumer = "assembly-consumer"
+	workerOutboxDispatcher = "outbox-dispatcher"
+)
+
 // App содержит все зависимости для запуска и корректного shutdown Order Service
 type App struct {
 	logger           *zap.Logger
 	httpServer       *http.Server
 	assemblyConsumer *eventkafka.OrderAssemblyCompletedConsumer
 	outboxDispatcher *eventkafka.OutboxDispatcher
+	outboxReconciler *eventkafka.OutboxReconciler
+	webhookDispatch  *webhook.Dispatcher
 	shutdownMgr      *platformshutdown.Manager
 	readiness        func() bool
 	wg               sync.WaitGroup
@@ -43,12 +58,23 @@ type App struct {
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Окна статистики заказов разбираются до подключения к зависимостям: ошибка конфига не оставляет открытых соединений
+	statsWindows, err := service.ParseStatsWindows(cfg.OrderStatsWindows)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ORDER_STATS_WINDOWS: %w", err)
+	}
+
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "order",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
@@ -70,11 +96,21 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Подключаемся к Inventory сервису
 	logger.Info("Connecting to Inventory service", zap.String("addr", cfg.InventoryGRPCAddr))
-	inventoryConn, err := grpc.NewClient(cfg.InventoryGRPCAddr,
+	// Observability снаружи: один span на вызов, повторы и отказы breaker'а внутри него
+	inventoryConn, err := platformdiscovery.NewClient(cfg.InventoryGRPCAddr, cfg.Discovery,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithChainUnaryInterceptor(platformobservability.GRPCUnaryClientInterceptor("order")),
+		grpc.WithChainUnaryInterceptor(
+			platformobservability.GRPCUnaryClientInterceptor("order"),
+			grpcclient.ResilienceInterceptor(logger, "inventory", callPolicy(cfg, cfg.InventoryCallTimeout, cfg.InventoryMaxRetries)),
+		),
 	)
 	if err != nil {
 		return nil, err
@@ -84,9 +120,19 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Подключаемся к Payment сервису
 	logger.Info("Connecting to Payment service", zap.String("addr", cfg.PaymentGRPCAddr))
-	paymentConn, err := grpc.NewClient(cfg.PaymentGRPCAddr,
+	paymentInterceptors := []grpc.UnaryClientInterceptor{
+		platformobservability.GRPCUnaryClientInterceptor("order"),
+		grpcclient.ResilienceInterceptor(logger, "payment", callPolicy(cfg, cfg.PaymentCallTimeout, cfg.PaymentMaxRetries)),
+	}
+	// Payment пускает к API только по токену вызывающего сервиса (PAYMENT_AUTH_TOKENS)
+	if cfg.PaymentServiceToken != "" {
+		paymentInterceptors = append(paymentInterceptors, grpcclient.ServiceTokenInterceptor(cfg.PaymentServiceToken))
+	} else {
+		logger.Warn("PAYMENT_SERVICE_TOKEN is not set: payment calls are rejected unless payment auth is disabled")
+	}
+	paymentConn, err := platformdiscovery.NewClient(cfg.PaymentGRPCAddr, cfg.Discovery,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithChainUnaryInterceptor(platformobservability.GRPCUnaryClientInterceptor("order")),
+		grpc.WithChainUnaryInterceptor(paymentInterceptors...),
 	)
 	if err != nil {
 		inventoryConn.Close()
@@ -95,6 +141,21 @@ func Build(cfg config.Config) (*App, error) {
 
 	paymentClient := paymentpb.NewPaymentServiceClient(paymentConn)
 
+	// IAM определяет пользователя сессии: владельца webhook'ов и ключ rate limit
+	logger.Info("Connecting to IAM service", zap.String("addr", cfg.IAMGRPCAddr))
+	iamConn, err := platformdiscovery.NewClient(cfg.IAMGRPCAddr, cfg.Discovery,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(platformobservability.GRPCUnaryClientInterceptor("order")),
+	)
+	if err != nil {
+		inventoryConn.Close()
+		paymentConn.Close()
+		return nil, err
+	}
+	closeIAMConn := func() {
+		iamConn.Close()
+	}
+
 	// Обёртываем gRPC клиенты в адаптеры
 	inventoryClientAdapter := grpcclient.NewInventoryClientAdapter(inventoryClient)
 	paymentClientAdapter := grpcclient.NewPaymentClientAdapter(paymentClient)
@@ -105,6 +166,7 @@ func Build(cfg config.Config) (*App, error) {
 	if err != nil {
 		inventoryConn.Close()
 		paymentConn.Close()
+		closeIAMConn()
 		return nil, err
 	}
 
@@ -113,6 +175,7 @@ func Build(cfg config.Config) (*App, error) {
 		pool.Close()
 		inventoryConn.Close()
 		paymentConn.Close()
+		closeIAMConn()
 		return nil, err
 	}
 	logger.Info("PostgreSQL connection established")
@@ -134,12 +197,12 @@ func Build(cfg config.Config) (*App, error) {
 	// Создаём PostgreSQL репозиторий
 	orderRepo := postgres.NewRepository(pool)
 
-	// Метрики заказов (orders_created_total, order_revenue_total)
+	// Метрики заказов (orders_created_total, order_revenue_total, order_items_read_errors_total)
 	var orderMetrics service.OrderMetricsRecorder
 	if cfg.OTelEnabled {
 		orderMetrics = newOrderMetricsRecorder()
 	}
-	orderService := service.NewOrderService(logger, inventoryClientAdapter, paymentClientAdapter, orderRepo, cfg.PaymentCompletedTopic, orderMetrics)
+	orderService := service.NewOrderService(logger, inventoryClientAdapter, paymentClientAdapter, orderRepo, cfg.PaymentCompletedTopic, cfg.PaymentDeclinedTopic, cfg.OrderAssembledTopic, cfg.UnavailableRetryDelay, orderMetrics, service.UUIDv7Generator{})
 
 	// Создаём outbox dispatcher для публикации событий из outbox таблицы
 	var outboxDispatcher *eventkafka.OutboxDispatcher
@@ -161,6 +224,41 @@ func Build(cfg config.Config) (*App, error) {
 		logger.Warn("Kafka brokers or topic not configured, outbox dispatcher will not be started")
 	}
 
+	// Сверка outbox с Kafka: sent события, которых нет в топике, публикуются повторно
+	var outboxReconciler *eventkafka.OutboxReconciler
+	if outboxDispatcher != nil && cfg.OutboxReconcileEnabled {
+		var reconcileMetrics eventkafka.OutboxReconcileMetrics
+		if cfg.OTelEnabled {
+			reconcileMetrics = newOutboxReconcileMetricsRecorder()
+		}
+		outboxReconciler = eventkafka.NewOutboxReconciler(
+			logger,
+			orderRepo,
+			eventkafka.NewKafkaOutboxTopicReader(cfg.Brokers, 10*time.Second),
+			reconcileMetrics,
+			cfg.OutboxReconcileInterval,
+			cfg.OutboxReconcileWindow,
+			cfg.OutboxStuckAfter,
+		)
+	}
+
+	// Webhook'и пользователей: callback'и ставятся в очередь репозиторием при смене статуса заказа
+	webhookService := service.NewWebhookService(logger, orderRepo)
+	var webhookDispatch *webhook.Dispatcher
+	if cfg.WebhookDispatchEnabled {
+		webhookDispatch = webhook.NewDispatcher(
+			logger,
+			orderRepo,
+			webhook.NewHTTPClient(cfg.WebhookTimeout), // только публичные адреса, без redirect'ов
+			50, // batch size
+			cfg.WebhookDispatchInterval,
+			cfg.WebhookMaxAttempts,
+			cfg.WebhookRetryBackoffBase,
+		)
+	} else {
+		logger.Warn("Webhook dispatch disabled, order status callbacks will not be sent")
+	}
+
 	// Создаём Kafka consumer для событий завершения сборки заказа
 	var assemblyConsumer *eventkafka.OrderAssemblyCompletedConsumer
 	if len(cfg.Brokers) > 0 && cfg.AssemblyCompletedTopic != "" {
@@ -183,10 +281,31 @@ func Build(cfg config.Config) (*App, error) {
 	}
 
 	// Создаем HTTP handler
-	handler := httpapi.NewHandler(orderService, logger)
+	// Статистика заказов пользователя (поддержка, лояльность): агрегаты SQL по окнам ORDER_STATS_WINDOWS
+	statsService := service.NewOrderStatsService(logger, orderRepo, statsWindows)
+
+	handler := httpapi.NewHandler(orderService, webhookService, statsService, logger)
+	// Фоновые обработчики, которые админ может приостановить через /admin/consumers/{name}/pause
+	if assemblyConsumer != nil {
+		handler.RegisterWorker(workerAssemblyConsumer, assemblyConsumer)
+	}
+	if outboxDispatcher != nil {
+		handler.RegisterWorker(workerOutboxDispatcher, outboxDispatcher)
+	}
 
 	// Настраиваем роутер (observability HTTP middleware добавляет trace_id в контекст и лог)
-	router := httpapi.NewRouter(handler, readiness, logger)
+	sessions := grpcclient.NewIAMSessionValidator(iampb.NewIAMServiceClient(iamConn))
+	var rateLimiter *ratelimit.Limiter
+	if cfg.RateLimitEnabled {
+		rateLimiter = ratelimit.NewLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst)
+		logger.Info("Order creation rate limit enabled",
+			zap.Float64("rps", cfg.RateLimitRPS),
+			zap.Int("burst", cfg.RateLimitBurst),
+		)
+	} else {
+		logger.Warn("Order creation rate limit disabled")
+	}
+	router := httpapi.NewRouter(handler, readiness, rateLimiter, sessions, cfg.AdminToken, logger)
 
 	// Создаём HTTP сервер
 	httpServer := &http.Server{
@@ -202,6 +321,9 @@ func Build(cfg config.Config) (*App, error) {
 
 	// Регистрируем shutdown функции в обратном порядке выполнения
 	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
 	if assemblyConsumer != nil {
 		shutdownMgr.Add("kafka_assembly_consumer", func(ctx context.Context) error {
 			return assemblyConsumer.Close()
@@ -224,17 +346,35 @@ func Build(cfg config.Config) (*App, error) {
 		paymentConn.Close()
 		return nil
 	})
+	shutdownMgr.Add("iam_conn", func(ctx context.Context) error {
+		closeIAMConn()
+		return nil
+	})
 
 	return &App{
 		logger:           logger,
 		httpServer:       httpServer,
 		assemblyConsumer: assemblyConsumer,
 		outboxDispatcher: outboxDispatcher,
+		outboxReconciler: outboxReconciler,
+		webhookDispatch:  webhookDispatch,
 		shutdownMgr:      shutdownMgr,
 		readiness:        readiness,
 	}, nil
 }
 
+// callPolicy собирает ограничения вызовов зависимости: таймаут и повторы свои, бюджет и breaker общие
+func callPolicy(cfg config.Config, timeout time.Duration, maxRetries int) grpcclient.CallPolicy {
+	return grpcclient.CallPolicy{
+		Timeout:                 timeout,
+		MaxRetries:              maxRetries,
+		RetryBackoff:            cfg.GRPCRetryBackoff,
+		RetryBudgetRatio:        cfg.GRPCRetryBudgetRatio,
+		BreakerFailureThreshold: cfg.GRPCBreakerFailureThreshold,
+		BreakerOpenTimeout:      cfg.GRPCBreakerOpenTimeout,
+	}
+}
+
 // Run запускает сервис и блокируется до получения сигнала shutdown
 func (a *App) Run() error {
 	defer platformlogging.Sync(a.logger)
@@ -280,6 +420,32 @@ func (a *App) Run() error {
 		a.logger.Info("Outbox dispatcher started")
 	}
 
+	// Запускаем сверку outbox с Kafka (если включена)
+	if a.outboxReconciler != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.outboxReconciler.Start(consumerCtx); err != nil {
+				a.logger.Error("outbox reconciler error", zap.Error(err))
+			}
+		}()
+
+		a.logger.Info("Outbox reconciler started")
+	}
+
+	// Запускаем доставку callback'ов на webhook'и (если включена)
+	if a.webhookDispatch != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.webhookDispatch.Start(consumerCtx); err != nil {
+				a.logger.Error("webhook dispatcher error", zap.Error(err))
+			}
+		}()
+
+		a.logger.Info("Webhook dispatcher started")
+	}
+
 	// Ожидаем сигнал и выполняем shutdown
 	a.shutdownMgr.Wait()
 
@@ -295,18 +461,52 @@ func (a *App) Run() error {
 
 // orderMetricsRecorder реализует service.OrderMetricsRecorder через OpenTelemetry Meter.
 type orderMetricsRecorder struct {
-	ordersCreated metric.Int64Counter
-	orderRevenue  metric.Int64Counter
+	ordersCreated  metric.Int64Counter
+	orderRevenue   metric.Int64Counter
+	itemReadErrors metric.Int64Counter
 }
 
 func newOrderMetricsRecorder() *orderMetricsRecorder {
 	meter := otel.Meter("order")
 	ordersCreated, _ := meter.Int64Counter("orders_created_total", metric.WithDescription("Total orders created"))
 	orderRevenue, _ := meter.Int64Counter("order_revenue_total", metric.WithDescription("Total order revenue in cents"))
-	return &orderMetricsRecorder{ordersCreated: ordersCreated, orderRevenue: orderRevenue}
+	itemReadErrors, _ := meter.Int64Counter("order_items_read_errors_total", metric.WithDescription("Orders returned without items because order items could not be read"))
+	return &orderMetricsRecorder{ordersCreated: ordersCreated, orderRevenue: orderRevenue, itemReadErrors: itemReadErrors}
 }
 
 func (r *orderMetricsRecorder) RecordOrderCreated(revenueCents int64) {
 	r.ordersCreated.Add(context.Background(), 1, metric.WithAttributes(attribute.String("status", "success")))
 	r.orderRevenue.Add(context.Background(), revenueCents, metric.WithAttributes(attribute.String("status", "success")))
 }
+
+func (r *orderMetricsRecorder) RecordOrderItemsReadError() {
+	r.itemReadErrors.Add(context.Background(), 1)
+}
+
+// outboxReconcileMetricsRecorder реализует eventkafka.OutboxReconcileMetrics через OpenTelemetry Meter.
+type outboxReconcileMetricsRecorder struct {
+	missing         metric.Int64Counter
+	stuck           metric.Int64Gauge
+	reconcileErrors metric.Int64Counter
+}
+
+func newOutboxReconcileMetricsRecorder() *outboxReconcileMetricsRecorder {
+	meter := otel.Meter("order")
+	missing, _ := meter.Int64Counter("outbox_reconcile_missing_total", metric.WithDescription("Outbox events marked sent but not found in Kafka"))
+	stuck, _ := meter.Int64Gauge("outbox_stuck_events", metric.WithDescription("Outbox events stuck in pending or failed status"))
+	reconcileErrors, _ := meter.Int64Counter("outbox_reconcile_errors_total", metric.WithDescription("Failed outbox reconcile runs"))
+	return &outboxReconcileMetricsRecorder{missing: missing, stuck: stuck, reconcileErrors: reconcileErrors}
+}
+
+func (r *outboxReconcileMetricsRecorder) RecordMissing(topic string, count int) {
+	r.missing.Add(context.Background(), int64(count), metric.WithAttributes(attribute.String("topic", topic)))
+}
+
+func (r *outboxReconcileMetricsRecorder) RecordBacklog(backlog repository.OutboxBacklog) {
+	r.stuck.Record(context.Background(), backlog.StalePending, metric.WithAttributes(attribute.String("status", "pending")))
+	r.stuck.Record(context.Background(), backlog.Failed, metric.WithAttributes(attribute.String("status", "failed")))
+}
+
+func (r *outboxReconcileMetricsRecorder) RecordReconcileError() {
+	r.reconcileErrors.Add(context.Background(), 1)
+}
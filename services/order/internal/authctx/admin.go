@@ -0,0 +1,20 @@
+package authctx
+
+import (
+	"context"
+)
+
+type ctxKeyAdmin struct{}
+
+var adminKey = ctxKeyAdmin{}
+
+// WithAdmin помечает запрос как выполненный администратором (используется HTTP middleware)
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey, true)
+}
+
+// IsAdmin возвращает true, если запрос помечен как административный
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
@@ -0,0 +1,34 @@
+package authctx
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidSession возвращается validator'ом сессий, если IAM не знает session_id или сессия истекла
+// Остальные ошибки validator'а - недоступность IAM
+var ErrInvalidSession = errors.New("invalid or expired session")
+
+// RoleAdmin - роль администратора IAM (ValidateSessionResponse.role)
+const RoleAdmin = "admin"
+
+// Session - пользователь сессии и его роль по данным IAM
+type Session struct {
+	UserID string
+	Role   string
+}
+
+type ctxKeyUserID struct{}
+
+var userIDKey = ctxKeyUserID{}
+
+// WithUserID сохраняет пользователя, которому IAM выдал сессию запроса (используется HTTP middleware)
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext возвращает пользователя проверенной сессии, если он был установлен
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
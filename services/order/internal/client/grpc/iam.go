@@ -0,0 +1,67 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	iampb "github.com/shestoi/GoBigTech/services/iam/v1"
+	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/probe"
+)
+
+// IAMProbeAuthenticator логинит пользователя probe через IAM gRPC
+// Реализует probe.Authenticator, чтобы probe не зависел от protobuf типов
+type IAMProbeAuthenticator struct {
+	client   iampb.IAMServiceClient
+	login    string
+	password string
+}
+
+// NewIAMProbeAuthenticator создаёт authenticator для учётной записи probe
+func NewIAMProbeAuthenticator(client iampb.IAMServiceClient, login, password string) probe.Authenticator {
+	return &IAMProbeAuthenticator{
+		client:   client,
+		login:    login,
+		password: password,
+	}
+}
+
+// Login реализует probe.Authenticator: каждый прогон получает новую сессию
+func (a *IAMProbeAuthenticator) Login(ctx context.Context) (probe.Session, error) {
+	resp, err := a.client.Login(ctx, &iampb.LoginRequest{
+		Login:    a.login,
+		Password: a.password,
+	})
+	if err != nil {
+		return probe.Session{}, err
+	}
+	return probe.Session{UserID: resp.UserId, SessionID: resp.SessionId}, nil
+}
+
+// IAMSessionValidator определяет пользователя по session_id через IAM gRPC
+// Реализует middleware.SessionValidator: ключ rate limit и владелец webhook'ов - пользователь сессии, а не поле запроса,
+// роль admin открывает /admin/*
+type IAMSessionValidator struct {
+	client iampb.IAMServiceClient
+}
+
+// NewIAMSessionValidator создаёт validator сессий поверх IAM клиента
+func NewIAMSessionValidator(client iampb.IAMServiceClient) *IAMSessionValidator {
+	return &IAMSessionValidator{client: client}
+}
+
+// ValidateSession возвращает пользователя сессии и его роль
+// Невалидная или истёкшая сессия - authctx.ErrInvalidSession, остальные ошибки IAM возвращаются как есть
+func (v *IAMSessionValidator) ValidateSession(ctx context.Context, sessionID string) (authctx.Session, error) {
+	resp, err := v.client.ValidateSession(ctx, &iampb.ValidateSessionRequest{SessionId: sessionID})
+	if err != nil {
+		if code := status.Code(err); code == codes.Unauthenticated || code == codes.InvalidArgument {
+			return authctx.Session{}, fmt.Errorf("%w: %v", authctx.ErrInvalidSession, err)
+		}
+		return authctx.Session{}, err
+	}
+	return authctx.Session{UserID: resp.GetUserId(), Role: resp.GetRole()}, nil
+}
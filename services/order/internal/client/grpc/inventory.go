@@ -2,6 +2,7 @@ package grpcclient
 
 import (
 	"context"
+	"fmt"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -9,6 +10,7 @@ import (
 
 	inventorypb "github.com/shestoi/GoBigTech/services/inventory/v1"
 	"github.com/shestoi/GoBigTech/services/order/internal/authctx"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
 )
 
@@ -25,28 +27,35 @@ func NewInventoryClientAdapter(client inventorypb.InventoryServiceClient) servic
 	}
 }
 
-// ReserveStock реализует service.InventoryClient интерфейс
+// ReserveStockBatch реализует service.InventoryClient интерфейс
 // Прокидывает x-session-id из context в gRPC metadata для Inventory interceptor
-func (a *InventoryClientAdapter) ReserveStock(ctx context.Context, productID string, quantity int32) error {
+// Резервы создаются без срока: заказ оплачивается в том же запросе
+func (a *InventoryClientAdapter) ReserveStockBatch(ctx context.Context, orderID string, items []domain.Line) error {
 	sid, ok := authctx.SessionIDFromContext(ctx) // извлекаем session_id из контекста
 	if !ok || sid == "" {
 		return status.Error(codes.Unauthenticated, "session_id is required")
 	}
 	ctx = metadata.AppendToOutgoingContext(ctx, "x-session-id", sid) // добавляем session_id в metadata
 
-	req := &inventorypb.ReserveStockRequest{ // создаём запрос на резервирование товара
-		ProductId: productID, // id товара
-		Quantity:  quantity, // количество товара
+	req := &inventorypb.ReserveStockBatchRequest{ // создаём запрос на резервирование всех позиций заказа
+		OrderId: orderID,
+		Items:   make([]*inventorypb.ReserveStockItem, 0, len(items)),
+	}
+	for _, item := range items {
+		req.Items = append(req.Items, &inventorypb.ReserveStockItem{
+			ProductId: item.ProductID, // id товара
+			Quantity:  item.Quantity,  // количество товара
+		})
 	}
 
-	resp, err := a.client.ReserveStock(ctx, req) // вызываем gRPC метод на резервирование товара
+	resp, err := a.client.ReserveStockBatch(ctx, req) // вызываем gRPC метод пакетного резервирования
 	if err != nil {
 		return err
 	}
 
-	// Проверяем успешность резервирования
+	// Проверяем успешность резервирования: при неудаче Inventory ничего не зарезервировал
 	if !resp.Success {
-		return &ReservationError{Message: "failed to reserve stock"}
+		return &ReservationError{Message: fmt.Sprintf("insufficient stock for product %s", resp.InsufficientProductId)}
 	}
 
 	return nil
@@ -3,6 +3,8 @@ package grpcclient
 import (
 	"context"
 
+	"google.golang.org/grpc/status"
+
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
 	paymentpb "github.com/shestoi/GoBigTech/services/payment/v1"
 )
@@ -22,18 +24,23 @@ func NewPaymentClientAdapter(client paymentpb.PaymentServiceClient) service.Paym
 
 // ProcessPayment реализует service.PaymentClient интерфейс
 // Преобразует простые типы в protobuf структуры и обратно
-func (a *PaymentClientAdapter) ProcessPayment(ctx context.Context, orderID, userID string, amount float64, method string) (string, error) {
+func (a *PaymentClientAdapter) ProcessPayment(ctx context.Context, orderID, userID string, amount int64, currency, method string) (string, error) {
 	// Преобразуем простые типы в protobuf запрос
 	req := &paymentpb.ProcessPaymentRequest{
-		OrderId: orderID,
-		UserId:  userID,
-		Amount:  amount,
-		Method:  method,
+		OrderId:  orderID,
+		UserId:   userID,
+		Amount:   amount,
+		Method:   method,
+		Currency: currency,
 	}
 
 	// Вызываем gRPC клиент
 	resp, err := a.client.ProcessPayment(ctx, req)
 	if err != nil {
+		// Отказ в оплате приходит деталями PaymentDeclined в gRPC статусе
+		if declined := paymentDeclinedFromError(err); declined != nil {
+			return "", declined
+		}
 		return "", err
 	}
 
@@ -46,6 +53,31 @@ func (a *PaymentClientAdapter) ProcessPayment(ctx context.Context, orderID, user
 	return resp.TransactionId, nil
 }
 
+// declineReasons сопоставляет protobuf enum причины отказа с причинами service слоя
+var declineReasons = map[paymentpb.DeclineReason]string{
+	paymentpb.DeclineReason_DECLINE_REASON_INSUFFICIENT_FUNDS: service.DeclineReasonInsufficientFunds,
+	paymentpb.DeclineReason_DECLINE_REASON_LIMIT_EXCEEDED:     service.DeclineReasonLimitExceeded,
+	paymentpb.DeclineReason_DECLINE_REASON_RISK_DECLINED:      service.DeclineReasonRiskDeclined,
+	paymentpb.DeclineReason_DECLINE_REASON_PROVIDER_ERROR:     service.DeclineReasonProviderError,
+}
+
+// paymentDeclinedFromError извлекает PaymentDeclined из деталей gRPC статуса; nil - это не отказ в оплате
+func paymentDeclinedFromError(err error) *service.PaymentDeclinedError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if declined, ok := detail.(*paymentpb.PaymentDeclined); ok {
+			return &service.PaymentDeclinedError{
+				Reason:  declineReasons[declined.GetReason()],
+				Message: declined.GetMessage(),
+			}
+		}
+	}
+	return nil
+}
+
 // PaymentError представляет ошибку обработки оплаты
 type PaymentError struct {
 	Message string
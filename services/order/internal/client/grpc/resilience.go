@@ -0,0 +1,272 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+)
+
+// ErrCircuitOpen возвращается без вызова зависимости, пока её circuit breaker открыт
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// maxRetryTokens - сколько повторов может накопить бюджет (всплеск после долгой спокойной работы)
+const maxRetryTokens = 10
+
+// CallPolicy - ограничения вызовов одной зависимости (Inventory, Payment)
+type CallPolicy struct {
+	Timeout          time.Duration // дедлайн одной попытки; 0 - только дедлайн запроса
+	MaxRetries       int           // повторы при codes.Unavailable без деталей (соединение не установлено или оборвано)
+	RetryBackoff     time.Duration // пауза перед повтором, растёт линейно с номером попытки
+	RetryBudgetRatio float64       // повторов не больше этой доли от числа вызовов (например 0.1 - 10%)
+
+	BreakerFailureThreshold int           // столько неудач подряд открывают breaker
+	BreakerOpenTimeout      time.Duration // сколько breaker открыт до пробного вызова
+}
+
+// ResilienceInterceptor ограничивает вызовы зависимости name: таймаут на попытку, повторы в пределах бюджета
+// и circuit breaker. Пока breaker открыт, вызовы сразу завершаются ErrCircuitOpen, не дожидаясь зависшего сервиса.
+//
+// Неудачей зависимости считаются codes.Unavailable и codes.DeadlineExceeded без деталей в статусе.
+// Ответ с деталями (например, PaymentDeclined с причиной provider_error) - результат обработки, а не сбой.
+// Неудача и отказ открытого breaker возвращаются как *service.DependencyUnavailableError (исходная ошибка - в Err).
+func ResilienceInterceptor(logger *zap.Logger, name string, policy CallPolicy) grpc.UnaryClientInterceptor {
+	breaker := newCircuitBreaker(policy.BreakerFailureThreshold, policy.BreakerOpenTimeout, time.Now)
+	budget := newRetryBudget(policy.RetryBudgetRatio)
+	logger = logger.With(zap.String("dependency", name))
+
+	// unavailable оборачивает сбой зависимости в service.DependencyUnavailableError с подсказкой, когда повторить:
+	// до пробного вызова, если breaker открыт, иначе следующий шаг backoff
+	unavailable := func(err error, failed bool, attempt int) error {
+		if !failed {
+			return err
+		}
+		retryAfter := breaker.retryAfter()
+		if retryAfter == 0 {
+			retryAfter = policy.RetryBackoff * time.Duration(attempt+1)
+		}
+		return &service.DependencyUnavailableError{Dependency: name, RetryAfter: retryAfter, Err: err}
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		budget.deposit()
+
+		for attempt := 0; ; attempt++ {
+			if !breaker.allow() {
+				return &service.DependencyUnavailableError{
+					Dependency:  name,
+					RetryAfter:  breaker.retryAfter(),
+					BreakerOpen: true,
+					Err:         ErrCircuitOpen,
+				}
+			}
+
+			callCtx, cancel := ctx, context.CancelFunc(func() {})
+			if policy.Timeout > 0 {
+				callCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+			}
+			err := invoker(callCtx, method, req, reply, cc, opts...)
+			cancel()
+
+			// Отмена запроса клиентом - не вина зависимости
+			failed := ctx.Err() == nil && isDependencyFailure(err)
+			if from, to, changed := breaker.record(!failed); changed {
+				logger.Warn("circuit breaker state changed",
+					zap.String("method", method),
+					zap.String("from", from.String()),
+					zap.String("to", to.String()),
+					zap.Error(err),
+				)
+			}
+
+			if err == nil || ctx.Err() != nil || attempt >= policy.MaxRetries || !isRetryable(err) {
+				return unavailable(err, failed, attempt)
+			}
+			if !budget.withdraw() {
+				logger.Debug("retry budget exhausted", zap.String("method", method))
+				return unavailable(err, failed, attempt)
+			}
+
+			logger.Warn("retrying call",
+				zap.String("method", method),
+				zap.Int("attempt", attempt+1),
+				zap.Error(err),
+			)
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(policy.RetryBackoff * time.Duration(attempt+1)):
+			}
+		}
+	}
+}
+
+// isDependencyFailure сообщает, что зависимость недоступна или не ответила вовремя
+func isDependencyFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+	if len(st.Details()) > 0 {
+		return false
+	}
+	return st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded
+}
+
+// isRetryable сообщает, что запрос стоит повторить: Unavailable без деталей - соединение не установлено
+// или оборвано. Обычно запрос не дошёл до обработчика, но при обрыве мог выполниться - повторять можно
+// только идемпотентные вызовы (ProcessPayment идемпотентен по order_id)
+func isRetryable(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unavailable && len(st.Details()) == 0
+}
+
+// breakerState - состояние circuit breaker
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // вызовы проходят
+	breakerOpen                         // вызовы отклоняются до истечения openTimeout
+	breakerHalfOpen                     // пропускается один пробный вызов
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker открывается после failureThreshold неудач подряд; через openTimeout пропускает один
+// пробный вызов: успех закрывает breaker, неудача снова открывает. failureThreshold <= 0 - breaker выключен.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool // пробный вызов в half-open уже выполняется
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration, now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              now,
+	}
+}
+
+// allow решает, можно ли выполнить вызов
+func (b *circuitBreaker) allow() bool {
+	if b.failureThreshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// retryAfter возвращает, через сколько breaker пропустит следующий вызов: остаток openTimeout, если breaker
+// открыт, и весь openTimeout, пока выполняется пробный вызов (его неудача снова откроет breaker).
+// 0 - breaker закрыт или выключен
+func (b *circuitBreaker) retryAfter() time.Duration {
+	if b.failureThreshold <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		return max(b.openTimeout-b.now().Sub(b.openedAt), 0)
+	case breakerHalfOpen:
+		return b.openTimeout
+	default:
+		return 0
+	}
+}
+
+// record учитывает результат вызова и возвращает смену состояния, если она произошла
+func (b *circuitBreaker) record(success bool) (from, to breakerState, changed bool) {
+	if b.failureThreshold <= 0 {
+		return breakerClosed, breakerClosed, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = breakerClosed
+	} else {
+		b.failures++
+		if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	}
+	return from, b.state, from != b.state
+}
+
+// retryBudget ограничивает долю повторов: каждый вызов добавляет ratio токена, повтор тратит один.
+// Так при массовом отказе зависимости повторы не умножают нагрузку на неё.
+type retryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{ratio: ratio, tokens: maxRetryTokens}
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, maxRetryTokens)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,144 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/service"
+	paymentpb "github.com/shestoi/GoBigTech/services/payment/v1"
+)
+
+// fakeInvoker возвращает ошибки по очереди и считает вызовы
+type fakeInvoker struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeInvoker) invoke(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	if len(f.errs) > 1 {
+		f.errs = f.errs[1:]
+	}
+	return err
+}
+
+func TestResilienceInterceptor(t *testing.T) {
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	call := func(interceptor grpc.UnaryClientInterceptor, invoker *fakeInvoker) error {
+		return interceptor(ctx, "/inventory.v1.InventoryService/ReserveStock", nil, nil, nil, invoker.invoke)
+	}
+
+	t.Run("unavailable is retried", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "inventory", CallPolicy{MaxRetries: 2, RetryBudgetRatio: 0.1})
+		invoker := &fakeInvoker{errs: []error{unavailable, nil}}
+
+		require.NoError(t, call(interceptor, invoker))
+		require.Equal(t, 2, invoker.calls)
+	})
+
+	t.Run("business errors are not retried", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "inventory", CallPolicy{MaxRetries: 2})
+		invoker := &fakeInvoker{errs: []error{status.Error(codes.FailedPrecondition, "out of stock")}}
+
+		require.Error(t, call(interceptor, invoker))
+		require.Equal(t, 1, invoker.calls)
+	})
+
+	t.Run("retry budget limits retries", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "inventory", CallPolicy{MaxRetries: 1})
+		invoker := &fakeInvoker{errs: []error{unavailable}}
+
+		// Бюджет без пополнения (ratio 0) позволяет только maxRetryTokens повторов
+		for i := 0; i < maxRetryTokens+5; i++ {
+			require.Error(t, call(interceptor, invoker))
+		}
+		require.Equal(t, maxRetryTokens+5+maxRetryTokens, invoker.calls)
+	})
+
+	t.Run("timeout per attempt", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "payment", CallPolicy{Timeout: 10 * time.Millisecond})
+		hung := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+
+		start := time.Now()
+		err := interceptor(ctx, "/payment.v1.PaymentService/ProcessPayment", nil, nil, nil, hung)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("breaker opens and recovers", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "payment", CallPolicy{
+			BreakerFailureThreshold: 2,
+			BreakerOpenTimeout:      20 * time.Millisecond,
+		})
+		invoker := &fakeInvoker{errs: []error{unavailable, unavailable, nil}}
+
+		require.Error(t, call(interceptor, invoker))
+		require.Error(t, call(interceptor, invoker))
+		// Breaker открыт: вызов не доходит до зависимости
+		require.ErrorIs(t, call(interceptor, invoker), ErrCircuitOpen)
+		require.Equal(t, 2, invoker.calls)
+
+		time.Sleep(30 * time.Millisecond)
+		require.NoError(t, call(interceptor, invoker)) // пробный вызов закрывает breaker
+		require.NoError(t, call(interceptor, invoker))
+		require.Equal(t, 4, invoker.calls)
+	})
+
+	t.Run("failures carry retry after from backoff and breaker state", func(t *testing.T) {
+		interceptor := ResilienceInterceptor(zap.NewNop(), "payment", CallPolicy{
+			RetryBackoff:            100 * time.Millisecond,
+			BreakerFailureThreshold: 2,
+			BreakerOpenTimeout:      time.Minute,
+		})
+		invoker := &fakeInvoker{errs: []error{unavailable}}
+
+		// Breaker ещё закрыт: повторить через следующий шаг backoff
+		var unavailableErr *service.DependencyUnavailableError
+		err := call(interceptor, invoker)
+		require.ErrorAs(t, err, &unavailableErr)
+		require.Equal(t, "payment", unavailableErr.Dependency)
+		require.Equal(t, 100*time.Millisecond, unavailableErr.RetryAfter)
+		require.False(t, unavailableErr.BreakerOpen)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		// Вторая неудача открывает breaker: повторить после openTimeout
+		require.ErrorAs(t, call(interceptor, invoker), &unavailableErr)
+		require.InDelta(t, time.Minute, unavailableErr.RetryAfter, float64(time.Second))
+
+		err = call(interceptor, invoker)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.ErrorAs(t, err, &unavailableErr)
+		require.True(t, unavailableErr.BreakerOpen)
+		require.Greater(t, unavailableErr.RetryAfter, 50*time.Second)
+		require.Equal(t, 2, invoker.calls)
+	})
+
+	t.Run("declines with details do not open breaker", func(t *testing.T) {
+		st, err := status.New(codes.Unavailable, "provider error").WithDetails(&paymentpb.PaymentDeclined{
+			Reason: paymentpb.DeclineReason_DECLINE_REASON_PROVIDER_ERROR,
+		})
+		require.NoError(t, err)
+
+		interceptor := ResilienceInterceptor(zap.NewNop(), "payment", CallPolicy{MaxRetries: 2, BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Minute})
+		invoker := &fakeInvoker{errs: []error{st.Err()}}
+		for i := 0; i < 3; i++ {
+			require.Equal(t, codes.Unavailable, status.Code(call(interceptor, invoker)))
+		}
+		require.Equal(t, 3, invoker.calls)
+	})
+}
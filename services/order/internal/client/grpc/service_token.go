@@ -0,0 +1,19 @@
+package grpcclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ServiceTokenHeader ключ, в котором Payment ждёт токен вызывающего сервиса
+const ServiceTokenHeader = "x-service-token"
+
+// ServiceTokenInterceptor передаёт токен Order в metadata каждого вызова: по нему Payment пускает к своему API
+func ServiceTokenInterceptor(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, ServiceTokenHeader, token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
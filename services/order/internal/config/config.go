@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
+
+	platformdiscovery "github.com/shestoi/GoBigTech/platform/discovery"
 )
 
 // Env представляет окружение приложения
@@ -24,20 +27,64 @@ type Config struct {
 	PostgresDSN       string
 	InventoryGRPCAddr string
 	PaymentGRPCAddr   string
+	Discovery         platformdiscovery.Config // GRPC_DISCOVERY_*, GRPC_CLIENT_*: статический список или SRV, round_robin между инстансами
 	ShutdownTimeout   time.Duration
+	DebugAddr         string // DEBUG_ADDR: отладочный сервер (pprof, expvar), только loopback; пусто - выключен
+
+	// PaymentServiceToken - токен Order для Payment (x-service-token), должен совпадать с order= в PAYMENT_AUTH_TOKENS;
+	// пусто - токен не передаётся (Payment с PAYMENT_AUTH_DISABLED)
+	PaymentServiceToken string
+
+	// Ограничения вызовов Inventory/Payment (grpcclient.CallPolicy)
+	InventoryCallTimeout        time.Duration // дедлайн одной попытки ReserveStockBatch
+	InventoryMaxRetries         int
+	PaymentCallTimeout          time.Duration // дедлайн одной попытки ProcessPayment
+	PaymentMaxRetries           int           // повтор безопасен: Payment идемпотентен по order_id
+	GRPCRetryBackoff            time.Duration
+	GRPCRetryBudgetRatio        float64       // доля повторов от числа вызовов
+	GRPCBreakerFailureThreshold int           // неудач подряд до открытия breaker; 0 - breaker выключен
+	GRPCBreakerOpenTimeout      time.Duration // сколько breaker открыт до пробного вызова
+	UnavailableRetryDelay       time.Duration // пауза перед повтором резервирования или оплаты при недоступном Inventory/Payment (с разбросом); 0 - без повтора
 
 	// Kafka
 	Brokers                          []string      //список брокеров Kafka
 	PaymentCompletedTopic            string        //топик для оплаты заказа
+	PaymentDeclinedTopic             string        //топик для событий отказа в оплате
 	AssemblyCompletedTopic           string        //топик для событий завершения сборки заказа
+	OrderAssembledTopic              string        //топик для событий order.assembled (заказ собран, публикует Order)
 	OrderConsumerGroupID             string        //consumer group ID для Order Service
 	AssemblyConsumerRetryMaxAttempts int           //максимальное количество попыток retry для assembly consumer
 	AssemblyConsumerRetryBackoffBase time.Duration //базовый интервал для backoff retry
 
+	// Сверка outbox с Kafka (поиск тихих потерь публикации)
+	OutboxReconcileEnabled  bool
+	OutboxReconcileInterval time.Duration // интервал между сверками
+	OutboxReconcileWindow   time.Duration // за какой период сверяются sent события
+	OutboxStuckAfter        time.Duration // через сколько pending событие считается зависшим
+
+	// Доставка callback'ов на webhook'и пользователей
+	WebhookDispatchEnabled  bool
+	WebhookDispatchInterval time.Duration // интервал опроса очереди доставки
+	WebhookMaxAttempts      int           // после стольких неудачных попыток доставка помечается failed
+	WebhookRetryBackoffBase time.Duration // задержка перед второй попыткой, дальше удваивается
+	WebhookTimeout          time.Duration // таймаут HTTP запроса к получателю
+
+	// Окна статистики заказов пользователя по умолчанию (GET /users/{id}/order-stats): 7d, 72h, all
+	OrderStatsWindows []string
+
 	// OpenTelemetry
 	OTelEnabled       bool
 	OTelEndpoint      string
 	OTelSamplingRatio float64
+
+	// Rate limiting создания заказов (по пользователю сессии x-session-id, без сессии - по IP)
+	RateLimitEnabled bool
+	RateLimitRPS     float64 // сколько запросов в секунду восполняется на один ключ
+	RateLimitBurst   int     // сколько запросов подряд разрешено одному ключу
+	IAMGRPCAddr      string  // IAM_GRPC_ADDR: IAM определяет пользователя сессии (ключ лимита, владелец webhook'ов)
+
+	// Админ-доступ (архивация заказов, include_archived); пустой токен - админ-доступ выключен
+	AdminToken string
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -81,6 +128,65 @@ func Load() (Config, error) {
 		cfg.PaymentGRPCAddr = getString("PAYMENT_GRPC_ADDR", "payment:50052")
 	}
 
+	// PAYMENT_SERVICE_TOKEN
+	cfg.PaymentServiceToken = getString("PAYMENT_SERVICE_TOKEN", "")
+
+	// GRPC_DISCOVERY_*, GRPC_CLIENT_*: обнаружение и балансировка инстансов зависимостей
+	cfg.Discovery = platformdiscovery.DefaultConfig()
+	if err := platformdiscovery.LoadEnv(&cfg.Discovery); err != nil {
+		return Config{}, fmt.Errorf("invalid gRPC discovery config: %w", err)
+	}
+
+	// Таймауты, повторы и circuit breaker для Inventory/Payment
+	inventoryCallTimeout, err := time.ParseDuration(getString("INVENTORY_GRPC_TIMEOUT", "2s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_GRPC_TIMEOUT: %w", err)
+	}
+	cfg.InventoryCallTimeout = inventoryCallTimeout
+
+	inventoryMaxRetries, err := parseInt(getString("INVENTORY_GRPC_MAX_RETRIES", "1"), 1)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid INVENTORY_GRPC_MAX_RETRIES: %w", err)
+	}
+	cfg.InventoryMaxRetries = inventoryMaxRetries
+
+	paymentCallTimeout, err := time.ParseDuration(getString("PAYMENT_GRPC_TIMEOUT", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid PAYMENT_GRPC_TIMEOUT: %w", err)
+	}
+	cfg.PaymentCallTimeout = paymentCallTimeout
+
+	paymentMaxRetries, err := parseInt(getString("PAYMENT_GRPC_MAX_RETRIES", "1"), 1)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid PAYMENT_GRPC_MAX_RETRIES: %w", err)
+	}
+	cfg.PaymentMaxRetries = paymentMaxRetries
+
+	grpcRetryBackoff, err := time.ParseDuration(getString("GRPC_RETRY_BACKOFF", "100ms"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GRPC_RETRY_BACKOFF: %w", err)
+	}
+	cfg.GRPCRetryBackoff = grpcRetryBackoff
+	cfg.GRPCRetryBudgetRatio = getFloat64("GRPC_RETRY_BUDGET_RATIO", 0.1)
+
+	breakerFailureThreshold, err := parseInt(getString("GRPC_BREAKER_FAILURE_THRESHOLD", "5"), 5)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GRPC_BREAKER_FAILURE_THRESHOLD: %w", err)
+	}
+	cfg.GRPCBreakerFailureThreshold = breakerFailureThreshold
+
+	breakerOpenTimeout, err := time.ParseDuration(getString("GRPC_BREAKER_OPEN_TIMEOUT", "30s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GRPC_BREAKER_OPEN_TIMEOUT: %w", err)
+	}
+	cfg.GRPCBreakerOpenTimeout = breakerOpenTimeout
+
+	unavailableRetryDelay, err := time.ParseDuration(getString("ORDER_UNAVAILABLE_RETRY_DELAY", "0s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ORDER_UNAVAILABLE_RETRY_DELAY: %w", err)
+	}
+	cfg.UnavailableRetryDelay = unavailableRetryDelay
+
 	// SHUTDOWN_TIMEOUT
 	shutdownTimeoutStr := getString("SHUTDOWN_TIMEOUT", "5s")
 	shutdownTimeout, err := time.ParseDuration(shutdownTimeoutStr)
@@ -89,6 +195,9 @@ func Load() (Config, error) {
 	}
 	cfg.ShutdownTimeout = shutdownTimeout
 
+	// DEBUG_ADDR (например 127.0.0.1:6060); по умолчанию отладочный сервер выключен
+	cfg.DebugAddr = getString("DEBUG_ADDR", "")
+
 	// Kafka
 	brokersStr := getString("KAFKA_BROKERS", "") //получаем список брокеров из переменных окружения
 	if brokersStr != "" {
@@ -113,7 +222,9 @@ func Load() (Config, error) {
 		}
 	}
 	cfg.PaymentCompletedTopic = getString("KAFKA_ORDER_PAYMENT_COMPLETED_TOPIC", "order.payment.completed")
+	cfg.PaymentDeclinedTopic = getString("KAFKA_ORDER_PAYMENT_DECLINED_TOPIC", "order.payment.declined")
 	cfg.AssemblyCompletedTopic = getString("KAFKA_ORDER_ASSEMBLY_COMPLETED_TOPIC", "order.assembly.completed")
+	cfg.OrderAssembledTopic = getString("KAFKA_ORDER_ASSEMBLED_TOPIC", "order.assembled")
 	cfg.OrderConsumerGroupID = getString("KAFKA_ORDER_CONSUMER_GROUP_ID", "order-service")
 
 	// Retry настройки для assembly consumer (order <- order.assembly.completed)
@@ -131,6 +242,59 @@ func Load() (Config, error) {
 	}
 	cfg.AssemblyConsumerRetryBackoffBase = retryBackoffBase
 
+	// Сверка outbox с Kafka
+	cfg.OutboxReconcileEnabled = getBool("OUTBOX_RECONCILE_ENABLED", true)
+	outboxReconcileInterval, err := time.ParseDuration(getString("OUTBOX_RECONCILE_INTERVAL", "5m"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid OUTBOX_RECONCILE_INTERVAL: %w", err)
+	}
+	cfg.OutboxReconcileInterval = outboxReconcileInterval
+
+	outboxReconcileWindow, err := time.ParseDuration(getString("OUTBOX_RECONCILE_WINDOW", "1h"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid OUTBOX_RECONCILE_WINDOW: %w", err)
+	}
+	cfg.OutboxReconcileWindow = outboxReconcileWindow
+
+	outboxStuckAfter, err := time.ParseDuration(getString("OUTBOX_STUCK_AFTER", "5m"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid OUTBOX_STUCK_AFTER: %w", err)
+	}
+	cfg.OutboxStuckAfter = outboxStuckAfter
+
+	// Доставка callback'ов на webhook'и
+	cfg.WebhookDispatchEnabled = getBool("WEBHOOK_DISPATCH_ENABLED", true)
+	webhookDispatchInterval, err := time.ParseDuration(getString("WEBHOOK_DISPATCH_INTERVAL", "2s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL: %w", err)
+	}
+	cfg.WebhookDispatchInterval = webhookDispatchInterval
+
+	webhookMaxAttempts, err := parseInt(getString("WEBHOOK_MAX_ATTEMPTS", "5"), 5)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS: %w", err)
+	}
+	cfg.WebhookMaxAttempts = webhookMaxAttempts
+
+	webhookRetryBackoffBase, err := time.ParseDuration(getString("WEBHOOK_RETRY_BACKOFF_BASE", "10s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_RETRY_BACKOFF_BASE: %w", err)
+	}
+	cfg.WebhookRetryBackoffBase = webhookRetryBackoffBase
+
+	webhookTimeout, err := time.ParseDuration(getString("WEBHOOK_TIMEOUT", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_TIMEOUT: %w", err)
+	}
+	cfg.WebhookTimeout = webhookTimeout
+
+	// Статистика заказов
+	for _, window := range strings.Split(getString("ORDER_STATS_WINDOWS", "7d,30d,365d,all"), ",") {
+		if window = strings.TrimSpace(window); window != "" {
+			cfg.OrderStatsWindows = append(cfg.OrderStatsWindows, window)
+		}
+	}
+
 	// OpenTelemetry
 	cfg.OTelEnabled = getBool("OTEL_ENABLED", false)
 	if cfg.AppEnv == EnvLocal {
@@ -140,6 +304,23 @@ func Load() (Config, error) {
 	}
 	cfg.OTelSamplingRatio = getFloat64("OTEL_SAMPLING_RATIO", 1.0)
 
+	// Rate limiting
+	cfg.RateLimitEnabled = getBool("ORDER_RATE_LIMIT_ENABLED", true)
+	cfg.RateLimitRPS = getFloat64("ORDER_RATE_LIMIT_RPS", 1.0)
+	rateLimitBurst, err := parseInt(getString("ORDER_RATE_LIMIT_BURST", "5"), 5)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ORDER_RATE_LIMIT_BURST: %w", err)
+	}
+	cfg.RateLimitBurst = rateLimitBurst
+	if cfg.AppEnv == EnvLocal {
+		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "127.0.0.1:50053")
+	} else {
+		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "iam:50053")
+	}
+
+	// Админ-доступ
+	cfg.AdminToken = getString("ORDER_ADMIN_TOKEN", "")
+
 	// Валидация
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
@@ -162,6 +343,36 @@ func (c Config) Validate() error {
 	if c.PaymentGRPCAddr == "" {
 		return fmt.Errorf("PAYMENT_GRPC_ADDR is required")
 	}
+	if err := c.Discovery.Validate(); err != nil {
+		return err
+	}
+	if c.InventoryCallTimeout <= 0 {
+		return fmt.Errorf("INVENTORY_GRPC_TIMEOUT must be positive")
+	}
+	if c.InventoryMaxRetries < 0 {
+		return fmt.Errorf("INVENTORY_GRPC_MAX_RETRIES must not be negative")
+	}
+	if c.PaymentCallTimeout <= 0 {
+		return fmt.Errorf("PAYMENT_GRPC_TIMEOUT must be positive")
+	}
+	if c.PaymentMaxRetries < 0 {
+		return fmt.Errorf("PAYMENT_GRPC_MAX_RETRIES must not be negative")
+	}
+	if c.GRPCRetryBackoff < 0 {
+		return fmt.Errorf("GRPC_RETRY_BACKOFF must not be negative")
+	}
+	if c.GRPCRetryBudgetRatio < 0 || c.GRPCRetryBudgetRatio > 1 {
+		return fmt.Errorf("GRPC_RETRY_BUDGET_RATIO must be in [0, 1]")
+	}
+	if c.GRPCBreakerFailureThreshold < 0 {
+		return fmt.Errorf("GRPC_BREAKER_FAILURE_THRESHOLD must not be negative")
+	}
+	if c.GRPCBreakerFailureThreshold > 0 && c.GRPCBreakerOpenTimeout <= 0 {
+		return fmt.Errorf("GRPC_BREAKER_OPEN_TIMEOUT must be positive")
+	}
+	if c.UnavailableRetryDelay < 0 {
+		return fmt.Errorf("ORDER_UNAVAILABLE_RETRY_DELAY must not be negative")
+	}
 	if c.ShutdownTimeout <= 0 {
 		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive")
 	}
@@ -171,9 +382,15 @@ func (c Config) Validate() error {
 	if c.PaymentCompletedTopic == "" {
 		return fmt.Errorf("KAFKA_ORDER_PAYMENT_COMPLETED_TOPIC is required")
 	}
+	if c.PaymentDeclinedTopic == "" {
+		return fmt.Errorf("KAFKA_ORDER_PAYMENT_DECLINED_TOPIC is required")
+	}
 	if c.AssemblyCompletedTopic == "" {
 		return fmt.Errorf("KAFKA_ORDER_ASSEMBLY_COMPLETED_TOPIC is required")
 	}
+	if c.OrderAssembledTopic == "" {
+		return fmt.Errorf("KAFKA_ORDER_ASSEMBLED_TOPIC is required")
+	}
 	if c.OrderConsumerGroupID == "" {
 		return fmt.Errorf("KAFKA_ORDER_CONSUMER_GROUP_ID is required")
 	}
@@ -183,9 +400,42 @@ func (c Config) Validate() error {
 	if c.AssemblyConsumerRetryBackoffBase <= 0 {
 		return fmt.Errorf("ORDER_KAFKA_RETRY_BACKOFF_BASE must be positive")
 	}
+	if c.OutboxReconcileEnabled && c.OutboxReconcileInterval <= 0 {
+		return fmt.Errorf("OUTBOX_RECONCILE_INTERVAL must be positive")
+	}
+	if c.OutboxReconcileEnabled && c.OutboxReconcileWindow <= 0 {
+		return fmt.Errorf("OUTBOX_RECONCILE_WINDOW must be positive")
+	}
+	if c.OutboxReconcileEnabled && c.OutboxStuckAfter <= 0 {
+		return fmt.Errorf("OUTBOX_STUCK_AFTER must be positive")
+	}
+	if c.WebhookDispatchEnabled && c.WebhookDispatchInterval <= 0 {
+		return fmt.Errorf("WEBHOOK_DISPATCH_INTERVAL must be positive")
+	}
+	if c.WebhookDispatchEnabled && c.WebhookMaxAttempts <= 0 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+	if c.WebhookDispatchEnabled && c.WebhookRetryBackoffBase <= 0 {
+		return fmt.Errorf("WEBHOOK_RETRY_BACKOFF_BASE must be positive")
+	}
+	if c.WebhookDispatchEnabled && c.WebhookTimeout <= 0 {
+		return fmt.Errorf("WEBHOOK_TIMEOUT must be positive")
+	}
+	if len(c.OrderStatsWindows) == 0 {
+		return fmt.Errorf("ORDER_STATS_WINDOWS must not be empty")
+	}
 	if c.OTelEnabled && (c.OTelSamplingRatio < 0 || c.OTelSamplingRatio > 1) {
 		return fmt.Errorf("OTEL_SAMPLING_RATIO must be in [0, 1]")
 	}
+	if c.RateLimitEnabled && c.RateLimitRPS <= 0 {
+		return fmt.Errorf("ORDER_RATE_LIMIT_RPS must be positive")
+	}
+	if c.RateLimitEnabled && c.RateLimitBurst <= 0 {
+		return fmt.Errorf("ORDER_RATE_LIMIT_BURST must be positive")
+	}
+	if c.IAMGRPCAddr == "" {
+		return fmt.Errorf("IAM_GRPC_ADDR is required")
+	}
 	return nil
 }
 
@@ -197,16 +447,61 @@ func (c Config) Log() {
 	log.Printf("  ORDER_POSTGRES_DSN: %s", maskDSN(c.PostgresDSN))
 	log.Printf("  INVENTORY_GRPC_ADDR: %s", c.InventoryGRPCAddr)
 	log.Printf("  PAYMENT_GRPC_ADDR: %s", c.PaymentGRPCAddr)
+	if c.PaymentServiceToken != "" {
+		log.Printf("  PAYMENT_SERVICE_TOKEN: ***")
+	} else {
+		log.Printf("  PAYMENT_SERVICE_TOKEN: <not set>")
+	}
+	log.Printf("  GRPC_DISCOVERY_REFRESH_INTERVAL: %s", c.Discovery.SRVRefreshInterval)
+	log.Printf("  GRPC_CLIENT_HEALTH_CHECK: %v", c.Discovery.HealthCheck)
+	log.Printf("  GRPC_CLIENT_SUBSET_SIZE: %d", c.Discovery.SubsetSize)
+	log.Printf("  INVENTORY_GRPC_TIMEOUT: %s", c.InventoryCallTimeout)
+	log.Printf("  INVENTORY_GRPC_MAX_RETRIES: %d", c.InventoryMaxRetries)
+	log.Printf("  PAYMENT_GRPC_TIMEOUT: %s", c.PaymentCallTimeout)
+	log.Printf("  PAYMENT_GRPC_MAX_RETRIES: %d", c.PaymentMaxRetries)
+	log.Printf("  GRPC_RETRY_BACKOFF: %s", c.GRPCRetryBackoff)
+	log.Printf("  GRPC_RETRY_BUDGET_RATIO: %f", c.GRPCRetryBudgetRatio)
+	log.Printf("  GRPC_BREAKER_FAILURE_THRESHOLD: %d", c.GRPCBreakerFailureThreshold)
+	log.Printf("  GRPC_BREAKER_OPEN_TIMEOUT: %s", c.GRPCBreakerOpenTimeout)
+	log.Printf("  ORDER_UNAVAILABLE_RETRY_DELAY: %s", c.UnavailableRetryDelay)
 	log.Printf("  SHUTDOWN_TIMEOUT: %s", c.ShutdownTimeout)
+	log.Printf("  DEBUG_ADDR: %q", c.DebugAddr)
 	log.Printf("  KAFKA_BROKERS: %v", c.Brokers)
 	log.Printf("  KAFKA_ORDER_PAYMENT_COMPLETED_TOPIC: %s", c.PaymentCompletedTopic)
+	log.Printf("  KAFKA_ORDER_PAYMENT_DECLINED_TOPIC: %s", c.PaymentDeclinedTopic)
 	log.Printf("  KAFKA_ORDER_ASSEMBLY_COMPLETED_TOPIC: %s", c.AssemblyCompletedTopic)
+	log.Printf("  KAFKA_ORDER_ASSEMBLED_TOPIC: %s", c.OrderAssembledTopic)
 	log.Printf("  KAFKA_ORDER_CONSUMER_GROUP_ID: %s", c.OrderConsumerGroupID)
 	log.Printf("  ORDER_KAFKA_RETRY_MAX_ATTEMPTS: %d", c.AssemblyConsumerRetryMaxAttempts)
 	log.Printf("  ORDER_KAFKA_RETRY_BACKOFF_BASE: %s", c.AssemblyConsumerRetryBackoffBase)
+	log.Printf("  OUTBOX_RECONCILE_ENABLED: %v", c.OutboxReconcileEnabled)
+	if c.OutboxReconcileEnabled {
+		log.Printf("  OUTBOX_RECONCILE_INTERVAL: %s", c.OutboxReconcileInterval)
+		log.Printf("  OUTBOX_RECONCILE_WINDOW: %s", c.OutboxReconcileWindow)
+		log.Printf("  OUTBOX_STUCK_AFTER: %s", c.OutboxStuckAfter)
+	}
+	log.Printf("  WEBHOOK_DISPATCH_ENABLED: %v", c.WebhookDispatchEnabled)
+	if c.WebhookDispatchEnabled {
+		log.Printf("  WEBHOOK_DISPATCH_INTERVAL: %s", c.WebhookDispatchInterval)
+		log.Printf("  WEBHOOK_MAX_ATTEMPTS: %d", c.WebhookMaxAttempts)
+		log.Printf("  WEBHOOK_RETRY_BACKOFF_BASE: %s", c.WebhookRetryBackoffBase)
+		log.Printf("  WEBHOOK_TIMEOUT: %s", c.WebhookTimeout)
+	}
+	log.Printf("  ORDER_STATS_WINDOWS: %s", strings.Join(c.OrderStatsWindows, ","))
 	log.Printf("  OTEL_ENABLED: %v", c.OTelEnabled)
 	log.Printf("  OTEL_EXPORTER_OTLP_ENDPOINT: %s", c.OTelEndpoint)
 	log.Printf("  OTEL_SAMPLING_RATIO: %f", c.OTelSamplingRatio)
+	log.Printf("  ORDER_RATE_LIMIT_ENABLED: %v", c.RateLimitEnabled)
+	if c.RateLimitEnabled {
+		log.Printf("  ORDER_RATE_LIMIT_RPS: %f", c.RateLimitRPS)
+		log.Printf("  ORDER_RATE_LIMIT_BURST: %d", c.RateLimitBurst)
+		log.Printf("  IAM_GRPC_ADDR: %s", c.IAMGRPCAddr)
+	}
+	if c.AdminToken != "" {
+		log.Printf("  ORDER_ADMIN_TOKEN: ***")
+	} else {
+		log.Printf("  ORDER_ADMIN_TOKEN: (not set, admin endpoints disabled)")
+	}
 }
 
 // getBool читает переменную окружения как bool (1, true, yes = true)
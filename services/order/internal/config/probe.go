@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// ProbeConfig содержит конфигурацию synthetic probe (cmd/order-probe)
+type ProbeConfig struct {
+	AppEnv      Env
+	OrderURL    string // базовый URL Order HTTP API
+	IAMGRPCAddr string
+	Login       string // учётная запись probe в IAM
+	Password    string
+	ProductID   string // тестовый товар, на который создаётся заказ
+	Currency    string
+
+	Interval        time.Duration // интервал между прогонами
+	AssemblyTimeout time.Duration // сколько ждать статуса assembled
+	PollInterval    time.Duration // интервал опроса GET /orders/{id}
+
+	// DSN базы Notification; пусто - проверка истории уведомлений пропускается
+	NotificationPostgresDSN string
+
+	// OpenTelemetry (метрики probe)
+	OTelEnabled  bool
+	OTelEndpoint string
+}
+
+// LoadProbe загружает конфигурацию probe из переменных окружения
+func LoadProbe() (ProbeConfig, error) {
+	cfg := ProbeConfig{}
+
+	appEnv := Env(getString("APP_ENV", string(EnvLocal)))
+	if appEnv != EnvLocal && appEnv != EnvDocker {
+		return ProbeConfig{}, fmt.Errorf("invalid APP_ENV: %s (must be 'local' or 'docker')", appEnv)
+	}
+	cfg.AppEnv = appEnv
+
+	if cfg.AppEnv == EnvLocal {
+		cfg.OrderURL = getString("PROBE_ORDER_URL", "http://127.0.0.1:8080")
+		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "127.0.0.1:50053")
+		cfg.OTelEndpoint = getString("OTEL_EXPORTER_OTLP_ENDPOINT", "127.0.0.1:4317")
+	} else {
+		cfg.OrderURL = getString("PROBE_ORDER_URL", "http://order:8080")
+		cfg.IAMGRPCAddr = getString("IAM_GRPC_ADDR", "iam:50053")
+		cfg.OTelEndpoint = getString("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317")
+	}
+
+	cfg.Login = getString("PROBE_LOGIN", "")
+	cfg.Password = getString("PROBE_PASSWORD", "")
+	cfg.ProductID = getString("PROBE_PRODUCT_ID", "probe-product")
+	cfg.Currency = getString("PROBE_CURRENCY", "RUB")
+
+	interval, err := time.ParseDuration(getString("PROBE_INTERVAL", "1m"))
+	if err != nil {
+		return ProbeConfig{}, fmt.Errorf("invalid PROBE_INTERVAL: %w", err)
+	}
+	cfg.Interval = interval
+
+	assemblyTimeout, err := time.ParseDuration(getString("PROBE_ASSEMBLY_TIMEOUT", "2m"))
+	if err != nil {
+		return ProbeConfig{}, fmt.Errorf("invalid PROBE_ASSEMBLY_TIMEOUT: %w", err)
+	}
+	cfg.AssemblyTimeout = assemblyTimeout
+
+	pollInterval, err := time.ParseDuration(getString("PROBE_POLL_INTERVAL", "2s"))
+	if err != nil {
+		return ProbeConfig{}, fmt.Errorf("invalid PROBE_POLL_INTERVAL: %w", err)
+	}
+	cfg.PollInterval = pollInterval
+
+	cfg.NotificationPostgresDSN = getString("PROBE_NOTIFICATION_POSTGRES_DSN", "")
+	cfg.OTelEnabled = getBool("OTEL_ENABLED", false)
+
+	if err := cfg.Validate(); err != nil {
+		return ProbeConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+// Validate проверяет корректность конфигурации probe
+func (c ProbeConfig) Validate() error {
+	if c.OrderURL == "" {
+		return fmt.Errorf("PROBE_ORDER_URL is required")
+	}
+	if c.IAMGRPCAddr == "" {
+		return fmt.Errorf("IAM_GRPC_ADDR is required")
+	}
+	if c.Login == "" {
+		return fmt.Errorf("PROBE_LOGIN is required")
+	}
+	if c.Password == "" {
+		return fmt.Errorf("PROBE_PASSWORD is required")
+	}
+	if c.ProductID == "" {
+		return fmt.Errorf("PROBE_PRODUCT_ID is required")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("PROBE_INTERVAL must be positive")
+	}
+	if c.AssemblyTimeout <= 0 {
+		return fmt.Errorf("PROBE_ASSEMBLY_TIMEOUT must be positive")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("PROBE_POLL_INTERVAL must be positive")
+	}
+	return nil
+}
+
+// Log выводит конфигурацию probe в лог (с маскировкой паролей)
+func (c ProbeConfig) Log() {
+	log.Printf("Probe config loaded:")
+	log.Printf("  APP_ENV: %s", c.AppEnv)
+	log.Printf("  PROBE_ORDER_URL: %s", c.OrderURL)
+	log.Printf("  IAM_GRPC_ADDR: %s", c.IAMGRPCAddr)
+	log.Printf("  PROBE_LOGIN: %s", c.Login)
+	log.Printf("  PROBE_PASSWORD: ***")
+	log.Printf("  PROBE_PRODUCT_ID: %s", c.ProductID)
+	log.Printf("  PROBE_CURRENCY: %s", c.Currency)
+	log.Printf("  PROBE_INTERVAL: %s", c.Interval)
+	log.Printf("  PROBE_ASSEMBLY_TIMEOUT: %s", c.AssemblyTimeout)
+	log.Printf("  PROBE_POLL_INTERVAL: %s", c.PollInterval)
+	if c.NotificationPostgresDSN != "" {
+		log.Printf("  PROBE_NOTIFICATION_POSTGRES_DSN: %s", maskDSN(c.NotificationPostgresDSN))
+	} else {
+		log.Printf("  PROBE_NOTIFICATION_POSTGRES_DSN: (not set, notification check disabled)")
+	}
+	log.Printf("  OTEL_ENABLED: %v", c.OTelEnabled)
+	log.Printf("  OTEL_EXPORTER_OTLP_ENDPOINT: %s", c.OTelEndpoint)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultCurrency используется, если клиент не передал валюту заказа
+const DefaultCurrency = "RUB"
+
+// ErrUnsupportedCurrency возвращается, если валюта заказа не поддерживается
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// ErrCurrencyMismatch возвращается при сложении сумм в разных валютах
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// supportedCurrencies содержит коды валют ISO 4217, в которых можно оформить заказ
+var supportedCurrencies = map[string]struct{}{
+	"RUB": {},
+	"USD": {},
+	"EUR": {},
+}
+
+// NormalizeCurrency приводит код валюты к верхнему регистру и проверяет, что он поддерживается.
+// Пустое значение заменяется на DefaultCurrency.
+func NormalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency, nil
+	}
+	if _, ok := supportedCurrencies[currency]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return currency, nil
+}
+
+// Money - сумма в минимальных единицах валюты (копейки, центы)
+// Суммы в разных валютах не складываются: так сумма заказа не может смешать цены в RUB и USD
+type Money struct {
+	Amount   int64
+	Currency string // код валюты ISO 4217
+}
+
+// NewMoney создаёт сумму amount минимальных единиц валюты currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// IsZero сообщает, что сумма не задана (например, у позиции старого заказа нет снимка цены)
+func (m Money) IsZero() bool {
+	return m == Money{}
+}
+
+// Times возвращает сумму за quantity единиц
+func (m Money) Times(quantity int32) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// Add складывает суммы одной валюты; нулевая сумма (Money{}) принимает валюту второго слагаемого
+func (m Money) Add(other Money) (Money, error) {
+	if m.IsZero() {
+		return other, nil
+	}
+	if other.IsZero() {
+		return m, nil
+	}
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
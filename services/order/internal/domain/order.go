@@ -0,0 +1,125 @@
+// Package domain содержит доменную модель заказа: агрегат Order, позиции и value objects (Money, Status).
+// Модель не зависит от хранилища и HTTP: service слой переводит её в структуры repository,
+// а HTTP слой - в сгенерированные DTO, поэтому схема БД и API меняются независимо.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ошибки валидации заказа
+var (
+	ErrEmptyOrder  = errors.New("order must contain at least one item")
+	ErrInvalidLine = errors.New("invalid order line")
+)
+
+// Line - строка заказа, как её передал клиент: товар и количество
+type Line struct {
+	ProductID string
+	Quantity  int32
+}
+
+// Item - позиция заказа
+type Item struct {
+	ProductID string
+	Quantity  int32
+	Status    ItemStatus
+	UnitPrice Money // цена единицы на момент покупки; не меняется при изменении цен каталога, пустая у старых заказов
+}
+
+// Total возвращает стоимость позиции
+func (i Item) Total() Money {
+	return i.UnitPrice.Times(i.Quantity)
+}
+
+// Order - агрегат заказа
+// Статусы заказа и позиций меняются только через методы агрегата, которые проверяют допустимость перехода
+type Order struct {
+	ID         string
+	UserID     string
+	Status     Status
+	Items      []Item
+	Total      Money // сумма заказа по зафиксированным ценам позиций
+	CreatedAt  time.Time
+	ArchivedAt time.Time // нулевое значение - заказ не архивирован
+	ItemsError string    // позиции не прочитаны из хранилища (мягкий режим чтения для поддержки), Items пустой
+}
+
+// PriceFunc возвращает цену единицы товара в минимальных единицах валюты заказа
+type PriceFunc func(productID string) int64
+
+// NewOrder создаёт заказ в статусе StatusNew с позициями в статусе ItemStatusReserved
+// Цена каждой позиции фиксируется через price, сумма заказа считается по зафиксированным ценам
+func NewOrder(id, userID string, lines []Line, currency string, price PriceFunc) (Order, error) {
+	if len(lines) == 0 {
+		return Order{}, ErrEmptyOrder
+	}
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Order{}, err
+	}
+
+	order := Order{
+		ID:     id,
+		UserID: userID,
+		Status: StatusNew,
+		Items:  make([]Item, 0, len(lines)),
+		Total:  NewMoney(0, currency),
+	}
+	for i, line := range lines {
+		if line.ProductID == "" || line.Quantity <= 0 {
+			return Order{}, fmt.Errorf("%w: items[%d]", ErrInvalidLine, i)
+		}
+		item := Item{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			Status:    ItemStatusReserved,
+			UnitPrice: NewMoney(price(line.ProductID), currency),
+		}
+		if order.Total, err = order.Total.Add(item.Total()); err != nil {
+			return Order{}, err
+		}
+		order.Items = append(order.Items, item)
+	}
+	return order, nil
+}
+
+// Lines возвращает товары и количества заказа (для резервирования и событий)
+func (o Order) Lines() []Line {
+	lines := make([]Line, 0, len(o.Items))
+	for _, item := range o.Items {
+		lines = append(lines, Line{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return lines
+}
+
+// IsArchived сообщает, что заказ архивирован
+func (o Order) IsArchived() bool {
+	return !o.ArchivedAt.IsZero()
+}
+
+// MarkPaid переводит заказ в StatusPaid; позиции остаются зарезервированными
+func (o *Order) MarkPaid() error {
+	return o.transitionTo(StatusPaid)
+}
+
+// DeclinePayment переводит заказ в StatusPaymentDeclined и отменяет все позиции
+func (o *Order) DeclinePayment() error {
+	if err := o.transitionTo(StatusPaymentDeclined); err != nil {
+		return err
+	}
+	for i := range o.Items {
+		o.Items[i].Status = ItemStatusCancelled
+	}
+	return nil
+}
+
+func (o *Order) transitionTo(to Status) error {
+	if err := Transition(o.Status, to); err != nil {
+		return err
+	}
+	o.Status = to
+	return nil
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fixedPrice(string) int64 { return 10000 }
+
+func TestNewOrder(t *testing.T) {
+	t.Run("fixes unit prices and computes total", func(t *testing.T) {
+		order, err := NewOrder("order-1", "user-1", []Line{
+			{ProductID: "product-1", Quantity: 2},
+			{ProductID: "product-2", Quantity: 1},
+		}, "usd", fixedPrice)
+
+		require.NoError(t, err)
+		require.Equal(t, StatusNew, order.Status)
+		require.Equal(t, NewMoney(30000, "USD"), order.Total)
+		require.Len(t, order.Items, 2)
+		for _, item := range order.Items {
+			require.Equal(t, ItemStatusReserved, item.Status)
+			require.Equal(t, NewMoney(10000, "USD"), item.UnitPrice)
+		}
+	})
+
+	t.Run("empty currency defaults to RUB", func(t *testing.T) {
+		order, err := NewOrder("order-1", "user-1", []Line{{ProductID: "product-1", Quantity: 1}}, "", fixedPrice)
+
+		require.NoError(t, err)
+		require.Equal(t, DefaultCurrency, order.Total.Currency)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		_, err := NewOrder("order-1", "user-1", nil, "", fixedPrice)
+		require.ErrorIs(t, err, ErrEmptyOrder)
+
+		_, err = NewOrder("order-1", "user-1", []Line{{ProductID: "product-1", Quantity: 0}}, "", fixedPrice)
+		require.ErrorIs(t, err, ErrInvalidLine)
+
+		_, err = NewOrder("order-1", "user-1", []Line{{ProductID: "product-1", Quantity: 1}}, "XYZ", fixedPrice)
+		require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	})
+}
+
+func TestOrder_Transitions(t *testing.T) {
+	newOrder := func(t *testing.T) Order {
+		order, err := NewOrder("order-1", "user-1", []Line{{ProductID: "product-1", Quantity: 1}}, "", fixedPrice)
+		require.NoError(t, err)
+		return order
+	}
+
+	t.Run("paid order cannot be declined", func(t *testing.T) {
+		order := newOrder(t)
+
+		require.NoError(t, order.MarkPaid())
+		require.Equal(t, StatusPaid, order.Status)
+		require.ErrorIs(t, order.DeclinePayment(), ErrInvalidTransition)
+		require.Equal(t, ItemStatusReserved, order.Items[0].Status)
+	})
+
+	t.Run("decline cancels items", func(t *testing.T) {
+		order := newOrder(t)
+
+		require.NoError(t, order.DeclinePayment())
+		require.Equal(t, StatusPaymentDeclined, order.Status)
+		require.Equal(t, ItemStatusCancelled, order.Items[0].Status)
+		require.ErrorIs(t, order.MarkPaid(), ErrInvalidTransition)
+	})
+
+	t.Run("item transitions", func(t *testing.T) {
+		require.True(t, ItemStatusReserved.CanTransitionTo(ItemStatusAssembled))
+		require.True(t, ItemStatusAssembled.CanTransitionTo(ItemStatusShipped))
+		require.False(t, ItemStatusCancelled.CanTransitionTo(ItemStatusAssembled))
+		require.False(t, ItemStatusShipped.CanTransitionTo(ItemStatusReserved))
+		require.NoError(t, TransitionItem(ItemStatusReserved, ItemStatusCancelled))
+		require.ErrorIs(t, TransitionItem(ItemStatusReserved, ItemStatusShipped), ErrInvalidTransition)
+	})
+}
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		allowed  bool
+	}{
+		{StatusNew, StatusPaid, true},
+		{StatusNew, StatusPaymentDeclined, true},
+		{StatusPaid, StatusAssembled, true},
+		{StatusNew, StatusAssembled, false},
+		{StatusPaid, StatusPaymentDeclined, false},
+		{StatusAssembled, StatusPaid, false},
+		{StatusPaymentDeclined, StatusPaid, false},
+		{StatusPaid, Status("shipped"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := Transition(tt.from, tt.to)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+
+			var transitionErr *TransitionError
+			require.ErrorAs(t, err, &transitionErr)
+			require.Equal(t, string(tt.from), transitionErr.From)
+			require.Equal(t, string(tt.to), transitionErr.To)
+			require.ErrorIs(t, err, ErrInvalidTransition)
+		})
+	}
+}
+
+func TestTerminalStatuses(t *testing.T) {
+	require.Equal(t, []Status{StatusPaymentDeclined, StatusAssembled}, TerminalStatuses())
+	require.False(t, StatusNew.IsTerminal())
+	require.False(t, StatusPaid.IsTerminal())
+}
+
+func TestMoney_Add(t *testing.T) {
+	sum, err := NewMoney(100, "RUB").Add(NewMoney(250, "RUB"))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(350, "RUB"), sum)
+
+	_, err = NewMoney(100, "RUB").Add(NewMoney(100, "USD"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition возвращается при недопустимой смене статуса заказа или позиции
+// Конкретная ошибка - *TransitionError, errors.Is(err, ErrInvalidTransition) срабатывает для неё
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// TransitionError - недопустимая смена статуса заказа или позиции: из From в To
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %s -> %s", ErrInvalidTransition, e.From, e.To)
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, ErrInvalidTransition)
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// Status - статус заказа
+type Status string
+
+const (
+	// StatusNew - заказ сформирован, но ещё не оплачен; в хранилище в этом статусе не попадает
+	StatusNew Status = "new"
+	// StatusPaid - заказ оплачен, все позиции зарезервированы
+	StatusPaid Status = "paid"
+	// StatusPaymentDeclined - в оплате отказано, позиции отменены
+	StatusPaymentDeclined Status = "payment_declined"
+	// StatusAssembled - заказ собран (Assembly)
+	StatusAssembled Status = "assembled"
+)
+
+// statusTransitions - допустимые переходы статуса заказа
+var statusTransitions = map[Status][]Status{
+	StatusNew:  {StatusPaid, StatusPaymentDeclined},
+	StatusPaid: {StatusAssembled},
+}
+
+// IsStored сообщает, что заказ может храниться в статусе s: все известные статусы, кроме StatusNew
+func (s Status) IsStored() bool {
+	switch s {
+	case StatusPaid, StatusPaymentDeclined, StatusAssembled:
+		return true
+	}
+	return false
+}
+
+// IsTerminal сообщает, что заказ в статусе s завершён: он хранится и дальше никуда не переходит
+func (s Status) IsTerminal() bool {
+	return s.IsStored() && len(statusTransitions[s]) == 0
+}
+
+// TerminalStatuses возвращает все конечные статусы заказа (IsTerminal): по ним отбираются завершённые заказы
+func TerminalStatuses() []Status {
+	var terminal []Status
+	for _, s := range []Status{StatusPaid, StatusPaymentDeclined, StatusAssembled} {
+		if s.IsTerminal() {
+			terminal = append(terminal, s)
+		}
+	}
+	return terminal
+}
+
+// CanTransitionTo сообщает, можно ли перевести заказ из статуса s в to
+func (s Status) CanTransitionTo(to Status) bool {
+	return canTransition(statusTransitions[s], to)
+}
+
+// Transition проверяет переход статуса заказа from -> to по statusTransitions
+// Единая точка проверки для агрегата и для всех путей записи статуса в хранилище.
+// Новый заказ сохраняется переходом из StatusNew. Возвращает *TransitionError, если переход недопустим
+func Transition(from, to Status) error {
+	if !from.CanTransitionTo(to) {
+		return transitionError(from, to)
+	}
+	return nil
+}
+
+// ItemStatus - статус позиции заказа: каждая позиция проходит сборку/отгрузку независимо (частичное выполнение заказа)
+type ItemStatus string
+
+const (
+	ItemStatusReserved  ItemStatus = "reserved"  // товар зарезервирован в Inventory, ждёт сборки
+	ItemStatusAssembled ItemStatus = "assembled" // позиция собрана
+	ItemStatusShipped   ItemStatus = "shipped"   // позиция отгружена
+	ItemStatusCancelled ItemStatus = "cancelled" // позиция отменена (не нашлась при сборке или отказ в оплате)
+)
+
+// itemStatusTransitions - допустимые переходы статуса позиции
+var itemStatusTransitions = map[ItemStatus][]ItemStatus{
+	ItemStatusReserved:  {ItemStatusAssembled, ItemStatusCancelled},
+	ItemStatusAssembled: {ItemStatusShipped},
+}
+
+// CanTransitionTo сообщает, можно ли перевести позицию из статуса s в to
+func (s ItemStatus) CanTransitionTo(to ItemStatus) bool {
+	return canTransition(itemStatusTransitions[s], to)
+}
+
+// TransitionItem проверяет переход статуса позиции from -> to по itemStatusTransitions
+// Возвращает *TransitionError, если переход недопустим
+func TransitionItem(from, to ItemStatus) error {
+	if !from.CanTransitionTo(to) {
+		return transitionError(from, to)
+	}
+	return nil
+}
+
+// IsAssemblyResult сообщает, что статус может прийти в событии сборки (assembled или cancelled)
+func (s ItemStatus) IsAssemblyResult() bool {
+	return s == ItemStatusAssembled || s == ItemStatusCancelled
+}
+
+func canTransition[S comparable](allowed []S, to S) bool {
+	for _, s := range allowed {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func transitionError[S ~string](from, to S) error {
+	return &TransitionError{From: string(from), To: string(to)}
+}
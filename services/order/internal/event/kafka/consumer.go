@@ -3,16 +3,23 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
 )
 
 // OrderAssemblyCompletedConsumer обрабатывает события завершения сборки заказа из Kafka
+// Обработку можно приостановить (Pause/Resume): consumer остаётся в группе, но не читает новые сообщения.
 type OrderAssemblyCompletedConsumer struct {
+	pauseGate
+
 	logger      *zap.Logger
 	reader      *kafka.Reader
 	service     *service.OrderService
@@ -74,6 +81,12 @@ func (c *OrderAssemblyCompletedConsumer) Start(ctx context.Context) error {
 	)
 
 	for {
+		// На паузе не читаем новые сообщения; reader продолжает heartbeat, поэтому rebalance не происходит
+		if err := c.waitResumed(ctx); err != nil {
+			c.logger.Info("consumer context cancelled, stopping")
+			return nil
+		}
+
 		// FetchMessage вместо ReadMessage для ручного контроля commit
 		m, err := c.reader.FetchMessage(ctx)
 		if err != nil {
@@ -117,6 +130,23 @@ func (c *OrderAssemblyCompletedConsumer) Start(ctx context.Context) error {
 // processMessage обрабатывает одно сообщение из Kafka
 // Возвращает true, если нужно закоммитить offset (успешная обработка)
 func (c *OrderAssemblyCompletedConsumer) processMessage(ctx context.Context, m kafka.Message) bool {
+	// Продолжаем trace из заголовков сообщения (assembly -> order)
+	headers := platformkafka.HeadersFromKafka(m.Headers)
+	ctx, span := platformobservability.StartConsumerSpan(ctx, tracerName, m.Topic, headers)
+	defer span.End()
+
+	// Маршрутизация по заголовку event_type: чужие события пропускаем, не разбирая body
+	if eventType := headers.EventType(); eventType != "" && eventType != eventTypeOrderAssemblyCompleted {
+		c.logger.Warn("skipping message with unexpected event_type",
+			zap.String("event_type", eventType),
+			zap.String("event_id", headers.EventID()),
+			zap.String("topic", m.Topic),
+			zap.Int("partition", m.Partition),
+			zap.Int64("offset", m.Offset),
+		)
+		return true
+	}
+
 	// Парсим JSON сообщение
 	var payload map[string]interface{}
 	if err := json.Unmarshal(m.Value, &payload); err != nil {
@@ -254,6 +284,30 @@ func (c *OrderAssemblyCompletedConsumer) parseOrderAssemblyCompletedEvent(payloa
 	if v, ok := payload["user_id"].(string); ok {
 		event.UserID = v
 	}
+	// items необязательны: в событиях старого формата их нет, заказ считается собранным целиком
+	if v, ok := payload["items"].([]interface{}); ok {
+		for _, raw := range v {
+			item, ok := raw.(map[string]interface{})
+			if !ok {
+				return event, &ParseError{Field: "items", Message: "items must be an array of objects"}
+			}
+			productID, _ := item["product_id"].(string)
+			if productID == "" {
+				return event, &ParseError{Field: "items", Message: "items[].product_id is required"}
+			}
+			quantity, _ := item["quantity"].(float64)
+			rawStatus, _ := item["status"].(string)
+			status := domain.ItemStatus(rawStatus)
+			if status != "" && !status.IsAssemblyResult() {
+				return event, &ParseError{Field: "items", Message: fmt.Sprintf("unsupported item status %q", status)}
+			}
+			event.Items = append(event.Items, service.AssemblyItem{
+				ProductID: productID,
+				Quantity:  int32(quantity),
+				Status:    status,
+			})
+		}
+	}
 
 	return event, nil
 }
@@ -0,0 +1,24 @@
+package kafka
+
+import (
+	"context"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// tracerName - имя сервиса для span публикации и обработки сообщений
+const tracerName = "order"
+
+// eventTypeOrderAssemblyCompleted - тип события, который читает OrderAssemblyCompletedConsumer
+// Сообщения с другим event_type в заголовке пропускаются без разбора body
+const eventTypeOrderAssemblyCompleted = "order.assembly.completed"
+
+// outboxEventSchemaVersion - версия схемы событий Order (event_version в payload)
+const outboxEventSchemaVersion = 1
+
+// outboxMessageHeaders собирает заголовки Kafka для события из outbox:
+// trace context и request_id из ctx (span публикации), тип, ID и версию схемы события
+func outboxMessageHeaders(ctx context.Context, event repository.OutboxEvent) platformkafka.Headers {
+	return platformkafka.NewHeaders(ctx, event.EventType, event.EventID, outboxEventSchemaVersion)
+}
@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+func TestOutboxMessageHeaders_RoundTrip(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = platformobservability.WithRequestID(ctx, "req-1")
+
+	msgHeaders := outboxMessageHeaders(ctx, repository.OutboxEvent{
+		EventID:   "event-1",
+		EventType: "order.payment.completed",
+	}).Kafka()
+
+	headers := platformkafka.HeadersFromKafka(msgHeaders)
+	require.Equal(t, "order.payment.completed", headers.EventType())
+	require.Equal(t, "event-1", headers.EventID())
+	require.Equal(t, outboxEventSchemaVersion, headers.SchemaVersion())
+	require.Zero(t, headers.RetryCount())
+
+	restored := headers.Context(context.Background())
+
+	restoredSC := trace.SpanContextFromContext(restored)
+	require.True(t, restoredSC.IsRemote())
+	require.Equal(t, traceID, restoredSC.TraceID())
+	require.Equal(t, spanID, restoredSC.SpanID())
+
+	requestID, ok := platformobservability.RequestIDFromContext(restored)
+	require.True(t, ok)
+	require.Equal(t, "req-1", requestID)
+}
+
+func TestHeaders_Empty(t *testing.T) {
+	headers := platformkafka.HeadersFromKafka(nil)
+	require.Nil(t, headers.Kafka())
+	require.Empty(t, headers.EventType())
+	require.Zero(t, headers.SchemaVersion())
+
+	ctx := context.Background()
+	require.Equal(t, ctx, headers.Context(ctx))
+}
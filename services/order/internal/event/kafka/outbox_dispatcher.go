@@ -6,13 +6,18 @@ import (
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 )
 
 // OutboxDispatcher обрабатывает события из outbox таблицы и публикует их в Kafka
+// Публикацию можно приостановить (Pause/Resume): события копятся в outbox и уходят после Resume.
 type OutboxDispatcher struct {
+	pauseGate
+
 	logger     *zap.Logger
 	repo       repository.OrderRepository
 	writer     *kafka.Writer
@@ -85,6 +90,12 @@ func (d *OutboxDispatcher) processBatch(ctx context.Context) error {
 		return ctx.Err()
 	}
 
+	// На паузе пропускаем тик: pending события остаются в outbox
+	if d.Paused() {
+		d.logger.Debug("outbox dispatcher paused, skipping batch")
+		return nil
+	}
+
 	events, err := d.repo.GetPendingOutboxEvents(ctx, d.batchSize) //d.batchSize - количество событий, которые будут обработаны за один раз
 	if err != nil {
 		// Если контекст отменён, не логируем как ошибку
@@ -129,12 +140,19 @@ func (d *OutboxDispatcher) processBatch(ctx context.Context) error {
 func (d *OutboxDispatcher) processEvent(ctx context.Context, event repository.OutboxEvent) error {
 	var lastErr error
 
+	// Span публикации продолжает trace запроса, сохранившего событие в outbox
+	pubCtx, span := platformobservability.StartProducerSpan(
+		platformobservability.ContextFromMessageHeaders(ctx, event.Headers), tracerName, event.Topic)
+	defer span.End()
+	headers := outboxMessageHeaders(pubCtx, event).Kafka()
+
 	for attempt := 1; attempt <= d.maxRetries; attempt++ {
 		// Публикуем в Kafka
 		msg := kafka.Message{
-			Topic: event.Topic,               // topic из outbox таблицы
-			Key:   []byte(event.AggregateID), // order_id как key
-			Value: event.Payload,
+			Topic:   event.Topic,               // topic из outbox таблицы
+			Key:     []byte(event.AggregateID), // order_id как key
+			Value:   event.Payload,
+			Headers: headers, // trace context, request_id, event_type, event_id, schema_version
 		}
 
 		err := d.writer.WriteMessages(ctx, msg)
@@ -193,6 +211,9 @@ func (d *OutboxDispatcher) processEvent(ctx context.Context, event repository.Ou
 		return ctx.Err()
 	}
 
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+
 	errMsg := fmt.Sprintf("failed after %d attempts: %v", d.maxRetries, lastErr)
 	if markErr := d.repo.MarkOutboxEventFailed(ctx, event.EventID, errMsg); markErr != nil {
 		// Если контекст отменён, не логируем как ошибку
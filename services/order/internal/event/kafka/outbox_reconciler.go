@@ -0,0 +1,321 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// reconcileBatchSize - сколько sent событий читается из БД за один запрос (страница сверки)
+const reconcileBatchSize = 1000
+
+// OutboxTopicReader читает из топика ID опубликованных событий
+type OutboxTopicReader interface {
+	// PublishedEventIDs возвращает event_id всех сообщений топика, записанных начиная с since
+	PublishedEventIDs(ctx context.Context, topic string, since time.Time) (map[string]struct{}, error)
+}
+
+// OutboxReconcileMetrics записывает метрики сверки outbox с Kafka
+type OutboxReconcileMetrics interface {
+	// RecordMissing учитывает sent события, которых нет в топике
+	RecordMissing(topic string, count int)
+	// RecordBacklog выставляет текущее количество зависших событий
+	RecordBacklog(backlog repository.OutboxBacklog)
+	// RecordReconcileError учитывает неудачный проход сверки
+	RecordReconcileError()
+}
+
+// OutboxReconciler периодически сверяет outbox с Kafka и ловит тихие потери публикации:
+//   - события со статусом sent, которых нет в топике, возвращаются в pending и публикуются повторно
+//     (consumers идемпотентны по event_id, поэтому дубль безопасен)
+//   - pending события старше stuckAfter и failed события учитываются в метрике зависших
+type OutboxReconciler struct {
+	logger     *zap.Logger
+	repo       repository.OrderRepository
+	reader     OutboxTopicReader
+	metrics    OutboxReconcileMetrics // nil - метрики не пишутся
+	interval   time.Duration
+	window     time.Duration
+	stuckAfter time.Duration
+	batchSize  int
+}
+
+// NewOutboxReconciler создаёт новый reconciler
+func NewOutboxReconciler(
+	logger *zap.Logger,
+	repo repository.OrderRepository,
+	reader OutboxTopicReader,
+	metrics OutboxReconcileMetrics,
+	interval time.Duration, //interval - интервал между сверками
+	window time.Duration, //window - за какой период сверяются sent события
+	stuckAfter time.Duration, //stuckAfter - через сколько pending событие считается зависшим
+) *OutboxReconciler {
+	return &OutboxReconciler{
+		logger:     logger,
+		repo:       repo,
+		reader:     reader,
+		metrics:    metrics,
+		interval:   interval,
+		window:     window,
+		stuckAfter: stuckAfter,
+		batchSize:  reconcileBatchSize,
+	}
+}
+
+// Start запускает сверку по таймеру до отмены контекста
+func (r *OutboxReconciler) Start(ctx context.Context) error {
+	r.logger.Info("starting outbox reconciler",
+		zap.Duration("interval", r.interval),
+		zap.Duration("window", r.window),
+		zap.Duration("stuck_after", r.stuckAfter),
+	)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("outbox reconciler context cancelled, stopping")
+			return nil
+		case <-ticker.C:
+			if err := r.Reconcile(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if r.metrics != nil {
+					r.metrics.RecordReconcileError()
+				}
+				r.logger.Error("failed to reconcile outbox", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reconcile выполняет один проход сверки
+func (r *OutboxReconciler) Reconcile(ctx context.Context) error {
+	now := time.Now()
+
+	backlog, err := r.repo.GetOutboxBacklog(ctx, now.Add(-r.stuckAfter))
+	if err != nil {
+		return fmt.Errorf("failed to get outbox backlog: %w", err)
+	}
+	if r.metrics != nil {
+		r.metrics.RecordBacklog(backlog)
+	}
+	if backlog.StalePending > 0 || backlog.Failed > 0 {
+		r.logger.Warn("outbox has stuck events",
+			zap.Int64("stale_pending", backlog.StalePending),
+			zap.Int64("failed", backlog.Failed),
+			zap.Duration("stuck_after", r.stuckAfter),
+		)
+	}
+
+	// Окно читается страницами по (sent_at, event_id), пока не закончится: сверяются все события окна.
+	// Каждую страницу сначала читаем из БД, затем топик: всё, что отмечено sent, уже записано в Kafka
+	cursor := repository.SentOutboxCursor{SentAt: now.Add(-r.window)}
+	checked := 0
+	for {
+		events, err := r.repo.GetSentOutboxEvents(ctx, cursor, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get sent outbox events: %w", err)
+		}
+		if err := r.reconcileEvents(ctx, events); err != nil {
+			return err
+		}
+		checked += len(events)
+
+		if len(events) < r.batchSize {
+			break
+		}
+		last := events[len(events)-1]
+		// События, отправленные после начала прохода, сверит следующий проход: иначе под нагрузкой проход не закончится
+		if last.SentAt.After(now) {
+			break
+		}
+		cursor = repository.SentOutboxCursor{SentAt: last.SentAt, EventID: last.EventID}
+	}
+
+	r.logger.Debug("outbox reconciled", zap.Int("checked", checked), zap.Duration("window", r.window))
+	return nil
+}
+
+// reconcileEvents сверяет страницу sent событий по топикам
+func (r *OutboxReconciler) reconcileEvents(ctx context.Context, events []repository.OutboxEvent) error {
+	byTopic := make(map[string][]repository.OutboxEvent)
+	for _, event := range events {
+		byTopic[event.Topic] = append(byTopic[event.Topic], event)
+	}
+
+	for topic, topicEvents := range byTopic {
+		if err := r.reconcileTopic(ctx, topic, topicEvents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileTopic сверяет sent события одного топика и возвращает пропавшие в pending
+func (r *OutboxReconciler) reconcileTopic(ctx context.Context, topic string, events []repository.OutboxEvent) error {
+	// Сообщение записывается в Kafka после создания события в outbox, поэтому created_at - нижняя граница
+	since := events[0].CreatedAt
+	for _, event := range events {
+		if event.CreatedAt.Before(since) {
+			since = event.CreatedAt
+		}
+	}
+
+	published, err := r.reader.PublishedEventIDs(ctx, topic, since)
+	if err != nil {
+		return fmt.Errorf("failed to read topic %s: %w", topic, err)
+	}
+
+	missing := 0
+	for _, event := range events {
+		if _, ok := published[event.EventID]; ok {
+			continue
+		}
+		missing++
+		r.logger.Error("outbox event marked sent but not found in kafka, republishing",
+			zap.String("event_id", event.EventID),
+			zap.String("event_type", event.EventType),
+			zap.String("topic", topic),
+			zap.String("aggregate_id", event.AggregateID),
+			zap.Time("sent_at", event.SentAt),
+		)
+		if err := r.repo.ResetOutboxEventPending(ctx, event.EventID); err != nil {
+			return fmt.Errorf("failed to reset event %s to pending: %w", event.EventID, err)
+		}
+	}
+
+	if missing > 0 && r.metrics != nil {
+		r.metrics.RecordMissing(topic, missing)
+	}
+
+	r.logger.Debug("outbox topic reconciled",
+		zap.String("topic", topic),
+		zap.Int("checked", len(events)),
+		zap.Int("missing", missing),
+	)
+
+	return nil
+}
+
+// KafkaOutboxTopicReader читает партиции топика напрямую (без consumer group, offsets не коммитятся)
+type KafkaOutboxTopicReader struct {
+	brokers []string
+	timeout time.Duration
+}
+
+// NewKafkaOutboxTopicReader создаёт reader топиков для сверки outbox
+func NewKafkaOutboxTopicReader(brokers []string, timeout time.Duration) *KafkaOutboxTopicReader {
+	return &KafkaOutboxTopicReader{
+		brokers: brokers,
+		timeout: timeout,
+	}
+}
+
+// PublishedEventIDs возвращает event_id сообщений всех партиций топика, записанных начиная с since
+func (r *KafkaOutboxTopicReader) PublishedEventIDs(ctx context.Context, topic string, since time.Time) (map[string]struct{}, error) {
+	dialer := &kafka.Dialer{Timeout: r.timeout}
+
+	var (
+		conn   *kafka.Conn
+		broker string
+		err    error
+	)
+	for _, broker = range r.brokers {
+		conn, err = dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions: %w", err)
+	}
+
+	ids := make(map[string]struct{})
+	for _, p := range partitions {
+		if err := r.readPartition(ctx, dialer, broker, topic, p.ID, since, ids); err != nil {
+			return nil, fmt.Errorf("partition %d: %w", p.ID, err)
+		}
+	}
+	return ids, nil
+}
+
+// readPartition добавляет в ids event_id сообщений партиции от since до текущего конца
+func (r *KafkaOutboxTopicReader) readPartition(ctx context.Context, dialer *kafka.Dialer, broker, topic string, partition int, since time.Time, ids map[string]struct{}) error {
+	conn, err := dialer.DialLeader(ctx, "tcp", broker, topic, partition)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	first, err := conn.ReadOffset(since)
+	if err != nil {
+		return err
+	}
+	last, err := conn.ReadLastOffset()
+	if err != nil {
+		return err
+	}
+	if first < 0 || first >= last {
+		return nil // в партиции нет сообщений после since
+	}
+	if _, err := conn.Seek(first, kafka.SeekAbsolute); err != nil {
+		return err
+	}
+
+	offset := first
+	for offset < last {
+		if err := conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+			return err
+		}
+		batch := conn.ReadBatch(1, 10e6) // 10MB
+		start := offset
+		for offset < last {
+			msg, err := batch.ReadMessage()
+			if err != nil {
+				break
+			}
+			offset = msg.Offset + 1
+			if eventID := messageEventID(msg); eventID != "" {
+				ids[eventID] = struct{}{}
+			}
+		}
+		if err := batch.Close(); err != nil && offset < last {
+			return err
+		}
+		if offset == start {
+			return fmt.Errorf("no progress reading offset %d (last %d)", offset, last)
+		}
+	}
+	return nil
+}
+
+// messageEventID берёт event_id из заголовка, а для сообщений без заголовков - из JSON body
+func messageEventID(msg kafka.Message) string {
+	if eventID := platformkafka.HeadersFromKafka(msg.Headers).EventID(); eventID != "" {
+		return eventID
+	}
+	var payload struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal(msg.Value, &payload); err != nil {
+		return ""
+	}
+	return payload.EventID
+}
@@ -0,0 +1,168 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+)
+
+// fakeTopicReader отдаёт заранее заданные event_id по топикам
+type fakeTopicReader struct {
+	ids   map[string][]string
+	err   error
+	since map[string]time.Time
+}
+
+func (f *fakeTopicReader) PublishedEventIDs(_ context.Context, topic string, since time.Time) (map[string]struct{}, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.since == nil {
+		f.since = make(map[string]time.Time)
+	}
+	f.since[topic] = since
+	result := make(map[string]struct{})
+	for _, id := range f.ids[topic] {
+		result[id] = struct{}{}
+	}
+	return result, nil
+}
+
+// fakeReconcileMetrics запоминает записанные метрики
+type fakeReconcileMetrics struct {
+	missing map[string]int
+	backlog repository.OutboxBacklog
+	errors  int
+}
+
+func (m *fakeReconcileMetrics) RecordMissing(topic string, count int) {
+	if m.missing == nil {
+		m.missing = make(map[string]int)
+	}
+	m.missing[topic] += count
+}
+
+func (m *fakeReconcileMetrics) RecordBacklog(backlog repository.OutboxBacklog) {
+	m.backlog = backlog
+}
+
+func (m *fakeReconcileMetrics) RecordReconcileError() {
+	m.errors++
+}
+
+func TestOutboxReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("missing events are reset to pending", func(t *testing.T) {
+		repo := repoMocks.NewOrderRepository(t)
+		repo.On("GetOutboxBacklog", ctx, mock.AnythingOfType("time.Time")).
+			Return(repository.OutboxBacklog{StalePending: 2, Failed: 1}, nil)
+		repo.On("GetSentOutboxEvents", ctx, mock.AnythingOfType("repository.SentOutboxCursor"), reconcileBatchSize).
+			Return([]repository.OutboxEvent{
+				{EventID: "evt-1", Topic: "order.payment.completed", CreatedAt: createdAt.Add(time.Minute)},
+				{EventID: "evt-2", Topic: "order.payment.completed", CreatedAt: createdAt},
+				{EventID: "evt-3", Topic: "order.payment.declined", CreatedAt: createdAt},
+			}, nil)
+		repo.On("ResetOutboxEventPending", ctx, "evt-2").Return(nil).Once()
+
+		reader := &fakeTopicReader{ids: map[string][]string{
+			"order.payment.completed": {"evt-1"},
+			"order.payment.declined":  {"evt-3"},
+		}}
+		metrics := &fakeReconcileMetrics{}
+		r := NewOutboxReconciler(zap.NewNop(), repo, reader, metrics, time.Minute, time.Hour, 5*time.Minute)
+
+		require.NoError(t, r.Reconcile(ctx))
+		require.Equal(t, map[string]int{"order.payment.completed": 1}, metrics.missing)
+		require.Equal(t, repository.OutboxBacklog{StalePending: 2, Failed: 1}, metrics.backlog)
+		// Топик читается с самого раннего created_at среди сверяемых событий
+		require.Equal(t, createdAt, reader.since["order.payment.completed"])
+	})
+
+	t.Run("window is read page by page", func(t *testing.T) {
+		sentAt := time.Now().Add(-30 * time.Minute)
+		repo := repoMocks.NewOrderRepository(t)
+		repo.On("GetOutboxBacklog", ctx, mock.AnythingOfType("time.Time")).Return(repository.OutboxBacklog{}, nil)
+		repo.On("GetSentOutboxEvents", ctx, mock.MatchedBy(func(cursor repository.SentOutboxCursor) bool {
+			return cursor.EventID == "" && cursor.SentAt.Before(sentAt)
+		}), 2).Return([]repository.OutboxEvent{
+			{EventID: "evt-1", Topic: "order.payment.completed", CreatedAt: createdAt, SentAt: sentAt},
+			{EventID: "evt-2", Topic: "order.payment.completed", CreatedAt: createdAt, SentAt: sentAt},
+		}, nil).Once()
+		// Следующая страница начинается после последнего события: у событий с тем же sent_at решает event_id
+		repo.On("GetSentOutboxEvents", ctx, repository.SentOutboxCursor{SentAt: sentAt, EventID: "evt-2"}, 2).
+			Return([]repository.OutboxEvent{
+				{EventID: "evt-3", Topic: "order.payment.declined", CreatedAt: createdAt, SentAt: sentAt},
+			}, nil).Once()
+		repo.On("ResetOutboxEventPending", ctx, "evt-3").Return(nil).Once()
+
+		reader := &fakeTopicReader{ids: map[string][]string{"order.payment.completed": {"evt-1", "evt-2"}}}
+		metrics := &fakeReconcileMetrics{}
+		r := NewOutboxReconciler(zap.NewNop(), repo, reader, metrics, time.Minute, time.Hour, 5*time.Minute)
+		r.batchSize = 2
+
+		require.NoError(t, r.Reconcile(ctx))
+		require.Equal(t, map[string]int{"order.payment.declined": 1}, metrics.missing)
+	})
+
+	t.Run("events sent after the pass started are left for the next pass", func(t *testing.T) {
+		repo := repoMocks.NewOrderRepository(t)
+		repo.On("GetOutboxBacklog", ctx, mock.AnythingOfType("time.Time")).Return(repository.OutboxBacklog{}, nil)
+		repo.On("GetSentOutboxEvents", ctx, mock.AnythingOfType("repository.SentOutboxCursor"), 1).
+			Return([]repository.OutboxEvent{
+				{EventID: "evt-1", Topic: "order.payment.completed", CreatedAt: createdAt, SentAt: time.Now().Add(time.Minute)},
+			}, nil).Once()
+
+		reader := &fakeTopicReader{ids: map[string][]string{"order.payment.completed": {"evt-1"}}}
+		r := NewOutboxReconciler(zap.NewNop(), repo, reader, nil, time.Minute, time.Hour, 5*time.Minute)
+		r.batchSize = 1
+
+		require.NoError(t, r.Reconcile(ctx))
+	})
+
+	t.Run("nothing sent", func(t *testing.T) {
+		repo := repoMocks.NewOrderRepository(t)
+		repo.On("GetOutboxBacklog", ctx, mock.AnythingOfType("time.Time")).Return(repository.OutboxBacklog{}, nil)
+		repo.On("GetSentOutboxEvents", ctx, mock.AnythingOfType("repository.SentOutboxCursor"), reconcileBatchSize).
+			Return([]repository.OutboxEvent{}, nil)
+
+		reader := &fakeTopicReader{}
+		r := NewOutboxReconciler(zap.NewNop(), repo, reader, nil, time.Minute, time.Hour, 5*time.Minute)
+
+		require.NoError(t, r.Reconcile(ctx))
+		require.Empty(t, reader.since)
+	})
+
+	t.Run("topic read error", func(t *testing.T) {
+		repo := repoMocks.NewOrderRepository(t)
+		repo.On("GetOutboxBacklog", ctx, mock.AnythingOfType("time.Time")).Return(repository.OutboxBacklog{}, nil)
+		repo.On("GetSentOutboxEvents", ctx, mock.AnythingOfType("repository.SentOutboxCursor"), reconcileBatchSize).
+			Return([]repository.OutboxEvent{{EventID: "evt-1", Topic: "order.payment.completed", CreatedAt: createdAt}}, nil)
+
+		reader := &fakeTopicReader{err: errors.New("broker unavailable")}
+		r := NewOutboxReconciler(zap.NewNop(), repo, reader, nil, time.Minute, time.Hour, 5*time.Minute)
+
+		err := r.Reconcile(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "broker unavailable")
+	})
+}
+
+func TestMessageEventID(t *testing.T) {
+	require.Equal(t, "evt-header", messageEventID(kafka.Message{
+		Headers: []kafka.Header{{Key: "event_id", Value: []byte("evt-header")}},
+		Value:   []byte(`{"event_id":"evt-body"}`),
+	}))
+	require.Equal(t, "evt-body", messageEventID(kafka.Message{Value: []byte(`{"event_id":"evt-body"}`)}))
+	require.Empty(t, messageEventID(kafka.Message{Value: []byte("not json")}))
+}
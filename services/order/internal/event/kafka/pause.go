@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGate позволяет приостановить и возобновить фоновую обработку без перезапуска процесса.
+// Пауза действует между сообщениями/батчами: уже начатая обработка доводится до конца.
+// Нулевое значение готово к использованию (обработка не приостановлена).
+type pauseGate struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{} // закрывается при Resume; nil, пока обработка не приостановлена
+}
+
+// Pause приостанавливает обработку. Возвращает false, если она уже была приостановлена.
+func (g *pauseGate) Pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return false
+	}
+	g.paused = true
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// Resume возобновляет обработку. Возвращает false, если она не была приостановлена.
+func (g *pauseGate) Resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		return false
+	}
+	g.paused = false
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+// Paused сообщает, приостановлена ли обработка
+func (g *pauseGate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.paused
+}
+
+// waitResumed блокируется, пока обработка приостановлена. Возвращает ошибку контекста, если он отменён раньше.
+func (g *pauseGate) waitResumed(ctx context.Context) error {
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+
+	if resumed == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseGate(t *testing.T) {
+	t.Run("not paused by default", func(t *testing.T) {
+		var g pauseGate
+
+		require.False(t, g.Paused())
+		require.False(t, g.Resume())
+		require.NoError(t, g.waitResumed(context.Background()))
+	})
+
+	t.Run("pause blocks until resume", func(t *testing.T) {
+		var g pauseGate
+		require.True(t, g.Pause())
+		require.False(t, g.Pause())
+		require.True(t, g.Paused())
+
+		done := make(chan error, 1)
+		go func() { done <- g.waitResumed(context.Background()) }()
+
+		select {
+		case <-done:
+			t.Fatal("waitResumed returned while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		require.True(t, g.Resume())
+		require.False(t, g.Paused())
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("waitResumed did not return after resume")
+		}
+	})
+
+	t.Run("cancelled context while paused", func(t *testing.T) {
+		var g pauseGate
+		g.Pause()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, g.waitResumed(ctx), context.Canceled)
+	})
+}
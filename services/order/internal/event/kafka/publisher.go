@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
 	"github.com/shestoi/GoBigTech/services/order/internal/service"
 )
 
@@ -42,14 +42,16 @@ func (p *KafkaPaymentEventPublisher) Close() error {
 // PublishOrderPaid публикует событие успешной оплаты заказа в Kafka
 func (p *KafkaPaymentEventPublisher) PublishOrderPaid(ctx context.Context, event service.OrderPaidEvent) error {
 	// Формируем JSON payload события - это данные, которые будут отправлены в Kafka
+	eventID := service.UUIDv7Generator{}.NewID() //генерируем уникальный ID для события
 	payload := map[string]interface{}{
-		"event_id":       uuid.New().String(), //генерируем уникальный ID для события
+		"event_id":       eventID,
 		"event_type":     "order.payment.completed",
-		"event_version":  1,                                     //версия события
+		"event_version":  outboxEventSchemaVersion,              //версия события
 		"occurred_at":    time.Now().UTC().Format(time.RFC3339), //время события
 		"order_id":       event.OrderID,                         //ID заказа
 		"user_id":        event.UserID,                          //ID пользователя
 		"amount":         event.Amount,                          //сумма оплаты
+		"currency":       event.Currency,                        //валюта оплаты
 		"payment_method": event.PaymentMethod,                   //метод оплаты
 	}
 
@@ -64,8 +66,9 @@ func (p *KafkaPaymentEventPublisher) PublishOrderPaid(ctx context.Context, event
 
 	// Отправляем сообщение в Kafka
 	message := kafka.Message{
-		Key:   []byte(event.OrderID), //ключ для сообщения - ID заказа
-		Value: valueBytes,            //значение для сообщения - данные события
+		Key:     []byte(event.OrderID), //ключ для сообщения - ID заказа
+		Value:   valueBytes,            //значение для сообщения - данные события
+		Headers: platformkafka.NewHeaders(ctx, "order.payment.completed", eventID, outboxEventSchemaVersion).Kafka(),
 	}
 
 	err = p.writer.WriteMessages(ctx, message)
@@ -0,0 +1,46 @@
+package probe
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// otelMetricsRecorder реализует MetricsRecorder через OpenTelemetry Meter.
+type otelMetricsRecorder struct {
+	runs         metric.Int64Counter
+	runDuration  metric.Float64Histogram
+	stepDuration metric.Float64Histogram
+}
+
+// NewOTelMetricsRecorder создаёт recorder метрик probe_runs_total, probe_run_duration_seconds и probe_step_duration_seconds
+func NewOTelMetricsRecorder() MetricsRecorder {
+	meter := otel.Meter("order-probe")
+	runs, _ := meter.Int64Counter("probe_runs_total", metric.WithDescription("Order probe runs by result"))
+	runDuration, _ := meter.Float64Histogram("probe_run_duration_seconds", metric.WithDescription("Order probe happy path duration"), metric.WithUnit("s"))
+	stepDuration, _ := meter.Float64Histogram("probe_step_duration_seconds", metric.WithDescription("Order probe step duration"), metric.WithUnit("s"))
+	return &otelMetricsRecorder{runs: runs, runDuration: runDuration, stepDuration: stepDuration}
+}
+
+func (r *otelMetricsRecorder) RecordStep(step string, success bool, duration time.Duration) {
+	r.stepDuration.Record(context.Background(), duration.Seconds(), metric.WithAttributes(
+		attribute.String("step", step),
+		attribute.String("result", result(success)),
+	))
+}
+
+func (r *otelMetricsRecorder) RecordRun(success bool, duration time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("result", result(success)))
+	r.runs.Add(context.Background(), 1, attrs)
+	r.runDuration.Record(context.Background(), duration.Seconds(), attrs)
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
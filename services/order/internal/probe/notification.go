@@ -0,0 +1,29 @@
+package probe
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresNotificationChecker читает историю уведомлений из inbox таблицы Notification
+type PostgresNotificationChecker struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresNotificationChecker создаёт checker поверх pool базы Notification
+func NewPostgresNotificationChecker(pool *pgxpool.Pool) *PostgresNotificationChecker {
+	return &PostgresNotificationChecker{pool: pool}
+}
+
+// NotificationSent проверяет, что событие eventType по заказу обработано Notification со статусом sent
+func (c *PostgresNotificationChecker) NotificationSent(ctx context.Context, orderID, eventType string) (bool, error) {
+	var sent bool
+	err := c.pool.QueryRow(ctx,
+		`SELECT EXISTS (
+		   SELECT 1 FROM notification_inbox_events
+		   WHERE order_id = $1 AND event_type = $2 AND status = 'sent'
+		 )`,
+		orderID, eventType).Scan(&sent)
+	return sent, err
+}
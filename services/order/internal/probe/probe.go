@@ -0,0 +1,283 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+)
+
+// Шаги прогона (label step в метриках)
+const (
+	StepLogin             = "login"
+	StepCreateOrder       = "create_order"
+	StepWaitAssembled     = "wait_assembled"
+	StepCheckNotification = "check_notification"
+)
+
+// События, уведомления по которым должны появиться в истории Notification
+var notificationEventTypes = []string{"order.payment.completed", "order.assembly.completed"}
+
+// Session - сессия пользователя probe в IAM
+type Session struct {
+	UserID    string
+	SessionID string
+}
+
+// Authenticator логинит пользователя probe
+type Authenticator interface {
+	Login(ctx context.Context) (Session, error)
+}
+
+// NotificationChecker проверяет историю уведомлений по заказу
+type NotificationChecker interface {
+	// NotificationSent возвращает true, если уведомление о событии eventType по заказу отправлено
+	NotificationSent(ctx context.Context, orderID, eventType string) (bool, error)
+}
+
+// MetricsRecorder записывает результаты прогонов probe
+type MetricsRecorder interface {
+	RecordStep(step string, success bool, duration time.Duration)
+	RecordRun(success bool, duration time.Duration)
+}
+
+// Config - параметры прогона
+type Config struct {
+	OrderURL        string
+	ProductID       string
+	Currency        string
+	AssemblyTimeout time.Duration // сколько ждать статуса assembled и уведомлений
+	PollInterval    time.Duration
+}
+
+// Probe периодически проходит happy path заказа как внешний клиент:
+// логин в IAM -> POST /orders -> ожидание assembled -> проверка истории уведомлений.
+// Используется как black-box монитор SLO.
+type Probe struct {
+	logger        *zap.Logger
+	httpClient    *http.Client
+	auth          Authenticator
+	notifications NotificationChecker // nil - проверка уведомлений пропускается
+	metrics       MetricsRecorder     // nil - метрики не пишутся
+	cfg           Config
+}
+
+// New создаёт новый probe
+func New(logger *zap.Logger, httpClient *http.Client, auth Authenticator, notifications NotificationChecker, metrics MetricsRecorder, cfg Config) *Probe {
+	return &Probe{
+		logger:        logger,
+		httpClient:    httpClient,
+		auth:          auth,
+		notifications: notifications,
+		metrics:       metrics,
+		cfg:           cfg,
+	}
+}
+
+// Start выполняет прогон сразу и затем каждые interval до отмены контекста
+func (p *Probe) Start(ctx context.Context, interval time.Duration) error {
+	p.logger.Info("starting order probe",
+		zap.String("order_url", p.cfg.OrderURL),
+		zap.String("product_id", p.cfg.ProductID),
+		zap.Duration("interval", interval),
+		zap.Bool("notification_check", p.notifications != nil),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.runAndLog(ctx)
+
+		select {
+		case <-ctx.Done():
+			p.logger.Info("order probe context cancelled, stopping")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAndLog выполняет один прогон и логирует результат
+func (p *Probe) runAndLog(ctx context.Context) {
+	start := time.Now()
+	orderID, err := p.RunOnce(ctx)
+	if ctx.Err() != nil {
+		return // остановка probe - не провал прогона
+	}
+	duration := time.Since(start)
+	if p.metrics != nil {
+		p.metrics.RecordRun(err == nil, duration)
+	}
+	if err != nil {
+		p.logger.Error("order probe run failed",
+			zap.Error(err),
+			zap.String("order_id", orderID),
+			zap.Duration("duration", duration),
+		)
+		return
+	}
+	p.logger.Info("order probe run succeeded",
+		zap.String("order_id", orderID),
+		zap.Duration("duration", duration),
+	)
+}
+
+// RunOnce выполняет один прогон happy path и возвращает ID созданного заказа (если дошли до его создания)
+func (p *Probe) RunOnce(ctx context.Context) (string, error) {
+	var session Session
+	if err := p.step(StepLogin, func() error {
+		var err error
+		session, err = p.auth.Login(ctx)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	var orderID string
+	if err := p.step(StepCreateOrder, func() error {
+		var err error
+		orderID, err = p.createOrder(ctx, session)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	if err := p.step(StepWaitAssembled, func() error {
+		return p.waitAssembled(ctx, session, orderID)
+	}); err != nil {
+		return orderID, err
+	}
+
+	if p.notifications == nil {
+		return orderID, nil
+	}
+	if err := p.step(StepCheckNotification, func() error {
+		return p.waitNotifications(ctx, orderID)
+	}); err != nil {
+		return orderID, err
+	}
+
+	return orderID, nil
+}
+
+// step выполняет шаг прогона, записывает его длительность и оборачивает ошибку именем шага
+func (p *Probe) step(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	if p.metrics != nil {
+		p.metrics.RecordStep(name, err == nil, time.Since(start))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// createOrder создаёт заказ на тестовый товар через POST /orders
+func (p *Probe) createOrder(ctx context.Context, session Session) (string, error) {
+	currency := p.cfg.Currency
+	body, err := json.Marshal(orderapi.OrderRequest{
+		UserId:   session.UserID,
+		Items:    []orderapi.OrderItem{{ProductId: p.cfg.ProductID, Quantity: 1}},
+		Currency: &currency,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.OrderURL+"/orders", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-session-id", session.SessionID)
+
+	var order orderapi.Order
+	if err := p.do(req, http.StatusCreated, &order); err != nil {
+		return "", err
+	}
+	if order.Status != "paid" {
+		return order.Id, fmt.Errorf("unexpected order status %q", order.Status)
+	}
+	return order.Id, nil
+}
+
+// waitAssembled опрашивает GET /orders/{id}, пока заказ не станет assembled
+func (p *Probe) waitAssembled(ctx context.Context, session Session, orderID string) error {
+	return p.poll(ctx, func() (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.OrderURL+"/orders/"+url.PathEscape(orderID), nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("x-session-id", session.SessionID)
+
+		var order orderapi.Order
+		if err := p.do(req, http.StatusOK, &order); err != nil {
+			return false, err
+		}
+		return order.Status == "assembled", nil
+	})
+}
+
+// waitNotifications ждёт, пока в истории Notification появятся уведомления об оплате и сборке заказа
+func (p *Probe) waitNotifications(ctx context.Context, orderID string) error {
+	return p.poll(ctx, func() (bool, error) {
+		for _, eventType := range notificationEventTypes {
+			sent, err := p.notifications.NotificationSent(ctx, orderID, eventType)
+			if err != nil || !sent {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// poll вызывает check каждые PollInterval, пока он не вернёт true, ошибку или не истечёт AssemblyTimeout
+func (p *Probe) poll(ctx context.Context, check func() (bool, error)) error {
+	deadline := time.Now().Add(p.cfg.AssemblyTimeout)
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s", p.cfg.AssemblyTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.cfg.PollInterval):
+		}
+	}
+}
+
+// do выполняет HTTP запрос и декодирует JSON ответ, если статус совпал с ожидаемым
+func (p *Probe) do(req *http.Request, expectedStatus int, out interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: unexpected status %d: %s", req.Method, req.URL.Path, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", req.Method, req.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	orderapi "github.com/shestoi/GoBigTech/services/order/api"
+)
+
+type fakeAuth struct {
+	err error
+}
+
+func (a *fakeAuth) Login(context.Context) (Session, error) {
+	if a.err != nil {
+		return Session{}, a.err
+	}
+	return Session{UserID: "probe-user", SessionID: "probe-session"}, nil
+}
+
+type fakeNotifications struct {
+	sent map[string]bool // eventType -> sent
+}
+
+func (n *fakeNotifications) NotificationSent(_ context.Context, _, eventType string) (bool, error) {
+	return n.sent[eventType], nil
+}
+
+type fakeMetrics struct {
+	mu    sync.Mutex
+	steps map[string]bool
+}
+
+func (m *fakeMetrics) RecordStep(step string, success bool, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.steps == nil {
+		m.steps = make(map[string]bool)
+	}
+	m.steps[step] = success
+}
+
+func (m *fakeMetrics) RecordRun(bool, time.Duration) {}
+
+// newOrderServer - Order API, в котором заказ становится assembled после assembledAfter опросов
+func newOrderServer(t *testing.T, assembledAfter int) *httptest.Server {
+	t.Helper()
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "probe-session", r.Header.Get("x-session-id"))
+		var req orderapi.OrderRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "probe-user", req.UserId)
+		require.Equal(t, []orderapi.OrderItem{{ProductId: "probe-product", Quantity: 1}}, req.Items)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(orderapi.Order{Id: "order-1", UserId: req.UserId, Status: "paid"})
+	})
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		status := "paid"
+		if polls > assembledAfter {
+			status = "assembled"
+		}
+		_ = json.NewEncoder(w).Encode(orderapi.Order{Id: r.PathValue("id"), Status: status})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestProbe(srv *httptest.Server, auth Authenticator, notifications NotificationChecker, metrics MetricsRecorder) *Probe {
+	return New(zap.NewNop(), srv.Client(), auth, notifications, metrics, Config{
+		OrderURL:        srv.URL,
+		ProductID:       "probe-product",
+		Currency:        "RUB",
+		AssemblyTimeout: 200 * time.Millisecond,
+		PollInterval:    5 * time.Millisecond,
+	})
+}
+
+func TestProbe_RunOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("happy path", func(t *testing.T) {
+		metrics := &fakeMetrics{}
+		notifications := &fakeNotifications{sent: map[string]bool{
+			"order.payment.completed":  true,
+			"order.assembly.completed": true,
+		}}
+		p := newTestProbe(newOrderServer(t, 2), &fakeAuth{}, notifications, metrics)
+
+		orderID, err := p.RunOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "order-1", orderID)
+		require.Equal(t, map[string]bool{
+			StepLogin:             true,
+			StepCreateOrder:       true,
+			StepWaitAssembled:     true,
+			StepCheckNotification: true,
+		}, metrics.steps)
+	})
+
+	t.Run("login failed", func(t *testing.T) {
+		metrics := &fakeMetrics{}
+		p := newTestProbe(newOrderServer(t, 0), &fakeAuth{err: errors.New("invalid credentials")}, nil, metrics)
+
+		_, err := p.RunOnce(ctx)
+		require.ErrorContains(t, err, "login: invalid credentials")
+		require.Equal(t, map[string]bool{StepLogin: false}, metrics.steps)
+	})
+
+	t.Run("order not assembled in time", func(t *testing.T) {
+		p := newTestProbe(newOrderServer(t, 1000), &fakeAuth{}, nil, nil)
+
+		orderID, err := p.RunOnce(ctx)
+		require.ErrorContains(t, err, "wait_assembled: timed out")
+		require.Equal(t, "order-1", orderID)
+	})
+
+	t.Run("notification missing", func(t *testing.T) {
+		notifications := &fakeNotifications{sent: map[string]bool{"order.payment.completed": true}}
+		p := newTestProbe(newOrderServer(t, 0), &fakeAuth{}, notifications, nil)
+
+		_, err := p.RunOnce(ctx)
+		require.ErrorContains(t, err, "check_notification: timed out")
+	})
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval - как часто удаляются неактивные bucket'ы (чтобы map не рос бесконечно)
+const sweepInterval = time.Minute
+
+// bucket хранит состояние token bucket для одного ключа
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter реализует in-memory token bucket с отдельным bucket на каждый ключ (user_id, IP).
+// Состояние не разделяется между репликами: для нескольких инстансов лимит действует на каждый инстанс.
+type Limiter struct {
+	mu        sync.Mutex
+	rate      float64 // токенов в секунду
+	burst     float64 // максимальное количество токенов в bucket
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLimiter создаёт новый Limiter.
+// rate - сколько запросов в секунду восполняется, burst - сколько запросов можно сделать подряд.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return NewLimiterWithClock(rate, burst, time.Now)
+}
+
+// NewLimiterWithClock создаёт новый Limiter с кастомными часами (для тестов)
+func NewLimiterWithClock(rate float64, burst int, now func() time.Time) *Limiter {
+	return &Limiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: now(),
+		now:       now,
+	}
+}
+
+// Allow списывает один токен для key.
+// Возвращает true, если запрос разрешён, иначе false и время, через которое появится следующий токен.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	// Восполняем токены за прошедшее время
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	retryAfter := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, retryAfter
+}
+
+// sweep удаляет bucket'ы, которые успели полностью восполниться: они эквивалентны новому bucket
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	fullAfter := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= fullAfter {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock позволяет управлять временем в тестах
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Run("burst is allowed, then requests are rejected with retry after", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		limiter := NewLimiterWithClock(1, 3, clock.Now)
+
+		for i := 0; i < 3; i++ {
+			allowed, _ := limiter.Allow("user:1")
+			require.True(t, allowed, "request %d should be allowed", i)
+		}
+
+		allowed, retryAfter := limiter.Allow("user:1")
+		require.False(t, allowed)
+		require.Equal(t, time.Second, retryAfter)
+	})
+
+	t.Run("tokens are refilled over time", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		limiter := NewLimiterWithClock(2, 1, clock.Now)
+
+		allowed, _ := limiter.Allow("user:1")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow("user:1")
+		require.False(t, allowed)
+
+		clock.now = clock.now.Add(500 * time.Millisecond)
+		allowed, _ = limiter.Allow("user:1")
+		require.True(t, allowed)
+	})
+
+	t.Run("keys have independent buckets", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		limiter := NewLimiterWithClock(1, 1, clock.Now)
+
+		allowed, _ := limiter.Allow("user:1")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow("user:2")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow("user:1")
+		require.False(t, allowed)
+	})
+
+	t.Run("idle buckets are swept", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		limiter := NewLimiterWithClock(1, 1, clock.Now)
+
+		limiter.Allow("user:1")
+		require.Len(t, limiter.buckets, 1)
+
+		clock.now = clock.now.Add(sweepInterval)
+		limiter.Allow("user:2")
+		require.Len(t, limiter.buckets, 1)
+		require.Contains(t, limiter.buckets, "user:2")
+	})
+}
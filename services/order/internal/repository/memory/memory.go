@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 )
 
@@ -25,10 +26,22 @@ func NewMemoryRepository() *MemoryRepository {
 
 // Save сохраняет заказ в памяти
 // Защищён мьютексом для безопасного доступа из разных горутин
+// Смена статуса проверяется по domain.Transition, как в PostgreSQL репозитории
 func (r *MemoryRepository) Save(ctx context.Context, order repository.Order) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	existing, exists := r.orders[order.ID]
+	if !exists || existing.Status != order.Status {
+		from := domain.StatusNew
+		if exists {
+			from = domain.Status(existing.Status)
+		}
+		if err := domain.Transition(from, domain.Status(order.Status)); err != nil {
+			return err
+		}
+	}
+
 	// Если у заказа нет CreatedAt, устанавливаем текущее время
 	if order.CreatedAt == 0 {
 		order.CreatedAt = time.Now().Unix()
@@ -40,12 +53,12 @@ func (r *MemoryRepository) Save(ctx context.Context, order repository.Order) err
 
 // GetByID получает заказ по ID из памяти
 // Защищён мьютексом для безопасного доступа из разных горутин
-func (r *MemoryRepository) GetByID(ctx context.Context, id string) (repository.Order, error) {
+func (r *MemoryRepository) GetByID(ctx context.Context, id string, opts repository.GetOptions) (repository.Order, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	order, exists := r.orders[id]
-	if !exists {
+	if !exists || (order.ArchivedAt != 0 && !opts.IncludeArchived) {
 		return repository.Order{}, repository.ErrNotFound
 	}
 
@@ -16,9 +16,55 @@ type OrderRepository struct {
 	mock.Mock
 }
 
-// GetByID provides a mock function with given fields: ctx, id
-func (_m *OrderRepository) GetByID(ctx context.Context, id string) (repository.Order, error) {
-	ret := _m.Called(ctx, id)
+// ArchiveCompletedBefore provides a mock function with given fields: ctx, before
+func (_m *OrderRepository) ArchiveCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveCompletedBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangeStatusTx provides a mock function with given fields: ctx, audit, event
+func (_m *OrderRepository) ChangeStatusTx(ctx context.Context, audit repository.StatusAudit, event repository.OutboxEvent) error {
+	ret := _m.Called(ctx, audit, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeStatusTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.StatusAudit, repository.OutboxEvent) error); ok {
+		r0 = rf(ctx, audit, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, id, opts
+func (_m *OrderRepository) GetByID(ctx context.Context, id string, opts repository.GetOptions) (repository.Order, error) {
+	ret := _m.Called(ctx, id, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByID")
@@ -26,17 +72,45 @@ func (_m *OrderRepository) GetByID(ctx context.Context, id string) (repository.O
 
 	var r0 repository.Order
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (repository.Order, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.GetOptions) (repository.Order, error)); ok {
+		return rf(ctx, id, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) repository.Order); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.GetOptions) repository.Order); ok {
+		r0 = rf(ctx, id, opts)
 	} else {
 		r0 = ret.Get(0).(repository.Order)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, repository.GetOptions) error); ok {
+		r1 = rf(ctx, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOutboxBacklog provides a mock function with given fields: ctx, staleBefore
+func (_m *OrderRepository) GetOutboxBacklog(ctx context.Context, staleBefore time.Time) (repository.OutboxBacklog, error) {
+	ret := _m.Called(ctx, staleBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOutboxBacklog")
+	}
+
+	var r0 repository.OutboxBacklog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (repository.OutboxBacklog, error)); ok {
+		return rf(ctx, staleBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) repository.OutboxBacklog); ok {
+		r0 = rf(ctx, staleBefore)
+	} else {
+		r0 = ret.Get(0).(repository.OutboxBacklog)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, staleBefore)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -74,9 +148,39 @@ func (_m *OrderRepository) GetPendingOutboxEvents(ctx context.Context, limit int
 	return r0, r1
 }
 
-// HandleAssemblyCompletedTx provides a mock function with given fields: ctx, eventID, eventType, occurredAt, orderID
-func (_m *OrderRepository) HandleAssemblyCompletedTx(ctx context.Context, eventID string, eventType string, occurredAt time.Time, orderID string) (bool, int64, error) {
-	ret := _m.Called(ctx, eventID, eventType, occurredAt, orderID)
+// GetSentOutboxEvents provides a mock function with given fields: ctx, after, limit
+func (_m *OrderRepository) GetSentOutboxEvents(ctx context.Context, after repository.SentOutboxCursor, limit int) ([]repository.OutboxEvent, error) {
+	ret := _m.Called(ctx, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSentOutboxEvents")
+	}
+
+	var r0 []repository.OutboxEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.SentOutboxCursor, int) ([]repository.OutboxEvent, error)); ok {
+		return rf(ctx, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.SentOutboxCursor, int) []repository.OutboxEvent); ok {
+		r0 = rf(ctx, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.SentOutboxCursor, int) error); ok {
+		r1 = rf(ctx, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleAssemblyCompletedTx provides a mock function with given fields: ctx, eventID, eventType, occurredAt, orderID, items, assembled
+func (_m *OrderRepository) HandleAssemblyCompletedTx(ctx context.Context, eventID string, eventType string, occurredAt time.Time, orderID string, items []repository.ItemStatusChange, assembled repository.OutboxEvent) (bool, int64, error) {
+	ret := _m.Called(ctx, eventID, eventType, occurredAt, orderID, items, assembled)
 
 	if len(ret) == 0 {
 		panic("no return value specified for HandleAssemblyCompletedTx")
@@ -85,23 +189,23 @@ func (_m *OrderRepository) HandleAssemblyCompletedTx(ctx context.Context, eventI
 	var r0 bool
 	var r1 int64
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, string) (bool, int64, error)); ok {
-		return rf(ctx, eventID, eventType, occurredAt, orderID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, string, []repository.ItemStatusChange, repository.OutboxEvent) (bool, int64, error)); ok {
+		return rf(ctx, eventID, eventType, occurredAt, orderID, items, assembled)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, string) bool); ok {
-		r0 = rf(ctx, eventID, eventType, occurredAt, orderID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, string, []repository.ItemStatusChange, repository.OutboxEvent) bool); ok {
+		r0 = rf(ctx, eventID, eventType, occurredAt, orderID, items, assembled)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, string) int64); ok {
-		r1 = rf(ctx, eventID, eventType, occurredAt, orderID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, string, []repository.ItemStatusChange, repository.OutboxEvent) int64); ok {
+		r1 = rf(ctx, eventID, eventType, occurredAt, orderID, items, assembled)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, string, time.Time, string) error); ok {
-		r2 = rf(ctx, eventID, eventType, occurredAt, orderID)
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, time.Time, string, []repository.ItemStatusChange, repository.OutboxEvent) error); ok {
+		r2 = rf(ctx, eventID, eventType, occurredAt, orderID, items, assembled)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -109,6 +213,36 @@ func (_m *OrderRepository) HandleAssemblyCompletedTx(ctx context.Context, eventI
 	return r0, r1, r2
 }
 
+// List provides a mock function with given fields: ctx, filter
+func (_m *OrderRepository) List(ctx context.Context, filter repository.ListFilter) ([]repository.Order, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []repository.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.ListFilter) ([]repository.Order, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.ListFilter) []repository.Order); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MarkOutboxEventFailed provides a mock function with given fields: ctx, eventID, errMsg
 func (_m *OrderRepository) MarkOutboxEventFailed(ctx context.Context, eventID string, errMsg string) error {
 	ret := _m.Called(ctx, eventID, errMsg)
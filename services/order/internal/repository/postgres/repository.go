@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 )
 
@@ -25,6 +30,7 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 // Save сохраняет заказ в PostgreSQL
 // Использует транзакцию для атомарного сохранения order и order_items
+// Смена статуса проверяется по domain.Transition: недопустимый переход - *domain.TransitionError, заказ не меняется
 func (r *Repository) Save(ctx context.Context, order repository.Order) error {
 	// Начинаем транзакцию
 	tx, err := r.pool.Begin(ctx)
@@ -34,28 +40,36 @@ func (r *Repository) Save(ctx context.Context, order repository.Order) error {
 	// Гарантируем откат транзакции в случае ошибки
 	defer tx.Rollback(ctx)
 
+	if err = checkStatusTransitionTx(ctx, tx, order); err != nil {
+		return err
+	}
+
 	// Сохраняем order
 	// Если CreatedAt == 0, используем DEFAULT now() из БД
 	var createdAt time.Time
 	if order.CreatedAt > 0 {
 		createdAt = time.Unix(order.CreatedAt, 0)
 		_, err = tx.Exec(ctx,
-			`INSERT INTO orders (id, user_id, status, created_at) 
-			 VALUES ($1, $2, $3, $4) 
+			`INSERT INTO orders (id, user_id, status, total_amount, currency, created_at) 
+			 VALUES ($1, $2, $3, $4, $5, $6) 
 			 ON CONFLICT (id) DO UPDATE SET 
 			   user_id = EXCLUDED.user_id,
 			   status = EXCLUDED.status,
+			   total_amount = EXCLUDED.total_amount,
+			   currency = EXCLUDED.currency,
 			   created_at = EXCLUDED.created_at`,
-			order.ID, order.UserID, order.Status, createdAt)
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency, createdAt)
 	} else {
 		// Используем DEFAULT now() из БД
 		_, err = tx.Exec(ctx,
-			`INSERT INTO orders (id, user_id, status) 
-			 VALUES ($1, $2, $3) 
+			`INSERT INTO orders (id, user_id, status, total_amount, currency) 
+			 VALUES ($1, $2, $3, $4, $5) 
 			 ON CONFLICT (id) DO UPDATE SET 
 			   user_id = EXCLUDED.user_id,
-			   status = EXCLUDED.status`,
-			order.ID, order.UserID, order.Status)
+			   status = EXCLUDED.status,
+			   total_amount = EXCLUDED.total_amount,
+			   currency = EXCLUDED.currency`,
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency)
 	}
 	if err != nil {
 		return err
@@ -72,9 +86,9 @@ func (r *Repository) Save(ctx context.Context, order repository.Order) error {
 	// Сохраняем order_items
 	for _, item := range order.Items {
 		_, err = tx.Exec(ctx,
-			`INSERT INTO order_items (order_id, product_id, quantity) 
-			 VALUES ($1, $2, $3)`,
-			order.ID, item.ProductID, item.Quantity)
+			`INSERT INTO order_items (order_id, product_id, quantity, status, unit_price, currency) 
+			 VALUES ($1, $2, $3, $4, $5, $6)`,
+			order.ID, item.ProductID, item.Quantity, itemStatusOrDefault(item.Status), item.UnitPrice, item.Currency)
 		if err != nil {
 			return err
 		}
@@ -90,15 +104,18 @@ func (r *Repository) Save(ctx context.Context, order repository.Order) error {
 
 // GetByID получает заказ по ID из PostgreSQL
 // Собирает order и order_items в доменную модель
-func (r *Repository) GetByID(ctx context.Context, id string) (repository.Order, error) {
+// Архивные заказы возвращаются только при opts.IncludeArchived=true, иначе ErrNotFound.
+// При opts.TolerateItemErrors ошибка чтения order_items не прерывает запрос: заказ возвращается без позиций с ItemsError
+func (r *Repository) GetByID(ctx context.Context, id string, opts repository.GetOptions) (repository.Order, error) {
 	// Получаем order
 	var order repository.Order
 	var createdAt time.Time
+	var archivedAt *time.Time
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, user_id, status, created_at 
+		`SELECT id, user_id, status, total_amount, currency, created_at, archived_at 
 		 FROM orders 
-		 WHERE id = $1`,
-		id).Scan(&order.ID, &order.UserID, &order.Status, &createdAt)
+		 WHERE id = $1 AND ($2 OR archived_at IS NULL)`,
+		id, opts.IncludeArchived).Scan(&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.Currency, &createdAt, &archivedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return repository.Order{}, repository.ErrNotFound
@@ -108,34 +125,165 @@ func (r *Repository) GetByID(ctx context.Context, id string) (repository.Order,
 
 	// Конвертируем время в Unix timestamp
 	order.CreatedAt = createdAt.Unix()
+	if archivedAt != nil {
+		order.ArchivedAt = archivedAt.Unix()
+	}
 
 	// Получаем order_items
+	order.Items, err = r.getItems(ctx, id)
+	if err != nil {
+		// Отмена запроса - не повреждение данных, её не скрываем и в мягком режиме
+		if !opts.TolerateItemErrors || ctx.Err() != nil {
+			return repository.Order{}, err
+		}
+		order.Items = make([]repository.OrderItem, 0)
+		order.ItemsError = err.Error()
+	}
+
+	return order, nil
+}
+
+// getItems читает позиции заказа, упорядоченные по product_id
+func (r *Repository) getItems(ctx context.Context, orderID string) ([]repository.OrderItem, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT product_id, quantity 
+		`SELECT product_id, quantity, status, unit_price, currency 
 		 FROM order_items 
 		 WHERE order_id = $1 
 		 ORDER BY product_id`,
-		id)
+		orderID)
 	if err != nil {
-		return repository.Order{}, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	// Собираем items
-	order.Items = make([]repository.OrderItem, 0)
+	items := make([]repository.OrderItem, 0)
 	for rows.Next() {
 		var item repository.OrderItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
-			return repository.Order{}, err
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.Status, &item.UnitPrice, &item.Currency); err != nil {
+			return nil, err
 		}
-		order.Items = append(order.Items, item)
+		items = append(items, item)
 	}
 
 	if err = rows.Err(); err != nil {
-		return repository.Order{}, err
+		return nil, err
 	}
 
-	return order, nil
+	return items, nil
+}
+
+// List возвращает заказы из PostgreSQL по фильтру (пользователь и/или товар), новые первыми
+// Items всех заказов загружаются одним запросом (все позиции заказа, а не только совпавший товар)
+func (r *Repository) List(ctx context.Context, filter repository.ListFilter) ([]repository.Order, error) {
+	// Условия собираем только из заданных полей фильтра, чтобы planner использовал индексы
+	// idx_orders_user_id и idx_order_items_product_id
+	conditions := make([]string, 0, 3)
+	args := make([]any, 0, 3)
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("o.user_id = $%d", len(args)))
+	}
+	if filter.ProductID != "" {
+		args = append(args, filter.ProductID)
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = o.id AND i.product_id = $%d)", len(args)))
+	}
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "o.archived_at IS NULL")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.pool.Query(ctx,
+		fmt.Sprintf(`SELECT o.id, o.user_id, o.status, o.total_amount, o.currency, o.created_at, o.archived_at 
+		 FROM orders o 
+		 %s 
+		 ORDER BY o.created_at DESC, o.id 
+		 LIMIT $%d`, where, len(args)),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]repository.Order, 0)
+	orderIDs := make([]string, 0)
+	for rows.Next() {
+		var order repository.Order
+		var createdAt time.Time
+		var archivedAt *time.Time
+		if err := rows.Scan(&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.Currency, &createdAt, &archivedAt); err != nil {
+			return nil, err
+		}
+		order.CreatedAt = createdAt.Unix()
+		if archivedAt != nil {
+			order.ArchivedAt = archivedAt.Unix()
+		}
+		order.Items = make([]repository.OrderItem, 0)
+		orders = append(orders, order)
+		orderIDs = append(orderIDs, order.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	// Получаем order_items для всех найденных заказов
+	itemRows, err := r.pool.Query(ctx,
+		`SELECT order_id, product_id, quantity, status, unit_price, currency 
+		 FROM order_items 
+		 WHERE order_id = ANY($1) 
+		 ORDER BY order_id, product_id`,
+		orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	indexByID := make(map[string]int, len(orders))
+	for i, order := range orders {
+		indexByID[order.ID] = i
+	}
+	for itemRows.Next() {
+		var orderID string
+		var item repository.OrderItem
+		if err := itemRows.Scan(&orderID, &item.ProductID, &item.Quantity, &item.Status, &item.UnitPrice, &item.Currency); err != nil {
+			return nil, err
+		}
+		i := indexByID[orderID]
+		orders[i].Items = append(orders[i].Items, item)
+	}
+	if err = itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// ArchiveCompletedBefore помечает archived_at для заказов в конечных статусах (domain.TerminalStatuses),
+// созданных раньше before
+func (r *Repository) ArchiveCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	terminal := domain.TerminalStatuses()
+	statuses := make([]string, 0, len(terminal))
+	for _, status := range terminal {
+		statuses = append(statuses, string(status))
+	}
+
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE orders SET archived_at = now() 
+		 WHERE status = ANY($2) AND created_at < $1 AND archived_at IS NULL`,
+		before, statuses)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
 
 // HandleAssemblyCompletedTx обрабатывает событие завершения сборки заказа в транзакции
@@ -143,7 +291,10 @@ func (r *Repository) GetByID(ctx context.Context, id string) (repository.Order,
 //   - inserted=true если событие впервые обработано (вставлено в inbox)
 //   - inserted=false если событие уже было обработано (duplicate event_id)
 //   - rowsAffected - количество обновлённых строк в orders (0 или 1)
-func (r *Repository) HandleAssemblyCompletedTx(ctx context.Context, eventID, eventType string, occurredAt time.Time, orderID string) (inserted bool, rowsAffected int64, err error) {
+//
+// Статусы позиций меняются только вместе с переходом заказа paid -> assembled и только у позиций в статусе reserved.
+// Событие assembled (order.assembled) попадает в outbox только при этом переходе: дубликат или уже собранный заказ его не порождают
+func (r *Repository) HandleAssemblyCompletedTx(ctx context.Context, eventID, eventType string, occurredAt time.Time, orderID string, items []repository.ItemStatusChange, assembled repository.OutboxEvent) (inserted bool, rowsAffected int64, err error) {
 	// Начинаем транзакцию
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -172,16 +323,31 @@ func (r *Repository) HandleAssemblyCompletedTx(ctx context.Context, eventID, eve
 	inserted = true
 
 	// Обновляем статус заказа: paid -> assembled
+	if err = domain.Transition(domain.StatusPaid, domain.StatusAssembled); err != nil {
+		return false, 0, err
+	}
 	result, err := tx.Exec(ctx,
-		`UPDATE orders SET status = 'assembled' 
-		 WHERE id = $1 AND status = 'paid'`,
-		orderID)
+		`UPDATE orders SET status = $3 
+		 WHERE id = $1 AND status = $2`,
+		orderID, domain.StatusPaid, domain.StatusAssembled)
 	if err != nil {
 		return false, 0, err
 	}
 
 	rowsAffected = result.RowsAffected() //получаем количество обновлённых строк
 
+	if rowsAffected > 0 {
+		if err = updateAssembledItemsTx(ctx, tx, orderID, items); err != nil {
+			return false, 0, err
+		}
+		if err = insertOutboxEventTx(ctx, tx, assembled.EventID, assembled.EventType, assembled.OccurredAt, orderID, assembled.Payload, assembled.Topic); err != nil {
+			return false, 0, err
+		}
+		if err = enqueueWebhookDeliveriesTx(ctx, tx, eventID, eventType, occurredAt, orderID); err != nil {
+			return false, 0, err
+		}
+	}
+
 	// Коммитим транзакцию
 	if err = tx.Commit(ctx); err != nil {
 		return false, 0, err
@@ -190,7 +356,62 @@ func (r *Repository) HandleAssemblyCompletedTx(ctx context.Context, eventID, eve
 	return inserted, rowsAffected, nil
 }
 
+// updateAssembledItemsTx выставляет статусы позиций после сборки
+// Без items (события старого формата) заказ считается собранным целиком
+// Статус позиции проверяется по domain.TransitionItem из reserved: недопустимый статус откатывает всю транзакцию
+func updateAssembledItemsTx(ctx context.Context, tx pgx.Tx, orderID string, items []repository.ItemStatusChange) error {
+	if len(items) == 0 {
+		_, err := tx.Exec(ctx,
+			`UPDATE order_items SET status = $3 
+			 WHERE order_id = $1 AND status = $2`,
+			orderID, domain.ItemStatusReserved, domain.ItemStatusAssembled)
+		return err
+	}
+
+	for _, item := range items {
+		if err := domain.TransitionItem(domain.ItemStatusReserved, domain.ItemStatus(item.Status)); err != nil {
+			return fmt.Errorf("item %s: %w", item.ProductID, err)
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE order_items SET status = $3 
+			 WHERE order_id = $1 AND product_id = $2 AND status = $4`,
+			orderID, item.ProductID, item.Status, domain.ItemStatusReserved)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatusTransitionTx проверяет смену статуса заказа перед upsert в рамках транзакции tx
+// Строка заказа блокируется (FOR UPDATE) до конца транзакции, чтобы статус не сменили между проверкой и записью.
+// Новый заказ проверяется как переход из domain.StatusNew; повторное сохранение с тем же статусом - не переход
+func checkStatusTransitionTx(ctx context.Context, tx pgx.Tx, order repository.Order) error {
+	from := domain.StatusNew
+	var current string
+	err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, order.ID).Scan(&current)
+	switch {
+	case err == nil:
+		if current == order.Status {
+			return nil
+		}
+		from = domain.Status(current)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+	return domain.Transition(from, domain.Status(order.Status))
+}
+
+// itemStatusOrDefault возвращает статус позиции для сохранения: новые позиции - reserved
+func itemStatusOrDefault(status string) string {
+	if status == "" {
+		return repository.ItemStatusReserved
+	}
+	return status
+}
+
 // SaveWithOutbox сохраняет заказ и добавляет событие в outbox в одной транзакции
+// Смена статуса проверяется так же, как в Save
 func (r *Repository) SaveWithOutbox(ctx context.Context, order repository.Order, eventID, eventType string, occurredAt time.Time, payload []byte, topic string) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -198,26 +419,34 @@ func (r *Repository) SaveWithOutbox(ctx context.Context, order repository.Order,
 	}
 	defer tx.Rollback(ctx)
 
+	if err = checkStatusTransitionTx(ctx, tx, order); err != nil {
+		return err
+	}
+
 	// Сохраняем order
 	var createdAt time.Time
 	if order.CreatedAt > 0 {
 		createdAt = time.Unix(order.CreatedAt, 0)
 		_, err = tx.Exec(ctx,
-			`INSERT INTO orders (id, user_id, status, created_at) 
-			 VALUES ($1, $2, $3, $4) 
+			`INSERT INTO orders (id, user_id, status, total_amount, currency, created_at) 
+			 VALUES ($1, $2, $3, $4, $5, $6) 
 			 ON CONFLICT (id) DO UPDATE SET 
 			   user_id = EXCLUDED.user_id,
 			   status = EXCLUDED.status,
+			   total_amount = EXCLUDED.total_amount,
+			   currency = EXCLUDED.currency,
 			   created_at = EXCLUDED.created_at`,
-			order.ID, order.UserID, order.Status, createdAt)
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency, createdAt)
 	} else {
 		_, err = tx.Exec(ctx,
-			`INSERT INTO orders (id, user_id, status) 
-			 VALUES ($1, $2, $3) 
+			`INSERT INTO orders (id, user_id, status, total_amount, currency) 
+			 VALUES ($1, $2, $3, $4, $5) 
 			 ON CONFLICT (id) DO UPDATE SET 
 			   user_id = EXCLUDED.user_id,
-			   status = EXCLUDED.status`,
-			order.ID, order.UserID, order.Status)
+			   status = EXCLUDED.status,
+			   total_amount = EXCLUDED.total_amount,
+			   currency = EXCLUDED.currency`,
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency)
 	}
 	if err != nil {
 		return err
@@ -232,31 +461,47 @@ func (r *Repository) SaveWithOutbox(ctx context.Context, order repository.Order,
 	// Сохраняем order_items
 	for _, item := range order.Items {
 		_, err = tx.Exec(ctx,
-			`INSERT INTO order_items (order_id, product_id, quantity) 
-			 VALUES ($1, $2, $3)`,
-			order.ID, item.ProductID, item.Quantity)
+			`INSERT INTO order_items (order_id, product_id, quantity, status, unit_price, currency) 
+			 VALUES ($1, $2, $3, $4, $5, $6)`,
+			order.ID, item.ProductID, item.Quantity, itemStatusOrDefault(item.Status), item.UnitPrice, item.Currency)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Добавляем событие в outbox
-	_, err = tx.Exec(ctx,
-		`INSERT INTO order_outbox_events (event_id, event_type, occurred_at, aggregate_id, payload, topic, status)
-		 VALUES ($1, $2, $3, $4, $5, $6, 'pending')`,
-		eventID, eventType, occurredAt, order.ID, payload, topic)
-	if err != nil {
+	if err = insertOutboxEventTx(ctx, tx, eventID, eventType, occurredAt, order.ID, payload, topic); err != nil {
+		return err
+	}
+
+	// Callback'и на webhook'и пользователя о новом статусе заказа
+	if err = enqueueWebhookDeliveriesTx(ctx, tx, eventID, eventType, occurredAt, order.ID); err != nil {
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
+// insertOutboxEventTx добавляет pending событие в outbox в рамках транзакции tx
+func insertOutboxEventTx(ctx context.Context, tx pgx.Tx, eventID, eventType string, occurredAt time.Time, aggregateID string, payload []byte, topic string) error {
+	// Сохраняем trace context и request_id вместе с событием: dispatcher передаст их в заголовки Kafka
+	headers, err := json.Marshal(platformobservability.MessageHeaders(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_outbox_events (event_id, event_type, occurred_at, aggregate_id, payload, topic, status, headers)
+		 VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7)`,
+		eventID, eventType, occurredAt, aggregateID, payload, topic, headers)
+	return err
+}
+
 // GetPendingOutboxEvents получает pending события из outbox для отправки
 // pending - это статус события, которое нужно отправить
 func (r *Repository) GetPendingOutboxEvents(ctx context.Context, limit int) ([]repository.OutboxEvent, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT event_id, event_type, occurred_at, aggregate_id, payload, topic, status, attempts, last_error, created_at, sent_at
+		`SELECT event_id, event_type, occurred_at, aggregate_id, payload, topic, status, attempts, last_error, created_at, sent_at, headers
 		 FROM order_outbox_events
 		 WHERE status = 'pending' 
 		 ORDER BY created_at ASC
@@ -267,17 +512,61 @@ func (r *Repository) GetPendingOutboxEvents(ctx context.Context, limit int) ([]r
 	}
 	defer rows.Close()
 
+	return scanOutboxEvents(rows)
+}
+
+// GetSentOutboxEvents возвращает страницу событий со статусом sent после курсора, старые первыми
+// Пустой EventID курсора включает события, отправленные ровно в after.SentAt (event_id не пустой)
+func (r *Repository) GetSentOutboxEvents(ctx context.Context, after repository.SentOutboxCursor, limit int) ([]repository.OutboxEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT event_id, event_type, occurred_at, aggregate_id, payload, topic, status, attempts, last_error, created_at, sent_at, headers
+		 FROM order_outbox_events
+		 WHERE status = 'sent' AND (sent_at, event_id) > ($1, $2)
+		 ORDER BY sent_at ASC, event_id ASC
+		 LIMIT $3`,
+		after.SentAt, after.EventID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxEvents(rows)
+}
+
+// GetOutboxBacklog считает pending события старше staleBefore и failed события
+func (r *Repository) GetOutboxBacklog(ctx context.Context, staleBefore time.Time) (repository.OutboxBacklog, error) {
+	var backlog repository.OutboxBacklog
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FILTER (WHERE status = 'pending' AND created_at < $1),
+		        COUNT(*) FILTER (WHERE status = 'failed')
+		 FROM order_outbox_events
+		 WHERE status IN ('pending', 'failed')`,
+		staleBefore).Scan(&backlog.StalePending, &backlog.Failed)
+	if err != nil {
+		return repository.OutboxBacklog{}, err
+	}
+	return backlog, nil
+}
+
+// scanOutboxEvents читает строки order_outbox_events (колонки в порядке SELECT из GetPendingOutboxEvents)
+func scanOutboxEvents(rows pgx.Rows) ([]repository.OutboxEvent, error) {
 	events := make([]repository.OutboxEvent, 0)
 	for rows.Next() {
 		var event repository.OutboxEvent
 		var sentAt *time.Time
+		var headers []byte
 		err := rows.Scan(
 			&event.EventID, &event.EventType, &event.OccurredAt, &event.AggregateID,
 			&event.Payload, &event.Topic, &event.Status, &event.Attempts,
-			&event.LastError, &event.CreatedAt, &sentAt)
+			&event.LastError, &event.CreatedAt, &sentAt, &headers)
 		if err != nil {
 			return nil, err
 		}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &event.Headers); err != nil {
+				return nil, err
+			}
+		}
 		if sentAt != nil {
 			event.SentAt = *sentAt
 		}
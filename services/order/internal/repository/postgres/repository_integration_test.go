@@ -6,8 +6,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib" //для goose миграций
 
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 )
 
@@ -88,8 +91,10 @@ func TestRepository_Integration(t *testing.T) {
 			UserID: "user-1",
 			Status: "paid",
 			Items: []repository.OrderItem{
-				{ProductID: "product-1", Quantity: 2},
+				{ProductID: "product-1", Quantity: 2, UnitPrice: 10000, Currency: "USD"},
 			},
+			TotalAmount: 20000,
+			Currency:    "USD",
 		}
 
 		// Сохраняем заказ
@@ -97,23 +102,302 @@ func TestRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 
 		// Получаем заказ по ID
-		got, err := repo.GetByID(ctx, "order-1")
+		got, err := repo.GetByID(ctx, "order-1", repository.GetOptions{})
 		require.NoError(t, err)
 
 		// Проверяем основные поля
 		require.Equal(t, order.ID, got.ID)
 		require.Equal(t, order.UserID, got.UserID)
 		require.Equal(t, order.Status, got.Status)
+		require.Equal(t, order.TotalAmount, got.TotalAmount)
+		require.Equal(t, order.Currency, got.Currency)
 
 		// Проверяем items
 		require.Len(t, got.Items, 1)
 		require.Equal(t, order.Items[0].ProductID, got.Items[0].ProductID)
 		require.Equal(t, order.Items[0].Quantity, got.Items[0].Quantity)
+		require.Equal(t, order.Items[0].UnitPrice, got.Items[0].UnitPrice)
+		require.Equal(t, order.Items[0].Currency, got.Items[0].Currency)
+	})
+
+	t.Run("Save rejects illegal status transitions", func(t *testing.T) {
+		order := repository.Order{ID: "order-transition", UserID: "user-1", Status: "assembled", TotalAmount: 100, Currency: "USD"}
+
+		// Новый заказ сохраняется только переходом из new: сразу assembled нельзя
+		var transitionErr *domain.TransitionError
+		require.ErrorAs(t, repo.Save(ctx, order), &transitionErr)
+		require.Equal(t, "new", transitionErr.From)
+
+		order.Status = "paid"
+		require.NoError(t, repo.Save(ctx, order))
+		// Повторное сохранение с тем же статусом - не переход
+		require.NoError(t, repo.Save(ctx, order))
+
+		order.Status = "payment_declined"
+		require.ErrorIs(t, repo.Save(ctx, order), domain.ErrInvalidTransition)
+		require.ErrorIs(t, repo.SaveWithOutbox(ctx, order, "evt-transition", "order.payment.declined", time.Now(), []byte(`{}`), "order.payment.declined"),
+			domain.ErrInvalidTransition)
+
+		got, err := repo.GetByID(ctx, "order-transition", repository.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "paid", got.Status)
 	})
 
 	t.Run("GetByID_NotFound", func(t *testing.T) {
-		_, err := repo.GetByID(ctx, "missing")
+		_, err := repo.GetByID(ctx, "missing", repository.GetOptions{})
 		require.Error(t, err)
 		require.True(t, errors.Is(err, repository.ErrNotFound), "Expected ErrNotFound, got: %v", err)
 	})
+
+	t.Run("HandleAssemblyCompletedTx updates item statuses", func(t *testing.T) {
+		order := repository.Order{
+			ID:     "order-partial",
+			UserID: "user-1",
+			Status: "paid",
+			Items: []repository.OrderItem{
+				{ProductID: "product-1", Quantity: 1},
+				{ProductID: "product-2", Quantity: 2},
+			},
+			TotalAmount: 30000,
+			Currency:    "RUB",
+		}
+		require.NoError(t, repo.Save(ctx, order))
+
+		got, err := repo.GetByID(ctx, "order-partial", repository.GetOptions{})
+		require.NoError(t, err)
+		for _, item := range got.Items {
+			require.Equal(t, repository.ItemStatusReserved, item.Status)
+		}
+
+		assembled := repository.OutboxEvent{
+			EventID:    "assembled-order-partial-evt-partial",
+			EventType:  "order.assembled",
+			OccurredAt: time.Now(),
+			Payload:    []byte(`{"order_id":"order-partial"}`),
+			Topic:      "order.assembled",
+		}
+		inserted, rowsAffected, err := repo.HandleAssemblyCompletedTx(ctx, "evt-partial", "order.assembly.completed", time.Now(), "order-partial",
+			[]repository.ItemStatusChange{
+				{ProductID: "product-1", Status: repository.ItemStatusAssembled},
+				{ProductID: "product-2", Status: repository.ItemStatusCancelled},
+			}, assembled)
+		require.NoError(t, err)
+		require.True(t, inserted)
+		require.Equal(t, int64(1), rowsAffected)
+
+		// order.assembled записан в outbox в той же транзакции
+		var topic, aggregateID string
+		err = pool.QueryRow(ctx,
+			`SELECT topic, aggregate_id FROM order_outbox_events WHERE event_id = $1 AND status = 'pending'`,
+			assembled.EventID).Scan(&topic, &aggregateID)
+		require.NoError(t, err)
+		require.Equal(t, "order.assembled", topic)
+		require.Equal(t, "order-partial", aggregateID)
+
+		// Повтор события сборки (duplicate) не порождает второй order.assembled
+		assembled.EventID = "assembled-order-partial-evt-partial-2"
+		inserted, _, err = repo.HandleAssemblyCompletedTx(ctx, "evt-partial", "order.assembly.completed", time.Now(), "order-partial", nil, assembled)
+		require.NoError(t, err)
+		require.False(t, inserted)
+		var count int
+		require.NoError(t, pool.QueryRow(ctx,
+			`SELECT count(*) FROM order_outbox_events WHERE aggregate_id = 'order-partial' AND event_type = 'order.assembled'`).Scan(&count))
+		require.Equal(t, 1, count)
+
+		got, err = repo.GetByID(ctx, "order-partial", repository.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "assembled", got.Status)
+		require.Len(t, got.Items, 2)
+		require.Equal(t, repository.ItemStatusAssembled, got.Items[0].Status)
+		require.Equal(t, repository.ItemStatusCancelled, got.Items[1].Status)
+	})
+
+	t.Run("ArchiveCompletedBefore and List", func(t *testing.T) {
+		for _, o := range []repository.Order{
+			{ID: "order-archive-1", UserID: "user-archive", Status: "paid", TotalAmount: 100, Currency: "USD"},
+			{ID: "order-archive-1", UserID: "user-archive", Status: "assembled", TotalAmount: 100, Currency: "USD"},
+			{ID: "order-archive-2", UserID: "user-archive", Status: "paid", TotalAmount: 200, Currency: "USD"},
+			{ID: "order-archive-3", UserID: "user-archive", Status: "payment_declined", TotalAmount: 300, Currency: "USD"},
+		} {
+			require.NoError(t, repo.Save(ctx, o))
+		}
+
+		// Граница в будущем: подходят все заказы, но архивируются только завершённые (assembled, payment_declined)
+		archived, err := repo.ArchiveCompletedBefore(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), archived)
+
+		// Повторная архивация не трогает уже архивные заказы
+		archived, err = repo.ArchiveCompletedBefore(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(0), archived)
+
+		_, err = repo.GetByID(ctx, "order-archive-1", repository.GetOptions{})
+		require.True(t, errors.Is(err, repository.ErrNotFound), "Expected ErrNotFound, got: %v", err)
+
+		got, err := repo.GetByID(ctx, "order-archive-1", repository.GetOptions{IncludeArchived: true})
+		require.NoError(t, err)
+		require.NotZero(t, got.ArchivedAt)
+
+		orders, err := repo.List(ctx, repository.ListFilter{UserID: "user-archive", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		require.Equal(t, "order-archive-2", orders[0].ID)
+
+		_, err = repo.GetByID(ctx, "order-archive-3", repository.GetOptions{})
+		require.True(t, errors.Is(err, repository.ErrNotFound), "Expected ErrNotFound, got: %v", err)
+
+		orders, err = repo.List(ctx, repository.ListFilter{UserID: "user-archive", IncludeArchived: true, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, orders, 3)
+	})
+
+	t.Run("List by product", func(t *testing.T) {
+		for _, o := range []repository.Order{
+			{ID: "order-recall-1", UserID: "user-recall-1", Status: "paid", Items: []repository.OrderItem{
+				{ProductID: "product-recall", Quantity: 1}, {ProductID: "product-other", Quantity: 3},
+			}},
+			{ID: "order-recall-2", UserID: "user-recall-2", Status: "paid", Items: []repository.OrderItem{
+				{ProductID: "product-recall", Quantity: 2},
+			}},
+			{ID: "order-recall-3", UserID: "user-recall-1", Status: "paid", Items: []repository.OrderItem{
+				{ProductID: "product-other", Quantity: 1},
+			}},
+		} {
+			require.NoError(t, repo.Save(ctx, o))
+		}
+
+		// Товар среди всех пользователей; у заказа возвращаются все позиции, а не только совпавшая
+		orders, err := repo.List(ctx, repository.ListFilter{ProductID: "product-recall", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		ids := []string{orders[0].ID, orders[1].ID}
+		require.ElementsMatch(t, []string{"order-recall-1", "order-recall-2"}, ids)
+		for _, o := range orders {
+			if o.ID == "order-recall-1" {
+				require.Len(t, o.Items, 2)
+			}
+		}
+
+		// Товар у конкретного пользователя
+		orders, err = repo.List(ctx, repository.ListFilter{UserID: "user-recall-1", ProductID: "product-recall", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		require.Equal(t, "order-recall-1", orders[0].ID)
+	})
+
+	t.Run("Outbox sent events and backlog", func(t *testing.T) {
+		for _, id := range []string{"evt-sent", "evt-pending"} {
+			order := repository.Order{ID: "order-" + id, UserID: "user-outbox", Status: "paid"}
+			require.NoError(t, repo.SaveWithOutbox(ctx, order, id, "order.payment.completed", time.Now(), []byte(`{}`), "order.payment.completed"))
+		}
+		require.NoError(t, repo.MarkOutboxEventSent(ctx, "evt-sent"))
+
+		events, err := repo.GetSentOutboxEvents(ctx, repository.SentOutboxCursor{SentAt: time.Now().Add(-time.Hour)}, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "evt-sent", events[0].EventID)
+		require.NotZero(t, events[0].SentAt)
+
+		// Следующая страница после последнего события пуста
+		events, err = repo.GetSentOutboxEvents(ctx, repository.SentOutboxCursor{SentAt: events[0].SentAt, EventID: events[0].EventID}, 10)
+		require.NoError(t, err)
+		require.Empty(t, events)
+
+		// Граница в будущем: pending событие считается зависшим
+		backlog, err := repo.GetOutboxBacklog(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, repository.OutboxBacklog{StalePending: 1}, backlog)
+
+		backlog, err = repo.GetOutboxBacklog(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, repository.OutboxBacklog{}, backlog)
+	})
+
+	t.Run("Concurrent webhook registrations do not exceed limit", func(t *testing.T) {
+		const limit, attempts = 3, 10
+		var wg sync.WaitGroup
+		errs := make(chan error, attempts)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- repo.CreateWebhook(ctx, repository.Webhook{
+					ID:        fmt.Sprintf("wh-limit-%d", i),
+					UserID:    "user-webhook-limit",
+					URL:       fmt.Sprintf("https://example.com/hook/%d", i),
+					CreatedAt: time.Now(),
+				}, limit)
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		created := 0
+		for err := range errs {
+			if err == nil {
+				created++
+				continue
+			}
+			require.ErrorIs(t, err, repository.ErrWebhookLimitReached)
+		}
+		require.Equal(t, limit, created)
+
+		webhooks, err := repo.ListWebhooks(ctx, "user-webhook-limit")
+		require.NoError(t, err)
+		require.Len(t, webhooks, limit)
+	})
+
+	t.Run("Webhook deliveries are enqueued with status change", func(t *testing.T) {
+		webhook := repository.Webhook{ID: "wh-1", UserID: "user-webhook", URL: "https://example.com/hook", Secret: "s3cret", CreatedAt: time.Now()}
+		require.NoError(t, repo.CreateWebhook(ctx, webhook, 2))
+		require.ErrorIs(t, repo.CreateWebhook(ctx, repository.Webhook{ID: "wh-2", UserID: "user-webhook", URL: webhook.URL, CreatedAt: time.Now()}, 2),
+			repository.ErrWebhookExists)
+		// Лимит 1 уже исчерпан: второй URL не сохраняется
+		require.ErrorIs(t, repo.CreateWebhook(ctx, repository.Webhook{ID: "wh-3", UserID: "user-webhook", URL: "https://example.org/hook", CreatedAt: time.Now()}, 1),
+			repository.ErrWebhookLimitReached)
+
+		order := repository.Order{ID: "order-webhook", UserID: "user-webhook", Status: "paid"}
+		require.NoError(t, repo.SaveWithOutbox(ctx, order, "evt-webhook", "order.payment.completed", time.Now(), []byte(`{}`), "order.payment.completed"))
+
+		deliveries, err := repo.GetDueWebhookDeliveries(ctx, time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, "wh-1", deliveries[0].WebhookID)
+		require.Equal(t, "order-webhook", deliveries[0].OrderID)
+		require.Equal(t, "paid", deliveries[0].OrderStatus)
+		require.Equal(t, "s3cret", deliveries[0].Secret)
+
+		// Неудачная попытка откладывает доставку до nextAttemptAt
+		require.NoError(t, repo.MarkWebhookAttemptFailed(ctx, "evt-webhook", "wh-1", "unexpected status 500", time.Now().Add(time.Hour), false))
+		deliveries, err = repo.GetDueWebhookDeliveries(ctx, time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		require.NoError(t, repo.DeleteWebhook(ctx, "user-webhook", "wh-1"))
+		require.ErrorIs(t, repo.DeleteWebhook(ctx, "user-webhook", "wh-1"), repository.ErrWebhookNotFound)
+	})
+
+	t.Run("GetUserOrderStats groups by window, status and currency", func(t *testing.T) {
+		now := time.Now()
+		orders := []repository.Order{
+			{ID: "order-stats-1", UserID: "user-stats", Status: "paid", TotalAmount: 1000, Currency: "RUB", CreatedAt: now.Unix()},
+			{ID: "order-stats-2", UserID: "user-stats", Status: "paid", TotalAmount: 500, Currency: "RUB", CreatedAt: now.Unix()},
+			{ID: "order-stats-3", UserID: "user-stats", Status: "paid", TotalAmount: 700, Currency: "USD", CreatedAt: now.Unix()},
+			{ID: "order-stats-4", UserID: "user-stats", Status: "cancelled", TotalAmount: 300, Currency: "RUB", CreatedAt: now.Add(-48 * time.Hour).Unix()},
+		}
+		for _, order := range orders {
+			require.NoError(t, repo.Save(ctx, order))
+		}
+
+		rows, err := repo.GetUserOrderStats(ctx, "user-stats", []time.Time{now.Add(-time.Hour), {}})
+		require.NoError(t, err)
+		require.Equal(t, []repository.OrderStatsRow{
+			{Window: 0, Status: "paid", Currency: "RUB", Count: 2, TotalAmount: 1500},
+			{Window: 0, Status: "paid", Currency: "USD", Count: 1, TotalAmount: 700},
+			{Window: 1, Status: "cancelled", Currency: "RUB", Count: 1, TotalAmount: 300},
+			{Window: 1, Status: "paid", Currency: "RUB", Count: 2, TotalAmount: 1500},
+			{Window: 1, Status: "paid", Currency: "USD", Count: 1, TotalAmount: 700},
+		}, rows)
+	})
 }
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// GetUserOrderStats считает заказы пользователя по окнам одним запросом: окна разворачиваются через unnest
+// и соединяются с заказами пользователя; покрывающий индекс idx_orders_user_id_created_at_stats
+// позволяет обойтись index-only scan без чтения строк заказов
+func (r *Repository) GetUserOrderStats(ctx context.Context, userID string, since []time.Time) ([]repository.OrderStatsRow, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT w.idx - 1, o.status, o.currency, COUNT(*), COALESCE(SUM(o.total_amount), 0)
+		 FROM unnest($2::timestamptz[]) WITH ORDINALITY AS w(since, idx)
+		 JOIN orders o ON o.user_id = $1 AND o.created_at >= w.since
+		 GROUP BY w.idx, o.status, o.currency
+		 ORDER BY w.idx, o.status, o.currency`,
+		userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []repository.OrderStatsRow
+	for rows.Next() {
+		var row repository.OrderStatsRow
+		if err := rows.Scan(&row.Window, &row.Status, &row.Currency, &row.Count, &row.TotalAmount); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// ChangeStatusTx меняет статус заказа по запросу оператора вместе с журналом, outbox и webhook'ами в одной транзакции
+// UPDATE с условием на прежний статус: параллельная смена статуса (сборка, другой оператор) даёт ErrStatusConflict
+func (r *Repository) ChangeStatusTx(ctx context.Context, audit repository.StatusAudit, event repository.OutboxEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET status = $3 
+		 WHERE id = $1 AND status = $2 AND archived_at IS NULL`,
+		audit.OrderID, audit.FromStatus, audit.ToStatus)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// Отличаем отсутствующий (или архивный) заказ от сменившегося статуса
+		var archived bool
+		err = tx.QueryRow(ctx, `SELECT archived_at IS NOT NULL FROM orders WHERE id = $1`, audit.OrderID).Scan(&archived)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && archived) {
+			return repository.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return repository.ErrStatusConflict
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_status_audit (order_id, from_status, to_status, forced, operator, reason, event_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		audit.OrderID, audit.FromStatus, audit.ToStatus, audit.Forced, audit.Operator, audit.Reason, audit.EventID)
+	if err != nil {
+		return err
+	}
+
+	if err = insertOutboxEventTx(ctx, tx, event.EventID, event.EventType, event.OccurredAt, audit.OrderID, event.Payload, event.Topic); err != nil {
+		return err
+	}
+	if err = enqueueWebhookDeliveriesTx(ctx, tx, event.EventID, event.EventType, event.OccurredAt, audit.OrderID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// CreateWebhook сохраняет webhook пользователя, если у него меньше limit webhook'ов
+// Транзакционный advisory lock по пользователю упорядочивает параллельные регистрации: без него две транзакции
+// увидели бы одно и то же количество и обе вставили бы строку сверх лимита
+func (r *Repository) CreateWebhook(ctx context.Context, webhook repository.Webhook, limit int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('order_webhooks:' || $1))`, webhook.UserID); err != nil {
+		return err
+	}
+
+	var count int
+	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM order_webhooks WHERE user_id = $1`, webhook.UserID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= limit {
+		return repository.ErrWebhookLimitReached
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_webhooks (id, user_id, url, secret, created_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, webhook.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation (user_id, url)
+			return repository.ErrWebhookExists
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListWebhooks возвращает webhook'и пользователя, старые первыми
+func (r *Repository) ListWebhooks(ctx context.Context, userID string) ([]repository.Webhook, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, url, secret, created_at
+		 FROM order_webhooks
+		 WHERE user_id = $1
+		 ORDER BY created_at ASC, id ASC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]repository.Webhook, 0)
+	for rows.Next() {
+		var webhook repository.Webhook
+		if err := rows.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook удаляет webhook пользователя; недоставленные callback'и удаляются каскадно
+func (r *Repository) DeleteWebhook(ctx context.Context, userID, id string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM order_webhooks WHERE id = $1 AND user_id = $2`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// GetDueWebhookDeliveries возвращает pending доставки с наступившим временем попытки, старые первыми
+func (r *Repository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]repository.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d.event_id, d.event_type, d.webhook_id, w.url, w.secret, d.order_id, d.user_id, d.order_status, d.occurred_at, d.attempts
+		 FROM order_webhook_deliveries d
+		 JOIN order_webhooks w ON w.id = d.webhook_id
+		 WHERE d.status = 'pending' AND d.next_attempt_at <= $1
+		 ORDER BY d.next_attempt_at ASC
+		 LIMIT $2`,
+		now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]repository.WebhookDelivery, 0)
+	for rows.Next() {
+		var d repository.WebhookDelivery
+		err := rows.Scan(&d.EventID, &d.EventType, &d.WebhookID, &d.URL, &d.Secret,
+			&d.OrderID, &d.UserID, &d.OrderStatus, &d.OccurredAt, &d.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// MarkWebhookDelivered отмечает доставку как успешную
+func (r *Repository) MarkWebhookDelivered(ctx context.Context, eventID, webhookID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE order_webhook_deliveries
+		 SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = NOW()
+		 WHERE event_id = $1 AND webhook_id = $2`,
+		eventID, webhookID)
+	return err
+}
+
+// MarkWebhookAttemptFailed сохраняет неудачную попытку: следующая в nextAttemptAt или статус failed, если final
+func (r *Repository) MarkWebhookAttemptFailed(ctx context.Context, eventID, webhookID, errMsg string, nextAttemptAt time.Time, final bool) error {
+	status := repository.WebhookDeliveryPending
+	if final {
+		status = repository.WebhookDeliveryFailed
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE order_webhook_deliveries
+		 SET status = $3, attempts = attempts + 1, last_error = $4, next_attempt_at = $5
+		 WHERE event_id = $1 AND webhook_id = $2`,
+		eventID, webhookID, status, errMsg, nextAttemptAt)
+	return err
+}
+
+// enqueueWebhookDeliveriesTx ставит в очередь callback'и о текущем статусе заказа на все webhook'и его владельца
+// Вызывается в транзакции смены статуса, поэтому callback не теряется и не появляется без смены статуса
+func enqueueWebhookDeliveriesTx(ctx context.Context, tx pgx.Tx, eventID, eventType string, occurredAt time.Time, orderID string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO order_webhook_deliveries (event_id, webhook_id, event_type, order_id, user_id, order_status, occurred_at)
+		 SELECT $1, w.id, $2, o.id, o.user_id, o.status, $3
+		 FROM orders o
+		 JOIN order_webhooks w ON w.user_id = o.user_id
+		 WHERE o.id = $4
+		 ON CONFLICT (event_id, webhook_id) DO NOTHING`,
+		eventID, eventType, occurredAt, orderID)
+	return err
+}
@@ -9,20 +9,56 @@ import (
 // Order представляет доменную модель заказа
 // Это бизнес-сущность, не привязанная к HTTP или БД
 type Order struct {
-	ID        string
-	UserID    string
-	Status    string
-	Items     []OrderItem
-	CreatedAt int64 // Unix timestamp для простоты
+	ID          string
+	UserID      string
+	Status      string
+	Items       []OrderItem
+	TotalAmount int64  // сумма заказа в минимальных единицах валюты (копейки, центы)
+	Currency    string // код валюты ISO 4217 (RUB, USD, ...)
+	CreatedAt   int64  // Unix timestamp для простоты
+	ArchivedAt  int64  // Unix timestamp архивации, 0 - заказ не архивирован
+	ItemsError  string // ошибка чтения позиций при GetOptions.TolerateItemErrors; Items тогда пустой
+}
+
+// GetOptions задаёт параметры получения заказа по ID
+type GetOptions struct {
+	IncludeArchived bool // возвращать ли архивный заказ (по умолчанию нет)
+	// TolerateItemErrors - мягкий режим для страниц поддержки: если позиции не читаются (например, повреждена
+	// строка order_items), заказ возвращается без позиций с ошибкой в ItemsError. По умолчанию (строгий режим,
+	// для бизнес-логики) ошибка чтения позиций - ошибка всего запроса
+	TolerateItemErrors bool
 }
 
+// ListFilter задаёт параметры выборки заказов
+type ListFilter struct {
+	UserID          string // заказы какого пользователя возвращать (пусто - всех пользователей)
+	ProductID       string // только заказы, содержащие этот товар (пусто - любые)
+	IncludeArchived bool   // возвращать ли архивные заказы (по умолчанию нет)
+	Limit           int    // максимальное количество заказов
+}
+
+// Статусы позиций заказа: каждая позиция проходит сборку/отгрузку независимо (частичное выполнение заказа)
+const (
+	ItemStatusReserved  = "reserved"  // товар зарезервирован в Inventory, ждёт сборки
+	ItemStatusAssembled = "assembled" // позиция собрана
+	ItemStatusShipped   = "shipped"   // позиция отгружена
+	ItemStatusCancelled = "cancelled" // позиция отменена (например, не нашлась при сборке)
+)
+
 // OrderItem представляет товар в заказе
 type OrderItem struct {
 	ProductID string
 	Quantity  int32
+	Status    string // статус позиции (ItemStatus*); пустой при сохранении - ItemStatusReserved
+	UnitPrice int64  // цена единицы на момент покупки в минимальных единицах валюты; не меняется при изменении цен каталога
+	Currency  string // валюта UnitPrice (совпадает с валютой заказа)
 }
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=OrderRepository --dir=. --output=./mocks --outpkg=mocks
+// ItemStatusChange - изменение статуса одной позиции заказа (из события сборки)
+type ItemStatusChange struct {
+	ProductID string
+	Status    string
+}
 
 // OrderRepository определяет интерфейс для работы с хранилищем заказов
 // Service слой зависит от этого интерфейса, а не от конкретной реализации
@@ -31,17 +67,30 @@ type OrderRepository interface {
 	Save(ctx context.Context, order Order) error
 
 	// GetByID получает заказ по ID
-	// Возвращает ErrNotFound, если заказ не найден
-	GetByID(ctx context.Context, id string) (Order, error)
+	// Возвращает ErrNotFound, если заказ не найден или архивирован (при opts.IncludeArchived=false)
+	GetByID(ctx context.Context, id string, opts GetOptions) (Order, error)
+
+	// List возвращает заказы пользователя, новые первыми
+	// Архивные заказы возвращаются только при filter.IncludeArchived
+	List(ctx context.Context, filter ListFilter) ([]Order, error)
+
+	// ArchiveCompletedBefore архивирует (soft delete) завершённые заказы (assembled, payment_declined - все конечные
+	// статусы domain.TerminalStatuses), созданные раньше before
+	// Уже архивные заказы не трогает. Возвращает количество заархивированных заказов
+	ArchiveCompletedBefore(ctx context.Context, before time.Time) (int64, error)
 
 	// HandleAssemblyCompletedTx обрабатывает событие завершения сборки заказа в транзакции
+	// Вместе со статусом заказа обновляет статусы позиций: items из события, а если их нет - все reserved позиции становятся assembled
 	// Возвращает (inserted, rowsAffected, error):
 	//   - inserted=true если событие впервые обработано
 	//   - inserted=false если событие уже было обработано (duplicate)
 	//   - rowsAffected - количество обновлённых строк (0 или 1)
-	HandleAssemblyCompletedTx(ctx context.Context, eventID, eventType string, occurredAt time.Time, orderID string) (inserted bool, rowsAffected int64, err error)
+	// При rowsAffected=1 в той же транзакции добавляет assembled в outbox (событие order.assembled)
+	// и ставит в очередь callback'и на webhook'и пользователя
+	HandleAssemblyCompletedTx(ctx context.Context, eventID, eventType string, occurredAt time.Time, orderID string, items []ItemStatusChange, assembled OutboxEvent) (inserted bool, rowsAffected int64, err error)
 
 	// SaveWithOutbox сохраняет заказ и добавляет событие в outbox в одной транзакции
+	// В той же транзакции ставит в очередь callback'и на webhook'и пользователя (см. WebhookRepository)
 	SaveWithOutbox(ctx context.Context, order Order, eventID, eventType string, occurredAt time.Time, payload []byte, topic string) error
 
 	// GetPendingOutboxEvents получает pending события из outbox для отправки
@@ -55,6 +104,43 @@ type OrderRepository interface {
 
 	// ResetOutboxEventPending сбрасывает статус события на pending для retry
 	ResetOutboxEventPending(ctx context.Context, eventID string) error
+
+	// GetSentOutboxEvents возвращает до limit событий со статусом sent после курсора after в порядке (sent_at, event_id)
+	// для сверки с Kafka. Курсор {SentAt: since} - первая страница событий, отправленных начиная с since
+	GetSentOutboxEvents(ctx context.Context, after SentOutboxCursor, limit int) ([]OutboxEvent, error)
+
+	// GetOutboxBacklog считает зависшие события: pending, созданные раньше staleBefore, и failed
+	GetOutboxBacklog(ctx context.Context, staleBefore time.Time) (OutboxBacklog, error)
+
+	// ChangeStatusTx меняет статус заказа audit.FromStatus -> audit.ToStatus по запросу оператора
+	// В той же транзакции пишет запись в журнал order_status_audit, событие в outbox и callback'и на webhook'и.
+	// Допустимость перехода (и force) проверяет service. Статусы позиций не меняются.
+	// ErrNotFound - заказа нет или он архивирован; ErrStatusConflict - статус уже не audit.FromStatus
+	ChangeStatusTx(ctx context.Context, audit StatusAudit, event OutboxEvent) error
+}
+
+// StatusAudit - запись журнала административной смены статуса заказа
+type StatusAudit struct {
+	OrderID    string
+	FromStatus string
+	ToStatus   string
+	Forced     bool   // переход недопустим по domain.Transition и выполнен с force
+	Operator   string // кто сменил статус
+	Reason     string // инцидент, тикет
+	EventID    string // событие, которое ушло в outbox вместе со сменой статуса
+}
+
+// SentOutboxCursor - позиция в выборке sent событий outbox (keyset по sent_at, event_id):
+// следующая страница начинается с события после последнего прочитанного
+type SentOutboxCursor struct {
+	SentAt  time.Time
+	EventID string
+}
+
+// OutboxBacklog - количество событий outbox, которые не уходят в Kafka
+type OutboxBacklog struct {
+	StalePending int64 // pending дольше порога
+	Failed       int64 // failed (сброс на pending не удался)
 }
 
 // OutboxEvent представляет событие в outbox таблице
@@ -70,7 +156,11 @@ type OutboxEvent struct {
 	LastError   *string
 	CreatedAt   time.Time
 	SentAt      time.Time
+	Headers     map[string]string // trace context и request_id на момент сохранения события
 }
 
 // ErrNotFound возвращается, когда заказ не найден в хранилище
 var ErrNotFound = errors.New("order not found")
+
+// ErrStatusConflict возвращается ChangeStatusTx, если статус заказа сменился между чтением и записью
+var ErrStatusConflict = errors.New("order status changed concurrently")
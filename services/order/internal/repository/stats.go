@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// OrderStatsRow - количество и сумма заказов пользователя одного статуса и валюты в одном окне
+type OrderStatsRow struct {
+	Window      int // индекс окна в since
+	Status      string
+	Currency    string
+	Count       int64
+	TotalAmount int64 // в минимальных единицах Currency
+}
+
+// OrderStatsRepository определяет интерфейс агрегатов по заказам пользователя
+type OrderStatsRepository interface {
+	// GetUserOrderStats считает заказы пользователя по статусу и валюте для каждого окна: заказы, созданные
+	// начиная с since[i]; нулевое время - за всё время. Архивные заказы учитываются
+	// Строки отсортированы по окну, статусу и валюте; статусов без заказов в ответе нет
+	GetUserOrderStats(ctx context.Context, userID string, since []time.Time) ([]OrderStatsRow, error)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Webhook - URL пользователя для callback'ов об изменении статуса заказа
+type Webhook struct {
+	ID        string
+	UserID    string
+	URL       string
+	Secret    string // ключ HMAC-SHA256 подписи callback'ов
+	CreatedAt time.Time
+}
+
+// Статусы доставки callback'а
+const (
+	WebhookDeliveryPending   = "pending"   // ждёт отправки (в том числе повторной)
+	WebhookDeliveryDelivered = "delivered" // получатель ответил 2xx
+	WebhookDeliveryFailed    = "failed"    // попытки исчерпаны
+)
+
+// WebhookDelivery - callback об изменении статуса заказа, который нужно доставить на URL webhook'а
+// Создаётся в одной транзакции со сменой статуса заказа (и событием outbox), по одному на каждый webhook пользователя
+type WebhookDelivery struct {
+	EventID     string // ID события, вызвавшего смену статуса (как в outbox/inbox)
+	EventType   string
+	WebhookID   string
+	URL         string
+	Secret      string
+	OrderID     string
+	UserID      string
+	OrderStatus string // статус заказа после события
+	OccurredAt  time.Time
+	Attempts    int
+}
+
+// WebhookRepository определяет интерфейс для хранения webhook'ов и очереди их доставки
+type WebhookRepository interface {
+	// CreateWebhook сохраняет webhook, если у пользователя меньше limit webhook'ов
+	// ErrWebhookExists, если URL уже зарегистрирован у пользователя; ErrWebhookLimitReached, если webhook'ов уже limit.
+	// Проверка лимита и вставка атомарны: параллельные регистрации одного пользователя не превышают limit
+	CreateWebhook(ctx context.Context, webhook Webhook, limit int) error
+
+	// ListWebhooks возвращает webhook'и пользователя, старые первыми
+	ListWebhooks(ctx context.Context, userID string) ([]Webhook, error)
+
+	// DeleteWebhook удаляет webhook пользователя вместе с недоставленными callback'ами
+	// Возвращает ErrWebhookNotFound, если webhook'а нет или он принадлежит другому пользователю
+	DeleteWebhook(ctx context.Context, userID, id string) error
+
+	// GetDueWebhookDeliveries возвращает pending доставки, время попытки которых наступило к now
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+
+	// MarkWebhookDelivered отмечает доставку как успешную
+	MarkWebhookDelivered(ctx context.Context, eventID, webhookID string) error
+
+	// MarkWebhookAttemptFailed увеличивает attempts и сохраняет ошибку
+	// Доставка остаётся pending до nextAttemptAt или, если final, переходит в failed
+	MarkWebhookAttemptFailed(ctx context.Context, eventID, webhookID, errMsg string, nextAttemptAt time.Time, final bool) error
+}
+
+// ErrWebhookExists возвращается, когда URL уже зарегистрирован у пользователя
+var ErrWebhookExists = errors.New("webhook already registered")
+
+// ErrWebhookLimitReached возвращается, когда у пользователя уже максимальное количество webhook'ов
+var ErrWebhookLimitReached = errors.New("webhook limit reached")
+
+// ErrWebhookNotFound возвращается, когда webhook не найден у пользователя
+var ErrWebhookNotFound = errors.New("webhook not found")
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+var (
+	// ErrUnknownStatus возвращается, если целевой статус не из тех, в которых хранится заказ
+	ErrUnknownStatus = errors.New("unknown order status")
+	// ErrStatusChangeAuditRequired возвращается без reason или operator: смена статуса без них не попадёт в журнал
+	ErrStatusChangeAuditRequired = errors.New("reason and operator are required")
+	// ErrStatusUnchanged возвращается, если заказ уже в целевом статусе
+	ErrStatusUnchanged = errors.New("order already has this status")
+)
+
+// ChangeStatusInput содержит входные данные для смены статуса заказа оператором
+type ChangeStatusInput struct {
+	OrderID  string
+	Status   domain.Status
+	Force    bool // разрешить переход, недопустимый по domain.Transition
+	Reason   string
+	Operator string
+}
+
+// ChangeStatusOutput содержит результат смены статуса заказа оператором
+type ChangeStatusOutput struct {
+	OrderID string
+	From    domain.Status
+	To      domain.Status
+	Forced  bool   // переход недопустим по domain.Transition и выполнен с Force
+	EventID string // событие, которое ушло в outbox вместе со сменой статуса
+}
+
+// ChangeStatus меняет статус заказа по запросу оператора вместо ручного UPDATE в БД
+// Переход проверяется по domain.Transition; недопустимый переход выполняется только с Force.
+// Вместе со статусом пишется запись журнала и событие, соответствующее новому статусу
+// (order.payment.completed, order.payment.declined или order.assembled), поэтому downstream сервисы
+// и webhook'и видят смену статуса так же, как при обычной обработке заказа
+func (s *OrderService) ChangeStatus(ctx context.Context, input ChangeStatusInput) (*ChangeStatusOutput, error) {
+	if !input.Status.IsStored() {
+		return nil, ErrUnknownStatus
+	}
+	if input.Reason == "" || input.Operator == "" {
+		return nil, ErrStatusChangeAuditRequired
+	}
+
+	logger := platformobservability.L(ctx, s.logger).With(
+		zap.String("order_id", input.OrderID),
+		zap.String("operator", input.Operator),
+	)
+
+	record, err := s.orderRepo.GetByID(ctx, input.OrderID, repository.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	order := orderFromRecord(record)
+	from := order.Status
+
+	if from == input.Status {
+		return nil, ErrStatusUnchanged
+	}
+	forced := false
+	if err := domain.Transition(from, input.Status); err != nil {
+		if !input.Force {
+			return nil, err
+		}
+		forced = true
+	}
+
+	audit := repository.StatusAudit{
+		OrderID:    order.ID,
+		FromStatus: string(from),
+		ToStatus:   string(input.Status),
+		Forced:     forced,
+		Operator:   input.Operator,
+		Reason:     input.Reason,
+	}
+	event, err := s.newStatusChangedEvent(order, audit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status changed event: %w", err)
+	}
+	audit.EventID = event.EventID
+
+	if err := s.orderRepo.ChangeStatusTx(ctx, audit, event); err != nil {
+		logger.Error("failed to change order status", zap.Error(err))
+		return nil, fmt.Errorf("failed to change order status: %w", err)
+	}
+
+	logger.Warn("order status changed by operator",
+		zap.String("from", string(from)),
+		zap.String("to", string(input.Status)),
+		zap.Bool("forced", forced),
+		zap.String("reason", input.Reason),
+		zap.String("event_id", event.EventID),
+	)
+
+	return &ChangeStatusOutput{
+		OrderID: order.ID,
+		From:    from,
+		To:      input.Status,
+		Forced:  forced,
+		EventID: event.EventID,
+	}, nil
+}
+
+// newStatusChangedEvent формирует событие нового статуса заказа для outbox
+// Payload совпадает с событием обычной обработки заказа (состав, сумма), а в status_change
+// указано, кто и почему сменил статус: consumers могут отличить ручную смену от обычной
+func (s *OrderService) newStatusChangedEvent(order domain.Order, audit repository.StatusAudit) (repository.OutboxEvent, error) {
+	var eventType, topic string
+	switch domain.Status(audit.ToStatus) {
+	case domain.StatusPaid:
+		eventType, topic = "order.payment.completed", s.paymentCompletedTopic
+	case domain.StatusPaymentDeclined:
+		eventType, topic = "order.payment.declined", s.paymentDeclinedTopic
+	case domain.StatusAssembled:
+		eventType, topic = "order.assembled", s.assembledTopic
+	default:
+		return repository.OutboxEvent{}, ErrUnknownStatus
+	}
+
+	occurredAt := time.Now().UTC()
+	eventID := s.ids.NewID()
+
+	eventItems := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		eventItems = append(eventItems, map[string]interface{}{
+			"product_id": item.ProductID,
+			"quantity":   item.Quantity,
+			"status":     item.Status,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"event_id":      eventID,
+		"event_type":    eventType,
+		"event_version": 1,
+		"occurred_at":   occurredAt.Format(time.RFC3339),
+		"order_id":      order.ID,
+		"user_id":       order.UserID,
+		"status":        audit.ToStatus,
+		"amount":        order.Total.Amount,
+		"currency":      order.Total.Currency,
+		"items":         eventItems,
+		"status_change": map[string]interface{}{
+			"from_status": audit.FromStatus,
+			"forced":      audit.Forced,
+			"operator":    audit.Operator,
+			"reason":      audit.Reason,
+		},
+	}
+	if eventType == "order.payment.declined" {
+		// Причина отказа неизвестна: статус сменил оператор, а не Payment
+		payload["reason"] = "unknown"
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return repository.OutboxEvent{}, err
+	}
+
+	return repository.OutboxEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		OccurredAt:  occurredAt,
+		AggregateID: order.ID,
+		Payload:     payloadBytes,
+		Topic:       topic,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+func TestOrderService_ChangeStatus(t *testing.T) {
+	ctx := context.Background()
+	paidOrder := repository.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Status:      string(domain.StatusPaid),
+		Items:       []repository.OrderItem{{ProductID: "product-1", Quantity: 2, Status: repository.ItemStatusReserved}},
+		TotalAmount: 20000,
+		Currency:    "RUB",
+	}
+
+	t.Run("allowed transition writes audit and corresponding event", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{}).Return(paidOrder, nil).Once()
+
+		var audit repository.StatusAudit
+		var event repository.OutboxEvent
+		mockRepo.On("ChangeStatusTx", ctx, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				audit = args.Get(1).(repository.StatusAudit)
+				event = args.Get(2).(repository.OutboxEvent)
+			}).Return(nil).Once()
+
+		out, err := svc.ChangeStatus(ctx, ChangeStatusInput{OrderID: "order-1", Status: domain.StatusAssembled, Reason: "INC-42", Operator: "ops-1"})
+
+		require.NoError(t, err)
+		require.False(t, out.Forced)
+		require.Equal(t, domain.StatusPaid, out.From)
+		require.Equal(t, repository.StatusAudit{
+			OrderID: "order-1", FromStatus: "paid", ToStatus: "assembled", Operator: "ops-1", Reason: "INC-42", EventID: event.EventID,
+		}, audit)
+		require.Equal(t, "order.assembled", event.EventType)
+		require.Equal(t, "order.assembled", event.Topic)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, "assembled", payload["status"])
+		require.Equal(t, map[string]interface{}{"from_status": "paid", "forced": false, "operator": "ops-1", "reason": "INC-42"}, payload["status_change"])
+	})
+
+	t.Run("invalid transition requires force", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{}).Return(paidOrder, nil).Once()
+
+		_, err := svc.ChangeStatus(ctx, ChangeStatusInput{OrderID: "order-1", Status: domain.StatusPaymentDeclined, Reason: "INC-42", Operator: "ops-1"})
+
+		require.ErrorIs(t, err, domain.ErrInvalidTransition)
+		mockRepo.AssertNotCalled(t, "ChangeStatusTx", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("forced transition is marked in audit", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{}).Return(paidOrder, nil).Once()
+		mockRepo.On("ChangeStatusTx", ctx, mock.MatchedBy(func(a repository.StatusAudit) bool {
+			return a.Forced && a.ToStatus == "payment_declined"
+		}), mock.MatchedBy(func(e repository.OutboxEvent) bool {
+			return e.EventType == "order.payment.declined" && e.Topic == "order.payment.declined"
+		})).Return(nil).Once()
+
+		out, err := svc.ChangeStatus(ctx, ChangeStatusInput{OrderID: "order-1", Status: domain.StatusPaymentDeclined, Force: true, Reason: "INC-42", Operator: "ops-1"})
+
+		require.NoError(t, err)
+		require.True(t, out.Forced)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			input ChangeStatusInput
+			err   error
+		}{
+			{"unknown status", ChangeStatusInput{OrderID: "order-1", Status: "shipped", Reason: "r", Operator: "o"}, ErrUnknownStatus},
+			{"new is not a stored status", ChangeStatusInput{OrderID: "order-1", Status: domain.StatusNew, Reason: "r", Operator: "o"}, ErrUnknownStatus},
+			{"no operator", ChangeStatusInput{OrderID: "order-1", Status: domain.StatusAssembled, Reason: "r"}, ErrStatusChangeAuditRequired},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				svc, _ := newTestServiceWithRepo(t)
+
+				_, err := svc.ChangeStatus(ctx, tc.input)
+
+				require.ErrorIs(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("same status is rejected", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{}).Return(paidOrder, nil).Once()
+
+		_, err := svc.ChangeStatus(ctx, ChangeStatusInput{OrderID: "order-1", Status: domain.StatusPaid, Force: true, Reason: "r", Operator: "o"})
+
+		require.ErrorIs(t, err, ErrStatusUnchanged)
+	})
+
+	t.Run("concurrent status change is returned", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{}).Return(paidOrder, nil).Once()
+		mockRepo.On("ChangeStatusTx", ctx, mock.Anything, mock.Anything).Return(repository.ErrStatusConflict).Once()
+
+		_, err := svc.ChangeStatus(ctx, ChangeStatusInput{OrderID: "order-1", Status: domain.StatusAssembled, Reason: "r", Operator: "o"})
+
+		require.ErrorIs(t, err, repository.ErrStatusConflict)
+	})
+}
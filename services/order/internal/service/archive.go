@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+)
+
+// ErrInvalidArchiveAge возвращается, если возраст заказов для архивации не положительный
+var ErrInvalidArchiveAge = errors.New("older_than must be positive")
+
+// ArchiveOrdersInput содержит входные данные для архивации заказов
+type ArchiveOrdersInput struct {
+	OlderThan time.Duration // архивируются завершённые заказы, созданные раньше now - OlderThan
+}
+
+// ArchiveOrdersOutput содержит результат архивации заказов
+type ArchiveOrdersOutput struct {
+	Archived int64     // сколько заказов заархивировано
+	Before   time.Time // граница created_at, по которой отбирались заказы
+}
+
+// ArchiveOrders архивирует (soft delete) завершённые заказы старше input.OlderThan
+// Архивные заказы остаются в БД, но не возвращаются GetOrder/ListOrders без IncludeArchived
+func (s *OrderService) ArchiveOrders(ctx context.Context, input ArchiveOrdersInput) (*ArchiveOrdersOutput, error) {
+	if input.OlderThan <= 0 {
+		return nil, ErrInvalidArchiveAge
+	}
+
+	before := time.Now().UTC().Add(-input.OlderThan)
+
+	logger := platformobservability.L(ctx, s.logger)
+	archived, err := s.orderRepo.ArchiveCompletedBefore(ctx, before)
+	if err != nil {
+		logger.Error("failed to archive orders",
+			zap.Error(err),
+			zap.Time("before", before),
+		)
+		return nil, fmt.Errorf("failed to archive orders: %w", err)
+	}
+
+	logger.Info("orders archived",
+		zap.Int64("archived", archived),
+		zap.Time("before", before),
+	)
+
+	return &ArchiveOrdersOutput{
+		Archived: archived,
+		Before:   before,
+	}, nil
+}
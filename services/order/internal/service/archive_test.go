@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/order/internal/service/mocks"
+)
+
+func newTestServiceWithRepo(t *testing.T) (*OrderService, *repoMocks.OrderRepository) {
+	mockRepo := repoMocks.NewOrderRepository(t)
+	svc := NewOrderService(zap.NewNop(), mocks.NewInventoryClient(t), mocks.NewPaymentClient(t), mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+	return svc, mockRepo
+}
+
+func TestOrderService_ListOrders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default limit and archived excluded", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("List", ctx, repository.ListFilter{UserID: "user-1", Limit: DefaultListLimit}).
+			Return([]repository.Order{
+				{ID: "order-2", UserID: "user-1", Status: "paid"},
+				{ID: "order-1", UserID: "user-1", Status: "assembled"},
+			}, nil).Once()
+
+		result, err := svc.ListOrders(ctx, ListOrdersInput{UserID: "user-1"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "order-2", result[0].OrderID)
+	})
+
+	t.Run("limit is capped and include archived is passed through", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("List", ctx, repository.ListFilter{UserID: "user-1", IncludeArchived: true, Limit: MaxListLimit}).
+			Return([]repository.Order{{ID: "order-1", UserID: "user-1", ArchivedAt: 1700000000}}, nil).Once()
+
+		result, err := svc.ListOrders(ctx, ListOrdersInput{UserID: "user-1", IncludeArchived: true, Limit: 1000})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, time.Unix(1700000000, 0).UTC(), result[0].ArchivedAt)
+	})
+
+	t.Run("product_id without user_id searches all users", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("List", ctx, repository.ListFilter{ProductID: "product-1", Limit: DefaultListLimit}).
+			Return([]repository.Order{
+				{ID: "order-2", UserID: "user-2", Items: []repository.OrderItem{{ProductID: "product-1", Quantity: 1}}},
+				{ID: "order-1", UserID: "user-1", Items: []repository.OrderItem{{ProductID: "product-1", Quantity: 2}}},
+			}, nil).Once()
+
+		result, err := svc.ListOrders(ctx, ListOrdersInput{ProductID: "product-1"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "user-2", result[0].UserID)
+	})
+
+	t.Run("error: user_id or product_id is required", func(t *testing.T) {
+		svc, _ := newTestServiceWithRepo(t)
+
+		_, err := svc.ListOrders(ctx, ListOrdersInput{})
+		require.ErrorIs(t, err, ErrUserIDRequired)
+	})
+}
+
+func TestOrderService_ArchiveOrders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("archives orders older than cutoff", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		olderThan := 30 * 24 * time.Hour
+		expectedBefore := time.Now().UTC().Add(-olderThan)
+
+		mockRepo.On("ArchiveCompletedBefore", ctx, mock.MatchedBy(func(before time.Time) bool {
+			return before.Sub(expectedBefore).Abs() < time.Minute
+		})).Return(int64(3), nil).Once()
+
+		result, err := svc.ArchiveOrders(ctx, ArchiveOrdersInput{OlderThan: olderThan})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.Archived)
+	})
+
+	t.Run("error: older_than must be positive", func(t *testing.T) {
+		svc, _ := newTestServiceWithRepo(t)
+
+		_, err := svc.ArchiveOrders(ctx, ArchiveOrdersInput{})
+		require.ErrorIs(t, err, ErrInvalidArchiveAge)
+	})
+
+	t.Run("error: repository failure", func(t *testing.T) {
+		svc, mockRepo := newTestServiceWithRepo(t)
+		mockRepo.On("ArchiveCompletedBefore", ctx, mock.Anything).Return(int64(0), errors.New("db down")).Once()
+
+		_, err := svc.ArchiveOrders(ctx, ArchiveOrdersInput{OlderThan: time.Hour})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to archive orders")
+	})
+}
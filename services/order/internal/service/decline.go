@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+)
+
+// Причины отказа в оплате (приходят из Payment в деталях gRPC статуса)
+const (
+	DeclineReasonInsufficientFunds = "insufficient_funds"
+	DeclineReasonLimitExceeded     = "limit_exceeded"
+	DeclineReasonRiskDeclined      = "risk_declined"
+	DeclineReasonProviderError     = "provider_error"
+)
+
+// PaymentDeclinedError возвращается PaymentClient, если Payment отказал в оплате,
+// и CreateOrder - с заполненным OrderID сохранённого заказа со статусом domain.StatusPaymentDeclined
+type PaymentDeclinedError struct {
+	Reason  string // одна из DeclineReason*; пусто - причина неизвестна
+	Message string
+	OrderID string
+}
+
+func (e *PaymentDeclinedError) Error() string {
+	return fmt.Sprintf("payment declined (%s): %s", e.Reason, e.Message)
+}
+
+// Retryable сообщает, можно ли повторить оплату позже без действий пользователя
+func (e *PaymentDeclinedError) Retryable() bool {
+	return e.Reason == DeclineReasonProviderError
+}
+
+// saveDeclinedOrder сохраняет заказ со статусом payment_declined и событие order.payment.declined в outbox
+// Ошибка сохранения не скрывает отказ: она логируется, клиент всё равно получает причину отказа
+func (s *OrderService) saveDeclinedOrder(ctx context.Context, logger *zap.Logger, order domain.Order, paymentMethod string, declined *PaymentDeclinedError) {
+	if err := order.DeclinePayment(); err != nil {
+		logger.Error("failed to decline order", zap.String("order_id", order.ID), zap.Error(err))
+		return
+	}
+
+	eventID := s.ids.NewID()
+	eventType := "order.payment.declined"
+	occurredAt := time.Now().UTC()
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"event_id":       eventID,
+		"event_type":     eventType,
+		"event_version":  1,
+		"occurred_at":    occurredAt.Format(time.RFC3339),
+		"order_id":       order.ID,
+		"user_id":        order.UserID,
+		"amount":         order.Total.Amount,
+		"currency":       order.Total.Currency,
+		"payment_method": paymentMethod,
+		"reason":         declined.Reason,
+	})
+	if err != nil {
+		logger.Error("failed to marshal payment declined event", zap.String("order_id", order.ID), zap.Error(err))
+		return
+	}
+
+	if err := s.orderRepo.SaveWithOutbox(ctx, orderToRecord(order), eventID, eventType, occurredAt, payloadBytes, s.paymentDeclinedTopic); err != nil {
+		logger.Error("failed to save declined order with outbox", zap.String("order_id", order.ID), zap.Error(err))
+		return
+	}
+
+	declined.OrderID = order.ID
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUIDv7Generator(t *testing.T) {
+	var gen UUIDv7Generator
+
+	first, second := gen.NewID(), gen.NewID()
+
+	parsed, err := uuid.Parse(first)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), parsed.Version())
+	require.NotEqual(t, first, second)
+	// UUIDv7 упорядочены по времени создания: более поздний ID больше и в строковом виде
+	require.Less(t, first, second)
+}
+
+func TestSequenceIDGenerator(t *testing.T) {
+	gen := NewSequenceIDGenerator("order")
+	require.Equal(t, "order-1", gen.NewID())
+	require.Equal(t, "order-2", gen.NewID())
+
+	// Конкурентные вызовы не выдают одинаковых ID
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[string]struct{})
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := gen.NewID()
+			mu.Lock()
+			ids[id] = struct{}{}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.Len(t, ids, 50)
+}
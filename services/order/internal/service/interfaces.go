@@ -3,26 +3,24 @@ package service
 import (
 	"context"
 	"time"
-)
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=InventoryClient --dir=. --output=./mocks --outpkg=mocks
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+)
 
 // InventoryClient определяет интерфейс для работы с Inventory сервисом
 // Использует доменные типы вместо protobuf - это делает service независимым от gRPC
 type InventoryClient interface {
-	// ReserveStock резервирует товар на складе
-	// Возвращает ошибку, если резервирование не удалось
-	ReserveStock(ctx context.Context, productID string, quantity int32) error
+	// ReserveStockBatch резервирует все позиции заказа на складе по принципу всё или ничего
+	// Возвращает ошибку, если не удалось зарезервировать хотя бы одну позицию (тогда не зарезервировано ничего)
+	ReserveStockBatch(ctx context.Context, orderID string, items []domain.Line) error
 }
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=PaymentClient --dir=. --output=./mocks --outpkg=mocks
-
 // PaymentClient определяет интерфейс для работы с Payment сервисом
 // Использует доменные типы вместо protobuf - это делает service независимым от gRPC
 type PaymentClient interface {
-	// ProcessPayment обрабатывает оплату заказа
+	// ProcessPayment обрабатывает оплату заказа; amount - в минимальных единицах валюты currency (код ISO 4217)
 	// Возвращает transaction ID и ошибку
-	ProcessPayment(ctx context.Context, orderID, userID string, amount float64, method string) (string, error)
+	ProcessPayment(ctx context.Context, orderID, userID string, amount int64, currency, method string) (string, error)
 }
 
 // OrderPaidEvent представляет событие успешной оплаты заказа
@@ -30,11 +28,10 @@ type OrderPaidEvent struct {
 	OrderID       string
 	UserID        string
 	Amount        int64 // сумма в минимальных единицах (копейки, центы)
+	Currency      string
 	PaymentMethod string
 }
 
-//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=PaymentEventPublisher --dir=. --output=./mocks --outpkg=mocks
-
 // PaymentEventPublisher определяет интерфейс для публикации событий оплаты заказа
 // Используется для отправки событий в Kafka или другие системы событий
 type PaymentEventPublisher interface {
@@ -43,6 +40,7 @@ type PaymentEventPublisher interface {
 }
 
 // OrderAssemblyCompletedEvent представляет событие завершения сборки заказа (входящее из Kafka)
+// Items несут статус каждой позиции после сборки; в событиях старого формата их нет - заказ собран целиком
 type OrderAssemblyCompletedEvent struct {
 	EventID      string
 	EventType    string
@@ -50,9 +48,24 @@ type OrderAssemblyCompletedEvent struct {
 	OccurredAt   time.Time
 	OrderID      string
 	UserID       string
+	Items        []AssemblyItem
+}
+
+// AssemblyItem - позиция заказа в событии сборки
+type AssemblyItem struct {
+	ProductID string
+	Quantity  int32
+	Status    domain.ItemStatus // domain.ItemStatusAssembled или domain.ItemStatusCancelled; пусто - собрана
 }
 
 // OrderMetricsRecorder записывает метрики заказов (опционально, может быть nil).
 type OrderMetricsRecorder interface {
 	RecordOrderCreated(revenueCents int64)
+	RecordOrderItemsReadError()
+}
+
+// IDGenerator выдаёт ID заказов и событий outbox
+// В проде - UUIDv7Generator, в тестах - SequenceIDGenerator с предсказуемыми ID
+type IDGenerator interface {
+	NewID() string
 }
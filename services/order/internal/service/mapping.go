@@ -0,0 +1,81 @@
+package service
+
+import (
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// Преобразования между доменной моделью и структурами хранилища.
+// Схема хранения (Unix timestamp, сумма и валюта отдельными полями) не протекает в domain и HTTP API.
+
+// orderToRecord преобразует агрегат заказа в структуру repository для сохранения
+func orderToRecord(order domain.Order) repository.Order {
+	items := make([]repository.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, repository.OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Status:    string(item.Status),
+			UnitPrice: item.UnitPrice.Amount,
+			Currency:  item.UnitPrice.Currency,
+		})
+	}
+
+	return repository.Order{
+		ID:          order.ID,
+		UserID:      order.UserID,
+		Status:      string(order.Status),
+		Items:       items,
+		TotalAmount: order.Total.Amount,
+		Currency:    order.Total.Currency,
+		CreatedAt:   unixOrZero(order.CreatedAt),
+		ArchivedAt:  unixOrZero(order.ArchivedAt),
+	}
+}
+
+// orderFromRecord восстанавливает агрегат заказа из структуры repository
+// Пустой статус позиции (заказы до появления статусов позиций) читается как ItemStatusReserved
+func orderFromRecord(record repository.Order) domain.Order {
+	items := make([]domain.Item, 0, len(record.Items))
+	for _, item := range record.Items {
+		status := domain.ItemStatus(item.Status)
+		if status == "" {
+			status = domain.ItemStatusReserved
+		}
+		items = append(items, domain.Item{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Status:    status,
+			UnitPrice: domain.NewMoney(item.UnitPrice, item.Currency),
+		})
+	}
+
+	return domain.Order{
+		ID:         record.ID,
+		UserID:     record.UserID,
+		Status:     domain.Status(record.Status),
+		Items:      items,
+		Total:      domain.NewMoney(record.TotalAmount, record.Currency),
+		CreatedAt:  timeOrZero(record.CreatedAt),
+		ArchivedAt: timeOrZero(record.ArchivedAt),
+		ItemsError: record.ItemsError,
+	}
+}
+
+// unixOrZero возвращает Unix timestamp; нулевое время хранится как 0
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// timeOrZero возвращает время по Unix timestamp; 0 - нулевое время
+func timeOrZero(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).UTC()
+}
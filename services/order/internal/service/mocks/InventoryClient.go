@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	domain "github.com/shestoi/GoBigTech/services/order/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,17 +14,17 @@ type InventoryClient struct {
 	mock.Mock
 }
 
-// ReserveStock provides a mock function with given fields: ctx, productID, quantity
-func (_m *InventoryClient) ReserveStock(ctx context.Context, productID string, quantity int32) error {
-	ret := _m.Called(ctx, productID, quantity)
+// ReserveStockBatch provides a mock function with given fields: ctx, orderID, items
+func (_m *InventoryClient) ReserveStockBatch(ctx context.Context, orderID string, items []domain.Line) error {
+	ret := _m.Called(ctx, orderID, items)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ReserveStock")
+		panic("no return value specified for ReserveStockBatch")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int32) error); ok {
-		r0 = rf(ctx, productID, quantity)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []domain.Line) error); ok {
+		r0 = rf(ctx, orderID, items)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -13,9 +13,9 @@ type PaymentClient struct {
 	mock.Mock
 }
 
-// ProcessPayment provides a mock function with given fields: ctx, orderID, userID, amount, method
-func (_m *PaymentClient) ProcessPayment(ctx context.Context, orderID string, userID string, amount float64, method string) (string, error) {
-	ret := _m.Called(ctx, orderID, userID, amount, method)
+// ProcessPayment provides a mock function with given fields: ctx, orderID, userID, amount, currency, method
+func (_m *PaymentClient) ProcessPayment(ctx context.Context, orderID string, userID string, amount int64, currency string, method string) (string, error) {
+	ret := _m.Called(ctx, orderID, userID, amount, currency, method)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ProcessPayment")
@@ -23,17 +23,17 @@ func (_m *PaymentClient) ProcessPayment(ctx context.Context, orderID string, use
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, float64, string) (string, error)); ok {
-		return rf(ctx, orderID, userID, amount, method)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, string, string) (string, error)); ok {
+		return rf(ctx, orderID, userID, amount, currency, method)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, float64, string) string); ok {
-		r0 = rf(ctx, orderID, userID, amount, method)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, string, string) string); ok {
+		r0 = rf(ctx, orderID, userID, amount, currency, method)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, float64, string) error); ok {
-		r1 = rf(ctx, orderID, userID, amount, method)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, string, string) error); ok {
+		r1 = rf(ctx, orderID, userID, amount, currency, method)
 	} else {
 		r1 = ret.Error(1)
 	}
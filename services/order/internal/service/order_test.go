@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
 	"github.com/shestoi/GoBigTech/services/order/internal/service/mocks"
@@ -32,6 +35,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		expectedError        bool
 		errorContains        string
 		validateOrder        func(t *testing.T, order repository.Order)
+		expectedCurrency     string // пусто — domain.DefaultCurrency
 		expectPaymentCalled  bool
 		expectRepoSaveCalled bool
 	}{
@@ -39,7 +43,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			name: "success: all steps succeed with single item",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -59,13 +63,55 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				require.Len(t, order.Items, 1)
 				require.Equal(t, "product-456", order.Items[0].ProductID)
 				require.Equal(t, int32(3), order.Items[0].Quantity)
+				require.Equal(t, int64(3*100*100), order.TotalAmount)
+				require.Equal(t, domain.DefaultCurrency, order.Currency)
+			},
+		},
+		{
+			name: "success: explicit currency is normalized and stored with order",
+			input: CreateOrderInput{
+				UserID: "user-123",
+				Items: []domain.Line{
+					{
+						ProductID: "product-456",
+						Quantity:  1,
+					},
+				},
+				Currency: "usd",
+			},
+			inventoryErrors:      map[string]error{"product-456": nil},
+			paymentTransactionID: "txn-789",
+			expectedError:        false,
+			expectedCurrency:     "USD",
+			expectPaymentCalled:  true,
+			expectRepoSaveCalled: true,
+			validateOrder: func(t *testing.T, order repository.Order) {
+				require.Equal(t, int64(100*100), order.TotalAmount)
+				require.Equal(t, "USD", order.Currency)
 			},
 		},
+		{
+			name: "error: unsupported currency",
+			input: CreateOrderInput{
+				UserID: "user-123",
+				Items: []domain.Line{
+					{
+						ProductID: "product-456",
+						Quantity:  1,
+					},
+				},
+				Currency: "XYZ",
+			},
+			expectedError:        true,
+			errorContains:        "unsupported currency",
+			expectPaymentCalled:  false,
+			expectRepoSaveCalled: false,
+		},
 		{
 			name: "success: all steps succeed with multiple items",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -100,7 +146,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			name: "error: empty items",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items:  []repository.OrderItem{},
+				Items:  []domain.Line{},
 			},
 			inventoryErrors:      nil,
 			paymentTransactionID: "",
@@ -112,10 +158,10 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			expectRepoSaveCalled: false,
 		},
 		{
-			name: "error: inventory ReserveStock fails for first item",
+			name: "error: inventory ReserveStockBatch fails for the only item",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -132,10 +178,10 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			expectRepoSaveCalled: false,
 		},
 		{
-			name: "error: inventory ReserveStock fails for second item",
+			name: "error: inventory ReserveStockBatch fails for second item (nothing reserved)",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -162,7 +208,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			name: "error: payment ProcessPayment fails",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -182,7 +228,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			name: "error: repository Save fails",
 			input: CreateOrderInput{
 				UserID: "user-123",
-				Items: []repository.OrderItem{
+				Items: []domain.Line{
 					{
 						ProductID: "product-456",
 						Quantity:  3,
@@ -208,42 +254,47 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			mockRepo := repoMocks.NewOrderRepository(t)
 
 			logger := zap.NewNop()
-			service := NewOrderService(logger, mockInventory, mockPayment, mockRepo, "order.payment.completed", nil)
+			service := NewOrderService(logger, mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil,
+				NewSequenceIDGenerator("order"))
 
-			// Настройка моков для inventory (для каждого item)
+			// Настройка мока для inventory: один пакетный вызов, ошибка любой позиции - ошибка всего пакета
 			if tt.inventoryErrors != nil {
+				var err error
 				for _, item := range tt.input.Items {
-					err := tt.inventoryErrors[item.ProductID]
-					mockInventory.On("ReserveStock", anyContext(), item.ProductID, item.Quantity).
-						Return(err).Once()
+					if itemErr := tt.inventoryErrors[item.ProductID]; itemErr != nil && err == nil {
+						err = itemErr
+					}
 				}
+				mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), tt.input.Items).
+					Return(err).Once()
 			}
 
 			if tt.expectPaymentCalled {
-				// orderID теперь генерируется динамически, используем MatchedBy для проверки
-				// сумма вычисляется из количества товаров: quantity * pricePerItemCents / 100.0
-				const pricePerItemCents = 100 * 100 // 100 условных единиц, каждая = 100 копеек
+				// orderID выдаёт SequenceIDGenerator: первый ID теста - ID заказа
+				// сумма вычисляется из количества товаров: quantity * pricePerItemCents, в копейках
 
 				expectedTotalAmountCents := int64(0) // ожидаемая сумма в копейках
 				for _, item := range tt.input.Items {
 					expectedTotalAmountCents += int64(item.Quantity) * pricePerItemCents
 				}
-				expectedAmount := float64(expectedTotalAmountCents) / 100.0 // конвертируем в float64 для ProcessPayment
+				expectedCurrency := tt.expectedCurrency
+				if expectedCurrency == "" {
+					expectedCurrency = domain.DefaultCurrency
+				}
 
 				mockPayment.On("ProcessPayment", anyContext(),
-					mock.MatchedBy(func(orderID string) bool {
-						return len(orderID) > 0 && orderID[:6] == "order-" // проверяем, что ID заказа начинается с "order-"
-					}),
+					"order-1",
 					tt.input.UserID,
-					mock.MatchedBy(func(amount float64) bool {
+					mock.MatchedBy(func(amount int64) bool {
 						// Проверяем сумму - должна совпадать с ожидаемой
 						// Используем точное сравнение, так как расчет целочисленный
-						if amount != expectedAmount {
-							t.Logf("Amount mismatch: expected %.2f, got %.2f", expectedAmount, amount)
+						if amount != expectedTotalAmountCents {
+							t.Logf("Amount mismatch: expected %d, got %d", expectedTotalAmountCents, amount)
 							return false
 						}
 						return true
 					}),
+					expectedCurrency,
 					"card").
 					Return(tt.paymentTransactionID, tt.paymentError).Once()
 			} else {
@@ -260,7 +311,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 					}
 					for i, expectedItem := range tt.input.Items {
 						if order.Items[i].ProductID != expectedItem.ProductID ||
-							order.Items[i].Quantity != expectedItem.Quantity {
+							order.Items[i].Quantity != expectedItem.Quantity ||
+							order.Items[i].Status != repository.ItemStatusReserved {
 							return false
 						}
 					}
@@ -287,11 +339,16 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				require.NotNil(t, result)
 				require.NotEmpty(t, result.OrderID)
 				require.Equal(t, tt.input.UserID, result.UserID)
-				require.Equal(t, "paid", result.Status)
+				require.Equal(t, domain.StatusPaid, result.Status)
+				require.NotEmpty(t, result.Total.Currency)
+				require.Positive(t, result.Total.Amount)
 				require.Equal(t, len(tt.input.Items), len(result.Items))
 				for i, expectedItem := range tt.input.Items {
 					require.Equal(t, expectedItem.ProductID, result.Items[i].ProductID)
 					require.Equal(t, expectedItem.Quantity, result.Items[i].Quantity)
+					require.Equal(t, domain.ItemStatusReserved, result.Items[i].Status)
+					// Цена единицы и валюта фиксируются в позиции на момент покупки
+					require.Equal(t, domain.NewMoney(pricePerItemCents, result.Total.Currency), result.Items[i].UnitPrice)
 				}
 			}
 
@@ -335,7 +392,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 			validateOutput: func(t *testing.T, output *GetOrderOutput) {
 				require.Equal(t, "order-123", output.OrderID)
 				require.Equal(t, "user-456", output.UserID)
-				require.Equal(t, "paid", output.Status)
+				require.Equal(t, domain.StatusPaid, output.Status)
 				require.Len(t, output.Items, 1)
 				require.Equal(t, "product-789", output.Items[0].ProductID)
 				require.Equal(t, int32(5), output.Items[0].Quantity)
@@ -366,7 +423,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 			validateOutput: func(t *testing.T, output *GetOrderOutput) {
 				require.Equal(t, "order-456", output.OrderID)
 				require.Equal(t, "user-789", output.UserID)
-				require.Equal(t, "paid", output.Status)
+				require.Equal(t, domain.StatusPaid, output.Status)
 				require.Len(t, output.Items, 2)
 				require.Equal(t, "product-111", output.Items[0].ProductID)
 				require.Equal(t, int32(2), output.Items[0].Quantity)
@@ -400,7 +457,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 			validateOutput: func(t *testing.T, output *GetOrderOutput) {
 				require.Equal(t, "order-456", output.OrderID)
 				require.Equal(t, "user-789", output.UserID)
-				require.Equal(t, "pending", output.Status)
+				require.Equal(t, domain.Status("pending"), output.Status)
 				require.Len(t, output.Items, 0)
 			},
 		},
@@ -414,9 +471,9 @@ func TestOrderService_GetOrder(t *testing.T) {
 			mockRepo := repoMocks.NewOrderRepository(t)
 
 			logger := zap.NewNop()
-			service := NewOrderService(logger, mockInventory, mockPayment, mockRepo, "order.payment.completed", nil)
+			service := NewOrderService(logger, mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
 
-			mockRepo.On("GetByID", ctx, tt.input.OrderID).
+			mockRepo.On("GetByID", ctx, tt.input.OrderID, repository.GetOptions{IncludeArchived: tt.input.IncludeArchived}).
 				Return(tt.repoOrder, tt.repoError).Once()
 
 			// Act
@@ -441,3 +498,194 @@ func TestOrderService_GetOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderService_CreateOrder_GeneratedIDs(t *testing.T) {
+	ctx := context.Background()
+	mockInventory := mocks.NewInventoryClient(t)
+	mockPayment := mocks.NewPaymentClient(t)
+	mockRepo := repoMocks.NewOrderRepository(t)
+	svc := NewOrderService(zap.NewNop(), mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil,
+		NewSequenceIDGenerator("id"))
+
+	// Первый ID - заказ, второй - событие order.payment.completed
+	mockInventory.On("ReserveStockBatch", anyContext(), "id-1", mock.Anything).Return(nil).Once()
+	mockPayment.On("ProcessPayment", anyContext(), "id-1", "user-123", mock.Anything, domain.DefaultCurrency, "card").
+		Return("tx-1", nil).Once()
+	mockRepo.On("SaveWithOutbox", anyContext(), mock.MatchedBy(func(order repository.Order) bool {
+		return order.ID == "id-1"
+	}), "id-2", "order.payment.completed", mock.Anything, mock.MatchedBy(func(payload []byte) bool {
+		var event map[string]interface{}
+		return json.Unmarshal(payload, &event) == nil && event["event_id"] == "id-2" && event["order_id"] == "id-1"
+	}), "order.payment.completed").Return(nil).Once()
+
+	result, err := svc.CreateOrder(ctx, CreateOrderInput{
+		UserID: "user-123",
+		Items:  []domain.Line{{ProductID: "product-456", Quantity: 1}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "id-1", result.OrderID)
+}
+
+func TestOrderService_CreateOrder_PaymentDeclined(t *testing.T) {
+	ctx := context.Background()
+	input := CreateOrderInput{
+		UserID: "user-123",
+		Items:  []domain.Line{{ProductID: "product-456", Quantity: 1}},
+	}
+
+	tests := []struct {
+		name            string
+		saveErr         error
+		expectedOrderID bool
+	}{
+		{name: "declined order is saved with outbox event", expectedOrderID: true},
+		{name: "save failure does not hide the decline", saveErr: errors.New("database error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInventory := mocks.NewInventoryClient(t)
+			mockPayment := mocks.NewPaymentClient(t)
+			mockRepo := repoMocks.NewOrderRepository(t)
+			svc := NewOrderService(zap.NewNop(), mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+
+			mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+			mockPayment.On("ProcessPayment", anyContext(), mock.Anything, "user-123", mock.Anything, domain.DefaultCurrency, "card").
+				Return("", &PaymentDeclinedError{Reason: DeclineReasonInsufficientFunds, Message: "not enough money"}).Once()
+			mockRepo.On("SaveWithOutbox", anyContext(), mock.MatchedBy(func(order repository.Order) bool {
+				return order.Status == string(domain.StatusPaymentDeclined) &&
+					len(order.Items) == 1 &&
+					order.Items[0].Status == repository.ItemStatusCancelled
+			}), mock.Anything, "order.payment.declined", mock.Anything, mock.MatchedBy(func(payload []byte) bool {
+				var event map[string]interface{}
+				return json.Unmarshal(payload, &event) == nil && event["reason"] == DeclineReasonInsufficientFunds
+			}), "order.payment.declined").Return(tt.saveErr).Once()
+
+			result, err := svc.CreateOrder(ctx, input)
+
+			require.Nil(t, result)
+			var declined *PaymentDeclinedError
+			require.ErrorAs(t, err, &declined)
+			require.Equal(t, DeclineReasonInsufficientFunds, declined.Reason)
+			require.False(t, declined.Retryable())
+			if tt.expectedOrderID {
+				require.NotEmpty(t, declined.OrderID)
+			} else {
+				require.Empty(t, declined.OrderID)
+			}
+		})
+	}
+}
+
+func TestOrderService_CreateOrder_PaymentUnavailable(t *testing.T) {
+	ctx := context.Background()
+	input := CreateOrderInput{
+		UserID: "user-123",
+		Items:  []domain.Line{{ProductID: "product-456", Quantity: 1}},
+	}
+	unavailable := &DependencyUnavailableError{Dependency: DependencyPayment, RetryAfter: time.Second, Err: errors.New("connection refused")}
+
+	t.Run("payment is retried once", func(t *testing.T) {
+		mockInventory := mocks.NewInventoryClient(t)
+		mockPayment := mocks.NewPaymentClient(t)
+		mockRepo := repoMocks.NewOrderRepository(t)
+		svc := NewOrderService(zap.NewNop(), mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", time.Millisecond, nil, nil)
+
+		mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+		mockPayment.On("ProcessPayment", anyContext(), mock.Anything, "user-123", mock.Anything, domain.DefaultCurrency, "card").
+			Return("", unavailable).Once()
+		mockPayment.On("ProcessPayment", anyContext(), mock.Anything, "user-123", mock.Anything, domain.DefaultCurrency, "card").
+			Return("txn-1", nil).Once()
+		mockRepo.On("SaveWithOutbox", anyContext(), mock.Anything, mock.Anything, "order.payment.completed", mock.Anything, mock.Anything, "order.payment.completed").
+			Return(nil).Once()
+
+		result, err := svc.CreateOrder(ctx, input)
+
+		require.NoError(t, err)
+		require.Equal(t, domain.StatusPaid, result.Status)
+	})
+
+	tests := []struct {
+		name          string
+		payErr        error
+		expectedCalls int
+	}{
+		{name: "second failure is returned", payErr: unavailable, expectedCalls: 2},
+		{name: "open breaker is not retried", payErr: &DependencyUnavailableError{Dependency: DependencyPayment, BreakerOpen: true}, expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInventory := mocks.NewInventoryClient(t)
+			mockPayment := mocks.NewPaymentClient(t)
+			svc := NewOrderService(zap.NewNop(), mockInventory, mockPayment, repoMocks.NewOrderRepository(t), "order.payment.completed", "order.payment.declined", "order.assembled", time.Millisecond, nil, nil)
+
+			mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+			mockPayment.On("ProcessPayment", anyContext(), mock.Anything, "user-123", mock.Anything, domain.DefaultCurrency, "card").
+				Return("", tt.payErr).Times(tt.expectedCalls)
+
+			_, err := svc.CreateOrder(ctx, input)
+
+			var unavailableErr *DependencyUnavailableError
+			require.ErrorAs(t, err, &unavailableErr)
+		})
+	}
+}
+
+func TestOrderService_CreateOrder_InventoryUnavailable(t *testing.T) {
+	ctx := context.Background()
+	mockInventory := mocks.NewInventoryClient(t)
+	mockPayment := mocks.NewPaymentClient(t)
+	mockRepo := repoMocks.NewOrderRepository(t)
+	svc := NewOrderService(zap.NewNop(), mockInventory, mockPayment, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", time.Millisecond, nil, nil)
+
+	unavailable := &DependencyUnavailableError{Dependency: DependencyInventory, RetryAfter: time.Second, Err: errors.New("connection refused")}
+	mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), mock.Anything).Return(unavailable).Once()
+	mockInventory.On("ReserveStockBatch", anyContext(), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+	mockPayment.On("ProcessPayment", anyContext(), mock.Anything, "user-123", mock.Anything, domain.DefaultCurrency, "card").
+		Return("txn-1", nil).Once()
+	mockRepo.On("SaveWithOutbox", anyContext(), mock.Anything, mock.Anything, "order.payment.completed", mock.Anything, mock.Anything, "order.payment.completed").
+		Return(nil).Once()
+
+	result, err := svc.CreateOrder(ctx, CreateOrderInput{
+		UserID: "user-123",
+		Items:  []domain.Line{{ProductID: "product-456", Quantity: 1}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPaid, result.Status)
+}
+
+// fakeOrderMetrics считает вызовы OrderMetricsRecorder
+type fakeOrderMetrics struct {
+	itemsReadErrors int
+}
+
+func (m *fakeOrderMetrics) RecordOrderCreated(int64) {}
+
+func (m *fakeOrderMetrics) RecordOrderItemsReadError() { m.itemsReadErrors++ }
+
+func TestOrderService_GetOrder_TolerateItemErrors(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := repoMocks.NewOrderRepository(t)
+	metrics := &fakeOrderMetrics{}
+	svc := NewOrderService(zap.NewNop(), mocks.NewInventoryClient(t), mocks.NewPaymentClient(t), mockRepo,
+		"order.payment.completed", "order.payment.declined", "order.assembled", 0, metrics, nil)
+
+	mockRepo.On("GetByID", ctx, "order-1", repository.GetOptions{TolerateItemErrors: true}).
+		Return(repository.Order{
+			ID:         "order-1",
+			UserID:     "user-1",
+			Status:     "paid",
+			Items:      []repository.OrderItem{},
+			ItemsError: "can't scan into dest[1]",
+		}, nil).Once()
+
+	result, err := svc.GetOrder(ctx, GetOrderInput{OrderID: "order-1", TolerateItemErrors: true})
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPaid, result.Status)
+	require.Empty(t, result.Items)
+	require.Equal(t, "can't scan into dest[1]", result.ItemsError)
+	require.Equal(t, 1, metrics.itemsReadErrors)
+}
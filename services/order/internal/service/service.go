@@ -3,8 +3,8 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -12,9 +12,20 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 )
 
+// pricePerItemCents - цена единицы любого товара: 100 единиц валюты заказа, каждая = 100 минимальных единиц
+const pricePerItemCents = 100 * 100
+
+// unitPrice возвращает текущую цену единицы товара в минимальных единицах валюты
+// Упрощённо все товары стоят одинаково; в реальном приложении цена берётся из каталога товаров
+func unitPrice(productID string) int64 {
+	return pricePerItemCents
+}
+
 // OrderService содержит бизнес-логику работы с заказами
 type OrderService struct {
 	logger                *zap.Logger
@@ -22,43 +33,62 @@ type OrderService struct {
 	paymentClient         PaymentClient
 	orderRepo             repository.OrderRepository
 	paymentCompletedTopic string
+	paymentDeclinedTopic  string
+	assembledTopic        string
+	unavailableRetryDelay time.Duration        // пауза перед повтором идемпотентного шага; 0 - без повтора
 	metrics               OrderMetricsRecorder // опционально, может быть nil
+	ids                   IDGenerator
 }
 
 // NewOrderService создаёт новый экземпляр OrderService.
+// topic - топик события успешной оплаты, declinedTopic - топик события отказа в оплате,
+// assembledTopic - топик события order.assembled (заказ собран).
+// unavailableRetryDelay - пауза перед единственным повтором резервирования или оплаты, если Inventory или Payment недоступен; 0 - без повтора.
 // metrics может быть nil — тогда метрики не записываются.
+// ids - генератор ID заказов и событий; nil - UUIDv7Generator.
 func NewOrderService(
 	logger *zap.Logger,
 	inventoryClient InventoryClient,
 	paymentClient PaymentClient,
 	orderRepo repository.OrderRepository,
 	topic string,
+	declinedTopic string,
+	assembledTopic string,
+	unavailableRetryDelay time.Duration,
 	metrics OrderMetricsRecorder,
+	ids IDGenerator,
 ) *OrderService {
+	if ids == nil {
+		ids = UUIDv7Generator{}
+	}
 	return &OrderService{
 		logger:                logger,
 		inventoryClient:       inventoryClient,
 		paymentClient:         paymentClient,
 		orderRepo:             orderRepo,
 		paymentCompletedTopic: topic,
+		paymentDeclinedTopic:  declinedTopic,
+		assembledTopic:        assembledTopic,
+		unavailableRetryDelay: unavailableRetryDelay,
 		metrics:               metrics,
+		ids:                   ids,
 	}
 }
 
 // CreateOrderInput содержит входные данные для создания заказа
-// Использует доменную модель repository.OrderItem для работы с несколькими товарами
 type CreateOrderInput struct {
-	UserID string
-	Items  []repository.OrderItem
+	UserID   string
+	Items    []domain.Line
+	Currency string // код валюты ISO 4217; пустое значение — domain.DefaultCurrency
 }
 
 // CreateOrderOutput содержит результат создания заказа
-// Использует доменную модель repository.OrderItem
 type CreateOrderOutput struct {
 	OrderID string
 	UserID  string
-	Status  string
-	Items   []repository.OrderItem
+	Status  domain.Status
+	Items   []domain.Item
+	Total   domain.Money
 }
 
 // CreateOrder создаёт новый заказ
@@ -68,76 +98,88 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 	ctx, span := tracer.Start(ctx, "CreateOrder", trace.WithSpanKind(trace.SpanKindInternal))
 	defer span.End()
 
-	log.Printf("Creating order for user %s with %d items", input.UserID, len(input.Items))
+	logger := platformobservability.L(ctx, s.logger)
+	logger.Info("creating order", zap.String("user_id", input.UserID), zap.Int("items", len(input.Items)))
+
+	// 1. Генерируем ID заказа
+	orderID := s.ids.NewID()
 
-	// Валидация: должен быть хотя бы один товар
-	if len(input.Items) == 0 {
-		err := fmt.Errorf("order must contain at least one item")
+	// Формируем заказ: валидация позиций и валюты, цена единицы фиксируется в позиции на момент покупки,
+	// поэтому изменение цен каталога не меняет старые заказы
+	order, err := domain.NewOrder(orderID, input.UserID, input.Items, input.Currency, unitPrice)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	// 1. Резервируем товары через Inventory сервис
-	ctx, reserveSpan := tracer.Start(ctx, "Inventory.ReserveStock", trace.WithSpanKind(trace.SpanKindClient))
-	for _, item := range input.Items {
-		err := s.inventoryClient.ReserveStock(ctx, item.ProductID, item.Quantity)
-		if err != nil {
-			log.Printf("Inventory ReserveStock error for product %s: %v", item.ProductID, err)
-			reserveSpan.RecordError(err)
-			reserveSpan.SetStatus(codes.Error, err.Error())
-			reserveSpan.End()
-			return nil, fmt.Errorf("inventory service error for product %s: %w", item.ProductID, err)
-		}
+	// 2. Резервируем все товары одним запросом в Inventory: либо все позиции, либо ни одной.
+	// Резервирование идемпотентно по order_id, поэтому при недоступности Inventory его можно повторить
+	ctx, reserveSpan := tracer.Start(ctx, "Inventory.ReserveStockBatch", trace.WithSpanKind(trace.SpanKindClient))
+	if err := retryUnavailable(ctx, logger, s.unavailableRetryDelay, "inventory", func() error {
+		return s.inventoryClient.ReserveStockBatch(ctx, orderID, order.Lines())
+	}); err != nil {
+		logger.Error("inventory reserve stock failed", zap.String("order_id", orderID), zap.Error(err))
+		reserveSpan.RecordError(err)
+		reserveSpan.SetStatus(codes.Error, err.Error())
+		reserveSpan.End()
+		return nil, fmt.Errorf("inventory service error: %w", err)
 	}
 	reserveSpan.End()
 
-	log.Printf("All inventory items reserved successfully")
-
-	// 2. Генерируем ID заказа (в будущем можно использовать UUID или другой генератор)
-	orderID := fmt.Sprintf("order-%d", time.Now().UnixNano()) //генерируем уникальный ID для заказа
-
-	// 3. Вычисляем сумму заказа (упрощённо: каждый товар стоит 100 единиц)
-	// В реальном приложении нужно получать цены из каталога товаров
+	logger.Info("inventory items reserved")
 
-	const pricePerItemCents = 100 * 100 // 100 условных единиц, каждая = 100 копеек
-
-	totalAmount := int64(0)
-	for _, item := range input.Items {
-		totalAmount += int64(item.Quantity) * pricePerItemCents
-	}
-
-	// 4. Обрабатываем оплату через Payment сервис
+	// 3. Обрабатываем оплату через Payment сервис
 	ctx, paymentSpan := tracer.Start(ctx, "Payment.Charge", trace.WithSpanKind(trace.SpanKindClient))
 	paymentMethod := "card" // можно передавать из input в будущем
-	amountFloat := float64(totalAmount) / 100.0
-	transactionID, err := s.paymentClient.ProcessPayment(ctx, orderID, input.UserID, amountFloat, paymentMethod)
+	// Оплата идемпотентна по order_id, поэтому при недоступности Payment её можно повторить
+	var transactionID string
+	err = retryUnavailable(ctx, logger, s.unavailableRetryDelay, "payment", func() error {
+		var callErr error
+		transactionID, callErr = s.paymentClient.ProcessPayment(ctx, orderID, input.UserID, order.Total.Amount, order.Total.Currency, paymentMethod)
+		return callErr
+	})
 	if err != nil {
 		paymentSpan.RecordError(err)
 		paymentSpan.SetStatus(codes.Error, err.Error())
 		paymentSpan.End()
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		log.Printf("Payment ProcessPayment error: %v", err)
+
+		// Отказ в оплате - бизнес-результат: сохраняем заказ с причиной и публикуем событие
+		var declined *PaymentDeclinedError
+		if errors.As(err, &declined) {
+			logger.Warn("payment declined", zap.String("order_id", orderID), zap.String("reason", declined.Reason))
+			s.saveDeclinedOrder(ctx, logger, order, paymentMethod, declined)
+			return nil, fmt.Errorf("payment declined: %w", err)
+		}
+
+		logger.Error("payment failed", zap.String("order_id", orderID), zap.Error(err))
 		return nil, fmt.Errorf("payment service error: %w", err)
 	}
 	paymentSpan.End()
 
-	log.Printf("Payment processed successfully, transaction ID: %s", transactionID)
+	logger.Info("payment processed", zap.String("order_id", orderID), zap.String("transaction_id", transactionID))
 
-	// 5. Создаём доменную модель заказа
-	order := repository.Order{
-		ID:     orderID,
-		UserID: input.UserID,
-		Status: "paid",
-		Items:  input.Items, // Используем Items из input напрямую
+	// 4. Заказ оплачен, позиции остаются зарезервированными до сборки
+	if err := order.MarkPaid(); err != nil {
+		return nil, err
 	}
 
-	// 6. Формируем событие успешной оплаты заказа
-	eventID := fmt.Sprintf("payment-%s-%d", orderID, time.Now().UnixNano())
+	// 5. Формируем событие успешной оплаты заказа
+	eventID := s.ids.NewID()
 	eventType := "order.payment.completed"
 	occurredAt := time.Now().UTC()
 
+	// Краткий состав заказа: downstream сервисы (assembly, notification) используют его без обращения к Order
+	eventItems := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		eventItems = append(eventItems, map[string]interface{}{
+			"product_id": item.ProductID,
+			"quantity":   item.Quantity,
+		})
+	}
+
 	eventPayload := map[string]interface{}{
 		"event_id":       eventID,
 		"event_type":     eventType,
@@ -145,8 +187,10 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 		"occurred_at":    occurredAt.Format(time.RFC3339),
 		"order_id":       orderID,
 		"user_id":        input.UserID,
-		"amount":         totalAmount,
+		"amount":         order.Total.Amount,
+		"currency":       order.Total.Currency,
 		"payment_method": paymentMethod,
+		"items":          eventItems,
 	}
 
 	payloadBytes, err := json.Marshal(eventPayload)
@@ -154,63 +198,140 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
 	}
 
-	// 7. Сохраняем заказ и событие в outbox в одной транзакции
+	// 6. Сохраняем заказ и событие в outbox в одной транзакции
 	topic := s.paymentCompletedTopic
-	if err := s.orderRepo.SaveWithOutbox(ctx, order, eventID, eventType, occurredAt, payloadBytes, topic); err != nil {
+	if err := s.orderRepo.SaveWithOutbox(ctx, orderToRecord(order), eventID, eventType, occurredAt, payloadBytes, topic); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		log.Printf("Failed to save order with outbox: %v", err)
+		logger.Error("failed to save order with outbox", zap.String("order_id", orderID), zap.Error(err))
 		return nil, fmt.Errorf("failed to save order with outbox: %w", err)
 	}
 
 	if s.metrics != nil {
-		s.metrics.RecordOrderCreated(totalAmount)
+		s.metrics.RecordOrderCreated(order.Total.Amount)
 	}
 
-	log.Printf("Order saved successfully with outbox event: %s", orderID)
+	logger.Info("order saved with outbox event", zap.String("order_id", orderID), zap.String("event_id", eventID))
 
 	return &CreateOrderOutput{
-		OrderID: orderID,
-		UserID:  input.UserID,
-		Status:  "paid",
-		Items:   input.Items, // Возвращаем Items из input
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		Status:  order.Status,
+		Items:   order.Items,
+		Total:   order.Total,
 	}, nil
 }
 
 // GetOrderInput содержит входные данные для получения заказа
 type GetOrderInput struct {
-	OrderID string
+	OrderID         string
+	IncludeArchived bool // вернуть заказ, даже если он архивирован (только для админов)
+	// TolerateItemErrors - вернуть заказ без позиций, если их не удалось прочитать (для поддержки, только для админов).
+	// Бизнес-логика использует строгий режим: ошибка чтения позиций - ошибка запроса
+	TolerateItemErrors bool
 }
 
 // GetOrderOutput содержит результат получения заказа
-// Использует доменную модель repository.OrderItem
 type GetOrderOutput struct {
-	OrderID string
-	UserID  string
-	Status  string
-	Items   []repository.OrderItem
+	OrderID    string
+	UserID     string
+	Status     domain.Status
+	Items      []domain.Item
+	Total      domain.Money
+	ArchivedAt time.Time // нулевое значение - заказ не архивирован
+	ItemsError string    // позиции не прочитаны (только при GetOrderInput.TolerateItemErrors), Items пустой
 }
 
 // GetOrder получает заказ по ID
 // Бизнес-логика здесь, а не в HTTP-обработчике
 func (s *OrderService) GetOrder(ctx context.Context, input GetOrderInput) (*GetOrderOutput, error) {
-	log.Printf("Getting order: %s", input.OrderID)
+	logger := platformobservability.L(ctx, s.logger).With(zap.String("order_id", input.OrderID))
+	logger.Debug("getting order")
 
 	// Получаем заказ из репозитория
-	order, err := s.orderRepo.GetByID(ctx, input.OrderID)
+	order, err := s.orderRepo.GetByID(ctx, input.OrderID, repository.GetOptions{
+		IncludeArchived:    input.IncludeArchived,
+		TolerateItemErrors: input.TolerateItemErrors,
+	})
 	if err != nil {
-		log.Printf("Failed to get order: %v", err)
+		logger.Warn("failed to get order", zap.Error(err))
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
+	if order.ItemsError != "" {
+		logger.Error("order returned without items: failed to read order items", zap.String("items_error", order.ItemsError))
+		if s.metrics != nil {
+			s.metrics.RecordOrderItemsReadError()
+		}
+	}
+
+	// Преобразуем запись хранилища в доменную модель и DTO
+	return newGetOrderOutput(orderFromRecord(order)), nil
+}
 
-	// Преобразуем доменную модель в DTO
-	// Возвращаем Items целиком, без извлечения первого элемента
+// newGetOrderOutput преобразует доменную модель заказа в DTO
+// Возвращает Items целиком, без извлечения первого элемента
+func newGetOrderOutput(order domain.Order) *GetOrderOutput {
 	return &GetOrderOutput{
-		OrderID: order.ID,
-		UserID:  order.UserID,
-		Status:  order.Status,
-		Items:   order.Items, // Возвращаем все Items
-	}, nil
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		Items:      order.Items, // Возвращаем все Items
+		Total:      order.Total,
+		ArchivedAt: order.ArchivedAt,
+		ItemsError: order.ItemsError,
+	}
+}
+
+// DefaultListLimit - сколько заказов возвращает ListOrders, если limit не задан
+const DefaultListLimit = 50
+
+// MaxListLimit - максимальное количество заказов за один запрос ListOrders
+const MaxListLimit = 100
+
+// ErrUserIDRequired возвращается, если для списка заказов не указан ни пользователь, ни товар
+var ErrUserIDRequired = errors.New("user_id or product_id is required")
+
+// ListOrdersInput содержит входные данные для получения списка заказов
+// Нужен хотя бы один из UserID и ProductID
+type ListOrdersInput struct {
+	UserID          string
+	ProductID       string // только заказы с этим товаром; без UserID - по всем пользователям (отзыв товара, инциденты)
+	IncludeArchived bool   // включать архивные заказы (только для админов)
+	Limit           int    // 0 - DefaultListLimit, больше MaxListLimit - обрезается до MaxListLimit
+}
+
+// ListOrders возвращает заказы пользователя и/или заказы с товаром, новые первыми
+// Архивные заказы по умолчанию не возвращаются
+func (s *OrderService) ListOrders(ctx context.Context, input ListOrdersInput) ([]GetOrderOutput, error) {
+	if input.UserID == "" && input.ProductID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	orders, err := s.orderRepo.List(ctx, repository.ListFilter{
+		UserID:          input.UserID,
+		ProductID:       input.ProductID,
+		IncludeArchived: input.IncludeArchived,
+		Limit:           limit,
+	})
+	if err != nil {
+		platformobservability.L(ctx, s.logger).Error("failed to list orders",
+			zap.String("user_id", input.UserID), zap.String("product_id", input.ProductID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	result := make([]GetOrderOutput, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, *newGetOrderOutput(orderFromRecord(order)))
+	}
+	return result, nil
 }
 
 // HandleOrderAssemblyCompleted обрабатывает событие завершения сборки заказа
@@ -222,13 +343,33 @@ func (s *OrderService) HandleOrderAssemblyCompleted(ctx context.Context, event O
 		zap.String("user_id", event.UserID),
 	)
 
-	// Вызываем repository метод, который делает insert в inbox + update status в одной транзакции
+	// Статусы позиций из события; пустой статус - позиция собрана
+	itemChanges := make([]repository.ItemStatusChange, 0, len(event.Items))
+	for _, item := range event.Items {
+		status := item.Status
+		if status == "" {
+			status = domain.ItemStatusAssembled
+		}
+		itemChanges = append(itemChanges, repository.ItemStatusChange{
+			ProductID: item.ProductID,
+			Status:    string(status),
+		})
+	}
+
+	assembled, err := s.newOrderAssembledEvent(event, itemChanges)
+	if err != nil {
+		return fmt.Errorf("failed to build order assembled event: %w", err)
+	}
+
+	// Вызываем repository метод, который делает insert в inbox + update status (заказа и позиций) + insert в outbox в одной транзакции
 	inserted, rowsAffected, err := s.orderRepo.HandleAssemblyCompletedTx(
 		ctx,
 		event.EventID,
 		event.EventType,
 		event.OccurredAt,
 		event.OrderID,
+		itemChanges,
+		assembled,
 	)
 	if err != nil {
 		s.logger.Error("failed to handle assembly completed event",
@@ -264,3 +405,48 @@ func (s *OrderService) HandleOrderAssemblyCompleted(ctx context.Context, event O
 
 	return nil
 }
+
+// newOrderAssembledEvent формирует событие order.assembled для outbox: собственное событие Order о том,
+// что заказ собран. Downstream сервисы (delivery, notification) читают его вместо сырого топика Assembly.
+// Событие попадает в outbox только вместе с переходом заказа paid -> assembled.
+func (s *OrderService) newOrderAssembledEvent(event OrderAssemblyCompletedEvent, items []repository.ItemStatusChange) (repository.OutboxEvent, error) {
+	// ID выводится из входящего события, а не из s.ids: повторная доставка order.assembly.completed
+	// даёт тот же event_id, и downstream дедуплицирует его так же, как исходное событие
+	eventID := fmt.Sprintf("assembled-%s-%s", event.OrderID, event.EventID)
+	eventType := "order.assembled"
+	occurredAt := time.Now().UTC()
+
+	// Статусы позиций из события сборки; пустой список - заказ собран целиком
+	eventItems := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		eventItems = append(eventItems, map[string]interface{}{
+			"product_id": item.ProductID,
+			"status":     item.Status,
+		})
+	}
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"event_id":              eventID,
+		"event_type":            eventType,
+		"event_version":         1,
+		"occurred_at":           occurredAt.Format(time.RFC3339),
+		"order_id":              event.OrderID,
+		"user_id":               event.UserID,
+		"status":                domain.StatusAssembled,
+		"assembly_event_id":     event.EventID,
+		"assembly_completed_at": event.OccurredAt.UTC().Format(time.RFC3339),
+		"items":                 eventItems,
+	})
+	if err != nil {
+		return repository.OutboxEvent{}, err
+	}
+
+	return repository.OutboxEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		OccurredAt:  occurredAt,
+		AggregateID: event.OrderID,
+		Payload:     payloadBytes,
+		Topic:       s.assembledTopic,
+	}, nil
+}
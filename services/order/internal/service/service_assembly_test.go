@@ -2,13 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 
+	"github.com/shestoi/GoBigTech/services/order/internal/domain"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
 	"github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
 )
 
@@ -27,9 +31,9 @@ func TestOrderService_HandleOrderAssemblyCompleted(t *testing.T) {
 
 	t.Run("inserted=true, rowsAffected=1 -> ok", func(t *testing.T) {
 		mockRepo := mocks.NewOrderRepository(t)
-		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", nil)
+		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
 
-		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123").
+		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123", []repository.ItemStatusChange{}, mock.AnythingOfType("repository.OutboxEvent")).
 			Return(true, int64(1), nil).Once()
 
 		err := svc.HandleOrderAssemblyCompleted(ctx, event)
@@ -40,9 +44,9 @@ func TestOrderService_HandleOrderAssemblyCompleted(t *testing.T) {
 
 	t.Run("inserted=false (duplicate) -> ok, update not required", func(t *testing.T) {
 		mockRepo := mocks.NewOrderRepository(t)
-		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", nil)
+		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
 
-		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123").
+		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123", []repository.ItemStatusChange{}, mock.AnythingOfType("repository.OutboxEvent")).
 			Return(false, int64(0), nil).Once()
 
 		err := svc.HandleOrderAssemblyCompleted(ctx, event)
@@ -53,9 +57,9 @@ func TestOrderService_HandleOrderAssemblyCompleted(t *testing.T) {
 
 	t.Run("inserted=true, rowsAffected=0 -> ok + warn", func(t *testing.T) {
 		mockRepo := mocks.NewOrderRepository(t)
-		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", nil)
+		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
 
-		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123").
+		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123", []repository.ItemStatusChange{}, mock.AnythingOfType("repository.OutboxEvent")).
 			Return(true, int64(0), nil).Once()
 
 		err := svc.HandleOrderAssemblyCompleted(ctx, event)
@@ -66,10 +70,10 @@ func TestOrderService_HandleOrderAssemblyCompleted(t *testing.T) {
 
 	t.Run("repo error -> error", func(t *testing.T) {
 		mockRepo := mocks.NewOrderRepository(t)
-		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", nil)
+		svc := NewOrderService(logger, nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
 
 		repoErr := errors.New("repository error")
-		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123").
+		mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-1", "order.assembly.completed", event.OccurredAt, "order-123", []repository.ItemStatusChange{}, mock.AnythingOfType("repository.OutboxEvent")).
 			Return(false, int64(0), repoErr).Once()
 
 		err := svc.HandleOrderAssemblyCompleted(ctx, event)
@@ -79,3 +83,77 @@ func TestOrderService_HandleOrderAssemblyCompleted(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestOrderService_HandleOrderAssemblyCompleted_ItemStatuses(t *testing.T) {
+	ctx := context.Background()
+
+	event := OrderAssemblyCompletedEvent{
+		EventID:      "evt-2",
+		EventType:    "order.assembly.completed",
+		EventVersion: 1,
+		OccurredAt:   time.Now(),
+		OrderID:      "order-123",
+		UserID:       "user-456",
+		Items: []AssemblyItem{
+			{ProductID: "product-1", Quantity: 2, Status: domain.ItemStatusAssembled},
+			{ProductID: "product-2", Quantity: 1, Status: domain.ItemStatusCancelled},
+			{ProductID: "product-3", Quantity: 1}, // статус не указан - позиция собрана
+		},
+	}
+
+	mockRepo := mocks.NewOrderRepository(t)
+	svc := NewOrderService(zap.NewNop(), nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+
+	mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-2", "order.assembly.completed", event.OccurredAt, "order-123", []repository.ItemStatusChange{
+		{ProductID: "product-1", Status: repository.ItemStatusAssembled},
+		{ProductID: "product-2", Status: repository.ItemStatusCancelled},
+		{ProductID: "product-3", Status: repository.ItemStatusAssembled},
+	}, mock.AnythingOfType("repository.OutboxEvent")).Return(true, int64(1), nil).Once()
+
+	err := svc.HandleOrderAssemblyCompleted(ctx, event)
+	assert.NoError(t, err)
+}
+
+func TestOrderService_HandleOrderAssemblyCompleted_OrderAssembledEvent(t *testing.T) {
+	ctx := context.Background()
+
+	event := OrderAssemblyCompletedEvent{
+		EventID:      "evt-3",
+		EventType:    "order.assembly.completed",
+		EventVersion: 1,
+		OccurredAt:   time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+		OrderID:      "order-123",
+		UserID:       "user-456",
+		Items: []AssemblyItem{
+			{ProductID: "product-1", Quantity: 2, Status: domain.ItemStatusCancelled},
+		},
+	}
+
+	mockRepo := mocks.NewOrderRepository(t)
+	svc := NewOrderService(zap.NewNop(), nil, nil, mockRepo, "order.payment.completed", "order.payment.declined", "order.assembled", 0, nil, nil)
+
+	var assembled repository.OutboxEvent
+	mockRepo.On("HandleAssemblyCompletedTx", ctx, "evt-3", "order.assembly.completed", event.OccurredAt, "order-123",
+		mock.Anything, mock.AnythingOfType("repository.OutboxEvent")).
+		Run(func(args mock.Arguments) { assembled = args.Get(6).(repository.OutboxEvent) }).
+		Return(true, int64(1), nil).Once()
+
+	assert.NoError(t, svc.HandleOrderAssemblyCompleted(ctx, event))
+
+	assert.Equal(t, "assembled-order-123-evt-3", assembled.EventID)
+	assert.Equal(t, "order.assembled", assembled.EventType)
+	assert.Equal(t, "order.assembled", assembled.Topic)
+	assert.Equal(t, "order-123", assembled.AggregateID)
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(assembled.Payload, &payload))
+	assert.Equal(t, "order.assembled", payload["event_type"])
+	assert.Equal(t, "order-123", payload["order_id"])
+	assert.Equal(t, "user-456", payload["user_id"])
+	assert.Equal(t, "assembled", payload["status"])
+	assert.Equal(t, "evt-3", payload["assembly_event_id"])
+	assert.Equal(t, "2026-01-10T12:00:00Z", payload["assembly_completed_at"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"product_id": "product-1", "status": "cancelled"},
+	}, payload["items"])
+}
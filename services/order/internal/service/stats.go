@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// MaxStatsWindows - сколько окон можно запросить в одной статистике заказов
+const MaxStatsWindows = 10
+
+// StatsWindowAll - окно статистики за всё время
+const StatsWindowAll = "all"
+
+// ErrStatsUserIDRequired возвращается, если не передан пользователь статистики
+var ErrStatsUserIDRequired = errors.New("user_id is required")
+
+// ErrInvalidStatsWindow возвращается, если окно статистики не разбирается или окон слишком много
+var ErrInvalidStatsWindow = errors.New("invalid stats window")
+
+// StatsWindow - окно статистики: заказы, созданные за последние Duration
+type StatsWindow struct {
+	Name     string        // как задано: 30d, 72h, all
+	Duration time.Duration // 0 - за всё время
+}
+
+// ParseStatsWindows разбирает окна статистики: Go duration (72h), дни (30d) или all
+// Пустой список - ошибка: окна по умолчанию подставляет вызывающий
+func ParseStatsWindows(names []string) ([]StatsWindow, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: at least one window is required", ErrInvalidStatsWindow)
+	}
+	if len(names) > MaxStatsWindows {
+		return nil, fmt.Errorf("%w: at most %d windows", ErrInvalidStatsWindow, MaxStatsWindows)
+	}
+
+	windows := make([]StatsWindow, 0, len(names))
+	for _, raw := range names {
+		name := strings.TrimSpace(raw)
+		window, err := parseStatsWindow(name)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+func parseStatsWindow(name string) (StatsWindow, error) {
+	if name == StatsWindowAll {
+		return StatsWindow{Name: name}, nil
+	}
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(name, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return StatsWindow{}, fmt.Errorf("%w: %q", ErrInvalidStatsWindow, name)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(name)
+		if err != nil {
+			return StatsWindow{}, fmt.Errorf("%w: %q", ErrInvalidStatsWindow, name)
+		}
+		d = parsed
+	}
+	if d <= 0 {
+		return StatsWindow{}, fmt.Errorf("%w: %q must be positive", ErrInvalidStatsWindow, name)
+	}
+	return StatsWindow{Name: name, Duration: d}, nil
+}
+
+// OrderStatsService считает агрегаты по заказам пользователя для страниц поддержки и программы лояльности
+type OrderStatsService struct {
+	logger         *zap.Logger
+	statsRepo      repository.OrderStatsRepository
+	defaultWindows []StatsWindow
+	now            func() time.Time
+}
+
+// NewOrderStatsService создаёт новый экземпляр OrderStatsService
+// defaultWindows используются, если в запросе окна не заданы (ORDER_STATS_WINDOWS)
+func NewOrderStatsService(logger *zap.Logger, statsRepo repository.OrderStatsRepository, defaultWindows []StatsWindow) *OrderStatsService {
+	return &OrderStatsService{
+		logger:         logger,
+		statsRepo:      statsRepo,
+		defaultWindows: defaultWindows,
+		now:            time.Now,
+	}
+}
+
+// GetUserOrderStatsInput содержит входные данные для статистики заказов пользователя
+type GetUserOrderStatsInput struct {
+	UserID  string
+	Windows []string // пусто - окна по умолчанию
+}
+
+// StatusStats - заказы одного статуса и валюты в окне
+type StatusStats struct {
+	Status      string
+	Currency    string
+	Count       int64
+	TotalAmount int64 // в минимальных единицах Currency
+}
+
+// WindowStats - статистика заказов пользователя за одно окно
+type WindowStats struct {
+	Window      string
+	Since       time.Time // начало окна; нулевое - за всё время
+	OrdersCount int64
+	ByStatus    []StatusStats // по статусу, затем по валюте
+}
+
+// UserOrderStats - статистика заказов пользователя по окнам в порядке запроса
+type UserOrderStats struct {
+	UserID  string
+	Windows []WindowStats
+}
+
+// GetUserOrderStats возвращает количество и суммы заказов пользователя по статусам для каждого окна
+// Суммы разных валют не складываются: у каждой пары статус/валюта своя строка
+func (s *OrderStatsService) GetUserOrderStats(ctx context.Context, input GetUserOrderStatsInput) (*UserOrderStats, error) {
+	if input.UserID == "" {
+		return nil, ErrStatsUserIDRequired
+	}
+
+	windows := s.defaultWindows
+	if len(input.Windows) > 0 {
+		parsed, err := ParseStatsWindows(input.Windows)
+		if err != nil {
+			return nil, err
+		}
+		windows = parsed
+	}
+
+	now := s.now().UTC()
+	since := make([]time.Time, len(windows))
+	result := &UserOrderStats{UserID: input.UserID, Windows: make([]WindowStats, len(windows))}
+	for i, w := range windows {
+		if w.Duration > 0 {
+			since[i] = now.Add(-w.Duration)
+		}
+		result.Windows[i] = WindowStats{Window: w.Name, Since: since[i], ByStatus: []StatusStats{}}
+	}
+
+	rows, err := s.statsRepo.GetUserOrderStats(ctx, input.UserID, since)
+	if err != nil {
+		platformobservability.L(ctx, s.logger).Error("get user order stats failed",
+			zap.String("user_id", input.UserID), zap.Error(err))
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Window < 0 || row.Window >= len(windows) {
+			continue
+		}
+		w := &result.Windows[row.Window]
+		w.OrdersCount += row.Count
+		w.ByStatus = append(w.ByStatus, StatusStats{
+			Status:      row.Status,
+			Currency:    row.Currency,
+			Count:       row.Count,
+			TotalAmount: row.TotalAmount,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+)
+
+func TestParseStatsWindows(t *testing.T) {
+	windows, err := ParseStatsWindows([]string{"7d", " 72h", "all"})
+	require.NoError(t, err)
+	require.Equal(t, []StatsWindow{
+		{Name: "7d", Duration: 7 * 24 * time.Hour},
+		{Name: "72h", Duration: 72 * time.Hour},
+		{Name: "all"},
+	}, windows)
+
+	for _, names := range [][]string{
+		nil,
+		{"week"},
+		{"0d"},
+		{"-1h"},
+		{"1d", "2d", "3d", "4d", "5d", "6d", "7d", "8d", "9d", "10d", "11d"},
+	} {
+		_, err := ParseStatsWindows(names)
+		require.ErrorIs(t, err, ErrInvalidStatsWindow, names)
+	}
+}
+
+func TestOrderStatsService_GetUserOrderStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	defaults := []StatsWindow{{Name: "30d", Duration: 30 * 24 * time.Hour}, {Name: "all"}}
+
+	newService := func(t *testing.T) (*OrderStatsService, *repoMocks.OrderStatsRepository) {
+		repo := repoMocks.NewOrderStatsRepository(t)
+		s := NewOrderStatsService(zap.NewNop(), repo, defaults)
+		s.now = func() time.Time { return now }
+		return s, repo
+	}
+
+	t.Run("default windows", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetUserOrderStats", ctx, "user-1", []time.Time{now.Add(-30 * 24 * time.Hour), {}}).
+			Return([]repository.OrderStatsRow{
+				{Window: 0, Status: "paid", Currency: "RUB", Count: 2, TotalAmount: 3000},
+				{Window: 1, Status: "paid", Currency: "RUB", Count: 5, TotalAmount: 9000},
+				{Window: 1, Status: "payment_declined", Currency: "RUB", Count: 1, TotalAmount: 700},
+			}, nil).Once()
+
+		stats, err := s.GetUserOrderStats(ctx, GetUserOrderStatsInput{UserID: "user-1"})
+		require.NoError(t, err)
+		require.Equal(t, &UserOrderStats{
+			UserID: "user-1",
+			Windows: []WindowStats{
+				{
+					Window: "30d", Since: now.Add(-30 * 24 * time.Hour), OrdersCount: 2,
+					ByStatus: []StatusStats{{Status: "paid", Currency: "RUB", Count: 2, TotalAmount: 3000}},
+				},
+				{
+					Window: "all", OrdersCount: 6,
+					ByStatus: []StatusStats{
+						{Status: "paid", Currency: "RUB", Count: 5, TotalAmount: 9000},
+						{Status: "payment_declined", Currency: "RUB", Count: 1, TotalAmount: 700},
+					},
+				},
+			},
+		}, stats)
+	})
+
+	t.Run("requested windows without orders", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetUserOrderStats", ctx, "user-1", []time.Time{now.Add(-24 * time.Hour)}).Return(nil, nil).Once()
+
+		stats, err := s.GetUserOrderStats(ctx, GetUserOrderStatsInput{UserID: "user-1", Windows: []string{"1d"}})
+		require.NoError(t, err)
+		require.Equal(t, []WindowStats{{Window: "1d", Since: now.Add(-24 * time.Hour), ByStatus: []StatusStats{}}}, stats.Windows)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		s, _ := newService(t)
+
+		_, err := s.GetUserOrderStats(ctx, GetUserOrderStatsInput{})
+		require.ErrorIs(t, err, ErrStatsUserIDRequired)
+
+		_, err = s.GetUserOrderStats(ctx, GetUserOrderStatsInput{UserID: "user-1", Windows: []string{"soon"}})
+		require.ErrorIs(t, err, ErrInvalidStatsWindow)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.On("GetUserOrderStats", ctx, "user-1", []time.Time{now.Add(-30 * 24 * time.Hour), {}}).
+			Return(nil, errors.New("db down")).Once()
+
+		_, err := s.GetUserOrderStats(ctx, GetUserOrderStatsInput{UserID: "user-1"})
+		require.Error(t, err)
+	})
+}
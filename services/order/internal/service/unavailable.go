@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Зависимости Order Service (DependencyUnavailableError.Dependency)
+const (
+	DependencyInventory = "inventory"
+	DependencyPayment   = "payment"
+)
+
+// DependencyUnavailableError возвращается клиентами Inventory и Payment, когда зависимость недоступна:
+// соединение не установлено, дедлайн истёк или circuit breaker открыт. HTTP отвечает 503 с Retry-After
+type DependencyUnavailableError struct {
+	Dependency  string        // DependencyInventory или DependencyPayment
+	RetryAfter  time.Duration // когда имеет смысл повторить: до пробного вызова breaker или следующий шаг backoff
+	BreakerOpen bool          // вызов не выполнялся: breaker открыт
+	Err         error
+}
+
+func (e *DependencyUnavailableError) Error() string {
+	return fmt.Sprintf("%s is unavailable: %v", e.Dependency, e.Err)
+}
+
+func (e *DependencyUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// retryUnavailable выполняет идемпотентный шаг call и, если зависимость оказалась недоступна,
+// повторяет его один раз через retryDelay со случайным разбросом [retryDelay/2, retryDelay*3/2).
+// Разброс не даёт запросам, упавшим одновременно, повторить вызов тоже одновременно.
+// retryDelay <= 0 - повтор выключен. При открытом breaker повтор бесполезен и не делается
+func retryUnavailable(ctx context.Context, logger *zap.Logger, retryDelay time.Duration, step string, call func() error) error {
+	err := call()
+	var unavailable *DependencyUnavailableError
+	if err == nil || retryDelay <= 0 || !errors.As(err, &unavailable) || unavailable.BreakerOpen {
+		return err
+	}
+
+	delay := retryDelay/2 + time.Duration(rand.Int64N(int64(retryDelay)))
+	logger.Warn("dependency unavailable, retrying step",
+		zap.String("step", step),
+		zap.String("dependency", unavailable.Dependency),
+		zap.Duration("delay", delay),
+		zap.Error(err),
+	)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return err
+	case <-timer.C:
+	}
+	return call()
+}
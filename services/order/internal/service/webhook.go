@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	"github.com/shestoi/GoBigTech/services/order/internal/webhook"
+)
+
+// MaxWebhooksPerUser - сколько webhook'ов может зарегистрировать один пользователь
+const MaxWebhooksPerUser = 10
+
+// webhookSecretBytes - длина секрета подписи callback'ов (до hex-кодирования)
+const webhookSecretBytes = 32
+
+// ErrWebhookUserIDRequired возвращается, если не передан пользователь webhook'а
+var ErrWebhookUserIDRequired = errors.New("user_id is required")
+
+// ErrInvalidWebhookURL возвращается, если URL webhook'а не абсолютный http(s) URL
+// или его хост не разрешается в публичные адреса (см. webhook.CheckAddr)
+var ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http(s) url of a public host")
+
+// ErrTooManyWebhooks возвращается, если у пользователя уже MaxWebhooksPerUser webhook'ов
+var ErrTooManyWebhooks = errors.New("too many webhooks")
+
+// WebhookService управляет webhook'ами пользователей (callback'и об изменении статуса заказа)
+// Сами callback'и ставятся в очередь репозиторием при смене статуса и отправляются webhook.Dispatcher
+type WebhookService struct {
+	logger      *zap.Logger
+	webhookRepo repository.WebhookRepository
+	resolver    webhook.Resolver
+}
+
+// NewWebhookService создаёт новый экземпляр WebhookService
+func NewWebhookService(logger *zap.Logger, webhookRepo repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		logger:      logger,
+		webhookRepo: webhookRepo,
+		resolver:    net.DefaultResolver,
+	}
+}
+
+// RegisterWebhookInput содержит входные данные для регистрации webhook'а
+type RegisterWebhookInput struct {
+	UserID string // пользователь проверенной сессии, не поле запроса
+	URL    string
+}
+
+// RegisterWebhook регистрирует URL пользователя для callback'ов и генерирует секрет подписи
+// Секрет возвращается только здесь: дальше он нужен получателю для проверки X-Webhook-Signature
+func (s *WebhookService) RegisterWebhook(ctx context.Context, input RegisterWebhookInput) (*repository.Webhook, error) {
+	if input.UserID == "" {
+		return nil, ErrWebhookUserIDRequired
+	}
+	rawURL := strings.TrimSpace(input.URL)
+	if err := s.validateWebhookURL(ctx, rawURL); err != nil {
+		return nil, err
+	}
+
+	logger := platformobservability.L(ctx, s.logger).With(zap.String("user_id", input.UserID))
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	created := repository.Webhook{
+		ID:        uuid.New().String(),
+		UserID:    input.UserID,
+		URL:       rawURL,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	// Лимит проверяется репозиторием в одной транзакции со вставкой: параллельные регистрации его не превысят
+	if err := s.webhookRepo.CreateWebhook(ctx, created, MaxWebhooksPerUser); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrWebhookExists):
+			return nil, err
+		case errors.Is(err, repository.ErrWebhookLimitReached):
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyWebhooks, MaxWebhooksPerUser)
+		}
+		logger.Error("failed to create webhook", zap.Error(err))
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	logger.Info("webhook registered", zap.String("webhook_id", created.ID))
+	return &created, nil
+}
+
+// ListWebhooks возвращает webhook'и пользователя (секреты не очищаются - это делает HTTP слой)
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID string) ([]repository.Webhook, error) {
+	if userID == "" {
+		return nil, ErrWebhookUserIDRequired
+	}
+	webhooks, err := s.webhookRepo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет webhook пользователя; недоставленные callback'и удаляются вместе с ним
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		return ErrWebhookUserIDRequired
+	}
+	if err := s.webhookRepo.DeleteWebhook(ctx, userID, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	platformobservability.L(ctx, s.logger).Info("webhook deleted",
+		zap.String("user_id", userID),
+		zap.String("webhook_id", id),
+	)
+	return nil
+}
+
+// validateWebhookURL проверяет, что URL абсолютный, со схемой http/https, а хост разрешается только в публичные адреса
+// Dispatcher повторяет проверку адреса при каждом соединении: DNS мог измениться после регистрации
+func (s *WebhookService) validateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if err := webhook.CheckHost(ctx, s.resolver, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	return nil
+}
+
+// newWebhookSecret генерирует случайный секрет подписи в hex
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
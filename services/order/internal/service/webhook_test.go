@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+)
+
+// fakeResolver - DNS для тестов: host -> адреса, неизвестный host - ошибка
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+var testResolver = fakeResolver{
+	"example.com":         {netip.MustParseAddr("93.184.215.14")},
+	"internal.example":    {netip.MustParseAddr("10.0.0.5")},
+	"split.example":       {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("127.0.0.1")},
+	"metadata.example":    {netip.MustParseAddr("169.254.169.254")},
+	"mapped-lo.example":   {netip.MustParseAddr("::ffff:127.0.0.1")},
+	"ipv6-public.example": {netip.MustParseAddr("2606:2800:21f:cb07:6820:80da:af6b:8b2c")},
+}
+
+func newTestWebhookService(repo repository.WebhookRepository) *WebhookService {
+	svc := NewWebhookService(zap.NewNop(), repo)
+	svc.resolver = testResolver
+	return svc
+}
+
+func TestWebhookService_RegisterWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("registers webhook with secret", func(t *testing.T) {
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("CreateWebhook", ctx, mock.MatchedBy(func(w repository.Webhook) bool {
+			return w.UserID == "user-1" && w.URL == "https://example.com/hook" && w.ID != "" && len(w.Secret) == 2*webhookSecretBytes
+		}), MaxWebhooksPerUser).Return(nil).Once()
+
+		svc := newTestWebhookService(repo)
+		webhook, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: " https://example.com/hook "})
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/hook", webhook.URL)
+		require.NotEmpty(t, webhook.Secret)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		svc := newTestWebhookService(repoMocks.NewWebhookRepository(t))
+		for _, rawURL := range []string{"", "example.com/hook", "ftp://example.com/hook", "https://", "https://unknown.example/hook"} {
+			_, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: rawURL})
+			require.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+		}
+	})
+
+	t.Run("internal addresses are rejected", func(t *testing.T) {
+		svc := newTestWebhookService(repoMocks.NewWebhookRepository(t))
+		for _, rawURL := range []string{
+			"http://localhost:8080/hook",
+			"http://127.0.0.1/hook",
+			"http://[::1]/hook",
+			"http://10.1.2.3/hook",
+			"http://172.16.0.1/hook",
+			"http://192.168.1.1/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"http://0.0.0.0/hook",
+			"http://100.64.0.1/hook",
+			"http://[fd00::1]/hook",
+			"https://internal.example/hook",
+			"https://metadata.example/hook",
+			"https://mapped-lo.example/hook",
+			"https://split.example/hook", // хотя бы один внутренний адрес
+		} {
+			_, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: rawURL})
+			require.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+		}
+	})
+
+	t.Run("public ipv6 host is accepted", func(t *testing.T) {
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("CreateWebhook", ctx, mock.Anything, MaxWebhooksPerUser).Return(nil).Once()
+
+		_, err := newTestWebhookService(repo).RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: "https://ipv6-public.example/hook"})
+		require.NoError(t, err)
+	})
+
+	t.Run("user_id required", func(t *testing.T) {
+		svc := newTestWebhookService(repoMocks.NewWebhookRepository(t))
+		_, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{URL: "https://example.com/hook"})
+		require.ErrorIs(t, err, ErrWebhookUserIDRequired)
+	})
+
+	t.Run("too many webhooks", func(t *testing.T) {
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("CreateWebhook", ctx, mock.Anything, MaxWebhooksPerUser).Return(repository.ErrWebhookLimitReached).Once()
+
+		svc := newTestWebhookService(repo)
+		_, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: "https://example.com/hook"})
+		require.ErrorIs(t, err, ErrTooManyWebhooks)
+	})
+
+	t.Run("duplicate url", func(t *testing.T) {
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("CreateWebhook", ctx, mock.Anything, MaxWebhooksPerUser).Return(repository.ErrWebhookExists).Once()
+
+		svc := newTestWebhookService(repo)
+		_, err := svc.RegisterWebhook(ctx, RegisterWebhookInput{UserID: "user-1", URL: "https://example.com/hook"})
+		require.ErrorIs(t, err, repository.ErrWebhookExists)
+	})
+}
+
+func TestWebhookService_DeleteWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	repo := repoMocks.NewWebhookRepository(t)
+	repo.On("DeleteWebhook", ctx, "user-1", "wh-1").Return(repository.ErrWebhookNotFound).Once()
+
+	svc := NewWebhookService(zap.NewNop(), repo)
+	require.ErrorIs(t, svc.DeleteWebhook(ctx, "user-1", "wh-1"), repository.ErrWebhookNotFound)
+}
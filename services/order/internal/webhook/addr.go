@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed возвращается для адресов, на которые Order не отправляет callback'и:
+// loopback, частные сети, link-local (в том числе metadata облака 169.254.169.254), multicast и unspecified
+var ErrAddressNotAllowed = errors.New("address is not publicly routable")
+
+// sharedAddressSpace - 100.64.0.0/10 (CGNAT), часто используется для внутренних сетей кластера
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckAddr возвращает ErrAddressNotAllowed, если адрес внутри инфраструктуры, а не в интернете
+// Иначе callback пользователя стал бы запросом Order к внутренним сервисам (SSRF)
+func CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr) ||
+		(addr.Is4() && addr.As4()[0] == 0) { // 0.0.0.0/8
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+	}
+	return nil
+}
+
+// NewHTTPClient создаёт HTTP клиент для callback'ов
+// Адрес проверяется CheckAddr при каждом соединении, уже после DNS: проверка при регистрации не спасает,
+// если DNS имени позже начнёт указывать во внутреннюю сеть. Redirect'ы не выполняются (ответ 3xx - неудачная попытка),
+// прокси из окружения не используется: иначе проверялся бы адрес прокси, а не получателя
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+			}
+			return CheckAddr(addrPort.Addr())
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Resolver разрешает имя хоста webhook'а в адреса; *net.Resolver реализует интерфейс
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// CheckHost разрешает host и возвращает ErrAddressNotAllowed, если хотя бы один его адрес не публичный
+// IP-литерал проверяется без DNS
+func CheckHost(ctx context.Context, resolver Resolver, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return CheckAddr(addr)
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("resolve %s: no addresses", host)
+	}
+	for _, addr := range addrs {
+		if err := CheckAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
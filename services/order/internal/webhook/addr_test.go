@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddr(t *testing.T) {
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.0.0.1", "172.16.5.4", "192.168.0.10", "fd12::1",
+		"169.254.169.254", "fe80::1", "0.0.0.0", "0.1.2.3", "::", "100.64.0.1", "224.0.0.1", "::ffff:10.0.0.1",
+	} {
+		require.ErrorIs(t, CheckAddr(netip.MustParseAddr(addr)), ErrAddressNotAllowed, addr)
+	}
+	for _, addr := range []string{"93.184.215.14", "8.8.8.8", "100.128.0.1", "2606:4700::1111"} {
+		require.NoError(t, CheckAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+// staticResolver - DNS с одним ответом на любой host
+type staticResolver []netip.Addr
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return r, nil
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	public := netip.MustParseAddr("93.184.215.14")
+	private := netip.MustParseAddr("10.0.0.1")
+
+	require.NoError(t, CheckHost(ctx, staticResolver{public}, "example.com"))
+	require.ErrorIs(t, CheckHost(ctx, staticResolver{public, private}, "example.com"), ErrAddressNotAllowed)
+	require.Error(t, CheckHost(ctx, staticResolver{}, "example.com"))
+	// IP-литерал не идёт в DNS
+	require.ErrorIs(t, CheckHost(ctx, staticResolver{public}, "127.0.0.1"), ErrAddressNotAllowed)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("internal address is refused at dial time", func(t *testing.T) {
+		called := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer srv.Close()
+
+		_, err := NewHTTPClient(time.Second).Post(srv.URL, "application/json", nil)
+
+		require.ErrorIs(t, err, ErrAddressNotAllowed)
+		require.False(t, called)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		client := NewHTTPClient(time.Second)
+		req := httptest.NewRequest(http.MethodPost, "http://169.254.169.254/latest/meta-data", nil)
+
+		require.ErrorIs(t, client.CheckRedirect(req, []*http.Request{req}), http.ErrUseLastResponse)
+	})
+}
@@ -0,0 +1,210 @@
+// Package webhook доставляет callback'и об изменении статуса заказа на URL, зарегистрированные пользователями.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+)
+
+// Заголовки callback'а
+const (
+	HeaderWebhookID = "X-Webhook-Id"
+	HeaderEventID   = "X-Webhook-Event-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxBackoff ограничивает экспоненциальную задержку между попытками
+const maxBackoff = 6 * time.Hour
+
+// Payload - тело callback'а (JSON)
+type Payload struct {
+	EventID    string `json:"event_id"`
+	EventType  string `json:"event_type"`
+	OrderID    string `json:"order_id"`
+	UserID     string `json:"user_id"`
+	Status     string `json:"status"`
+	OccurredAt string `json:"occurred_at"` // RFC3339, UTC
+}
+
+// Sign вычисляет значение X-Webhook-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+// Получатель повторяет вычисление со своим секретом и сравнивает результат (и проверяет свежесть timestamp)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher периодически отправляет pending callback'и из order_webhook_deliveries
+// Ответ 2xx - доставлено; иначе повтор с экспоненциальной задержкой, после maxAttempts попыток - failed
+type Dispatcher struct {
+	logger      *zap.Logger
+	repo        repository.WebhookRepository
+	client      *http.Client
+	batchSize   int
+	interval    time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+}
+
+// NewDispatcher создаёт новый dispatcher callback'ов
+func NewDispatcher(
+	logger *zap.Logger,
+	repo repository.WebhookRepository,
+	client *http.Client,
+	batchSize int,
+	interval time.Duration,
+	maxAttempts int,
+	backoffBase time.Duration, // задержка перед второй попыткой, дальше удваивается
+) *Dispatcher {
+	return &Dispatcher{
+		logger:      logger,
+		repo:        repo,
+		client:      client,
+		batchSize:   batchSize,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+	}
+}
+
+// Start запускает dispatcher и работает до отмены контекста
+func (d *Dispatcher) Start(ctx context.Context) error {
+	d.logger.Info("starting webhook dispatcher",
+		zap.Int("batch_size", d.batchSize),
+		zap.Duration("interval", d.interval),
+		zap.Int("max_attempts", d.maxAttempts),
+		zap.Duration("backoff_base", d.backoffBase),
+	)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.ProcessBatch(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("failed to process webhook deliveries", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("webhook dispatcher context cancelled, stopping")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch отправляет callback'и, время попытки которых наступило
+func (d *Dispatcher) ProcessBatch(ctx context.Context) error {
+	now := time.Now().UTC()
+	deliveries, err := d.repo.GetDueWebhookDeliveries(ctx, now, d.batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.processDelivery(ctx, delivery, now)
+	}
+	return nil
+}
+
+// processDelivery выполняет одну попытку доставки и сохраняет её результат
+func (d *Dispatcher) processDelivery(ctx context.Context, delivery repository.WebhookDelivery, now time.Time) {
+	logger := d.logger.With(
+		zap.String("event_id", delivery.EventID),
+		zap.String("webhook_id", delivery.WebhookID),
+		zap.String("order_id", delivery.OrderID),
+		zap.Int("attempt", delivery.Attempts+1),
+	)
+
+	sendErr := d.send(ctx, delivery, now)
+	if sendErr == nil {
+		if err := d.repo.MarkWebhookDelivered(ctx, delivery.EventID, delivery.WebhookID); err != nil {
+			logger.Error("failed to mark webhook delivered", zap.Error(err))
+			return
+		}
+		logger.Info("webhook delivered")
+		return
+	}
+	if ctx.Err() != nil {
+		return // остановка сервиса - попытка не считается
+	}
+
+	final := delivery.Attempts+1 >= d.maxAttempts
+	nextAttemptAt := now.Add(d.backoff(delivery.Attempts))
+	if err := d.repo.MarkWebhookAttemptFailed(ctx, delivery.EventID, delivery.WebhookID, sendErr.Error(), nextAttemptAt, final); err != nil {
+		logger.Error("failed to record webhook attempt", zap.Error(err))
+		return
+	}
+	if final {
+		logger.Error("webhook delivery failed, attempts exhausted", zap.Error(sendErr))
+		return
+	}
+	logger.Warn("webhook delivery failed, will retry", zap.Error(sendErr), zap.Time("next_attempt_at", nextAttemptAt))
+}
+
+// backoff возвращает задержку после неудачной попытки: backoffBase * 2^attempts, но не больше maxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.backoffBase
+	for i := 0; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// send отправляет подписанный callback; ошибка, если запрос не выполнен или ответ не 2xx
+func (d *Dispatcher) send(ctx context.Context, delivery repository.WebhookDelivery, now time.Time) error {
+	body, err := json.Marshal(Payload{
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		OrderID:    delivery.OrderID,
+		UserID:     delivery.UserID,
+		Status:     delivery.OrderStatus,
+		OccurredAt: delivery.OccurredAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, delivery.WebhookID)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
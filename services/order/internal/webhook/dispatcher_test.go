@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/order/internal/repository"
+	repoMocks "github.com/shestoi/GoBigTech/services/order/internal/repository/mocks"
+)
+
+func TestDispatcher_ProcessBatch(t *testing.T) {
+	ctx := context.Background()
+	occurredAt := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	newDelivery := func(url string, attempts int) repository.WebhookDelivery {
+		return repository.WebhookDelivery{
+			EventID:     "evt-1",
+			EventType:   "order.assembly.completed",
+			WebhookID:   "wh-1",
+			URL:         url,
+			Secret:      "s3cret",
+			OrderID:     "order-1",
+			UserID:      "user-1",
+			OrderStatus: "assembled",
+			OccurredAt:  occurredAt,
+			Attempts:    attempts,
+		}
+	}
+
+	t.Run("signed callback delivered", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, "wh-1", r.Header.Get(HeaderWebhookID))
+			require.Equal(t, "evt-1", r.Header.Get(HeaderEventID))
+			require.Equal(t, Sign("s3cret", r.Header.Get(HeaderTimestamp), body), r.Header.Get(HeaderSignature))
+
+			var payload Payload
+			require.NoError(t, json.Unmarshal(body, &payload))
+			require.Equal(t, Payload{
+				EventID:    "evt-1",
+				EventType:  "order.assembly.completed",
+				OrderID:    "order-1",
+				UserID:     "user-1",
+				Status:     "assembled",
+				OccurredAt: "2026-01-10T12:00:00Z",
+			}, payload)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("GetDueWebhookDeliveries", ctx, mock.AnythingOfType("time.Time"), 10).
+			Return([]repository.WebhookDelivery{newDelivery(srv.URL, 0)}, nil).Once()
+		repo.On("MarkWebhookDelivered", ctx, "evt-1", "wh-1").Return(nil).Once()
+
+		d := NewDispatcher(zap.NewNop(), repo, srv.Client(), 10, time.Second, 3, time.Minute)
+		require.NoError(t, d.ProcessBatch(ctx))
+	})
+
+	t.Run("failed attempt is retried with backoff", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("GetDueWebhookDeliveries", ctx, mock.AnythingOfType("time.Time"), 10).
+			Return([]repository.WebhookDelivery{newDelivery(srv.URL, 1)}, nil).Once()
+		start := time.Now()
+		repo.On("MarkWebhookAttemptFailed", ctx, "evt-1", "wh-1", "unexpected status 500",
+			mock.MatchedBy(func(next time.Time) bool {
+				// Вторая неудачная попытка: backoffBase * 2
+				return !next.Before(start.Add(2*time.Minute - time.Second))
+			}), false).Return(nil).Once()
+
+		d := NewDispatcher(zap.NewNop(), repo, srv.Client(), 10, time.Second, 3, time.Minute)
+		require.NoError(t, d.ProcessBatch(ctx))
+	})
+
+	t.Run("last attempt marks delivery failed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		repo := repoMocks.NewWebhookRepository(t)
+		repo.On("GetDueWebhookDeliveries", ctx, mock.AnythingOfType("time.Time"), 10).
+			Return([]repository.WebhookDelivery{newDelivery(srv.URL, 2)}, nil).Once()
+		repo.On("MarkWebhookAttemptFailed", ctx, "evt-1", "wh-1", "unexpected status 502", mock.Anything, true).
+			Return(nil).Once()
+
+		d := NewDispatcher(zap.NewNop(), repo, srv.Client(), 10, time.Second, 3, time.Minute)
+		require.NoError(t, d.ProcessBatch(ctx))
+	})
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		Sign("secret", "1700000000", []byte("{}")))
+}
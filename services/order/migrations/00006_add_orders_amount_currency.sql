@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS total_amount BIGINT NOT NULL DEFAULT 0, -- в минимальных единицах валюты
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'RUB';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS total_amount;
+-- +goose StatementEnd
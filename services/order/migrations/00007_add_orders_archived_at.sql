@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ; -- NULL - заказ не архивирован
+
+-- Большинство запросов читают только неархивные заказы
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_active ON orders(user_id, created_at DESC) WHERE archived_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_user_id_active;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'reserved'; -- reserved, assembled, shipped, cancelled
+
+ALTER TABLE order_items
+    ADD CONSTRAINT order_items_status_check CHECK (status IN ('reserved', 'assembled', 'shipped', 'cancelled'));
+
+-- Заказы, собранные до появления статусов позиций, собраны целиком
+UPDATE order_items SET status = 'assembled'
+FROM orders
+WHERE orders.id = order_items.order_id AND orders.status = 'assembled';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_items
+    DROP CONSTRAINT IF EXISTS order_items_status_check;
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
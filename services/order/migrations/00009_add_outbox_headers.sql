@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Заголовки события (traceparent, tracestate, baggage, x-request-id): OutboxDispatcher передаёт их в Kafka
+ALTER TABLE order_outbox_events
+    ADD COLUMN IF NOT EXISTS headers JSONB NOT NULL DEFAULT '{}'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_outbox_events
+    DROP COLUMN IF EXISTS headers;
+-- +goose StatementEnd
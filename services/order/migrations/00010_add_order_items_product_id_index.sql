@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Поиск заказов по товару (GET /orders?product_id=...): первичный ключ (order_id, product_id) не помогает искать по product_id
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_items_product_id;
+-- +goose StatementEnd
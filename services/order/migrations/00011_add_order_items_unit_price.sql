@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS unit_price BIGINT NOT NULL DEFAULT 0, -- цена единицы на момент покупки, в минимальных единицах валюты
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT '';
+
+-- До снимка цен все товары стоили одинаково, поэтому цена единицы восстанавливается из суммы заказа
+UPDATE order_items SET
+    unit_price = totals.total_amount / totals.quantity,
+    currency = totals.currency
+FROM (
+    SELECT o.id, o.total_amount, o.currency, SUM(i.quantity) AS quantity
+    FROM orders o
+    JOIN order_items i ON i.order_id = o.id
+    GROUP BY o.id, o.total_amount, o.currency
+) AS totals
+WHERE totals.id = order_items.order_id AND totals.quantity > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS unit_price;
+-- +goose StatementEnd
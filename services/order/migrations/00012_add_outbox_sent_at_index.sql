@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сверка outbox с Kafka читает недавно отправленные события (status = 'sent' AND sent_at >= ...)
+CREATE INDEX IF NOT EXISTS idx_order_outbox_events_sent_at ON order_outbox_events(sent_at) WHERE status = 'sent';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_outbox_events_sent_at;
+-- +goose StatementEnd
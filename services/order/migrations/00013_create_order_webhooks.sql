@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Webhook'и пользователей для callback'ов об изменении статуса заказа
+CREATE TABLE IF NOT EXISTS order_webhooks (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL, -- ключ HMAC-SHA256 подписи
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, url)
+);
+
+-- Очередь доставки: строки создаются в одной транзакции со сменой статуса заказа
+CREATE TABLE IF NOT EXISTS order_webhook_deliveries (
+    event_id TEXT NOT NULL,
+    webhook_id TEXT NOT NULL REFERENCES order_webhooks(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    order_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    order_status TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    PRIMARY KEY (event_id, webhook_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_webhook_deliveries_due ON order_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_webhook_deliveries_due;
+DROP TABLE IF EXISTS order_webhook_deliveries;
+DROP TABLE IF EXISTS order_webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Статистика заказов пользователя (GET /users/{id}/order-stats) считает и архивные заказы, поэтому частичный
+-- idx_orders_user_id_active не подходит; INCLUDE даёт index-only scan для COUNT/SUM по статусу и валюте
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at_stats
+    ON orders(user_id, created_at) INCLUDE (status, currency, total_amount);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_user_id_created_at_stats;
+-- +goose StatementEnd
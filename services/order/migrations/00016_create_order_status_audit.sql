@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал смены статуса заказа операторами (POST /admin/orders/{id}/status)
+CREATE TABLE IF NOT EXISTS order_status_audit (
+    id BIGSERIAL PRIMARY KEY,
+    order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    forced BOOLEAN NOT NULL DEFAULT FALSE, -- переход вне domain.Transition
+    operator TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    event_id TEXT NOT NULL, -- событие outbox, записанное вместе со сменой статуса
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_audit_order_id ON order_status_audit(order_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_status_audit_order_id;
+DROP TABLE IF EXISTS order_status_audit;
+-- +goose StatementEnd
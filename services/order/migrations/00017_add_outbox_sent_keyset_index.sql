@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сверка outbox с Kafka читает sent события страницами по (sent_at, event_id)
+CREATE INDEX IF NOT EXISTS idx_order_outbox_events_sent_at_event_id ON order_outbox_events(sent_at, event_id) WHERE status = 'sent';
+DROP INDEX IF EXISTS idx_order_outbox_events_sent_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_order_outbox_events_sent_at ON order_outbox_events(sent_at) WHERE status = 'sent';
+DROP INDEX IF EXISTS idx_order_outbox_events_sent_at_event_id;
+-- +goose StatementEnd
@@ -2,7 +2,15 @@ package grpcapi
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
 	"github.com/shestoi/GoBigTech/services/payment/internal/service"
 	paymentpb "github.com/shestoi/GoBigTech/services/payment/v1"
 )
@@ -11,37 +19,506 @@ import (
 // Зависит от service слоя, но не знает о деталях реализации (repository, БД и т.д.)
 type Handler struct {
 	paymentpb.UnimplementedPaymentServiceServer
-	paymentService *service.PaymentService
+	paymentService      *service.PaymentService
+	subscriptionService *service.SubscriptionService
+	refundService       *service.RefundService
+	walletService       *service.WalletService
+	methodService       *service.PaymentMethodService
 }
 
 // NewHandler создаёт новый gRPC handler
-func NewHandler(paymentService *service.PaymentService) *Handler {
+func NewHandler(paymentService *service.PaymentService, subscriptionService *service.SubscriptionService, refundService *service.RefundService, walletService *service.WalletService, methodService *service.PaymentMethodService) *Handler {
 	return &Handler{
-		paymentService: paymentService,
+		paymentService:      paymentService,
+		subscriptionService: subscriptionService,
+		refundService:       refundService,
+		walletService:       walletService,
+		methodService:       methodService,
 	}
 }
 
 // ProcessPayment обрабатывает gRPC запрос ProcessPayment
 // Тонкий слой: преобразует protobuf типы в простые типы и вызывает service
+// Невалидная сумма, неподдерживаемая валюта, повтор в другой валюте или оплата с кошелька с manual_capture -
+// codes.InvalidArgument; кошелёк недоступен - codes.FailedPrecondition; заданы и method, и payment_method_id -
+// codes.InvalidArgument, сохранённого способа оплаты у пользователя нет - codes.NotFound
 func (h *Handler) ProcessPayment(ctx context.Context, req *paymentpb.ProcessPaymentRequest) (*paymentpb.ProcessPaymentResponse, error) {
 	// Вызываем service слой для обработки платежа
 	// gRPC handler только преобразует типы protobuf <-> простые типы
-	transactionID, success, err := h.paymentService.ProcessPayment(
-		ctx,
-		req.GetOrderId(),
-		req.GetUserId(),
-		req.GetAmount(),
-		req.GetMethod(),
-	)
+	tx, err := h.paymentService.Pay(ctx, service.PaymentInput{
+		OrderID:         req.GetOrderId(),
+		UserID:          req.GetUserId(),
+		Amount:          req.GetAmount(),
+		Currency:        req.GetCurrency(),
+		Method:          req.GetMethod(),
+		ManualCapture:   req.GetManualCapture(),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		PaymentMethodID: req.GetPaymentMethodId(),
+	})
 
 	if err != nil {
+		var declineErr *service.DeclineError
+		if errors.As(err, &declineErr) {
+			return nil, declineStatus(declineErr)
+		}
+		switch {
+		case errors.Is(err, service.ErrOrderAlreadyPaid):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		case errors.Is(err, service.ErrInvalidAmount), errors.Is(err, service.ErrUnsupportedCurrency),
+			errors.Is(err, service.ErrCurrencyMismatch), errors.Is(err, service.ErrWalletManualCapture),
+			errors.Is(err, service.ErrPaymentMethodAmbiguous):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrPaymentMethodNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrWalletDisabled):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, err
 	}
 
 	return &paymentpb.ProcessPaymentResponse{
-		Success:       success,
-		TransactionId: transactionID,
+		Success:       tx.Status == repository.StatusCaptured || tx.Status == repository.StatusRefunded,
+		TransactionId: tx.TransactionID,
+		Status:        paymentStatuses[tx.Status],
+	}, nil
+}
+
+// ConfirmPayment обрабатывает gRPC запрос ConfirmPayment
+func (h *Handler) ConfirmPayment(ctx context.Context, req *paymentpb.ConfirmPaymentRequest) (*paymentpb.ConfirmPaymentResponse, error) {
+	tx, err := h.paymentService.ConfirmPayment(ctx, req.GetOrderId(), req.GetAmount())
+	if err != nil {
+		var declineErr *service.DeclineError
+		switch {
+		case errors.As(err, &declineErr):
+			return nil, declineStatus(declineErr)
+		case errors.Is(err, service.ErrOrderIDRequired), errors.Is(err, service.ErrInvalidCaptureAmount),
+			errors.Is(err, service.ErrCaptureAmountExceeded):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.ConfirmPaymentResponse{Payment: paymentToProto(tx)}, nil
+}
+
+// VoidAuthorization обрабатывает gRPC запрос VoidAuthorization
+func (h *Handler) VoidAuthorization(ctx context.Context, req *paymentpb.VoidAuthorizationRequest) (*paymentpb.VoidAuthorizationResponse, error) {
+	tx, err := h.paymentService.VoidAuthorization(ctx, req.GetOrderId())
+	if err != nil {
+		var declineErr *service.DeclineError
+		switch {
+		case errors.As(err, &declineErr):
+			return nil, declineStatus(declineErr)
+		case errors.Is(err, service.ErrOrderIDRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.VoidAuthorizationResponse{Payment: paymentToProto(tx)}, nil
+}
+
+// declineReasons сопоставляет причины отказа service слоя с protobuf enum
+var declineReasons = map[service.DeclineReason]paymentpb.DeclineReason{
+	service.DeclineInsufficientFunds: paymentpb.DeclineReason_DECLINE_REASON_INSUFFICIENT_FUNDS,
+	service.DeclineLimitExceeded:     paymentpb.DeclineReason_DECLINE_REASON_LIMIT_EXCEEDED,
+	service.DeclineRiskDeclined:      paymentpb.DeclineReason_DECLINE_REASON_RISK_DECLINED,
+	service.DeclineProviderError:     paymentpb.DeclineReason_DECLINE_REASON_PROVIDER_ERROR,
+	service.DeclineCardDeclined:      paymentpb.DeclineReason_DECLINE_REASON_CARD_DECLINED,
+}
+
+// declineStatus преобразует отказ в gRPC статус с деталями PaymentDeclined
+// Ошибка провайдера - codes.Unavailable (можно повторить), остальные отказы - codes.FailedPrecondition
+func declineStatus(declineErr *service.DeclineError) error {
+	code := codes.FailedPrecondition
+	if declineErr.Reason == service.DeclineProviderError {
+		code = codes.Unavailable
+	}
+
+	st := status.New(code, declineErr.Error())
+	withDetails, err := st.WithDetails(&paymentpb.PaymentDeclined{
+		Reason:  declineReasons[declineErr.Reason],
+		Message: declineErr.Message,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// CreateSubscription обрабатывает gRPC запрос CreateSubscription
+// Невалидные user_id, amount, currency или interval_seconds - codes.InvalidArgument
+func (h *Handler) CreateSubscription(ctx context.Context, req *paymentpb.CreateSubscriptionRequest) (*paymentpb.CreateSubscriptionResponse, error) {
+	subscription, err := h.subscriptionService.CreateSubscription(ctx, service.SubscriptionInput{
+		UserID:   req.GetUserId(),
+		Amount:   req.GetAmount(),
+		Currency: req.GetCurrency(),
+		Method:   req.GetMethod(),
+		Interval: time.Duration(req.GetIntervalSeconds()) * time.Second,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) || errors.Is(err, service.ErrInvalidAmount) ||
+			errors.Is(err, service.ErrInvalidInterval) || errors.Is(err, service.ErrUnsupportedCurrency) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	return &paymentpb.CreateSubscriptionResponse{Subscription: subscriptionToProto(subscription)}, nil
+}
+
+// GetSubscription обрабатывает gRPC запрос GetSubscription
+func (h *Handler) GetSubscription(ctx context.Context, req *paymentpb.GetSubscriptionRequest) (*paymentpb.GetSubscriptionResponse, error) {
+	subscription, err := h.subscriptionService.GetSubscription(ctx, req.GetSubscriptionId())
+	if err != nil {
+		if errors.Is(err, repository.ErrSubscriptionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return &paymentpb.GetSubscriptionResponse{Subscription: subscriptionToProto(subscription)}, nil
+}
+
+// subscriptionToProto преобразует подписку в protobuf
+func subscriptionToProto(s repository.Subscription) *paymentpb.Subscription {
+	return &paymentpb.Subscription{
+		SubscriptionId:  s.ID,
+		UserId:          s.UserID,
+		Amount:          s.Amount,
+		Currency:        s.Currency,
+		Method:          s.Method,
+		IntervalSeconds: int64(s.Interval / time.Second),
+		Status:          s.Status,
+		Period:          s.Period,
+		NextChargeAt:    timestamppb.New(s.NextChargeAt),
+		CreatedAt:       timestamppb.New(s.CreatedAt),
+	}
+}
+
+// RefundBatch обрабатывает gRPC запрос RefundBatch
+// Пустой пакет или пакет больше лимита - codes.InvalidArgument; ошибки отдельных позиций возвращаются в их результатах
+func (h *Handler) RefundBatch(ctx context.Context, req *paymentpb.RefundBatchRequest) (*paymentpb.RefundBatchResponse, error) {
+	items := make([]service.RefundInput, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		reason := item.GetReason()
+		if reason == "" {
+			reason = req.GetReason()
+		}
+		items = append(items, service.RefundInput{OrderID: item.GetOrderId(), Reason: reason})
+	}
+
+	results, err := h.refundService.RefundBatch(ctx, items)
+	if err != nil {
+		if errors.Is(err, service.ErrRefundBatchEmpty) || errors.Is(err, service.ErrRefundBatchTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &paymentpb.RefundBatchResponse{Results: make([]*paymentpb.RefundItemResult, 0, len(results))}
+	for _, r := range results {
+		item := refundItemResultToProto(r)
+		switch item.GetStatus() {
+		case paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_REFUNDED, paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_ALREADY_REFUNDED:
+			resp.RefundedCount++
+		default:
+			resp.FailedCount++
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
+
+// RefundPayment обрабатывает gRPC запрос RefundPayment
+// Ошибки сопоставляются с кодами так же, как статусы позиций RefundBatch: NOT_FOUND - codes.NotFound,
+// NOT_REFUNDABLE - codes.FailedPrecondition, невалидный запрос - codes.InvalidArgument;
+// кошелёк недоступен - codes.FailedPrecondition
+func (h *Handler) RefundPayment(ctx context.Context, req *paymentpb.RefundPaymentRequest) (*paymentpb.RefundPaymentResponse, error) {
+	refund, replayed, err := h.refundService.Refund(ctx, service.RefundInput{
+		OrderID:        req.GetOrderId(),
+		Amount:         req.GetAmount(),
+		Reason:         req.GetReason(),
+		ToWallet:       req.GetToWallet(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderIDRequired), errors.Is(err, service.ErrInvalidRefundAmount),
+			errors.Is(err, service.ErrRefundAmountExceeded):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrPaymentNotRefundable), errors.Is(err, service.ErrWalletDisabled):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, provider.ErrUnavailable):
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, err
+	}
+
+	refundStatus := paymentpb.RefundStatus_REFUND_STATUS_REFUNDED
+	if replayed {
+		refundStatus = paymentpb.RefundStatus_REFUND_STATUS_ALREADY_REFUNDED
+	}
+	return &paymentpb.RefundPaymentResponse{Status: refundStatus, Refund: refundToProto(refund)}, nil
+}
+
+// GetPayment обрабатывает gRPC запрос GetPayment
+func (h *Handler) GetPayment(ctx context.Context, req *paymentpb.GetPaymentRequest) (*paymentpb.GetPaymentResponse, error) {
+	tx, err := h.paymentService.GetPayment(ctx, req.GetOrderId(), req.GetTransactionId())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentLookupRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.GetPaymentResponse{Payment: paymentToProto(tx)}, nil
+}
+
+// ListTransactions обрабатывает gRPC запрос ListTransactions
+// Нет user_id или невалидный page_token - codes.InvalidArgument
+func (h *Handler) ListTransactions(ctx context.Context, req *paymentpb.ListTransactionsRequest) (*paymentpb.ListTransactionsResponse, error) {
+	page, err := h.paymentService.ListTransactions(ctx, service.ListTransactionsInput{
+		UserID:    req.GetUserId(),
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) || errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &paymentpb.ListTransactionsResponse{NextPageToken: page.NextPageToken}
+	for _, tx := range page.Transactions {
+		resp.Transactions = append(resp.Transactions, paymentToProto(tx))
+	}
+	return resp, nil
+}
+
+// GetWallet обрабатывает gRPC запрос GetWallet
+// Нет user_id или неподдерживаемая валюта - codes.InvalidArgument
+func (h *Handler) GetWallet(ctx context.Context, req *paymentpb.GetWalletRequest) (*paymentpb.GetWalletResponse, error) {
+	wallet, err := h.walletService.GetWallet(ctx, req.GetUserId(), req.GetCurrency())
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) || errors.Is(err, service.ErrUnsupportedCurrency) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.GetWalletResponse{Wallet: walletToProto(wallet)}, nil
+}
+
+// CreditWallet обрабатывает gRPC запрос CreditWallet
+// Невалидный запрос - codes.InvalidArgument, operation_id занят другой операцией - codes.AlreadyExists
+func (h *Handler) CreditWallet(ctx context.Context, req *paymentpb.CreditWalletRequest) (*paymentpb.CreditWalletResponse, error) {
+	op, replayed, err := h.walletService.Credit(ctx, service.CreditInput{
+		OperationID: req.GetOperationId(),
+		UserID:      req.GetUserId(),
+		Amount:      req.GetAmount(),
+		Currency:    req.GetCurrency(),
+		Reason:      req.GetReason(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOperationIDRequired), errors.Is(err, service.ErrUserIDRequired),
+			errors.Is(err, service.ErrInvalidAmount), errors.Is(err, service.ErrUnsupportedCurrency):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrWalletOperationConflict):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, err
+	}
+
+	wallet, err := h.walletService.GetWallet(ctx, op.UserID, op.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentpb.CreditWalletResponse{
+		Operation: walletOperationToProto(op),
+		Wallet:    walletToProto(wallet),
+		Replayed:  replayed,
 	}, nil
 }
 
+// StorePaymentMethod обрабатывает gRPC запрос StorePaymentMethod
+// Нет user_id или token, last4 не из 4 цифр - codes.InvalidArgument
+func (h *Handler) StorePaymentMethod(ctx context.Context, req *paymentpb.StorePaymentMethodRequest) (*paymentpb.StorePaymentMethodResponse, error) {
+	method, created, err := h.methodService.Store(ctx, service.StorePaymentMethodInput{
+		UserID: req.GetUserId(),
+		Token:  req.GetToken(),
+		Brand:  req.GetBrand(),
+		Last4:  req.GetLast4(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) || errors.Is(err, service.ErrPaymentTokenRequired) ||
+			errors.Is(err, service.ErrInvalidCardLast4) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.StorePaymentMethodResponse{PaymentMethod: paymentMethodToProto(method), Created: created}, nil
+}
 
+// ListPaymentMethods обрабатывает gRPC запрос ListPaymentMethods
+// Нет user_id - codes.InvalidArgument
+func (h *Handler) ListPaymentMethods(ctx context.Context, req *paymentpb.ListPaymentMethodsRequest) (*paymentpb.ListPaymentMethodsResponse, error) {
+	methods, err := h.methodService.List(ctx, req.GetUserId())
+	if err != nil {
+		if errors.Is(err, service.ErrUserIDRequired) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	resp := &paymentpb.ListPaymentMethodsResponse{}
+	for _, method := range methods {
+		resp.PaymentMethods = append(resp.PaymentMethods, paymentMethodToProto(method))
+	}
+	return resp, nil
+}
+
+// DeletePaymentMethod обрабатывает gRPC запрос DeletePaymentMethod
+// Нет user_id или payment_method_id - codes.InvalidArgument, способа оплаты у пользователя нет - codes.NotFound
+func (h *Handler) DeletePaymentMethod(ctx context.Context, req *paymentpb.DeletePaymentMethodRequest) (*paymentpb.DeletePaymentMethodResponse, error) {
+	if err := h.methodService.Delete(ctx, req.GetUserId(), req.GetPaymentMethodId()); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserIDRequired), errors.Is(err, service.ErrPaymentMethodIDRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrPaymentMethodNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return &paymentpb.DeletePaymentMethodResponse{}, nil
+}
+
+// paymentStatuses сопоставляет статусы транзакции с protobuf enum
+var paymentStatuses = map[string]paymentpb.PaymentStatus{
+	repository.StatusPending:      paymentpb.PaymentStatus_PAYMENT_STATUS_PENDING,
+	repository.StatusAuthorized:   paymentpb.PaymentStatus_PAYMENT_STATUS_AUTHORIZED,
+	repository.StatusCaptured:     paymentpb.PaymentStatus_PAYMENT_STATUS_CAPTURED,
+	repository.StatusFailed:       paymentpb.PaymentStatus_PAYMENT_STATUS_FAILED,
+	repository.StatusRefunded:     paymentpb.PaymentStatus_PAYMENT_STATUS_REFUNDED,
+	repository.StatusDeclinedRisk: paymentpb.PaymentStatus_PAYMENT_STATUS_DECLINED_RISK,
+	repository.StatusVoided:       paymentpb.PaymentStatus_PAYMENT_STATUS_VOIDED,
+}
+
+// paymentToProto преобразует транзакцию в protobuf
+func paymentToProto(tx repository.Transaction) *paymentpb.Payment {
+	return &paymentpb.Payment{
+		TransactionId:   tx.TransactionID,
+		OrderId:         tx.OrderID,
+		UserId:          tx.UserID,
+		Amount:          tx.Amount,
+		Currency:        tx.Currency,
+		Method:          tx.Method,
+		Status:          paymentStatuses[tx.Status],
+		DeclineReason:   declineReasons[service.DeclineReason(tx.DeclineReason)],
+		RiskFlag:        tx.RiskFlag,
+		PaymentMethodId: tx.PaymentMethodID,
+		CapturedAmount:  tx.CapturedAmount,
+		RefundedAmount:  tx.RefundedAmount,
+		CreatedAt:       timestamppb.New(time.Unix(tx.CreatedAt, 0)),
+	}
+}
+
+// refundToProto преобразует возврат в protobuf
+func refundToProto(r repository.Refund) *paymentpb.Refund {
+	return &paymentpb.Refund{
+		RefundId:          r.RefundID,
+		OrderId:           r.OrderID,
+		TransactionId:     r.TransactionID,
+		Amount:            r.Amount,
+		Currency:          r.Currency,
+		Reason:            r.Reason,
+		CreatedAt:         timestamppb.New(r.CreatedAt),
+		WalletOperationId: r.WalletOperationID,
+	}
+}
+
+// paymentMethodToProto преобразует сохранённый способ оплаты в protobuf без токена провайдера
+func paymentMethodToProto(m repository.PaymentMethod) *paymentpb.PaymentMethod {
+	return &paymentpb.PaymentMethod{
+		PaymentMethodId: m.ID,
+		UserId:          m.UserID,
+		Brand:           m.Brand,
+		Last4:           m.Last4,
+		CreatedAt:       timestamppb.New(m.CreatedAt),
+	}
+}
+
+// walletToProto преобразует кошелёк в protobuf; у кошелька без зачислений updated_at не задаётся
+func walletToProto(w repository.Wallet) *paymentpb.Wallet {
+	wallet := &paymentpb.Wallet{
+		UserId:   w.UserID,
+		Currency: w.Currency,
+		Balance:  w.Balance,
+	}
+	if !w.UpdatedAt.IsZero() {
+		wallet.UpdatedAt = timestamppb.New(w.UpdatedAt)
+	}
+	return wallet
+}
+
+// walletOperationToProto преобразует операцию по кошельку в protobuf
+func walletOperationToProto(op repository.WalletOperation) *paymentpb.WalletOperation {
+	return &paymentpb.WalletOperation{
+		OperationId: op.OperationID,
+		UserId:      op.UserID,
+		Currency:    op.Currency,
+		Kind:        op.Kind,
+		Amount:      op.Amount,
+		Reference:   op.Reference,
+		CreatedAt:   timestamppb.New(op.CreatedAt),
+	}
+}
+
+// refundItemResultToProto преобразует результат позиции возврата в protobuf
+func refundItemResultToProto(r service.RefundItemResult) *paymentpb.RefundItemResult {
+	if r.Err != nil {
+		return &paymentpb.RefundItemResult{
+			OrderId: r.OrderID,
+			Status:  refundErrorStatus(r.Err),
+			Error:   r.Err.Error(),
+		}
+	}
+
+	itemStatus := paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_REFUNDED
+	if r.Replayed {
+		itemStatus = paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_ALREADY_REFUNDED
+	}
+	return &paymentpb.RefundItemResult{
+		OrderId:       r.OrderID,
+		Status:        itemStatus,
+		RefundId:      r.Refund.RefundID,
+		TransactionId: r.Refund.TransactionID,
+		Amount:        r.Refund.Amount,
+		Currency:      r.Refund.Currency,
+	}
+}
+
+// refundErrorStatus сопоставляет ошибку возврата со статусом позиции
+func refundErrorStatus(err error) paymentpb.RefundItemStatus {
+	switch {
+	case errors.Is(err, service.ErrPaymentNotFound):
+		return paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_NOT_FOUND
+	case errors.Is(err, service.ErrPaymentNotRefundable):
+		return paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_NOT_REFUNDABLE
+	case errors.Is(err, service.ErrOrderIDRequired):
+		return paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_INVALID
+	default:
+		return paymentpb.RefundItemStatus_REFUND_ITEM_STATUS_FAILED
+	}
+}
@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// maxWebhookBodyBytes ограничивает тело webhook'а: события провайдера - единицы килобайт
+const maxWebhookBodyBytes = 64 << 10
+
+// PaymentEventHandler применяет событие провайдера к транзакции (service.PaymentService)
+type PaymentEventHandler interface {
+	HandlePaymentEvent(ctx context.Context, event provider.PaymentEvent) error
+}
+
+// WebhookHandler принимает callback'и платёжного провайдера о результате отложенных платежей
+// Ответ 2xx - событие обработано (или не нужно сервису), провайдер больше его не присылает;
+// остальные ответы провайдер повторяет, поэтому временные ошибки отвечают 5xx, а не 2xx
+type WebhookHandler struct {
+	parser   provider.WebhookParser
+	payments PaymentEventHandler
+	logger   *zap.Logger
+}
+
+// NewWebhookHandler создаёт HTTP handler webhook'ов провайдера
+func NewWebhookHandler(parser provider.WebhookParser, payments PaymentEventHandler, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		parser:   parser,
+		payments: payments,
+		logger:   logger,
+	}
+}
+
+// HandleProviderEvent обрабатывает POST /webhooks/provider
+// Неверная подпись или тело - 400, транзакция ещё не сохранена - 404 (провайдер повторит доставку)
+func (h *WebhookHandler) HandleProviderEvent(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.parser.ParseEvent(payload, r.Header)
+	if err != nil {
+		if errors.Is(err, provider.ErrInvalidSignature) {
+			h.logger.Warn("provider webhook rejected", zap.Error(err))
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.payments.HandlePaymentEvent(r.Context(), event); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.Error("provider webhook failed",
+			zap.Error(err),
+			zap.String("event_id", event.ID),
+			zap.String("payment_id", event.PaymentID),
+		)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider/stripe"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// fakePayments записывает события и возвращает заданную ошибку
+type fakePayments struct {
+	events []provider.PaymentEvent
+	err    error
+}
+
+func (f *fakePayments) HandlePaymentEvent(ctx context.Context, event provider.PaymentEvent) error {
+	f.events = append(f.events, event)
+	return f.err
+}
+
+func TestWebhookHandler(t *testing.T) {
+	const (
+		secret  = "whsec_test"
+		payload = `{"id": "evt_1", "type": "payment_intent.succeeded", "data": {"object": {"id": "pi_1"}}}`
+	)
+
+	tests := []struct {
+		name         string
+		signature    string
+		serviceErr   error
+		expectedCode int
+		handled      bool
+	}{
+		{
+			name:         "valid event is handled",
+			signature:    stripe.Sign(secret, time.Now(), []byte(payload)),
+			expectedCode: http.StatusOK,
+			handled:      true,
+		},
+		{
+			name:         "invalid signature is rejected",
+			signature:    stripe.Sign("whsec_other", time.Now(), []byte(payload)),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown payment asks provider to retry",
+			signature:    stripe.Sign(secret, time.Now(), []byte(payload)),
+			serviceErr:   fmt.Errorf("failed to find transaction: %w", repository.ErrNotFound),
+			expectedCode: http.StatusNotFound,
+			handled:      true,
+		},
+		{
+			name:         "service failure is a server error",
+			signature:    stripe.Sign(secret, time.Now(), []byte(payload)),
+			serviceErr:   fmt.Errorf("connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			handled:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := &fakePayments{err: tt.serviceErr}
+			router := NewRouter(NewWebhookHandler(stripe.NewWebhookParser(secret, 5*time.Minute), payments, zap.NewNop()), nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/provider", strings.NewReader(payload))
+			req.Header.Set(stripe.SignatureHeader, tt.signature)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedCode, rec.Code)
+			require.Equal(t, tt.handled, len(payments.events) == 1)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package httpapi
+
+import (
+	"net/http"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/http"
+)
+
+// NewRouter создаёт роутер HTTP API Payment Service: webhook провайдера и GET /health
+// Webhook не требует сессии: подлинность запроса проверяется подписью провайдера
+func NewRouter(webhooks *WebhookHandler, readiness func() bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhooks/provider", webhooks.HandleProviderEvent)
+	mux.Handle("GET /health", platformhealth.Handler(readiness))
+	return mux
+}
@@ -2,19 +2,38 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
+	platformdebug "github.com/shestoi/GoBigTech/platform/debug"
 	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
 	platformlogging "github.com/shestoi/GoBigTech/platform/logging"
 	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
 	platformshutdown "github.com/shestoi/GoBigTech/platform/shutdown"
 	grpcapi "github.com/shestoi/GoBigTech/services/payment/internal/api/grpc"
+	httpapi "github.com/shestoi/GoBigTech/services/payment/internal/api/http"
 	"github.com/shestoi/GoBigTech/services/payment/internal/config"
-	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	eventkafka "github.com/shestoi/GoBigTech/services/payment/internal/event/kafka"
+	"github.com/shestoi/GoBigTech/services/payment/internal/interceptor"
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider/stripe"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/postgres"
+	"github.com/shestoi/GoBigTech/services/payment/internal/risk"
 	"github.com/shestoi/GoBigTech/services/payment/internal/service"
 	paymentpb "github.com/shestoi/GoBigTech/services/payment/v1"
 	"google.golang.org/grpc"
@@ -27,8 +46,12 @@ type App struct {
 	logger      *zap.Logger
 	grpcServer  *grpc.Server
 	listener    net.Listener
+	httpServer  *http.Server // webhook провайдера; nil, если PAYMENT_WEBHOOK_SECRET не задан
 	health      *platformhealth.Health
 	shutdownMgr *platformshutdown.Manager
+	scheduler   *service.SubscriptionScheduler
+	dispatcher  *eventkafka.OutboxDispatcher
+	monitor     *postgresMonitor // nil, если проверка PostgreSQL для readiness выключена
 	wg          sync.WaitGroup
 }
 
@@ -36,12 +59,17 @@ type App struct {
 func Build(cfg config.Config) (*App, error) {
 	const op = "app.Build"
 
-	// Создаём logger
+	// Создаём logger; повторяющиеся предупреждения и ошибки ограничиваются по LOG_SAMPLING_*
+	logSampling, err := platformlogging.LoadSamplingEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sampling config: %w", err)
+	}
 	logger, err := platformlogging.New(platformlogging.Config{
 		ServiceName: "payment",
 		Env:         string(cfg.AppEnv),
 		Level:       os.Getenv("LOG_LEVEL"),
 		Format:      os.Getenv("LOG_FORMAT"),
+		Sampling:    logSampling,
 	})
 	if err != nil {
 		return nil, err
@@ -63,24 +91,159 @@ func Build(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
-	// Создаём in-memory репозиторий
-	paymentRepo := memory.NewMemoryRepository()
+	// Отладочный сервер (pprof, expvar, дамп горутин): только при заданном DEBUG_ADDR, слушает loopback
+	debugServer, err := platformdebug.Start(cfg.DebugAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Подключаемся к PostgreSQL
+	logger.Info("Connecting to PostgreSQL")
+	pool, err := pgxpool.New(context.Background(), cfg.PostgresDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем подключение к PostgreSQL
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	logger.Info("PostgreSQL connection established")
+
+	// Применяем миграции из ./migrations (в образе они лежат рядом с бинарником)
+	if err := applyMigrations(cfg.PostgresDSN); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	logger.Info("Database migrations applied successfully")
+
+	// Транзакции, возвраты, подписки и outbox хранятся в одном репозитории
+	paymentRepo := postgres.NewRepository(pool)
+
+	// Платёжный провайдер по PAYMENT_PROVIDER, поверх него - имитация задержки и отказов из PAYMENT_PROVIDER_*
+	// и метрики вызовов при включённом OTEL, снаружи - повторы и circuit breaker (PAYMENT_PROVIDER_RETRY_*, _BREAKER_*)
+	var gateway provider.PaymentProvider
+	switch cfg.Provider {
+	case config.ProviderStripe:
+		gateway = stripe.New(cfg.StripeAPIURL, cfg.StripeSecretKey, cfg.ProviderTimeout)
+	default:
+		gateway = mockprovider.New()
+	}
+	logger.Info("Payment provider configured", zap.String("provider", cfg.Provider))
+
+	var providerMetrics service.ProviderMetricsRecorder
+	var resilienceMetrics service.ResilienceMetricsRecorder
+	var paymentMetrics service.PaymentMetricsRecorder
+	if cfg.OTelEnabled {
+		metrics := newProviderMetricsRecorder()
+		providerMetrics, resilienceMetrics = metrics, metrics
+		paymentMetrics = newPaymentMetricsRecorder()
+	}
+	simulatedProvider := service.NewProviderSimulator(gateway, service.ProviderSimulation{
+		Latency:     cfg.ProviderLatency,
+		Jitter:      cfg.ProviderJitter,
+		SlowRate:    cfg.ProviderSlowRate,
+		SlowLatency: cfg.ProviderSlowLatency,
+		FailureRate: cfg.ProviderFailureRate,
+	}, providerMetrics)
+	paymentProvider := service.NewProviderResilience(simulatedProvider, service.ProviderResiliencePolicy{
+		MaxRetries:              cfg.ProviderMaxRetries,
+		RetryBackoff:            cfg.ProviderRetryBackoff,
+		RetryMaxBackoff:         cfg.ProviderRetryMaxBackoff,
+		BreakerFailureThreshold: cfg.ProviderBreakerFailureThreshold,
+		BreakerOpenTimeout:      cfg.ProviderBreakerOpenTimeout,
+	}, resilienceMetrics)
+
+	// Антифрод-проверка по порогам PAYMENT_RISK_*: velocity считается по payment_transactions
+	var riskChecker risk.RiskChecker
+	if cfg.RiskEnabled {
+		riskChecker = risk.NewThresholdChecker(paymentRepo, risk.Thresholds{
+			ReviewAmount:   cfg.RiskReviewAmount,
+			VelocityWindow: cfg.RiskVelocityWindow,
+			MaxPayments:    cfg.RiskMaxPayments,
+			MaxAmount:      cfg.RiskMaxAmount,
+		})
+	} else {
+		logger.Info("PAYMENT_RISK_ENABLED=false: risk check disabled")
+	}
 
 	// Создаём service слой
-	paymentService := service.NewPaymentService(paymentRepo)
+	paymentService := service.NewPaymentService(paymentRepo, cfg.MaxAmount, paymentProvider, riskChecker, paymentRepo, paymentRepo, paymentMetrics)
+
+	// Результаты платежей пишутся в outbox вместе со статусом транзакции, dispatcher публикует их в Kafka
+	outboxDispatcher := eventkafka.NewOutboxDispatcher(
+		logger,
+		paymentRepo,
+		cfg.KafkaBrokers,
+		cfg.PaymentSucceededTopic,
+		cfg.PaymentFailedTopic,
+		cfg.PaymentVoidedTopic,
+		cfg.OutboxBatchSize,
+		cfg.OutboxInterval,
+	)
+
+	// Подписки: списания идут через paymentService, события - в Kafka
+	subscriptionRepo := paymentRepo
+	subscriptionPublisher := eventkafka.NewKafkaSubscriptionEventPublisher(
+		logger,
+		cfg.KafkaBrokers,
+		cfg.SubscriptionChargedTopic,
+		cfg.SubscriptionFailedTopic,
+	)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, paymentService, subscriptionPublisher)
+	subscriptionScheduler := service.NewSubscriptionScheduler(subscriptionService, cfg.SubscriptionChargeInterval)
+
+	// Возвраты: таблица payment_refunds, транзакции читаются из payment_transactions
+	refundService := service.NewRefundService(paymentRepo, paymentRepo, paymentProvider, paymentRepo, cfg.RefundBatchMaxItems, cfg.RefundBatchConcurrency, paymentMetrics)
+
+	// Кошельки: таблицы payment_wallets*, оплата с кошелька и возврат на него - через paymentService и refundService
+	walletService := service.NewWalletService(paymentRepo)
+
+	// Сохранённые способы оплаты: таблица payment_methods, оплата по ним - через paymentService
+	methodService := service.NewPaymentMethodService(paymentRepo)
 
 	// Создаём gRPC handler
-	grpcHandler := grpcapi.NewHandler(paymentService)
+	grpcHandler := grpcapi.NewHandler(paymentService, subscriptionService, refundService, walletService, methodService)
+
+	// Webhook провайдера: результат отложенных платежей (СБП, оплата по счёту)
+	// События в формате Stripe для обоих адаптеров: для mock их можно отправить вручную, подписав stripe.Sign
+	var httpServer *http.Server
+	if cfg.WebhookSecret != "" {
+		readiness := func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			return pool.Ping(ctx) == nil
+		}
+		webhookHandler := httpapi.NewWebhookHandler(stripe.NewWebhookParser(cfg.WebhookSecret, cfg.WebhookTolerance), paymentService, logger)
+		httpServer = &http.Server{
+			Addr:         cfg.WebhookAddr,
+			Handler:      platformobservability.HTTPMiddleware("payment", logger)(httpapi.NewRouter(webhookHandler, readiness)),
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		logger.Info("Payment provider webhook configured", zap.String("addr", cfg.WebhookAddr))
+	} else {
+		logger.Info("PAYMENT_WEBHOOK_SECRET is not set: provider webhook disabled, pending payments are not completed")
+	}
 
 	// Слушаем на указанном адресе
 	listener, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
+		pool.Close()
 		return nil, err
 	}
 
-	// gRPC сервер с tracing interceptor
+	// Токен вызывающего сервиса (Order, admin tooling) проверяется после tracing, чтобы отказы попадали в трейсы
+	authInterceptor := interceptor.NewServiceAuthInterceptor(cfg.AuthTokens, logger, cfg.AuthDisabled)
+
+	// gRPC сервер с tracing и auth interceptors
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(platformobservability.GRPCUnaryServerInterceptor("payment")),
+		grpc.ChainUnaryInterceptor(
+			platformobservability.GRPCUnaryServerInterceptor("payment"),
+			authInterceptor.Unary(),
+		),
 	)
 
 	// Включаем reflection, если указано в конфиге
@@ -89,7 +252,7 @@ func Build(cfg config.Config) (*App, error) {
 		logger.Info("gRPC reflection enabled")
 	}
 
-	// Создаём health check с начальным статусом SERVING
+	// Создаём health check с начальным статусом SERVING: PostgreSQL уже проверен, миграции применены
 	health := platformhealth.New(grpc_health_v1.HealthCheckResponse_SERVING)
 	health.Register(grpcServer)
 	logger.Info("Health check initialized with SERVING status")
@@ -99,29 +262,78 @@ func Build(cfg config.Config) (*App, error) {
 
 	logger.Info("Payment gRPC server configured", zap.String("addr", cfg.GRPCAddr))
 
+	// Readiness переоценивается по доступности PostgreSQL, а не только при старте
+	var monitor *postgresMonitor
+	if cfg.PostgresCheckInterval > 0 {
+		monitor = newPostgresMonitor(pool, health, logger, cfg.PostgresCheckInterval, cfg.PostgresFailureThreshold)
+		logger.Info("PostgreSQL readiness check enabled",
+			zap.Duration("interval", cfg.PostgresCheckInterval),
+			zap.Int("failure_threshold", cfg.PostgresFailureThreshold),
+		)
+	}
+
 	// Создаём shutdown manager
 	shutdownMgr := platformshutdown.New(cfg.ShutdownTimeout, logger)
 
 	// Регистрируем shutdown функции в обратном порядке выполнения
 	shutdownMgr.Add("otel", otelShutdown)
+	if debugServer != nil {
+		shutdownMgr.Add("debug_server", platformshutdown.ShutdownHTTPServer(debugServer))
+	}
+	shutdownMgr.Add("postgres_pool", platformshutdown.ClosePool(pool))
+	shutdownMgr.Add("subscription_publisher", func(ctx context.Context) error {
+		return subscriptionPublisher.Close()
+	})
+	shutdownMgr.Add("outbox_dispatcher", func(ctx context.Context) error {
+		return outboxDispatcher.Close()
+	})
 	shutdownMgr.Add("grpc_server", platformshutdown.ShutdownGRPCServer(grpcServer))
+	if httpServer != nil {
+		shutdownMgr.Add("webhook_http_server", platformshutdown.ShutdownHTTPServer(httpServer))
+	}
 	shutdownMgr.Add("health_readiness", platformshutdown.SetHealthNotServing(health))
+	// Монитор останавливается первым: после этого readiness меняет только health_readiness
+	if monitor != nil {
+		shutdownMgr.Add("postgres_monitor", monitor.Stop)
+	}
 
 	return &App{
 		logger:      logger,
 		grpcServer:  grpcServer,
 		listener:    listener,
+		httpServer:  httpServer,
 		health:      health,
 		shutdownMgr: shutdownMgr,
+		scheduler:   subscriptionScheduler,
+		dispatcher:  outboxDispatcher,
+		monitor:     monitor,
 	}, nil
 }
 
+// applyMigrations применяет goose миграции из каталога migrations относительно рабочей директории
+func applyMigrations(dsn string) error {
+	db, err := goose.OpenDBWithDriver("pgx", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return goose.Up(db, filepath.Join(wd, "migrations"))
+}
+
 // Run запускает сервис и блокируется до получения сигнала shutdown
 func (a *App) Run() error {
 	defer platformlogging.Sync(a.logger)
 
 	a.logger.Info("Starting Payment service", zap.String("addr", a.listener.Addr().String()))
 
+	schedulerCtx, schedulerCancel := context.WithCancel(context.Background())
+	defer schedulerCancel()
+
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
@@ -130,10 +342,113 @@ func (a *App) Run() error {
 		}
 	}()
 
+	if a.httpServer != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				a.logger.Error("webhook HTTP server error", zap.Error(err))
+			}
+		}()
+	}
+
+	if a.monitor != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.monitor.Run()
+		}()
+	}
+
+	// Запускаем планировщик списаний по подпискам в отдельной горутине
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.scheduler.Start(schedulerCtx); err != nil {
+			a.logger.Error("subscription scheduler error", zap.Error(err))
+		}
+	}()
+
+	// Публикуем события результата платежей из outbox
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.dispatcher.Start(schedulerCtx); err != nil {
+			a.logger.Error("outbox dispatcher error", zap.Error(err))
+		}
+	}()
+
 	// Ожидаем сигнал и выполняем shutdown
 	a.shutdownMgr.Wait()
 
+	// Останавливаем планировщик и outbox dispatcher
+	schedulerCancel()
+
 	a.wg.Wait()
 	a.logger.Info("Payment service stopped")
 	return nil
 }
+
+// providerMetricsRecorder реализует service.ProviderMetricsRecorder и service.ResilienceMetricsRecorder
+// через OpenTelemetry Meter.
+type providerMetricsRecorder struct {
+	calls        metric.Int64Counter
+	duration     metric.Float64Histogram
+	retries      metric.Int64Counter
+	breakerState metric.Int64Gauge
+}
+
+func newProviderMetricsRecorder() *providerMetricsRecorder {
+	meter := otel.Meter("payment")
+	calls, _ := meter.Int64Counter("payment_provider_requests_total", metric.WithDescription("Payment provider calls by result: success, declined, failure or canceled"))
+	duration, _ := meter.Float64Histogram("payment_provider_duration_ms", metric.WithDescription("Payment provider call duration in milliseconds"),
+		metric.WithExplicitBucketBoundaries(5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000))
+	retries, _ := meter.Int64Counter("payment_provider_retries_total", metric.WithDescription("Payment provider call retries by operation: authorize, capture or refund"))
+	breakerState, _ := meter.Int64Gauge("payment_provider_circuit_breaker_state", metric.WithDescription("Payment provider circuit breaker state: 0 - closed, 1 - half-open, 2 - open"))
+	breakerState.Record(context.Background(), int64(service.BreakerClosed))
+	return &providerMetricsRecorder{calls: calls, duration: duration, retries: retries, breakerState: breakerState}
+}
+
+func (r *providerMetricsRecorder) RecordProviderCall(d time.Duration, result string) {
+	attrs := metric.WithAttributes(attribute.String("result", result))
+	r.calls.Add(context.Background(), 1, attrs)
+	r.duration.Record(context.Background(), float64(d.Microseconds())/1000, attrs)
+}
+
+func (r *providerMetricsRecorder) RecordProviderRetry(operation string) {
+	r.retries.Add(context.Background(), 1, metric.WithAttributes(attribute.String("operation", operation)))
+}
+
+func (r *providerMetricsRecorder) RecordBreakerState(state service.BreakerState) {
+	r.breakerState.Record(context.Background(), int64(state))
+}
+
+// paymentMetricsRecorder реализует service.PaymentMetricsRecorder через OpenTelemetry Meter.
+type paymentMetricsRecorder struct {
+	attempts       metric.Int64Counter
+	duration       metric.Float64Histogram
+	refunds        metric.Int64Counter
+	refundedAmount metric.Int64Counter
+}
+
+func newPaymentMetricsRecorder() *paymentMetricsRecorder {
+	meter := otel.Meter("payment")
+	attempts, _ := meter.Int64Counter("payment_attempts_total", metric.WithDescription("ProcessPayment attempts by result and payment method"))
+	duration, _ := meter.Float64Histogram("payment_processing_duration_ms", metric.WithDescription("ProcessPayment processing duration in milliseconds, including provider calls and retries"),
+		metric.WithExplicitBucketBoundaries(5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000))
+	refunds, _ := meter.Int64Counter("payment_refunds_total", metric.WithDescription("Refunds by result: refunded, replayed, rejected or error"))
+	refundedAmount, _ := meter.Int64Counter("payment_refunded_amount_total", metric.WithDescription("Refunded amount in minor currency units"))
+	return &paymentMetricsRecorder{attempts: attempts, duration: duration, refunds: refunds, refundedAmount: refundedAmount}
+}
+
+func (r *paymentMetricsRecorder) RecordPaymentAttempt(result, method string, d time.Duration) {
+	r.attempts.Add(context.Background(), 1, metric.WithAttributes(attribute.String("result", result), attribute.String("method", method)))
+	r.duration.Record(context.Background(), float64(d.Microseconds())/1000, metric.WithAttributes(attribute.String("result", result)))
+}
+
+func (r *paymentMetricsRecorder) RecordRefund(result string, amount int64, currency string) {
+	r.refunds.Add(context.Background(), 1, metric.WithAttributes(attribute.String("result", result)))
+	if amount > 0 {
+		r.refundedAmount.Add(context.Background(), amount, metric.WithAttributes(attribute.String("currency", currency)))
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	platformhealth "github.com/shestoi/GoBigTech/platform/health/grpc"
+)
+
+// postgresMonitor переводит readiness Payment в NOT_SERVING, когда PostgreSQL недоступен, и обратно, когда он вернулся
+// Раз в interval выполняется ping: NOT_SERVING ставится после failureThreshold неудачных ping подряд,
+// SERVING возвращается после первого удачного
+type postgresMonitor struct {
+	pool             *pgxpool.Pool
+	health           *platformhealth.Health
+	logger           *zap.Logger
+	interval         time.Duration
+	failureThreshold int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newPostgresMonitor(pool *pgxpool.Pool, health *platformhealth.Health, logger *zap.Logger, interval time.Duration, failureThreshold int) *postgresMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &postgresMonitor{
+		pool:             pool,
+		health:           health,
+		logger:           logger,
+		interval:         interval,
+		failureThreshold: failureThreshold,
+		ctx:              ctx,
+		cancel:           cancel,
+		done:             make(chan struct{}),
+	}
+}
+
+// Run выполняет проверки до вызова Stop
+func (m *postgresMonitor) Run() {
+	defer close(m.done)
+	ctx := m.ctx
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	serving := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if !serving {
+				m.health.SetServing("")
+				m.logger.Info("PostgreSQL recovered, readiness set to SERVING", zap.Int("failed_checks", failures))
+				serving = true
+			}
+			failures = 0
+			continue
+		}
+
+		failures++
+		m.logger.Warn("PostgreSQL readiness check failed", zap.Int("consecutive_failures", failures), zap.Error(err))
+		if serving && failures >= m.failureThreshold {
+			serving = false
+			m.health.SetNotServing("")
+			m.logger.Error("PostgreSQL unreachable, readiness set to NOT_SERVING", zap.Int("consecutive_failures", failures))
+		}
+	}
+}
+
+// ping проверяет соединение с таймаутом, равным interval
+func (m *postgresMonitor) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+	return m.pool.Ping(ctx)
+}
+
+// Stop останавливает проверки и ждёт выхода из Run: после него readiness меняет только shutdown
+func (m *postgresMonitor) Stop(ctx context.Context) error {
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
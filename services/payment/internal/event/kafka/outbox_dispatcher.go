@@ -0,0 +1,156 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/service"
+)
+
+// messageWriter - запись сообщений в Kafka; реализуется *kafka.Writer
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// OutboxDispatcher публикует события результата платежа из outbox в Kafka
+// Доставка at-least-once: событие отмечается отправленным после записи в Kafka, и падение между записью
+// и отметкой публикует его повторно - consumers дедуплицируют по event_id.
+// Ключ сообщения - order_id: события одного заказа попадают в одну партицию и читаются по порядку
+type OutboxDispatcher struct {
+	logger    *zap.Logger
+	repo      repository.OutboxRepository
+	writer    messageWriter
+	topics    map[string]string // тип события -> топик
+	batchSize int
+	interval  time.Duration
+}
+
+// NewOutboxDispatcher создаёт outbox dispatcher
+func NewOutboxDispatcher(
+	logger *zap.Logger,
+	repo repository.OutboxRepository,
+	brokers []string,
+	succeededTopic, failedTopic, voidedTopic string,
+	batchSize int, // событий за один проход
+	interval time.Duration, // интервал между проходами
+) *OutboxDispatcher {
+	writer := &kafka.Writer{ // топик задаётся в каждом сообщении
+		Addr:     kafka.TCP(brokers...),
+		Balancer: &kafka.Hash{}, // партиция по ключу (order_id)
+	}
+
+	return &OutboxDispatcher{
+		logger: logger,
+		repo:   repo,
+		writer: writer,
+		topics: map[string]string{
+			service.EventTypePaymentSucceeded: succeededTopic,
+			service.EventTypePaymentFailed:    failedTopic,
+			service.EventTypePaymentVoided:    voidedTopic,
+		},
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+// Start запускает dispatcher и блокируется до отмены ctx
+func (d *OutboxDispatcher) Start(ctx context.Context) error {
+	d.logger.Info("starting payment outbox dispatcher",
+		zap.Int("batch_size", d.batchSize),
+		zap.Duration("interval", d.interval),
+	)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.processBatch(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("failed to process outbox batch", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("payment outbox dispatcher stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// processBatch публикует до batchSize неотправленных событий, старые первыми
+// На первой ошибке проход прерывается: следующие события того же заказа не должны обогнать неотправленное
+func (d *OutboxDispatcher) processBatch(ctx context.Context) error {
+	events, err := d.repo.GetPendingOutboxEvents(ctx, d.batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get pending events: %w", err)
+	}
+
+	for _, event := range events {
+		if err := d.publish(ctx, event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if markErr := d.repo.MarkOutboxEventFailed(ctx, event.EventID, err.Error()); markErr != nil {
+				d.logger.Error("failed to mark outbox event failed", zap.Error(markErr), zap.String("event_id", event.EventID))
+			}
+			d.logger.Warn("failed to publish outbox event, will retry",
+				zap.Error(err),
+				zap.String("event_id", event.EventID),
+				zap.String("event_type", event.EventType),
+				zap.Int("attempts", event.Attempts+1),
+			)
+			return nil
+		}
+
+		if err := d.repo.MarkOutboxEventSent(ctx, event.EventID); err != nil {
+			// Событие уже в Kafka: на следующем проходе оно уйдёт повторно, consumer отбросит дубль
+			return fmt.Errorf("failed to mark event %s sent: %w", event.EventID, err)
+		}
+		d.logger.Info("payment event published",
+			zap.String("event_id", event.EventID),
+			zap.String("event_type", event.EventType),
+			zap.String("order_id", event.AggregateID),
+		)
+	}
+	return nil
+}
+
+// publish записывает событие в топик его типа
+func (d *OutboxDispatcher) publish(ctx context.Context, event repository.OutboxEvent) error {
+	topic, ok := d.topics[event.EventType]
+	if !ok {
+		return fmt.Errorf("unknown payment event type %q", event.EventType)
+	}
+
+	// Span публикации продолжает trace запроса, сохранившего событие в outbox
+	pubCtx, span := platformobservability.StartProducerSpan(
+		platformobservability.ContextFromMessageHeaders(ctx, event.Headers), tracerName, topic)
+	defer span.End()
+
+	err := d.writer.WriteMessages(pubCtx, kafka.Message{
+		Topic:   topic,
+		Key:     []byte(event.AggregateID),
+		Value:   event.Payload,
+		Headers: platformkafka.NewHeaders(pubCtx, event.EventType, event.EventID, service.PaymentEventVersion).Kafka(),
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Close закрывает Kafka writer
+func (d *OutboxDispatcher) Close() error {
+	return d.writer.Close()
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/shestoi/GoBigTech/services/payment/internal/service"
+)
+
+// fakeWriter запоминает записанные сообщения; err возвращается на каждую запись
+type fakeWriter struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func newTestDispatcher(repo repository.OutboxRepository, writer messageWriter) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		logger: zap.NewNop(),
+		repo:   repo,
+		writer: writer,
+		topics: map[string]string{
+			service.EventTypePaymentSucceeded: "payment.succeeded",
+			service.EventTypePaymentFailed:    "payment.failed",
+			service.EventTypePaymentVoided:    "payment.voided",
+		},
+		batchSize: 10,
+		interval:  time.Second,
+	}
+}
+
+func TestOutboxDispatcher_ProcessBatch(t *testing.T) {
+	ctx := context.Background()
+	succeeded := repository.OutboxEvent{
+		EventID: "payment.succeeded-tx-1", EventType: service.EventTypePaymentSucceeded, AggregateID: "order-1", Payload: []byte(`{}`),
+	}
+	failed := repository.OutboxEvent{
+		EventID: "payment.failed-tx-2", EventType: service.EventTypePaymentFailed, AggregateID: "order-2", Payload: []byte(`{}`),
+	}
+
+	t.Run("publishes events to topic by type keyed by order", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		writer := &fakeWriter{}
+		repo.On("GetPendingOutboxEvents", ctx, 10).Return([]repository.OutboxEvent{succeeded, failed}, nil).Once()
+		repo.On("MarkOutboxEventSent", ctx, succeeded.EventID).Return(nil).Once()
+		repo.On("MarkOutboxEventSent", ctx, failed.EventID).Return(nil).Once()
+
+		require.NoError(t, newTestDispatcher(repo, writer).processBatch(ctx))
+
+		require.Len(t, writer.messages, 2)
+		require.Equal(t, "payment.succeeded", writer.messages[0].Topic)
+		require.Equal(t, []byte("order-1"), writer.messages[0].Key)
+		require.Equal(t, "payment.failed", writer.messages[1].Topic)
+	})
+
+	t.Run("publish error records attempt and stops batch", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		writer := &fakeWriter{err: errors.New("kafka unavailable")}
+		repo.On("GetPendingOutboxEvents", ctx, 10).Return([]repository.OutboxEvent{succeeded, failed}, nil).Once()
+		repo.On("MarkOutboxEventFailed", ctx, succeeded.EventID, "kafka unavailable").Return(nil).Once()
+
+		require.NoError(t, newTestDispatcher(repo, writer).processBatch(ctx))
+
+		repo.AssertNotCalled(t, "MarkOutboxEventSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown event type is not published", func(t *testing.T) {
+		repo := mocks.NewOutboxRepository(t)
+		writer := &fakeWriter{}
+		unknown := repository.OutboxEvent{EventID: "evt-1", EventType: "payment.unknown"}
+		repo.On("GetPendingOutboxEvents", ctx, 10).Return([]repository.OutboxEvent{unknown}, nil).Once()
+		repo.On("MarkOutboxEventFailed", ctx, "evt-1", mock.Anything).Return(nil).Once()
+
+		require.NoError(t, newTestDispatcher(repo, writer).processBatch(ctx))
+
+		require.Empty(t, writer.messages)
+	})
+}
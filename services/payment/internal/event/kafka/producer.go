@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	platformkafka "github.com/shestoi/GoBigTech/platform/kafka"
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/payment/internal/service"
+)
+
+// tracerName - имя сервиса для span публикации сообщений
+const tracerName = "payment"
+
+// subscriptionEventVersion - версия схемы событий подписки; 2 - amount в минимальных единицах валюты
+const subscriptionEventVersion = 2
+
+// KafkaSubscriptionEventPublisher реализует service.SubscriptionEventPublisher используя Kafka
+// События charged и failed пишутся в разные топики; ключ сообщения - ID подписки, поэтому события
+// одной подписки попадают в одну партицию и читаются в порядке периодов
+type KafkaSubscriptionEventPublisher struct {
+	logger *zap.Logger
+	writer *kafka.Writer
+	topics map[string]string // тип события -> топик
+}
+
+// NewKafkaSubscriptionEventPublisher создаёт Kafka publisher событий подписки
+func NewKafkaSubscriptionEventPublisher(logger *zap.Logger, brokers []string, chargedTopic, failedTopic string) *KafkaSubscriptionEventPublisher {
+	writer := &kafka.Writer{ // топик задаётся в каждом сообщении
+		Addr:     kafka.TCP(brokers...),
+		Balancer: &kafka.Hash{}, // партиция по ключу (ID подписки)
+	}
+
+	return &KafkaSubscriptionEventPublisher{
+		logger: logger,
+		writer: writer,
+		topics: map[string]string{
+			service.EventTypeSubscriptionCharged: chargedTopic,
+			service.EventTypeSubscriptionFailed:  failedTopic,
+		},
+	}
+}
+
+// Close закрывает Kafka writer
+func (p *KafkaSubscriptionEventPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// PublishSubscriptionEvent публикует событие payment.subscription.charged или payment.subscription.failed
+func (p *KafkaSubscriptionEventPublisher) PublishSubscriptionEvent(ctx context.Context, event service.SubscriptionEvent) error {
+	topic, ok := p.topics[event.EventType]
+	if !ok {
+		return fmt.Errorf("unknown subscription event type %q", event.EventType)
+	}
+
+	payload := map[string]interface{}{
+		"event_id":        event.EventID,
+		"event_type":      event.EventType,
+		"event_version":   subscriptionEventVersion,
+		"occurred_at":     event.OccurredAt.Format(time.RFC3339),
+		"subscription_id": event.SubscriptionID,
+		"user_id":         event.UserID,
+		"period":          event.Period,
+		"amount":          event.Amount,
+		"currency":        event.Currency,
+	}
+	if event.TransactionID != "" {
+		payload["transaction_id"] = event.TransactionID
+	}
+	if event.DeclineReason != "" {
+		payload["reason"] = event.DeclineReason
+	}
+
+	valueBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := platformobservability.StartProducerSpan(ctx, tracerName, topic)
+	defer span.End()
+
+	message := kafka.Message{
+		Topic:   topic,
+		Key:     []byte(event.SubscriptionID),
+		Value:   valueBytes,
+		Headers: platformkafka.NewHeaders(ctx, event.EventType, event.EventID, subscriptionEventVersion).Kafka(),
+	}
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		p.logger.Error("failed to publish subscription event",
+			zap.Error(err),
+			zap.String("topic", topic),
+			zap.String("subscription_id", event.SubscriptionID),
+			zap.Int64("period", event.Period),
+		)
+		return err
+	}
+
+	p.logger.Info("subscription event published",
+		zap.String("topic", topic),
+		zap.String("event_id", event.EventID),
+		zap.String("subscription_id", event.SubscriptionID),
+	)
+	return nil
+}
@@ -0,0 +1,122 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ServiceTokenHeader ключ для передачи токена вызывающего сервиса в gRPC metadata
+	ServiceTokenHeader = "x-service-token"
+)
+
+// ctxKeyCaller типизированный ключ для хранения имени вызывающего сервиса в context
+type ctxKeyCaller struct{}
+
+var callerKey = ctxKeyCaller{}
+
+// CallerFromContext извлекает имя вызывающего сервиса (order, admin), чей токен прошёл проверку
+// Возвращает имя и true, если значение найдено, иначе пустую строку и false
+func CallerFromContext(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerKey).(string)
+	return caller, ok
+}
+
+// ServiceAuthInterceptor пускает к API Payment только известные сервисы: Order и admin tooling
+// Каждый вызывающий сервис передаёт свой общий секрет в x-service-token
+type ServiceAuthInterceptor struct {
+	tokens   map[string]string // caller -> token
+	logger   *zap.Logger
+	disabled bool
+}
+
+// NewServiceAuthInterceptor создаёт interceptor с токенами вызывающих сервисов (caller -> token)
+// disabled - dev mode: токен не проверяется, только для локального стенда
+func NewServiceAuthInterceptor(tokens map[string]string, logger *zap.Logger, disabled bool) *ServiceAuthInterceptor {
+	if disabled {
+		logger.Warn("service auth is disabled: any peer can call the payment API")
+	}
+	return &ServiceAuthInterceptor{
+		tokens:   tokens,
+		logger:   logger,
+		disabled: disabled,
+	}
+}
+
+// Unary возвращает unary interceptor для проверки токена вызывающего сервиса
+func (a *ServiceAuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		// Пропускаем health check и reflection без проверки токена
+		if a.disabled || isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticate сверяет x-service-token из metadata с токенами вызывающих сервисов и кладёт имя сервиса в context
+func (a *ServiceAuthInterceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(ServiceTokenHeader)
+	if len(tokens) == 0 || tokens[0] == "" {
+		a.logger.Warn("service token not found in metadata",
+			zap.String("method", fullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "service token is required")
+	}
+
+	caller, ok := a.callerByToken(tokens[0])
+	if !ok {
+		a.logger.Warn("invalid service token",
+			zap.String("method", fullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "invalid service token")
+	}
+
+	a.logger.Debug("service token validated",
+		zap.String("caller", caller),
+		zap.String("method", fullMethod),
+	)
+	return context.WithValue(ctx, callerKey, caller), nil
+}
+
+// callerByToken ищет вызывающий сервис по токену
+// Токен сравнивается со всеми известными за постоянное время, чтобы время ответа не подсказывало токен
+func (a *ServiceAuthInterceptor) callerByToken(token string) (string, bool) {
+	found := ""
+	for caller, expected := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			found = caller
+		}
+	}
+	return found, found != ""
+}
+
+// isPublicMethod проверяет, является ли метод публичным (не требует аутентификации)
+func isPublicMethod(fullMethod string) bool {
+	// Health check методы
+	if fullMethod == "/grpc.health.v1.Health/Check" ||
+		fullMethod == "/grpc.health.v1.Health/Watch" {
+		return true
+	}
+
+	// Reflection методы (начинаются с /grpc.reflection)
+	return strings.HasPrefix(fullMethod, "/grpc.reflection")
+}
@@ -0,0 +1,78 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const processPaymentMethod = "/payment.v1.PaymentService/ProcessPayment"
+
+var testTokens = map[string]string{
+	"order": "order-token-0123456789",
+	"admin": "admin-token-0123456789",
+}
+
+// callUnary вызывает unary interceptor с токеном в metadata и возвращает вызывающий сервис из context handler
+func callUnary(auth *ServiceAuthInterceptor, method, token string) (string, bool, error) {
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ServiceTokenHeader, token))
+	}
+	var (
+		caller string
+		called bool
+	)
+	_, err := auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		caller, _ = CallerFromContext(ctx)
+		called = true
+		return nil, nil
+	})
+	return caller, called, err
+}
+
+func TestServiceAuthInterceptor_Unary(t *testing.T) {
+	auth := NewServiceAuthInterceptor(testTokens, zap.NewNop(), false)
+
+	t.Run("known token identifies caller", func(t *testing.T) {
+		caller, called, err := callUnary(auth, processPaymentMethod, "admin-token-0123456789")
+
+		require.NoError(t, err)
+		require.True(t, called)
+		require.Equal(t, "admin", caller)
+	})
+
+	t.Run("no token is rejected", func(t *testing.T) {
+		_, called, err := callUnary(auth, processPaymentMethod, "")
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.False(t, called)
+	})
+
+	t.Run("unknown token is rejected", func(t *testing.T) {
+		_, called, err := callUnary(auth, processPaymentMethod, "order-token-wrong")
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.False(t, called)
+	})
+
+	t.Run("health check is public", func(t *testing.T) {
+		_, called, err := callUnary(auth, "/grpc.health.v1.Health/Check", "")
+
+		require.NoError(t, err)
+		require.True(t, called)
+	})
+
+	t.Run("disabled auth skips token check", func(t *testing.T) {
+		_, called, err := callUnary(NewServiceAuthInterceptor(nil, zap.NewNop(), true), processPaymentMethod, "")
+
+		require.NoError(t, err)
+		require.True(t, called)
+	})
+}
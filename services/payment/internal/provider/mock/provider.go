@@ -0,0 +1,148 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+// Способы оплаты, для которых Provider детерминированно отказывает: аналог тестовых карт платёжных шлюзов
+const (
+	MethodInsufficientFunds = "card_insufficient_funds"
+	MethodRisk              = "card_risk"
+	MethodDeclined          = "card_declined"
+	MethodUnavailable       = "card_provider_unavailable" // ErrUnavailable на каждый Authorize
+)
+
+// Способы оплаты с подтверждением покупателем: Authorize возвращает Pending, результат присылает webhook
+const (
+	MethodSBP     = "sbp"
+	MethodInvoice = "invoice"
+)
+
+// payment - состояние платежа у Provider
+type payment struct {
+	authorized int64
+	captured   int64
+	refunded   int64
+	voided     bool            // авторизация отменена (Void), списать её уже нельзя
+	refunds    map[string]bool // выполненные возвраты по ключу идемпотентности
+}
+
+// Provider - детерминированный платёжный провайдер в памяти
+// Результат зависит только от запроса: отказы задаются способом оплаты (Method*), остальное проходит.
+// Платежи MethodSBP и MethodInvoice остаются в ожидании: событие о них отправляется на webhook вручную.
+// ID платежа - mock_pay_<order_id> (mock_pay_<order_id>_<idempotency_key> для запроса с ключом),
+// поэтому повтор авторизации заказа возвращает тот же платёж.
+// Используется по умолчанию (PAYMENT_PROVIDER=mock) и в тестах; состояние теряется при рестарте
+type Provider struct {
+	mu       sync.Mutex
+	payments map[string]*payment // ключ = ID платежа
+}
+
+// New создаёт mock провайдер
+func New() *Provider {
+	return &Provider{
+		payments: make(map[string]*payment),
+	}
+}
+
+// Authorize авторизует сумму или отказывает по способу оплаты
+func (p *Provider) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	switch req.Method {
+	case MethodInsufficientFunds:
+		return provider.Authorization{}, &provider.DeclineError{Code: provider.DeclineInsufficientFunds, Message: "insufficient funds"}
+	case MethodRisk:
+		return provider.Authorization{}, &provider.DeclineError{Code: provider.DeclineRisk, Message: "blocked by risk rules"}
+	case MethodDeclined:
+		return provider.Authorization{}, &provider.DeclineError{Code: provider.DeclineCard, Message: "card declined"}
+	case MethodUnavailable:
+		return provider.Authorization{}, provider.ErrUnavailable
+	}
+
+	paymentID := "mock_pay_" + req.OrderID
+	if req.IdempotencyKey != "" {
+		paymentID += "_" + req.IdempotencyKey
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.payments[paymentID]; !exists {
+		p.payments[paymentID] = &payment{authorized: req.Amount, refunds: make(map[string]bool)}
+	}
+	if req.Method == MethodSBP || req.Method == MethodInvoice {
+		// Отложенный платёж списывается провайдером при подтверждении; Refund проверяет сумму по captured
+		p.payments[paymentID].captured = req.Amount
+		return provider.Authorization{PaymentID: paymentID, Pending: true}, nil
+	}
+	return provider.Authorization{PaymentID: paymentID}, nil
+}
+
+// Capture списывает авторизованную сумму; повторный Capture ничего не меняет
+func (p *Provider) Capture(ctx context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pay, exists := p.payments[paymentID]
+	if !exists {
+		return fmt.Errorf("payment %s not found", paymentID)
+	}
+	if amount == 0 {
+		amount = pay.authorized
+	}
+	if amount > pay.authorized {
+		return fmt.Errorf("capture amount %d exceeds authorized %d", amount, pay.authorized)
+	}
+	if pay.voided {
+		return &provider.DeclineError{Code: provider.DeclineCard, Message: "authorization voided"}
+	}
+	if pay.captured == 0 {
+		pay.captured = amount
+	}
+	return nil
+}
+
+// Void отменяет авторизацию; повторный Void ничего не меняет, списанный платёж отменить нельзя
+func (p *Provider) Void(ctx context.Context, paymentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pay, exists := p.payments[paymentID]
+	if !exists {
+		return fmt.Errorf("payment %s not found", paymentID)
+	}
+	if pay.captured > 0 {
+		return &provider.DeclineError{Code: provider.DeclineCard, Message: "payment already captured"}
+	}
+	pay.voided = true
+	return nil
+}
+
+// Refund возвращает часть списанной суммы; ID возврата - mock_re_<idempotency_key>
+// Сумма возвратов сверяется со списанной, только если платёж прошёл через этот экземпляр:
+// после рестарта сервиса состояние потеряно, и возврат старого платежа принимается как есть
+func (p *Provider) Refund(ctx context.Context, req provider.RefundRequest) (provider.RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := req.IdempotencyKey
+	if key == "" {
+		key = req.PaymentID
+	}
+	result := provider.RefundResult{RefundID: "mock_re_" + key}
+
+	pay, exists := p.payments[req.PaymentID]
+	if !exists {
+		return result, nil
+	}
+	if pay.refunds[key] {
+		return result, nil
+	}
+	if pay.refunded+req.Amount > pay.captured {
+		return provider.RefundResult{}, &provider.DeclineError{Code: provider.DeclineCard, Message: "refund exceeds captured amount"}
+	}
+	pay.refunded += req.Amount
+	pay.refunds[key] = true
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// PaymentProvider - платёжный шлюз, через который проходят деньги
+// Оплата двухшаговая: Authorize блокирует сумму на счёте покупателя, Capture её списывает, Void - освобождает.
+// Service слой зависит от этого интерфейса; адаптеры: mock (детерминированный, для разработки и тестов)
+// и stripe (HTTP API в стиле Stripe), выбираются PAYMENT_PROVIDER
+type PaymentProvider interface {
+	// Authorize блокирует сумму; повтор с теми же OrderID и IdempotencyKey возвращает ту же авторизацию
+	// Возвращает *DeclineError при отказе и ErrUnavailable, если провайдер недоступен
+	Authorize(ctx context.Context, req AuthorizeRequest) (Authorization, error)
+
+	// Capture списывает авторизованную сумму
+	// amount - в минимальных единицах валюты; 0 - вся авторизованная сумма
+	Capture(ctx context.Context, paymentID string, amount int64) error
+
+	// Void отменяет авторизацию до списания: заблокированная сумма освобождается; повторный Void ничего не меняет
+	// Возвращает *DeclineError, если авторизацию уже нельзя отменить
+	Void(ctx context.Context, paymentID string) error
+
+	// Refund возвращает списанную сумму или её часть; повтор с тем же IdempotencyKey не возвращает деньги второй раз
+	// Возвращает *DeclineError, если провайдер отказал в возврате
+	Refund(ctx context.Context, req RefundRequest) (RefundResult, error)
+}
+
+// AuthorizeRequest - запрос авторизации платежа заказа
+type AuthorizeRequest struct {
+	OrderID  string // вместе с IdempotencyKey - ключ идемпотентности у провайдера
+	UserID   string
+	Amount   int64  // в минимальных единицах валюты
+	Currency string // код валюты ISO 4217
+	Method   string
+	// IdempotencyKey - ключ попытки оплаты заказа; новая попытка после отказа не получает ответ прежней
+	IdempotencyKey string
+}
+
+// Authorization - успешная авторизация
+type Authorization struct {
+	PaymentID string // ID платежа у провайдера, по нему выполняются Capture и Refund
+	// Pending - провайдер принял платёж, но покупатель ещё не подтвердил его (СБП, оплата по счёту):
+	// Capture не нужен, результат придёт PaymentEvent'ом через webhook
+	Pending bool
+}
+
+// RefundRequest - запрос возврата по платежу провайдера
+type RefundRequest struct {
+	PaymentID      string
+	Amount         int64 // в минимальных единицах валюты
+	Reason         string
+	IdempotencyKey string
+}
+
+// RefundResult - выполненный возврат
+type RefundResult struct {
+	RefundID string // ID возврата у провайдера
+}
+
+// DeclineCode - причина отказа провайдера
+type DeclineCode string
+
+const (
+	// DeclineInsufficientFunds - недостаточно средств
+	DeclineInsufficientFunds DeclineCode = "insufficient_funds"
+	// DeclineRisk - платёж отклонён антифрод-проверкой провайдера
+	DeclineRisk DeclineCode = "risk"
+	// DeclineCard - банк отклонил карту по другой причине
+	DeclineCard DeclineCode = "card_declined"
+)
+
+// PaymentEventType - тип асинхронного события платежа
+type PaymentEventType string
+
+const (
+	// PaymentEventSucceeded - отложенный платёж оплачен, деньги списаны
+	PaymentEventSucceeded PaymentEventType = "succeeded"
+	// PaymentEventFailed - отложенный платёж не прошёл (отказ банка, истёк срок оплаты счёта)
+	PaymentEventFailed PaymentEventType = "failed"
+)
+
+// PaymentEvent - событие платежа из webhook провайдера с уже проверенной подписью
+type PaymentEvent struct {
+	ID          string // ID события у провайдера; провайдер повторяет доставку с тем же ID
+	Type        PaymentEventType
+	PaymentID   string      // Authorization.PaymentID
+	DeclineCode DeclineCode // только для PaymentEventFailed
+	Message     string
+}
+
+// WebhookParser проверяет подпись webhook'а провайдера и разбирает его тело в PaymentEvent
+// События, которые сервис не обрабатывает, возвращаются с пустым Type
+type WebhookParser interface {
+	// ParseEvent возвращает ErrInvalidSignature, если подпись не сошлась или устарела
+	ParseEvent(payload []byte, header http.Header) (PaymentEvent, error)
+}
+
+// ErrInvalidSignature - подпись webhook'а не прошла проверку: запрос не от провайдера или повторён слишком поздно
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// DeclineError - провайдер отказал в операции; повтор того же запроса тоже получит отказ
+type DeclineError struct {
+	Code    DeclineCode
+	Message string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("provider declined (%s): %s", e.Code, e.Message)
+}
+
+// ErrUnavailable - провайдер недоступен или ответил временной ошибкой; операцию можно повторить
+var ErrUnavailable = errors.New("payment provider is unavailable")
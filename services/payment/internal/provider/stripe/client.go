@@ -0,0 +1,191 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+// DefaultAPIURL - адрес Stripe API
+const DefaultAPIURL = "https://api.stripe.com"
+
+// Client - адаптер платёжного шлюза с HTTP API в стиле Stripe (PaymentIntents с ручным capture и cancel, Refunds)
+// Запросы - form-urlencoded POST с секретным ключом в Authorization: Bearer и заголовком Idempotency-Key,
+// суммы - целые в минимальных единицах валюты (копейки, центы)
+type Client struct {
+	apiURL    string
+	secretKey string
+	client    *http.Client
+}
+
+// New создаёт адаптер
+// apiURL - адрес API без /v1 (DefaultAPIURL; в тестах - httptest сервер), пусто - DefaultAPIURL;
+// timeout ограничивает один HTTP вызов
+func New(apiURL, secretKey string, timeout time.Duration) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		apiURL:    strings.TrimRight(apiURL, "/"),
+		secretKey: secretKey,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// paymentIntent - поля ответа PaymentIntent, которые нужны адаптеру
+type paymentIntent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// refund - поля ответа Refund, которые нужны адаптеру
+type refund struct {
+	ID string `json:"id"`
+}
+
+// apiError - тело ответа с ошибкой
+type apiError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+// Authorize создаёт и подтверждает PaymentIntent с capture_method=manual: сумма блокируется, но не списывается
+// Ключ идемпотентности - order_id, поэтому повтор авторизации заказа вернёт тот же PaymentIntent
+func (c *Client) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10)) // Stripe тоже принимает минимальные единицы валюты
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("payment_method", req.Method)
+	form.Set("capture_method", "manual")
+	form.Set("confirm", "true")
+	form.Set("metadata[order_id]", req.OrderID)
+	form.Set("metadata[user_id]", req.UserID)
+
+	idempotencyKey := "authorize-" + req.OrderID
+	if req.IdempotencyKey != "" {
+		idempotencyKey += "-" + req.IdempotencyKey
+	}
+	var intent paymentIntent
+	if err := c.post(ctx, "/v1/payment_intents", idempotencyKey, form, &intent); err != nil {
+		return provider.Authorization{}, err
+	}
+	switch intent.Status {
+	case "requires_capture":
+		return provider.Authorization{PaymentID: intent.ID}, nil
+	case "processing", "requires_action":
+		// Способ оплаты с подтверждением покупателем: результат придёт событием payment_intent.*
+		return provider.Authorization{PaymentID: intent.ID, Pending: true}, nil
+	}
+	return provider.Authorization{}, &provider.DeclineError{
+		Code:    provider.DeclineCard,
+		Message: fmt.Sprintf("payment intent %s in status %s", intent.ID, intent.Status),
+	}
+}
+
+// Capture списывает авторизованную сумму PaymentIntent; amount = 0 - вся сумма
+func (c *Client) Capture(ctx context.Context, paymentID string, amount int64) error {
+	form := url.Values{}
+	if amount > 0 {
+		form.Set("amount_to_capture", strconv.FormatInt(amount, 10))
+	}
+	var intent paymentIntent
+	return c.post(ctx, "/v1/payment_intents/"+url.PathEscape(paymentID)+"/capture", "capture-"+paymentID, form, &intent)
+}
+
+// Void отменяет PaymentIntent с несписанной авторизацией (cancel): заблокированная сумма освобождается
+func (c *Client) Void(ctx context.Context, paymentID string) error {
+	var intent paymentIntent
+	return c.post(ctx, "/v1/payment_intents/"+url.PathEscape(paymentID)+"/cancel", "cancel-"+paymentID, url.Values{}, &intent)
+}
+
+// Refund создаёт возврат по PaymentIntent
+func (c *Client) Refund(ctx context.Context, req provider.RefundRequest) (provider.RefundResult, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.PaymentID)
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	if req.Reason != "" {
+		form.Set("metadata[reason]", req.Reason)
+	}
+
+	var r refund
+	if err := c.post(ctx, "/v1/refunds", req.IdempotencyKey, form, &r); err != nil {
+		return provider.RefundResult{}, err
+	}
+	return provider.RefundResult{RefundID: r.ID}, nil
+}
+
+// post выполняет запрос к API и декодирует ответ в result
+// Сетевые ошибки, 429 и 5xx - provider.ErrUnavailable (повтор с тем же ключом идемпотентности безопасен),
+// 402 и card_error - *provider.DeclineError, остальные 4xx - ошибка запроса
+func (c *Client) post(ctx context.Context, path, idempotencyKey string, form url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: %v", provider.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read response: %v", provider.ErrUnavailable, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: status %d", provider.ErrUnavailable, resp.StatusCode)
+	}
+
+	var apiErr apiError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return fmt.Errorf("provider API status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode == http.StatusPaymentRequired || apiErr.Error.Type == "card_error" {
+		return &provider.DeclineError{Code: declineCode(apiErr.Error.DeclineCode, apiErr.Error.Code), Message: apiErr.Error.Message}
+	}
+	return errors.New("provider API error: " + apiErr.Error.Message)
+}
+
+// declineCode сопоставляет decline_code/code Stripe с причиной отказа
+func declineCode(declineCode, code string) provider.DeclineCode {
+	switch {
+	case declineCode == "insufficient_funds" || code == "insufficient_funds":
+		return provider.DeclineInsufficientFunds
+	case declineCode == "fraudulent" || declineCode == "merchant_blacklist" || declineCode == "stolen_card" ||
+		declineCode == "lost_card":
+		return provider.DeclineRisk
+	default:
+		return provider.DeclineCard
+	}
+}
@@ -0,0 +1,118 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("authorize sends manual capture intent", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			got = r
+			_, _ = w.Write([]byte(`{"id": "pi_1", "status": "requires_capture"}`))
+		}))
+		defer srv.Close()
+
+		auth, err := New(srv.URL, "sk_test", time.Second).Authorize(ctx, provider.AuthorizeRequest{
+			OrderID: "order-1", Amount: 15050, Currency: "RUB", Method: "pm_card",
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "pi_1", auth.PaymentID)
+		require.Equal(t, "/v1/payment_intents", got.URL.Path)
+		require.Equal(t, "Bearer sk_test", got.Header.Get("Authorization"))
+		require.Equal(t, "authorize-order-1", got.Header.Get("Idempotency-Key"))
+		require.Equal(t, "15050", got.PostForm.Get("amount"))
+		require.Equal(t, "rub", got.PostForm.Get("currency"))
+		require.Equal(t, "manual", got.PostForm.Get("capture_method"))
+	})
+
+	t.Run("client idempotency key is part of authorize key", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			_, _ = w.Write([]byte(`{"id": "pi_2", "status": "requires_capture"}`))
+		}))
+		defer srv.Close()
+
+		_, err := New(srv.URL, "sk_test", time.Second).Authorize(ctx, provider.AuthorizeRequest{
+			OrderID: "order-1", Amount: 1, IdempotencyKey: "retry-2",
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "authorize-order-1-retry-2", got.Header.Get("Idempotency-Key"))
+	})
+
+	t.Run("card error is a decline", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusPaymentRequired)
+			_, _ = w.Write([]byte(`{"error": {"type": "card_error", "code": "card_declined", "decline_code": "insufficient_funds", "message": "no money"}}`))
+		}))
+		defer srv.Close()
+
+		_, err := New(srv.URL, "sk_test", time.Second).Authorize(ctx, provider.AuthorizeRequest{OrderID: "order-1", Amount: 1})
+
+		var declineErr *provider.DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, provider.DeclineInsufficientFunds, declineErr.Code)
+	})
+
+	t.Run("server error is retryable", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		err := New(srv.URL, "sk_test", time.Second).Capture(ctx, "pi_1", 0)
+
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+	})
+
+	t.Run("void cancels payment intent", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			_, _ = w.Write([]byte(`{"id": "pi_1", "status": "canceled"}`))
+		}))
+		defer srv.Close()
+
+		err := New(srv.URL, "sk_test", time.Second).Void(ctx, "pi_1")
+
+		require.NoError(t, err)
+		require.Equal(t, "/v1/payment_intents/pi_1/cancel", got.URL.Path)
+		require.Equal(t, "cancel-pi_1", got.Header.Get("Idempotency-Key"))
+	})
+
+	t.Run("refund uses idempotency key", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			got = r
+			_, _ = w.Write([]byte(`{"id": "re_1"}`))
+		}))
+		defer srv.Close()
+
+		result, err := New(srv.URL, "sk_test", time.Second).Refund(ctx, provider.RefundRequest{
+			PaymentID: "pi_1", Amount: 1000, IdempotencyKey: "rf_order-1",
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "re_1", result.RefundID)
+		require.Equal(t, "/v1/refunds", got.URL.Path)
+		require.Equal(t, "rf_order-1", got.Header.Get("Idempotency-Key"))
+		require.Equal(t, "pi_1", got.PostForm.Get("payment_intent"))
+		require.Equal(t, "1000", got.PostForm.Get("amount"))
+	})
+}
@@ -0,0 +1,136 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+// SignatureHeader - заголовок с подписью webhook'а: "t=<unix timestamp>,v1=<hex HMAC-SHA256>"
+const SignatureHeader = "Stripe-Signature"
+
+// WebhookParser реализует provider.WebhookParser для событий в формате Stripe
+// Подпись - HMAC-SHA256 с секретом endpoint'а от строки "<timestamp>.<тело>"; timestamp старше tolerance
+// отклоняется, чтобы перехваченный запрос нельзя было повторить позже
+type WebhookParser struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewWebhookParser создаёт парсер webhook'ов с секретом endpoint'а
+// tolerance - допустимое расхождение timestamp подписи с текущим временем; 0 - не проверяется
+func NewWebhookParser(secret string, tolerance time.Duration) *WebhookParser {
+	return &WebhookParser{
+		secret:    secret,
+		tolerance: tolerance,
+		now:       time.Now,
+	}
+}
+
+// event - поля события, которые нужны сервису
+type event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID               string `json:"id"`
+			LastPaymentError *struct {
+				Code        string `json:"code"`
+				DeclineCode string `json:"decline_code"`
+				Message     string `json:"message"`
+			} `json:"last_payment_error"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// ParseEvent проверяет подпись и разбирает событие
+// payment_intent.succeeded и payment_intent.payment_failed сопоставляются с provider.PaymentEvent*,
+// остальные типы событий возвращаются с пустым Type
+func (p *WebhookParser) ParseEvent(payload []byte, header http.Header) (provider.PaymentEvent, error) {
+	if err := p.verify(payload, header.Get(SignatureHeader)); err != nil {
+		return provider.PaymentEvent{}, err
+	}
+
+	var e event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return provider.PaymentEvent{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	result := provider.PaymentEvent{ID: e.ID, PaymentID: e.Data.Object.ID}
+	switch e.Type {
+	case "payment_intent.succeeded":
+		result.Type = provider.PaymentEventSucceeded
+	case "payment_intent.payment_failed":
+		result.Type = provider.PaymentEventFailed
+		result.DeclineCode = provider.DeclineCard
+		if paymentErr := e.Data.Object.LastPaymentError; paymentErr != nil {
+			result.DeclineCode = declineCode(paymentErr.DeclineCode, paymentErr.Code)
+			result.Message = paymentErr.Message
+		}
+	}
+	return result, nil
+}
+
+// verify сверяет подписи v1 из заголовка с ожидаемой; при ротации секрета провайдер присылает несколько v1
+func (p *WebhookParser) verify(payload []byte, signature string) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed %s header", provider.ErrInvalidSignature, SignatureHeader)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", provider.ErrInvalidSignature)
+	}
+	if age := p.now().Sub(time.Unix(unix, 0)); p.tolerance > 0 && (age > p.tolerance || age < -p.tolerance) {
+		return fmt.Errorf("%w: timestamp outside tolerance", provider.ErrInvalidSignature)
+	}
+
+	expected := computeSignature(p.secret, timestamp, payload)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", provider.ErrInvalidSignature)
+}
+
+// Sign возвращает значение SignatureHeader для тела webhook'а: так подписывает провайдер
+// Используется в тестах и для отправки событий mock провайдера вручную
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + computeSignature(secret, t, payload)
+}
+
+// computeSignature - hex(HMAC-SHA256(secret, timestamp + "." + payload))
+func computeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
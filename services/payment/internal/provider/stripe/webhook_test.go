@@ -0,0 +1,82 @@
+package stripe
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+func TestWebhookParser_ParseEvent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	parser := NewWebhookParser("whsec_test", 5*time.Minute)
+	parser.now = func() time.Time { return now }
+
+	signed := func(payload string, secret string, at time.Time) http.Header {
+		header := http.Header{}
+		header.Set(SignatureHeader, Sign(secret, at, []byte(payload)))
+		return header
+	}
+
+	t.Run("payment succeeded", func(t *testing.T) {
+		payload := `{"id": "evt_1", "type": "payment_intent.succeeded", "data": {"object": {"id": "pi_1", "status": "succeeded"}}}`
+
+		event, err := parser.ParseEvent([]byte(payload), signed(payload, "whsec_test", now))
+
+		require.NoError(t, err)
+		require.Equal(t, provider.PaymentEvent{ID: "evt_1", Type: provider.PaymentEventSucceeded, PaymentID: "pi_1"}, event)
+	})
+
+	t.Run("payment failed carries decline code", func(t *testing.T) {
+		payload := `{"id": "evt_2", "type": "payment_intent.payment_failed", "data": {"object": {"id": "pi_1",
+			"last_payment_error": {"code": "card_declined", "decline_code": "insufficient_funds", "message": "no money"}}}}`
+
+		event, err := parser.ParseEvent([]byte(payload), signed(payload, "whsec_test", now.Add(-time.Minute)))
+
+		require.NoError(t, err)
+		require.Equal(t, provider.PaymentEventFailed, event.Type)
+		require.Equal(t, provider.DeclineInsufficientFunds, event.DeclineCode)
+		require.Equal(t, "no money", event.Message)
+	})
+
+	t.Run("other event types have empty type", func(t *testing.T) {
+		payload := `{"id": "evt_3", "type": "charge.refunded", "data": {"object": {"id": "ch_1"}}}`
+
+		event, err := parser.ParseEvent([]byte(payload), signed(payload, "whsec_test", now))
+
+		require.NoError(t, err)
+		require.Empty(t, event.Type)
+	})
+
+	t.Run("one of several signatures matches", func(t *testing.T) {
+		payload := `{"id": "evt_1", "type": "payment_intent.succeeded", "data": {"object": {"id": "pi_1"}}}`
+		header := http.Header{}
+		header.Set(SignatureHeader, Sign("whsec_test", now, []byte(payload))+",v1=deadbeef")
+
+		_, err := parser.ParseEvent([]byte(payload), header)
+
+		require.NoError(t, err)
+	})
+
+	payload := `{"id": "evt_1", "type": "payment_intent.succeeded", "data": {"object": {"id": "pi_1"}}}`
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{name: "missing header", header: http.Header{}},
+		{name: "wrong secret", header: signed(payload, "whsec_other", now)},
+		{name: "timestamp too old", header: signed(payload, "whsec_test", now.Add(-10*time.Minute))},
+		{name: "timestamp in the future", header: signed(payload, "whsec_test", now.Add(10*time.Minute))},
+		{name: "body modified", header: signed(payload+" ", "whsec_test", now)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ParseEvent([]byte(payload), tt.header)
+
+			require.ErrorIs(t, err, provider.ErrInvalidSignature)
+		})
+	}
+}
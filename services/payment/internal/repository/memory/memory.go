@@ -2,48 +2,271 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
 )
 
-// MemoryRepository реализует PaymentRepository используя in-memory хранилище
-// Используется для разработки и тестирования
-// В production будет заменён на реализацию с БД
+// MemoryRepository реализует PaymentRepository, WalletRepository и PaymentMethodRepository используя in-memory хранилище
+// Используется в unit-тестах; сервис хранит транзакции в PostgreSQL (repository/postgres)
 type MemoryRepository struct {
-	mu          sync.RWMutex
-	transactions map[string]repository.Transaction // ключ = orderID
+	mu           sync.RWMutex
+	transactions map[string][]repository.Transaction // ключ = orderID, попытки оплаты в порядке сохранения
+	outbox       []repository.OutboxEvent
+
+	wallets          map[walletKey]repository.Wallet
+	walletOperations map[string]repository.WalletOperation // ключ = OperationID
+	walletEntries    []repository.WalletEntry
+
+	paymentMethods map[string]repository.PaymentMethod // ключ = ID
 }
 
 // NewMemoryRepository создаёт новый in-memory репозиторий
 func NewMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
-		transactions: make(map[string]repository.Transaction),
+		transactions:     make(map[string][]repository.Transaction),
+		wallets:          make(map[walletKey]repository.Wallet),
+		walletOperations: make(map[string]repository.WalletOperation),
+		paymentMethods:   make(map[string]repository.PaymentMethod),
 	}
 }
 
-// GetByOrderID получает транзакцию по orderID из памяти
+// GetByOrderID получает последнюю транзакцию заказа из памяти
 // Защищён мьютексом для безопасного доступа из разных горутин
 func (r *MemoryRepository) GetByOrderID(ctx context.Context, orderID string) (repository.Transaction, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	tx, exists := r.transactions[orderID]
-	if !exists {
+	attempts := r.transactions[orderID]
+	if len(attempts) == 0 {
 		return repository.Transaction{}, repository.ErrNotFound
 	}
 
-	return tx, nil
+	return attempts[len(attempts)-1], nil
+}
+
+// GetByIdempotencyKey ищет транзакцию заказа с ключом идемпотентности среди его попыток
+func (r *MemoryRepository) GetByIdempotencyKey(ctx context.Context, orderID, idempotencyKey string) (repository.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, tx := range r.transactions[orderID] {
+		if tx.IdempotencyKey == idempotencyKey {
+			return tx, nil
+		}
+	}
+	return repository.Transaction{}, repository.ErrNotFound
 }
 
 // Save сохраняет транзакцию в памяти
+// Как и уникальные индексы в PostgreSQL, отклоняет с ErrAlreadyExists повтор ключа идемпотентности заказа
+// и новую попытку, пока у заказа есть неотклонённая транзакция
 // Защищён мьютексом для безопасного доступа из разных горутин
 func (r *MemoryRepository) Save(ctx context.Context, tx repository.Transaction) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.transactions[tx.OrderID] = tx
+	return r.save(tx)
+}
+
+// SaveWithOutbox сохраняет транзакцию и, если она сохранена, событие outbox
+func (r *MemoryRepository) SaveWithOutbox(ctx context.Context, tx repository.Transaction, event repository.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.save(tx); err != nil {
+		return err
+	}
+	r.outbox = append(r.outbox, event)
+	return nil
+}
+
+// save сохраняет транзакцию; вызывается под r.mu
+func (r *MemoryRepository) save(tx repository.Transaction) error {
+	for _, existing := range r.transactions[tx.OrderID] {
+		if existing.IdempotencyKey == tx.IdempotencyKey || !repository.IsDeclined(existing.Status) {
+			return repository.ErrAlreadyExists
+		}
+	}
+	r.transactions[tx.OrderID] = append(r.transactions[tx.OrderID], tx)
+	return nil
+}
+
+// GetByProviderPaymentID ищет транзакцию по ID платежа у провайдера перебором
+func (r *MemoryRepository) GetByProviderPaymentID(ctx context.Context, providerPaymentID string) (repository.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, attempts := range r.transactions {
+		for _, tx := range attempts {
+			if providerPaymentID != "" && tx.ProviderPaymentID == providerPaymentID {
+				return tx, nil
+			}
+		}
+	}
+	return repository.Transaction{}, repository.ErrNotFound
+}
+
+// UpdateStatus меняет статус транзакции, если текущий статус равен from
+func (r *MemoryRepository) UpdateStatus(ctx context.Context, transactionID, from, to, declineReason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateStatus(transactionID, from, to, declineReason)
+}
+
+// UpdateStatusWithOutbox меняет статус и, если он изменён, сохраняет событие outbox
+func (r *MemoryRepository) UpdateStatusWithOutbox(ctx context.Context, transactionID, from, to, declineReason string, event repository.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.updateStatus(transactionID, from, to, declineReason); err != nil {
+		return err
+	}
+	r.outbox = append(r.outbox, event)
+	return nil
+}
+
+// CaptureWithOutbox переводит транзакцию в captured со списанной суммой и, если статус изменён, сохраняет событие outbox
+func (r *MemoryRepository) CaptureWithOutbox(ctx context.Context, transactionID, from string, capturedAmount int64, event repository.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, err := r.find(transactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != from {
+		return repository.ErrStatusConflict
+	}
+	tx.Status, tx.DeclineReason, tx.CapturedAmount = repository.StatusCaptured, "", capturedAmount
+	r.outbox = append(r.outbox, event)
+	return nil
+}
+
+// addRefunded увеличивает RefundedAmount транзакции в StatusCaptured на amount, если не превышена списанная сумма;
+// транзакция, возвращённая целиком, переходит в StatusRefunded. Вызывается RefundRepository
+func (r *MemoryRepository) addRefunded(transactionID string, amount int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, err := r.find(transactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != repository.StatusCaptured || tx.RefundedAmount+amount > tx.CapturedAmount {
+		return repository.ErrRefundLimitExceeded
+	}
+	tx.RefundedAmount += amount
+	if tx.RefundedAmount == tx.CapturedAmount {
+		tx.Status = repository.StatusRefunded
+	}
+	return nil
+}
+
+// OutboxEvents возвращает сохранённые события outbox в порядке записи
+func (r *MemoryRepository) OutboxEvents() []repository.OutboxEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]repository.OutboxEvent(nil), r.outbox...)
+}
+
+// updateStatus меняет статус транзакции compare-and-set'ом; вызывается под r.mu
+func (r *MemoryRepository) updateStatus(transactionID, from, to, declineReason string) error {
+	tx, err := r.find(transactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != from {
+		return repository.ErrStatusConflict
+	}
+	tx.Status = to
+	tx.DeclineReason = declineReason
 	return nil
 }
 
+// find возвращает указатель на сохранённую транзакцию для изменения; вызывается под r.mu
+func (r *MemoryRepository) find(transactionID string) (*repository.Transaction, error) {
+	for _, attempts := range r.transactions {
+		for i := range attempts {
+			if attempts[i].TransactionID == transactionID {
+				return &attempts[i], nil
+			}
+		}
+	}
+	return nil, repository.ErrNotFound
+}
 
+// GetByTransactionID ищет транзакцию по ID перебором
+func (r *MemoryRepository) GetByTransactionID(ctx context.Context, transactionID string) (repository.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, attempts := range r.transactions {
+		for _, tx := range attempts {
+			if tx.TransactionID == transactionID {
+				return tx, nil
+			}
+		}
+	}
+	return repository.Transaction{}, repository.ErrNotFound
+}
+
+// ListByUserID возвращает страницу транзакций пользователя в порядке PostgreSQL реализации
+func (r *MemoryRepository) ListByUserID(ctx context.Context, userID string, after *repository.TransactionCursor, limit int) ([]repository.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var transactions []repository.Transaction
+	for _, attempts := range r.transactions {
+		for _, tx := range attempts {
+			if tx.UserID != userID {
+				continue
+			}
+			if after != nil && !before(tx, *after) {
+				continue
+			}
+			transactions = append(transactions, tx)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return before(transactions[j], repository.TransactionCursor{
+			CreatedAt:     transactions[i].CreatedAt,
+			TransactionID: transactions[i].TransactionID,
+		})
+	})
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+	return transactions, nil
+}
+
+// GetUserActivity считает транзакции пользователя, созданные не раньше since
+func (r *MemoryRepository) GetUserActivity(ctx context.Context, userID string, since int64) (repository.UserActivity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var activity repository.UserActivity
+	for _, attempts := range r.transactions {
+		for _, tx := range attempts {
+			if tx.UserID != userID || tx.CreatedAt < since {
+				continue
+			}
+			activity.Payments++
+			if !repository.IsDeclined(tx.Status) {
+				activity.Amount += tx.Amount
+			}
+		}
+	}
+	return activity, nil
+}
+
+// before сообщает, идёт ли транзакция после cursor в выдаче (старше по CreatedAt, затем по TransactionID)
+func before(tx repository.Transaction, cursor repository.TransactionCursor) bool {
+	if tx.CreatedAt != cursor.CreatedAt {
+		return tx.CreatedAt < cursor.CreatedAt
+	}
+	return tx.TransactionID < cursor.TransactionID
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// CreatePaymentMethod сохраняет способ оплаты; токен у пользователя сохраняется один раз
+func (r *MemoryRepository) CreatePaymentMethod(ctx context.Context, method repository.PaymentMethod) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.paymentMethods {
+		if existing.UserID == method.UserID && existing.Token == method.Token {
+			return repository.ErrPaymentMethodExists
+		}
+	}
+	r.paymentMethods[method.ID] = method
+	return nil
+}
+
+// GetPaymentMethod возвращает способ оплаты по ID
+func (r *MemoryRepository) GetPaymentMethod(ctx context.Context, methodID string) (repository.PaymentMethod, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	method, exists := r.paymentMethods[methodID]
+	if !exists {
+		return repository.PaymentMethod{}, repository.ErrPaymentMethodNotFound
+	}
+	return method, nil
+}
+
+// GetPaymentMethodByToken возвращает способ оплаты пользователя по токену
+func (r *MemoryRepository) GetPaymentMethodByToken(ctx context.Context, userID, token string) (repository.PaymentMethod, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, method := range r.paymentMethods {
+		if method.UserID == userID && method.Token == token {
+			return method, nil
+		}
+	}
+	return repository.PaymentMethod{}, repository.ErrPaymentMethodNotFound
+}
+
+// ListPaymentMethods возвращает способы оплаты пользователя, новые первыми
+func (r *MemoryRepository) ListPaymentMethods(ctx context.Context, userID string) ([]repository.PaymentMethod, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var methods []repository.PaymentMethod
+	for _, method := range r.paymentMethods {
+		if method.UserID == userID {
+			methods = append(methods, method)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		if !methods[i].CreatedAt.Equal(methods[j].CreatedAt) {
+			return methods[i].CreatedAt.After(methods[j].CreatedAt)
+		}
+		return methods[i].ID > methods[j].ID
+	})
+	return methods, nil
+}
+
+// DeletePaymentMethod удаляет способ оплаты пользователя; чужой способ оплаты не удаляется
+func (r *MemoryRepository) DeletePaymentMethod(ctx context.Context, userID, methodID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	method, exists := r.paymentMethods[methodID]
+	if !exists || method.UserID != userID {
+		return repository.ErrPaymentMethodNotFound
+	}
+	delete(r.paymentMethods, methodID)
+	return nil
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// RefundRepository реализует repository.RefundRepository используя in-memory хранилище
+// Возвращённая сумма учитывается в транзакциях payments, как в PostgreSQL - в той же операции, что и возврат
+type RefundRepository struct {
+	mu       sync.RWMutex
+	refunds  map[string]repository.Refund // ключ = RefundID
+	payments *MemoryRepository
+}
+
+// NewRefundRepository создаёт новый in-memory репозиторий возвратов транзакций payments
+func NewRefundRepository(payments *MemoryRepository) *RefundRepository {
+	return &RefundRepository{
+		refunds:  make(map[string]repository.Refund),
+		payments: payments,
+	}
+}
+
+// GetRefund возвращает возврат по ID
+func (r *RefundRepository) GetRefund(ctx context.Context, refundID string) (repository.Refund, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	refund, exists := r.refunds[refundID]
+	if !exists {
+		return repository.Refund{}, repository.ErrRefundNotFound
+	}
+	return refund, nil
+}
+
+// CreateRefund сохраняет возврат и увеличивает RefundedAmount транзакции, если остаток позволяет
+func (r *RefundRepository) CreateRefund(ctx context.Context, refund repository.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.refunds[refund.RefundID]; exists {
+		return repository.ErrRefundAlreadyExists
+	}
+	if err := r.payments.addRefunded(refund.TransactionID, refund.Amount); err != nil {
+		return err
+	}
+	r.refunds[refund.RefundID] = refund
+	return nil
+}
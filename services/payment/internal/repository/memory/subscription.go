@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// SubscriptionRepository реализует repository.SubscriptionRepository используя in-memory хранилище
+type SubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]repository.Subscription // ключ = ID подписки
+}
+
+// NewSubscriptionRepository создаёт новый in-memory репозиторий подписок
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subscriptions: make(map[string]repository.Subscription),
+	}
+}
+
+// CreateSubscription сохраняет подписку в памяти
+func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, subscription repository.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+// GetSubscription возвращает подписку по ID
+func (r *SubscriptionRepository) GetSubscription(ctx context.Context, subscriptionID string) (repository.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, exists := r.subscriptions[subscriptionID]
+	if !exists {
+		return repository.Subscription{}, repository.ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// ListDueSubscriptions возвращает активные подписки, срок списания которых наступил, самые просроченные первыми
+func (r *SubscriptionRepository) ListDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]repository.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]repository.Subscription, 0)
+	for _, subscription := range r.subscriptions {
+		if subscription.Status == repository.SubscriptionStatusActive && !subscription.NextChargeAt.After(now) {
+			due = append(due, subscription)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextChargeAt.Before(due[j].NextChargeAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// AdvanceSubscription переводит подписку на следующий период, если она всё ещё на периоде period
+func (r *SubscriptionRepository) AdvanceSubscription(ctx context.Context, subscriptionID string, period int64, nextChargeAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, exists := r.subscriptions[subscriptionID]
+	if !exists {
+		return repository.ErrSubscriptionNotFound
+	}
+	if subscription.Period != period {
+		return repository.ErrSubscriptionConflict
+	}
+	subscription.Period = period + 1
+	subscription.NextChargeAt = nextChargeAt
+	r.subscriptions[subscriptionID] = subscription
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// walletKey - ключ кошелька: пользователь и валюта
+type walletKey struct {
+	userID   string
+	currency string
+}
+
+// GetWallet возвращает кошелёк пользователя в валюте currency
+func (r *MemoryRepository) GetWallet(ctx context.Context, userID, currency string) (repository.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, exists := r.wallets[walletKey{userID: userID, currency: currency}]
+	if !exists {
+		return repository.Wallet{}, repository.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+// GetWalletOperation возвращает операцию по кошельку по её ID
+func (r *MemoryRepository) GetWalletOperation(ctx context.Context, operationID string) (repository.WalletOperation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, exists := r.walletOperations[operationID]
+	if !exists {
+		return repository.WalletOperation{}, repository.ErrWalletOperationNotFound
+	}
+	return op, nil
+}
+
+// CreditWallet зачисляет сумму на кошелёк, создавая его при первом зачислении
+func (r *MemoryRepository) CreditWallet(ctx context.Context, op repository.WalletOperation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.walletOperations[op.OperationID]; exists {
+		return repository.ErrWalletOperationExists
+	}
+	key := walletKey{userID: op.UserID, currency: op.Currency}
+	wallet := r.wallets[key]
+	wallet.UserID, wallet.Currency = op.UserID, op.Currency
+	wallet.Balance += op.Amount
+	wallet.UpdatedAt = op.CreatedAt
+	r.wallets[key] = wallet
+	r.applyWalletOperation(op)
+	return nil
+}
+
+// PayFromWallet списывает сумму с кошелька и сохраняет транзакцию с событием outbox; при ошибке ничего не меняет
+func (r *MemoryRepository) PayFromWallet(ctx context.Context, op repository.WalletOperation, tx repository.Transaction, event repository.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := walletKey{userID: op.UserID, currency: op.Currency}
+	wallet, exists := r.wallets[key]
+	if !exists || wallet.Balance < op.Amount {
+		return repository.ErrInsufficientBalance
+	}
+	if _, exists := r.walletOperations[op.OperationID]; exists {
+		return repository.ErrWalletOperationExists
+	}
+	if err := r.save(tx); err != nil {
+		return err
+	}
+	r.outbox = append(r.outbox, event)
+	wallet.Balance -= op.Amount
+	wallet.UpdatedAt = op.CreatedAt
+	r.wallets[key] = wallet
+	r.applyWalletOperation(op)
+	return nil
+}
+
+// WalletEntries возвращает журнал проводок в порядке записи
+func (r *MemoryRepository) WalletEntries() []repository.WalletEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]repository.WalletEntry(nil), r.walletEntries...)
+}
+
+// applyWalletOperation сохраняет операцию и её проводки; вызывается под r.mu
+func (r *MemoryRepository) applyWalletOperation(op repository.WalletOperation) {
+	r.walletOperations[op.OperationID] = op
+	r.walletEntries = append(r.walletEntries, repository.WalletEntries(op)...)
+}
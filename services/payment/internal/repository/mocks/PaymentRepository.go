@@ -14,6 +14,52 @@ type PaymentRepository struct {
 	mock.Mock
 }
 
+// CaptureWithOutbox provides a mock function with given fields: ctx, transactionID, from, capturedAmount, event
+func (_m *PaymentRepository) CaptureWithOutbox(ctx context.Context, transactionID string, from string, capturedAmount int64, event repository.OutboxEvent) error {
+	ret := _m.Called(ctx, transactionID, from, capturedAmount, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CaptureWithOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, repository.OutboxEvent) error); ok {
+		r0 = rf(ctx, transactionID, from, capturedAmount, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByIdempotencyKey provides a mock function with given fields: ctx, orderID, idempotencyKey
+func (_m *PaymentRepository) GetByIdempotencyKey(ctx context.Context, orderID string, idempotencyKey string) (repository.Transaction, error) {
+	ret := _m.Called(ctx, orderID, idempotencyKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdempotencyKey")
+	}
+
+	var r0 repository.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (repository.Transaction, error)); ok {
+		return rf(ctx, orderID, idempotencyKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) repository.Transaction); ok {
+		r0 = rf(ctx, orderID, idempotencyKey)
+	} else {
+		r0 = ret.Get(0).(repository.Transaction)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, orderID, idempotencyKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByOrderID provides a mock function with given fields: ctx, orderID
 func (_m *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) (repository.Transaction, error) {
 	ret := _m.Called(ctx, orderID)
@@ -42,6 +88,120 @@ func (_m *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) (
 	return r0, r1
 }
 
+// GetByProviderPaymentID provides a mock function with given fields: ctx, providerPaymentID
+func (_m *PaymentRepository) GetByProviderPaymentID(ctx context.Context, providerPaymentID string) (repository.Transaction, error) {
+	ret := _m.Called(ctx, providerPaymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByProviderPaymentID")
+	}
+
+	var r0 repository.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (repository.Transaction, error)); ok {
+		return rf(ctx, providerPaymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) repository.Transaction); ok {
+		r0 = rf(ctx, providerPaymentID)
+	} else {
+		r0 = ret.Get(0).(repository.Transaction)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, providerPaymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByTransactionID provides a mock function with given fields: ctx, transactionID
+func (_m *PaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (repository.Transaction, error) {
+	ret := _m.Called(ctx, transactionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTransactionID")
+	}
+
+	var r0 repository.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (repository.Transaction, error)); ok {
+		return rf(ctx, transactionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) repository.Transaction); ok {
+		r0 = rf(ctx, transactionID)
+	} else {
+		r0 = ret.Get(0).(repository.Transaction)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserActivity provides a mock function with given fields: ctx, userID, since
+func (_m *PaymentRepository) GetUserActivity(ctx context.Context, userID string, since int64) (repository.UserActivity, error) {
+	ret := _m.Called(ctx, userID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserActivity")
+	}
+
+	var r0 repository.UserActivity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (repository.UserActivity, error)); ok {
+		return rf(ctx, userID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) repository.UserActivity); ok {
+		r0 = rf(ctx, userID, since)
+	} else {
+		r0 = ret.Get(0).(repository.UserActivity)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByUserID provides a mock function with given fields: ctx, userID, after, limit
+func (_m *PaymentRepository) ListByUserID(ctx context.Context, userID string, after *repository.TransactionCursor, limit int) ([]repository.Transaction, error) {
+	ret := _m.Called(ctx, userID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUserID")
+	}
+
+	var r0 []repository.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *repository.TransactionCursor, int) ([]repository.Transaction, error)); ok {
+		return rf(ctx, userID, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *repository.TransactionCursor, int) []repository.Transaction); ok {
+		r0 = rf(ctx, userID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *repository.TransactionCursor, int) error); ok {
+		r1 = rf(ctx, userID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, tx
 func (_m *PaymentRepository) Save(ctx context.Context, tx repository.Transaction) error {
 	ret := _m.Called(ctx, tx)
@@ -60,6 +220,60 @@ func (_m *PaymentRepository) Save(ctx context.Context, tx repository.Transaction
 	return r0
 }
 
+// SaveWithOutbox provides a mock function with given fields: ctx, tx, event
+func (_m *PaymentRepository) SaveWithOutbox(ctx context.Context, tx repository.Transaction, event repository.OutboxEvent) error {
+	ret := _m.Called(ctx, tx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWithOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.Transaction, repository.OutboxEvent) error); ok {
+		r0 = rf(ctx, tx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, transactionID, from, to, declineReason
+func (_m *PaymentRepository) UpdateStatus(ctx context.Context, transactionID string, from string, to string, declineReason string) error {
+	ret := _m.Called(ctx, transactionID, from, to, declineReason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, transactionID, from, to, declineReason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatusWithOutbox provides a mock function with given fields: ctx, transactionID, from, to, declineReason, event
+func (_m *PaymentRepository) UpdateStatusWithOutbox(ctx context.Context, transactionID string, from string, to string, declineReason string, event repository.OutboxEvent) error {
+	ret := _m.Called(ctx, transactionID, from, to, declineReason, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatusWithOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, repository.OutboxEvent) error); ok {
+		r0 = rf(ctx, transactionID, from, to, declineReason, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewPaymentRepository creates a new instance of PaymentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPaymentRepository(t interface {
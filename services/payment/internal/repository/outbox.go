@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxEvent - событие результата платежа в outbox (payment.succeeded, payment.failed)
+// Пишется в одной транзакции БД со статусом платежа (SaveWithOutbox, UpdateStatusWithOutbox), поэтому
+// падение сервиса между сохранением платежа и публикацией не теряет событие: его отправит dispatcher
+type OutboxEvent struct {
+	EventID     string
+	EventType   string // топик Kafka dispatcher выбирает по типу события
+	OccurredAt  time.Time
+	AggregateID string            // order_id, ключ сообщения Kafka
+	Payload     []byte            // JSON payload
+	Attempts    int               // неудачные попытки публикации
+	Headers     map[string]string // trace context и request_id на момент сохранения события
+}
+
+// OutboxRepository - очередь неотправленных событий outbox
+type OutboxRepository interface {
+	// GetPendingOutboxEvents возвращает до limit неотправленных событий, старые первыми
+	GetPendingOutboxEvents(ctx context.Context, limit int) ([]OutboxEvent, error)
+
+	// MarkOutboxEventSent отмечает событие отправленным
+	MarkOutboxEventSent(ctx context.Context, eventID string) error
+
+	// MarkOutboxEventFailed записывает неудачную попытку публикации и ошибку
+	// Событие остаётся неотправленным и уходит на следующем проходе dispatcher'а
+	MarkOutboxEventFailed(ctx context.Context, eventID, errMsg string) error
+}
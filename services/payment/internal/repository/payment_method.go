@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PaymentMethod - сохранённый способ оплаты пользователя: токен провайдера вместо реквизитов карты
+// Token передаётся провайдеру как способ оплаты и наружу не отдаётся; Brand и Last4 - для показа пользователю
+type PaymentMethod struct {
+	ID        string
+	UserID    string // user_id пользователя IAM
+	Token     string // токен у платёжного провайдера (например, pm_... у Stripe)
+	Brand     string // платёжная система: visa, mastercard, mir, ...
+	Last4     string // последние 4 цифры номера карты
+	CreatedAt time.Time
+}
+
+// PaymentMethodRepository определяет интерфейс для хранения сохранённых способов оплаты
+type PaymentMethodRepository interface {
+	// CreatePaymentMethod сохраняет способ оплаты
+	// Возвращает ErrPaymentMethodExists, если у пользователя уже сохранён этот токен
+	CreatePaymentMethod(ctx context.Context, method PaymentMethod) error
+
+	// GetPaymentMethod возвращает способ оплаты по ID
+	// Возвращает ErrPaymentMethodNotFound, если способа оплаты нет
+	GetPaymentMethod(ctx context.Context, methodID string) (PaymentMethod, error)
+
+	// GetPaymentMethodByToken возвращает способ оплаты пользователя с токеном token
+	// Возвращает ErrPaymentMethodNotFound, если такого нет
+	GetPaymentMethodByToken(ctx context.Context, userID, token string) (PaymentMethod, error)
+
+	// ListPaymentMethods возвращает способы оплаты пользователя, новые первыми
+	ListPaymentMethods(ctx context.Context, userID string) ([]PaymentMethod, error)
+
+	// DeletePaymentMethod удаляет способ оплаты methodID пользователя userID
+	// Возвращает ErrPaymentMethodNotFound, если у пользователя такого нет
+	DeletePaymentMethod(ctx context.Context, userID, methodID string) error
+}
+
+// ErrPaymentMethodNotFound возвращается, когда сохранённый способ оплаты не найден
+var ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+// ErrPaymentMethodExists возвращается, когда токен уже сохранён у пользователя
+var ErrPaymentMethodExists = errors.New("payment method already exists")
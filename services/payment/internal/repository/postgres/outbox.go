@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+
+	platformobservability "github.com/shestoi/GoBigTech/platform/observability"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// insertOutboxEvent добавляет неотправленное событие в outbox через q - транзакцию БД вместе со сменой статуса платежа
+func insertOutboxEvent(ctx context.Context, q querier, event repository.OutboxEvent) error {
+	// Сохраняем trace context и request_id вместе с событием: dispatcher передаст их в заголовки Kafka
+	headers, err := json.Marshal(platformobservability.MessageHeaders(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx,
+		`INSERT INTO payment_outbox_events (event_id, event_type, occurred_at, aggregate_id, payload, headers)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.EventID, event.EventType, event.OccurredAt, event.AggregateID, event.Payload, headers)
+	return err
+}
+
+// GetPendingOutboxEvents получает неотправленные события по частичному индексу (sent_at IS NULL)
+func (r *Repository) GetPendingOutboxEvents(ctx context.Context, limit int) ([]repository.OutboxEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT event_id, event_type, occurred_at, aggregate_id, payload, attempts, headers
+		 FROM payment_outbox_events
+		 WHERE sent_at IS NULL
+		 ORDER BY created_at ASC
+		 LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxEvents(rows)
+}
+
+// MarkOutboxEventSent отмечает событие отправленным
+func (r *Repository) MarkOutboxEventSent(ctx context.Context, eventID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE payment_outbox_events SET sent_at = NOW(), last_error = NULL WHERE event_id = $1`,
+		eventID)
+	return err
+}
+
+// MarkOutboxEventFailed увеличивает attempts и сохраняет ошибку публикации
+func (r *Repository) MarkOutboxEventFailed(ctx context.Context, eventID, errMsg string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE payment_outbox_events SET attempts = attempts + 1, last_error = $2 WHERE event_id = $1`,
+		eventID, errMsg)
+	return err
+}
+
+// scanOutboxEvents читает строки payment_outbox_events в порядке колонок GetPendingOutboxEvents
+func scanOutboxEvents(rows pgx.Rows) ([]repository.OutboxEvent, error) {
+	events := make([]repository.OutboxEvent, 0)
+	for rows.Next() {
+		var (
+			event   repository.OutboxEvent
+			headers []byte
+		)
+		if err := rows.Scan(&event.EventID, &event.EventType, &event.OccurredAt, &event.AggregateID,
+			&event.Payload, &event.Attempts, &headers); err != nil {
+			return nil, err
+		}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &event.Headers); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// paymentMethodColumns - колонки payment_methods в порядке scanPaymentMethod
+const paymentMethodColumns = `id, user_id, token, brand, last4, created_at`
+
+// CreatePaymentMethod сохраняет способ оплаты; повтор токена у пользователя упирается в UNIQUE (user_id, token)
+func (r *Repository) CreatePaymentMethod(ctx context.Context, method repository.PaymentMethod) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO payment_methods (`+paymentMethodColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		method.ID, method.UserID, method.Token, method.Brand, method.Last4, method.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repository.ErrPaymentMethodExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetPaymentMethod возвращает способ оплаты по ID
+func (r *Repository) GetPaymentMethod(ctx context.Context, methodID string) (repository.PaymentMethod, error) {
+	return scanPaymentMethod(r.pool.QueryRow(ctx,
+		`SELECT `+paymentMethodColumns+`
+		 FROM payment_methods
+		 WHERE id = $1`,
+		methodID))
+}
+
+// GetPaymentMethodByToken возвращает способ оплаты пользователя по токену
+func (r *Repository) GetPaymentMethodByToken(ctx context.Context, userID, token string) (repository.PaymentMethod, error) {
+	return scanPaymentMethod(r.pool.QueryRow(ctx,
+		`SELECT `+paymentMethodColumns+`
+		 FROM payment_methods
+		 WHERE user_id = $1 AND token = $2`,
+		userID, token))
+}
+
+// ListPaymentMethods возвращает способы оплаты пользователя по индексу (user_id, created_at), новые первыми
+func (r *Repository) ListPaymentMethods(ctx context.Context, userID string) ([]repository.PaymentMethod, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+paymentMethodColumns+`
+		 FROM payment_methods
+		 WHERE user_id = $1
+		 ORDER BY created_at DESC, id DESC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var methods []repository.PaymentMethod
+	for rows.Next() {
+		method, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+	return methods, rows.Err()
+}
+
+// DeletePaymentMethod удаляет способ оплаты пользователя; чужой способ оплаты не удаляется
+func (r *Repository) DeletePaymentMethod(ctx context.Context, userID, methodID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM payment_methods WHERE id = $1 AND user_id = $2`,
+		methodID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrPaymentMethodNotFound
+	}
+	return nil
+}
+
+// scanPaymentMethod читает строку с колонками paymentMethodColumns
+func scanPaymentMethod(row pgx.Row) (repository.PaymentMethod, error) {
+	var method repository.PaymentMethod
+	err := row.Scan(&method.ID, &method.UserID, &method.Token, &method.Brand, &method.Last4, &method.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.PaymentMethod{}, repository.ErrPaymentMethodNotFound
+		}
+		return repository.PaymentMethod{}, err
+	}
+	return method, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// GetRefund возвращает возврат по ID
+func (r *Repository) GetRefund(ctx context.Context, refundID string) (repository.Refund, error) {
+	var refund repository.Refund
+	err := r.pool.QueryRow(ctx,
+		`SELECT refund_id, order_id, transaction_id, amount, currency, reason, provider_refund_id, wallet_operation_id, created_at
+		 FROM payment_refunds
+		 WHERE refund_id = $1`,
+		refundID).Scan(&refund.RefundID, &refund.OrderID, &refund.TransactionID, &refund.Amount, &refund.Currency,
+		&refund.Reason, &refund.ProviderRefundID, &refund.WalletOperationID, &refund.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Refund{}, repository.ErrRefundNotFound
+		}
+		return repository.Refund{}, err
+	}
+	return refund, nil
+}
+
+// CreateRefund сохраняет возврат и увеличивает refunded_amount транзакции в одной транзакции БД
+// Повтор refund_id упирается в первичный ключ до изменения транзакции; UPDATE блокирует строку транзакции,
+// поэтому конкурентные частичные возвраты проверяют остаток по очереди и не возвращают больше списанного
+func (r *Repository) CreateRefund(ctx context.Context, refund repository.Refund) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	_, err = dbTx.Exec(ctx,
+		`INSERT INTO payment_refunds (refund_id, order_id, transaction_id, amount, currency, reason, provider_refund_id, wallet_operation_id, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		refund.RefundID, refund.OrderID, refund.TransactionID, refund.Amount, refund.Currency, refund.Reason,
+		refund.ProviderRefundID, refund.WalletOperationID, refund.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repository.ErrRefundAlreadyExists
+		}
+		return err
+	}
+
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE payment_transactions
+		 SET refunded_amount = refunded_amount + $2,
+		     status = CASE WHEN refunded_amount + $2 = captured_amount THEN 'refunded' ELSE status END
+		 WHERE transaction_id = $1 AND status = 'captured' AND refunded_amount + $2 <= captured_amount`,
+		refund.TransactionID, refund.Amount)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrRefundLimitExceeded
+	}
+	return dbTx.Commit(ctx)
+}
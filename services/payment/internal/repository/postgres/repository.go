@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// uniqueViolation - код ошибки PostgreSQL при нарушении уникального ограничения
+const uniqueViolation = "23505"
+
+// querier - общие методы *pgxpool.Pool и pgx.Tx: запрос выполняется и отдельно, и в транзакции вместе с outbox
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Repository реализует PaymentRepository, RefundRepository, SubscriptionRepository, OutboxRepository, WalletRepository и PaymentMethodRepository используя PostgreSQL
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository создаёт новый PostgreSQL репозиторий
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		pool: pool,
+	}
+}
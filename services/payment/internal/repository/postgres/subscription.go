@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// subscriptionColumns - колонки payment_subscriptions в порядке scanSubscription
+const subscriptionColumns = `id, user_id, amount, currency, method, interval_seconds, status, period, next_charge_at, created_at`
+
+// CreateSubscription сохраняет подписку
+func (r *Repository) CreateSubscription(ctx context.Context, subscription repository.Subscription) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO payment_subscriptions (`+subscriptionColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		subscription.ID, subscription.UserID, subscription.Amount, subscription.Currency, subscription.Method,
+		int64(subscription.Interval/time.Second), subscription.Status, subscription.Period,
+		subscription.NextChargeAt, subscription.CreatedAt)
+	return err
+}
+
+// GetSubscription возвращает подписку по ID
+func (r *Repository) GetSubscription(ctx context.Context, subscriptionID string) (repository.Subscription, error) {
+	row := r.pool.QueryRow(ctx,
+		`SELECT `+subscriptionColumns+` FROM payment_subscriptions WHERE id = $1`,
+		subscriptionID)
+	subscription, err := scanSubscription(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Subscription{}, repository.ErrSubscriptionNotFound
+		}
+		return repository.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// ListDueSubscriptions возвращает активные подписки, срок списания которых наступил, самые просроченные первыми
+// Идёт по частичному индексу idx_payment_subscriptions_due
+func (r *Repository) ListDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]repository.Subscription, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+subscriptionColumns+`
+		 FROM payment_subscriptions
+		 WHERE status = $1 AND next_charge_at <= $2
+		 ORDER BY next_charge_at ASC
+		 LIMIT $3`,
+		repository.SubscriptionStatusActive, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]repository.Subscription, 0)
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, subscription)
+	}
+	return due, rows.Err()
+}
+
+// AdvanceSubscription переводит подписку на следующий период, если она всё ещё на периоде period
+// Условие period = $2 в UPDATE - compare-and-set: из конкурентных планировщиков период продвигает один
+func (r *Repository) AdvanceSubscription(ctx context.Context, subscriptionID string, period int64, nextChargeAt time.Time) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE payment_subscriptions
+		 SET period = period + 1, next_charge_at = $3
+		 WHERE id = $1 AND period = $2`,
+		subscriptionID, period, nextChargeAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Ничего не обновлено: подписки нет или период уже продвинут
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM payment_subscriptions WHERE id = $1)`,
+		subscriptionID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrSubscriptionNotFound
+	}
+	return repository.ErrSubscriptionConflict
+}
+
+// scanSubscription читает строку subscriptionColumns
+func scanSubscription(row pgx.Row) (repository.Subscription, error) {
+	var (
+		subscription    repository.Subscription
+		intervalSeconds int64
+	)
+	err := row.Scan(&subscription.ID, &subscription.UserID, &subscription.Amount, &subscription.Currency, &subscription.Method,
+		&intervalSeconds, &subscription.Status, &subscription.Period, &subscription.NextChargeAt, &subscription.CreatedAt)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	subscription.Interval = time.Duration(intervalSeconds) * time.Second
+	return subscription, nil
+}
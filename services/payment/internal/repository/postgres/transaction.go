@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// transactionColumns - колонки payment_transactions в порядке scanTransaction
+const transactionColumns = `transaction_id, order_id, user_id, amount, currency, method, status, decline_reason, provider_payment_id, idempotency_key, risk_flag, payment_method_id, captured_amount, refunded_amount, created_at`
+
+// GetByOrderID получает последнюю транзакцию заказа
+// Неотклонённая транзакция у заказа одна (частичный уникальный индекс), и она идёт первой;
+// иначе - последняя отклонённая попытка
+func (r *Repository) GetByOrderID(ctx context.Context, orderID string) (repository.Transaction, error) {
+	return scanTransaction(r.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+`
+		 FROM payment_transactions
+		 WHERE order_id = $1
+		 ORDER BY status NOT IN ('failed', 'declined_risk') DESC, created_at DESC, transaction_id DESC
+		 LIMIT 1`,
+		orderID))
+}
+
+// GetByIdempotencyKey получает транзакцию заказа по ключу идемпотентности (уникальный индекс (order_id, idempotency_key))
+func (r *Repository) GetByIdempotencyKey(ctx context.Context, orderID, idempotencyKey string) (repository.Transaction, error) {
+	return scanTransaction(r.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+`
+		 FROM payment_transactions
+		 WHERE order_id = $1 AND idempotency_key = $2`,
+		orderID, idempotencyKey))
+}
+
+// GetByTransactionID получает транзакцию по её ID
+func (r *Repository) GetByTransactionID(ctx context.Context, transactionID string) (repository.Transaction, error) {
+	return scanTransaction(r.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+`
+		 FROM payment_transactions
+		 WHERE transaction_id = $1`,
+		transactionID))
+}
+
+// ListByUserID возвращает страницу транзакций пользователя по индексу (user_id, created_at, transaction_id)
+// Keyset-пагинация: следующая страница начинается строго после after, новые транзакции не сдвигают страницы
+func (r *Repository) ListByUserID(ctx context.Context, userID string, after *repository.TransactionCursor, limit int) ([]repository.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		 FROM payment_transactions
+		 WHERE user_id = $1`
+	args := []interface{}{userID}
+	if after != nil {
+		query += ` AND (created_at, transaction_id) < ($2, $3)`
+		args = append(args, time.Unix(after.CreatedAt, 0).UTC(), after.TransactionID)
+	}
+	query += ` ORDER BY created_at DESC, transaction_id DESC LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []repository.Transaction
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, rows.Err()
+}
+
+// GetUserActivity считает транзакции пользователя с since по индексу (user_id, created_at, transaction_id)
+func (r *Repository) GetUserActivity(ctx context.Context, userID string, since int64) (repository.UserActivity, error) {
+	var activity repository.UserActivity
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(amount) FILTER (WHERE status NOT IN ('failed', 'declined_risk')), 0)::bigint
+		 FROM payment_transactions
+		 WHERE user_id = $1 AND created_at >= $2`,
+		userID, time.Unix(since, 0).UTC()).Scan(&activity.Payments, &activity.Amount)
+	if err != nil {
+		return repository.UserActivity{}, err
+	}
+	return activity, nil
+}
+
+// GetByProviderPaymentID получает транзакцию по ID платежа у провайдера (частичный уникальный индекс)
+func (r *Repository) GetByProviderPaymentID(ctx context.Context, providerPaymentID string) (repository.Transaction, error) {
+	return scanTransaction(r.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+`
+		 FROM payment_transactions
+		 WHERE provider_payment_id = $1 AND provider_payment_id <> ''`,
+		providerPaymentID))
+}
+
+// Save сохраняет транзакцию
+// Уникальные индексы не дают конкурентным запросам оплатить заказ дважды: (order_id, idempotency_key)
+// и order_id среди неотклонённых транзакций; второй запрос получает repository.ErrAlreadyExists
+func (r *Repository) Save(ctx context.Context, tx repository.Transaction) error {
+	return saveTransaction(ctx, r.pool, tx)
+}
+
+// SaveWithOutbox сохраняет транзакцию и событие outbox в одной транзакции БД
+func (r *Repository) SaveWithOutbox(ctx context.Context, tx repository.Transaction, event repository.OutboxEvent) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	if err := saveTransaction(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	if err := insertOutboxEvent(ctx, dbTx, event); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// UpdateStatus переводит транзакцию из статуса from в статус to
+// Условие status = $2 в UPDATE - compare-and-set: из конкурентных webhook'ов статус меняет один
+func (r *Repository) UpdateStatus(ctx context.Context, transactionID, from, to, declineReason string) error {
+	return updateTransactionStatus(ctx, r.pool, transactionID, from, to, declineReason)
+}
+
+// UpdateStatusWithOutbox переводит транзакцию в статус to и добавляет событие outbox в одной транзакции БД
+// Событие сохраняется, только если статус сменил этот вызов
+func (r *Repository) UpdateStatusWithOutbox(ctx context.Context, transactionID, from, to, declineReason string, event repository.OutboxEvent) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	if err := updateTransactionStatus(ctx, dbTx, transactionID, from, to, declineReason); err != nil {
+		return err
+	}
+	if err := insertOutboxEvent(ctx, dbTx, event); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// CaptureWithOutbox переводит транзакцию в captured со списанной суммой и добавляет событие outbox в одной транзакции БД
+func (r *Repository) CaptureWithOutbox(ctx context.Context, transactionID, from string, capturedAmount int64, event repository.OutboxEvent) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE payment_transactions
+		 SET status = 'captured', decline_reason = '', captured_amount = $3
+		 WHERE transaction_id = $1 AND status = $2`,
+		transactionID, from, capturedAmount)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notUpdatedError(ctx, dbTx, transactionID)
+	}
+	if err := insertOutboxEvent(ctx, dbTx, event); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// saveTransaction вставляет транзакцию платежа через q (пул или транзакция БД)
+func saveTransaction(ctx context.Context, q querier, tx repository.Transaction) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO payment_transactions (`+transactionColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		tx.TransactionID, tx.OrderID, tx.UserID, tx.Amount, tx.Currency, tx.Method,
+		tx.Status, tx.DeclineReason, tx.ProviderPaymentID, tx.IdempotencyKey, tx.RiskFlag, tx.PaymentMethodID, tx.CapturedAmount, tx.RefundedAmount, time.Unix(tx.CreatedAt, 0).UTC())
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation { // order_id, (order_id, idempotency_key) или transaction_id
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// updateTransactionStatus - compare-and-set статуса через q (пул или транзакция БД)
+func updateTransactionStatus(ctx context.Context, q querier, transactionID, from, to, declineReason string) error {
+	tag, err := q.Exec(ctx,
+		`UPDATE payment_transactions
+		 SET status = $3, decline_reason = $4
+		 WHERE transaction_id = $1 AND status = $2`,
+		transactionID, from, to, declineReason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	return notUpdatedError(ctx, q, transactionID)
+}
+
+// notUpdatedError объясняет compare-and-set статуса, не обновивший ни одной строки:
+// транзакции нет - repository.ErrNotFound, статус уже изменён - repository.ErrStatusConflict
+func notUpdatedError(ctx context.Context, q querier, transactionID string) error {
+	var exists bool
+	if err := q.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM payment_transactions WHERE transaction_id = $1)`,
+		transactionID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return repository.ErrStatusConflict
+}
+
+// scanTransaction читает строку transactionColumns
+func scanTransaction(row pgx.Row) (repository.Transaction, error) {
+	var (
+		tx        repository.Transaction
+		createdAt time.Time
+	)
+	err := row.Scan(&tx.TransactionID, &tx.OrderID, &tx.UserID, &tx.Amount, &tx.Currency, &tx.Method,
+		&tx.Status, &tx.DeclineReason, &tx.ProviderPaymentID, &tx.IdempotencyKey, &tx.RiskFlag, &tx.PaymentMethodID, &tx.CapturedAmount, &tx.RefundedAmount, &createdAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Transaction{}, repository.ErrNotFound
+		}
+		return repository.Transaction{}, err
+	}
+	tx.CreatedAt = createdAt.Unix()
+	return tx, nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// GetWallet возвращает кошелёк пользователя в валюте currency
+func (r *Repository) GetWallet(ctx context.Context, userID, currency string) (repository.Wallet, error) {
+	var wallet repository.Wallet
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id, currency, balance, updated_at
+		 FROM payment_wallets
+		 WHERE user_id = $1 AND currency = $2`,
+		userID, currency).Scan(&wallet.UserID, &wallet.Currency, &wallet.Balance, &wallet.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Wallet{}, repository.ErrWalletNotFound
+		}
+		return repository.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+// GetWalletOperation возвращает операцию по кошельку по её ID
+func (r *Repository) GetWalletOperation(ctx context.Context, operationID string) (repository.WalletOperation, error) {
+	var op repository.WalletOperation
+	err := r.pool.QueryRow(ctx,
+		`SELECT operation_id, user_id, currency, kind, amount, reference, created_at
+		 FROM payment_wallet_operations
+		 WHERE operation_id = $1`,
+		operationID).Scan(&op.OperationID, &op.UserID, &op.Currency, &op.Kind, &op.Amount, &op.Reference, &op.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.WalletOperation{}, repository.ErrWalletOperationNotFound
+		}
+		return repository.WalletOperation{}, err
+	}
+	return op, nil
+}
+
+// CreditWallet зачисляет сумму на кошелёк в одной транзакции БД с операцией и её проводками
+// Повтор operation_id упирается в первичный ключ payment_wallet_operations до изменения баланса
+func (r *Repository) CreditWallet(ctx context.Context, op repository.WalletOperation) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	if err := insertWalletOperation(ctx, dbTx, op); err != nil {
+		return err
+	}
+	if _, err := dbTx.Exec(ctx,
+		`INSERT INTO payment_wallets (user_id, currency, balance, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $4)
+		 ON CONFLICT (user_id, currency) DO UPDATE
+		 SET balance = payment_wallets.balance + EXCLUDED.balance, updated_at = EXCLUDED.updated_at`,
+		op.UserID, op.Currency, op.Amount, op.CreatedAt); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// PayFromWallet списывает сумму с кошелька и сохраняет транзакцию платежа с событием outbox в одной транзакции БД
+// Условие balance >= $3 в UPDATE не даёт конкурентным оплатам увести баланс в минус
+func (r *Repository) PayFromWallet(ctx context.Context, op repository.WalletOperation, tx repository.Transaction, event repository.OutboxEvent) error {
+	dbTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback(ctx)
+
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE payment_wallets
+		 SET balance = balance - $3, updated_at = $4
+		 WHERE user_id = $1 AND currency = $2 AND balance >= $3`,
+		op.UserID, op.Currency, op.Amount, op.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrInsufficientBalance
+	}
+	if err := insertWalletOperation(ctx, dbTx, op); err != nil {
+		return err
+	}
+	if err := saveTransaction(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	if err := insertOutboxEvent(ctx, dbTx, event); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
+
+// insertWalletOperation сохраняет операцию и её проводки (repository.WalletEntries) через q - транзакцию БД
+// вместе с изменением баланса
+func insertWalletOperation(ctx context.Context, q querier, op repository.WalletOperation) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO payment_wallet_operations (operation_id, user_id, currency, kind, amount, reference, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		op.OperationID, op.UserID, op.Currency, op.Kind, op.Amount, op.Reference, op.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repository.ErrWalletOperationExists
+		}
+		return err
+	}
+
+	for _, entry := range repository.WalletEntries(op) {
+		if _, err := q.Exec(ctx,
+			`INSERT INTO payment_wallet_entries (operation_id, account, currency, amount, created_at)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			entry.OperationID, entry.Account, entry.Currency, entry.Amount, entry.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Refund - возврат платежа, связанный с исходной транзакцией заказа
+// У заказа может быть несколько частичных возвратов; в сумме они не больше списанной суммы транзакции
+type Refund struct {
+	RefundID      string
+	OrderID       string
+	TransactionID string // исходная транзакция оплаты
+	Amount        int64  // вся списанная сумма или её часть, в минимальных единицах валюты
+	Currency      string // код валюты ISO 4217
+	Reason        string
+	// ProviderRefundID - ID возврата у платёжного провайдера; пусто, если платёж прошёл без провайдера
+	ProviderRefundID string
+	// WalletOperationID - операция зачисления на кошелёк, если деньги вернулись на кошелёк, а не через провайдера
+	WalletOperationID string
+	CreatedAt         time.Time
+}
+
+// RefundRepository определяет интерфейс для хранения возвратов
+type RefundRepository interface {
+	// GetRefund возвращает возврат по ID
+	// Возвращает ErrRefundNotFound, если возврата нет
+	GetRefund(ctx context.Context, refundID string) (Refund, error)
+
+	// CreateRefund сохраняет возврат и атомарно с ним увеличивает RefundedAmount транзакции refund.TransactionID;
+	// транзакция, возвращённая целиком, переходит в StatusRefunded
+	// Возвращает ErrRefundAlreadyExists, если возврат с таким ID уже сохранён, и ErrRefundLimitExceeded,
+	// если транзакция не в StatusCaptured или возвраты превысили бы списанную сумму, - тогда ничего не меняется
+	CreateRefund(ctx context.Context, refund Refund) error
+}
+
+// ErrRefundNotFound возвращается, когда возврата нет
+var ErrRefundNotFound = errors.New("refund not found")
+
+// ErrRefundAlreadyExists возвращается, когда возврат с таким ID уже сохранён (например, конкурентным запросом)
+var ErrRefundAlreadyExists = errors.New("refund already exists")
+
+// ErrRefundLimitExceeded возвращается CreateRefund, когда вернуть уже нечего или меньше суммы возврата
+// (например, конкурентный частичный возврат забрал остаток)
+var ErrRefundLimitExceeded = errors.New("refund exceeds captured amount")
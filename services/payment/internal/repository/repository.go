@@ -10,25 +10,120 @@ import (
 type Transaction struct {
 	OrderID       string
 	UserID        string
-	Amount        float64
+	Amount        int64  // в минимальных единицах валюты (копейки, центы)
+	Currency      string // код валюты ISO 4217
 	Method        string
 	TransactionID string
-	Status        string
-	CreatedAt     int64 // Unix timestamp
+	Status        string // Status*; допустимые переходы проверяет service слой
+	DeclineReason string // причина отказа (только для StatusFailed и StatusDeclinedRisk)
+	// ProviderPaymentID - ID платежа у платёжного провайдера, по нему делаются возвраты и находится транзакция
+	// из webhook провайдера; пусто, если провайдер не вызывался
+	ProviderPaymentID string
+	// IdempotencyKey - ключ идемпотентности клиента; уникален в пределах заказа, пусто - запрос без ключа
+	IdempotencyKey string
+	// RiskFlag - правило антифрод-проверки, пометившее (платёж проведён) или отклонившее (StatusDeclinedRisk)
+	// платёж; пусто - проверка пройдена или не выполнялась
+	RiskFlag string
+	// PaymentMethodID - сохранённый способ оплаты, токен которого ушёл провайдеру в Method; пусто - способ передан явно
+	PaymentMethodID string
+	// CapturedAmount - списанная сумма: вся Amount или меньше при частичном списании (ConfirmPayment с amount);
+	// 0, пока деньги не списаны. Остаток авторизации после частичного списания освобождается
+	CapturedAmount int64
+	// RefundedAmount - сумма всех возвратов, не больше CapturedAmount; вернуть ещё можно CapturedAmount - RefundedAmount
+	RefundedAmount int64
+	CreatedAt      int64 // Unix timestamp
+}
+
+// Статусы транзакции
+const (
+	// StatusPending - провайдер принял платёж, но результат придёт позже webhook'ом (СБП, оплата по счёту)
+	StatusPending = "pending"
+	// StatusAuthorized - сумма заблокирована у провайдера и ждёт списания (ConfirmPayment)
+	StatusAuthorized = "authorized"
+	// StatusCaptured - деньги списаны
+	StatusCaptured = "captured"
+	// StatusFailed - в оплате отказано (лимит, отказ провайдера или банка)
+	StatusFailed = "failed"
+	// StatusRefunded - вся списанная сумма возвращена (одним или несколькими частичными возвратами)
+	StatusRefunded = "refunded"
+	// StatusDeclinedRisk - платёж отклонён антифрод-проверкой сервиса до обращения к провайдеру
+	StatusDeclinedRisk = "declined_risk"
+	// StatusVoided - авторизация отменена до списания (VoidAuthorization), заблокированная сумма освобождена
+	StatusVoided = "voided"
+)
+
+// IsDeclined сообщает, отклонена ли транзакция (StatusFailed или StatusDeclinedRisk)
+// У заказа может быть несколько отклонённых попыток, но не больше одной неотклонённой
+func IsDeclined(status string) bool {
+	return status == StatusFailed || status == StatusDeclinedRisk
+}
+
+// UserActivity - платежи пользователя за период, по ним антифрод-проверка считает velocity
+type UserActivity struct {
+	Payments int   // попыток оплаты, включая отклонённые
+	Amount   int64 // сумма неотклонённых платежей в минимальных единицах (валюты не различаются)
+}
+
+// TransactionCursor - позиция транзакции в выдаче ListByUserID
+type TransactionCursor struct {
+	CreatedAt     int64
+	TransactionID string
 }
 
 // PaymentRepository определяет интерфейс для работы с хранилищем транзакций
 // Service слой зависит от этого интерфейса, а не от конкретной реализации
 type PaymentRepository interface {
-	// GetByOrderID получает транзакцию по orderID
-	// Возвращает ErrNotFound, если транзакция не найдена
+	// GetByOrderID получает последнюю транзакцию заказа
+	// У заказа может быть несколько отклонённых попыток (IsDeclined), но не больше одной в другом статусе,
+	// и она всегда последняя. Возвращает ErrNotFound, если транзакция не найдена
 	GetByOrderID(ctx context.Context, orderID string) (Transaction, error)
-	
+
+	// GetByIdempotencyKey получает транзакцию заказа, сохранённую с ключом идемпотентности idempotencyKey
+	// Возвращает ErrNotFound, если транзакция не найдена
+	GetByIdempotencyKey(ctx context.Context, orderID, idempotencyKey string) (Transaction, error)
+
 	// Save сохраняет транзакцию в хранилище
+	// Возвращает ErrAlreadyExists, если у заказа уже есть транзакция с тем же ключом идемпотентности
+	// или неотклонённая транзакция (IsDeclined)
 	Save(ctx context.Context, tx Transaction) error
+
+	// SaveWithOutbox сохраняет транзакцию и событие outbox атомарно; ошибки - как у Save, при ошибке событие не сохраняется
+	SaveWithOutbox(ctx context.Context, tx Transaction, event OutboxEvent) error
+
+	// GetByTransactionID получает транзакцию по её ID
+	// Возвращает ErrNotFound, если транзакция не найдена
+	GetByTransactionID(ctx context.Context, transactionID string) (Transaction, error)
+
+	// ListByUserID возвращает до limit транзакций пользователя, новые первыми (CreatedAt, затем TransactionID по убыванию)
+	// after - последняя транзакция предыдущей страницы; nil - первая страница
+	ListByUserID(ctx context.Context, userID string, after *TransactionCursor, limit int) ([]Transaction, error)
+
+	// GetUserActivity считает транзакции пользователя, созданные не раньше since (Unix timestamp)
+	GetUserActivity(ctx context.Context, userID string, since int64) (UserActivity, error)
+
+	// GetByProviderPaymentID получает транзакцию по ID платежа у провайдера
+	// Возвращает ErrNotFound, если транзакция не найдена
+	GetByProviderPaymentID(ctx context.Context, providerPaymentID string) (Transaction, error)
+
+	// UpdateStatus переводит транзакцию из статуса from в статус to (compare-and-set)
+	// declineReason сохраняется вместе со статусом (для StatusFailed)
+	// Возвращает ErrNotFound, если транзакции нет, и ErrStatusConflict, если её статус уже не from
+	UpdateStatus(ctx context.Context, transactionID, from, to, declineReason string) error
+
+	// UpdateStatusWithOutbox - UpdateStatus и событие outbox атомарно: событие сохраняется, только если статус сменил этот вызов
+	UpdateStatusWithOutbox(ctx context.Context, transactionID, from, to, declineReason string, event OutboxEvent) error
+
+	// CaptureWithOutbox переводит транзакцию из статуса from в StatusCaptured со списанной суммой capturedAmount
+	// и сохраняет событие outbox атомарно; ошибки - как у UpdateStatus
+	CaptureWithOutbox(ctx context.Context, transactionID, from string, capturedAmount int64, event OutboxEvent) error
 }
 
 // ErrNotFound возвращается, когда транзакция не найдена в хранилище
 var ErrNotFound = errors.New("transaction not found")
 
+// ErrAlreadyExists возвращается Save, когда транзакция для заказа уже сохранена (например, конкурентным запросом)
+// или у заказа есть неотклонённая транзакция
+var ErrAlreadyExists = errors.New("transaction for order already exists")
 
+// ErrStatusConflict возвращается UpdateStatus, когда статус транзакции уже изменён (например, повторным webhook'ом)
+var ErrStatusConflict = errors.New("transaction status changed concurrently")
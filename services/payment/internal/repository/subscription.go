@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Subscription - регулярный платёж: сумма списывается с пользователя каждые Interval
+type Subscription struct {
+	ID           string
+	UserID       string
+	Amount       int64  // в минимальных единицах валюты
+	Currency     string // код валюты ISO 4217
+	Method       string
+	Interval     time.Duration
+	Status       string    // SubscriptionStatusActive или SubscriptionStatusCancelled
+	Period       int64     // номер следующего списания, начиная с 1
+	NextChargeAt time.Time // когда списывать период Period
+	CreatedAt    time.Time
+}
+
+// Статусы подписки
+const (
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusCancelled = "cancelled"
+)
+
+// SubscriptionRepository определяет интерфейс для хранения подписок
+type SubscriptionRepository interface {
+	// CreateSubscription сохраняет новую подписку
+	CreateSubscription(ctx context.Context, subscription Subscription) error
+
+	// GetSubscription возвращает подписку по ID
+	// Возвращает ErrSubscriptionNotFound, если подписки нет
+	GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error)
+
+	// ListDueSubscriptions возвращает до limit активных подписок с NextChargeAt <= now, самые просроченные первыми
+	ListDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+
+	// AdvanceSubscription переводит подписку на следующий период, если она всё ещё на периоде period
+	// Условие по period защищает от двойного продвижения при конкурентных запусках планировщика:
+	// тогда возвращается ErrSubscriptionConflict
+	AdvanceSubscription(ctx context.Context, subscriptionID string, period int64, nextChargeAt time.Time) error
+}
+
+// ErrSubscriptionNotFound возвращается, когда подписка не найдена
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrSubscriptionConflict возвращается, когда период подписки уже продвинут другим обработчиком
+var ErrSubscriptionConflict = errors.New("subscription period already advanced")
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Wallet - баланс пользователя в одной валюте; у пользователя по кошельку на валюту
+type Wallet struct {
+	UserID    string
+	Currency  string // код валюты ISO 4217
+	Balance   int64  // в минимальных единицах валюты, не отрицательный
+	UpdatedAt time.Time
+}
+
+// Виды операций по кошельку
+const (
+	// WalletOperationPromo - зачисление промо-бонуса
+	WalletOperationPromo = "promo"
+	// WalletOperationRefund - возврат платежа на кошелёк
+	WalletOperationRefund = "refund"
+	// WalletOperationPayment - оплата заказа с кошелька (списание)
+	WalletOperationPayment = "payment"
+)
+
+// Системные счета двойной записи: откуда приходят и куда уходят деньги кошельков
+const (
+	// WalletAccountPromo - промо-бюджет, источник промо-бонусов
+	WalletAccountPromo = "system:promo"
+	// WalletAccountRefunds - возвраты на кошелёк
+	WalletAccountRefunds = "system:refunds"
+	// WalletAccountPayments - выручка от оплат с кошелька
+	WalletAccountPayments = "system:payments"
+)
+
+// WalletOperation - операция по кошельку: зачисление (promo, refund) или списание (payment)
+// OperationID - ключ идемпотентности: операция с тем же ID применяется один раз
+type WalletOperation struct {
+	OperationID string
+	UserID      string
+	Currency    string
+	Kind        string // WalletOperation*
+	Amount      int64  // в минимальных единицах валюты, положительная; направление задаёт Kind
+	Reference   string // order_id для payment и refund, причина зачисления для promo
+	CreatedAt   time.Time
+}
+
+// WalletEntry - проводка двойной записи: Amount > 0 увеличивает баланс счёта Account, Amount < 0 - уменьшает
+// Проводки одной операции в сумме дают 0
+type WalletEntry struct {
+	OperationID string
+	Account     string // WalletAccount* или счёт кошелька WalletAccount(userID)
+	Currency    string
+	Amount      int64
+	CreatedAt   time.Time
+}
+
+// WalletAccount возвращает счёт кошелька пользователя в журнале проводок
+func WalletAccount(userID string) string {
+	return "wallet:" + userID
+}
+
+// WalletEntries возвращает проводки операции: кошелёк пользователя и системный счёт с противоположным знаком
+func WalletEntries(op WalletOperation) []WalletEntry {
+	counterAccount, amount := WalletAccountPromo, op.Amount
+	switch op.Kind {
+	case WalletOperationRefund:
+		counterAccount = WalletAccountRefunds
+	case WalletOperationPayment:
+		counterAccount, amount = WalletAccountPayments, -op.Amount
+	}
+	return []WalletEntry{
+		{OperationID: op.OperationID, Account: WalletAccount(op.UserID), Currency: op.Currency, Amount: amount, CreatedAt: op.CreatedAt},
+		{OperationID: op.OperationID, Account: counterAccount, Currency: op.Currency, Amount: -amount, CreatedAt: op.CreatedAt},
+	}
+}
+
+// WalletRepository определяет интерфейс для хранения кошельков и журнала их операций
+// Баланс меняется только вместе с операцией и её проводками, в одной транзакции БД
+type WalletRepository interface {
+	// GetWallet возвращает кошелёк пользователя в валюте currency
+	// Возвращает ErrWalletNotFound, если по нему ещё не было зачислений
+	GetWallet(ctx context.Context, userID, currency string) (Wallet, error)
+
+	// GetWalletOperation возвращает операцию по её ID
+	// Возвращает ErrWalletOperationNotFound, если операции нет
+	GetWalletOperation(ctx context.Context, operationID string) (WalletOperation, error)
+
+	// CreditWallet зачисляет op.Amount на кошелёк (promo, refund), создавая кошелёк при первом зачислении
+	// Возвращает ErrWalletOperationExists, если операция с таким ID уже применена
+	CreditWallet(ctx context.Context, op WalletOperation) error
+
+	// PayFromWallet списывает op.Amount с кошелька и сохраняет транзакцию платежа с событием outbox в одной транзакции БД:
+	// списания без транзакции (и транзакции без списания) не бывает
+	// Возвращает ErrInsufficientBalance, если баланса не хватает (или кошелька нет), и ErrAlreadyExists,
+	// если транзакцию заказа сохранил конкурентный запрос, - тогда баланс не меняется
+	PayFromWallet(ctx context.Context, op WalletOperation, tx Transaction, event OutboxEvent) error
+}
+
+// ErrWalletNotFound возвращается, когда у пользователя нет кошелька в запрошенной валюте
+var ErrWalletNotFound = errors.New("wallet not found")
+
+// ErrWalletOperationNotFound возвращается, когда операции по кошельку нет
+var ErrWalletOperationNotFound = errors.New("wallet operation not found")
+
+// ErrWalletOperationExists возвращается, когда операция с таким ID уже применена (повтор или конкурентный запрос)
+var ErrWalletOperationExists = errors.New("wallet operation already exists")
+
+// ErrInsufficientBalance возвращается, когда баланса кошелька не хватает для списания
+var ErrInsufficientBalance = errors.New("insufficient wallet balance")
@@ -0,0 +1,51 @@
+package risk
+
+import "context"
+
+// RiskChecker - антифрод-проверка платежа перед обращением к провайдеру
+// Service слой зависит от этого интерфейса; реализация по порогам - ThresholdChecker (PAYMENT_RISK_*)
+type RiskChecker interface {
+	// Check оценивает платёж; ошибка - проверку выполнить не удалось, платёж не проводится
+	Check(ctx context.Context, req CheckRequest) (Decision, error)
+}
+
+// CheckRequest - проверяемый платёж
+type CheckRequest struct {
+	OrderID  string
+	UserID   string
+	Amount   int64  // в минимальных единицах валюты
+	Currency string // код валюты ISO 4217
+	Method   string
+}
+
+// Action - решение антифрод-проверки
+type Action string
+
+const (
+	// ActionAllow - платёж проводится
+	ActionAllow Action = "allow"
+	// ActionFlag - платёж проводится, но помечается для ручной проверки (Transaction.RiskFlag)
+	ActionFlag Action = "flag"
+	// ActionDecline - платёж отклоняется без обращения к провайдеру (StatusDeclinedRisk)
+	ActionDecline Action = "decline"
+)
+
+// Правила ThresholdChecker; сохраняются в Transaction.RiskFlag
+const (
+	// RuleAmountReview - сумма платежа не меньше порога ручной проверки
+	RuleAmountReview = "amount_review"
+	// RuleVelocityCount - слишком много попыток оплаты пользователя за окно
+	RuleVelocityCount = "velocity_count"
+	// RuleVelocityAmount - слишком большая сумма платежей пользователя за окно
+	RuleVelocityAmount = "velocity_amount"
+)
+
+// Decision - результат проверки
+type Decision struct {
+	Action  Action
+	Rule    string // сработавшее правило (Rule*); пусто для ActionAllow
+	Message string // пояснение для лога и клиента
+}
+
+// Allow - решение без замечаний
+var Allow = Decision{Action: ActionAllow}
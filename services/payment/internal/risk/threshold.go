@@ -0,0 +1,77 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// ActivityRepository - история платежей пользователя; реализуется PaymentRepository
+type ActivityRepository interface {
+	GetUserActivity(ctx context.Context, userID string, since int64) (repository.UserActivity, error)
+}
+
+// Thresholds - пороги ThresholdChecker; нулевой порог выключает правило
+type Thresholds struct {
+	// ReviewAmount - платёж на эту сумму (в минимальных единицах) и больше помечается RuleAmountReview, но проводится
+	ReviewAmount int64
+	// VelocityWindow - окно velocity-проверок, считается назад от текущего платежа
+	VelocityWindow time.Duration
+	// MaxPayments - сколько попыток оплаты (включая отклонённые и текущую) пользователь может сделать за окно
+	MaxPayments int
+	// MaxAmount - сколько пользователь может заплатить за окно вместе с текущим платежом
+	MaxAmount int64
+}
+
+// ThresholdChecker - RiskChecker по порогам суммы и частоты платежей пользователя
+// Velocity-правила отклоняют платёж, порог суммы - только помечает: крупный платёж сам по себе не подозрителен
+type ThresholdChecker struct {
+	activity   ActivityRepository
+	thresholds Thresholds
+	now        func() time.Time
+}
+
+// NewThresholdChecker создаёт ThresholdChecker
+func NewThresholdChecker(activity ActivityRepository, thresholds Thresholds) *ThresholdChecker {
+	return &ThresholdChecker{
+		activity:   activity,
+		thresholds: thresholds,
+		now:        time.Now,
+	}
+}
+
+// Check проверяет платёж: сначала velocity-правила (отказ), затем порог суммы (пометка)
+func (c *ThresholdChecker) Check(ctx context.Context, req CheckRequest) (Decision, error) {
+	t := c.thresholds
+	if t.VelocityWindow > 0 && (t.MaxPayments > 0 || t.MaxAmount > 0) {
+		activity, err := c.activity.GetUserActivity(ctx, req.UserID, c.now().Add(-t.VelocityWindow).Unix())
+		if err != nil {
+			return Decision{}, fmt.Errorf("failed to get user activity: %w", err)
+		}
+		if t.MaxPayments > 0 && activity.Payments+1 > t.MaxPayments {
+			return Decision{
+				Action:  ActionDecline,
+				Rule:    RuleVelocityCount,
+				Message: fmt.Sprintf("more than %d payments in %s", t.MaxPayments, t.VelocityWindow),
+			}, nil
+		}
+		if t.MaxAmount > 0 && activity.Amount+req.Amount > t.MaxAmount {
+			return Decision{
+				Action:  ActionDecline,
+				Rule:    RuleVelocityAmount,
+				Message: fmt.Sprintf("payments over %d in %s", t.MaxAmount, t.VelocityWindow),
+			}, nil
+		}
+	}
+
+	if t.ReviewAmount > 0 && req.Amount >= t.ReviewAmount {
+		return Decision{
+			Action:  ActionFlag,
+			Rule:    RuleAmountReview,
+			Message: fmt.Sprintf("amount %d %s requires review (threshold %d)", req.Amount, req.Currency, t.ReviewAmount),
+		}, nil
+	}
+	return Allow, nil
+}
@@ -0,0 +1,69 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+)
+
+func TestThresholdChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	thresholds := Thresholds{ReviewAmount: 50000, VelocityWindow: time.Hour, MaxPayments: 3, MaxAmount: 100000}
+	since := now.Add(-time.Hour).Unix()
+
+	newChecker := func(repo ActivityRepository, thresholds Thresholds) *ThresholdChecker {
+		c := NewThresholdChecker(repo, thresholds)
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		amount   int64
+		activity repository.UserActivity
+		action   Action
+		rule     string
+	}{
+		{name: "allow", amount: 100, activity: repository.UserActivity{Payments: 2, Amount: 1000}, action: ActionAllow},
+		{name: "too many payments", amount: 100, activity: repository.UserActivity{Payments: 3}, action: ActionDecline, rule: RuleVelocityCount},
+		{name: "amount over window limit", amount: 60000, activity: repository.UserActivity{Payments: 1, Amount: 50000}, action: ActionDecline, rule: RuleVelocityAmount},
+		{name: "large amount flagged", amount: 50000, activity: repository.UserActivity{}, action: ActionFlag, rule: RuleAmountReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewPaymentRepository(t)
+			repo.On("GetUserActivity", ctx, "user-1", since).Return(tt.activity, nil).Once()
+
+			decision, err := newChecker(repo, thresholds).Check(ctx, CheckRequest{OrderID: "order-1", UserID: "user-1", Amount: tt.amount})
+
+			require.NoError(t, err)
+			require.Equal(t, tt.action, decision.Action)
+			require.Equal(t, tt.rule, decision.Rule)
+		})
+	}
+
+	t.Run("velocity disabled does not read activity", func(t *testing.T) {
+		repo := mocks.NewPaymentRepository(t)
+
+		decision, err := newChecker(repo, Thresholds{ReviewAmount: 50000}).Check(ctx, CheckRequest{UserID: "user-1", Amount: 100})
+
+		require.NoError(t, err)
+		require.Equal(t, Allow, decision)
+	})
+
+	t.Run("activity error", func(t *testing.T) {
+		repo := mocks.NewPaymentRepository(t)
+		repo.On("GetUserActivity", ctx, "user-1", since).Return(repository.UserActivity{}, errors.New("db down")).Once()
+
+		_, err := newChecker(repo, thresholds).Check(ctx, CheckRequest{UserID: "user-1", Amount: 100})
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedCurrency возвращается, если валюта платежа не поддерживается (handler маппит в codes.InvalidArgument)
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// ErrCurrencyMismatch возвращается Pay, если повтор оплаты заказа пришёл в валюте, отличной от сохранённой транзакции:
+// сумма в минимальных единицах другой валюты - другой платёж, а не повтор
+var ErrCurrencyMismatch = errors.New("currency does not match existing payment")
+
+// currencyExponents - поддерживаемые валюты ISO 4217 и число знаков после запятой у их минимальной единицы:
+// суммы хранятся и передаются в минимальных единицах (копейки, центы; у JPY минимальная единица - иена)
+var currencyExponents = map[string]int{
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CNY": 2,
+	"KZT": 2,
+	"JPY": 0,
+}
+
+// NormalizeCurrency приводит код валюты к верхнему регистру и проверяет, что он поддерживается
+// Пустое значение заменяется на DefaultCurrency
+func NormalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency, nil
+	}
+	if _, ok := currencyExponents[currency]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return currency, nil
+}
+
+// formatAmount форматирует сумму в минимальных единицах для сообщений: 150050 RUB -> "1500.50 RUB"
+func formatAmount(amount int64, currency string) string {
+	exponent := currencyExponents[currency]
+	if exponent == 0 {
+		return fmt.Sprintf("%d %s", amount, currency)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(1)
+	for i := 0; i < exponent; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, exponent, amount%scale, currency)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		want     string
+		wantErr  error
+	}{
+		{"empty falls back to default", "", DefaultCurrency, nil},
+		{"lower case", "usd", "USD", nil},
+		{"zero exponent currency", "JPY", "JPY", nil},
+		{"unsupported", "XYZ", "", ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCurrency(tt.currency)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "1500.50 RUB", formatAmount(150050, "RUB"))
+	require.Equal(t, "0.05 USD", formatAmount(5, "USD"))
+	require.Equal(t, "-1.20 EUR", formatAmount(-120, "EUR"))
+	require.Equal(t, "1500 JPY", formatAmount(1500, "JPY"))
+}
+
+func TestPaymentService_Currency(t *testing.T) {
+	ctx := context.Background()
+	input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 15050, Currency: "USD", Method: "card"}
+
+	t.Run("unsupported currency is rejected before saving", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 100000, mockprovider.New(), nil, nil, nil, nil)
+		unsupported := input
+		unsupported.Currency = "XYZ"
+
+		_, err := service.Pay(ctx, unsupported)
+
+		require.ErrorIs(t, err, ErrUnsupportedCurrency)
+		_, err = paymentRepo.GetByOrderID(ctx, "order-1")
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("retry in another currency is rejected", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 100000, mockprovider.New(), nil, nil, nil, nil)
+		first, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		retry := input
+		retry.Currency = "EUR"
+		_, err = service.Pay(ctx, retry)
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+		retry.Currency = "usd"
+		tx, err := service.Pay(ctx, retry)
+		require.NoError(t, err)
+		require.Equal(t, first.TransactionID, tx.TransactionID)
+		require.Equal(t, int64(15050), tx.Amount)
+	})
+
+	t.Run("limit is compared in minor units", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 10000, mockprovider.New(), nil, nil, nil, nil)
+
+		_, err := service.Pay(ctx, input)
+
+		var declineErr *DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineLimitExceeded, declineErr.Reason)
+		require.Contains(t, declineErr.Message, "150.50 USD")
+	})
+}
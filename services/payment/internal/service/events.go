@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// Типы событий результата платежа: пишутся в outbox вместе со статусом транзакции и публикуются в Kafka,
+// чтобы Order Service узнавал о результате асинхронно, а не только из ответа ProcessPayment
+const (
+	EventTypePaymentSucceeded = "payment.succeeded"
+	EventTypePaymentFailed    = "payment.failed"
+	EventTypePaymentVoided    = "payment.voided"
+)
+
+// PaymentEventVersion - версия схемы событий payment.succeeded / payment.failed / payment.voided; 2 - amount в минимальных единицах валюты
+const PaymentEventVersion = 2
+
+// paymentEvent возвращает событие outbox для транзакции, оплата которой завершилась:
+// StatusCaptured - payment.succeeded, StatusFailed и StatusDeclinedRisk - payment.failed, StatusVoided - payment.voided;
+// для остальных статусов ok = false
+// EventID детерминирован по транзакции: статус меняется compare-and-set'ом, поэтому событие одно
+func paymentEvent(tx repository.Transaction, occurredAt time.Time) (event repository.OutboxEvent, ok bool, err error) {
+	var eventType string
+	switch tx.Status {
+	case repository.StatusCaptured:
+		eventType = EventTypePaymentSucceeded
+	case repository.StatusFailed, repository.StatusDeclinedRisk:
+		eventType = EventTypePaymentFailed
+	case repository.StatusVoided:
+		eventType = EventTypePaymentVoided
+	default:
+		return repository.OutboxEvent{}, false, nil
+	}
+
+	eventID := eventType + "-" + tx.TransactionID
+	payload := map[string]interface{}{
+		"event_id":       eventID,
+		"event_type":     eventType,
+		"event_version":  PaymentEventVersion,
+		"occurred_at":    occurredAt.Format(time.RFC3339),
+		"order_id":       tx.OrderID,
+		"user_id":        tx.UserID,
+		"transaction_id": tx.TransactionID,
+		"amount":         tx.Amount,
+		"currency":       tx.Currency,
+		"method":         tx.Method,
+	}
+	if tx.Status == repository.StatusCaptured {
+		payload["captured_amount"] = tx.CapturedAmount
+	}
+	if tx.DeclineReason != "" {
+		payload["reason"] = tx.DeclineReason
+	}
+	if tx.RiskFlag != "" {
+		payload["risk_flag"] = tx.RiskFlag
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return repository.OutboxEvent{}, false, err
+	}
+
+	return repository.OutboxEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		OccurredAt:  occurredAt,
+		AggregateID: tx.OrderID,
+		Payload:     payloadBytes,
+	}, true, nil
+}
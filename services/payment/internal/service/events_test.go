@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_OutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card"}
+
+	t.Run("captured payment writes payment.succeeded once", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		tx, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+		_, err = service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		events := paymentRepo.OutboxEvents()
+		require.Len(t, events, 1)
+		require.Equal(t, EventTypePaymentSucceeded, events[0].EventType)
+		require.Equal(t, "payment.succeeded-"+tx.TransactionID, events[0].EventID)
+		require.Equal(t, "order-1", events[0].AggregateID)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+		require.Equal(t, tx.TransactionID, payload["transaction_id"])
+		require.Equal(t, "user-1", payload["user_id"])
+		require.Equal(t, float64(100), payload["amount"])
+	})
+
+	t.Run("decline writes payment.failed with reason", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+		declined := input
+		declined.Method = mockprovider.MethodInsufficientFunds
+
+		_, err := service.Pay(ctx, declined)
+		require.Error(t, err)
+
+		events := paymentRepo.OutboxEvents()
+		require.Len(t, events, 1)
+		require.Equal(t, EventTypePaymentFailed, events[0].EventType)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+		require.Equal(t, string(DeclineInsufficientFunds), payload["reason"])
+	})
+
+	t.Run("pending and authorized payments write event on completion", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+		pending := input
+		pending.Method = mockprovider.MethodSBP
+		_, err := service.Pay(ctx, pending)
+		require.NoError(t, err)
+		authorized := input
+		authorized.OrderID, authorized.ManualCapture = "order-2", true
+		_, err = service.Pay(ctx, authorized)
+		require.NoError(t, err)
+		require.Empty(t, paymentRepo.OutboxEvents())
+
+		require.NoError(t, service.HandlePaymentEvent(ctx, provider.PaymentEvent{
+			ID: "evt_1", Type: provider.PaymentEventFailed, PaymentID: "mock_pay_order-1", DeclineCode: provider.DeclineCard,
+		}))
+		_, err = service.ConfirmPayment(ctx, "order-2", 0)
+		require.NoError(t, err)
+
+		events := paymentRepo.OutboxEvents()
+		require.Len(t, events, 2)
+		require.Equal(t, EventTypePaymentFailed, events[0].EventType)
+		require.Equal(t, "order-1", events[0].AggregateID)
+		require.Equal(t, EventTypePaymentSucceeded, events[1].EventType)
+		require.Equal(t, "order-2", events[1].AggregateID)
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// Результаты попытки оплаты (Pay) для метрик
+const (
+	PaymentResultSuccess       = "success"        // деньги списаны
+	PaymentResultAuthorized    = "authorized"     // сумма заблокирована (ManualCapture)
+	PaymentResultPending       = "pending"        // ждёт подтверждения покупателем
+	PaymentResultDeclined      = "declined"       // отказ: лимит, антифрод-проверка, провайдер или банк
+	PaymentResultProviderError = "provider_error" // провайдер недоступен, повтор может пройти
+	PaymentResultRejected      = "rejected"       // невалидный запрос или конфликт с уже сохранённой оплатой заказа
+	PaymentResultError         = "error"          // внутренняя ошибка: хранилище, неожиданный ответ провайдера
+)
+
+// Результаты возврата (Refund) для метрик
+const (
+	RefundResultRefunded = "refunded" // деньги возвращены этим вызовом
+	RefundResultReplayed = "replayed" // возврат уже был сделан, отдан сохранённый
+	RefundResultRejected = "rejected" // невалидный запрос, платёж нельзя вернуть или провайдер отказал
+	RefundResultError    = "error"    // внутренняя ошибка: хранилище, провайдер недоступен
+)
+
+// MethodLabelStored - способ оплаты в метриках для оплаты сохранённым способом: токен в метки не попадает
+const MethodLabelStored = "stored"
+
+// PaymentMetricsRecorder записывает метрики платежей и возвратов (опционально, может быть nil)
+type PaymentMetricsRecorder interface {
+	// RecordPaymentAttempt записывает попытку оплаты: результат (PaymentResult*), способ оплаты и длительность обработки
+	RecordPaymentAttempt(result, method string, d time.Duration)
+	// RecordRefund записывает возврат с результатом RefundResult*; amount - возвращённая сумма
+	// в минимальных единицах валюты currency, только для RefundResultRefunded
+	RecordRefund(result string, amount int64, currency string)
+}
+
+// paymentResult определяет результат попытки оплаты для метрик
+func paymentResult(tx repository.Transaction, err error) string {
+	if err == nil {
+		switch tx.Status {
+		case repository.StatusAuthorized:
+			return PaymentResultAuthorized
+		case repository.StatusPending:
+			return PaymentResultPending
+		default:
+			return PaymentResultSuccess
+		}
+	}
+
+	var declineErr *DeclineError
+	switch {
+	case errors.As(err, &declineErr) && declineErr.Reason == DeclineProviderError:
+		return PaymentResultProviderError
+	case errors.As(err, &declineErr):
+		return PaymentResultDeclined
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrUnsupportedCurrency), errors.Is(err, ErrCurrencyMismatch),
+		errors.Is(err, ErrPaymentMethodAmbiguous), errors.Is(err, ErrWalletDisabled), errors.Is(err, ErrWalletManualCapture),
+		errors.Is(err, ErrOrderAlreadyPaid), errors.Is(err, repository.ErrPaymentMethodNotFound):
+		return PaymentResultRejected
+	default:
+		return PaymentResultError
+	}
+}
+
+// paymentMethodLabel возвращает способ оплаты для меток метрик
+func paymentMethodLabel(input PaymentInput) string {
+	switch {
+	case input.PaymentMethodID != "":
+		return MethodLabelStored
+	case input.Method == "":
+		return "unspecified"
+	default:
+		return input.Method
+	}
+}
+
+// refundResult определяет результат возврата для метрик
+func refundResult(replayed bool, err error) string {
+	switch {
+	case err == nil && replayed:
+		return RefundResultReplayed
+	case err == nil:
+		return RefundResultRefunded
+	case errors.Is(err, ErrOrderIDRequired), errors.Is(err, ErrInvalidRefundAmount), errors.Is(err, ErrRefundAmountExceeded),
+		errors.Is(err, ErrPaymentNotFound), errors.Is(err, ErrPaymentNotRefundable), errors.Is(err, ErrWalletDisabled):
+		return RefundResultRejected
+	default:
+		return RefundResultError
+	}
+}
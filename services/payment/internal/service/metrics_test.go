@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+)
+
+type fakePaymentMetrics struct {
+	attempts []string // result/method
+	refunds  []string
+	refunded int64
+}
+
+func (m *fakePaymentMetrics) RecordPaymentAttempt(result, method string, d time.Duration) {
+	m.attempts = append(m.attempts, result+"/"+method)
+}
+
+func (m *fakePaymentMetrics) RecordRefund(result string, amount int64, currency string) {
+	m.refunds = append(m.refunds, result)
+	m.refunded += amount
+}
+
+func TestPaymentService_Metrics(t *testing.T) {
+	ctx := context.Background()
+	metrics := &fakePaymentMetrics{}
+	repo := memory.NewMemoryRepository()
+	gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable}}
+	service := NewPaymentService(repo, 1000, gateway, nil, nil, repo, metrics)
+
+	// Act
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Method: "card"})
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Method: "card"})
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-2", UserID: "user-1", Amount: 100, Method: mockprovider.MethodSBP, ManualCapture: true})
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-3", UserID: "user-1", Amount: 5000, Method: "card"})
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-4", UserID: "user-1", Amount: 0, Method: "card"})
+	_, _ = service.Pay(ctx, PaymentInput{OrderID: "order-5", UserID: "user-1", Amount: 100, PaymentMethodID: "method-unknown"})
+
+	// Assert
+	require.Equal(t, []string{
+		"provider_error/card",
+		"success/card",
+		"pending/sbp",
+		"declined/card",
+		"rejected/card",
+		"rejected/" + MethodLabelStored,
+	}, metrics.attempts)
+}
+
+func TestRefundService_Metrics(t *testing.T) {
+	ctx := context.Background()
+	metrics := &fakePaymentMetrics{}
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Save(ctx, repository.Transaction{
+		OrderID: "order-1", TransactionID: "tx_order-1_1", Amount: 100, CapturedAmount: 100, Currency: "RUB", Status: repository.StatusCaptured,
+	}))
+	svc := NewRefundService(repo, memory.NewRefundRepository(repo), nil, nil, 10, 1, metrics)
+
+	// Act
+	_, _, _ = svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 40})
+	_, _, _ = svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 40})
+	_, _, _ = svc.Refund(ctx, RefundInput{OrderID: "order-missing"})
+
+	// Assert
+	require.Equal(t, []string{RefundResultRefunded, RefundResultReplayed, RefundResultRejected}, metrics.refunds)
+	require.Equal(t, int64(40), metrics.refunded)
+}
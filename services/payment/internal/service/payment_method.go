@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// Ошибки сохранённых способов оплаты
+var (
+	// ErrPaymentTokenRequired - не передан токен провайдера
+	ErrPaymentTokenRequired = errors.New("payment method token is required")
+	// ErrInvalidCardLast4 - last4 не из 4 цифр
+	ErrInvalidCardLast4 = errors.New("last4 must be exactly 4 digits")
+	// ErrPaymentMethodIDRequired - не передан ID способа оплаты
+	ErrPaymentMethodIDRequired = errors.New("payment_method_id is required")
+	// ErrPaymentMethodAmbiguous - в оплате заданы и method, и payment_method_id
+	ErrPaymentMethodAmbiguous = errors.New("method and payment_method_id are mutually exclusive")
+)
+
+// PaymentMethodService содержит бизнес-логику сохранённых способов оплаты
+// Токен провайдера сохраняется один раз и наружу не отдаётся: оплата ссылается на способ оплаты по ID
+type PaymentMethodService struct {
+	methods repository.PaymentMethodRepository
+}
+
+// NewPaymentMethodService создаёт сервис сохранённых способов оплаты
+func NewPaymentMethodService(methods repository.PaymentMethodRepository) *PaymentMethodService {
+	return &PaymentMethodService{methods: methods}
+}
+
+// StorePaymentMethodInput - способ оплаты для сохранения
+type StorePaymentMethodInput struct {
+	UserID string
+	Token  string // токен у платёжного провайдера
+	Brand  string // пусто - платёжная система неизвестна
+	Last4  string
+}
+
+// Store сохраняет способ оплаты пользователя
+// created = false, если токен уже сохранён у пользователя: тогда возвращается сохранённый способ оплаты
+func (s *PaymentMethodService) Store(ctx context.Context, input StorePaymentMethodInput) (method repository.PaymentMethod, created bool, err error) {
+	log.Printf("Store payment method called: user=%s, brand=%s, last4=%s", input.UserID, input.Brand, input.Last4)
+
+	if input.UserID == "" {
+		return repository.PaymentMethod{}, false, ErrUserIDRequired
+	}
+	if input.Token == "" {
+		return repository.PaymentMethod{}, false, ErrPaymentTokenRequired
+	}
+	if !isCardLast4(input.Last4) {
+		return repository.PaymentMethod{}, false, ErrInvalidCardLast4
+	}
+
+	now := time.Now().UTC()
+	method = repository.PaymentMethod{
+		ID:        fmt.Sprintf("method_%s_%d", input.UserID, now.UnixNano()),
+		UserID:    input.UserID,
+		Token:     input.Token,
+		Brand:     strings.ToLower(strings.TrimSpace(input.Brand)),
+		Last4:     input.Last4,
+		CreatedAt: now,
+	}
+	if err := s.methods.CreatePaymentMethod(ctx, method); err != nil {
+		if !errors.Is(err, repository.ErrPaymentMethodExists) {
+			return repository.PaymentMethod{}, false, fmt.Errorf("failed to save payment method: %w", err)
+		}
+		existing, err := s.methods.GetPaymentMethodByToken(ctx, input.UserID, input.Token)
+		if err != nil {
+			return repository.PaymentMethod{}, false, fmt.Errorf("failed to get existing payment method: %w", err)
+		}
+		return existing, false, nil
+	}
+
+	log.Printf("Payment method stored: id=%s, user=%s", method.ID, method.UserID)
+	return method, true, nil
+}
+
+// List возвращает способы оплаты пользователя, новые первыми
+func (s *PaymentMethodService) List(ctx context.Context, userID string) ([]repository.PaymentMethod, error) {
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+	methods, err := s.methods.ListPaymentMethods(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	return methods, nil
+}
+
+// Delete удаляет способ оплаты пользователя
+// Способ оплаты другого пользователя не удаляется: для него, как и для несуществующего, - repository.ErrPaymentMethodNotFound
+func (s *PaymentMethodService) Delete(ctx context.Context, userID, methodID string) error {
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+	if methodID == "" {
+		return ErrPaymentMethodIDRequired
+	}
+	if err := s.methods.DeletePaymentMethod(ctx, userID, methodID); err != nil {
+		if errors.Is(err, repository.ErrPaymentMethodNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete payment method: %w", err)
+	}
+	log.Printf("Payment method deleted: id=%s, user=%s", methodID, userID)
+	return nil
+}
+
+// storedMethod возвращает сохранённый способ оплаты methodID пользователя userID
+// Чужой способ оплаты неотличим от несуществующего - repository.ErrPaymentMethodNotFound
+func (s *PaymentService) storedMethod(ctx context.Context, userID, methodID string) (repository.PaymentMethod, error) {
+	if s.methods == nil {
+		return repository.PaymentMethod{}, repository.ErrPaymentMethodNotFound
+	}
+	method, err := s.methods.GetPaymentMethod(ctx, methodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPaymentMethodNotFound) {
+			return repository.PaymentMethod{}, err
+		}
+		return repository.PaymentMethod{}, fmt.Errorf("failed to get payment method: %w", err)
+	}
+	if method.UserID != userID {
+		return repository.PaymentMethod{}, repository.ErrPaymentMethodNotFound
+	}
+	return method, nil
+}
+
+// isCardLast4 проверяет, что last4 - ровно 4 цифры
+func isCardLast4(last4 string) bool {
+	if len(last4) != 4 {
+		return false
+	}
+	for _, c := range last4 {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// authorizeSpyProvider запоминает способ оплаты, с которым вызван Authorize, остальное делегирует mock-провайдеру
+type authorizeSpyProvider struct {
+	provider.PaymentProvider
+	methods []string
+}
+
+func (p *authorizeSpyProvider) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	p.methods = append(p.methods, req.Method)
+	return p.PaymentProvider.Authorize(ctx, req)
+}
+
+func TestPaymentMethodService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores token once per user", func(t *testing.T) {
+		// Arrange
+		svc := NewPaymentMethodService(memory.NewMemoryRepository())
+		input := StorePaymentMethodInput{UserID: "user-1", Token: "pm_card_visa", Brand: "Visa", Last4: "4242"}
+
+		// Act
+		first, created, err := svc.Store(ctx, input)
+		require.NoError(t, err)
+		require.True(t, created)
+		second, created, err := svc.Store(ctx, input)
+
+		// Assert
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, first.ID, second.ID)
+		require.Equal(t, "visa", first.Brand)
+	})
+
+	t.Run("lists user methods newest first", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		now := time.Now().UTC()
+		require.NoError(t, repo.CreatePaymentMethod(ctx, repository.PaymentMethod{ID: "method-1", UserID: "user-1", Token: "pm_1", Last4: "1111", CreatedAt: now.Add(-time.Hour)}))
+		require.NoError(t, repo.CreatePaymentMethod(ctx, repository.PaymentMethod{ID: "method-2", UserID: "user-1", Token: "pm_2", Last4: "2222", CreatedAt: now}))
+		require.NoError(t, repo.CreatePaymentMethod(ctx, repository.PaymentMethod{ID: "method-3", UserID: "user-2", Token: "pm_3", Last4: "3333", CreatedAt: now}))
+		svc := NewPaymentMethodService(repo)
+
+		// Act
+		methods, err := svc.List(ctx, "user-1")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, methods, 2)
+		require.Equal(t, "method-2", methods[0].ID)
+		require.Equal(t, "method-1", methods[1].ID)
+	})
+
+	t.Run("does not delete another user's method", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		svc := NewPaymentMethodService(repo)
+		method, _, err := svc.Store(ctx, StorePaymentMethodInput{UserID: "user-1", Token: "pm_1", Last4: "1111"})
+		require.NoError(t, err)
+
+		// Act
+		err = svc.Delete(ctx, "user-2", method.ID)
+
+		// Assert
+		require.ErrorIs(t, err, repository.ErrPaymentMethodNotFound)
+		require.NoError(t, svc.Delete(ctx, "user-1", method.ID))
+		_, err = repo.GetPaymentMethod(ctx, method.ID)
+		require.ErrorIs(t, err, repository.ErrPaymentMethodNotFound)
+	})
+
+	t.Run("validates input", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			input   StorePaymentMethodInput
+			wantErr error
+		}{
+			{"no user id", StorePaymentMethodInput{Token: "pm_1", Last4: "1111"}, ErrUserIDRequired},
+			{"no token", StorePaymentMethodInput{UserID: "user-1", Last4: "1111"}, ErrPaymentTokenRequired},
+			{"short last4", StorePaymentMethodInput{UserID: "user-1", Token: "pm_1", Last4: "111"}, ErrInvalidCardLast4},
+			{"last4 with letters", StorePaymentMethodInput{UserID: "user-1", Token: "pm_1", Last4: "11a1"}, ErrInvalidCardLast4},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc := NewPaymentMethodService(mocks.NewPaymentMethodRepository(t))
+
+				_, _, err := svc.Store(ctx, tt.input)
+
+				require.ErrorIs(t, err, tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestPaymentService_StoredPaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*memory.MemoryRepository, repository.PaymentMethod) {
+		t.Helper()
+		repo := memory.NewMemoryRepository()
+		method, _, err := NewPaymentMethodService(repo).Store(ctx, StorePaymentMethodInput{UserID: "user-1", Token: "pm_card_visa", Brand: "visa", Last4: "4242"})
+		require.NoError(t, err)
+		return repo, method
+	}
+
+	t.Run("passes stored token to provider", func(t *testing.T) {
+		// Arrange
+		repo, method := setup(t)
+		spy := &authorizeSpyProvider{PaymentProvider: mockprovider.New()}
+		service := NewPaymentService(repo, 100000, spy, nil, nil, repo, nil)
+
+		// Act
+		tx, err := service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 10000, PaymentMethodID: method.ID})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"pm_card_visa"}, spy.methods)
+		require.Equal(t, method.ID, tx.PaymentMethodID)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+	})
+
+	t.Run("another user's method is not found", func(t *testing.T) {
+		// Arrange
+		repo, method := setup(t)
+		service := NewPaymentService(repo, 100000, mockprovider.New(), nil, nil, repo, nil)
+
+		// Act
+		_, err := service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-2", Amount: 10000, PaymentMethodID: method.ID})
+
+		// Assert
+		require.ErrorIs(t, err, repository.ErrPaymentMethodNotFound)
+		_, err = repo.GetByOrderID(ctx, "order-1")
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("method and payment method id are mutually exclusive", func(t *testing.T) {
+		repo, method := setup(t)
+		service := NewPaymentService(repo, 100000, mockprovider.New(), nil, nil, repo, nil)
+
+		_, err := service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 10000, Method: "card", PaymentMethodID: method.ID})
+
+		require.ErrorIs(t, err, ErrPaymentMethodAmbiguous)
+	})
+
+	t.Run("retry after method deletion returns saved transaction", func(t *testing.T) {
+		// Arrange
+		repo, method := setup(t)
+		service := NewPaymentService(repo, 100000, mockprovider.New(), nil, nil, repo, nil)
+		input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 10000, PaymentMethodID: method.ID}
+		first, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeletePaymentMethod(ctx, "user-1", method.ID))
+
+		// Act
+		second, err := service.Pay(ctx, input)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, first.TransactionID, second.TransactionID)
+	})
+}
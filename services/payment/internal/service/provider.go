@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+// Результаты вызова провайдера для метрик
+const (
+	ProviderResultSuccess  = "success"
+	ProviderResultDeclined = "declined" // провайдер ответил отказом (decline)
+	ProviderResultFailure  = "failure"  // провайдер недоступен или ответил ошибкой
+	ProviderResultCanceled = "canceled" // клиент не дождался ответа (дедлайн или отмена)
+)
+
+// ProviderSimulation задаёт имитацию задержки и отказов поверх провайдера
+// mock провайдер отвечает мгновенно, и нагрузочные тесты через Order Service не видят реальных хвостов latency.
+// Нулевая ProviderSimulation - вызовы проходят к провайдеру без задержки и отказов
+type ProviderSimulation struct {
+	Latency     time.Duration // базовая задержка ответа
+	Jitter      time.Duration // случайная добавка к задержке в [0, Jitter)
+	SlowRate    float64       // доля медленных ответов [0, 1]
+	SlowLatency time.Duration // задержка медленного ответа вместо Latency (хвост распределения)
+	FailureRate float64       // доля имитированных отказов provider.ErrUnavailable [0, 1]
+}
+
+// ProviderMetricsRecorder записывает метрики вызовов провайдера (опционально, может быть nil)
+type ProviderMetricsRecorder interface {
+	// RecordProviderCall записывает длительность вызова провайдера и его результат (ProviderResult*)
+	RecordProviderCall(d time.Duration, result string)
+}
+
+// ProviderSimulator оборачивает провайдера: перед каждым вызовом добавляет задержку по ProviderSimulation
+// и случайные отказы, а длительность и результат вызова (вместе с задержкой) пишет в метрики
+// Реализует provider.PaymentProvider
+type ProviderSimulator struct {
+	next    provider.PaymentProvider
+	sim     ProviderSimulation
+	metrics ProviderMetricsRecorder
+	rand    func() float64 // [0, 1); подменяется в тестах
+}
+
+// NewProviderSimulator оборачивает провайдера next
+// metrics может быть nil (метрики не пишутся)
+func NewProviderSimulator(next provider.PaymentProvider, sim ProviderSimulation, metrics ProviderMetricsRecorder) *ProviderSimulator {
+	return &ProviderSimulator{
+		next:    next,
+		sim:     sim,
+		metrics: metrics,
+		rand:    rand.Float64,
+	}
+}
+
+// Authorize вызывает Authorize провайдера после имитации
+func (p *ProviderSimulator) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	var auth provider.Authorization
+	err := p.call(ctx, func() (err error) {
+		auth, err = p.next.Authorize(ctx, req)
+		return err
+	})
+	return auth, err
+}
+
+// Capture вызывает Capture провайдера после имитации
+func (p *ProviderSimulator) Capture(ctx context.Context, paymentID string, amount int64) error {
+	return p.call(ctx, func() error {
+		return p.next.Capture(ctx, paymentID, amount)
+	})
+}
+
+// Void вызывает Void провайдера после имитации
+func (p *ProviderSimulator) Void(ctx context.Context, paymentID string) error {
+	return p.call(ctx, func() error {
+		return p.next.Void(ctx, paymentID)
+	})
+}
+
+// Refund вызывает Refund провайдера после имитации
+func (p *ProviderSimulator) Refund(ctx context.Context, req provider.RefundRequest) (provider.RefundResult, error) {
+	var result provider.RefundResult
+	err := p.call(ctx, func() (err error) {
+		result, err = p.next.Refund(ctx, req)
+		return err
+	})
+	return result, err
+}
+
+// call выполняет имитацию и вызов провайдера fn
+// Возвращает provider.ErrUnavailable при имитированном отказе и ctx.Err(), если клиент не дождался ответа
+func (p *ProviderSimulator) call(ctx context.Context, fn func() error) error {
+	start := time.Now()
+
+	delay := p.sim.Latency
+	if p.sim.SlowRate > 0 && p.rand() < p.sim.SlowRate {
+		delay = p.sim.SlowLatency
+	}
+	if p.sim.Jitter > 0 {
+		delay += time.Duration(p.rand() * float64(p.sim.Jitter))
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			p.record(start, ProviderResultCanceled)
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if p.sim.FailureRate > 0 && p.rand() < p.sim.FailureRate {
+		p.record(start, ProviderResultFailure)
+		return provider.ErrUnavailable
+	}
+
+	err := fn()
+	var declineErr *provider.DeclineError
+	switch {
+	case err == nil:
+		p.record(start, ProviderResultSuccess)
+	case errors.As(err, &declineErr):
+		p.record(start, ProviderResultDeclined)
+	case ctx.Err() != nil:
+		p.record(start, ProviderResultCanceled)
+	default:
+		p.record(start, ProviderResultFailure)
+	}
+	return err
+}
+
+func (p *ProviderSimulator) record(start time.Time, result string) {
+	if p.metrics != nil {
+		p.metrics.RecordProviderCall(time.Since(start), result)
+	}
+}
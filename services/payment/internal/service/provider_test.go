@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedCall - вызов провайдера, записанный fakeProviderMetrics
+type recordedCall struct {
+	d      time.Duration
+	result string
+}
+
+type fakeProviderMetrics struct {
+	calls []recordedCall
+}
+
+func (m *fakeProviderMetrics) RecordProviderCall(d time.Duration, result string) {
+	m.calls = append(m.calls, recordedCall{d: d, result: result})
+}
+
+func TestProviderSimulator(t *testing.T) {
+	ctx := context.Background()
+	authorize := provider.AuthorizeRequest{OrderID: "order-1", Amount: 100, Currency: "RUB", Method: "card"}
+
+	t.Run("no simulation: instant success", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{}, metrics)
+
+		auth, err := simulator.Authorize(ctx, authorize)
+		require.NoError(t, err)
+		require.Equal(t, "mock_pay_order-1", auth.PaymentID)
+		require.Len(t, metrics.calls, 1)
+		require.Equal(t, ProviderResultSuccess, metrics.calls[0].result)
+	})
+
+	t.Run("latency with jitter is recorded", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{Latency: 20 * time.Millisecond, Jitter: 20 * time.Millisecond}, metrics)
+		simulator.rand = func() float64 { return 0.5 }
+
+		_, err := simulator.Authorize(ctx, authorize)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, metrics.calls[0].d, 30*time.Millisecond)
+	})
+
+	t.Run("slow response uses slow latency", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{Latency: time.Millisecond, SlowRate: 0.1, SlowLatency: 40 * time.Millisecond}, metrics)
+		simulator.rand = func() float64 { return 0.05 }
+
+		_, err := simulator.Authorize(ctx, authorize)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, metrics.calls[0].d, 40*time.Millisecond)
+	})
+
+	t.Run("simulated failure", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{FailureRate: 0.2}, metrics)
+		simulator.rand = func() float64 { return 0.1 }
+
+		_, err := simulator.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		require.Equal(t, ProviderResultFailure, metrics.calls[0].result)
+	})
+
+	t.Run("provider decline is recorded as declined", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{}, metrics)
+
+		_, err := simulator.Authorize(ctx, provider.AuthorizeRequest{OrderID: "order-1", Amount: 100, Method: mockprovider.MethodRisk})
+
+		var declineErr *provider.DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, ProviderResultDeclined, metrics.calls[0].result)
+	})
+
+	t.Run("deadline shorter than latency", func(t *testing.T) {
+		metrics := &fakeProviderMetrics{}
+		simulator := NewProviderSimulator(mockprovider.New(), ProviderSimulation{Latency: time.Minute}, metrics)
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := simulator.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, ProviderResultCanceled, metrics.calls[0].result)
+	})
+}
+
+func TestPaymentService_ProcessPayment_Provider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("provider failure declines with provider_error, decline not saved", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, NewProviderSimulator(mockprovider.New(), ProviderSimulation{FailureRate: 1}, nil), nil, nil, nil, nil)
+		mockRepo.On("GetByOrderID", ctx, "order-1").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", "card")
+
+		// Assert
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineProviderError, declineErr.Reason)
+		require.False(t, success)
+		require.Empty(t, transactionID)
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
+	})
+
+	t.Run("provider success saves transaction", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, NewProviderSimulator(mockprovider.New(), ProviderSimulation{Latency: time.Millisecond}, nil), nil, nil, nil, nil)
+		mockRepo.On("GetByOrderID", ctx, "order-1").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.AnythingOfType("repository.Transaction"), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", "card")
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, success)
+		require.NotEmpty(t, transactionID)
+	})
+
+	t.Run("provider decline is saved with mapped reason", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+		mockRepo.On("GetByOrderID", ctx, "order-1").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
+			return tx.Status == repository.StatusFailed && tx.DeclineReason == string(DeclineInsufficientFunds)
+		}), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+
+		// Act
+		_, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", mockprovider.MethodInsufficientFunds)
+
+		// Assert
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineInsufficientFunds, declineErr.Reason)
+		require.False(t, success)
+	})
+
+	t.Run("provider payment ID is saved for refunds", func(t *testing.T) {
+		// Arrange
+		paymentRepo := memory.NewMemoryRepository()
+		gateway := mockprovider.New()
+		service := NewPaymentService(paymentRepo, 1000, gateway, nil, nil, nil, nil)
+		refunds := NewRefundService(paymentRepo, memory.NewRefundRepository(paymentRepo), gateway, nil, 10, 1, nil)
+
+		// Act
+		_, _, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", "card")
+		require.NoError(t, err)
+		refund, _, refundErr := refunds.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 40})
+		_, _, notFoundErr := refunds.Refund(ctx, RefundInput{OrderID: "order-2"})
+
+		// Assert
+		require.NoError(t, refundErr)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, "mock_pay_order-1", tx.ProviderPaymentID)
+		require.Equal(t, "mock_re_rf_order-1", refund.ProviderRefundID)
+		require.ErrorIs(t, notFoundErr, ErrPaymentNotFound)
+	})
+}
+
+func TestPaymentService_HandlePaymentEvent(t *testing.T) {
+	ctx := context.Background()
+
+	// pendingPayment создаёт отложенный платёж СБП через mock провайдер
+	pendingPayment := func(t *testing.T) (*PaymentService, *memory.MemoryRepository) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", mockprovider.MethodSBP)
+		require.NoError(t, err)
+		require.False(t, success)
+		require.NotEmpty(t, transactionID)
+
+		// Повтор ProcessPayment до webhook'а - то же ожидание
+		repeatedID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", mockprovider.MethodSBP)
+		require.NoError(t, err)
+		require.False(t, success)
+		require.Equal(t, transactionID, repeatedID)
+		return service, paymentRepo
+	}
+
+	t.Run("succeeded completes pending payment, repeated event is a no-op", func(t *testing.T) {
+		service, paymentRepo := pendingPayment(t)
+		event := provider.PaymentEvent{ID: "evt_1", Type: provider.PaymentEventSucceeded, PaymentID: "mock_pay_order-1"}
+
+		require.NoError(t, service.HandlePaymentEvent(ctx, event))
+		require.NoError(t, service.HandlePaymentEvent(ctx, event))
+
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+		_, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", mockprovider.MethodSBP)
+		require.NoError(t, err)
+		require.True(t, success)
+	})
+
+	t.Run("failed declines with mapped reason, late success ignored", func(t *testing.T) {
+		service, paymentRepo := pendingPayment(t)
+
+		require.NoError(t, service.HandlePaymentEvent(ctx, provider.PaymentEvent{
+			ID: "evt_1", Type: provider.PaymentEventFailed, PaymentID: "mock_pay_order-1", DeclineCode: provider.DeclineInsufficientFunds,
+		}))
+		require.NoError(t, service.HandlePaymentEvent(ctx, provider.PaymentEvent{
+			ID: "evt_2", Type: provider.PaymentEventSucceeded, PaymentID: "mock_pay_order-1",
+		}))
+
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusFailed, tx.Status)
+		require.Equal(t, string(DeclineInsufficientFunds), tx.DeclineReason)
+		_, _, err = service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", mockprovider.MethodSBP)
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineInsufficientFunds, declineErr.Reason)
+	})
+
+	t.Run("unknown payment returns ErrNotFound", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		err := service.HandlePaymentEvent(ctx, provider.PaymentEvent{ID: "evt_1", Type: provider.PaymentEventSucceeded, PaymentID: "pi_unknown"})
+
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("unsupported event type is ignored", func(t *testing.T) {
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, nil, nil, nil, nil, nil)
+
+		require.NoError(t, service.HandlePaymentEvent(ctx, provider.PaymentEvent{ID: "evt_1", PaymentID: "pi_1"}))
+	})
+
+	t.Run("concurrent status change is not an error", func(t *testing.T) {
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, nil, nil, nil, nil, nil)
+		mockRepo.On("GetByProviderPaymentID", ctx, "pi_1").
+			Return(repository.Transaction{TransactionID: "tx-1", Status: repository.StatusPending}, nil).Once()
+		mockRepo.On("CaptureWithOutbox", ctx, "tx-1", repository.StatusPending, int64(0), mock.AnythingOfType("repository.OutboxEvent")).
+			Return(repository.ErrStatusConflict).Once()
+
+		err := service.HandlePaymentEvent(ctx, provider.PaymentEvent{ID: "evt_1", Type: provider.PaymentEventSucceeded, PaymentID: "pi_1"})
+
+		require.NoError(t, err)
+	})
+}
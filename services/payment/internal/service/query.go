@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// DefaultTransactionPageSize - размер страницы ListTransactions, если он не задан
+const DefaultTransactionPageSize = 50
+
+// MaxTransactionPageSize - максимальный размер страницы ListTransactions
+const MaxTransactionPageSize = 200
+
+var (
+	// ErrPaymentLookupRequired возвращается GetPayment без order_id и transaction_id
+	ErrPaymentLookupRequired = errors.New("order_id or transaction_id is required")
+	// ErrInvalidPageToken возвращается, если page_token не выдан ListTransactions
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// ListTransactionsInput - параметры страницы транзакций пользователя
+type ListTransactionsInput struct {
+	UserID    string
+	PageSize  int    // 0 - DefaultTransactionPageSize, больше MaxTransactionPageSize - обрезается
+	PageToken string // NextPageToken предыдущей страницы; пусто - первая страница
+}
+
+// TransactionPage - страница транзакций пользователя
+type TransactionPage struct {
+	Transactions  []repository.Transaction // новые первыми
+	NextPageToken string                   // пусто - страница последняя
+}
+
+// GetPayment возвращает транзакцию по orderID или transactionID
+// Если заданы оба, транзакция должна подходить под оба: иначе repository.ErrNotFound
+func (s *PaymentService) GetPayment(ctx context.Context, orderID, transactionID string) (repository.Transaction, error) {
+	var (
+		tx  repository.Transaction
+		err error
+	)
+	switch {
+	case orderID != "":
+		tx, err = s.repo.GetByOrderID(ctx, orderID)
+	case transactionID != "":
+		tx, err = s.repo.GetByTransactionID(ctx, transactionID)
+	default:
+		return repository.Transaction{}, ErrPaymentLookupRequired
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.Transaction{}, err
+		}
+		return repository.Transaction{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if transactionID != "" && tx.TransactionID != transactionID {
+		return repository.Transaction{}, fmt.Errorf("%w: order %s has transaction %s", repository.ErrNotFound, orderID, tx.TransactionID)
+	}
+	return tx, nil
+}
+
+// ListTransactions возвращает страницу транзакций пользователя, новые первыми
+func (s *PaymentService) ListTransactions(ctx context.Context, input ListTransactionsInput) (TransactionPage, error) {
+	if input.UserID == "" {
+		return TransactionPage{}, ErrUserIDRequired
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultTransactionPageSize
+	}
+	if pageSize > MaxTransactionPageSize {
+		pageSize = MaxTransactionPageSize
+	}
+	after, err := decodeTransactionPageToken(input.PageToken)
+	if err != nil {
+		return TransactionPage{}, err
+	}
+
+	// Лишняя строка показывает, есть ли следующая страница
+	transactions, err := s.repo.ListByUserID(ctx, input.UserID, after, pageSize+1)
+	if err != nil {
+		return TransactionPage{}, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	page := TransactionPage{Transactions: transactions}
+	if len(transactions) > pageSize {
+		page.Transactions = transactions[:pageSize]
+		last := page.Transactions[pageSize-1]
+		page.NextPageToken = encodeTransactionPageToken(repository.TransactionCursor{
+			CreatedAt:     last.CreatedAt,
+			TransactionID: last.TransactionID,
+		})
+	}
+	return page, nil
+}
+
+// encodeTransactionPageToken упаковывает позицию последней транзакции страницы в непрозрачный для клиента токен
+func encodeTransactionPageToken(cursor repository.TransactionCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(cursor.CreatedAt, 10) + ":" + cursor.TransactionID))
+}
+
+// decodeTransactionPageToken возвращает позицию, после которой начинается страница; nil - первая страница
+func decodeTransactionPageToken(token string) (*repository.TransactionCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	createdAt, transactionID, ok := strings.Cut(string(raw), ":")
+	if !ok || transactionID == "" {
+		return nil, ErrInvalidPageToken
+	}
+	unix, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &repository.TransactionCursor{CreatedAt: unix, TransactionID: transactionID}, nil
+}
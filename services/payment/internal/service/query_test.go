@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+)
+
+func TestPaymentService_GetPayment(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Save(ctx, repository.Transaction{OrderID: "order-1", UserID: "user-1", TransactionID: "tx-1", Status: repository.StatusCaptured}))
+	service := NewPaymentService(repo, 1000, nil, nil, nil, nil, nil)
+
+	byOrder, err := service.GetPayment(ctx, "order-1", "")
+	require.NoError(t, err)
+	require.Equal(t, "tx-1", byOrder.TransactionID)
+
+	byTransaction, err := service.GetPayment(ctx, "", "tx-1")
+	require.NoError(t, err)
+	require.Equal(t, "order-1", byTransaction.OrderID)
+
+	_, err = service.GetPayment(ctx, "order-1", "tx-2")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = service.GetPayment(ctx, "order-2", "")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = service.GetPayment(ctx, "", "")
+	require.ErrorIs(t, err, ErrPaymentLookupRequired)
+}
+
+func TestPaymentService_ListTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages follow created_at, then transaction_id, newest first", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+		for _, tx := range []repository.Transaction{
+			{OrderID: "order-1", UserID: "user-1", TransactionID: "tx-1", CreatedAt: 100},
+			{OrderID: "order-2", UserID: "user-1", TransactionID: "tx-2", CreatedAt: 200},
+			{OrderID: "order-3", UserID: "user-1", TransactionID: "tx-3", CreatedAt: 200},
+			{OrderID: "order-4", UserID: "user-2", TransactionID: "tx-4", CreatedAt: 300},
+		} {
+			require.NoError(t, repo.Save(ctx, tx))
+		}
+		service := NewPaymentService(repo, 1000, nil, nil, nil, nil, nil)
+
+		first, err := service.ListTransactions(ctx, ListTransactionsInput{UserID: "user-1", PageSize: 2})
+		require.NoError(t, err)
+		second, err := service.ListTransactions(ctx, ListTransactionsInput{UserID: "user-1", PageSize: 2, PageToken: first.NextPageToken})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"tx-3", "tx-2"}, transactionIDs(first.Transactions))
+		require.NotEmpty(t, first.NextPageToken)
+		require.Equal(t, []string{"tx-1"}, transactionIDs(second.Transactions))
+		require.Empty(t, second.NextPageToken)
+	})
+
+	t.Run("page size is capped", func(t *testing.T) {
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, nil, nil, nil, nil, nil)
+		mockRepo.On("ListByUserID", ctx, "user-1", (*repository.TransactionCursor)(nil), MaxTransactionPageSize+1).
+			Return(nil, errors.New("connection refused")).Once()
+
+		_, err := service.ListTransactions(ctx, ListTransactionsInput{UserID: "user-1", PageSize: 1000})
+
+		require.ErrorContains(t, err, "connection refused")
+	})
+
+	tests := []struct {
+		name          string
+		input         ListTransactionsInput
+		expectedError error
+	}{
+		{name: "no user", input: ListTransactionsInput{}, expectedError: ErrUserIDRequired},
+		{name: "garbage token", input: ListTransactionsInput{UserID: "user-1", PageToken: "!!!"}, expectedError: ErrInvalidPageToken},
+		{name: "token without transaction", input: ListTransactionsInput{UserID: "user-1", PageToken: "MTAw"}, expectedError: ErrInvalidPageToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewPaymentService(mocks.NewPaymentRepository(t), 1000, nil, nil, nil, nil, nil)
+
+			_, err := service.ListTransactions(ctx, tt.input)
+
+			require.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func transactionIDs(transactions []repository.Transaction) []string {
+	ids := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		ids = append(ids, tx.TransactionID)
+	}
+	return ids
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// Ошибки возврата
+var (
+	// ErrOrderIDRequired - в позиции возврата не передан order_id
+	ErrOrderIDRequired = errors.New("order_id is required")
+	// ErrPaymentNotFound - у заказа нет платежа
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrPaymentNotRefundable - платёж нельзя вернуть (например, в оплате было отказано)
+	ErrPaymentNotRefundable = errors.New("payment is not refundable")
+	// ErrInvalidRefundAmount - отрицательная сумма возврата
+	ErrInvalidRefundAmount = errors.New("refund amount must not be negative")
+	// ErrRefundAmountExceeded - сумма возврата больше остатка: списанной суммы за вычетом прежних возвратов
+	ErrRefundAmountExceeded = errors.New("refund amount exceeds refundable amount")
+	// ErrRefundBatchEmpty - в пакете возвратов нет позиций
+	ErrRefundBatchEmpty = errors.New("refund batch is empty")
+	// ErrRefundBatchTooLarge - позиций в пакете больше лимита
+	ErrRefundBatchTooLarge = errors.New("refund batch is too large")
+)
+
+// RefundInput - запрос на возврат платежа заказа
+type RefundInput struct {
+	OrderID string
+	Amount  int64 // в минимальных единицах валюты платежа; 0 - весь остаток списанной суммы
+	Reason  string
+	// IdempotencyKey различает частичные возвраты одного заказа: повтор с тем же ключом возвращает
+	// сохранённый возврат. Без ключа у заказа один возврат, как и до частичных возвратов
+	IdempotencyKey string
+	// ToWallet - вернуть деньги на кошелёк пользователя, а не через провайдера
+	// Платёж с кошелька (MethodWallet) возвращается на кошелёк всегда
+	ToWallet bool
+}
+
+// RefundItemResult - результат возврата одной позиции пакета
+type RefundItemResult struct {
+	OrderID  string
+	Refund   repository.Refund // заполнен, если Err == nil
+	Replayed bool              // возврат уже был сделан раньше, повторно деньги не возвращались
+	Err      error
+}
+
+// RefundService содержит бизнес-логику возвратов
+// Списанная сумма транзакции заказа возвращается целиком или частями, пока не исчерпан остаток;
+// повтор возврата (тот же заказ и ключ идемпотентности) возвращает уже сохранённый возврат
+type RefundService struct {
+	payments       repository.PaymentRepository
+	refunds        repository.RefundRepository
+	provider       provider.PaymentProvider
+	wallets        repository.WalletRepository
+	maxBatchSize   int
+	maxConcurrency int
+	metrics        PaymentMetricsRecorder
+}
+
+// NewRefundService создаёт сервис возвратов
+// paymentProvider может быть nil: тогда возврат только записывается, деньги через провайдера не возвращаются
+// wallets может быть nil: тогда возврат на кошелёк недоступен
+// maxBatchSize - сколько позиций принимает RefundBatch, maxConcurrency - сколько из них обрабатываются одновременно
+// metrics может быть nil (метрики не пишутся)
+func NewRefundService(payments repository.PaymentRepository, refunds repository.RefundRepository, paymentProvider provider.PaymentProvider, wallets repository.WalletRepository, maxBatchSize, maxConcurrency int, metrics PaymentMetricsRecorder) *RefundService {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return &RefundService{
+		payments:       payments,
+		refunds:        refunds,
+		provider:       paymentProvider,
+		wallets:        wallets,
+		maxBatchSize:   maxBatchSize,
+		maxConcurrency: maxConcurrency,
+		metrics:        metrics,
+	}
+}
+
+// Refund возвращает платёж заказа: input.Amount или, если он 0, весь остаток - списанную сумму за вычетом прежних возвратов
+// Транзакция переходит в StatusRefunded, когда остаток исчерпан; до этого она остаётся captured и возвращается частями.
+// replayed = true, если возврат с тем же ключом идемпотентности уже был сделан: тогда возвращается сохранённый
+// возврат без сверки суммы, поэтому повтор шага саги отмены заказа не приводит к ошибке
+// Каждый вызов, в том числе позиция RefundBatch, записывается в метрики
+func (s *RefundService) Refund(ctx context.Context, input RefundInput) (refund repository.Refund, replayed bool, err error) {
+	refund, replayed, err = s.refund(ctx, input)
+	if s.metrics != nil {
+		result := refundResult(replayed, err)
+		if result == RefundResultRefunded {
+			s.metrics.RecordRefund(result, refund.Amount, refund.Currency)
+		} else {
+			s.metrics.RecordRefund(result, 0, "")
+		}
+	}
+	return refund, replayed, err
+}
+
+// refund выполняет возврат, см. Refund
+func (s *RefundService) refund(ctx context.Context, input RefundInput) (refund repository.Refund, replayed bool, err error) {
+	if input.OrderID == "" {
+		return repository.Refund{}, false, ErrOrderIDRequired
+	}
+	if input.Amount < 0 {
+		return repository.Refund{}, false, ErrInvalidRefundAmount
+	}
+
+	refundID := fmt.Sprintf("rf_%s", input.OrderID)
+	if input.IdempotencyKey != "" {
+		refundID = fmt.Sprintf("rf_%s_%s", input.OrderID, input.IdempotencyKey)
+	}
+	existing, err := s.refunds.GetRefund(ctx, refundID)
+	if err == nil {
+		return existing, true, nil
+	}
+	if !errors.Is(err, repository.ErrRefundNotFound) {
+		return repository.Refund{}, false, fmt.Errorf("failed to check existing refund: %w", err)
+	}
+
+	tx, err := s.payments.GetByOrderID(ctx, input.OrderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.Refund{}, false, ErrPaymentNotFound
+		}
+		return repository.Refund{}, false, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if err := validateTransition(tx.Status, repository.StatusRefunded); err != nil {
+		return repository.Refund{}, false, fmt.Errorf("%w: %v", ErrPaymentNotRefundable, err)
+	}
+	remaining := tx.CapturedAmount - tx.RefundedAmount
+	if remaining <= 0 {
+		return repository.Refund{}, false, fmt.Errorf("%w: nothing left to refund", ErrPaymentNotRefundable)
+	}
+	amount := remaining
+	if input.Amount > 0 {
+		if input.Amount > remaining {
+			return repository.Refund{}, false, fmt.Errorf("%w: %s > %s", ErrRefundAmountExceeded, formatAmount(input.Amount, tx.Currency), formatAmount(remaining, tx.Currency))
+		}
+		amount = input.Amount
+	}
+
+	refund = repository.Refund{
+		RefundID:      refundID,
+		OrderID:       input.OrderID,
+		TransactionID: tx.TransactionID,
+		Amount:        amount,
+		Currency:      tx.Currency,
+		Reason:        input.Reason,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	// Деньги возвращает провайдер или зачисление на кошелёк; ключ идемпотентности - refund_id, поэтому
+	// конкурентный или повторный после сбоя сохранения запрос не вернёт деньги второй раз
+	if input.ToWallet || tx.Method == MethodWallet {
+		if s.wallets == nil {
+			return repository.Refund{}, false, ErrWalletDisabled
+		}
+		op, _, err := creditWallet(ctx, s.wallets, repository.WalletOperation{
+			OperationID: refund.RefundID,
+			UserID:      tx.UserID,
+			Currency:    tx.Currency,
+			Kind:        repository.WalletOperationRefund,
+			Amount:      amount,
+			Reference:   input.OrderID,
+			CreatedAt:   refund.CreatedAt,
+		})
+		if err != nil {
+			return repository.Refund{}, false, fmt.Errorf("wallet refund failed: %w", err)
+		}
+		refund.WalletOperationID = op.OperationID
+	} else if s.provider != nil && tx.ProviderPaymentID != "" {
+		result, err := s.provider.Refund(ctx, provider.RefundRequest{
+			PaymentID:      tx.ProviderPaymentID,
+			Amount:         amount,
+			Reason:         input.Reason,
+			IdempotencyKey: refund.RefundID,
+		})
+		if err != nil {
+			var declineErr *provider.DeclineError
+			if errors.As(err, &declineErr) {
+				return repository.Refund{}, false, fmt.Errorf("%w: %v", ErrPaymentNotRefundable, declineErr)
+			}
+			return repository.Refund{}, false, fmt.Errorf("provider refund failed: %w", err)
+		}
+		refund.ProviderRefundID = result.RefundID
+	}
+
+	// Возврат сохраняется вместе с увеличением RefundedAmount транзакции: конкурентные частичные возвраты
+	// с разными ключами не вернут в сумме больше списанного
+	if err := s.refunds.CreateRefund(ctx, refund); err != nil {
+		if errors.Is(err, repository.ErrRefundAlreadyExists) {
+			// Конкурентный запрос с тем же ключом вернул деньги раньше: отдаём его возврат
+			existing, getErr := s.refunds.GetRefund(ctx, refund.RefundID)
+			if getErr != nil {
+				return repository.Refund{}, false, fmt.Errorf("failed to get concurrent refund: %w", getErr)
+			}
+			return existing, true, nil
+		}
+		if errors.Is(err, repository.ErrRefundLimitExceeded) {
+			log.Printf("Refund exceeds remaining amount after concurrent refund: refund=%s, order=%s", refund.RefundID, refund.OrderID)
+			return repository.Refund{}, false, fmt.Errorf("%w: concurrent refund of order %s", ErrRefundAmountExceeded, input.OrderID)
+		}
+		return repository.Refund{}, false, fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	log.Printf("Refund created: refund=%s, order=%s, amount=%s, remaining=%s", refund.RefundID, refund.OrderID,
+		formatAmount(refund.Amount, refund.Currency), formatAmount(remaining-refund.Amount, refund.Currency))
+	return refund, false, nil
+}
+
+// RefundBatch возвращает платежи нескольких заказов для массовых возвратов поддержки (например, после сбоя сборки)
+// Позиции обрабатываются параллельно, не больше maxConcurrency одновременно; ошибка одной позиции
+// не останавливает остальные. Результаты - в порядке items
+// Ошибкой всего вызова бывают только пустой пакет (ErrRefundBatchEmpty) и превышение лимита (ErrRefundBatchTooLarge);
+// если клиент отменил запрос, ещё не начатые позиции получают ctx.Err()
+func (s *RefundService) RefundBatch(ctx context.Context, items []RefundInput) ([]RefundItemResult, error) {
+	if len(items) == 0 {
+		return nil, ErrRefundBatchEmpty
+	}
+	if len(items) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: %d items, at most %d", ErrRefundBatchTooLarge, len(items), s.maxBatchSize)
+	}
+
+	log.Printf("RefundBatch called: items=%d, concurrency=%d", len(items), s.maxConcurrency)
+
+	results := make([]RefundItemResult, len(items))
+	sem := make(chan struct{}, s.maxConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		results[i].OrderID = item.OrderID
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Refund, results[i].Replayed, results[i].Err = s.Refund(ctx, item)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	log.Printf("RefundBatch finished: items=%d, failed=%d", len(items), failed)
+	return results, nil
+}
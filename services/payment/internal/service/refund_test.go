@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundService_Refund(t *testing.T) {
+	ctx := context.Background()
+	paidTx := repository.Transaction{
+		OrderID:        "order-1",
+		Amount:         15050,
+		CapturedAmount: 15050,
+		Currency:       "RUB",
+		TransactionID:  "tx_order-1_1",
+		Status:         repository.StatusCaptured,
+	}
+
+	t.Run("refunds full amount linked to transaction", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(paidTx, nil).Once()
+		refundRepo.On("CreateRefund", ctx, mock.MatchedBy(func(r repository.Refund) bool {
+			return r.RefundID == "rf_order-1" && r.TransactionID == "tx_order-1_1" &&
+				r.Amount == 15050 && r.Currency == "RUB" && r.Reason == "INC-42"
+		})).Return(nil).Once()
+
+		// Act
+		refund, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Reason: "INC-42"})
+
+		// Assert
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, "rf_order-1", refund.RefundID)
+	})
+
+	t.Run("refunds requested part of the amount", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(paidTx, nil).Once()
+		refundRepo.On("CreateRefund", ctx, mock.MatchedBy(func(r repository.Refund) bool {
+			return r.TransactionID == "tx_order-1_1" && r.Amount == 5000
+		})).Return(nil).Once()
+
+		// Act
+		refund, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 5000, Reason: "order cancelled"})
+
+		// Assert
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, int64(5000), refund.Amount)
+	})
+
+	t.Run("already refunded order returns saved refund", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		saved := repository.Refund{RefundID: "rf_order-1", OrderID: "order-1", Amount: 15050}
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(saved, nil).Once()
+
+		// Act
+		refund, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1"})
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, saved, refund)
+		paymentRepo.AssertNotCalled(t, "GetByOrderID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("idempotency key separates partial refunds", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		partlyRefundedTx := paidTx
+		partlyRefundedTx.RefundedAmount = 5000
+		refundRepo.On("GetRefund", ctx, "rf_order-1_item-2").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(partlyRefundedTx, nil).Once()
+		refundRepo.On("CreateRefund", ctx, mock.MatchedBy(func(r repository.Refund) bool {
+			return r.RefundID == "rf_order-1_item-2" && r.Amount == 10050
+		})).Return(nil).Once()
+
+		// Act
+		refund, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", IdempotencyKey: "item-2"})
+
+		// Assert: без суммы возвращается остаток
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, int64(10050), refund.Amount)
+	})
+
+	t.Run("concurrent refund exhausts remaining amount", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		refundRepo.On("GetRefund", ctx, "rf_order-1_item-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(paidTx, nil).Once()
+		refundRepo.On("CreateRefund", ctx, mock.Anything).Return(repository.ErrRefundLimitExceeded).Once()
+
+		// Act
+		_, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 10000, IdempotencyKey: "item-1"})
+
+		// Assert
+		require.ErrorIs(t, err, ErrRefundAmountExceeded)
+	})
+
+	t.Run("concurrent refund wins the race", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		winner := repository.Refund{RefundID: "rf_order-1", OrderID: "order-1", Reason: "first"}
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(paidTx, nil).Once()
+		refundRepo.On("CreateRefund", ctx, mock.Anything).Return(repository.ErrRefundAlreadyExists).Once()
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(winner, nil).Once()
+
+		// Act
+		refund, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Reason: "second"})
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, "first", refund.Reason)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			orderID string
+			tx      repository.Transaction
+			txErr   error
+			err     error
+		}{
+			{"empty order", "", repository.Transaction{}, nil, ErrOrderIDRequired},
+			{"no payment", "order-1", repository.Transaction{}, repository.ErrNotFound, ErrPaymentNotFound},
+			{"declined payment", "order-1", repository.Transaction{Status: repository.StatusFailed}, nil, ErrPaymentNotRefundable},
+			{"nothing captured", "order-1", repository.Transaction{Amount: 100, Status: repository.StatusCaptured}, nil, ErrPaymentNotRefundable},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				// Arrange
+				paymentRepo := mocks.NewPaymentRepository(t)
+				refundRepo := mocks.NewRefundRepository(t)
+				svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+				if tc.orderID != "" {
+					refundRepo.On("GetRefund", ctx, "rf_"+tc.orderID).Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+					paymentRepo.On("GetByOrderID", ctx, tc.orderID).Return(tc.tx, tc.txErr).Once()
+				}
+
+				// Act
+				_, _, err := svc.Refund(ctx, RefundInput{OrderID: tc.orderID})
+
+				// Assert
+				require.ErrorIs(t, err, tc.err)
+				refundRepo.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		// Arrange
+		paymentRepo := mocks.NewPaymentRepository(t)
+		refundRepo := mocks.NewRefundRepository(t)
+		svc := NewRefundService(paymentRepo, refundRepo, nil, nil, 10, 2, nil)
+
+		partlyRefundedTx := paidTx
+		partlyRefundedTx.RefundedAmount = 5000
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Once()
+		paymentRepo.On("GetByOrderID", ctx, "order-1").Return(partlyRefundedTx, nil).Once()
+
+		// Act
+		_, _, negativeErr := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: -1})
+		_, _, exceededErr := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 10051})
+
+		// Assert
+		require.ErrorIs(t, negativeErr, ErrInvalidRefundAmount)
+		require.ErrorIs(t, exceededErr, ErrRefundAmountExceeded)
+		refundRepo.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRefundService_PartialRefunds(t *testing.T) {
+	ctx := context.Background()
+	paymentRepo := memory.NewMemoryRepository()
+	require.NoError(t, paymentRepo.Save(ctx, repository.Transaction{
+		OrderID: "order-1", TransactionID: "tx_order-1_1", Amount: 100, CapturedAmount: 100, Currency: "RUB", Status: repository.StatusCaptured,
+	}))
+	svc := NewRefundService(paymentRepo, memory.NewRefundRepository(paymentRepo), nil, nil, 10, 2, nil)
+
+	// Act
+	first, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 30, IdempotencyKey: "item-1"})
+	require.NoError(t, err)
+	_, replayed, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 30, IdempotencyKey: "item-1"})
+	require.NoError(t, err)
+	require.True(t, replayed)
+	tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+	require.NoError(t, err)
+	require.Equal(t, repository.StatusCaptured, tx.Status)
+	_, _, exceededErr := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 80, IdempotencyKey: "item-2"})
+	rest, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", IdempotencyKey: "item-2"})
+	require.NoError(t, err)
+	_, _, exhaustedErr := svc.Refund(ctx, RefundInput{OrderID: "order-1", IdempotencyKey: "item-3"})
+
+	// Assert
+	require.Equal(t, "rf_order-1_item-1", first.RefundID)
+	require.ErrorIs(t, exceededErr, ErrRefundAmountExceeded)
+	require.Equal(t, int64(70), rest.Amount)
+	require.ErrorIs(t, exhaustedErr, ErrPaymentNotRefundable)
+	tx, err = paymentRepo.GetByOrderID(ctx, "order-1")
+	require.NoError(t, err)
+	require.Equal(t, repository.StatusRefunded, tx.Status)
+	require.Equal(t, int64(100), tx.RefundedAmount)
+}
+
+// slowPaymentRepository считает одновременные чтения транзакций, чтобы проверить ограничение параллелизма
+type slowPaymentRepository struct {
+	*memory.MemoryRepository
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+}
+
+func (r *slowPaymentRepository) GetByOrderID(ctx context.Context, orderID string) (repository.Transaction, error) {
+	n := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		seen := r.maxSeen.Load()
+		if n <= seen || r.maxSeen.CompareAndSwap(seen, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return r.MemoryRepository.GetByOrderID(ctx, orderID)
+}
+
+func TestRefundService_RefundBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("per-item results in request order with bounded concurrency", func(t *testing.T) {
+		// Arrange
+		paymentRepo := &slowPaymentRepository{MemoryRepository: memory.NewMemoryRepository()}
+		orderIDs := []string{"order-1", "order-2", "order-3", "order-4", "order-5", "order-6"}
+		for _, id := range orderIDs {
+			require.NoError(t, paymentRepo.Save(ctx, repository.Transaction{
+				OrderID: id, Amount: 10, CapturedAmount: 10, Currency: "RUB", TransactionID: "tx_" + id, Status: repository.StatusCaptured,
+			}))
+		}
+		require.NoError(t, paymentRepo.Save(ctx, repository.Transaction{OrderID: "order-declined", Status: repository.StatusFailed}))
+		svc := NewRefundService(paymentRepo, memory.NewRefundRepository(paymentRepo.MemoryRepository), nil, nil, 10, 2, nil)
+
+		items := []RefundInput{{OrderID: "order-missing"}, {OrderID: "order-declined"}}
+		for _, id := range orderIDs {
+			items = append(items, RefundInput{OrderID: id, Reason: "INC-42"})
+		}
+
+		// Act
+		results, err := svc.RefundBatch(ctx, items)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, len(items))
+		require.ErrorIs(t, results[0].Err, ErrPaymentNotFound)
+		require.ErrorIs(t, results[1].Err, ErrPaymentNotRefundable)
+		for i, id := range orderIDs {
+			r := results[i+2]
+			require.Equal(t, id, r.OrderID)
+			require.NoError(t, r.Err)
+			require.False(t, r.Replayed)
+			require.Equal(t, "tx_"+id, r.Refund.TransactionID)
+		}
+		require.LessOrEqual(t, paymentRepo.maxSeen.Load(), int32(2))
+	})
+
+	t.Run("repeated batch does not refund twice", func(t *testing.T) {
+		// Arrange
+		paymentRepo := memory.NewMemoryRepository()
+		require.NoError(t, paymentRepo.Save(ctx, repository.Transaction{OrderID: "order-1", TransactionID: "tx_order-1", Amount: 10, CapturedAmount: 10, Status: repository.StatusCaptured}))
+		svc := NewRefundService(paymentRepo, memory.NewRefundRepository(paymentRepo), nil, nil, 10, 4, nil)
+		items := []RefundInput{{OrderID: "order-1"}, {OrderID: "order-1"}}
+
+		// Act
+		first, err := svc.RefundBatch(ctx, items)
+		require.NoError(t, err)
+		second, err := svc.RefundBatch(ctx, items)
+		require.NoError(t, err)
+
+		// Assert: в первом пакете ровно один возврат новый, во втором оба повторные
+		require.NoError(t, first[0].Err)
+		require.NoError(t, first[1].Err)
+		require.NotEqual(t, first[0].Replayed, first[1].Replayed)
+		require.True(t, second[0].Replayed)
+		require.True(t, second[1].Replayed)
+	})
+
+	t.Run("empty or oversized batch is rejected", func(t *testing.T) {
+		// Arrange
+		svc := NewRefundService(mocks.NewPaymentRepository(t), mocks.NewRefundRepository(t), nil, nil, 2, 2, nil)
+
+		// Act
+		_, emptyErr := svc.RefundBatch(ctx, nil)
+		_, largeErr := svc.RefundBatch(ctx, []RefundInput{{OrderID: "a"}, {OrderID: "b"}, {OrderID: "c"}})
+
+		// Assert
+		require.ErrorIs(t, emptyErr, ErrRefundBatchEmpty)
+		require.ErrorIs(t, largeErr, ErrRefundBatchTooLarge)
+	})
+
+	t.Run("canceled request fails items that did not start", func(t *testing.T) {
+		// Arrange
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		svc := NewRefundService(mocks.NewPaymentRepository(t), mocks.NewRefundRepository(t), nil, nil, 10, 1, nil)
+
+		// Act
+		results, err := svc.RefundBatch(canceledCtx, []RefundInput{{OrderID: "order-1"}, {OrderID: "order-2"}})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, r := range results {
+			require.ErrorIs(t, r.Err, context.Canceled)
+		}
+	})
+}
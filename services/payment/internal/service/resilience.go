@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+)
+
+// ErrCircuitOpen возвращается без вызова провайдера, пока его circuit breaker открыт
+// Оборачивается в provider.ErrUnavailable: для Pay это тот же отказ provider_error, что и недоступность провайдера
+var ErrCircuitOpen = errors.New("provider circuit breaker is open")
+
+// ProviderResiliencePolicy - повторы и circuit breaker вызовов провайдера
+// Нулевая политика - вызовы проходят к провайдеру как есть
+type ProviderResiliencePolicy struct {
+	MaxRetries      int           // повторы после provider.ErrUnavailable; 0 - без повторов
+	RetryBackoff    time.Duration // базовая пауза перед повтором, удваивается с каждой попыткой
+	RetryMaxBackoff time.Duration // верхняя граница паузы; 0 - без границы
+
+	BreakerFailureThreshold int           // столько неудач подряд открывают breaker; 0 - breaker выключен
+	BreakerOpenTimeout      time.Duration // сколько breaker открыт до пробного вызова
+}
+
+// BreakerState - состояние circuit breaker провайдера
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // вызовы проходят
+	BreakerHalfOpen                     // пропускается один пробный вызов
+	BreakerOpen                         // вызовы отклоняются до истечения BreakerOpenTimeout
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// ResilienceMetricsRecorder записывает метрики повторов и circuit breaker (опционально, может быть nil)
+type ResilienceMetricsRecorder interface {
+	// RecordProviderRetry записывает повтор операции провайдера (authorize, capture, refund)
+	RecordProviderRetry(operation string)
+	// RecordBreakerState записывает текущее состояние breaker'а; вызывается при каждой смене состояния
+	RecordBreakerState(state BreakerState)
+}
+
+// ProviderResilience оборачивает провайдера повторами с экспоненциальной паузой со случайным разбросом
+// и circuit breaker'ом: нестабильный шлюз не держит каждый платёж до таймаута Order Service.
+// Реализует provider.PaymentProvider
+//
+// Повторяется только provider.ErrUnavailable: все операции провайдера идемпотентны по своим ключам,
+// поэтому повтор не спишет и не вернёт деньги дважды. Отказ (*provider.DeclineError) - ответ провайдера,
+// он не повторяется и не открывает breaker. Пауза не выходит за дедлайн ctx: если следующая попытка
+// не успеет, возвращается последняя ошибка.
+type ProviderResilience struct {
+	next    provider.PaymentProvider
+	policy  ProviderResiliencePolicy
+	breaker *providerBreaker
+	metrics ResilienceMetricsRecorder
+	rand    func() float64 // [0, 1); подменяется в тестах
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewProviderResilience оборачивает провайдера next
+// metrics может быть nil (метрики не пишутся)
+func NewProviderResilience(next provider.PaymentProvider, policy ProviderResiliencePolicy, metrics ResilienceMetricsRecorder) *ProviderResilience {
+	return &ProviderResilience{
+		next:    next,
+		policy:  policy,
+		breaker: newProviderBreaker(policy.BreakerFailureThreshold, policy.BreakerOpenTimeout, time.Now),
+		metrics: metrics,
+		rand:    rand.Float64,
+		sleep:   sleepContext,
+	}
+}
+
+// Authorize вызывает Authorize провайдера с повторами
+func (p *ProviderResilience) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	var auth provider.Authorization
+	err := p.call(ctx, "authorize", func() (err error) {
+		auth, err = p.next.Authorize(ctx, req)
+		return err
+	})
+	return auth, err
+}
+
+// Capture вызывает Capture провайдера с повторами
+func (p *ProviderResilience) Capture(ctx context.Context, paymentID string, amount int64) error {
+	return p.call(ctx, "capture", func() error {
+		return p.next.Capture(ctx, paymentID, amount)
+	})
+}
+
+// Void вызывает Void провайдера с повторами
+func (p *ProviderResilience) Void(ctx context.Context, paymentID string) error {
+	return p.call(ctx, "void", func() error {
+		return p.next.Void(ctx, paymentID)
+	})
+}
+
+// Refund вызывает Refund провайдера с повторами
+func (p *ProviderResilience) Refund(ctx context.Context, req provider.RefundRequest) (provider.RefundResult, error) {
+	var result provider.RefundResult
+	err := p.call(ctx, "refund", func() (err error) {
+		result, err = p.next.Refund(ctx, req)
+		return err
+	})
+	return result, err
+}
+
+// call выполняет fn через breaker и повторяет её при provider.ErrUnavailable
+func (p *ProviderResilience) call(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		allowed, probe := p.breaker.allow()
+		if !allowed {
+			return fmt.Errorf("%w: %w", provider.ErrUnavailable, ErrCircuitOpen)
+		}
+		if probe {
+			p.breakerChanged(operation, BreakerOpen, BreakerHalfOpen, nil)
+		}
+
+		err := fn()
+		// Отмена запроса клиентом - не вина провайдера
+		failed := errors.Is(err, provider.ErrUnavailable) && ctx.Err() == nil
+		if from, to, changed := p.breaker.record(!failed); changed {
+			p.breakerChanged(operation, from, to, err)
+		}
+		if !failed || attempt >= p.policy.MaxRetries {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return err
+		}
+		log.Printf("Retrying provider call: operation=%s, attempt=%d, delay=%s, err=%v", operation, attempt+1, delay, err)
+		if p.metrics != nil {
+			p.metrics.RecordProviderRetry(operation)
+		}
+		if sleepErr := p.sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+func (p *ProviderResilience) breakerChanged(operation string, from, to BreakerState, err error) {
+	log.Printf("Provider circuit breaker state changed: operation=%s, from=%s, to=%s, err=%v", operation, from, to, err)
+	if p.metrics != nil {
+		p.metrics.RecordBreakerState(to)
+	}
+}
+
+// backoff возвращает паузу перед повтором attempt: RetryBackoff * 2^attempt, не больше RetryMaxBackoff,
+// со случайным разбросом в [половина, целая] - одновременно упавшие вызовы не повторяются одновременно
+func (p *ProviderResilience) backoff(attempt int) time.Duration {
+	delay := p.policy.RetryBackoff << min(attempt, 30)
+	if p.policy.RetryMaxBackoff > 0 && (delay > p.policy.RetryMaxBackoff || delay <= 0) {
+		delay = p.policy.RetryMaxBackoff
+	}
+	return delay/2 + time.Duration(p.rand()*float64(delay/2))
+}
+
+// sleepContext ждёт d или отмены ctx
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// providerBreaker открывается после failureThreshold неудач подряд; через openTimeout пропускает один
+// пробный вызов: успех закрывает breaker, неудача снова открывает. failureThreshold <= 0 - breaker выключен
+type providerBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // пробный вызов в half-open уже выполняется
+}
+
+func newProviderBreaker(failureThreshold int, openTimeout time.Duration, now func() time.Time) *providerBreaker {
+	return &providerBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              now,
+	}
+}
+
+// allow решает, можно ли выполнить вызов; probe - вызов пробный, breaker только что перешёл в half-open
+func (b *providerBreaker) allow() (allowed, probe bool) {
+	if b.failureThreshold <= 0 {
+		return true, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false, false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true, true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, false
+	default:
+		return true, false
+	}
+}
+
+// record учитывает результат вызова и возвращает смену состояния, если она произошла
+func (b *providerBreaker) record(success bool) (from, to BreakerState, changed bool) {
+	if b.failureThreshold <= 0 {
+		return BreakerClosed, BreakerClosed, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = BreakerClosed
+	} else {
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+		}
+	}
+	return from, b.state, from != b.state
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+)
+
+// flakyProvider - mock провайдер, Authorize которого возвращает errs по очереди, затем отвечает успехом
+type flakyProvider struct {
+	*mockprovider.Provider
+	errs  []error
+	calls int
+}
+
+func (p *flakyProvider) Authorize(ctx context.Context, req provider.AuthorizeRequest) (provider.Authorization, error) {
+	p.calls++
+	if p.calls <= len(p.errs) && p.errs[p.calls-1] != nil {
+		return provider.Authorization{}, p.errs[p.calls-1]
+	}
+	return p.Provider.Authorize(ctx, req)
+}
+
+type fakeResilienceMetrics struct {
+	retries []string
+	states  []BreakerState
+}
+
+func (m *fakeResilienceMetrics) RecordProviderRetry(operation string) {
+	m.retries = append(m.retries, operation)
+}
+
+func (m *fakeResilienceMetrics) RecordBreakerState(state BreakerState) {
+	m.states = append(m.states, state)
+}
+
+// newTestResilience создаёт ProviderResilience без пауз между повторами; паузы записываются в sleeps
+func newTestResilience(next provider.PaymentProvider, policy ProviderResiliencePolicy, metrics ResilienceMetricsRecorder, sleeps *[]time.Duration) *ProviderResilience {
+	p := NewProviderResilience(next, policy, metrics)
+	p.rand = func() float64 { return 1 }
+	p.sleep = func(_ context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return p
+}
+
+func TestProviderResilience(t *testing.T) {
+	ctx := context.Background()
+	authorize := provider.AuthorizeRequest{OrderID: "order-1", Amount: 100, Currency: "RUB", Method: "card"}
+
+	t.Run("unavailable provider is retried with exponential backoff", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable, provider.ErrUnavailable}}
+		metrics := &fakeResilienceMetrics{}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{MaxRetries: 2, RetryBackoff: 100 * time.Millisecond}, metrics, &sleeps)
+
+		auth, err := p.Authorize(ctx, authorize)
+
+		require.NoError(t, err)
+		require.Equal(t, "mock_pay_order-1", auth.PaymentID)
+		require.Equal(t, 3, gateway.calls)
+		require.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, sleeps)
+		require.Equal(t, []string{"authorize", "authorize"}, metrics.retries)
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable, provider.ErrUnavailable, provider.ErrUnavailable}}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{MaxRetries: 1, RetryBackoff: time.Millisecond}, nil, &sleeps)
+
+		_, err := p.Authorize(ctx, authorize)
+
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		require.Equal(t, 2, gateway.calls)
+	})
+
+	t.Run("decline is not retried", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{&provider.DeclineError{Code: provider.DeclineCard}}}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{MaxRetries: 2, RetryBackoff: time.Millisecond, BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Minute}, nil, &sleeps)
+
+		_, err := p.Authorize(ctx, authorize)
+		var declineErr *provider.DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, 1, gateway.calls)
+
+		// Отказ не открывает breaker
+		_, err = p.Authorize(ctx, authorize)
+		require.NoError(t, err)
+	})
+
+	t.Run("backoff is capped and jittered", func(t *testing.T) {
+		p := NewProviderResilience(mockprovider.New(), ProviderResiliencePolicy{RetryBackoff: 100 * time.Millisecond, RetryMaxBackoff: 300 * time.Millisecond}, nil)
+		p.rand = func() float64 { return 0 }
+
+		require.Equal(t, 50*time.Millisecond, p.backoff(0))
+		require.Equal(t, 150*time.Millisecond, p.backoff(2))
+		require.Equal(t, 150*time.Millisecond, p.backoff(40))
+	})
+
+	t.Run("retry that would miss the deadline is skipped", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable}}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{MaxRetries: 2, RetryBackoff: time.Second}, nil, &sleeps)
+		deadlineCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		_, err := p.Authorize(deadlineCtx, authorize)
+
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		require.Equal(t, 1, gateway.calls)
+		require.Empty(t, sleeps)
+	})
+
+	t.Run("breaker opens, rejects calls and closes after successful probe", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable, provider.ErrUnavailable}}
+		metrics := &fakeResilienceMetrics{}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{BreakerFailureThreshold: 2, BreakerOpenTimeout: time.Minute}, metrics, &sleeps)
+		now := time.Now()
+		p.breaker.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			_, err := p.Authorize(ctx, authorize)
+			require.ErrorIs(t, err, provider.ErrUnavailable)
+		}
+		_, err := p.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		require.Equal(t, 2, gateway.calls)
+
+		now = now.Add(time.Minute)
+		_, err = p.Authorize(ctx, authorize)
+		require.NoError(t, err)
+		require.Equal(t, 3, gateway.calls)
+		require.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, metrics.states)
+	})
+
+	t.Run("failed probe reopens breaker", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable, provider.ErrUnavailable}}
+		metrics := &fakeResilienceMetrics{}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Minute}, metrics, &sleeps)
+		now := time.Now()
+		p.breaker.now = func() time.Time { return now }
+
+		_, err := p.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		now = now.Add(time.Minute)
+		_, err = p.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, provider.ErrUnavailable)
+		require.False(t, errors.Is(err, ErrCircuitOpen))
+
+		_, err = p.Authorize(ctx, authorize)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen}, metrics.states)
+	})
+
+	t.Run("open breaker declines payment with provider_error", func(t *testing.T) {
+		gateway := &flakyProvider{Provider: mockprovider.New(), errs: []error{provider.ErrUnavailable}}
+		var sleeps []time.Duration
+		p := newTestResilience(gateway, ProviderResiliencePolicy{BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Minute}, nil, &sleeps)
+		service := NewPaymentService(memory.NewMemoryRepository(), 100000, p, nil, nil, nil, nil)
+		_, _ = p.Authorize(ctx, authorize)
+
+		_, err := service.Pay(ctx, PaymentInput{OrderID: "order-2", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card"})
+
+		var declineErr *DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineProviderError, declineErr.Reason)
+		require.Equal(t, 1, gateway.calls)
+	})
+}
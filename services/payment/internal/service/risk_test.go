@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/risk"
+)
+
+// stubRiskChecker возвращает decisions по очереди, последнее - на все следующие вызовы
+type stubRiskChecker struct {
+	decisions []risk.Decision
+	err       error
+	calls     int
+}
+
+func (c *stubRiskChecker) Check(_ context.Context, _ risk.CheckRequest) (risk.Decision, error) {
+	if c.err != nil {
+		return risk.Decision{}, c.err
+	}
+	decision := c.decisions[min(c.calls, len(c.decisions)-1)]
+	c.calls++
+	return decision, nil
+}
+
+func TestPaymentService_RiskCheck(t *testing.T) {
+	ctx := context.Background()
+	input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card"}
+
+	t.Run("velocity decline is saved as declined_risk and replayed", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		checker := risk.NewThresholdChecker(paymentRepo, risk.Thresholds{VelocityWindow: time.Hour, MaxPayments: 1})
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), checker, nil, nil, nil)
+
+		_, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+		second := input
+		second.OrderID = "order-2"
+		_, err = service.Pay(ctx, second)
+
+		var declineErr *DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineRiskDeclined, declineErr.Reason)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-2")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusDeclinedRisk, tx.Status)
+		require.Equal(t, risk.RuleVelocityCount, tx.RiskFlag)
+		require.Empty(t, tx.ProviderPaymentID)
+
+		_, err = service.Pay(ctx, second)
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineRiskDeclined, declineErr.Reason)
+
+		events := paymentRepo.OutboxEvents()
+		require.Len(t, events, 2)
+		require.Equal(t, EventTypePaymentFailed, events[1].EventType)
+		require.Equal(t, "order-2", events[1].AggregateID)
+	})
+
+	t.Run("flagged payment is charged with risk flag", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		checker := risk.NewThresholdChecker(paymentRepo, risk.Thresholds{ReviewAmount: 100})
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), checker, nil, nil, nil)
+
+		tx, err := service.Pay(ctx, input)
+
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+		require.Equal(t, risk.RuleAmountReview, tx.RiskFlag)
+	})
+
+	t.Run("new idempotency key after risk decline is checked again", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		checker := &stubRiskChecker{decisions: []risk.Decision{
+			{Action: risk.ActionDecline, Rule: risk.RuleVelocityAmount, Message: "too much"},
+			risk.Allow,
+		}}
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), checker, nil, nil, nil)
+		first := input
+		first.IdempotencyKey = "key-1"
+		_, err := service.Pay(ctx, first)
+		require.Error(t, err)
+
+		retry := input
+		retry.IdempotencyKey = "key-2"
+		tx, err := service.Pay(ctx, retry)
+
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+		require.Empty(t, tx.RiskFlag)
+		require.Equal(t, 2, checker.calls)
+	})
+
+	t.Run("checker error does not save transaction", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), &stubRiskChecker{err: errors.New("db down")}, nil, nil, nil)
+
+		_, err := service.Pay(ctx, input)
+
+		require.Error(t, err)
+		var declineErr *DeclineError
+		require.False(t, errors.As(err, &declineErr))
+		_, err = paymentRepo.GetByOrderID(ctx, "order-1")
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
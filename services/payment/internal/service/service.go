@@ -2,76 +2,608 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
 	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/risk"
+)
+
+// DefaultCurrency используется, если клиент не передал валюту платежа
+const DefaultCurrency = "RUB"
+
+// DeclineReason - причина отказа в оплате
+type DeclineReason string
+
+const (
+	// DeclineInsufficientFunds - недостаточно средств
+	DeclineInsufficientFunds DeclineReason = "insufficient_funds"
+	// DeclineLimitExceeded - сумма превышает лимит одного платежа
+	DeclineLimitExceeded DeclineReason = "limit_exceeded"
+	// DeclineRiskDeclined - платёж отклонён антифрод-проверкой (сервиса - StatusDeclinedRisk, провайдера - StatusFailed)
+	DeclineRiskDeclined DeclineReason = "risk_declined"
+	// DeclineProviderError - платёжный провайдер недоступен, платёж можно повторить позже
+	DeclineProviderError DeclineReason = "provider_error"
+	// DeclineCardDeclined - банк отклонил карту по другой причине
+	DeclineCardDeclined DeclineReason = "card_declined"
+)
+
+// providerDeclineReasons сопоставляет отказы провайдера с причинами отказа в оплате
+var providerDeclineReasons = map[provider.DeclineCode]DeclineReason{
+	provider.DeclineInsufficientFunds: DeclineInsufficientFunds,
+	provider.DeclineRisk:              DeclineRiskDeclined,
+	provider.DeclineCard:              DeclineCardDeclined,
+}
+
+// DeclineError возвращается ProcessPayment, если в оплате отказано
+// Это не техническая ошибка: клиент получает причину отказа и может предпринять действие
+type DeclineError struct {
+	Reason  DeclineReason
+	Message string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("payment declined (%s): %s", e.Reason, e.Message)
+}
+
+// ErrOrderAlreadyPaid возвращается Pay, если запрос с новым ключом идемпотентности пришёл по заказу,
+// у которого уже есть неотклонённая транзакция (списанная, авторизованная, отменённая или ожидающая)
+var ErrOrderAlreadyPaid = errors.New("order already has a payment with another idempotency key")
+
+// Ошибки суммы списания ConfirmPayment
+var (
+	// ErrInvalidCaptureAmount - отрицательная сумма списания
+	ErrInvalidCaptureAmount = errors.New("capture amount must not be negative")
+	// ErrCaptureAmountExceeded - сумма списания больше авторизованной
+	ErrCaptureAmountExceeded = errors.New("capture amount exceeds authorized amount")
 )
 
 // PaymentService содержит бизнес-логику работы с платежами
 // Использует только простые типы Go, не зависит от protobuf
 // Зависит от интерфейса PaymentRepository, а не от конкретной реализации
 type PaymentService struct {
-	repo repository.PaymentRepository
+	repo        repository.PaymentRepository
+	maxAmount   int64
+	provider    provider.PaymentProvider
+	riskChecker risk.RiskChecker
+	wallets     repository.WalletRepository
+	methods     repository.PaymentMethodRepository
+	metrics     PaymentMetricsRecorder
 }
 
 // NewPaymentService создаёт новый экземпляр PaymentService
 // Принимает repository как зависимость - это позволяет легко подменять его в тестах
-func NewPaymentService(repo repository.PaymentRepository) *PaymentService {
+// maxAmount - лимит суммы одного платежа в минимальных единицах валюты платежа, платежи больше лимита
+// отклоняются с DeclineLimitExceeded
+// paymentProvider может быть nil (провайдер не вызывается, оплата проходит сразу)
+// riskChecker может быть nil (антифрод-проверка не выполняется)
+// wallets может быть nil (оплата с кошелька, MethodWallet, недоступна)
+// methods может быть nil (оплата сохранённым способом, PaymentMethodID, недоступна)
+// metrics может быть nil (метрики не пишутся)
+func NewPaymentService(repo repository.PaymentRepository, maxAmount int64, paymentProvider provider.PaymentProvider, riskChecker risk.RiskChecker, wallets repository.WalletRepository, methods repository.PaymentMethodRepository, metrics PaymentMetricsRecorder) *PaymentService {
 	return &PaymentService{
-		repo: repo,
+		repo:        repo,
+		maxAmount:   maxAmount,
+		provider:    paymentProvider,
+		riskChecker: riskChecker,
+		wallets:     wallets,
+		methods:     methods,
+		metrics:     metrics,
 	}
 }
 
-// ProcessPayment обрабатывает платеж
-// Реализует идемпотентность: повторный вызов для того же orderID возвращает тот же transactionID
+// PaymentInput - запрос на оплату заказа
+type PaymentInput struct {
+	OrderID  string
+	UserID   string
+	Amount   int64  // в минимальных единицах валюты (копейки, центы)
+	Currency string // код валюты ISO 4217 из поддерживаемых (NormalizeCurrency); пусто - DefaultCurrency
+	Method   string // MethodWallet - оплата с кошелька пользователя, остальные способы - через провайдера
+	// PaymentMethodID - сохранённый способ оплаты пользователя вместо Method: провайдеру уходит его токен
+	PaymentMethodID string
+	// ManualCapture - двухшаговая оплата: сумма только блокируется (StatusAuthorized), списывает её ConfirmPayment
+	ManualCapture bool
+	// IdempotencyKey - ключ идемпотентности клиента: повтор с тем же ключом возвращает исходную транзакцию,
+	// новый ключ начинает новую попытку, если прежняя отклонена. Пусто - повтор последней попытки заказа
+	IdempotencyKey string
+}
+
+// ProcessPayment обрабатывает платеж с немедленным списанием (см. Pay)
+// success = true, если деньги списаны; для отложенного платежа - transaction ID и success = false без ошибки
 // Возвращает transaction ID, success и ошибку
-func (s *PaymentService) ProcessPayment(ctx context.Context, orderID, userID string, amount float64, method string) (transactionID string, success bool, err error) {
-	log.Printf("ProcessPayment called: order=%s, user=%s, amount=%f, method=%s",
-		orderID, userID, amount, method)
+func (s *PaymentService) ProcessPayment(ctx context.Context, orderID, userID string, amount int64, currency, method string) (transactionID string, success bool, err error) {
+	tx, err := s.Pay(ctx, PaymentInput{
+		OrderID:  orderID,
+		UserID:   userID,
+		Amount:   amount,
+		Currency: currency,
+		Method:   method,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return tx.TransactionID, isCharged(tx.Status), nil
+}
 
-	// a) Валидация: сумма должна быть положительной
-	if amount <= 0 {
-		return "", false, fmt.Errorf("invalid amount: must be greater than 0")
+// Pay обрабатывает платеж и возвращает сохранённую транзакцию
+// Реализует идемпотентность: повторный вызов для того же orderID (и IdempotencyKey, если задан) возвращает
+// ту же транзакцию в её текущем статусе; ErrOrderAlreadyPaid - новый ключ по уже оплаченному заказу,
+// ErrCurrencyMismatch - повтор в другой валюте
+// При отказе возвращает *DeclineError; отказ тоже сохраняется (StatusFailed, для антифрод-проверки - StatusDeclinedRisk),
+// повторный вызов возвращает ту же причину
+// Начальный статус: StatusCaptured; StatusAuthorized при ManualCapture; StatusPending, если провайдер ждёт
+// подтверждения покупателя - результат придёт через HandlePaymentEvent
+// Каждый вызов, в том числе повтор, записывается в метрики с результатом и длительностью обработки
+func (s *PaymentService) Pay(ctx context.Context, input PaymentInput) (repository.Transaction, error) {
+	start := time.Now()
+	tx, err := s.pay(ctx, input)
+	if s.metrics != nil {
+		s.metrics.RecordPaymentAttempt(paymentResult(tx, err), paymentMethodLabel(input), time.Since(start))
 	}
+	return tx, err
+}
 
-	// b) Проверяем, существует ли уже транзакция для этого orderID (идемпотентность)
-	existingTx, err := s.repo.GetByOrderID(ctx, orderID)
+// pay выполняет оплату по шагам, см. Pay
+func (s *PaymentService) pay(ctx context.Context, input PaymentInput) (repository.Transaction, error) {
+	log.Printf("Pay called: order=%s, user=%s, amount=%d, currency=%s, method=%s, payment_method_id=%s, manual_capture=%v, idempotency_key=%s",
+		input.OrderID, input.UserID, input.Amount, input.Currency, input.Method, input.PaymentMethodID, input.ManualCapture, input.IdempotencyKey)
+
+	// a) Валидация: сумма должна быть положительной, валюта - поддерживаемой
+	if input.Amount <= 0 {
+		return repository.Transaction{}, fmt.Errorf("invalid amount: %w", ErrInvalidAmount)
+	}
+	currency, err := NormalizeCurrency(input.Currency)
+	if err != nil {
+		return repository.Transaction{}, err
+	}
+	input.Currency = currency
+	if input.PaymentMethodID != "" && input.Method != "" {
+		return repository.Transaction{}, ErrPaymentMethodAmbiguous
+	}
+	if input.Method == MethodWallet {
+		if s.wallets == nil {
+			return repository.Transaction{}, ErrWalletDisabled
+		}
+		if input.ManualCapture {
+			return repository.Transaction{}, ErrWalletManualCapture
+		}
+	}
+
+	// b) Проверяем, существует ли уже транзакция для этого запроса (идемпотентность)
+	existingTx, err := s.existingPayment(ctx, input.OrderID, input.IdempotencyKey)
 	if err == nil {
-		// Транзакция найдена - возвращаем существующий transactionID (идемпотентность)
-		log.Printf("Payment already processed for order=%s, returning existing transactionID=%s",
-			orderID, existingTx.TransactionID)
-		return existingTx.TransactionID, true, nil
+		return existingResult(existingTx, input.Currency)
+	}
+	if errors.Is(err, ErrOrderAlreadyPaid) {
+		return repository.Transaction{}, err
 	}
 
 	// Если ошибка не ErrNotFound, возвращаем её
 	if err != repository.ErrNotFound {
 		log.Printf("Error getting transaction: %v", err)
-		return "", false, fmt.Errorf("failed to check existing transaction: %w", err)
+		return repository.Transaction{}, fmt.Errorf("failed to check existing transaction: %w", err)
 	}
 
-	// c) Транзакция не найдена - создаём новую
-	// Генерируем transaction ID: tx_{orderID}_{timestamp}
-	transactionID = fmt.Sprintf("tx_%s_%d", orderID, time.Now().Unix())
+	// c) Сохранённый способ оплаты: провайдеру уходит его токен; проверяется только для новой транзакции,
+	// поэтому повтор оплаты после удаления способа возвращает сохранённый результат
+	if input.PaymentMethodID != "" {
+		method, err := s.storedMethod(ctx, input.UserID, input.PaymentMethodID)
+		if err != nil {
+			return repository.Transaction{}, err
+		}
+		input.Method = method.Token
+	}
 
-	// Создаём доменную модель транзакции
+	// d) Создаём новую транзакцию
+	// Генерируем transaction ID: tx_{orderID}_{timestamp}; наносекунды различают попытки заказа в одну секунду
+	now := time.Now()
 	tx := repository.Transaction{
-		OrderID:       orderID,
-		UserID:        userID,
-		Amount:        amount,
-		Method:        method,
-		TransactionID: transactionID,
-		Status:        "success",
-		CreatedAt:     time.Now().Unix(),
+		OrderID:         input.OrderID,
+		UserID:          input.UserID,
+		Amount:          input.Amount,
+		Currency:        input.Currency,
+		Method:          input.Method,
+		TransactionID:   fmt.Sprintf("tx_%s_%d", input.OrderID, now.UnixNano()),
+		Status:          repository.StatusCaptured,
+		IdempotencyKey:  input.IdempotencyKey,
+		PaymentMethodID: input.PaymentMethodID,
+		CreatedAt:       now.Unix(),
+	}
+	if input.ManualCapture {
+		tx.Status = repository.StatusAuthorized
+	} else {
+		// Без ManualCapture списывается вся сумма; отказ и отложенный платёж её обнуляют
+		tx.CapturedAmount = tx.Amount
+	}
+
+	// e) Проверяем лимит суммы платежа; отказ сохраняем, чтобы повторный вызов вернул ту же причину
+	if s.maxAmount > 0 && input.Amount > s.maxAmount {
+		return s.decline(ctx, tx, repository.StatusFailed, &DeclineError{
+			Reason:  DeclineLimitExceeded,
+			Message: fmt.Sprintf("amount %s exceeds limit %s", formatAmount(input.Amount, input.Currency), formatAmount(s.maxAmount, input.Currency)),
+		})
+	}
+
+	// f) Антифрод-проверка до обращения к провайдеру: отказ сохраняется со статусом StatusDeclinedRisk,
+	// пометка - в RiskFlag, и платёж проводится дальше
+	if s.riskChecker != nil {
+		decision, err := s.riskChecker.Check(ctx, risk.CheckRequest{
+			OrderID:  tx.OrderID,
+			UserID:   tx.UserID,
+			Amount:   tx.Amount,
+			Currency: tx.Currency,
+			Method:   tx.Method,
+		})
+		if err != nil {
+			log.Printf("Risk check failed: order=%s, err=%v", input.OrderID, err)
+			return repository.Transaction{}, fmt.Errorf("risk check failed: %w", err)
+		}
+		tx.RiskFlag = decision.Rule
+		switch decision.Action {
+		case risk.ActionDecline:
+			return s.decline(ctx, tx, repository.StatusDeclinedRisk, &DeclineError{Reason: DeclineRiskDeclined, Message: decision.Message})
+		case risk.ActionFlag:
+			log.Printf("Payment flagged by risk check: order=%s, rule=%s, message=%s", input.OrderID, decision.Rule, decision.Message)
+		}
+	}
+
+	// g) Оплата с кошелька: списание и транзакция сохраняются вместе, провайдер не вызывается
+	if input.Method == MethodWallet {
+		return s.payFromWallet(ctx, tx)
+	}
+
+	// h) Авторизация и (без ManualCapture) списание у провайдера
+	// Отказ провайдера сохраняется, как и отказ по лимиту; недоступность провайдера - нет:
+	// provider_error временный, и повтор может пройти
+	if s.provider != nil {
+		auth, err := s.charge(ctx, tx, !input.ManualCapture)
+		if err != nil {
+			var providerDecline *provider.DeclineError
+			if errors.As(err, &providerDecline) {
+				return s.decline(ctx, tx, repository.StatusFailed, &DeclineError{Reason: providerDeclineReasons[providerDecline.Code], Message: providerDecline.Message})
+			}
+			if errors.Is(err, provider.ErrUnavailable) {
+				log.Printf("Payment provider unavailable: order=%s", input.OrderID)
+				return repository.Transaction{}, &DeclineError{Reason: DeclineProviderError, Message: err.Error()}
+			}
+			return repository.Transaction{}, fmt.Errorf("payment provider call failed: %w", err)
+		}
+		tx.ProviderPaymentID = auth.PaymentID
+		if auth.Pending {
+			// Покупатель ещё подтверждает платёж: сохраняем ожидание, результат придёт webhook'ом (HandlePaymentEvent)
+			tx.Status, tx.CapturedAmount = repository.StatusPending, 0
+		}
 	}
 
 	// Сохраняем транзакцию в repository
-	if err := s.repo.Save(ctx, tx); err != nil {
+	if err := s.save(ctx, tx); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return s.concurrentResult(ctx, tx)
+		}
 		log.Printf("Failed to save transaction: %v", err)
-		return "", false, fmt.Errorf("failed to save transaction: %w", err)
+		return repository.Transaction{}, fmt.Errorf("failed to save transaction: %w", err)
+	}
+
+	log.Printf("Payment processed: transactionID=%s, status=%s", tx.TransactionID, tx.Status)
+	return tx, nil
+}
+
+// charge авторизует сумму у провайдера и, если capture, сразу её списывает
+// Авторизация идемпотентна по order_id и ключу идемпотентности: повтор после сбоя между шагами списывает
+// тот же платёж, а новая попытка после отказа доходит до провайдера, а не получает сохранённый им отказ.
+// Отложенный платёж (Authorization.Pending) не списывается: провайдер спишет его сам после подтверждения
+func (s *PaymentService) charge(ctx context.Context, tx repository.Transaction, capture bool) (provider.Authorization, error) {
+	auth, err := s.provider.Authorize(ctx, provider.AuthorizeRequest{
+		OrderID:        tx.OrderID,
+		UserID:         tx.UserID,
+		Amount:         tx.Amount,
+		Currency:       tx.Currency,
+		Method:         tx.Method,
+		IdempotencyKey: tx.IdempotencyKey,
+	})
+	if err != nil {
+		return provider.Authorization{}, err
+	}
+	if auth.Pending || !capture {
+		return auth, nil
+	}
+	if err := s.provider.Capture(ctx, auth.PaymentID, 0); err != nil {
+		return provider.Authorization{}, err
+	}
+	return auth, nil
+}
+
+// decline сохраняет отказ в статусе status (StatusFailed или StatusDeclinedRisk), чтобы повторный вызов
+// вернул ту же причину, и возвращает его
+func (s *PaymentService) decline(ctx context.Context, tx repository.Transaction, status string, declineErr *DeclineError) (repository.Transaction, error) {
+	tx.Status, tx.CapturedAmount = status, 0
+	tx.DeclineReason = string(declineErr.Reason)
+	if err := s.save(ctx, tx); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return s.concurrentResult(ctx, tx)
+		}
+		log.Printf("Failed to save declined transaction: %v", err)
+		return repository.Transaction{}, fmt.Errorf("failed to save transaction: %w", err)
+	}
+	log.Printf("Payment declined: order=%s, reason=%s", tx.OrderID, declineErr.Reason)
+	return repository.Transaction{}, declineErr
+}
+
+// existingResult возвращает результат уже сохранённой транзакции заказа (идемпотентность)
+// Повтор в валюте currency, отличной от валюты транзакции, - ErrCurrencyMismatch: сумма означала бы другие деньги
+func existingResult(tx repository.Transaction, currency string) (repository.Transaction, error) {
+	if tx.Currency != currency {
+		log.Printf("Payment retried in another currency: order=%s, existing=%s, requested=%s", tx.OrderID, tx.Currency, currency)
+		return repository.Transaction{}, fmt.Errorf("%w: order %s is paid in %s, got %s", ErrCurrencyMismatch, tx.OrderID, tx.Currency, currency)
+	}
+	if repository.IsDeclined(tx.Status) {
+		log.Printf("Payment already declined for order=%s, reason=%s", tx.OrderID, tx.DeclineReason)
+		return repository.Transaction{}, &DeclineError{Reason: DeclineReason(tx.DeclineReason), Message: "payment was declined earlier"}
+	}
+	// Транзакция найдена - возвращаем её в текущем статусе
+	log.Printf("Payment already processed for order=%s, returning existing transactionID=%s, status=%s",
+		tx.OrderID, tx.TransactionID, tx.Status)
+	return tx, nil
+}
+
+// existingPayment ищет сохранённую транзакцию запроса
+// Без ключа идемпотентности запрос - повтор последней попытки оплаты заказа. С ключом - повтор попытки
+// с тем же ключом; если её нет, новая попытка допустима, только когда прежние отклонены, иначе ErrOrderAlreadyPaid.
+// Возвращает repository.ErrNotFound, если запрос нужно выполнить как новый
+func (s *PaymentService) existingPayment(ctx context.Context, orderID, idempotencyKey string) (repository.Transaction, error) {
+	if idempotencyKey == "" {
+		return s.repo.GetByOrderID(ctx, orderID)
+	}
+	tx, err := s.repo.GetByIdempotencyKey(ctx, orderID, idempotencyKey)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return tx, err
+	}
+	latest, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return repository.Transaction{}, err
+	}
+	if !repository.IsDeclined(latest.Status) {
+		log.Printf("Order already paid with another idempotency key: order=%s, transactionID=%s", orderID, latest.TransactionID)
+		return repository.Transaction{}, ErrOrderAlreadyPaid
+	}
+	return repository.Transaction{}, repository.ErrNotFound
+}
+
+// concurrentResult вызывается, когда конкурентный запрос сохранил транзакцию заказа раньше нас:
+// возвращаем его результат, а не ошибку, как при обычном повторе
+// attempt - наша несохранённая попытка. Если конкурентный запрос был с другим ключом идемпотентности,
+// возвращается ErrOrderAlreadyPaid, если в другой валюте - ErrCurrencyMismatch
+func (s *PaymentService) concurrentResult(ctx context.Context, attempt repository.Transaction) (repository.Transaction, error) {
+	existingTx, err := s.existingPayment(ctx, attempt.OrderID, attempt.IdempotencyKey)
+	if errors.Is(err, ErrOrderAlreadyPaid) {
+		return repository.Transaction{}, err
+	}
+	if err != nil {
+		return repository.Transaction{}, fmt.Errorf("failed to read concurrently saved transaction: %w", err)
+	}
+	return existingResult(existingTx, attempt.Currency)
+}
+
+// ConfirmPayment списывает авторизованную сумму двухшаговой оплаты: authorized -> captured
+// amount - сумма списания в минимальных единицах валюты, 0 - вся авторизованная сумма. Меньшая сумма
+// (заказ собран не полностью) списывается один раз, остаток авторизации освобождается; возвращать
+// после этого можно только списанное (Transaction.CapturedAmount).
+// Повтор по уже списанной транзакции возвращает её без нового списания. Отказ провайдера в списании
+// (например, истёк срок авторизации) переводит транзакцию в StatusFailed и возвращается *DeclineError;
+// недоступность провайдера - *DeclineError с DeclineProviderError, статус не меняется.
+// Возвращает ErrOrderIDRequired, ErrInvalidCaptureAmount, ErrCaptureAmountExceeded, ErrPaymentNotFound
+// и ErrInvalidTransition (отложенный, отклонённый платёж)
+func (s *PaymentService) ConfirmPayment(ctx context.Context, orderID string, amount int64) (repository.Transaction, error) {
+	if orderID == "" {
+		return repository.Transaction{}, ErrOrderIDRequired
+	}
+	if amount < 0 {
+		return repository.Transaction{}, ErrInvalidCaptureAmount
+	}
+	tx, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.Transaction{}, ErrPaymentNotFound
+		}
+		return repository.Transaction{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if isCharged(tx.Status) {
+		log.Printf("Payment already captured: order=%s, transactionID=%s", orderID, tx.TransactionID)
+		return tx, nil
+	}
+	// pending -> captured тоже допустимый переход, но его выполняет только webhook провайдера:
+	// покупатель ещё не подтвердил отложенный платёж, списывать по нему нечего
+	if tx.Status != repository.StatusAuthorized {
+		return repository.Transaction{}, fmt.Errorf("%w: confirm %s payment", ErrInvalidTransition, tx.Status)
+	}
+	if amount > tx.Amount {
+		return repository.Transaction{}, fmt.Errorf("%w: %s > %s", ErrCaptureAmountExceeded, formatAmount(amount, tx.Currency), formatAmount(tx.Amount, tx.Currency))
+	}
+	if amount == 0 {
+		amount = tx.Amount
+	}
+
+	if s.provider != nil && tx.ProviderPaymentID != "" {
+		if err := s.provider.Capture(ctx, tx.ProviderPaymentID, amount); err != nil {
+			var providerDecline *provider.DeclineError
+			if errors.As(err, &providerDecline) {
+				declineErr := &DeclineError{Reason: providerDeclineReasons[providerDecline.Code], Message: providerDecline.Message}
+				if err := s.transition(ctx, tx, repository.StatusFailed, string(declineErr.Reason)); err != nil {
+					return repository.Transaction{}, err
+				}
+				return repository.Transaction{}, declineErr
+			}
+			if errors.Is(err, provider.ErrUnavailable) {
+				return repository.Transaction{}, &DeclineError{Reason: DeclineProviderError, Message: err.Error()}
+			}
+			return repository.Transaction{}, fmt.Errorf("payment provider call failed: %w", err)
+		}
+	}
+
+	tx.CapturedAmount = amount
+	if err := s.transition(ctx, tx, repository.StatusCaptured, ""); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			// Конкурентный ConfirmPayment списал платёж раньше: отдаём его результат
+			return s.concurrentResult(ctx, tx)
+		}
+		return repository.Transaction{}, err
+	}
+	tx.Status = repository.StatusCaptured
+	log.Printf("Payment captured: order=%s, transactionID=%s, amount=%s", orderID, tx.TransactionID, formatAmount(amount, tx.Currency))
+	return tx, nil
+}
+
+// VoidAuthorization отменяет авторизацию двухшаговой оплаты до списания: authorized -> voided
+// Заблокированная у провайдера сумма освобождается, списанной и возвращаемой суммы у транзакции нет
+// (CapturedAmount остаётся 0); вместе со статусом в outbox пишется payment.voided. Заказ после отмены
+// оплатить заново нельзя. Повтор по уже отменённой транзакции возвращает её без нового вызова провайдера.
+// Отказ провайдера в отмене - *DeclineError, статус не меняется; недоступность провайдера -
+// *DeclineError с DeclineProviderError, вызов можно повторить.
+// Возвращает ErrOrderIDRequired, ErrPaymentNotFound и ErrInvalidTransition (платёж не в статусе authorized)
+func (s *PaymentService) VoidAuthorization(ctx context.Context, orderID string) (repository.Transaction, error) {
+	if orderID == "" {
+		return repository.Transaction{}, ErrOrderIDRequired
+	}
+	tx, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.Transaction{}, ErrPaymentNotFound
+		}
+		return repository.Transaction{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if tx.Status == repository.StatusVoided {
+		log.Printf("Authorization already voided: order=%s, transactionID=%s", orderID, tx.TransactionID)
+		return tx, nil
+	}
+	if tx.Status != repository.StatusAuthorized {
+		return repository.Transaction{}, fmt.Errorf("%w: void %s payment", ErrInvalidTransition, tx.Status)
+	}
+
+	if s.provider != nil && tx.ProviderPaymentID != "" {
+		if err := s.provider.Void(ctx, tx.ProviderPaymentID); err != nil {
+			var providerDecline *provider.DeclineError
+			if errors.As(err, &providerDecline) {
+				return repository.Transaction{}, &DeclineError{Reason: providerDeclineReasons[providerDecline.Code], Message: providerDecline.Message}
+			}
+			if errors.Is(err, provider.ErrUnavailable) {
+				return repository.Transaction{}, &DeclineError{Reason: DeclineProviderError, Message: err.Error()}
+			}
+			return repository.Transaction{}, fmt.Errorf("payment provider call failed: %w", err)
+		}
+	}
+
+	if err := s.transition(ctx, tx, repository.StatusVoided, ""); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			// Статус успели изменить: конкурентный VoidAuthorization (вернём его результат) или ConfirmPayment
+			return s.voidResult(ctx, tx.TransactionID)
+		}
+		return repository.Transaction{}, err
+	}
+	tx.Status = repository.StatusVoided
+	log.Printf("Authorization voided: order=%s, transactionID=%s, amount=%s", orderID, tx.TransactionID, formatAmount(tx.Amount, tx.Currency))
+	return tx, nil
+}
+
+// voidResult перечитывает транзакцию после конфликта статуса в VoidAuthorization:
+// отменённая возвращается, в любом другом статусе отменять уже нечего - ErrInvalidTransition
+func (s *PaymentService) voidResult(ctx context.Context, transactionID string) (repository.Transaction, error) {
+	tx, err := s.repo.GetByTransactionID(ctx, transactionID)
+	if err != nil {
+		return repository.Transaction{}, fmt.Errorf("failed to read concurrently updated transaction: %w", err)
+	}
+	if tx.Status != repository.StatusVoided {
+		return repository.Transaction{}, fmt.Errorf("%w: void %s payment", ErrInvalidTransition, tx.Status)
+	}
+	return tx, nil
+}
+
+// save сохраняет новую транзакцию; завершённую оплату (captured, failed) - вместе с событием outbox
+func (s *PaymentService) save(ctx context.Context, tx repository.Transaction) error {
+	event, ok, err := paymentEvent(tx, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to build payment event: %w", err)
+	}
+	if !ok {
+		return s.repo.Save(ctx, tx)
+	}
+	return s.repo.SaveWithOutbox(ctx, tx, event)
+}
+
+// transition проверяет переход по transitions и сохраняет его compare-and-set'ом от текущего статуса tx
+// Переход в captured, failed или voided сохраняется вместе с событием outbox, в captured - ещё и со списанной суммой tx.CapturedAmount.
+// Возвращает repository.ErrStatusConflict, если статус успели изменить
+func (s *PaymentService) transition(ctx context.Context, tx repository.Transaction, to, declineReason string) error {
+	if err := validateTransition(tx.Status, to); err != nil {
+		return err
+	}
+
+	from := tx.Status
+	tx.Status, tx.DeclineReason = to, declineReason
+	event, ok, err := paymentEvent(tx, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to build payment event: %w", err)
+	}
+	switch {
+	case to == repository.StatusCaptured:
+		err = s.repo.CaptureWithOutbox(ctx, tx.TransactionID, from, tx.CapturedAmount, event)
+	case ok:
+		err = s.repo.UpdateStatusWithOutbox(ctx, tx.TransactionID, from, to, declineReason, event)
+	default:
+		err = s.repo.UpdateStatus(ctx, tx.TransactionID, from, to, declineReason)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return err
+		}
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	return nil
+}
+
+// HandlePaymentEvent применяет событие провайдера к отложенной транзакции: pending -> captured или failed
+// Провайдер доставляет событие at-least-once: повтор и событие по уже завершённой транзакции ничего не меняют.
+// Возвращает repository.ErrNotFound, если транзакции с таким платежом нет - например, событие пришло раньше,
+// чем ProcessPayment сохранил транзакцию; провайдер повторит доставку
+func (s *PaymentService) HandlePaymentEvent(ctx context.Context, event provider.PaymentEvent) error {
+	if event.Type != provider.PaymentEventSucceeded && event.Type != provider.PaymentEventFailed {
+		log.Printf("Provider event ignored: id=%s", event.ID)
+		return nil
+	}
+
+	tx, err := s.repo.GetByProviderPaymentID(ctx, event.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to find transaction for payment %s: %w", event.PaymentID, err)
+	}
+	if tx.Status != repository.StatusPending {
+		log.Printf("Provider event for completed transaction ignored: id=%s, transactionID=%s, status=%s",
+			event.ID, tx.TransactionID, tx.Status)
+		return nil
+	}
+
+	// Отложенный платёж провайдер списывает целиком
+	status, declineReason := repository.StatusCaptured, ""
+	tx.CapturedAmount = tx.Amount
+	if event.Type == provider.PaymentEventFailed {
+		status = repository.StatusFailed
+		reason, ok := providerDeclineReasons[event.DeclineCode]
+		if !ok {
+			reason = DeclineCardDeclined
+		}
+		declineReason = string(reason)
+	}
+
+	err = s.transition(ctx, tx, status, declineReason)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// Конкурентная доставка того же события уже завершила транзакцию
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Payment processed successfully: transactionID=%s", transactionID)
-	return transactionID, true, nil
+	log.Printf("Pending payment completed: transactionID=%s, status=%s, event=%s", tx.TransactionID, status, event.ID)
+	return nil
 }
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
 	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
 	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -18,10 +20,10 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 	t.Run("amount <= 0 returns error, repo not called", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 0, "RUB", "card")
 
 		// Assert
 		require.Error(t, err)
@@ -29,16 +31,16 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 		require.False(t, success)
 		require.Empty(t, transactionID)
 		mockRepo.AssertNotCalled(t, "GetByOrderID")
-		mockRepo.AssertNotCalled(t, "Save")
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
 	})
 
 	t.Run("negative amount returns error, repo not called", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", -10.0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", -1000, "RUB", "card")
 
 		// Assert
 		require.Error(t, err)
@@ -46,55 +48,57 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 		require.False(t, success)
 		require.Empty(t, transactionID)
 		mockRepo.AssertNotCalled(t, "GetByOrderID")
-		mockRepo.AssertNotCalled(t, "Save")
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
 	})
 
 	t.Run("existing transaction returns same transactionID, Save not called", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		existingTx := repository.Transaction{
 			OrderID:       "order-1",
 			UserID:        "user-1",
-			Amount:        100.0,
+			Amount:        10000,
+			Currency:      "RUB",
 			Method:        "card",
 			TransactionID: "tx_order-1_1234567890",
-			Status:        "success",
+			Status:        repository.StatusCaptured,
 			CreatedAt:     time.Now().Unix(),
 		}
 
 		mockRepo.On("GetByOrderID", ctx, "order-1").Return(existingTx, nil).Once()
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100.0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 10000, "RUB", "card")
 
 		// Assert
 		require.NoError(t, err)
 		require.True(t, success)
 		require.Equal(t, "tx_order-1_1234567890", transactionID)
 		mockRepo.AssertExpectations(t)
-		mockRepo.AssertNotCalled(t, "Save")
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
 	})
 
 	t.Run("ErrNotFound creates new transaction and saves it", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		mockRepo.On("GetByOrderID", ctx, "order-2").Return(repository.Transaction{}, repository.ErrNotFound).Once()
-		mockRepo.On("Save", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
+		mockRepo.On("SaveWithOutbox", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
 			return tx.OrderID == "order-2" &&
 				tx.UserID == "user-2" &&
-				tx.Amount == 200.0 &&
+				tx.Amount == 20000 &&
+				tx.Currency == "RUB" &&
 				tx.Method == "card" &&
-				tx.Status == "success" &&
+				tx.Status == repository.StatusCaptured &&
 				tx.TransactionID != "" &&
 				tx.CreatedAt > 0
-		})).Return(nil).Once()
+		}), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-2", "user-2", 200.0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-2", "user-2", 20000, "RUB", "card")
 
 		// Assert
 		require.NoError(t, err)
@@ -104,16 +108,83 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("empty currency falls back to DefaultCurrency", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByOrderID", ctx, "order-5").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
+			return tx.OrderID == "order-5" && tx.Currency == DefaultCurrency
+		}), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-5", "user-5", 5000, "", "card")
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, success)
+		require.NotEmpty(t, transactionID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("amount above limit is declined and saved", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByOrderID", ctx, "order-6").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
+			return tx.OrderID == "order-6" &&
+				tx.Status == repository.StatusFailed &&
+				tx.DeclineReason == string(DeclineLimitExceeded)
+		}), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-6", "user-6", 100001, "RUB", "card")
+
+		// Assert
+		var declineErr *DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineLimitExceeded, declineErr.Reason)
+		require.False(t, success)
+		require.Empty(t, transactionID)
+	})
+
+	t.Run("existing declined transaction returns the same reason", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByOrderID", ctx, "order-7").Return(repository.Transaction{
+			OrderID:       "order-7",
+			Currency:      "RUB",
+			Status:        repository.StatusFailed,
+			DeclineReason: string(DeclineLimitExceeded),
+		}, nil).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-7", "user-7", 5000, "RUB", "card")
+
+		// Assert
+		var declineErr *DeclineError
+		require.ErrorAs(t, err, &declineErr)
+		require.Equal(t, DeclineLimitExceeded, declineErr.Reason)
+		require.False(t, success)
+		require.Empty(t, transactionID)
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
+	})
+
 	t.Run("GetByOrderID returns arbitrary error", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		arbitraryErr := errors.New("database connection failed")
 		mockRepo.On("GetByOrderID", ctx, "order-3").Return(repository.Transaction{}, arbitraryErr).Once()
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-3", "user-3", 300.0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-3", "user-3", 30000, "RUB", "card")
 
 		// Assert
 		require.Error(t, err)
@@ -121,22 +192,22 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 		require.False(t, success)
 		require.Empty(t, transactionID)
 		mockRepo.AssertExpectations(t)
-		mockRepo.AssertNotCalled(t, "Save")
+		mockRepo.AssertNotCalled(t, "SaveWithOutbox")
 	})
 
 	t.Run("Save returns error", func(t *testing.T) {
 		// Arrange
 		mockRepo := mocks.NewPaymentRepository(t)
-		service := NewPaymentService(mockRepo)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
 
 		saveErr := errors.New("failed to save to database")
 		mockRepo.On("GetByOrderID", ctx, "order-4").Return(repository.Transaction{}, repository.ErrNotFound).Once()
-		mockRepo.On("Save", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
+		mockRepo.On("SaveWithOutbox", ctx, mock.MatchedBy(func(tx repository.Transaction) bool {
 			return tx.OrderID == "order-4"
-		})).Return(saveErr).Once()
+		}), mock.AnythingOfType("repository.OutboxEvent")).Return(saveErr).Once()
 
 		// Act
-		transactionID, success, err := service.ProcessPayment(ctx, "order-4", "user-4", 400.0, "card")
+		transactionID, success, err := service.ProcessPayment(ctx, "order-4", "user-4", 40000, "RUB", "card")
 
 		// Assert
 		require.Error(t, err)
@@ -145,5 +216,104 @@ func TestPaymentService_ProcessPayment(t *testing.T) {
 		require.Empty(t, transactionID)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("concurrent Save conflict returns the stored transaction", func(t *testing.T) {
+		// Arrange
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 100000, nil, nil, nil, nil, nil)
+
+		storedTx := repository.Transaction{OrderID: "order-8", Currency: "RUB", TransactionID: "tx_concurrent", Status: repository.StatusCaptured}
+		mockRepo.On("GetByOrderID", ctx, "order-8").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.AnythingOfType("repository.Transaction"), mock.AnythingOfType("repository.OutboxEvent")).Return(repository.ErrAlreadyExists).Once()
+		mockRepo.On("GetByOrderID", ctx, "order-8").Return(storedTx, nil).Once()
+
+		// Act
+		transactionID, success, err := service.ProcessPayment(ctx, "order-8", "user-8", 10000, "RUB", "card")
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, success)
+		require.Equal(t, "tx_concurrent", transactionID)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
+func TestPaymentService_Pay_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card", IdempotencyKey: "key-1"}
+
+	t.Run("replay with same key returns original transaction", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		first, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+		replayed, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		require.Equal(t, first, replayed)
+		require.Equal(t, "key-1", replayed.IdempotencyKey)
+		require.Equal(t, "mock_pay_order-1_key-1", replayed.ProviderPaymentID)
+	})
+
+	t.Run("new key retries declined order, old key still returns decline", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+		declined := input
+		declined.Method = mockprovider.MethodInsufficientFunds
+		_, err := service.Pay(ctx, declined)
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+
+		retry := input
+		retry.IdempotencyKey = "key-2"
+		tx, err := service.Pay(ctx, retry)
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+
+		_, err = service.Pay(ctx, declined)
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineInsufficientFunds, declineErr.Reason)
+		latest, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, tx.TransactionID, latest.TransactionID)
+	})
+
+	t.Run("new key on paid order returns ErrOrderAlreadyPaid", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		_, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		retry := input
+		retry.IdempotencyKey = "key-2"
+		_, err = service.Pay(ctx, retry)
+
+		require.ErrorIs(t, err, ErrOrderAlreadyPaid)
+	})
+
+	t.Run("request without key replays latest attempt", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		paid, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		withoutKey := input
+		withoutKey.IdempotencyKey = ""
+		tx, err := service.Pay(ctx, withoutKey)
+
+		require.NoError(t, err)
+		require.Equal(t, paid.TransactionID, tx.TransactionID)
+	})
+
+	t.Run("concurrent attempt with another key returns ErrOrderAlreadyPaid", func(t *testing.T) {
+		mockRepo := mocks.NewPaymentRepository(t)
+		service := NewPaymentService(mockRepo, 1000, nil, nil, nil, nil, nil)
+		mockRepo.On("GetByIdempotencyKey", ctx, "order-1", "key-1").Return(repository.Transaction{}, repository.ErrNotFound).Twice()
+		mockRepo.On("GetByOrderID", ctx, "order-1").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		mockRepo.On("SaveWithOutbox", ctx, mock.Anything, mock.AnythingOfType("repository.OutboxEvent")).Return(repository.ErrAlreadyExists).Once()
+		mockRepo.On("GetByOrderID", ctx, "order-1").
+			Return(repository.Transaction{TransactionID: "tx_concurrent", OrderID: "order-1", Status: repository.StatusCaptured, IdempotencyKey: "key-2"}, nil).Once()
+
+		_, err := service.Pay(ctx, input)
+
+		require.ErrorIs(t, err, ErrOrderAlreadyPaid)
+	})
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// ErrInvalidTransition возвращается, если из текущего статуса транзакции нельзя перейти в запрошенный
+var ErrInvalidTransition = errors.New("invalid transaction status transition")
+
+// transitions - допустимые переходы статуса транзакции
+//
+//	pending    -> captured | failed        webhook провайдера о результате отложенного платежа
+//	authorized -> captured | failed        ConfirmPayment: списание или отказ провайдера в списании
+//	authorized -> voided                   VoidAuthorization: отмена авторизации до списания
+//	captured   -> refunded                 возврат всей списанной суммы (последний из частичных возвратов)
+//
+// Начальный статус выбирает ProcessPayment: captured, authorized (manual_capture), pending или failed.
+// failed, voided и refunded - конечные
+var transitions = map[string][]string{
+	repository.StatusPending:    {repository.StatusCaptured, repository.StatusFailed},
+	repository.StatusAuthorized: {repository.StatusCaptured, repository.StatusFailed, repository.StatusVoided},
+	repository.StatusCaptured:   {repository.StatusRefunded},
+}
+
+// validateTransition проверяет переход from -> to по transitions
+func validateTransition(from, to string) error {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+}
+
+// isCharged сообщает, были ли деньги по транзакции списаны (в том числе если потом возвращены)
+func isCharged(status string) bool {
+	return status == repository.StatusCaptured || status == repository.StatusRefunded
+}
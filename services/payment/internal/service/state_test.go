@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// captureDeclineProvider - mock провайдер, отказывающий в списании (истёкшая авторизация)
+type captureDeclineProvider struct {
+	*mockprovider.Provider
+}
+
+func (p captureDeclineProvider) Capture(ctx context.Context, paymentID string, amount int64) error {
+	return &provider.DeclineError{Code: provider.DeclineCard, Message: "authorization expired"}
+}
+
+// voidDeclineProvider - mock провайдер, отказывающий в отмене авторизации
+type voidDeclineProvider struct {
+	*mockprovider.Provider
+}
+
+func (p voidDeclineProvider) Void(ctx context.Context, paymentID string) error {
+	return &provider.DeclineError{Code: provider.DeclineCard, Message: "authorization already settled"}
+}
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		valid    bool
+	}{
+		{repository.StatusPending, repository.StatusCaptured, true},
+		{repository.StatusPending, repository.StatusFailed, true},
+		{repository.StatusAuthorized, repository.StatusCaptured, true},
+		{repository.StatusAuthorized, repository.StatusFailed, true},
+		{repository.StatusCaptured, repository.StatusRefunded, true},
+		{repository.StatusAuthorized, repository.StatusVoided, true},
+		{repository.StatusCaptured, repository.StatusVoided, false},
+		{repository.StatusPending, repository.StatusVoided, false},
+		{repository.StatusVoided, repository.StatusCaptured, false},
+		{repository.StatusPending, repository.StatusRefunded, false},
+		{repository.StatusAuthorized, repository.StatusRefunded, false},
+		{repository.StatusCaptured, repository.StatusFailed, false},
+		{repository.StatusFailed, repository.StatusCaptured, false},
+		{repository.StatusRefunded, repository.StatusCaptured, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			err := validateTransition(tt.from, tt.to)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidTransition)
+			}
+		})
+	}
+}
+
+func TestPaymentService_ConfirmPayment(t *testing.T) {
+	ctx := context.Background()
+	authorize := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card", ManualCapture: true}
+
+	t.Run("manual capture: authorized then captured, repeat is idempotent", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		authorized, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusAuthorized, authorized.Status)
+		_, success, err := service.ProcessPayment(ctx, "order-1", "user-1", 100, "RUB", "card")
+		require.NoError(t, err)
+		require.False(t, success)
+
+		captured, err := service.ConfirmPayment(ctx, "order-1", 0)
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, captured.Status)
+		require.Equal(t, authorized.TransactionID, captured.TransactionID)
+
+		repeated, err := service.ConfirmPayment(ctx, "order-1", 0)
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, repeated.Status)
+	})
+
+	t.Run("partial capture limits refunds to captured amount", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		gateway := mockprovider.New()
+		service := NewPaymentService(paymentRepo, 1000, gateway, nil, nil, nil, nil)
+		refunds := NewRefundService(paymentRepo, memory.NewRefundRepository(paymentRepo), gateway, nil, 10, 1, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+
+		captured, err := service.ConfirmPayment(ctx, "order-1", 60)
+		require.NoError(t, err)
+		require.Equal(t, int64(60), captured.CapturedAmount)
+		_, _, exceededErr := refunds.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 70})
+		refund, _, err := refunds.Refund(ctx, RefundInput{OrderID: "order-1"})
+
+		require.ErrorIs(t, exceededErr, ErrRefundAmountExceeded)
+		require.NoError(t, err)
+		require.Equal(t, int64(60), refund.Amount)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusRefunded, tx.Status)
+		require.Equal(t, int64(60), tx.RefundedAmount)
+	})
+
+	t.Run("capture amount above authorized is rejected", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+
+		_, negativeErr := service.ConfirmPayment(ctx, "order-1", -1)
+		_, exceededErr := service.ConfirmPayment(ctx, "order-1", 101)
+
+		require.ErrorIs(t, negativeErr, ErrInvalidCaptureAmount)
+		require.ErrorIs(t, exceededErr, ErrCaptureAmountExceeded)
+	})
+
+	t.Run("pending payment cannot be confirmed", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		input := authorize
+		input.Method = mockprovider.MethodSBP
+		_, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		_, err = service.ConfirmPayment(ctx, "order-1", 0)
+
+		require.ErrorIs(t, err, ErrInvalidTransition)
+	})
+
+	t.Run("failed payment cannot be confirmed", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		input := authorize
+		input.Method = mockprovider.MethodDeclined
+		_, err := service.Pay(ctx, input)
+		require.Error(t, err)
+
+		_, err = service.ConfirmPayment(ctx, "order-1", 0)
+
+		require.ErrorIs(t, err, ErrInvalidTransition)
+	})
+
+	t.Run("capture decline fails transaction", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, captureDeclineProvider{mockprovider.New()}, nil, nil, nil, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+
+		_, err = service.ConfirmPayment(ctx, "order-1", 0)
+
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineCardDeclined, declineErr.Reason)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusFailed, tx.Status)
+		require.Equal(t, string(DeclineCardDeclined), tx.DeclineReason)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		_, err := service.ConfirmPayment(ctx, "", 0)
+		require.ErrorIs(t, err, ErrOrderIDRequired)
+		_, err = service.ConfirmPayment(ctx, "order-unknown", 0)
+		require.ErrorIs(t, err, ErrPaymentNotFound)
+	})
+}
+
+func TestPaymentService_VoidAuthorization(t *testing.T) {
+	ctx := context.Background()
+	authorize := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 100, Currency: "RUB", Method: "card", ManualCapture: true}
+
+	t.Run("authorized payment is voided once with payment.voided event", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, mockprovider.New(), nil, nil, nil, nil)
+		authorized, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+
+		voided, err := service.VoidAuthorization(ctx, "order-1")
+		require.NoError(t, err)
+		repeated, err := service.VoidAuthorization(ctx, "order-1")
+		require.NoError(t, err)
+
+		require.Equal(t, repository.StatusVoided, voided.Status)
+		require.Equal(t, authorized.TransactionID, voided.TransactionID)
+		require.Equal(t, repository.StatusVoided, repeated.Status)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusVoided, tx.Status)
+		require.Zero(t, tx.CapturedAmount)
+		events := paymentRepo.OutboxEvents()
+		require.Len(t, events, 1)
+		require.Equal(t, EventTypePaymentVoided, events[0].EventType)
+		require.Equal(t, "payment.voided-"+authorized.TransactionID, events[0].EventID)
+	})
+
+	t.Run("voided authorization cannot be captured or paid again", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+		_, err = service.VoidAuthorization(ctx, "order-1")
+		require.NoError(t, err)
+
+		_, confirmErr := service.ConfirmPayment(ctx, "order-1", 0)
+		retry := authorize
+		retry.IdempotencyKey = "retry-1"
+		_, payErr := service.Pay(ctx, retry)
+
+		require.ErrorIs(t, confirmErr, ErrInvalidTransition)
+		require.ErrorIs(t, payErr, ErrOrderAlreadyPaid)
+	})
+
+	t.Run("captured payment cannot be voided", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+		_, err = service.ConfirmPayment(ctx, "order-1", 0)
+		require.NoError(t, err)
+
+		_, err = service.VoidAuthorization(ctx, "order-1")
+
+		require.ErrorIs(t, err, ErrInvalidTransition)
+	})
+
+	t.Run("pending payment cannot be voided", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+		input := authorize
+		input.Method = mockprovider.MethodSBP
+		_, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		_, err = service.VoidAuthorization(ctx, "order-1")
+
+		require.ErrorIs(t, err, ErrInvalidTransition)
+	})
+
+	t.Run("provider decline keeps authorization", func(t *testing.T) {
+		paymentRepo := memory.NewMemoryRepository()
+		service := NewPaymentService(paymentRepo, 1000, voidDeclineProvider{mockprovider.New()}, nil, nil, nil, nil)
+		_, err := service.Pay(ctx, authorize)
+		require.NoError(t, err)
+
+		_, err = service.VoidAuthorization(ctx, "order-1")
+
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineCardDeclined, declineErr.Reason)
+		tx, err := paymentRepo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusAuthorized, tx.Status)
+		require.Empty(t, paymentRepo.OutboxEvents())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := NewPaymentService(memory.NewMemoryRepository(), 1000, mockprovider.New(), nil, nil, nil, nil)
+
+		_, err := service.VoidAuthorization(ctx, "")
+		require.ErrorIs(t, err, ErrOrderIDRequired)
+		_, err = service.VoidAuthorization(ctx, "order-unknown")
+		require.ErrorIs(t, err, ErrPaymentNotFound)
+	})
+}
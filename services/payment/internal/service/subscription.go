@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// MinSubscriptionInterval - минимальный интервал между списаниями подписки
+const MinSubscriptionInterval = time.Minute
+
+// chargeBatchSize - сколько подписок списывает один проход планировщика
+const chargeBatchSize = 100
+
+// Типы событий подписки
+const (
+	EventTypeSubscriptionCharged = "payment.subscription.charged"
+	EventTypeSubscriptionFailed  = "payment.subscription.failed"
+)
+
+// Ошибки валидации подписки (handler маппит в codes.InvalidArgument)
+var (
+	ErrUserIDRequired  = errors.New("user_id is required")
+	ErrInvalidAmount   = errors.New("amount must be greater than 0")
+	ErrInvalidInterval = fmt.Errorf("interval must be at least %s", MinSubscriptionInterval)
+)
+
+// SubscriptionInput содержит параметры новой подписки
+type SubscriptionInput struct {
+	UserID   string
+	Amount   int64  // в минимальных единицах валюты
+	Currency string // из поддерживаемых (NormalizeCurrency); пусто - DefaultCurrency
+	Method   string
+	Interval time.Duration
+}
+
+// SubscriptionEvent - событие списания по подписке (EventTypeSubscriptionCharged или EventTypeSubscriptionFailed)
+// EventID детерминирован по подписке и периоду: повторная публикация того же периода не создаёт нового события
+type SubscriptionEvent struct {
+	EventID        string
+	EventType      string
+	OccurredAt     time.Time
+	SubscriptionID string
+	UserID         string
+	Period         int64
+	Amount         int64 // в минимальных единицах валюты
+	Currency       string
+	TransactionID  string        // только для charged
+	DeclineReason  DeclineReason // только для failed
+}
+
+// SubscriptionEventPublisher публикует события списаний по подписке
+type SubscriptionEventPublisher interface {
+	PublishSubscriptionEvent(ctx context.Context, event SubscriptionEvent) error
+}
+
+// SubscriptionService содержит бизнес-логику регулярных платежей
+// Каждое списание проходит через PaymentService.ProcessPayment с order_id вида sub_<id>_<период>:
+// идемпотентность ProcessPayment гарантирует не больше одного списания за период даже при повторах
+type SubscriptionService struct {
+	repo      repository.SubscriptionRepository
+	payments  *PaymentService
+	publisher SubscriptionEventPublisher
+}
+
+// NewSubscriptionService создаёт сервис подписок
+func NewSubscriptionService(repo repository.SubscriptionRepository, payments *PaymentService, publisher SubscriptionEventPublisher) *SubscriptionService {
+	return &SubscriptionService{
+		repo:      repo,
+		payments:  payments,
+		publisher: publisher,
+	}
+}
+
+// CreateSubscription создаёт активную подписку; первое списание - на ближайшем проходе планировщика
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, input SubscriptionInput) (repository.Subscription, error) {
+	log.Printf("CreateSubscription called: user=%s, amount=%d, currency=%s, interval=%s",
+		input.UserID, input.Amount, input.Currency, input.Interval)
+
+	if input.UserID == "" {
+		return repository.Subscription{}, ErrUserIDRequired
+	}
+	if input.Amount <= 0 {
+		return repository.Subscription{}, ErrInvalidAmount
+	}
+	if input.Interval < MinSubscriptionInterval {
+		return repository.Subscription{}, ErrInvalidInterval
+	}
+	currency, err := NormalizeCurrency(input.Currency)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+
+	now := time.Now().UTC()
+	subscription := repository.Subscription{
+		ID:           fmt.Sprintf("sub_%s_%d", input.UserID, now.UnixNano()),
+		UserID:       input.UserID,
+		Amount:       input.Amount,
+		Currency:     currency,
+		Method:       input.Method,
+		Interval:     input.Interval,
+		Status:       repository.SubscriptionStatusActive,
+		Period:       1,
+		NextChargeAt: now,
+		CreatedAt:    now,
+	}
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		log.Printf("Failed to save subscription: %v", err)
+		return repository.Subscription{}, fmt.Errorf("failed to save subscription: %w", err)
+	}
+
+	log.Printf("Subscription created: id=%s, user=%s", subscription.ID, subscription.UserID)
+	return subscription, nil
+}
+
+// GetSubscription возвращает подписку по ID
+// Возвращает repository.ErrSubscriptionNotFound, если подписки нет
+func (s *SubscriptionService) GetSubscription(ctx context.Context, subscriptionID string) (repository.Subscription, error) {
+	return s.repo.GetSubscription(ctx, subscriptionID)
+}
+
+// ChargeDue списывает все подписки, срок списания которых наступил к now, и возвращает число обработанных периодов
+// Ошибка одной подписки не останавливает остальные: подписка останется на том же периоде и будет списана на следующем проходе
+func (s *SubscriptionService) ChargeDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.repo.ListDueSubscriptions(ctx, now, chargeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due subscriptions: %w", err)
+	}
+
+	processed := 0
+	for _, subscription := range due {
+		if err := s.chargePeriod(ctx, subscription, now); err != nil {
+			if ctx.Err() != nil {
+				return processed, ctx.Err()
+			}
+			log.Printf("Subscription charge error: id=%s, period=%d: %v", subscription.ID, subscription.Period, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// chargePeriod списывает текущий период подписки, публикует событие и переводит подписку на следующий период
+// Порядок списание -> событие -> продвижение периода даёт at-least-once доставку события: если публикация или
+// продвижение не удались, период повторяется, ProcessPayment возвращает прежний результат без нового списания,
+// а событие публикуется с тем же event_id
+func (s *SubscriptionService) chargePeriod(ctx context.Context, subscription repository.Subscription, now time.Time) error {
+	orderID := fmt.Sprintf("sub_%s_%d", subscription.ID, subscription.Period)
+
+	event := SubscriptionEvent{
+		OccurredAt:     now,
+		SubscriptionID: subscription.ID,
+		UserID:         subscription.UserID,
+		Period:         subscription.Period,
+		Amount:         subscription.Amount,
+		Currency:       subscription.Currency,
+	}
+
+	transactionID, success, err := s.payments.ProcessPayment(ctx, orderID, subscription.UserID, subscription.Amount, subscription.Currency, subscription.Method)
+	var declineErr *DeclineError
+	switch {
+	case err == nil && !success:
+		// Платёж ждёт подтверждения провайдера: период повторится, когда webhook завершит транзакцию
+		return fmt.Errorf("payment %s is pending", transactionID)
+	case err == nil:
+		event.EventType = EventTypeSubscriptionCharged
+		event.TransactionID = transactionID
+	case errors.As(err, &declineErr) && declineErr.Reason == DeclineProviderError:
+		// Провайдер временно недоступен: отказ не сохранён, период повторится на следующем проходе
+		return fmt.Errorf("failed to process payment: %w", err)
+	case errors.As(err, &declineErr):
+		// Отказ - результат периода: период не повторяется, следующее списание - по расписанию
+		event.EventType = EventTypeSubscriptionFailed
+		event.DeclineReason = declineErr.Reason
+	default:
+		return fmt.Errorf("failed to process payment: %w", err)
+	}
+	event.EventID = fmt.Sprintf("%s-%s-%d", event.EventType, subscription.ID, subscription.Period)
+
+	if err := s.publisher.PublishSubscriptionEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", event.EventType, err)
+	}
+
+	err = s.repo.AdvanceSubscription(ctx, subscription.ID, subscription.Period, nextChargeAt(subscription, now))
+	if errors.Is(err, repository.ErrSubscriptionConflict) {
+		log.Printf("Subscription period already advanced: id=%s, period=%d", subscription.ID, subscription.Period)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to advance subscription: %w", err)
+	}
+
+	log.Printf("Subscription period processed: id=%s, period=%d, event=%s", subscription.ID, subscription.Period, event.EventType)
+	return nil
+}
+
+// nextChargeAt возвращает время следующего списания по сетке NextChargeAt + k*Interval
+// Пропущенные периоды (планировщик не работал дольше интервала) не списываются задним числом
+func nextChargeAt(subscription repository.Subscription, now time.Time) time.Time {
+	next := subscription.NextChargeAt.Add(subscription.Interval)
+	for !next.After(now) {
+		next = next.Add(subscription.Interval)
+	}
+	return next
+}
+
+// SubscriptionScheduler периодически списывает подписки, срок которых наступил
+type SubscriptionScheduler struct {
+	service  *SubscriptionService
+	interval time.Duration
+}
+
+// NewSubscriptionScheduler создаёт планировщик списаний
+func NewSubscriptionScheduler(service *SubscriptionService, interval time.Duration) *SubscriptionScheduler {
+	return &SubscriptionScheduler{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start запускает проверку по таймеру до отмены контекста
+func (w *SubscriptionScheduler) Start(ctx context.Context) error {
+	log.Printf("Starting subscription scheduler: interval=%s", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Subscription scheduler stopped")
+			return nil
+		case <-ticker.C:
+			processed, err := w.service.ChargeDue(ctx, time.Now().UTC())
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("Subscription scheduler error: %v", err)
+				continue
+			}
+			if processed > 0 {
+				log.Printf("Subscription scheduler: %d subscription periods processed", processed)
+			}
+		}
+	}
+}
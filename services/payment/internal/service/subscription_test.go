@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriptionPublisher запоминает опубликованные события
+// Мок не генерируется: он импортировал бы service (SubscriptionEvent) - цикл импортов с тестами service
+type fakeSubscriptionPublisher struct {
+	events []SubscriptionEvent
+	err    error
+}
+
+func (p *fakeSubscriptionPublisher) PublishSubscriptionEvent(ctx context.Context, event SubscriptionEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestSubscriptionService_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("validation errors, repo not called", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			input SubscriptionInput
+			err   error
+		}{
+			{"empty user", SubscriptionInput{Amount: 10, Interval: time.Hour}, ErrUserIDRequired},
+			{"zero amount", SubscriptionInput{UserID: "user-1", Interval: time.Hour}, ErrInvalidAmount},
+			{"short interval", SubscriptionInput{UserID: "user-1", Amount: 10, Interval: time.Second}, ErrInvalidInterval},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				// Arrange
+				subRepo := mocks.NewSubscriptionRepository(t)
+				svc := NewSubscriptionService(subRepo, NewPaymentService(mocks.NewPaymentRepository(t), 1000, nil, nil, nil, nil, nil), &fakeSubscriptionPublisher{})
+
+				// Act
+				_, err := svc.CreateSubscription(ctx, tc.input)
+
+				// Assert
+				require.ErrorIs(t, err, tc.err)
+				subRepo.AssertNotCalled(t, "CreateSubscription")
+			})
+		}
+	})
+
+	t.Run("creates active subscription due immediately", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		svc := NewSubscriptionService(subRepo, NewPaymentService(mocks.NewPaymentRepository(t), 1000, nil, nil, nil, nil, nil), &fakeSubscriptionPublisher{})
+
+		subRepo.On("CreateSubscription", ctx, mock.MatchedBy(func(s repository.Subscription) bool {
+			return s.UserID == "user-1" && s.Currency == "USD" && s.Period == 1 &&
+				s.Status == repository.SubscriptionStatusActive && s.NextChargeAt.Equal(s.CreatedAt)
+		})).Return(nil).Once()
+
+		// Act
+		subscription, err := svc.CreateSubscription(ctx, SubscriptionInput{UserID: "user-1", Amount: 10, Currency: "usd", Interval: time.Hour})
+
+		// Assert
+		require.NoError(t, err)
+		require.NotEmpty(t, subscription.ID)
+		require.Equal(t, time.Hour, subscription.Interval)
+	})
+}
+
+func TestSubscriptionService_ChargeDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	newSubscription := func(amount int64) repository.Subscription {
+		return repository.Subscription{
+			ID:           "sub-1",
+			UserID:       "user-1",
+			Amount:       amount,
+			Currency:     "RUB",
+			Method:       "card",
+			Interval:     time.Hour,
+			Status:       repository.SubscriptionStatusActive,
+			Period:       3,
+			NextChargeAt: now.Add(-90 * time.Minute),
+		}
+	}
+
+	t.Run("successful charge publishes charged event and advances period", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		paymentRepo := mocks.NewPaymentRepository(t)
+		publisher := &fakeSubscriptionPublisher{}
+		svc := NewSubscriptionService(subRepo, NewPaymentService(paymentRepo, 1000, nil, nil, nil, nil, nil), publisher)
+
+		subscription := newSubscription(100)
+		subRepo.On("ListDueSubscriptions", ctx, now, chargeBatchSize).Return([]repository.Subscription{subscription}, nil).Once()
+		paymentRepo.On("GetByOrderID", ctx, "sub_sub-1_3").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		paymentRepo.On("SaveWithOutbox", ctx, mock.AnythingOfType("repository.Transaction"), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+		// NextChargeAt было 1.5 часа назад: пропущенный период не списывается, следующее списание через полчаса
+		subRepo.On("AdvanceSubscription", ctx, "sub-1", int64(3), now.Add(30*time.Minute)).Return(nil).Once()
+
+		// Act
+		processed, err := svc.ChargeDue(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Len(t, publisher.events, 1)
+		event := publisher.events[0]
+		require.Equal(t, EventTypeSubscriptionCharged, event.EventType)
+		require.Equal(t, "payment.subscription.charged-sub-1-3", event.EventID)
+		require.Equal(t, int64(3), event.Period)
+		require.NotEmpty(t, event.TransactionID)
+	})
+
+	t.Run("declined charge publishes failed event and advances period", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		paymentRepo := mocks.NewPaymentRepository(t)
+		publisher := &fakeSubscriptionPublisher{}
+		svc := NewSubscriptionService(subRepo, NewPaymentService(paymentRepo, 1000, nil, nil, nil, nil, nil), publisher)
+
+		subscription := newSubscription(5000)
+		subRepo.On("ListDueSubscriptions", ctx, now, chargeBatchSize).Return([]repository.Subscription{subscription}, nil).Once()
+		paymentRepo.On("GetByOrderID", ctx, "sub_sub-1_3").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		paymentRepo.On("SaveWithOutbox", ctx, mock.AnythingOfType("repository.Transaction"), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+		subRepo.On("AdvanceSubscription", ctx, "sub-1", int64(3), now.Add(30*time.Minute)).Return(nil).Once()
+
+		// Act
+		processed, err := svc.ChargeDue(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Len(t, publisher.events, 1)
+		require.Equal(t, EventTypeSubscriptionFailed, publisher.events[0].EventType)
+		require.Equal(t, DeclineLimitExceeded, publisher.events[0].DeclineReason)
+		require.Empty(t, publisher.events[0].TransactionID)
+	})
+
+	t.Run("publish failure keeps period for retry", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		paymentRepo := mocks.NewPaymentRepository(t)
+		publisher := &fakeSubscriptionPublisher{err: errors.New("kafka unavailable")}
+		svc := NewSubscriptionService(subRepo, NewPaymentService(paymentRepo, 1000, nil, nil, nil, nil, nil), publisher)
+
+		subscription := newSubscription(100)
+		subRepo.On("ListDueSubscriptions", ctx, now, chargeBatchSize).Return([]repository.Subscription{subscription}, nil).Once()
+		paymentRepo.On("GetByOrderID", ctx, "sub_sub-1_3").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+		paymentRepo.On("SaveWithOutbox", ctx, mock.AnythingOfType("repository.Transaction"), mock.AnythingOfType("repository.OutboxEvent")).Return(nil).Once()
+
+		// Act
+		processed, err := svc.ChargeDue(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 0, processed)
+		subRepo.AssertNotCalled(t, "AdvanceSubscription")
+	})
+
+	t.Run("provider error keeps period for retry", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		paymentRepo := mocks.NewPaymentRepository(t)
+		publisher := &fakeSubscriptionPublisher{}
+		provider := NewProviderSimulator(mockprovider.New(), ProviderSimulation{FailureRate: 1}, nil)
+		svc := NewSubscriptionService(subRepo, NewPaymentService(paymentRepo, 1000, provider, nil, nil, nil, nil), publisher)
+
+		subscription := newSubscription(100)
+		subRepo.On("ListDueSubscriptions", ctx, now, chargeBatchSize).Return([]repository.Subscription{subscription}, nil).Once()
+		paymentRepo.On("GetByOrderID", ctx, "sub_sub-1_3").Return(repository.Transaction{}, repository.ErrNotFound).Once()
+
+		// Act
+		processed, err := svc.ChargeDue(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 0, processed)
+		require.Empty(t, publisher.events)
+		paymentRepo.AssertNotCalled(t, "SaveWithOutbox")
+		subRepo.AssertNotCalled(t, "AdvanceSubscription")
+	})
+
+	t.Run("retried period reuses existing transaction", func(t *testing.T) {
+		// Arrange
+		subRepo := mocks.NewSubscriptionRepository(t)
+		paymentRepo := mocks.NewPaymentRepository(t)
+		publisher := &fakeSubscriptionPublisher{}
+		svc := NewSubscriptionService(subRepo, NewPaymentService(paymentRepo, 1000, nil, nil, nil, nil, nil), publisher)
+
+		subscription := newSubscription(100)
+		existingTx := repository.Transaction{
+			OrderID:       "sub_sub-1_3",
+			UserID:        "user-1",
+			Amount:        100,
+			Currency:      "RUB",
+			TransactionID: "tx_sub_sub-1_3_1",
+			Status:        repository.StatusCaptured,
+		}
+		subRepo.On("ListDueSubscriptions", ctx, now, chargeBatchSize).Return([]repository.Subscription{subscription}, nil).Once()
+		paymentRepo.On("GetByOrderID", ctx, "sub_sub-1_3").Return(existingTx, nil).Once()
+		// Период уже продвинут параллельным проходом - это не ошибка
+		subRepo.On("AdvanceSubscription", ctx, "sub-1", int64(3), now.Add(30*time.Minute)).Return(repository.ErrSubscriptionConflict).Once()
+
+		// Act
+		processed, err := svc.ChargeDue(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		paymentRepo.AssertNotCalled(t, "SaveWithOutbox")
+		require.Len(t, publisher.events, 1)
+		require.Equal(t, "tx_sub_sub-1_3_1", publisher.events[0].TransactionID)
+	})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+)
+
+// MethodWallet - способ оплаты с баланса кошелька пользователя (ProcessPayment с method = "wallet")
+// Провайдер не вызывается: списание с кошелька и транзакция сохраняются в одной транзакции БД
+const MethodWallet = "wallet"
+
+// Ошибки кошелька
+var (
+	// ErrOperationIDRequired - не передан ID операции зачисления (ключ идемпотентности)
+	ErrOperationIDRequired = errors.New("operation_id is required")
+	// ErrWalletOperationConflict - ID операции уже использован операцией с другими параметрами
+	ErrWalletOperationConflict = errors.New("wallet operation id is already used by another operation")
+	// ErrWalletDisabled - сервис собран без хранилища кошельков
+	ErrWalletDisabled = errors.New("wallet is not available")
+	// ErrWalletManualCapture - оплата с кошелька списывается сразу, двухшаговой оплаты у неё нет
+	ErrWalletManualCapture = errors.New("wallet payments do not support manual capture")
+)
+
+// WalletService содержит бизнес-логику кошельков: баланс и зачисление промо-бонусов
+// Списание (оплата с кошелька) выполняет PaymentService, зачисление возврата - RefundService
+type WalletService struct {
+	wallets repository.WalletRepository
+}
+
+// NewWalletService создаёт сервис кошельков
+func NewWalletService(wallets repository.WalletRepository) *WalletService {
+	return &WalletService{wallets: wallets}
+}
+
+// CreditInput - зачисление промо-бонуса на кошелёк
+type CreditInput struct {
+	// OperationID - ключ идемпотентности: повтор с тем же ID не зачисляет деньги второй раз
+	OperationID string
+	UserID      string
+	Amount      int64  // в минимальных единицах валюты
+	Currency    string // пусто - DefaultCurrency
+	Reason      string // промо-акция или обращение в поддержку
+}
+
+// GetWallet возвращает кошелёк пользователя в валюте currency
+// Кошелёк без зачислений возвращается с нулевым балансом, а не ошибкой
+func (s *WalletService) GetWallet(ctx context.Context, userID, currency string) (repository.Wallet, error) {
+	if userID == "" {
+		return repository.Wallet{}, ErrUserIDRequired
+	}
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return repository.Wallet{}, err
+	}
+
+	wallet, err := s.wallets.GetWallet(ctx, userID, currency)
+	if errors.Is(err, repository.ErrWalletNotFound) {
+		return repository.Wallet{UserID: userID, Currency: currency}, nil
+	}
+	if err != nil {
+		return repository.Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return wallet, nil
+}
+
+// Credit зачисляет промо-бонус на кошелёк
+// replayed = true, если операция с этим OperationID уже была применена: тогда возвращается она, баланс не меняется
+func (s *WalletService) Credit(ctx context.Context, input CreditInput) (op repository.WalletOperation, replayed bool, err error) {
+	log.Printf("Wallet credit called: operation=%s, user=%s, amount=%d, currency=%s",
+		input.OperationID, input.UserID, input.Amount, input.Currency)
+
+	if input.OperationID == "" {
+		return repository.WalletOperation{}, false, ErrOperationIDRequired
+	}
+	if input.UserID == "" {
+		return repository.WalletOperation{}, false, ErrUserIDRequired
+	}
+	if input.Amount <= 0 {
+		return repository.WalletOperation{}, false, ErrInvalidAmount
+	}
+	currency, err := NormalizeCurrency(input.Currency)
+	if err != nil {
+		return repository.WalletOperation{}, false, err
+	}
+
+	return creditWallet(ctx, s.wallets, repository.WalletOperation{
+		OperationID: input.OperationID,
+		UserID:      input.UserID,
+		Currency:    currency,
+		Kind:        repository.WalletOperationPromo,
+		Amount:      input.Amount,
+		Reference:   input.Reason,
+		CreatedAt:   time.Now().UTC(),
+	})
+}
+
+// creditWallet применяет зачисление op; повтор операции возвращает сохранённую операцию с replayed = true,
+// если она совпадает с op, и ErrWalletOperationConflict, если нет
+func creditWallet(ctx context.Context, wallets repository.WalletRepository, op repository.WalletOperation) (repository.WalletOperation, bool, error) {
+	err := wallets.CreditWallet(ctx, op)
+	if err == nil {
+		log.Printf("Wallet credited: operation=%s, user=%s, kind=%s, amount=%s", op.OperationID, op.UserID, op.Kind, formatAmount(op.Amount, op.Currency))
+		return op, false, nil
+	}
+	if !errors.Is(err, repository.ErrWalletOperationExists) {
+		return repository.WalletOperation{}, false, fmt.Errorf("failed to credit wallet: %w", err)
+	}
+
+	existing, err := wallets.GetWalletOperation(ctx, op.OperationID)
+	if err != nil {
+		return repository.WalletOperation{}, false, fmt.Errorf("failed to get wallet operation: %w", err)
+	}
+	if existing.UserID != op.UserID || existing.Currency != op.Currency || existing.Kind != op.Kind || existing.Amount != op.Amount {
+		return repository.WalletOperation{}, false, fmt.Errorf("%w: %s", ErrWalletOperationConflict, op.OperationID)
+	}
+	return existing, true, nil
+}
+
+// payFromWallet списывает сумму платежа с кошелька и сохраняет транзакцию tx (StatusCaptured) с событием outbox
+// атомарно; нехватка баланса - отказ DeclineInsufficientFunds, сохраняемый как обычный отказ
+func (s *PaymentService) payFromWallet(ctx context.Context, tx repository.Transaction) (repository.Transaction, error) {
+	event, _, err := paymentEvent(tx, time.Now().UTC())
+	if err != nil {
+		return repository.Transaction{}, fmt.Errorf("failed to build payment event: %w", err)
+	}
+	op := repository.WalletOperation{
+		OperationID: "pay_" + tx.TransactionID,
+		UserID:      tx.UserID,
+		Currency:    tx.Currency,
+		Kind:        repository.WalletOperationPayment,
+		Amount:      tx.Amount,
+		Reference:   tx.OrderID,
+		CreatedAt:   time.Unix(tx.CreatedAt, 0).UTC(),
+	}
+
+	err = s.wallets.PayFromWallet(ctx, op, tx, event)
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		return s.decline(ctx, tx, repository.StatusFailed, &DeclineError{
+			Reason:  DeclineInsufficientFunds,
+			Message: fmt.Sprintf("wallet balance is less than %s", formatAmount(tx.Amount, tx.Currency)),
+		})
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return s.concurrentResult(ctx, tx)
+	default:
+		log.Printf("Failed to pay from wallet: %v", err)
+		return repository.Transaction{}, fmt.Errorf("failed to pay from wallet: %w", err)
+	}
+
+	log.Printf("Payment processed from wallet: transactionID=%s, user=%s", tx.TransactionID, tx.UserID)
+	return tx, nil
+}
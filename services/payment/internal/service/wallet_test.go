@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shestoi/GoBigTech/services/payment/internal/provider"
+	mockprovider "github.com/shestoi/GoBigTech/services/payment/internal/provider/mock"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/memory"
+	"github.com/shestoi/GoBigTech/services/payment/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// requireBalancedEntries проверяет, что проводки каждой операции в сумме дают 0
+func requireBalancedEntries(t *testing.T, entries []repository.WalletEntry) {
+	t.Helper()
+	sums := make(map[string]int64)
+	for _, entry := range entries {
+		sums[entry.OperationID] += entry.Amount
+	}
+	for operationID, sum := range sums {
+		require.Zerof(t, sum, "entries of operation %s are not balanced", operationID)
+	}
+}
+
+func TestWalletService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wallet without credits has zero balance", func(t *testing.T) {
+		// Arrange
+		svc := NewWalletService(memory.NewMemoryRepository())
+
+		// Act
+		wallet, err := svc.GetWallet(ctx, "user-1", "")
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, repository.Wallet{UserID: "user-1", Currency: DefaultCurrency}, wallet)
+	})
+
+	t.Run("credit is applied once per operation id", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		svc := NewWalletService(repo)
+		input := CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: 50000, Currency: "rub", Reason: "welcome"}
+
+		// Act
+		op, replayed, err := svc.Credit(ctx, input)
+		require.NoError(t, err)
+		require.False(t, replayed)
+		_, replayed, err = svc.Credit(ctx, input)
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, repository.WalletOperationPromo, op.Kind)
+		wallet, err := svc.GetWallet(ctx, "user-1", "RUB")
+		require.NoError(t, err)
+		require.Equal(t, int64(50000), wallet.Balance)
+		require.Len(t, repo.WalletEntries(), 2)
+		requireBalancedEntries(t, repo.WalletEntries())
+	})
+
+	t.Run("operation id reused with another amount is a conflict", func(t *testing.T) {
+		// Arrange
+		svc := NewWalletService(memory.NewMemoryRepository())
+		_, _, err := svc.Credit(ctx, CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: 50000})
+		require.NoError(t, err)
+
+		// Act
+		_, _, err = svc.Credit(ctx, CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: 70000})
+
+		// Assert
+		require.ErrorIs(t, err, ErrWalletOperationConflict)
+	})
+
+	t.Run("validates input", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			input   CreditInput
+			wantErr error
+		}{
+			{"no operation id", CreditInput{UserID: "user-1", Amount: 100}, ErrOperationIDRequired},
+			{"no user id", CreditInput{OperationID: "promo-1", Amount: 100}, ErrUserIDRequired},
+			{"zero amount", CreditInput{OperationID: "promo-1", UserID: "user-1"}, ErrInvalidAmount},
+			{"unknown currency", CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: 100, Currency: "XXX"}, ErrUnsupportedCurrency},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc := NewWalletService(mocks.NewWalletRepository(t))
+
+				_, _, err := svc.Credit(ctx, tt.input)
+
+				require.ErrorIs(t, err, tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestPaymentService_PayFromWallet(t *testing.T) {
+	ctx := context.Background()
+	credit := func(t *testing.T, repo *memory.MemoryRepository, amount int64) {
+		t.Helper()
+		_, _, err := NewWalletService(repo).Credit(ctx, CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: amount})
+		require.NoError(t, err)
+	}
+
+	t.Run("debits wallet and saves captured transaction with event", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		credit(t, repo, 50000)
+		service := NewPaymentService(repo, 100000, nil, nil, repo, nil, nil)
+
+		// Act
+		tx, err := service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 30000, Method: MethodWallet})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusCaptured, tx.Status)
+		wallet, err := repo.GetWallet(ctx, "user-1", DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(20000), wallet.Balance)
+		op, err := repo.GetWalletOperation(ctx, "pay_"+tx.TransactionID)
+		require.NoError(t, err)
+		require.Equal(t, "order-1", op.Reference)
+		require.Len(t, repo.WalletEntries(), 4)
+		requireBalancedEntries(t, repo.WalletEntries())
+		events := repo.OutboxEvents()
+		require.Len(t, events, 1)
+		require.Equal(t, "order-1", events[0].AggregateID)
+	})
+
+	t.Run("insufficient balance declines payment and keeps balance", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		credit(t, repo, 10000)
+		service := NewPaymentService(repo, 100000, nil, nil, repo, nil, nil)
+
+		// Act
+		_, err := service.Pay(ctx, PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 30000, Method: MethodWallet})
+
+		// Assert
+		var declineErr *DeclineError
+		require.True(t, errors.As(err, &declineErr))
+		require.Equal(t, DeclineInsufficientFunds, declineErr.Reason)
+		saved, err := repo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusFailed, saved.Status)
+		wallet, err := repo.GetWallet(ctx, "user-1", DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(10000), wallet.Balance)
+	})
+
+	t.Run("repeated payment does not debit twice", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		credit(t, repo, 50000)
+		service := NewPaymentService(repo, 100000, nil, nil, repo, nil, nil)
+		input := PaymentInput{OrderID: "order-1", UserID: "user-1", Amount: 30000, Method: MethodWallet}
+		first, err := service.Pay(ctx, input)
+		require.NoError(t, err)
+
+		// Act
+		second, err := service.Pay(ctx, input)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, first.TransactionID, second.TransactionID)
+		wallet, err := repo.GetWallet(ctx, "user-1", DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(20000), wallet.Balance)
+	})
+
+	t.Run("rejects manual capture and disabled wallet", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+
+		_, err := NewPaymentService(repo, 100000, nil, nil, repo, nil, nil).Pay(ctx, PaymentInput{
+			OrderID: "order-1", UserID: "user-1", Amount: 100, Method: MethodWallet, ManualCapture: true,
+		})
+		require.ErrorIs(t, err, ErrWalletManualCapture)
+
+		_, err = NewPaymentService(repo, 100000, nil, nil, nil, nil, nil).Pay(ctx, PaymentInput{
+			OrderID: "order-1", UserID: "user-1", Amount: 100, Method: MethodWallet,
+		})
+		require.ErrorIs(t, err, ErrWalletDisabled)
+	})
+}
+
+// refundSpyProvider считает вызовы Refund; остальные методы провайдера в тестах возврата на кошелёк не нужны
+type refundSpyProvider struct {
+	provider.PaymentProvider
+	refunds int
+}
+
+func (p *refundSpyProvider) Refund(ctx context.Context, req provider.RefundRequest) (provider.RefundResult, error) {
+	p.refunds++
+	return provider.RefundResult{RefundID: "re_1"}, nil
+}
+
+func TestRefundService_ToWallet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wallet payment is refunded to wallet", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		_, _, err := NewWalletService(repo).Credit(ctx, CreditInput{OperationID: "promo-1", UserID: "user-1", Amount: 50000})
+		require.NoError(t, err)
+		_, err = NewPaymentService(repo, 100000, nil, nil, repo, nil, nil).Pay(ctx, PaymentInput{
+			OrderID: "order-1", UserID: "user-1", Amount: 30000, Method: MethodWallet,
+		})
+		require.NoError(t, err)
+		svc := NewRefundService(repo, memory.NewRefundRepository(repo), nil, repo, 10, 2, nil)
+
+		// Act
+		refund, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", Amount: 10000})
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "rf_order-1", refund.WalletOperationID)
+		wallet, err := repo.GetWallet(ctx, "user-1", DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(30000), wallet.Balance)
+		requireBalancedEntries(t, repo.WalletEntries())
+	})
+
+	t.Run("card payment refunded to wallet skips provider", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		tx, err := NewPaymentService(repo, 100000, mockprovider.New(), nil, repo, nil, nil).Pay(ctx, PaymentInput{
+			OrderID: "order-1", UserID: "user-1", Amount: 30000, Method: "card",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, tx.ProviderPaymentID)
+		spy := &refundSpyProvider{}
+		svc := NewRefundService(repo, memory.NewRefundRepository(repo), spy, repo, 10, 2, nil)
+
+		// Act
+		refund, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1", ToWallet: true})
+
+		// Assert
+		require.NoError(t, err)
+		require.Zero(t, spy.refunds)
+		require.Empty(t, refund.ProviderRefundID)
+		wallet, err := repo.GetWallet(ctx, "user-1", DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(30000), wallet.Balance)
+		saved, err := repo.GetByOrderID(ctx, "order-1")
+		require.NoError(t, err)
+		require.Equal(t, repository.StatusRefunded, saved.Status)
+	})
+
+	t.Run("retry after failed save does not credit twice", func(t *testing.T) {
+		// Arrange
+		repo := memory.NewMemoryRepository()
+		paidTx := repository.Transaction{
+			TransactionID: "tx_order-1_1", OrderID: "order-1", UserID: "user-1",
+			Amount: 30000, CapturedAmount: 30000, Currency: "RUB", Method: MethodWallet, Status: repository.StatusCaptured,
+		}
+		require.NoError(t, repo.Save(ctx, paidTx))
+		refundRepo := mocks.NewRefundRepository(t)
+		refundRepo.On("GetRefund", ctx, "rf_order-1").Return(repository.Refund{}, repository.ErrRefundNotFound).Twice()
+		refundRepo.On("CreateRefund", ctx, mock.Anything).Return(errors.New("db down")).Once()
+		refundRepo.On("CreateRefund", ctx, mock.Anything).Return(nil).Once()
+		svc := NewRefundService(repo, refundRepo, nil, repo, 10, 2, nil)
+
+		// Act
+		_, _, err := svc.Refund(ctx, RefundInput{OrderID: "order-1"})
+		require.Error(t, err)
+		_, _, err = svc.Refund(ctx, RefundInput{OrderID: "order-1"})
+
+		// Assert
+		require.NoError(t, err)
+		wallet, err := repo.GetWallet(ctx, "user-1", "RUB")
+		require.NoError(t, err)
+		require.Equal(t, int64(30000), wallet.Balance)
+	})
+}
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payment_transactions (
+    transaction_id TEXT PRIMARY KEY, -- tx_<order_id>_<unix>
+    order_id TEXT NOT NULL, -- заказ; на заказ не больше одной транзакции (идемпотентность ProcessPayment)
+    user_id TEXT NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0), -- сумма в единицах валюты
+    currency TEXT NOT NULL, -- код валюты ISO 4217
+    method TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('success', 'declined')),
+    decline_reason TEXT NOT NULL DEFAULT '', -- только для declined
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT payment_transactions_order_id_key UNIQUE (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    refund_id TEXT PRIMARY KEY,
+    order_id TEXT NOT NULL, -- на заказ не больше одного возврата
+    transaction_id TEXT NOT NULL REFERENCES payment_transactions(transaction_id), -- исходная транзакция оплаты
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT payment_refunds_order_id_key UNIQUE (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS payment_subscriptions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    method TEXT NOT NULL,
+    interval_seconds BIGINT NOT NULL CHECK (interval_seconds >= 60), -- service.MinSubscriptionInterval
+    status TEXT NOT NULL CHECK (status IN ('active', 'cancelled')),
+    period BIGINT NOT NULL CHECK (period >= 1), -- номер следующего списания
+    next_charge_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Поиск подписок, срок списания которых наступил (ListDueSubscriptions): только активные
+CREATE INDEX IF NOT EXISTS idx_payment_subscriptions_due ON payment_subscriptions(next_charge_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_payment_transactions_user_id ON payment_transactions(user_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payment_transactions_user_id;
+DROP INDEX IF EXISTS idx_payment_subscriptions_due;
+DROP TABLE IF EXISTS payment_subscriptions;
+DROP TABLE IF EXISTS payment_refunds;
+DROP TABLE IF EXISTS payment_transactions;
+-- +goose StatementEnd
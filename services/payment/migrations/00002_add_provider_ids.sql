@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payment_transactions
+    ADD COLUMN IF NOT EXISTS provider_payment_id TEXT NOT NULL DEFAULT ''; -- ID платежа у провайдера, пусто - оплата без провайдера
+
+ALTER TABLE payment_refunds
+    ADD COLUMN IF NOT EXISTS provider_refund_id TEXT NOT NULL DEFAULT ''; -- ID возврата у провайдера
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_refunds DROP COLUMN IF EXISTS provider_refund_id;
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS provider_payment_id;
+-- +goose StatementEnd
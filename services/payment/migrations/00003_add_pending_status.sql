@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- pending: провайдер принял платёж, результат придёт webhook'ом (СБП, оплата по счёту)
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check CHECK (status IN ('success', 'declined', 'pending'));
+
+-- Поиск транзакции по событию провайдера (GetByProviderPaymentID); пустой ID - оплата без провайдера
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_transactions_provider_payment_id
+    ON payment_transactions(provider_payment_id) WHERE provider_payment_id <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payment_transactions_provider_payment_id;
+-- Незавершённые платежи откатываются в отказ provider_error: иначе старое ограничение не применится
+UPDATE payment_transactions SET status = 'declined', decline_reason = 'provider_error' WHERE status = 'pending';
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check CHECK (status IN ('success', 'declined'));
+-- +goose StatementEnd
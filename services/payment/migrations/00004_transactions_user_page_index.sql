@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Страницы ListTransactions: (user_id, created_at, transaction_id) по убыванию, transaction_id различает транзакции одной секунды
+CREATE INDEX IF NOT EXISTS idx_payment_transactions_user_page
+    ON payment_transactions(user_id, created_at DESC, transaction_id DESC);
+DROP INDEX IF EXISTS idx_payment_transactions_user_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_payment_transactions_user_id ON payment_transactions(user_id, created_at);
+DROP INDEX IF EXISTS idx_payment_transactions_user_page;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Статусы транзакции: pending -> authorized -> captured -> refunded, отказ - failed
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+
+UPDATE payment_transactions SET status = 'captured' WHERE status = 'success';
+UPDATE payment_transactions SET status = 'failed' WHERE status = 'declined';
+-- Уже возвращённые полностью платежи
+UPDATE payment_transactions t SET status = 'refunded'
+WHERE t.status = 'captured'
+  AND EXISTS (SELECT 1 FROM payment_refunds r WHERE r.transaction_id = t.transaction_id AND r.amount >= t.amount);
+
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'refunded'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+
+UPDATE payment_transactions SET status = 'success' WHERE status IN ('captured', 'refunded');
+-- Несписанные авторизации в старой модели не представимы: откатываются в отказ provider_error
+UPDATE payment_transactions SET status = 'declined', decline_reason = 'provider_error' WHERE status = 'authorized';
+UPDATE payment_transactions SET status = 'declined' WHERE status = 'failed';
+
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check CHECK (status IN ('success', 'declined', 'pending'));
+-- +goose StatementEnd
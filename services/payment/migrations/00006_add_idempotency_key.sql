@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ключ идемпотентности клиента (ProcessPaymentRequest.idempotency_key); пусто - запрос без ключа
+ALTER TABLE payment_transactions ADD COLUMN IF NOT EXISTS idempotency_key TEXT NOT NULL DEFAULT '';
+
+-- У заказа может быть несколько попыток оплаты: повтор ключа возвращает исходную попытку,
+-- новый ключ допустим, только пока все прежние попытки отклонены
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_order_id_key;
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_order_id_idempotency_key_key UNIQUE (order_id, idempotency_key);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_transactions_order_id_active
+    ON payment_transactions(order_id) WHERE status <> 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Старое ограничение - одна транзакция на заказ: оставляем последнюю попытку, более ранние отказы удаляются
+DELETE FROM payment_transactions t
+WHERE t.status = 'failed'
+  AND EXISTS (
+    SELECT 1 FROM payment_transactions newer
+    WHERE newer.order_id = t.order_id
+      AND (newer.status <> 'failed' OR (newer.created_at, newer.transaction_id) > (t.created_at, t.transaction_id))
+  );
+DROP INDEX IF EXISTS idx_payment_transactions_order_id_active;
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_order_id_idempotency_key_key;
+ALTER TABLE payment_transactions ADD CONSTRAINT payment_transactions_order_id_key UNIQUE (order_id);
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS idempotency_key;
+-- +goose StatementEnd
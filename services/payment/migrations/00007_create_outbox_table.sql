@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transactional outbox: событие результата платежа пишется в одной транзакции со статусом платежа,
+-- OutboxDispatcher публикует его в Kafka (payment.succeeded / payment.failed)
+CREATE TABLE IF NOT EXISTS payment_outbox_events (
+    event_id TEXT PRIMARY KEY, -- <event_type>-<transaction_id>: у транзакции одно событие каждого типа
+    event_type TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    aggregate_id TEXT NOT NULL, -- order_id, ключ сообщения Kafka
+    payload JSONB NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}'::jsonb, -- traceparent, tracestate, baggage, x-request-id
+    attempts INT NOT NULL DEFAULT 0, -- неудачные попытки публикации
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ -- NULL - событие ещё не опубликовано
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_outbox_events_pending ON payment_outbox_events(created_at) WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payment_outbox_events_pending;
+DROP TABLE IF EXISTS payment_outbox_events;
+-- +goose StatementEnd
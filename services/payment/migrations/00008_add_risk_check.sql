@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Антифрод-проверка: declined_risk - отказ до обращения к провайдеру, risk_flag - сработавшее правило
+ALTER TABLE payment_transactions ADD COLUMN IF NOT EXISTS risk_flag TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'refunded', 'declined_risk'));
+
+-- Отказ антифрода - такая же отклонённая попытка, как failed: новая попытка заказа допустима
+DROP INDEX IF EXISTS idx_payment_transactions_order_id_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_transactions_order_id_active
+    ON payment_transactions(order_id) WHERE status NOT IN ('failed', 'declined_risk');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+UPDATE payment_transactions SET status = 'failed' WHERE status = 'declined_risk';
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'refunded'));
+
+DROP INDEX IF EXISTS idx_payment_transactions_order_id_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_transactions_order_id_active
+    ON payment_transactions(order_id) WHERE status <> 'failed';
+
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS risk_flag;
+-- +goose StatementEnd
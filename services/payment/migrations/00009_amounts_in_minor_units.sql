@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Суммы в минимальных единицах валюты: NUMERIC(18, 2) -> BIGINT (копейки, центы; у JPY минимальная единица - иена)
+ALTER TABLE payment_transactions ALTER COLUMN amount TYPE BIGINT
+    USING round(amount * CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END);
+ALTER TABLE payment_refunds ALTER COLUMN amount TYPE BIGINT
+    USING round(amount * CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END);
+ALTER TABLE payment_subscriptions ALTER COLUMN amount TYPE BIGINT
+    USING round(amount * CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END);
+
+-- Неотправленные события outbox публикуются уже по схеме event_version 2
+UPDATE payment_outbox_events
+SET payload = payload || jsonb_build_object(
+        'amount', round((payload->>'amount')::numeric * CASE upper(payload->>'currency') WHEN 'JPY' THEN 1 ELSE 100 END)::bigint,
+        'event_version', 2)
+WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE payment_outbox_events
+SET payload = payload || jsonb_build_object(
+        'amount', (payload->>'amount')::numeric / CASE upper(payload->>'currency') WHEN 'JPY' THEN 1 ELSE 100 END,
+        'event_version', 1)
+WHERE sent_at IS NULL;
+
+ALTER TABLE payment_subscriptions ALTER COLUMN amount TYPE NUMERIC(18, 2)
+    USING amount::numeric / CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END;
+ALTER TABLE payment_refunds ALTER COLUMN amount TYPE NUMERIC(18, 2)
+    USING amount::numeric / CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END;
+ALTER TABLE payment_transactions ALTER COLUMN amount TYPE NUMERIC(18, 2)
+    USING amount::numeric / CASE upper(currency) WHEN 'JPY' THEN 1 ELSE 100 END;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Кошельки пользователей: баланс по валюте; меняется только вместе с операцией и её проводками
+CREATE TABLE IF NOT EXISTS payment_wallets (
+    user_id TEXT NOT NULL,
+    currency TEXT NOT NULL, -- код валюты ISO 4217
+    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0), -- в минимальных единицах валюты
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, currency)
+);
+
+-- Операции по кошельку: operation_id - ключ идемпотентности (pay_<transaction_id>, rf_<order_id>, ID промо-зачисления)
+CREATE TABLE IF NOT EXISTS payment_wallet_operations (
+    operation_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('promo', 'refund', 'payment')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    reference TEXT NOT NULL DEFAULT '', -- order_id или причина зачисления
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Журнал двойной записи: у каждой операции две проводки с суммой 0 - кошелёк и системный счёт (system:*)
+CREATE TABLE IF NOT EXISTS payment_wallet_entries (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id TEXT NOT NULL REFERENCES payment_wallet_operations(operation_id),
+    account TEXT NOT NULL, -- wallet:<user_id> или system:promo / system:refunds / system:payments
+    currency TEXT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0), -- > 0 - баланс счёта растёт, < 0 - уменьшается
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_wallet_entries_operation_id ON payment_wallet_entries(operation_id);
+CREATE INDEX IF NOT EXISTS idx_payment_wallet_entries_account ON payment_wallet_entries(account, currency, created_at);
+
+-- Возврат на кошелёк ссылается на операцию зачисления
+ALTER TABLE payment_refunds ADD COLUMN IF NOT EXISTS wallet_operation_id TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_refunds DROP COLUMN IF EXISTS wallet_operation_id;
+DROP INDEX IF EXISTS idx_payment_wallet_entries_account;
+DROP INDEX IF EXISTS idx_payment_wallet_entries_operation_id;
+DROP TABLE IF EXISTS payment_wallet_entries;
+DROP TABLE IF EXISTS payment_wallet_operations;
+DROP TABLE IF EXISTS payment_wallets;
+-- +goose StatementEnd
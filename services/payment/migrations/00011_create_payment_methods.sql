@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сохранённые способы оплаты: токен провайдера вместо реквизитов карты; токен у пользователя сохраняется один раз
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL, -- user_id пользователя IAM
+    token TEXT NOT NULL, -- токен у платёжного провайдера
+    brand TEXT NOT NULL DEFAULT '',
+    last4 TEXT NOT NULL CHECK (last4 ~ '^[0-9]{4}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_methods_user_id ON payment_methods(user_id, created_at);
+
+-- Транзакция, оплаченная сохранённым способом, ссылается на него; после удаления способа ссылка остаётся для истории
+ALTER TABLE payment_transactions ADD COLUMN IF NOT EXISTS payment_method_id TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS payment_method_id;
+DROP INDEX IF EXISTS idx_payment_methods_user_id;
+DROP TABLE IF EXISTS payment_methods;
+-- +goose StatementEnd
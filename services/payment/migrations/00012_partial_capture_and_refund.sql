@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Частичное списание и частичные возвраты: у транзакции учитываются списанная и возвращённая суммы
+ALTER TABLE payment_transactions
+    ADD COLUMN IF NOT EXISTS captured_amount BIGINT NOT NULL DEFAULT 0, -- списано; остаток авторизации освобождается
+    ADD COLUMN IF NOT EXISTS refunded_amount BIGINT NOT NULL DEFAULT 0; -- сумма возвратов
+
+-- До частичного списания списывалась вся сумма
+UPDATE payment_transactions SET captured_amount = amount WHERE status IN ('captured', 'refunded');
+UPDATE payment_transactions t SET refunded_amount = r.total
+FROM (SELECT transaction_id, SUM(amount) AS total FROM payment_refunds GROUP BY transaction_id) r
+WHERE r.transaction_id = t.transaction_id;
+
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_amounts_check
+        CHECK (captured_amount <= amount AND refunded_amount <= captured_amount);
+
+-- Возвратов у заказа может быть несколько: повтор определяется refund_id, а не order_id
+ALTER TABLE payment_refunds DROP CONSTRAINT IF EXISTS payment_refunds_order_id_key;
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_order_id ON payment_refunds(order_id);
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_transaction_id ON payment_refunds(transaction_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Откат возможен, только пока у каждого заказа не больше одного возврата
+DROP INDEX IF EXISTS idx_payment_refunds_transaction_id;
+DROP INDEX IF EXISTS idx_payment_refunds_order_id;
+ALTER TABLE payment_refunds ADD CONSTRAINT payment_refunds_order_id_key UNIQUE (order_id);
+
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_amounts_check;
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS refunded_amount;
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS captured_amount;
+-- +goose StatementEnd
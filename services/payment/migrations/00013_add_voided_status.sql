@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- voided: авторизация отменена до списания (VoidAuthorization). Это не отказ: новая попытка оплаты заказа
+-- после отмены не допускается, поэтому индекс idx_payment_transactions_order_id_active не меняется
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'refunded', 'declined_risk', 'voided'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Отменённые авторизации в старой модели не представимы: откатываются в отказ provider_error
+ALTER TABLE payment_transactions DROP CONSTRAINT IF EXISTS payment_transactions_status_check;
+UPDATE payment_transactions SET status = 'failed', decline_reason = 'provider_error' WHERE status = 'voided';
+ALTER TABLE payment_transactions
+    ADD CONSTRAINT payment_transactions_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'refunded', 'declined_risk'));
+-- +goose StatementEnd